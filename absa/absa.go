@@ -1,9 +1,16 @@
 package absa
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 	"github.com/nutcas3/payment-rails/absa/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/signature"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -23,6 +30,46 @@ func NewClient(clientID, clientSecret, apiKey, environment string) (*Client, err
 	}, nil
 }
 
+// SetHttpClient replaces the HTTP client. Call it before Use
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.apiClient.SetHttpClient(httpClient)
+}
+
+// SetBaseURL overrides the API base URL the environment selects
+func (c *Client) SetBaseURL(baseURL string) {
+	c.apiClient.SetBaseURL(baseURL)
+}
+
+// SetTokenStore sets the store used to share access tokens between clients
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.apiClient.SetTokenStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.apiClient.SetTokenSkew(skew)
+}
+
+// SetRetryPolicy sets how failed requests are retried
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.apiClient.SetRetryPolicy(policy)
+}
+
+// SetCircuitBreaker sets when requests to a failing endpoint are stopped
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.apiClient.SetCircuitBreaker(settings)
+}
+
+// Health reports the state of the circuit breaker
+func (c *Client) Health() breaker.Health {
+	return c.apiClient.Health()
+}
+
+// Use runs every request through middleware, see transport.Middleware
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.apiClient.Use(middleware...)
+}
+
 func (c *Client) SetWebhookSecret(webhookSecret string) {
 	c.webhookHandler = api.NewWebhookHandler(webhookSecret)
 }
@@ -35,48 +82,100 @@ func (c *Client) HandleWebhook(w http.ResponseWriter, r *http.Request, handlers
 	return nil
 }
 
+func (c *Client) SetWebhookVerifier(verifier *signature.Verifier) error {
+	if c.webhookHandler == nil {
+		return fmt.Errorf("webhook handler not initialized, call SetWebhookSecret first")
+	}
+	c.webhookHandler.Verifier = verifier
+	return nil
+}
+
 func (c *Client) GetAccountBalance(req api.AccountBalanceRequest) (*api.AccountBalanceResponse, error) {
-	return c.apiClient.GetAccountBalance(req)
+	return c.GetAccountBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context, req api.AccountBalanceRequest) (*api.AccountBalanceResponse, error) {
+	return c.apiClient.GetAccountBalanceWithContext(ctx, req)
 }
 
 func (c *Client) GetMiniStatement(req api.MiniStatementRequest) (*api.MiniStatementResponse, error) {
-	return c.apiClient.GetMiniStatement(req)
+	return c.GetMiniStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetMiniStatementWithContext(ctx context.Context, req api.MiniStatementRequest) (*api.MiniStatementResponse, error) {
+	return c.apiClient.GetMiniStatementWithContext(ctx, req)
 }
 
 func (c *Client) GetFullStatement(req api.FullStatementRequest) (*api.FullStatementResponse, error) {
-	return c.apiClient.GetFullStatement(req)
+	return c.GetFullStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetFullStatementWithContext(ctx context.Context, req api.FullStatementRequest) (*api.FullStatementResponse, error) {
+	return c.apiClient.GetFullStatementWithContext(ctx, req)
 }
 
 func (c *Client) ValidateAccount(req api.AccountValidateRequest) (*api.AccountValidateResponse, error) {
-	return c.apiClient.ValidateAccount(req)
+	return c.ValidateAccountWithContext(context.Background(), req)
+}
+
+func (c *Client) ValidateAccountWithContext(ctx context.Context, req api.AccountValidateRequest) (*api.AccountValidateResponse, error) {
+	return c.apiClient.ValidateAccountWithContext(ctx, req)
 }
 
 func (c *Client) SendMoney(req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
-	return c.apiClient.SendMoney(req)
+	return c.SendMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) SendMoneyWithContext(ctx context.Context, req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
+	return c.apiClient.SendMoneyWithContext(ctx, req)
 }
 
 func (c *Client) SendToMobileWallet(req api.MobileWalletRequest) (*api.MobileWalletResponse, error) {
-	return c.apiClient.SendToMobileWallet(req)
+	return c.SendToMobileWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) SendToMobileWalletWithContext(ctx context.Context, req api.MobileWalletRequest) (*api.MobileWalletResponse, error) {
+	return c.apiClient.SendToMobileWalletWithContext(ctx, req)
 }
 
 func (c *Client) SendInternalBankTransfer(req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
-	return c.apiClient.SendInternalBankTransfer(req)
+	return c.SendInternalBankTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) SendInternalBankTransferWithContext(ctx context.Context, req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
+	return c.apiClient.SendInternalBankTransferWithContext(ctx, req)
 }
 
 func (c *Client) PayBill(req api.BillPaymentRequest) (*api.BillPaymentResponse, error) {
-	return c.apiClient.PayBill(req)
+	return c.PayBillWithContext(context.Background(), req)
+}
+
+func (c *Client) PayBillWithContext(ctx context.Context, req api.BillPaymentRequest) (*api.BillPaymentResponse, error) {
+	return c.apiClient.PayBillWithContext(ctx, req)
 }
 
 func (c *Client) ReceiveMoney(req api.ReceiveMoneyRequest) (*api.ReceiveMoneyResponse, error) {
-	return c.apiClient.ReceiveMoney(req)
+	return c.ReceiveMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) ReceiveMoneyWithContext(ctx context.Context, req api.ReceiveMoneyRequest) (*api.ReceiveMoneyResponse, error) {
+	return c.apiClient.ReceiveMoneyWithContext(ctx, req)
 }
 
 func (c *Client) QueryTransaction(req api.TransactionQueryRequest) (*api.TransactionQueryResponse, error) {
-	return c.apiClient.QueryTransaction(req)
+	return c.QueryTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) QueryTransactionWithContext(ctx context.Context, req api.TransactionQueryRequest) (*api.TransactionQueryResponse, error) {
+	return c.apiClient.QueryTransactionWithContext(ctx, req)
 }
 
 func (c *Client) PurchaseAirtime(req api.AirtimePurchaseRequest) (*api.AirtimePurchaseResponse, error) {
-	return c.apiClient.PurchaseAirtime(req)
+	return c.PurchaseAirtimeWithContext(context.Background(), req)
+}
+
+func (c *Client) PurchaseAirtimeWithContext(ctx context.Context, req api.AirtimePurchaseRequest) (*api.AirtimePurchaseResponse, error) {
+	return c.apiClient.PurchaseAirtimeWithContext(ctx, req)
 }
 
 func GenerateReference() string {
@@ -85,77 +184,149 @@ func GenerateReference() string {
 
 
 func (c *Client) ProcessBulkPayment(req api.BulkPaymentRequest) (*api.BulkPaymentResponse, error) {
-	return c.apiClient.ProcessBulkPayment(req)
+	return c.ProcessBulkPaymentWithContext(context.Background(), req)
+}
+
+func (c *Client) ProcessBulkPaymentWithContext(ctx context.Context, req api.BulkPaymentRequest) (*api.BulkPaymentResponse, error) {
+	return c.apiClient.ProcessBulkPaymentWithContext(ctx, req)
 }
 
 func (c *Client) GetBulkPaymentStatus(req api.BulkPaymentStatusRequest) (*api.BulkPaymentStatusResponse, error) {
-	return c.apiClient.GetBulkPaymentStatus(req)
+	return c.GetBulkPaymentStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetBulkPaymentStatusWithContext(ctx context.Context, req api.BulkPaymentStatusRequest) (*api.BulkPaymentStatusResponse, error) {
+	return c.apiClient.GetBulkPaymentStatusWithContext(ctx, req)
 }
 
 
 func (c *Client) CreateStandingOrder(req api.StandingOrderRequest) (*api.StandingOrderResponse, error) {
-	return c.apiClient.CreateStandingOrder(req)
+	return c.CreateStandingOrderWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateStandingOrderWithContext(ctx context.Context, req api.StandingOrderRequest) (*api.StandingOrderResponse, error) {
+	return c.apiClient.CreateStandingOrderWithContext(ctx, req)
 }
 
 func (c *Client) GetStandingOrderStatus(req api.StandingOrderStatusRequest) (*api.StandingOrderStatusResponse, error) {
-	return c.apiClient.GetStandingOrderStatus(req)
+	return c.GetStandingOrderStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetStandingOrderStatusWithContext(ctx context.Context, req api.StandingOrderStatusRequest) (*api.StandingOrderStatusResponse, error) {
+	return c.apiClient.GetStandingOrderStatusWithContext(ctx, req)
 }
 
 func (c *Client) CancelStandingOrder(req api.StandingOrderCancelRequest) (*api.StandingOrderCancelResponse, error) {
-	return c.apiClient.CancelStandingOrder(req)
+	return c.CancelStandingOrderWithContext(context.Background(), req)
+}
+
+func (c *Client) CancelStandingOrderWithContext(ctx context.Context, req api.StandingOrderCancelRequest) (*api.StandingOrderCancelResponse, error) {
+	return c.apiClient.CancelStandingOrderWithContext(ctx, req)
 }
 
 func (c *Client) ListStandingOrders(req api.StandingOrderListRequest) (*api.StandingOrderListResponse, error) {
-	return c.apiClient.ListStandingOrders(req)
+	return c.ListStandingOrdersWithContext(context.Background(), req)
+}
+
+func (c *Client) ListStandingOrdersWithContext(ctx context.Context, req api.StandingOrderListRequest) (*api.StandingOrderListResponse, error) {
+	return c.apiClient.ListStandingOrdersWithContext(ctx, req)
 }
 
 
 func (c *Client) CreateBeneficiary(req api.BeneficiaryCreateRequest) (*api.BeneficiaryCreateResponse, error) {
-	return c.apiClient.CreateBeneficiary(req)
+	return c.CreateBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateBeneficiaryWithContext(ctx context.Context, req api.BeneficiaryCreateRequest) (*api.BeneficiaryCreateResponse, error) {
+	return c.apiClient.CreateBeneficiaryWithContext(ctx, req)
 }
 
 func (c *Client) ListBeneficiaries(req api.BeneficiaryListRequest) (*api.BeneficiaryListResponse, error) {
-	return c.apiClient.ListBeneficiaries(req)
+	return c.ListBeneficiariesWithContext(context.Background(), req)
+}
+
+func (c *Client) ListBeneficiariesWithContext(ctx context.Context, req api.BeneficiaryListRequest) (*api.BeneficiaryListResponse, error) {
+	return c.apiClient.ListBeneficiariesWithContext(ctx, req)
 }
 
 func (c *Client) GetBeneficiary(req api.BeneficiaryGetRequest) (*api.BeneficiaryGetResponse, error) {
-	return c.apiClient.GetBeneficiary(req)
+	return c.GetBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) GetBeneficiaryWithContext(ctx context.Context, req api.BeneficiaryGetRequest) (*api.BeneficiaryGetResponse, error) {
+	return c.apiClient.GetBeneficiaryWithContext(ctx, req)
 }
 
 func (c *Client) UpdateBeneficiary(req api.BeneficiaryUpdateRequest) (*api.BeneficiaryUpdateResponse, error) {
-	return c.apiClient.UpdateBeneficiary(req)
+	return c.UpdateBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) UpdateBeneficiaryWithContext(ctx context.Context, req api.BeneficiaryUpdateRequest) (*api.BeneficiaryUpdateResponse, error) {
+	return c.apiClient.UpdateBeneficiaryWithContext(ctx, req)
 }
 
 func (c *Client) DeleteBeneficiary(req api.BeneficiaryDeleteRequest) (*api.BeneficiaryDeleteResponse, error) {
-	return c.apiClient.DeleteBeneficiary(req)
+	return c.DeleteBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) DeleteBeneficiaryWithContext(ctx context.Context, req api.BeneficiaryDeleteRequest) (*api.BeneficiaryDeleteResponse, error) {
+	return c.apiClient.DeleteBeneficiaryWithContext(ctx, req)
 }
 
 
 func (c *Client) GetForexRate(req api.ForexRateRequest) (*api.ForexRateResponse, error) {
-	return c.apiClient.GetForexRate(req)
+	return c.GetForexRateWithContext(context.Background(), req)
+}
+
+func (c *Client) GetForexRateWithContext(ctx context.Context, req api.ForexRateRequest) (*api.ForexRateResponse, error) {
+	return c.apiClient.GetForexRateWithContext(ctx, req)
 }
 
 func (c *Client) ProcessForexTransfer(req api.ForexTransferRequest) (*api.ForexTransferResponse, error) {
-	return c.apiClient.ProcessForexTransfer(req)
+	return c.ProcessForexTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) ProcessForexTransferWithContext(ctx context.Context, req api.ForexTransferRequest) (*api.ForexTransferResponse, error) {
+	return c.apiClient.ProcessForexTransferWithContext(ctx, req)
 }
 
 func (c *Client) GetForexTransferStatus(req api.ForexTransferStatusRequest) (*api.ForexTransferStatusResponse, error) {
-	return c.apiClient.GetForexTransferStatus(req)
+	return c.GetForexTransferStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetForexTransferStatusWithContext(ctx context.Context, req api.ForexTransferStatusRequest) (*api.ForexTransferStatusResponse, error) {
+	return c.apiClient.GetForexTransferStatusWithContext(ctx, req)
 }
 
 
 func (c *Client) RequestOTP(req api.OTPRequest) (*api.OTPResponse, error) {
-	return c.apiClient.RequestOTP(req)
+	return c.RequestOTPWithContext(context.Background(), req)
+}
+
+func (c *Client) RequestOTPWithContext(ctx context.Context, req api.OTPRequest) (*api.OTPResponse, error) {
+	return c.apiClient.RequestOTPWithContext(ctx, req)
 }
 
 func (c *Client) VerifyOTP(req api.OTPVerifyRequest) (*api.OTPVerifyResponse, error) {
-	return c.apiClient.VerifyOTP(req)
+	return c.VerifyOTPWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyOTPWithContext(ctx context.Context, req api.OTPVerifyRequest) (*api.OTPVerifyResponse, error) {
+	return c.apiClient.VerifyOTPWithContext(ctx, req)
 }
 
 func (c *Client) AuthenticateTransaction(req api.TransactionAuthRequest) (*api.TransactionAuthResponse, error) {
-	return c.apiClient.AuthenticateTransaction(req)
+	return c.AuthenticateTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) AuthenticateTransactionWithContext(ctx context.Context, req api.TransactionAuthRequest) (*api.TransactionAuthResponse, error) {
+	return c.apiClient.AuthenticateTransactionWithContext(ctx, req)
 }
 
 func (c *Client) RegisterDevice(req api.DeviceRegistrationRequest) (*api.DeviceRegistrationResponse, error) {
-	return c.apiClient.RegisterDevice(req)
+	return c.RegisterDeviceWithContext(context.Background(), req)
+}
+
+func (c *Client) RegisterDeviceWithContext(ctx context.Context, req api.DeviceRegistrationRequest) (*api.DeviceRegistrationResponse, error) {
+	return c.apiClient.RegisterDeviceWithContext(ctx, req)
 }
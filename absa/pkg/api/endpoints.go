@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,9 +12,14 @@ import (
 
 // Account Balance
 func (c *Client) GetAccountBalance(req AccountBalanceRequest) (*AccountBalanceResponse, error) {
+	return c.GetAccountBalanceWithContext(context.Background(), req)
+}
+
+// GetAccountBalanceWithContext is like GetAccountBalance but takes a context.
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context, req AccountBalanceRequest) (*AccountBalanceResponse, error) {
 	endpoint := "/accounts/balance"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -33,9 +39,14 @@ func (c *Client) GetAccountBalance(req AccountBalanceRequest) (*AccountBalanceRe
 
 // Mini Statement
 func (c *Client) GetMiniStatement(req MiniStatementRequest) (*MiniStatementResponse, error) {
+	return c.GetMiniStatementWithContext(context.Background(), req)
+}
+
+// GetMiniStatementWithContext is like GetMiniStatement but takes a context.
+func (c *Client) GetMiniStatementWithContext(ctx context.Context, req MiniStatementRequest) (*MiniStatementResponse, error) {
 	endpoint := "/accounts/mini-statement"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -55,9 +66,14 @@ func (c *Client) GetMiniStatement(req MiniStatementRequest) (*MiniStatementRespo
 
 // Full Statement
 func (c *Client) GetFullStatement(req FullStatementRequest) (*FullStatementResponse, error) {
+	return c.GetFullStatementWithContext(context.Background(), req)
+}
+
+// GetFullStatementWithContext is like GetFullStatement but takes a context.
+func (c *Client) GetFullStatementWithContext(ctx context.Context, req FullStatementRequest) (*FullStatementResponse, error) {
 	endpoint := "/accounts/full-statement"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -77,9 +93,14 @@ func (c *Client) GetFullStatement(req FullStatementRequest) (*FullStatementRespo
 
 // Account Validation
 func (c *Client) ValidateAccount(req AccountValidateRequest) (*AccountValidateResponse, error) {
+	return c.ValidateAccountWithContext(context.Background(), req)
+}
+
+// ValidateAccountWithContext is like ValidateAccount but takes a context.
+func (c *Client) ValidateAccountWithContext(ctx context.Context, req AccountValidateRequest) (*AccountValidateResponse, error) {
 	endpoint := "/accounts/validate"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -99,9 +120,14 @@ func (c *Client) ValidateAccount(req AccountValidateRequest) (*AccountValidateRe
 
 // Send Money (Bank Transfer)
 func (c *Client) SendMoney(req SendMoneyRequest) (*SendMoneyResponse, error) {
+	return c.SendMoneyWithContext(context.Background(), req)
+}
+
+// SendMoneyWithContext is like SendMoney but takes a context.
+func (c *Client) SendMoneyWithContext(ctx context.Context, req SendMoneyRequest) (*SendMoneyResponse, error) {
 	endpoint := "/payments/bank-transfer"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -121,9 +147,14 @@ func (c *Client) SendMoney(req SendMoneyRequest) (*SendMoneyResponse, error) {
 
 // Internal Bank Transfer
 func (c *Client) SendInternalBankTransfer(req SendMoneyRequest) (*SendMoneyResponse, error) {
+	return c.SendInternalBankTransferWithContext(context.Background(), req)
+}
+
+// SendInternalBankTransferWithContext is like SendInternalBankTransfer but takes a context.
+func (c *Client) SendInternalBankTransferWithContext(ctx context.Context, req SendMoneyRequest) (*SendMoneyResponse, error) {
 	endpoint := "/payments/internal-transfer"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -143,9 +174,14 @@ func (c *Client) SendInternalBankTransfer(req SendMoneyRequest) (*SendMoneyRespo
 
 // Mobile Wallet
 func (c *Client) SendToMobileWallet(req MobileWalletRequest) (*MobileWalletResponse, error) {
+	return c.SendToMobileWalletWithContext(context.Background(), req)
+}
+
+// SendToMobileWalletWithContext is like SendToMobileWallet but takes a context.
+func (c *Client) SendToMobileWalletWithContext(ctx context.Context, req MobileWalletRequest) (*MobileWalletResponse, error) {
 	endpoint := "/payments/mobile-wallet"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -165,9 +201,14 @@ func (c *Client) SendToMobileWallet(req MobileWalletRequest) (*MobileWalletRespo
 
 // Bill Payment
 func (c *Client) PayBill(req BillPaymentRequest) (*BillPaymentResponse, error) {
+	return c.PayBillWithContext(context.Background(), req)
+}
+
+// PayBillWithContext is like PayBill but takes a context.
+func (c *Client) PayBillWithContext(ctx context.Context, req BillPaymentRequest) (*BillPaymentResponse, error) {
 	endpoint := "/payments/bill-payment"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -187,9 +228,14 @@ func (c *Client) PayBill(req BillPaymentRequest) (*BillPaymentResponse, error) {
 
 // Receive Money
 func (c *Client) ReceiveMoney(req ReceiveMoneyRequest) (*ReceiveMoneyResponse, error) {
+	return c.ReceiveMoneyWithContext(context.Background(), req)
+}
+
+// ReceiveMoneyWithContext is like ReceiveMoney but takes a context.
+func (c *Client) ReceiveMoneyWithContext(ctx context.Context, req ReceiveMoneyRequest) (*ReceiveMoneyResponse, error) {
 	endpoint := "/payments/receive"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -214,9 +260,14 @@ func (c *Client) ReceiveMoney(req ReceiveMoneyRequest) (*ReceiveMoneyResponse, e
 
 // Transaction Query
 func (c *Client) QueryTransaction(req TransactionQueryRequest) (*TransactionQueryResponse, error) {
+	return c.QueryTransactionWithContext(context.Background(), req)
+}
+
+// QueryTransactionWithContext is like QueryTransaction but takes a context.
+func (c *Client) QueryTransactionWithContext(ctx context.Context, req TransactionQueryRequest) (*TransactionQueryResponse, error) {
 	endpoint := "/transactions/status"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -244,9 +295,14 @@ func validateAmount(amount decimal.Decimal) error {
 
 // Airtime Purchase
 func (c *Client) PurchaseAirtime(req AirtimePurchaseRequest) (*AirtimePurchaseResponse, error) {
+	return c.PurchaseAirtimeWithContext(context.Background(), req)
+}
+
+// PurchaseAirtimeWithContext is like PurchaseAirtime but takes a context.
+func (c *Client) PurchaseAirtimeWithContext(ctx context.Context, req AirtimePurchaseRequest) (*AirtimePurchaseResponse, error) {
 	endpoint := "/payments/airtime"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -266,6 +322,11 @@ func (c *Client) PurchaseAirtime(req AirtimePurchaseRequest) (*AirtimePurchaseRe
 
 // Bulk Payments
 func (c *Client) ProcessBulkPayment(req BulkPaymentRequest) (*BulkPaymentResponse, error) {
+	return c.ProcessBulkPaymentWithContext(context.Background(), req)
+}
+
+// ProcessBulkPaymentWithContext is like ProcessBulkPayment but takes a context.
+func (c *Client) ProcessBulkPaymentWithContext(ctx context.Context, req BulkPaymentRequest) (*BulkPaymentResponse, error) {
 	endpoint := "/payments/bulk"
 	
 	// Validate each payment item amount
@@ -275,7 +336,7 @@ func (c *Client) ProcessBulkPayment(req BulkPaymentRequest) (*BulkPaymentRespons
 		}
 	}
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -294,9 +355,13 @@ func (c *Client) ProcessBulkPayment(req BulkPaymentRequest) (*BulkPaymentRespons
 }
 
 func (c *Client) GetBulkPaymentStatus(req BulkPaymentStatusRequest) (*BulkPaymentStatusResponse, error) {
+	return c.GetBulkPaymentStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetBulkPaymentStatusWithContext(ctx context.Context, req BulkPaymentStatusRequest) (*BulkPaymentStatusResponse, error) {
 	endpoint := "/payments/bulk/status"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -316,6 +381,11 @@ func (c *Client) GetBulkPaymentStatus(req BulkPaymentStatusRequest) (*BulkPaymen
 
 // Standing Orders/Recurring Payments
 func (c *Client) CreateStandingOrder(req StandingOrderRequest) (*StandingOrderResponse, error) {
+	return c.CreateStandingOrderWithContext(context.Background(), req)
+}
+
+// CreateStandingOrderWithContext is like CreateStandingOrder but takes a context.
+func (c *Client) CreateStandingOrderWithContext(ctx context.Context, req StandingOrderRequest) (*StandingOrderResponse, error) {
 	endpoint := "/payments/standing-orders"
 	
 	// Validate amount
@@ -340,7 +410,7 @@ func (c *Client) CreateStandingOrder(req StandingOrderRequest) (*StandingOrderRe
 		return nil, fmt.Errorf("invalid frequency: %s", req.Frequency)
 	}
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -359,9 +429,13 @@ func (c *Client) CreateStandingOrder(req StandingOrderRequest) (*StandingOrderRe
 }
 
 func (c *Client) GetStandingOrderStatus(req StandingOrderStatusRequest) (*StandingOrderStatusResponse, error) {
+	return c.GetStandingOrderStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetStandingOrderStatusWithContext(ctx context.Context, req StandingOrderStatusRequest) (*StandingOrderStatusResponse, error) {
 	endpoint := "/payments/standing-orders/status"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -380,9 +454,13 @@ func (c *Client) GetStandingOrderStatus(req StandingOrderStatusRequest) (*Standi
 }
 
 func (c *Client) CancelStandingOrder(req StandingOrderCancelRequest) (*StandingOrderCancelResponse, error) {
+	return c.CancelStandingOrderWithContext(context.Background(), req)
+}
+
+func (c *Client) CancelStandingOrderWithContext(ctx context.Context, req StandingOrderCancelRequest) (*StandingOrderCancelResponse, error) {
 	endpoint := "/payments/standing-orders/cancel"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -401,9 +479,13 @@ func (c *Client) CancelStandingOrder(req StandingOrderCancelRequest) (*StandingO
 }
 
 func (c *Client) ListStandingOrders(req StandingOrderListRequest) (*StandingOrderListResponse, error) {
+	return c.ListStandingOrdersWithContext(context.Background(), req)
+}
+
+func (c *Client) ListStandingOrdersWithContext(ctx context.Context, req StandingOrderListRequest) (*StandingOrderListResponse, error) {
 	endpoint := "/payments/standing-orders/list"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -423,6 +505,11 @@ func (c *Client) ListStandingOrders(req StandingOrderListRequest) (*StandingOrde
 
 // Beneficiary Management
 func (c *Client) CreateBeneficiary(req BeneficiaryCreateRequest) (*BeneficiaryCreateResponse, error) {
+	return c.CreateBeneficiaryWithContext(context.Background(), req)
+}
+
+// CreateBeneficiaryWithContext is like CreateBeneficiary but takes a context.
+func (c *Client) CreateBeneficiaryWithContext(ctx context.Context, req BeneficiaryCreateRequest) (*BeneficiaryCreateResponse, error) {
 	endpoint := "/beneficiaries"
 	
 	// Validate beneficiary type
@@ -443,7 +530,7 @@ func (c *Client) CreateBeneficiary(req BeneficiaryCreateRequest) (*BeneficiaryCr
 		return nil, fmt.Errorf("invalid beneficiary type: %s", req.Type)
 	}
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -462,9 +549,13 @@ func (c *Client) CreateBeneficiary(req BeneficiaryCreateRequest) (*BeneficiaryCr
 }
 
 func (c *Client) ListBeneficiaries(req BeneficiaryListRequest) (*BeneficiaryListResponse, error) {
+	return c.ListBeneficiariesWithContext(context.Background(), req)
+}
+
+func (c *Client) ListBeneficiariesWithContext(ctx context.Context, req BeneficiaryListRequest) (*BeneficiaryListResponse, error) {
 	endpoint := "/beneficiaries/list"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -483,9 +574,13 @@ func (c *Client) ListBeneficiaries(req BeneficiaryListRequest) (*BeneficiaryList
 }
 
 func (c *Client) GetBeneficiary(req BeneficiaryGetRequest) (*BeneficiaryGetResponse, error) {
+	return c.GetBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) GetBeneficiaryWithContext(ctx context.Context, req BeneficiaryGetRequest) (*BeneficiaryGetResponse, error) {
 	endpoint := "/beneficiaries/get"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -504,9 +599,13 @@ func (c *Client) GetBeneficiary(req BeneficiaryGetRequest) (*BeneficiaryGetRespo
 }
 
 func (c *Client) UpdateBeneficiary(req BeneficiaryUpdateRequest) (*BeneficiaryUpdateResponse, error) {
+	return c.UpdateBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) UpdateBeneficiaryWithContext(ctx context.Context, req BeneficiaryUpdateRequest) (*BeneficiaryUpdateResponse, error) {
 	endpoint := "/beneficiaries/update"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -525,9 +624,13 @@ func (c *Client) UpdateBeneficiary(req BeneficiaryUpdateRequest) (*BeneficiaryUp
 }
 
 func (c *Client) DeleteBeneficiary(req BeneficiaryDeleteRequest) (*BeneficiaryDeleteResponse, error) {
+	return c.DeleteBeneficiaryWithContext(context.Background(), req)
+}
+
+func (c *Client) DeleteBeneficiaryWithContext(ctx context.Context, req BeneficiaryDeleteRequest) (*BeneficiaryDeleteResponse, error) {
 	endpoint := "/beneficiaries/delete"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -547,9 +650,14 @@ func (c *Client) DeleteBeneficiary(req BeneficiaryDeleteRequest) (*BeneficiaryDe
 
 // Foreign Exchange
 func (c *Client) GetForexRate(req ForexRateRequest) (*ForexRateResponse, error) {
+	return c.GetForexRateWithContext(context.Background(), req)
+}
+
+// GetForexRateWithContext is like GetForexRate but takes a context.
+func (c *Client) GetForexRateWithContext(ctx context.Context, req ForexRateRequest) (*ForexRateResponse, error) {
 	endpoint := "/forex/rates"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -568,6 +676,10 @@ func (c *Client) GetForexRate(req ForexRateRequest) (*ForexRateResponse, error)
 }
 
 func (c *Client) ProcessForexTransfer(req ForexTransferRequest) (*ForexTransferResponse, error) {
+	return c.ProcessForexTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) ProcessForexTransferWithContext(ctx context.Context, req ForexTransferRequest) (*ForexTransferResponse, error) {
 	endpoint := "/forex/transfer"
 	
 	// Validate amount
@@ -580,7 +692,7 @@ func (c *Client) ProcessForexTransfer(req ForexTransferRequest) (*ForexTransferR
 		return nil, fmt.Errorf("source and destination currencies must be different")
 	}
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -599,9 +711,13 @@ func (c *Client) ProcessForexTransfer(req ForexTransferRequest) (*ForexTransferR
 }
 
 func (c *Client) GetForexTransferStatus(req ForexTransferStatusRequest) (*ForexTransferStatusResponse, error) {
+	return c.GetForexTransferStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) GetForexTransferStatusWithContext(ctx context.Context, req ForexTransferStatusRequest) (*ForexTransferStatusResponse, error) {
 	endpoint := "/forex/status"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -621,9 +737,14 @@ func (c *Client) GetForexTransferStatus(req ForexTransferStatusRequest) (*ForexT
 
 // Authentication Methods
 func (c *Client) RequestOTP(req OTPRequest) (*OTPResponse, error) {
+	return c.RequestOTPWithContext(context.Background(), req)
+}
+
+// RequestOTPWithContext is like RequestOTP but takes a context.
+func (c *Client) RequestOTPWithContext(ctx context.Context, req OTPRequest) (*OTPResponse, error) {
 	endpoint := "/auth/otp/request"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -648,9 +769,13 @@ func (c *Client) RequestOTP(req OTPRequest) (*OTPResponse, error) {
 }
 
 func (c *Client) VerifyOTP(req OTPVerifyRequest) (*OTPVerifyResponse, error) {
+	return c.VerifyOTPWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyOTPWithContext(ctx context.Context, req OTPVerifyRequest) (*OTPVerifyResponse, error) {
 	endpoint := "/auth/otp/verify"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -669,6 +794,10 @@ func (c *Client) VerifyOTP(req OTPVerifyRequest) (*OTPVerifyResponse, error) {
 }
 
 func (c *Client) AuthenticateTransaction(req TransactionAuthRequest) (*TransactionAuthResponse, error) {
+	return c.AuthenticateTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) AuthenticateTransactionWithContext(ctx context.Context, req TransactionAuthRequest) (*TransactionAuthResponse, error) {
 	endpoint := "/auth/transaction"
 	
 	// Validate authentication method
@@ -679,7 +808,7 @@ func (c *Client) AuthenticateTransaction(req TransactionAuthRequest) (*Transacti
 		return nil, fmt.Errorf("invalid authentication method: %s", req.AuthMethod)
 	}
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
@@ -698,9 +827,13 @@ func (c *Client) AuthenticateTransaction(req TransactionAuthRequest) (*Transacti
 }
 
 func (c *Client) RegisterDevice(req DeviceRegistrationRequest) (*DeviceRegistrationResponse, error) {
+	return c.RegisterDeviceWithContext(context.Background(), req)
+}
+
+func (c *Client) RegisterDeviceWithContext(ctx context.Context, req DeviceRegistrationRequest) (*DeviceRegistrationResponse, error) {
 	endpoint := "/auth/device/register"
 	
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req)
 	if err != nil {
 		return nil, err
 	}
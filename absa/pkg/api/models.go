@@ -98,7 +98,7 @@ type AccountValidateResponse struct {
 type SendMoneyRequest struct {
 	SourceAccount       string          `json:"sourceAccount"`
 	DestinationAccount  string          `json:"destinationAccount"`
-	DestinationBankCode string          `json:"destinationBankCode,omitempty"`
+	DestinationBankCode string          `json:"destinationBankCode,omitempty"` // Universal branch code, see banks.Find in rails/banks
 	Amount              decimal.Decimal `json:"amount"`
 	Currency            string          `json:"currency"`
 	Reference           string          `json:"reference"`
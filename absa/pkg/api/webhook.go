@@ -1,12 +1,13 @@
 package api
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
 const (
@@ -15,6 +16,10 @@ const (
 
 type WebhookHandler struct {
 	Secret string
+
+	// Verifier checks signatures, timestamps and replayed webhooks.
+	// NewWebhookHandler sets signature.New().
+	Verifier *signature.Verifier
 }
 
 type WebhookHandlers struct {
@@ -26,7 +31,30 @@ type WebhookHandlers struct {
 }
 
 type WebhookEvent struct {
-	EventType string `json:"eventType"`
+	EventType     string `json:"eventType"`
+	TransactionID string `json:"transactionId"`
+	AccountNumber string `json:"accountNumber"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// ID identifies the webhook for replay checks. Absa webhooks have no event
+// ID, so it is made of the event type, the transaction or account and the
+// timestamp, and is empty if the subject or timestamp is missing.
+func (e WebhookEvent) ID() string {
+	subject := e.TransactionID
+	if subject == "" {
+		subject = e.AccountNumber
+	}
+	if subject == "" || e.Timestamp == "" {
+		return ""
+	}
+	return e.EventType + ":" + subject + ":" + e.Timestamp
+}
+
+// Time parses Timestamp, which is zero if it is missing or not RFC 3339.
+func (e WebhookEvent) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, e.Timestamp)
+	return t
 }
 
 type PaymentSuccessWebhook struct {
@@ -79,20 +107,22 @@ type AccountUpdateWebhook struct {
 
 func NewWebhookHandler(secret string) *WebhookHandler {
 	return &WebhookHandler{
-		Secret: secret,
+		Secret:   secret,
+		Verifier: signature.New(),
 	}
 }
 
-func (h *WebhookHandler) ValidateSignature(body []byte, signature string) bool {
-	if h.Secret == "" || signature == "" {
-		return false
+func (h *WebhookHandler) verifier() *signature.Verifier {
+	if h.Verifier == nil {
+		return &signature.Verifier{}
 	}
+	return h.Verifier
+}
 
-	mac := hmac.New(sha256.New, []byte(h.Secret))
-	mac.Write(body)
-	expectedMAC := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedMAC))
+// ValidateSignature reports whether sig is the HMAC-SHA256 of body keyed
+// with Secret. It compares in constant time.
+func (h *WebhookHandler) ValidateSignature(body []byte, sig string) bool {
+	return h.verifier().Verify(body, sig, h.Secret) == nil
 }
 
 func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request, handlers WebhookHandlers) {
@@ -102,8 +132,8 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request, h
 		return
 	}
 
-	signature := r.Header.Get(WebhookSignatureHeader)
-	if !h.ValidateSignature(body, signature) {
+	sig := r.Header.Get(WebhookSignatureHeader)
+	if !h.ValidateSignature(body, sig) {
 		http.Error(w, "Invalid signature", http.StatusUnauthorized)
 		return
 	}
@@ -114,6 +144,32 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request, h
 		return
 	}
 
+	// Webhooks without an ID are only checked for their timestamp
+	var id string
+	if event.ID() != "" {
+		id = "absa:" + event.ID()
+	}
+	if err := h.verifier().Check(r.Context(), id, event.Time()); err != nil {
+		if errors.Is(err, signature.ErrReplayed) {
+			// Already handled, acknowledge so it is not sent again
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if errors.Is(err, signature.ErrTimestamp) {
+			http.Error(w, "Invalid timestamp", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error processing webhook", http.StatusInternalServerError)
+		return
+	}
+	// Let the webhook be sent again if it cannot be handled
+	handled := false
+	defer func() {
+		if !handled {
+			h.verifier().Forget(r.Context(), id)
+		}
+	}()
+
 	switch event.EventType {
 	case "payment.success":
 		var webhook PaymentSuccessWebhook
@@ -177,5 +233,6 @@ func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request, h
 		}
 	}
 
+	handled = true
 	w.WriteHeader(http.StatusOK)
 }
@@ -1,7 +1,16 @@
 package airtel
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/nutcas3/payment-rails/airtel/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -24,26 +33,90 @@ func New(clientID, clientSecret, publicKey string, sandbox bool, country, curren
 	}, nil
 }
 
-func (c *Client) UssdPush(reference, phone string, amount float64, transactionID string) (*api.CollectionResponse, error) {
-	return c.service.UssdPush(reference, phone, amount, transactionID)
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.service.SetHttpClient(httpClient)
+}
+
+func (c *Client) SetBaseURL(baseURL string) {
+	c.service.SetBaseURL(baseURL)
+}
+
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.service.SetTokenStore(store)
+}
+
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.service.SetTokenSkew(skew)
+}
+
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.service.SetRetryPolicy(policy)
+}
+
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.service.SetCircuitBreaker(settings)
+}
+
+func (c *Client) Health() breaker.Health {
+	return c.service.Health()
+}
+
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.service.Use(middleware...)
+}
+
+func (c *Client) Currency() string {
+	return c.service.Currency()
+}
+
+func (c *Client) Country() string {
+	return c.service.Country()
+}
+
+func (c *Client) UssdPush(reference, phone string, amount money.Money, transactionID string) (*api.CollectionResponse, error) {
+	return c.UssdPushWithContext(context.Background(), reference, phone, amount, transactionID)
+}
+
+func (c *Client) UssdPushWithContext(ctx context.Context, reference, phone string, amount money.Money, transactionID string) (*api.CollectionResponse, error) {
+	return c.service.UssdPushWithContext(ctx, reference, phone, amount, transactionID)
 }
 
 func (c *Client) GetTransactionStatus(transactionID string) (*api.TransactionStatusResponse, error) {
-	return c.service.GetTransactionStatus(transactionID)
+	return c.GetTransactionStatusWithContext(context.Background(), transactionID)
+}
+
+func (c *Client) GetTransactionStatusWithContext(ctx context.Context, transactionID string) (*api.TransactionStatusResponse, error) {
+	return c.service.GetTransactionStatusWithContext(ctx, transactionID)
 }
 
-func (c *Client) RefundTransaction(airtelMoneyID string, amount float64) (*api.RefundResponse, error) {
-	return c.service.RefundTransaction(airtelMoneyID, amount)
+func (c *Client) RefundTransaction(airtelMoneyID string, amount money.Money) (*api.RefundResponse, error) {
+	return c.RefundTransactionWithContext(context.Background(), airtelMoneyID, amount)
 }
 
-func (c *Client) Disburse(reference, phone string, amount float64, transactionID string, pin string) (*api.DisbursementResponse, error) {
-	return c.service.Disburse(reference, phone, amount, transactionID, pin)
+func (c *Client) RefundTransactionWithContext(ctx context.Context, airtelMoneyID string, amount money.Money) (*api.RefundResponse, error) {
+	return c.service.RefundTransactionWithContext(ctx, airtelMoneyID, amount)
+}
+
+func (c *Client) Disburse(reference, phone string, amount money.Money, transactionID string, pin string) (*api.DisbursementResponse, error) {
+	return c.DisburseWithContext(context.Background(), reference, phone, amount, transactionID, pin)
+}
+
+func (c *Client) DisburseWithContext(ctx context.Context, reference, phone string, amount money.Money, transactionID string, pin string) (*api.DisbursementResponse, error) {
+	return c.service.DisburseWithContext(ctx, reference, phone, amount, transactionID, pin)
 }
 
 func (c *Client) GetDisbursementStatus(transactionID string) (*api.DisbursementStatusResponse, error) {
-	return c.service.GetDisbursementStatus(transactionID)
+	return c.GetDisbursementStatusWithContext(context.Background(), transactionID)
+}
+
+func (c *Client) GetDisbursementStatusWithContext(ctx context.Context, transactionID string) (*api.DisbursementStatusResponse, error) {
+	return c.service.GetDisbursementStatusWithContext(ctx, transactionID)
 }
 
 func (c *Client) GetAccountBalance() (*api.AccountBalanceResponse, error) {
-	return c.service.GetAccountBalance()
+	return c.GetAccountBalanceWithContext(context.Background())
+}
+
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context) (*api.AccountBalanceResponse, error) {
+	return c.service.GetAccountBalanceWithContext(ctx)
 }
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type AccountBalanceResponse struct {
@@ -14,13 +17,17 @@ type AccountBalanceResponse struct {
 		Code        string `json:"code"`
 	} `json:"status"`
 	Data struct {
-		Balance float64 `json:"balance"`
-		Currency string `json:"currency"`
+		Balance  money.Number   `json:"balance"`
+		Currency money.Currency `json:"currency"`
 	} `json:"data"`
 }
 
 func (s *Service) GetAccountBalance() (*AccountBalanceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodGet, accountBalanceURL, nil)
+	return s.GetAccountBalanceWithContext(context.Background())
+}
+
+func (s *Service) GetAccountBalanceWithContext(ctx context.Context) (*AccountBalanceResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodGet, accountBalanceURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account balance: %w", err)
 	}
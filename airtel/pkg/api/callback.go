@@ -0,0 +1,69 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+type CallbackTransaction struct {
+	ID            string `json:"id"` // The transaction ID sent with the request
+	Message       string `json:"message"`
+	StatusCode    string `json:"status_code"`
+	AirtelMoneyID string `json:"airtel_money_id"`
+}
+
+// Callback is the body Airtel posts to the callback URL configured for the
+// application once a collection or disbursement completes.
+type Callback struct {
+	Transaction CallbackTransaction `json:"transaction"`
+
+	// Hash is only sent when callback authentication is enabled for the
+	// application, see Verify.
+	Hash string `json:"hash,omitempty"`
+
+	transaction json.RawMessage
+}
+
+// ParseCallback decodes a callback body.
+func ParseCallback(data []byte) (*Callback, error) {
+	var envelope struct {
+		Transaction json.RawMessage `json:"transaction"`
+		Hash        string          `json:"hash"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse Airtel callback: %w", err)
+	}
+	if len(envelope.Transaction) == 0 {
+		return nil, fmt.Errorf("Airtel callback is missing transaction")
+	}
+
+	cb := &Callback{Hash: envelope.Hash, transaction: envelope.Transaction}
+	if err := json.Unmarshal(envelope.Transaction, &cb.Transaction); err != nil {
+		return nil, fmt.Errorf("failed to parse Airtel callback transaction: %w", err)
+	}
+	return cb, nil
+}
+
+// Succeeded reports whether the transaction completed.
+func (cb *Callback) Succeeded() bool {
+	return cb.Transaction.StatusCode == "TS"
+}
+
+// Verify reports whether Hash is the base64 HMAC-SHA256 of the transaction
+// object, as sent, keyed with the callback authentication secret.
+func (cb *Callback) Verify(secret string) bool {
+	if secret == "" || cb.Hash == "" {
+		return false
+	}
+	hash, err := base64.StdEncoding.DecodeString(cb.Hash)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(cb.transaction)
+	return hmac.Equal(hash, mac.Sum(nil))
+}
@@ -2,12 +2,18 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -23,8 +29,6 @@ const (
 	disburseURL            = "/standard/v1/disbursements/"
 	disbursementStatusURL  = "/standard/v1/disbursements/"
 	accountBalanceURL      = "/standard/v1/accounts/balance"
-	
-	authTokenCacheKey = "airtel_auth_token"
 )
 
 type Service struct {
@@ -36,13 +40,15 @@ type Service struct {
 	currency       string
 	baseURL        string
 	httpClient     *http.Client
-	cache          *cache.Cache
+	tokens         *auth.TokenManager
+	retry          retry.Policy
+	breaker        *breaker.Breaker
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"`
-	TokenType   string `json:"token_type"`
+	AccessToken string         `json:"access_token"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
+	TokenType   string         `json:"token_type"`
 }
 
 func New(clientID, clientSecret, publicKey string, environment Environment, country, currency string) (*Service, error) {
@@ -55,9 +61,7 @@ func New(clientID, clientSecret, publicKey string, environment Environment, coun
 		baseURL = "https://openapi.airtel.africa"
 	}
 
-	c := cache.New(1*time.Hour, 10*time.Minute)
-
-	return &Service{
+	s := &Service{
 		clientID:      clientID,
 		clientSecret:  clientSecret,
 		publicKey:     publicKey,
@@ -66,19 +70,81 @@ func New(clientID, clientSecret, publicKey string, environment Environment, coun
 		currency:      currency,
 		baseURL:       baseURL,
 		httpClient:    &http.Client{Timeout: 30 * time.Second},
-		cache:         c,
-	}, nil
+	}
+	s.tokens = auth.NewTokenManager(nil, auth.Key("airtel", string(environment), clientID), s.fetchAuthToken)
+	s.retry = retry.DefaultPolicy()
+	s.breaker = breaker.New(provider, breaker.DefaultSettings())
+
+	return s, nil
 }
 
 func (s *Service) SetHttpClient(httpClient *http.Client) {
 	s.httpClient = httpClient
 }
 
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server. Tokens fetched from
+// baseURL are stored apart from the environment's.
+func (s *Service) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+	s.tokens.SetKey(auth.Key("airtel", baseURL, s.clientID))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same client ID reuse one token instead of each requesting their own.
+func (s *Service) SetTokenStore(store auth.TokenStore) {
+	s.tokens.SetStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (s *Service) SetTokenSkew(skew time.Duration) {
+	s.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (s *Service) SetRetryPolicy(policy retry.Policy) {
+	s.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (s *Service) SetCircuitBreaker(settings breaker.Settings) {
+	s.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (s *Service) Health() breaker.Health {
+	return s.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (s *Service) roundTrip(req *http.Request) (*http.Response, error) {
+	return s.breaker.Do(req, s.httpClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (s *Service) Use(middleware ...transport.Middleware) {
+	s.httpClient = transport.Wrap(s.httpClient, provider, middleware...)
+}
+
 func (s *Service) GetAuthToken() (string, error) {
-	if token, found := s.cache.Get(authTokenCacheKey); found {
-		return token.(string), nil
+	return s.GetAuthTokenWithContext(context.Background())
+}
+
+func (s *Service) GetAuthTokenWithContext(ctx context.Context) (string, error) {
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
+func (s *Service) fetchAuthToken(ctx context.Context) (auth.Token, error) {
 	url := s.baseURL + authURL
 	payload := map[string]string{
 		"client_id":     s.clientID,
@@ -88,66 +154,66 @@ func (s *Service) GetAuthToken() (string, error) {
 
 	reqBody, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal auth request payload: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to marshal auth request payload: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.roundTrip(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to execute auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("auth request failed with status: %s", resp.Status)
+		body, _ := readResponseBody(resp)
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 
 	var authResp AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return "", fmt.Errorf("failed to decode auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
-	expiresIn := authResp.ExpiresIn
-	if expiresIn == 0 {
-		expiresIn = 3600 // Default to 1 hour if not provided
-	}
-	s.cache.Set(authTokenCacheKey, authResp.AccessToken, time.Duration(expiresIn)*time.Second)
-
-	return authResp.AccessToken, nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
-func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte, error) {
-	token, err := s.GetAuthToken()
-	if err != nil {
-		return nil, err
-	}
-
+func (s *Service) makeRequest(ctx context.Context, method, url string, payload interface{}) ([]byte, error) {
 	var reqBody []byte
 	if payload != nil {
+		var err error
 		reqBody, err = json.Marshal(payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request payload: %w", err)
 		}
 	}
 
-	req, err := http.NewRequest(method, s.baseURL+url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	resp, err := s.tokens.Do(ctx, func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, s.baseURL+url, bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Country", s.country)
-	req.Header.Set("X-Currency", s.currency)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Country", s.country)
+		req.Header.Set("X-Currency", s.currency)
 
-	resp, err := s.httpClient.Do(req)
+		resp, err := s.retry.Do(req, s.roundTrip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+		return resp, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -156,17 +222,35 @@ func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte,
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errResp map[string]interface{}
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			return nil, fmt.Errorf("request failed with status: %s, error: %v", resp.Status, errResp)
-		}
-		return nil, fmt.Errorf("request failed with status: %s", resp.Status)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated || rejected(respBody) {
+		return nil, newProviderError(resp, respBody)
 	}
 
 	return respBody, nil
 }
 
+// Currency returns the currency the client transacts in.
+func (s *Service) Currency() string {
+	return s.currency
+}
+
+// Country returns the country the client transacts in.
+func (s *Service) Country() string {
+	return s.country
+}
+
+// checkAmount rejects amounts that are not positive or not in the currency
+// the client was created for, which Airtel applies to every transaction.
+func (s *Service) checkAmount(amount money.Money) error {
+	if !amount.Amount.IsPositive() {
+		return fmt.Errorf("amount must be greater than zero")
+	}
+	if !strings.EqualFold(string(amount.Currency), s.currency) {
+		return fmt.Errorf("amount is in %s but the client is configured for %s", amount.Currency, s.currency)
+	}
+	return nil
+}
+
 func readResponseBody(resp *http.Response) ([]byte, error) {
 	var respBody bytes.Buffer
 	_, err := respBody.ReadFrom(resp.Body)
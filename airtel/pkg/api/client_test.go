@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+func testService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == authURL {
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599","token_type":"bearer"}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	service, err := New("test-client-id", "test-client-secret", "", SANDBOX, "KE", "KES")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	service.SetBaseURL(server.URL)
+	return service
+}
+
+func TestUssdPushRejected(t *testing.T) {
+	service := testService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{},"status":{"code":"200","message":"Not enough balance","result_code":"ESB000001","response_code":"DP00800001007","success":false}}`))
+	})
+
+	_, err := service.UssdPush("order-1", "733123456", money.FromMinor(10000, "KES"), "tx-1")
+	perr, ok := railerrors.As(err)
+	if !ok {
+		t.Fatalf("Expected ProviderError for success false, got %v", err)
+	}
+	if perr.Code != "DP00800001007" || perr.Message != "Not enough balance" {
+		t.Errorf("Expected code 'DP00800001007' and its message, got %+v", perr)
+	}
+	if perr.Category != railerrors.CategoryInsufficientFunds {
+		t.Errorf("Expected category insufficient_funds, got %s", perr.Category)
+	}
+}
+
+func TestUssdPushAccepted(t *testing.T) {
+	service := testService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"transaction":{"id":"tx-1","status":"Success."}},"status":{"code":"200","message":"SUCCESS","result_code":"ESB000010","response_code":"DP00800001006","success":true}}`))
+	})
+
+	resp, err := service.UssdPush("order-1", "733123456", money.FromMinor(10000, "KES"), "tx-1")
+	if err != nil {
+		t.Fatalf("UssdPush failed: %v", err)
+	}
+	if !resp.Status.Success || resp.Data.Transaction.ID != "tx-1" {
+		t.Errorf("Expected the accepted transaction, got %+v", resp)
+	}
+}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type CollectionRequest struct {
@@ -14,9 +17,9 @@ type CollectionRequest struct {
 		MSISDN   string `json:"msisdn"`
 	} `json:"subscriber"`
 	Transaction struct {
-		Amount     float64 `json:"amount"`
-		ID         string  `json:"id"`
-		Reference  string  `json:"reference"`
+		Amount    money.Number `json:"amount"`
+		ID        string       `json:"id"`
+		Reference string       `json:"reference"`
 	} `json:"transaction"`
 }
 
@@ -62,8 +65,8 @@ type TransactionStatusResponse struct {
 
 type RefundRequest struct {
 	Transaction struct {
-		AirtelMoneyID string  `json:"airtel_money_id"`
-		Amount        float64 `json:"amount"`
+		AirtelMoneyID string       `json:"airtel_money_id"`
+		Amount        money.Number `json:"amount"`
 	} `json:"transaction"`
 }
 
@@ -82,15 +85,19 @@ type RefundResponse struct {
 	} `json:"data"`
 }
 
-func (s *Service) UssdPush(reference, phone string, amount float64, transactionID string) (*CollectionResponse, error) {
+func (s *Service) UssdPush(reference, phone string, amount money.Money, transactionID string) (*CollectionResponse, error) {
+	return s.UssdPushWithContext(context.Background(), reference, phone, amount, transactionID)
+}
+
+func (s *Service) UssdPushWithContext(ctx context.Context, reference, phone string, amount money.Money, transactionID string) (*CollectionResponse, error) {
 	if reference == "" {
 		return nil, fmt.Errorf("reference is required")
 	}
 	if phone == "" {
 		return nil, fmt.Errorf("phone number is required")
 	}
-	if amount <= 0 {
-		return nil, fmt.Errorf("amount must be greater than zero")
+	if err := s.checkAmount(amount); err != nil {
+		return nil, err
 	}
 	if transactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
@@ -106,11 +113,11 @@ func (s *Service) UssdPush(reference, phone string, amount float64, transactionI
 	req.Subscriber.Country = s.country
 	req.Subscriber.Currency = s.currency
 	req.Subscriber.MSISDN = phone
-	req.Transaction.Amount = amount
+	req.Transaction.Amount = amount.Number()
 	req.Transaction.ID = transactionID
 	req.Transaction.Reference = reference
 
-	respBody, err := s.makeRequest(http.MethodPost, ussdPushURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, ussdPushURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate USSD Push: %w", err)
 	}
@@ -124,12 +131,16 @@ func (s *Service) UssdPush(reference, phone string, amount float64, transactionI
 }
 
 func (s *Service) GetTransactionStatus(transactionID string) (*TransactionStatusResponse, error) {
+	return s.GetTransactionStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) GetTransactionStatusWithContext(ctx context.Context, transactionID string) (*TransactionStatusResponse, error) {
 	if transactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
 	}
 
 	url := transactionStatusURL + transactionID
-	respBody, err := s.makeRequest(http.MethodGet, url, nil)
+	respBody, err := s.makeRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction status: %w", err)
 	}
@@ -142,19 +153,23 @@ func (s *Service) GetTransactionStatus(transactionID string) (*TransactionStatus
 	return &response, nil
 }
 
-func (s *Service) RefundTransaction(airtelMoneyID string, amount float64) (*RefundResponse, error) {
+func (s *Service) RefundTransaction(airtelMoneyID string, amount money.Money) (*RefundResponse, error) {
+	return s.RefundTransactionWithContext(context.Background(), airtelMoneyID, amount)
+}
+
+func (s *Service) RefundTransactionWithContext(ctx context.Context, airtelMoneyID string, amount money.Money) (*RefundResponse, error) {
 	if airtelMoneyID == "" {
 		return nil, fmt.Errorf("airtel Money ID is required")
 	}
-	if amount <= 0 {
-		return nil, fmt.Errorf("amount must be greater than zero")
+	if err := s.checkAmount(amount); err != nil {
+		return nil, err
 	}
 
 	req := RefundRequest{}
 	req.Transaction.AirtelMoneyID = airtelMoneyID
-	req.Transaction.Amount = amount
+	req.Transaction.Amount = amount.Number()
 
-	respBody, err := s.makeRequest(http.MethodPost, refundURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, refundURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate refund: %w", err)
 	}
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type DisbursementRequest struct {
@@ -15,9 +18,9 @@ type DisbursementRequest struct {
 		MSISDN   string `json:"msisdn"`
 	} `json:"subscriber"`
 	Transaction struct {
-		Amount     float64 `json:"amount"`
-		ID         string  `json:"id"`
-		Reference  string  `json:"reference"`
+		Amount    money.Number `json:"amount"`
+		ID        string       `json:"id"`
+		Reference string       `json:"reference"`
 	} `json:"transaction"`
 }
 
@@ -61,15 +64,19 @@ type DisbursementStatusResponse struct {
 	} `json:"data"`
 }
 
-func (s *Service) Disburse(reference, phone string, amount float64, transactionID string, pin string) (*DisbursementResponse, error) {
+func (s *Service) Disburse(reference, phone string, amount money.Money, transactionID string, pin string) (*DisbursementResponse, error) {
+	return s.DisburseWithContext(context.Background(), reference, phone, amount, transactionID, pin)
+}
+
+func (s *Service) DisburseWithContext(ctx context.Context, reference, phone string, amount money.Money, transactionID string, pin string) (*DisbursementResponse, error) {
 	if reference == "" {
 		return nil, fmt.Errorf("reference is required")
 	}
 	if phone == "" {
 		return nil, fmt.Errorf("phone number is required")
 	}
-	if amount <= 0 {
-		return nil, fmt.Errorf("amount must be greater than zero")
+	if err := s.checkAmount(amount); err != nil {
+		return nil, err
 	}
 	if transactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
@@ -86,11 +93,11 @@ func (s *Service) Disburse(reference, phone string, amount float64, transactionI
 	req.Subscriber.Country = s.country
 	req.Subscriber.Currency = s.currency
 	req.Subscriber.MSISDN = phone
-	req.Transaction.Amount = amount
+	req.Transaction.Amount = amount.Number()
 	req.Transaction.ID = transactionID
 	req.Transaction.Reference = reference
 
-	respBody, err := s.makeRequest(http.MethodPost, disburseURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, disburseURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate disbursement: %w", err)
 	}
@@ -104,12 +111,16 @@ func (s *Service) Disburse(reference, phone string, amount float64, transactionI
 }
 
 func (s *Service) GetDisbursementStatus(transactionID string) (*DisbursementStatusResponse, error) {
+	return s.GetDisbursementStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) GetDisbursementStatusWithContext(ctx context.Context, transactionID string) (*DisbursementStatusResponse, error) {
 	if transactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
 	}
 
 	url := disbursementStatusURL + transactionID
-	respBody, err := s.makeRequest(http.MethodGet, url, nil)
+	respBody, err := s.makeRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disbursement status: %w", err)
 	}
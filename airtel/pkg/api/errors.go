@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "airtel"
+
+// ErrorResponse is the body Airtel sends with a rejected request. The OAuth
+// endpoint uses Error and ErrorDescription instead of Status.
+type ErrorResponse struct {
+	Status struct {
+		Code         string `json:"code"`
+		Message      string `json:"message"`
+		ResultCode   string `json:"result_code"`
+		ResponseCode string `json:"response_code"`
+		Success      bool   `json:"success"`
+	} `json:"status"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// responseCodeCategories maps Airtel response codes to a category.
+var responseCodeCategories = map[string]railerrors.Category{
+	"DP00800001002": railerrors.CategoryValidation,        // Incorrect PIN
+	"DP00800001003": railerrors.CategoryValidation,        // Exceeds withdrawal amount limit
+	"DP00800001004": railerrors.CategoryValidation,        // Invalid amount
+	"DP00800001007": railerrors.CategoryInsufficientFunds, // Not enough balance
+	"DP00800001010": railerrors.CategoryValidation,        // Transaction not permitted to payee
+	"DP00800001025": railerrors.CategoryNotFound,          // Transaction not found
+}
+
+// rejected reports whether body has a status.success of false. Airtel answers
+// many rejected requests, such as one from a wallet without enough balance,
+// with HTTP 200 and the reason in status.response_code.
+func rejected(body []byte) bool {
+	var resp struct {
+		Status *struct {
+			Success *bool `json:"success"`
+		} `json:"status"`
+	}
+	if json.Unmarshal(body, &resp) != nil || resp.Status == nil || resp.Status.Success == nil {
+		return false
+	}
+	return !*resp.Status.Success
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	json.Unmarshal(body, &errResp)
+
+	code := errResp.Status.ResponseCode
+	if code == "" {
+		code = errResp.Status.ResultCode
+	}
+	if code == "" {
+		code = errResp.Error
+	}
+	message := errResp.Status.Message
+	if message == "" {
+		message = errResp.ErrorDescription
+	}
+
+	perr := railerrors.FromResponse(provider, resp, code, message)
+	if category, ok := responseCodeCategories[code]; ok {
+		perr.SetCategory(category)
+	}
+	return perr
+}
@@ -1,11 +1,19 @@
 package coop
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/nutcas3/payment-rails/coop/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -39,11 +47,49 @@ func NewClient(clientID, clientSecret string, environment api.Environment) (*Cli
 	}, nil
 }
 
+// GenerateReference returns a unique message reference, see
+// idempotency.NewReference.
 func GenerateReference() string {
-	return fmt.Sprintf("COOP-%d", time.Now().UnixNano())
+	return idempotency.NewReference("COOP-")
+}
+
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.apiClient.SetHttpClient(httpClient)
+}
+
+func (c *Client) SetBaseURL(baseURL string) {
+	c.apiClient.SetBaseURL(baseURL)
+}
+
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.apiClient.SetTokenStore(store)
+}
+
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.apiClient.SetTokenSkew(skew)
+}
+
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.apiClient.SetRetryPolicy(policy)
+}
+
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.apiClient.SetCircuitBreaker(settings)
+}
+
+func (c *Client) Health() breaker.Health {
+	return c.apiClient.Health()
+}
+
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.apiClient.Use(middleware...)
 }
 
 func (c *Client) AccountBalance(accountNumber string) (*api.AccountBalanceResponse, error) {
+	return c.AccountBalanceWithContext(context.Background(), accountNumber)
+}
+
+func (c *Client) AccountBalanceWithContext(ctx context.Context, accountNumber string) (*api.AccountBalanceResponse, error) {
 	req := api.AccountBalanceRequest{
 		BaseRequest: api.BaseRequest{
 			MessageReference: GenerateReference(),
@@ -51,10 +97,14 @@ func (c *Client) AccountBalance(accountNumber string) (*api.AccountBalanceRespon
 		AccountNumber: accountNumber,
 	}
 
-	return c.apiClient.AccountBalance(req)
+	return c.apiClient.AccountBalanceWithContext(ctx, req)
 }
 
 func (c *Client) AccountTransactions(accountNumber string, noOfTransactions string) (*api.AccountTransactionsResponse, error) {
+	return c.AccountTransactionsWithContext(context.Background(), accountNumber, noOfTransactions)
+}
+
+func (c *Client) AccountTransactionsWithContext(ctx context.Context, accountNumber string, noOfTransactions string) (*api.AccountTransactionsResponse, error) {
 	if noOfTransactions == "" {
 		noOfTransactions = "10" // Default to 10 transactions
 	}
@@ -67,10 +117,14 @@ func (c *Client) AccountTransactions(accountNumber string, noOfTransactions stri
 		NoOfTransactions: noOfTransactions,
 	}
 
-	return c.apiClient.AccountTransactions(req)
+	return c.apiClient.AccountTransactionsWithContext(ctx, req)
 }
 
 func (c *Client) ExchangeRate(fromCurrency, toCurrency string) (*api.ExchangeRateResponse, error) {
+	return c.ExchangeRateWithContext(context.Background(), fromCurrency, toCurrency)
+}
+
+func (c *Client) ExchangeRateWithContext(ctx context.Context, fromCurrency, toCurrency string) (*api.ExchangeRateResponse, error) {
 	if fromCurrency == "" {
 		fromCurrency = "KES"
 	}
@@ -86,12 +140,16 @@ func (c *Client) ExchangeRate(fromCurrency, toCurrency string) (*api.ExchangeRat
 		ToCurrencyCode:   toCurrency,
 	}
 
-	return c.apiClient.ExchangeRate(req)
+	return c.apiClient.ExchangeRateWithContext(ctx, req)
+}
+
+func (c *Client) InternalFundsTransfer(sourceAccount string, amount money.Money, narration string, destinations []api.Destination) (*api.IFTResponse, error) {
+	return c.InternalFundsTransferWithContext(context.Background(), sourceAccount, amount, narration, destinations)
 }
 
-func (c *Client) InternalFundsTransfer(sourceAccount string, amount float64, currency, narration string, destinations []api.Destination) (*api.IFTResponse, error) {
-	if currency == "" {
-		currency = "KES"
+func (c *Client) InternalFundsTransferWithContext(ctx context.Context, sourceAccount string, amount money.Money, narration string, destinations []api.Destination) (*api.IFTResponse, error) {
+	if amount.Currency == "" {
+		amount.Currency = money.KES
 	}
 	if narration == "" {
 		narration = "Internal Transfer"
@@ -102,18 +160,22 @@ func (c *Client) InternalFundsTransfer(sourceAccount string, amount float64, cur
 			MessageReference: GenerateReference(),
 		},
 		AccountNumber:       sourceAccount,
-		Amount:              amount,
-		TransactionCurrency: currency,
+		Amount:              amount.Number(),
+		TransactionCurrency: amount.Currency,
 		Narration:           narration,
 		Destinations:        destinations,
 	}
 
-	return c.apiClient.InternalFundsTransfer(req)
+	return c.apiClient.InternalFundsTransferWithContext(ctx, req)
 }
 
-func (c *Client) PesaLinkTransfer(sourceAccount string, amount float64, currency, narration string, destinations []api.PesaLinkDestination) (*api.PesaLinkResponse, error) {
-	if currency == "" {
-		currency = "KES"
+func (c *Client) PesaLinkTransfer(sourceAccount string, amount money.Money, narration string, destinations []api.PesaLinkDestination) (*api.PesaLinkResponse, error) {
+	return c.PesaLinkTransferWithContext(context.Background(), sourceAccount, amount, narration, destinations)
+}
+
+func (c *Client) PesaLinkTransferWithContext(ctx context.Context, sourceAccount string, amount money.Money, narration string, destinations []api.PesaLinkDestination) (*api.PesaLinkResponse, error) {
+	if amount.Currency == "" {
+		amount.Currency = money.KES
 	}
 	if narration == "" {
 		narration = "PesaLink Transfer"
@@ -124,21 +186,25 @@ func (c *Client) PesaLinkTransfer(sourceAccount string, amount float64, currency
 			MessageReference: GenerateReference(),
 		},
 		AccountNumber:       sourceAccount,
-		Amount:              amount,
-		TransactionCurrency: currency,
+		Amount:              amount.Number(),
+		TransactionCurrency: amount.Currency,
 		Narration:           narration,
 		Destinations:        destinations,
 	}
 
-	return c.apiClient.PesaLinkSendToAccount(req)
+	return c.apiClient.PesaLinkSendToAccountWithContext(ctx, req)
 }
 
 func (c *Client) TransactionStatus(messageReference string) (*api.TransactionStatusResponse, error) {
+	return c.TransactionStatusWithContext(context.Background(), messageReference)
+}
+
+func (c *Client) TransactionStatusWithContext(ctx context.Context, messageReference string) (*api.TransactionStatusResponse, error) {
 	req := api.TransactionStatusRequest{
 		BaseRequest: api.BaseRequest{
 			MessageReference: messageReference,
 		},
 	}
 
-	return c.apiClient.TransactionStatus(req)
+	return c.apiClient.TransactionStatusWithContext(ctx, req)
 }
@@ -2,12 +2,18 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -23,8 +29,9 @@ type Client struct {
 	environment  Environment
 	baseURL      string
 	httpClient   *http.Client
-	accessToken  string
-	tokenExpiry  time.Time
+	tokens       *auth.TokenManager
+	retry        retry.Policy
+	breaker      *breaker.Breaker
 }
 
 func NewClient(clientID, clientSecret string, environment Environment) (*Client, error) {
@@ -56,6 +63,9 @@ func NewClient(clientID, clientSecret string, environment Environment) (*Client,
 			Timeout:   30 * time.Second,
 		},
 	}
+	client.tokens = auth.NewTokenManager(nil, auth.Key("coop", string(environment), clientID), client.fetchToken)
+	client.retry = retry.DefaultPolicy()
+	client.breaker = breaker.New(provider, breaker.DefaultSettings())
 
 	return client, nil
 }
@@ -64,11 +74,57 @@ func (c *Client) SetHttpClient(httpClient *http.Client) {
 	c.httpClient = httpClient
 }
 
-func (c *Client) authenticate() error {
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		return nil
-	}
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server. Tokens fetched from
+// baseURL are stored apart from the environment's.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+	c.tokens.SetKey(auth.Key("coop", baseURL, c.clientID))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same client ID reuse one token instead of each requesting their own.
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.tokens.SetStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.httpClient.Do)
+}
 
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.httpClient = transport.Wrap(c.httpClient, provider, middleware...)
+}
+
+func (c *Client) fetchToken(ctx context.Context) (auth.Token, error) {
 	authData := map[string]string{
 		"grant_type":    "client_credentials",
 		"client_id":     c.clientID,
@@ -77,69 +133,72 @@ func (c *Client) authenticate() error {
 
 	jsonData, err := json.Marshal(authData)
 	if err != nil {
-		return fmt.Errorf("failed to marshal auth data: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to marshal auth data: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/token", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/token", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return fmt.Errorf("failed to authenticate: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to authenticate: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("authentication failed with status %d: %s", resp.StatusCode, string(body))
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 
 	var authResp struct {
-		AccessToken string `json:"access_token"`
-		ExpiresIn   int    `json:"expires_in"`
-		TokenType   string `json:"token_type"`
+		AccessToken string         `json:"access_token"`
+		ExpiresIn   auth.ExpiresIn `json:"expires_in"`
+		TokenType   string         `json:"token_type"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return fmt.Errorf("failed to decode auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
-	c.accessToken = authResp.AccessToken
-	c.tokenExpiry = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
-
-	return nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
-func (c *Client) makeRequest(method, endpoint string, payload interface{}) (*http.Response, error) {
-	if err := c.authenticate(); err != nil {
-		return nil, err
-	}
-
-	var body io.Reader
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload interface{}) (*http.Response, error) {
+	var jsonData []byte
 	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+		var err error
+		jsonData, err = json.Marshal(payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal payload: %w", err)
 		}
-		body = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+endpoint, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.tokens.Do(ctx, func(token string) (*http.Response, error) {
+		var body io.Reader
+		if jsonData != nil {
+			body = bytes.NewBuffer(jsonData)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := c.retry.Do(req, c.roundTrip)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
 
-	return resp, nil
+		return resp, nil
+	})
 }
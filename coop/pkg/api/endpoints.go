@@ -1,14 +1,21 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 func (c *Client) AccountBalance(req AccountBalanceRequest) (*AccountBalanceResponse, error) {
-	resp, err := c.makeRequest("POST", "/AccountBalance", req)
+	return c.AccountBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) AccountBalanceWithContext(ctx context.Context, req AccountBalanceRequest) (*AccountBalanceResponse, error) {
+	resp, err := c.makeRequest(retry.WithIdempotent(ctx), "POST", "/AccountBalance", req)
 	if err != nil {
 		return nil, err
 	}
@@ -16,7 +23,7 @@ func (c *Client) AccountBalance(req AccountBalanceRequest) (*AccountBalanceRespo
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("account balance request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response AccountBalanceResponse
@@ -28,7 +35,11 @@ func (c *Client) AccountBalance(req AccountBalanceRequest) (*AccountBalanceRespo
 }
 
 func (c *Client) AccountTransactions(req AccountTransactionsRequest) (*AccountTransactionsResponse, error) {
-	resp, err := c.makeRequest("POST", "/AccountTransactions", req)
+	return c.AccountTransactionsWithContext(context.Background(), req)
+}
+
+func (c *Client) AccountTransactionsWithContext(ctx context.Context, req AccountTransactionsRequest) (*AccountTransactionsResponse, error) {
+	resp, err := c.makeRequest(retry.WithIdempotent(ctx), "POST", "/AccountTransactions", req)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +47,7 @@ func (c *Client) AccountTransactions(req AccountTransactionsRequest) (*AccountTr
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("account transactions request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response AccountTransactionsResponse
@@ -48,7 +59,11 @@ func (c *Client) AccountTransactions(req AccountTransactionsRequest) (*AccountTr
 }
 
 func (c *Client) ExchangeRate(req ExchangeRateRequest) (*ExchangeRateResponse, error) {
-	resp, err := c.makeRequest("POST", "/ExchangeRate", req)
+	return c.ExchangeRateWithContext(context.Background(), req)
+}
+
+func (c *Client) ExchangeRateWithContext(ctx context.Context, req ExchangeRateRequest) (*ExchangeRateResponse, error) {
+	resp, err := c.makeRequest(retry.WithIdempotent(ctx), "POST", "/ExchangeRate", req)
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +71,7 @@ func (c *Client) ExchangeRate(req ExchangeRateRequest) (*ExchangeRateResponse, e
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("exchange rate request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response ExchangeRateResponse
@@ -68,7 +83,11 @@ func (c *Client) ExchangeRate(req ExchangeRateRequest) (*ExchangeRateResponse, e
 }
 
 func (c *Client) InternalFundsTransfer(req IFTRequest) (*IFTResponse, error) {
-	resp, err := c.makeRequest("POST", "/IFTAccountToAccount", req)
+	return c.InternalFundsTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) InternalFundsTransferWithContext(ctx context.Context, req IFTRequest) (*IFTResponse, error) {
+	resp, err := c.makeRequest(ctx, "POST", "/IFTAccountToAccount", req)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +95,7 @@ func (c *Client) InternalFundsTransfer(req IFTRequest) (*IFTResponse, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("internal funds transfer request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response IFTResponse
@@ -88,7 +107,11 @@ func (c *Client) InternalFundsTransfer(req IFTRequest) (*IFTResponse, error) {
 }
 
 func (c *Client) PesaLinkSendToAccount(req PesaLinkRequest) (*PesaLinkResponse, error) {
-	resp, err := c.makeRequest("POST", "/PesaLinkSendToAccount", req)
+	return c.PesaLinkSendToAccountWithContext(context.Background(), req)
+}
+
+func (c *Client) PesaLinkSendToAccountWithContext(ctx context.Context, req PesaLinkRequest) (*PesaLinkResponse, error) {
+	resp, err := c.makeRequest(ctx, "POST", "/PesaLinkSendToAccount", req)
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +119,7 @@ func (c *Client) PesaLinkSendToAccount(req PesaLinkRequest) (*PesaLinkResponse,
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("pesalink transfer request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response PesaLinkResponse
@@ -108,7 +131,11 @@ func (c *Client) PesaLinkSendToAccount(req PesaLinkRequest) (*PesaLinkResponse,
 }
 
 func (c *Client) TransactionStatus(req TransactionStatusRequest) (*TransactionStatusResponse, error) {
-	resp, err := c.makeRequest("POST", "/TransactionStatus", req)
+	return c.TransactionStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) TransactionStatusWithContext(ctx context.Context, req TransactionStatusRequest) (*TransactionStatusResponse, error) {
+	resp, err := c.makeRequest(ctx, "POST", "/TransactionStatus", req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +143,7 @@ func (c *Client) TransactionStatus(req TransactionStatusRequest) (*TransactionSt
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("transaction status request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newProviderError(resp, body)
 	}
 
 	var response TransactionStatusResponse
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "coop"
+
+// errorBody covers the bodies Co-op sends with a rejected request: a
+// BaseResponse from the API, an OAuth error from the token endpoint, or a
+// fault from the gateway in front of both.
+type errorBody struct {
+	BaseResponse
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Fault            struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"fault"`
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errBody errorBody
+	if err := json.Unmarshal(body, &errBody); err != nil {
+		return railerrors.FromResponse(provider, resp, "", string(body))
+	}
+
+	code, message := errBody.ResponseCode, errBody.ResponseMessage
+	switch {
+	case errBody.Error != "":
+		code, message = errBody.Error, errBody.ErrorDescription
+	case errBody.Fault.Code != 0:
+		code, message = strconv.Itoa(errBody.Fault.Code), errBody.Fault.Message
+	}
+
+	perr := railerrors.FromResponse(provider, resp, code, message)
+	if perr.RequestID == "" {
+		perr.RequestID = errBody.MessageReference
+	}
+	return perr
+}
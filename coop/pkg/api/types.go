@@ -1,6 +1,10 @@
 package api
 
-import "time"
+import (
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+)
 
 type BaseRequest struct {
 	MessageReference string `json:"messageReference"`
@@ -19,14 +23,14 @@ type AccountBalanceRequest struct {
 
 type AccountBalanceResponse struct {
 	BaseResponse
-	AccountNumber    string  `json:"accountNumber"`
-	AccountName      string  `json:"accountName"`
-	Currency         string  `json:"currency"`
-	ProductName      string  `json:"productName"`
-	ClearedBalance   float64 `json:"clearedBalance"`
-	BookedBalance    float64 `json:"bookedBalance"`
-	UnclearedBalance float64 `json:"unclearedBalance"`
-	BlockedBalance   float64 `json:"blockedBalance"`
+	AccountNumber    string         `json:"accountNumber"`
+	AccountName      string         `json:"accountName"`
+	Currency         money.Currency `json:"currency"`
+	ProductName      string         `json:"productName"`
+	ClearedBalance   money.Number   `json:"clearedBalance"`
+	BookedBalance    money.Number   `json:"bookedBalance"`
+	UnclearedBalance money.Number   `json:"unclearedBalance"`
+	BlockedBalance   money.Number   `json:"blockedBalance"`
 }
 
 type AccountTransactionsRequest struct {
@@ -36,28 +40,28 @@ type AccountTransactionsRequest struct {
 }
 
 type Transaction struct {
-	TransactionID       string    `json:"transactionId"`
-	TransactionDate     time.Time `json:"transactionDate"`
-	ValueDate           time.Time `json:"valueDate"`
-	Narration           string    `json:"narration"`
-	TransactionType     string    `json:"transactionType"`
-	ServicePoint        string    `json:"servicePoint"`
-	AccountNumber       string    `json:"accountNumber"`
-	Currency            string    `json:"currency"`
-	Amount              float64   `json:"amount"`
-	SerialNumber        string    `json:"serialNumber"`
-	DebitCreditIndicator string   `json:"debitCreditIndicator"`
-	RunningClearedBalance float64 `json:"runningClearedBalance"`
-	RunningBookBalance   float64   `json:"runningBookBalance"`
+	TransactionID         string         `json:"transactionId"`
+	TransactionDate       time.Time      `json:"transactionDate"`
+	ValueDate             time.Time      `json:"valueDate"`
+	Narration             string         `json:"narration"`
+	TransactionType       string         `json:"transactionType"`
+	ServicePoint          string         `json:"servicePoint"`
+	AccountNumber         string         `json:"accountNumber"`
+	Currency              money.Currency `json:"currency"`
+	Amount                money.Number   `json:"amount"`
+	SerialNumber          string         `json:"serialNumber"`
+	DebitCreditIndicator  string         `json:"debitCreditIndicator"`
+	RunningClearedBalance money.Number   `json:"runningClearedBalance"`
+	RunningBookBalance    money.Number   `json:"runningBookBalance"`
 }
 
 type AccountTransactionsResponse struct {
 	BaseResponse
-	AccountNumber string        `json:"accountNumber"`
-	AccountName   string        `json:"accountName"`
-	Currency      string        `json:"currency"`
-	ProductName   string        `json:"productName"`
-	Transactions  []Transaction `json:"transactions"`
+	AccountNumber string         `json:"accountNumber"`
+	AccountName   string         `json:"accountName"`
+	Currency      money.Currency `json:"currency"`
+	ProductName   string         `json:"productName"`
+	Transactions  []Transaction  `json:"transactions"`
 }
 
 type ExchangeRateRequest struct {
@@ -77,20 +81,20 @@ type ExchangeRateResponse struct {
 }
 
 type Destination struct {
-	ReferenceNumber   string  `json:"referenceNumber"`
-	AccountNumber     string  `json:"accountNumber"`
-	Amount            float64 `json:"amount"`
-	TransactionCurrency string `json:"transactionCurrency"`
-	Narration         string  `json:"narration"`
+	ReferenceNumber     string         `json:"referenceNumber"`
+	AccountNumber       string         `json:"accountNumber"`
+	Amount              money.Number   `json:"amount"`
+	TransactionCurrency money.Currency `json:"transactionCurrency"`
+	Narration           string         `json:"narration"`
 }
 
 type IFTRequest struct {
 	BaseRequest
-	AccountNumber       string        `json:"accountNumber"`
-	Amount              float64       `json:"amount"`
-	TransactionCurrency string        `json:"transactionCurrency"`
-	Narration           string        `json:"narration"`
-	Destinations        []Destination `json:"destinations"`
+	AccountNumber       string         `json:"accountNumber"`
+	Amount              money.Number   `json:"amount"`
+	TransactionCurrency money.Currency `json:"transactionCurrency"`
+	Narration           string         `json:"narration"`
+	Destinations        []Destination  `json:"destinations"`
 }
 
 type IFTResponse struct {
@@ -99,19 +103,19 @@ type IFTResponse struct {
 }
 
 type PesaLinkDestination struct {
-	ReferenceNumber     string  `json:"referenceNumber"`
-	DestinationBank     string  `json:"destinationBank"`
-	AccountNumber       string  `json:"accountNumber"`
-	Amount              float64 `json:"amount"`
-	TransactionCurrency string  `json:"transactionCurrency"`
-	Narration           string  `json:"narration"`
+	ReferenceNumber     string         `json:"referenceNumber"`
+	DestinationBank     string         `json:"destinationBank"` // Bank code, see banks.Find in rails/banks
+	AccountNumber       string         `json:"accountNumber"`
+	Amount              money.Number   `json:"amount"`
+	TransactionCurrency money.Currency `json:"transactionCurrency"`
+	Narration           string         `json:"narration"`
 }
 
 type PesaLinkRequest struct {
 	BaseRequest
 	AccountNumber       string                `json:"accountNumber"`
-	Amount              float64               `json:"amount"`
-	TransactionCurrency string                `json:"transactionCurrency"`
+	Amount              money.Number          `json:"amount"`
+	TransactionCurrency money.Currency        `json:"transactionCurrency"`
 	Narration           string                `json:"narration"`
 	Destinations        []PesaLinkDestination `json:"destinations"`
 }
@@ -127,8 +131,8 @@ type TransactionStatusRequest struct {
 
 type TransactionStatusResponse struct {
 	BaseResponse
-	TransactionID     string `json:"transactionId"`
-	TransactionStatus string `json:"transactionStatus"`
-	TransactionAmount float64 `json:"transactionAmount"`
-	TransactionDate   time.Time `json:"transactionDate"`
+	TransactionID     string       `json:"transactionId"`
+	TransactionStatus string       `json:"transactionStatus"`
+	TransactionAmount money.Number `json:"transactionAmount"`
+	TransactionDate   time.Time    `json:"transactionDate"`
 }
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/nutcas3/payment-rails/airtel/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -32,14 +34,15 @@ func main() {
 	if err != nil {
 		log.Printf("Failed to get account balance: %v", err)
 	} else {
-		fmt.Printf("Account Balance: %.2f %s\n", balance.Data.Balance, balance.Data.Currency)
+		fmt.Printf("Account Balance: %s %s\n", balance.Data.Balance, balance.Data.Currency)
 	}
 
 	// Example 2: USSD Push (Collection)
 	fmt.Println("\nInitiating USSD Push payment...")
 	reference := "TEST-REF-" + time.Now().Format("20060102150405")
 	phone := "700000000" // Replace with actual phone number without country code
-	amount := 10.0       // Amount in the specified currency
+	// Amount in the client's currency
+	amount := money.New(decimal.NewFromInt(10), money.KES)
 	txID := "TX-" + time.Now().Format("20060102150405")
 
 	collectionResp, err := airtel.UssdPush(reference, phone, amount, txID)
@@ -5,6 +5,8 @@ import (
 	"log"
 	"github.com/nutcas3/payment-rails/coop"
 	"github.com/nutcas3/payment-rails/coop/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -23,8 +25,8 @@ func main() {
 		fmt.Printf("Account: %s\n", balance.AccountNumber)
 		fmt.Printf("Account Name: %s\n", balance.AccountName)
 		fmt.Printf("Currency: %s\n", balance.Currency)
-		fmt.Printf("Cleared Balance: %.2f\n", balance.ClearedBalance)
-		fmt.Printf("Booked Balance: %.2f\n", balance.BookedBalance)
+		fmt.Printf("Cleared Balance: %s\n", balance.ClearedBalance)
+		fmt.Printf("Booked Balance: %s\n", balance.BookedBalance)
 		fmt.Printf("Product: %s\n", balance.ProductName)
 	}
 
@@ -37,7 +39,7 @@ func main() {
 		fmt.Printf("Account: %s (%s)\n", transactions.AccountNumber, transactions.AccountName)
 		fmt.Printf("Found %d transactions:\n", len(transactions.Transactions))
 		for i, txn := range transactions.Transactions {
-			fmt.Printf("  %d. Date: %s, Amount: %.2f %s, Type: %s\n",
+			fmt.Printf("  %d. Date: %s, Amount: %s %s, Type: %s\n",
 				i+1, txn.TransactionDate.Format("2006-01-02"), txn.Amount, txn.Currency, txn.TransactionType)
 			fmt.Printf("     Narration: %s\n", txn.Narration)
 		}
@@ -61,14 +63,14 @@ func main() {
 		{
 			ReferenceNumber:     "REF001",
 			AccountNumber:       "0987654321",
-			Amount:              1000.00,
+			Amount:              money.NewNumber(decimal.NewFromInt(1000)),
 			TransactionCurrency: "KES",
 			Narration:           "Payment for services",
 		},
 		{
 			ReferenceNumber:     "REF002",
 			AccountNumber:       "1122334455",
-			Amount:              500.00,
+			Amount:              money.NewNumber(decimal.NewFromInt(500)),
 			TransactionCurrency: "KES",
 			Narration:           "Salary payment",
 		},
@@ -76,8 +78,7 @@ func main() {
 
 	iftResponse, err := client.InternalFundsTransfer(
 		"1234567890",     // source account
-		1500.00,          // total amount
-		"KES",            // currency
+		money.New(decimal.NewFromInt(1500), money.KES), // total amount
 		"Bulk transfer",  // narration
 		destinations,
 	)
@@ -97,7 +98,7 @@ func main() {
 			ReferenceNumber:     "PL001",
 			DestinationBank:     "01", // KCB Bank code
 			AccountNumber:       "1122334455",
-			Amount:              2000.00,
+			Amount:              money.NewNumber(decimal.NewFromInt(2000)),
 			TransactionCurrency: "KES",
 			Narration:           "Payment to supplier",
 		},
@@ -105,8 +106,7 @@ func main() {
 
 	pesaLinkResponse, err := client.PesaLinkTransfer(
 		"1234567890",         // source account
-		2000.00,              // total amount
-		"KES",                // currency
+		money.New(decimal.NewFromInt(2000), money.KES), // total amount
 		"PesaLink payment",   // narration
 		pesaLinkDestinations,
 	)
@@ -129,7 +129,7 @@ func main() {
 		} else {
 			fmt.Printf("Transaction Status: %s\n", status.TransactionStatus)
 			fmt.Printf("Transaction ID: %s\n", status.TransactionID)
-			fmt.Printf("Amount: %.2f\n", status.TransactionAmount)
+			fmt.Printf("Amount: %s\n", status.TransactionAmount)
 			fmt.Printf("Date: %s\n", status.TransactionDate.Format("2006-01-02 15:04:05"))
 		}
 	}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"github.com/nutcas3/payment-rails/kcb"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 	"time"
 )
 
@@ -49,7 +51,7 @@ func accountExamples(client *kcb.Client) {
 	} else {
 		fmt.Printf("  Account Number: %s\n", accountInfo.Data.AccountNumber)
 		fmt.Printf("  Account Name: %s\n", accountInfo.Data.AccountName)
-		fmt.Printf("  Balance: %.2f %s\n", accountInfo.Data.Balance, accountInfo.Data.Currency)
+		fmt.Printf("  Balance: %s %s\n", accountInfo.Data.Balance, accountInfo.Data.Currency)
 		fmt.Printf("  Account Type: %s\n", accountInfo.Data.AccountType)
 		fmt.Printf("  Branch: %s\n", accountInfo.Data.Branch)
 		fmt.Printf("  Status: %s\n", accountInfo.Data.Status)
@@ -63,7 +65,7 @@ func accountExamples(client *kcb.Client) {
 	} else {
 		fmt.Printf("  Account Number: %s\n", balance.Data.AccountNumber)
 		fmt.Printf("  Account Name: %s\n", balance.Data.AccountName)
-		fmt.Printf("  Balance: %.2f %s\n", balance.Data.Balance, balance.Data.Currency)
+		fmt.Printf("  Balance: %s %s\n", balance.Data.Balance, balance.Data.Currency)
 		fmt.Printf("  As Of: %s\n", balance.Data.AsOf)
 	}
 
@@ -92,7 +94,7 @@ func accountExamples(client *kcb.Client) {
 
 		for i := 0; i < txCount; i++ {
 			tx := statement.Data.Transactions[i]
-			fmt.Printf("    %s: %s %.2f - %s\n",
+			fmt.Printf("    %s: %s %s - %s\n",
 				tx.TransactionDate.Format("2006-01-02"),
 				tx.Type,
 				tx.Amount,
@@ -105,8 +107,7 @@ func accountExamples(client *kcb.Client) {
 	transfer, err := client.TransferFunds(
 		"1234567890",     // Source account
 		"0987654321",     // Destination account
-		1000.0,           // Amount
-		"KES",            // Currency
+		money.New(decimal.NewFromInt(1000), money.KES), // Amount
 		"INV123456",      // Reference
 		"Invoice payment", // Narration
 	)
@@ -116,7 +117,7 @@ func accountExamples(client *kcb.Client) {
 		fmt.Printf("  Transaction ID: %s\n", transfer.Data.TransactionID)
 		fmt.Printf("  From Account: %s\n", transfer.Data.SourceAccount)
 		fmt.Printf("  To Account: %s\n", transfer.Data.DestAccount)
-		fmt.Printf("  Amount: %.2f %s\n", transfer.Data.Amount, transfer.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", transfer.Data.Amount, transfer.Data.Currency)
 		fmt.Printf("  Status: %s\n", transfer.Data.Status)
 		fmt.Printf("  Transaction Date: %s\n", transfer.Data.TransactionDate)
 		fmt.Printf("  Reference: %s\n", transfer.Data.Reference)
@@ -142,12 +143,12 @@ func forexExamples(client *kcb.Client) {
 
 	// Example 2: Exchange Currency
 	fmt.Println("\n2. Exchange Currency:")
-	exchange, err := client.ExchangeCurrency("EUR", "USD", 100.0)
+	exchange, err := client.ExchangeCurrency(money.New(decimal.NewFromInt(100), money.EUR), money.USD)
 	if err != nil {
 		log.Printf("Failed to exchange currency: %v", err)
 	} else {
-		fmt.Printf("  From: %s %.2f\n", exchange.Data.FromCurrency, exchange.Data.Amount)
-		fmt.Printf("  To: %s %.2f\n", exchange.Data.ToCurrency, exchange.Data.ConvertedAmount)
+		fmt.Printf("  From: %s %s\n", exchange.Data.FromCurrency, exchange.Data.Amount)
+		fmt.Printf("  To: %s %s\n", exchange.Data.ToCurrency, exchange.Data.ConvertedAmount)
 		fmt.Printf("  Exchange Rate: %.4f\n", exchange.Data.ExchangeRate)
 		fmt.Printf("  Timestamp: %s\n", exchange.Data.Timestamp)
 	}
@@ -158,12 +159,12 @@ func paymentExamples(client *kcb.Client) {
 
 	// Example 1: Make Vooma Payment
 	fmt.Println("\n1. Make Vooma Payment:")
-	payment, err := client.VoomaPay(100.0)
+	payment, err := client.VoomaPay(money.New(decimal.NewFromInt(100), money.KES))
 	if err != nil {
 		log.Printf("Failed to make Vooma payment: %v", err)
 	} else {
 		fmt.Printf("  Transaction ID: %s\n", payment.Data.TransactionID)
-		fmt.Printf("  Amount: %.2f %s\n", payment.Data.Amount, payment.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", payment.Data.Amount, payment.Data.Currency)
 		fmt.Printf("  Status: %s\n", payment.Data.Status)
 		fmt.Printf("  Transaction Date: %s\n", payment.Data.TransactionDate)
 		fmt.Printf("  Reference: %s\n", payment.Data.Reference)
@@ -177,7 +178,7 @@ func paymentExamples(client *kcb.Client) {
 		log.Printf("Failed to check Vooma payment status: %v", err)
 	} else {
 		fmt.Printf("  Transaction ID: %s\n", voomaStatus.Data.TransactionID)
-		fmt.Printf("  Amount: %.2f %s\n", voomaStatus.Data.Amount, voomaStatus.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", voomaStatus.Data.Amount, voomaStatus.Data.Currency)
 		fmt.Printf("  Status: %s\n", voomaStatus.Data.Status)
 		if voomaStatus.Data.StatusReason != "" {
 			fmt.Printf("  Status Reason: %s\n", voomaStatus.Data.StatusReason)
@@ -196,8 +197,7 @@ func pesalinkExamples(client *kcb.Client) {
 		"1234567890",     // Source account
 		"0987654321",     // Destination account
 		"01",             // Destination bank code (e.g., 01 for KCB)
-		1000.0,           // Amount
-		"KES",            // Currency
+		money.New(decimal.NewFromInt(1000), money.KES), // Amount
 		"INV123456",      // Reference
 		"Invoice payment", // Narration
 		"254712345678",    // Phone number
@@ -208,7 +208,7 @@ func pesalinkExamples(client *kcb.Client) {
 		fmt.Printf("  Transaction ID: %s\n", transfer.Data.TransactionID)
 		fmt.Printf("  From Account: %s\n", transfer.Data.SourceAccount)
 		fmt.Printf("  To Account: %s (Bank: %s)\n", transfer.Data.DestAccount, transfer.Data.DestBank)
-		fmt.Printf("  Amount: %.2f %s\n", transfer.Data.Amount, transfer.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", transfer.Data.Amount, transfer.Data.Currency)
 		fmt.Printf("  Status: %s\n", transfer.Data.Status)
 		fmt.Printf("  Transaction Date: %s\n", transfer.Data.TransactionDate)
 		fmt.Printf("  Reference: %s\n", transfer.Data.Reference)
@@ -224,7 +224,7 @@ func pesalinkExamples(client *kcb.Client) {
 		fmt.Printf("  Transaction ID: %s\n", pesalinkStatus.Data.TransactionID)
 		fmt.Printf("  From Account: %s\n", pesalinkStatus.Data.SourceAccount)
 		fmt.Printf("  To Account: %s (Bank: %s)\n", pesalinkStatus.Data.DestAccount, pesalinkStatus.Data.DestBank)
-		fmt.Printf("  Amount: %.2f %s\n", pesalinkStatus.Data.Amount, pesalinkStatus.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", pesalinkStatus.Data.Amount, pesalinkStatus.Data.Currency)
 		fmt.Printf("  Status: %s\n", pesalinkStatus.Data.Status)
 		if pesalinkStatus.Data.StatusReason != "" {
 			fmt.Printf("  Status Reason: %s\n", pesalinkStatus.Data.StatusReason)
@@ -242,8 +242,7 @@ func mobileMoneyExamples(client *kcb.Client) {
 	transfer, err := client.MobileMoneyTransfer(
 		"1234567890",     // Source account
 		"254712345678",   // Phone number
-		1000.0,           // Amount
-		"KES",            // Currency
+		money.New(decimal.NewFromInt(1000), money.KES), // Amount
 		"INV123456",      // Reference
 		"Invoice payment", // Narration
 		"MPESA",           // Provider
@@ -254,7 +253,7 @@ func mobileMoneyExamples(client *kcb.Client) {
 		fmt.Printf("  Transaction ID: %s\n", transfer.Data.TransactionID)
 		fmt.Printf("  From Account: %s\n", transfer.Data.SourceAccount)
 		fmt.Printf("  To Phone: %s (Provider: %s)\n", transfer.Data.PhoneNumber, transfer.Data.Provider)
-		fmt.Printf("  Amount: %.2f %s\n", transfer.Data.Amount, transfer.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", transfer.Data.Amount, transfer.Data.Currency)
 		fmt.Printf("  Status: %s\n", transfer.Data.Status)
 		fmt.Printf("  Transaction Date: %s\n", transfer.Data.TransactionDate)
 		fmt.Printf("  Reference: %s\n", transfer.Data.Reference)
@@ -270,7 +269,7 @@ func mobileMoneyExamples(client *kcb.Client) {
 		fmt.Printf("  Transaction ID: %s\n", mobileStatus.Data.TransactionID)
 		fmt.Printf("  From Account: %s\n", mobileStatus.Data.SourceAccount)
 		fmt.Printf("  To Phone: %s (Provider: %s)\n", mobileStatus.Data.PhoneNumber, mobileStatus.Data.Provider)
-		fmt.Printf("  Amount: %.2f %s\n", mobileStatus.Data.Amount, mobileStatus.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", mobileStatus.Data.Amount, mobileStatus.Data.Currency)
 		fmt.Printf("  Status: %s\n", mobileStatus.Data.Status)
 		if mobileStatus.Data.StatusReason != "" {
 			fmt.Printf("  Status Reason: %s\n", mobileStatus.Data.StatusReason)
@@ -311,8 +310,7 @@ func utilityExamples(client *kcb.Client) {
 		"1234567890",     // Source account
 		"KPLC",           // Provider ID
 		"12345678",       // Account number with provider
-		1000.0,           // Amount
-		"KES",            // Currency
+		money.New(decimal.NewFromInt(1000), money.KES), // Amount
 		"BILL123456",     // Reference
 		"254712345678",   // Phone number for notifications
 	)
@@ -323,7 +321,7 @@ func utilityExamples(client *kcb.Client) {
 		fmt.Printf("  From Account: %s\n", payment.Data.SourceAccount)
 		fmt.Printf("  Provider: %s (%s)\n", payment.Data.ProviderName, payment.Data.ProviderID)
 		fmt.Printf("  Customer Account: %s\n", payment.Data.AccountNumber)
-		fmt.Printf("  Amount: %.2f %s\n", payment.Data.Amount, payment.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", payment.Data.Amount, payment.Data.Currency)
 		fmt.Printf("  Status: %s\n", payment.Data.Status)
 		fmt.Printf("  Transaction Date: %s\n", payment.Data.TransactionDate)
 		fmt.Printf("  Reference: %s\n", payment.Data.Reference)
@@ -343,7 +341,7 @@ func utilityExamples(client *kcb.Client) {
 		fmt.Printf("  From Account: %s\n", utilityStatus.Data.SourceAccount)
 		fmt.Printf("  Provider: %s (%s)\n", utilityStatus.Data.ProviderName, utilityStatus.Data.ProviderID)
 		fmt.Printf("  Customer Account: %s\n", utilityStatus.Data.AccountNumber)
-		fmt.Printf("  Amount: %.2f %s\n", utilityStatus.Data.Amount, utilityStatus.Data.Currency)
+		fmt.Printf("  Amount: %s %s\n", utilityStatus.Data.Amount, utilityStatus.Data.Currency)
 		fmt.Printf("  Status: %s\n", utilityStatus.Data.Status)
 		if utilityStatus.Data.StatusReason != "" {
 			fmt.Printf("  Status Reason: %s\n", utilityStatus.Data.StatusReason)
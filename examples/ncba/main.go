@@ -6,6 +6,8 @@ import (
 
 	"github.com/nutcas3/payment-rails/ncba"
 	"github.com/nutcas3/payment-rails/ncba/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -35,10 +37,10 @@ func main() {
 		TransferRequest: api.TransferRequest{
 			SourceAccount:      "1234567890",
 			DestinationAccount: "0987654321",
-			Amount:            1000.00,
-			Currency:          "KES",
-			Reference:         "INV001",
-			Narration:         "Payment for services",
+			Amount:             money.NewNumber(decimal.NewFromInt(1000)),
+			Currency:           money.KES,
+			Reference:          "INV001",
+			Narration:          "Payment for services",
 		},
 		DestinationName: "John Doe",
 	}
@@ -61,10 +63,10 @@ func main() {
 		TransferRequest: api.TransferRequest{
 			SourceAccount:      "1234567890",
 			DestinationAccount: "9876543210",
-			Amount:            5000.00,
-			Currency:          "KES",
-			Reference:         "INV002",
-			Narration:         "PesaLink transfer",
+			Amount:             money.NewNumber(decimal.NewFromInt(5000)),
+			Currency:           money.KES,
+			Reference:          "INV002",
+			Narration:          "PesaLink transfer",
 		},
 		DestinationBank: "KCB",
 		PhoneNumber:     "+254712345678",
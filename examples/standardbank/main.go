@@ -8,6 +8,9 @@ import (
 
 	"payment-rails/standardbank"
 	"payment-rails/standardbank/pkg/api"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -33,7 +36,7 @@ func main() {
 	// Example 1: Create a payment
 	fmt.Println("=== Creating Payment ===")
 	payment, err := client.Payments().Create(ctx, api.PaymentRequest{
-		Amount:            1000.00,
+		Amount:            money.NewNumber(decimal.NewFromInt(1000)),
 		Currency:          "ZAR",
 		Reference:         "INV-2024-001",
 		Description:       "Payment for services",
@@ -50,7 +53,7 @@ func main() {
 		fmt.Printf("  Payment ID: %s\n", payment.PaymentID)
 		fmt.Printf("  Transaction ID: %s\n", payment.TransactionID)
 		fmt.Printf("  Status: %s\n", payment.Status)
-		fmt.Printf("  Amount: %.2f %s\n", payment.Amount, payment.Currency)
+		fmt.Printf("  Amount: %s %s\n", payment.Amount, payment.Currency)
 	}
 
 	// Example 2: Get payment status
@@ -70,7 +73,7 @@ func main() {
 	transfer, err := client.Transfers().Create(ctx, api.InternalTransferRequest{
 		SourceAccount:      "ACC001",
 		DestinationAccount: "ACC002",
-		Amount:            500.00,
+		Amount:            money.NewNumber(decimal.NewFromInt(500)),
 		Currency:          "ZAR",
 		Reference:         "TRANSFER-001",
 		Description:       "Internal account transfer",
@@ -84,7 +87,7 @@ func main() {
 		fmt.Printf("Transfer created successfully!\n")
 		fmt.Printf("  Transfer ID: %s\n", transfer.TransferID)
 		fmt.Printf("  Status: %s\n", transfer.Status)
-		fmt.Printf("  Amount: %.2f %s\n", transfer.Amount, transfer.Currency)
+		fmt.Printf("  Amount: %s %s\n", transfer.Amount, transfer.Currency)
 	}
 
 	// Example 4: List available providers
@@ -106,7 +109,7 @@ func main() {
 
 		providerPayment, err := client.Providers().Pay(ctx, api.ProviderPaymentRequest{
 			ProviderID:  providerID,
-			Amount:      200.00,
+			Amount:      money.NewNumber(decimal.NewFromInt(200)),
 			Currency:    "ZAR",
 			Reference:   "PROV-PAY-001",
 			Description: "Mobile wallet payment",
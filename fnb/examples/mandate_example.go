@@ -9,6 +9,8 @@ import (
 
 	"github.com/nutcas3/payment-rails/fnb"
 	"github.com/nutcas3/payment-rails/fnb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -82,7 +84,7 @@ func createMandateExample(ctx context.Context, client *fnb.Client) string {
 
 		// Mandate details
 		ContractReference:   "SUB-2025-12345",
-		MaximumAmount:       500.00,
+		MaximumAmount:       money.NewNumber(decimal.NewFromInt(500)),
 		Currency:            "ZAR",
 		FrequencyType:       "MONTHLY",
 		FirstCollectionDate: firstCollection.Format("2006-01-02"),
@@ -104,7 +106,7 @@ func createMandateExample(ctx context.Context, client *fnb.Client) string {
 	fmt.Printf("  Status: %s\n", resp.Status)
 	fmt.Printf("  Contract Reference: %s\n", resp.ContractReference)
 	fmt.Printf("  Debtor: %s\n", resp.DebtorName)
-	fmt.Printf("  Maximum Amount: %.2f %s\n", resp.MaximumAmount, resp.Currency)
+	fmt.Printf("  Maximum Amount: %s %s\n", resp.MaximumAmount, resp.Currency)
 	fmt.Printf("  Frequency: %s\n", resp.FrequencyType)
 	fmt.Printf("  First Collection: %s\n", resp.FirstCollectionDate)
 
@@ -132,7 +134,7 @@ func checkMandateStatusExample(ctx context.Context, client *fnb.Client, mandateI
 	fmt.Printf("  Status: %s - %s\n", status.Status, status.StatusDescription)
 	fmt.Printf("  Creditor: %s\n", status.CreditorName)
 	fmt.Printf("  Debtor: %s (%s)\n", status.DebtorName, status.DebtorAccountNumber)
-	fmt.Printf("  Maximum Amount: %.2f %s\n", status.MaximumAmount, status.Currency)
+	fmt.Printf("  Maximum Amount: %s %s\n", status.MaximumAmount, status.Currency)
 	fmt.Printf("  Frequency: %s\n", status.FrequencyType)
 	fmt.Printf("  First Collection: %s\n", status.FirstCollectionDate)
 
@@ -154,7 +156,7 @@ func verifyMandateExample(ctx context.Context, client *fnb.Client, mandateID str
 		mandateID = "MAN123456789"
 	}
 
-	amount := 250.00
+	amount := money.New(decimal.NewFromInt(250), money.ZAR)
 
 	valid, err := client.VerifyMandate(ctx, mandateID, amount)
 	if err != nil {
@@ -165,7 +167,7 @@ func verifyMandateExample(ctx context.Context, client *fnb.Client, mandateID str
 	if valid {
 		fmt.Printf("✓ Mandate is valid for collection\n")
 		fmt.Printf("  Mandate ID: %s\n", mandateID)
-		fmt.Printf("  Collection Amount: %.2f ZAR\n", amount)
+		fmt.Printf("  Collection Amount: %s\n", amount)
 		fmt.Printf("  Status: Ready to collect\n")
 	} else {
 		fmt.Printf("✗ Mandate is NOT valid for collection\n")
@@ -180,7 +182,7 @@ func collectAgainstMandateExample(ctx context.Context, client *fnb.Client, manda
 	}
 
 	// First verify the mandate
-	valid, err := client.VerifyMandate(ctx, mandateID, 250.00)
+	valid, err := client.VerifyMandate(ctx, mandateID, money.New(decimal.NewFromInt(250), money.ZAR))
 	if err != nil {
 		log.Printf("Verification failed: %v", err)
 		return
@@ -194,7 +196,7 @@ func collectAgainstMandateExample(ctx context.Context, client *fnb.Client, manda
 	// Proceed with collection
 	req := api.MandateCollectionRequest{
 		MandateID:           mandateID,
-		Amount:              250.00,
+		Amount:              money.NewNumber(decimal.NewFromInt(250)),
 		CollectionReference: fmt.Sprintf("COLL-%s", time.Now().Format("20060102")),
 		Description:         fmt.Sprintf("Monthly subscription - %s", time.Now().Format("January 2006")),
 		IdempotencyKey:      fmt.Sprintf("coll-%s-%s", mandateID, time.Now().Format("20060102")),
@@ -210,7 +212,7 @@ func collectAgainstMandateExample(ctx context.Context, client *fnb.Client, manda
 	fmt.Printf("  Transaction ID: %s\n", resp.TransactionID)
 	fmt.Printf("  Status: %s\n", resp.Status)
 	fmt.Printf("  Collection Reference: %s\n", resp.CollectionReference)
-	fmt.Printf("  Amount: %.2f %s\n", resp.Amount, resp.Currency)
+	fmt.Printf("  Amount: %s %s\n", resp.Amount, resp.Currency)
 	fmt.Printf("  Processing Date: %s\n", resp.ProcessingDate.Format("2006-01-02"))
 }
 
@@ -234,7 +236,7 @@ func listMandatesExample(ctx context.Context, client *fnb.Client) {
 		fmt.Printf("%d. %s\n", i+1, mandate.ContractReference)
 		fmt.Printf("   Mandate ID: %s\n", mandate.MandateID)
 		fmt.Printf("   Debtor: %s\n", mandate.DebtorName)
-		fmt.Printf("   Amount: %.2f %s (%s)\n", mandate.MaximumAmount, mandate.Currency, mandate.FrequencyType)
+		fmt.Printf("   Amount: %s %s (%s)\n", mandate.MaximumAmount, mandate.Currency, mandate.FrequencyType)
 		fmt.Printf("   Next Collection: %s\n", mandate.NextCollectionDate)
 		fmt.Printf("\n")
 	}
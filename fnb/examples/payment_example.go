@@ -8,6 +8,8 @@ import (
 
 	"github.com/nutcas3/payment-rails/fnb"
 	"github.com/nutcas3/payment-rails/fnb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -55,7 +57,7 @@ func singlePaymentExample(ctx context.Context, client *fnb.Client) {
 		BeneficiaryName:          "John Doe",
 		BeneficiaryBankCode:      "250655", // FNB universal branch code
 		BeneficiaryReference:     "Payment from ABC Company",
-		Amount:                   1500.00,
+		Amount:                   money.NewNumber(decimal.NewFromInt(1500)),
 		Currency:                 "ZAR",
 		PaymentReference:         "INV-2025-001",
 		PaymentDescription:       "Invoice payment for services rendered",
@@ -73,7 +75,7 @@ func singlePaymentExample(ctx context.Context, client *fnb.Client) {
 	fmt.Printf("  Transaction ID: %s\n", resp.TransactionID)
 	fmt.Printf("  Status: %s\n", resp.Status)
 	fmt.Printf("  Reference: %s\n", resp.PaymentReference)
-	fmt.Printf("  Amount: %.2f %s\n", resp.Amount, resp.Currency)
+	fmt.Printf("  Amount: %s %s\n", resp.Amount, resp.Currency)
 	fmt.Printf("  Beneficiary: %s\n", resp.BeneficiaryName)
 	fmt.Printf("  Processing Date: %s\n", resp.ProcessingDate.Format("2006-01-02 15:04:05"))
 }
@@ -92,7 +94,7 @@ func paymentStatusExample(ctx context.Context, client *fnb.Client) {
 	fmt.Printf("  Transaction ID: %s\n", status.TransactionID)
 	fmt.Printf("  Reference: %s\n", status.PaymentReference)
 	fmt.Printf("  Status: %s - %s\n", status.Status, status.StatusDescription)
-	fmt.Printf("  Amount: %.2f %s\n", status.Amount, status.Currency)
+	fmt.Printf("  Amount: %s %s\n", status.Amount, status.Currency)
 	fmt.Printf("  Source Account: %s\n", status.SourceAccount)
 	fmt.Printf("  Beneficiary: %s (%s)\n", status.BeneficiaryName, status.BeneficiaryAccount)
 	fmt.Printf("  Processing Date: %s\n", status.ProcessingDate.Format("2006-01-02 15:04:05"))
@@ -108,7 +110,7 @@ func batchPaymentExample(ctx context.Context, client *fnb.Client) {
 	req := api.BatchPaymentRequest{
 		BatchReference:      "BATCH-2025-001",
 		SourceAccountNumber: "1234567890",
-		TotalAmount:         7500.00,
+		TotalAmount:         money.NewNumber(decimal.NewFromInt(7500)),
 		TotalCount:          3,
 		ProcessingDate:      "2025-10-03",
 		Payments: []api.EFTPaymentRequest{
@@ -116,7 +118,7 @@ func batchPaymentExample(ctx context.Context, client *fnb.Client) {
 				BeneficiaryAccountNumber: "1111111111",
 				BeneficiaryName:          "Supplier A Ltd",
 				BeneficiaryBankCode:      "250655",
-				Amount:                   2500.00,
+				Amount:                   money.NewNumber(decimal.NewFromInt(2500)),
 				Currency:                 "ZAR",
 				PaymentReference:         "INV-001",
 				PaymentDescription:       "Invoice 001 - Office supplies",
@@ -125,7 +127,7 @@ func batchPaymentExample(ctx context.Context, client *fnb.Client) {
 				BeneficiaryAccountNumber: "2222222222",
 				BeneficiaryName:          "Supplier B (Pty) Ltd",
 				BeneficiaryBankCode:      "198765", // Different bank
-				Amount:                   3000.00,
+				Amount:                   money.NewNumber(decimal.NewFromInt(3000)),
 				Currency:                 "ZAR",
 				PaymentReference:         "INV-002",
 				PaymentDescription:       "Invoice 002 - IT services",
@@ -134,7 +136,7 @@ func batchPaymentExample(ctx context.Context, client *fnb.Client) {
 				BeneficiaryAccountNumber: "3333333333",
 				BeneficiaryName:          "Contractor C",
 				BeneficiaryBankCode:      "250655",
-				Amount:                   2000.00,
+				Amount:                   money.NewNumber(decimal.NewFromInt(2000)),
 				Currency:                 "ZAR",
 				PaymentReference:         "INV-003",
 				PaymentDescription:       "Invoice 003 - Consulting fees",
@@ -152,14 +154,14 @@ func batchPaymentExample(ctx context.Context, client *fnb.Client) {
 	fmt.Printf("  Batch ID: %s\n", resp.BatchID)
 	fmt.Printf("  Batch Reference: %s\n", resp.BatchReference)
 	fmt.Printf("  Status: %s\n", resp.Status)
-	fmt.Printf("  Total Amount: %.2f ZAR\n", resp.TotalAmount)
+	fmt.Printf("  Total Amount: %s ZAR\n", resp.TotalAmount)
 	fmt.Printf("  Total Payments: %d\n", resp.TotalCount)
 	fmt.Printf("  Processing Date: %s\n", resp.ProcessingDate.Format("2006-01-02"))
 
 	if len(resp.PaymentResults) > 0 {
 		fmt.Printf("\n  Individual Payment Results:\n")
 		for i, result := range resp.PaymentResults {
-			fmt.Printf("    %d. %s - %s (%.2f ZAR)\n",
+			fmt.Printf("    %d. %s - %s (%s ZAR)\n",
 				i+1,
 				result.PaymentReference,
 				result.Status,
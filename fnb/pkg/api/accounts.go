@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type AccountVerificationRequest struct {
@@ -25,14 +27,14 @@ type AccountVerificationResponse struct {
 }
 
 type TransactionHistoryRequest struct {
-	AccountNumber   string  `json:"accountNumber"`
-	FromDate        string  `json:"fromDate"`
-	ToDate          string  `json:"toDate"`
-	TransactionType string  `json:"transactionType,omitempty"`
-	MinAmount       float64 `json:"minAmount,omitempty"`
-	MaxAmount       float64 `json:"maxAmount,omitempty"`
-	PageNumber      int     `json:"pageNumber,omitempty"`
-	PageSize        int     `json:"pageSize,omitempty"`  
+	AccountNumber   string       `json:"accountNumber"`
+	FromDate        string       `json:"fromDate"`
+	ToDate          string       `json:"toDate"`
+	TransactionType string       `json:"transactionType,omitempty"`
+	MinAmount       money.Number `json:"minAmount,omitzero"`
+	MaxAmount       money.Number `json:"maxAmount,omitzero"`
+	PageNumber      int          `json:"pageNumber,omitempty"`
+	PageSize        int          `json:"pageSize,omitempty"`
 }
 
 type TransactionHistoryResponse struct {
@@ -48,31 +50,31 @@ type TransactionHistoryResponse struct {
 }
 
 type Transaction struct {
-	TransactionID   string    `json:"transactionId"`
-	Date            time.Time `json:"date"`
-	ValueDate       string    `json:"valueDate,omitempty"`
-	Description     string    `json:"description"`
-	Reference       string    `json:"reference"`
-	Amount          float64   `json:"amount"`
-	Balance         float64   `json:"balance"`
-	Type            string    `json:"type"` 
-	Category        string    `json:"category,omitempty"` 
-	CounterParty    string    `json:"counterParty,omitempty"`
-	CounterPartyAccount string `json:"counterPartyAccount,omitempty"`
+	TransactionID       string       `json:"transactionId"`
+	Date                time.Time    `json:"date"`
+	ValueDate           string       `json:"valueDate,omitempty"`
+	Description         string       `json:"description"`
+	Reference           string       `json:"reference"`
+	Amount              money.Number `json:"amount"`
+	Balance             money.Number `json:"balance"`
+	Type                string       `json:"type"`
+	Category            string       `json:"category,omitempty"`
+	CounterParty        string       `json:"counterParty,omitempty"`
+	CounterPartyAccount string       `json:"counterPartyAccount,omitempty"`
 }
 
 type AccountBalanceRequest struct {
 	AccountNumber string `json:"accountNumber"`
 }
 type AccountBalanceResponse struct {
-	AccountNumber   string    `json:"accountNumber"`
-	AccountName     string    `json:"accountName"`
-	AccountType     string    `json:"accountType"`
-	Currency        string    `json:"currency"`
-	CurrentBalance  float64   `json:"currentBalance"`
-	AvailableBalance float64  `json:"availableBalance"`
-	OverdraftLimit  float64   `json:"overdraftLimit,omitempty"`
-	LastUpdated     time.Time `json:"lastUpdated"`
+	AccountNumber    string         `json:"accountNumber"`
+	AccountName      string         `json:"accountName"`
+	AccountType      string         `json:"accountType"`
+	Currency         money.Currency `json:"currency"`
+	CurrentBalance   money.Number   `json:"currentBalance"`
+	AvailableBalance money.Number   `json:"availableBalance"`
+	OverdraftLimit   money.Number   `json:"overdraftLimit,omitzero"`
+	LastUpdated      time.Time      `json:"lastUpdated"`
 }
 
 type ProofOfPaymentRequest struct {
@@ -81,20 +83,20 @@ type ProofOfPaymentRequest struct {
 	Format           string `json:"format,omitempty"` 
 }
 type ProofOfPaymentResponse struct {
-	TransactionID       string    `json:"transactionId"`
-	PaymentReference    string    `json:"paymentReference"`
-	PaymentDate         time.Time `json:"paymentDate"`
-	Amount              float64   `json:"amount"`
-	Currency            string    `json:"currency"`
-	SourceAccount       string    `json:"sourceAccount"`
-	SourceAccountName   string    `json:"sourceAccountName"`
-	BeneficiaryAccount  string    `json:"beneficiaryAccount"`
-	BeneficiaryName     string    `json:"beneficiaryName"`
-	BeneficiaryBank     string    `json:"beneficiaryBank"`
-	Status              string    `json:"status"`
-	Description         string    `json:"description"`
-	DocumentURL         string    `json:"documentUrl,omitempty"` 
-	DocumentData        string    `json:"documentData,omitempty"` 
+	TransactionID      string         `json:"transactionId"`
+	PaymentReference   string         `json:"paymentReference"`
+	PaymentDate        time.Time      `json:"paymentDate"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	SourceAccount      string         `json:"sourceAccount"`
+	SourceAccountName  string         `json:"sourceAccountName"`
+	BeneficiaryAccount string         `json:"beneficiaryAccount"`
+	BeneficiaryName    string         `json:"beneficiaryName"`
+	BeneficiaryBank    string         `json:"beneficiaryBank"`
+	Status             string         `json:"status"`
+	Description        string         `json:"description"`
+	DocumentURL        string         `json:"documentUrl,omitempty"`
+	DocumentData       string         `json:"documentData,omitempty"`
 }
 
 type StatementRequest struct {
@@ -109,14 +111,14 @@ type StatementResponse struct {
 	AccountName      string        `json:"accountName"`
 	FromDate         string        `json:"fromDate"`
 	ToDate           string        `json:"toDate"`
-	OpeningBalance   float64       `json:"openingBalance"`
-	ClosingBalance   float64       `json:"closingBalance"`
-	TotalCredits     float64       `json:"totalCredits"`
-	TotalDebits      float64       `json:"totalDebits"`
+	OpeningBalance   money.Number  `json:"openingBalance"`
+	ClosingBalance   money.Number  `json:"closingBalance"`
+	TotalCredits     money.Number  `json:"totalCredits"`
+	TotalDebits      money.Number  `json:"totalDebits"`
 	TransactionCount int           `json:"transactionCount"`
 	Transactions     []Transaction `json:"transactions,omitempty"`
-	DocumentURL      string        `json:"documentUrl,omitempty"` 
-	DocumentData     string        `json:"documentData,omitempty"` 
+	DocumentURL      string        `json:"documentUrl,omitempty"`
+	DocumentData     string        `json:"documentData,omitempty"`
 	EmailSent        bool          `json:"emailSent,omitempty"`
 }
 type NotificationPreferencesRequest struct {
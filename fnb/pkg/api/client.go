@@ -8,8 +8,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 const (
@@ -26,6 +33,9 @@ type Client struct {
 	accessToken  string
 	tokenExpiry  time.Time
 	tokenMu      sync.RWMutex
+	tokens       *auth.TokenManager
+	retry        retry.Policy
+	breaker      *breaker.Breaker
 	h2hConfig    *H2HConfig
 }
 
@@ -45,6 +55,22 @@ type ClientConfig struct {
 	Environment  string
 	BaseURL      string
 	H2HConfig    *H2HConfig
+
+	// TokenStore shares access tokens between clients and processes.
+	// Defaults to an in-memory store.
+	TokenStore auth.TokenStore
+	// TokenSkew is how long before expiry the access token is refreshed.
+	// Defaults to auth.DefaultSkew.
+	TokenSkew time.Duration
+	// RetryPolicy controls how requests that fail with a gateway error or a
+	// transient network error are retried. Defaults to retry.DefaultPolicy().
+	RetryPolicy *retry.Policy
+	// Breaker controls when requests to a failing endpoint are stopped.
+	// Defaults to breaker.DefaultSettings().
+	Breaker *breaker.Settings
+	// Middleware runs every request through transport middleware, such as
+	// transport.Log. More can be added with Client.Use.
+	Middleware []transport.Middleware
 }
 
 func NewClient(config *ClientConfig) *Client {
@@ -68,7 +94,7 @@ func NewClient(config *ClientConfig) *Client {
 		}
 	}
 
-	return &Client{
+	client := &Client{
 		clientID:     config.ClientID,
 		clientSecret: config.ClientSecret,
 		apiKey:       config.APIKey,
@@ -84,13 +110,31 @@ func NewClient(config *ClientConfig) *Client {
 		},
 		h2hConfig: config.H2HConfig,
 	}
+	client.tokens = auth.NewTokenManager(config.TokenStore, auth.Key("fnb", baseURL, config.ClientID), client.fetchToken)
+	if config.TokenSkew > 0 {
+		client.tokens.SetSkew(config.TokenSkew)
+	}
+
+	client.retry = retry.DefaultPolicy()
+	if config.RetryPolicy != nil {
+		client.retry = *config.RetryPolicy
+	}
+
+	settings := breaker.DefaultSettings()
+	if config.Breaker != nil {
+		settings = *config.Breaker
+	}
+	client.breaker = breaker.New(provider, settings)
+	client.Use(config.Middleware...)
+
+	return client
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-	Scope       string `json:"scope,omitempty"`
+	AccessToken string         `json:"access_token"`
+	TokenType   string         `json:"token_type"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
+	Scope       string         `json:"scope,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -126,6 +170,18 @@ func (c *Client) Authenticate(ctx context.Context) error {
 		return nil
 	}
 
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.accessToken = token.AccessToken
+	c.tokenExpiry = c.tokens.RefreshAt(token)
+
+	return nil
+}
+
+func (c *Client) fetchToken(ctx context.Context) (auth.Token, error) {
 	payload := map[string]string{
 		"grant_type":    "client_credentials",
 		"client_id":     c.clientID,
@@ -134,40 +190,37 @@ func (c *Client) Authenticate(ctx context.Context) error {
 
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal auth payload: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to marshal auth payload: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/oauth/token", bytes.NewBuffer(data))
 	if err != nil {
-		return fmt.Errorf("failed to create auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to execute auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		var errResp ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-			return fmt.Errorf("authentication failed with status %d", resp.StatusCode)
-		}
-		return &errResp
+		body, _ := io.ReadAll(resp.Body)
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 
 	var authResp AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return fmt.Errorf("failed to decode auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
-	c.accessToken = authResp.AccessToken
-	c.tokenExpiry = time.Now().Add(time.Duration(authResp.ExpiresIn-60) * time.Second) // Refresh 60s early
-
-	return nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
 func (c *Client) isTokenValid() bool {
@@ -175,54 +228,97 @@ func (c *Client) isTokenValid() bool {
 }
 
 func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	if err := c.Authenticate(ctx); err != nil {
-		return err
-	}
-
-	var reqBody io.Reader
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
+		ctx = withIdempotencyKey(ctx, data)
+	}
+
+	resp, respBody, err := c.send(ctx, method, path, data)
+	if err != nil {
+		return err
+	}
+	if auth.TokenRejected(resp.StatusCode, respBody) {
+		c.invalidateToken(ctx, resp.Request)
+		resp, respBody, err = c.send(ctx, method, path, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		return newProviderError(resp, respBody)
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, method, path string, data []byte) (*http.Response, []byte, error) {
+	if err := c.Authenticate(ctx); err != nil {
+		return nil, nil, idempotency.NotSent(err)
+	}
+
+	var reqBody io.Reader
+	if data != nil {
 		reqBody = bytes.NewBuffer(data)
 	}
 
 	url := c.baseURL + path
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, idempotency.NotSent(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	c.setHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.retry.Do(req, c.roundTrip)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err != nil {
-			return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
-		}
-		errResp.Status = resp.StatusCode
-		return &errResp
+	return resp, respBody, nil
+}
+
+// withIdempotencyKey marks ctx for retry when the request body carries an
+// idempotencyKey, which FNB uses to discard duplicate instructions.
+func withIdempotencyKey(ctx context.Context, data []byte) context.Context {
+	var body struct {
+		IdempotencyKey string `json:"idempotencyKey"`
 	}
+	if json.Unmarshal(data, &body) == nil && body.IdempotencyKey != "" {
+		return retry.WithIdempotent(ctx)
+	}
+	return ctx
+}
 
-	if result != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
+// invalidateToken drops the token req was sent with so the next request
+// authenticates again.
+func (c *Client) invalidateToken(ctx context.Context, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+
+	c.tokenMu.Lock()
+	if c.accessToken == token {
+		c.accessToken = ""
 	}
+	c.tokenMu.Unlock()
 
-	return nil
+	c.tokens.Invalidate(ctx, token)
 }
 
 func (c *Client) setHeaders(req *http.Request) {
@@ -235,10 +331,33 @@ func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("X-API-Key", c.apiKey)
 }
 
+// SetHttpClient replaces the HTTP client, including its TLS configuration.
+// Call it before Use, whose middleware is installed on the client.
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
 func (c *Client) GetHTTPClient() *http.Client {
 	return c.httpClient
 }
 
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.httpClient = transport.Wrap(c.httpClient, provider, middleware...)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.httpClient.Do)
+}
+
 func (c *Client) GetBaseURL() string {
 	return c.baseURL
 }
@@ -3,10 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
 )
 
 func TestNewClient(t *testing.T) {
@@ -106,14 +109,18 @@ func TestAuthenticateError(t *testing.T) {
 		t.Fatal("Expected authentication to fail")
 	}
 
-	errResp, ok := err.(*ErrorResponse)
-	if !ok {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
 		t.Fatalf("Expected ErrorResponse, got %T", err)
 	}
 
 	if errResp.ErrorCode != "invalid_client" {
 		t.Errorf("Expected error 'invalid_client', got %s", errResp.ErrorCode)
 	}
+
+	if category := railerrors.CategoryOf(err); category != railerrors.CategoryAuth {
+		t.Errorf("Expected category auth, got %s", category)
+	}
 }
 
 func TestDoRequest(t *testing.T) {
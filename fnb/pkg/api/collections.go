@@ -4,86 +4,89 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type EFTCollectionRequest struct {
-	CreditorAccountNumber string `json:"creditorAccountNumber"`
-	CreditorAccountType   string `json:"creditorAccountType,omitempty"`
-	CreditorName          string `json:"creditorName"`
-	DebtorAccountNumber string `json:"debtorAccountNumber"`
-	DebtorAccountType   string `json:"debtorAccountType,omitempty"`
-	DebtorName          string `json:"debtorName"`
-	DebtorBankCode      string `json:"debtorBankCode"` // Universal branch code
-	Amount              float64 `json:"amount"`
-	Currency            string  `json:"currency"`           // Default: ZAR
-	CollectionReference string  `json:"collectionReference"` // Your reference
-	CollectionDate      string  `json:"collectionDate,omitempty"` // Format: YYYY-MM-DD
-	Description         string  `json:"description"`
-	MandateID           string `json:"mandateId,omitempty"`
-	ContractReference   string `json:"contractReference,omitempty"`
-	NotificationEmail   string `json:"notificationEmail,omitempty"`
-	IdempotencyKey      string `json:"idempotencyKey,omitempty"`
+	CreditorAccountNumber string         `json:"creditorAccountNumber"`
+	CreditorAccountType   string         `json:"creditorAccountType,omitempty"`
+	CreditorName          string         `json:"creditorName"`
+	DebtorAccountNumber   string         `json:"debtorAccountNumber"`
+	DebtorAccountType     string         `json:"debtorAccountType,omitempty"`
+	DebtorName            string         `json:"debtorName"`
+	DebtorBankCode        string         `json:"debtorBankCode"` // Universal branch code
+	Amount                money.Number   `json:"amount"`
+	Currency              money.Currency `json:"currency"`                 // Default: ZAR
+	CollectionReference   string         `json:"collectionReference"`      // Your reference
+	CollectionDate        string         `json:"collectionDate,omitempty"` // Format: YYYY-MM-DD
+	Description           string         `json:"description"`
+	MandateID             string         `json:"mandateId,omitempty"`
+	ContractReference     string         `json:"contractReference,omitempty"`
+	NotificationEmail     string         `json:"notificationEmail,omitempty"`
+	IdempotencyKey        string         `json:"idempotencyKey,omitempty"`
 }
 
 type EFTCollectionResponse struct {
-	TransactionID        string    `json:"transactionId"`
-	Status               string    `json:"status"` // PENDING, PROCESSING, COMPLETED, FAILED, REJECTED
-	StatusDescription    string    `json:"statusDescription"`
-	CollectionReference  string    `json:"collectionReference"`
-	Amount               float64   `json:"amount"`
-	Currency             string    `json:"currency"`
-	ProcessingDate       time.Time `json:"processingDate"`
-	SettlementDate       string    `json:"settlementDate,omitempty"`
-	DebtorName           string    `json:"debtorName"`
-	Message              string    `json:"message,omitempty"`
+	TransactionID       string         `json:"transactionId"`
+	Status              string         `json:"status"` // PENDING, PROCESSING, COMPLETED, FAILED, REJECTED
+	StatusDescription   string         `json:"statusDescription"`
+	CollectionReference string         `json:"collectionReference"`
+	Amount              money.Number   `json:"amount"`
+	Currency            money.Currency `json:"currency"`
+	ProcessingDate      time.Time      `json:"processingDate"`
+	SettlementDate      string         `json:"settlementDate,omitempty"`
+	DebtorName          string         `json:"debtorName"`
+	Message             string         `json:"message,omitempty"`
 }
 
 type CollectionStatusResponse struct {
-	TransactionID        string    `json:"transactionId"`
-	CollectionReference  string    `json:"collectionReference"`
-	Status               string    `json:"status"`
-	StatusDescription    string    `json:"statusDescription"`
-	Amount               float64   `json:"amount"`
-	Currency             string    `json:"currency"`
-	CreditorAccount      string    `json:"creditorAccount"`
-	DebtorAccount        string    `json:"debtorAccount"`
-	DebtorName           string    `json:"debtorName"`
-	ProcessingDate       time.Time `json:"processingDate"`
-	SettlementDate       string    `json:"settlementDate,omitempty"`
-	FailureReason        string    `json:"failureReason,omitempty"`
-	RejectionReason      string    `json:"rejectionReason,omitempty"`
-	LastUpdated          time.Time `json:"lastUpdated"`
+	TransactionID       string         `json:"transactionId"`
+	CollectionReference string         `json:"collectionReference"`
+	Status              string         `json:"status"`
+	StatusDescription   string         `json:"statusDescription"`
+	Amount              money.Number   `json:"amount"`
+	Currency            money.Currency `json:"currency"`
+	CreditorAccount     string         `json:"creditorAccount"`
+	DebtorAccount       string         `json:"debtorAccount"`
+	DebtorName          string         `json:"debtorName"`
+	ProcessingDate      time.Time      `json:"processingDate"`
+	SettlementDate      string         `json:"settlementDate,omitempty"`
+	FailureReason       string         `json:"failureReason,omitempty"`
+	RejectionReason     string         `json:"rejectionReason,omitempty"`
+	LastUpdated         time.Time      `json:"lastUpdated"`
 }
 
 type BatchCollectionRequest struct {
 	BatchReference        string                 `json:"batchReference"`
 	CreditorAccountNumber string                 `json:"creditorAccountNumber"`
-	TotalAmount           float64                `json:"totalAmount"`
+	TotalAmount           money.Number           `json:"totalAmount"`
 	TotalCount            int                    `json:"totalCount"`
 	ProcessingDate        string                 `json:"processingDate,omitempty"`
 	Collections           []EFTCollectionRequest `json:"collections"`
 }
 
 type BatchCollectionResponse struct {
-	BatchID            string              `json:"batchId"`
-	BatchReference     string              `json:"batchReference"`
-	Status             string              `json:"status"`
-	TotalAmount        float64             `json:"totalAmount"`
-	TotalCount         int                 `json:"totalCount"`
-	SuccessCount       int                 `json:"successCount"`
-	FailureCount       int                 `json:"failureCount"`
-	ProcessingDate     time.Time           `json:"processingDate"`
-	CollectionResults  []CollectionResult  `json:"collectionResults,omitempty"`
+	BatchID           string             `json:"batchId"`
+	BatchReference    string             `json:"batchReference"`
+	Status            string             `json:"status"`
+	TotalAmount       money.Number       `json:"totalAmount"`
+	TotalCount        int                `json:"totalCount"`
+	SuccessCount      int                `json:"successCount"`
+	FailureCount      int                `json:"failureCount"`
+	ProcessingDate    time.Time          `json:"processingDate"`
+	CollectionResults []CollectionResult `json:"collectionResults,omitempty"`
 }
 
 type CollectionResult struct {
-	CollectionReference string  `json:"collectionReference"`
-	TransactionID       string  `json:"transactionId,omitempty"`
-	Status              string  `json:"status"`
-	StatusDescription   string  `json:"statusDescription"`
-	Amount              float64 `json:"amount"`
-	DebtorName          string  `json:"debtorName"`
-	FailureReason       string  `json:"failureReason,omitempty"`
+	CollectionReference string       `json:"collectionReference"`
+	TransactionID       string       `json:"transactionId,omitempty"`
+	Status              string       `json:"status"`
+	StatusDescription   string       `json:"statusDescription"`
+	Amount              money.Number `json:"amount"`
+	DebtorName          string       `json:"debtorName"`
+	FailureReason       string       `json:"failureReason,omitempty"`
 }
 
 type DisputeRequest struct {
@@ -106,6 +109,9 @@ func (c *Client) CreateEFTCollection(ctx context.Context, req EFTCollectionReque
 	if req.Currency == "" {
 		req.Currency = "ZAR"
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
 	if req.CollectionDate == "" {
 		req.CollectionDate = time.Now().Format("2006-01-02")
 	}
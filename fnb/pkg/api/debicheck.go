@@ -4,74 +4,76 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 
 type MandateRequest struct {
-
 	CreditorName          string `json:"creditorName"`
-	CreditorAbbreviation  string `json:"creditorAbbreviation"` 
+	CreditorAbbreviation  string `json:"creditorAbbreviation"`
 	CreditorAccountNumber string `json:"creditorAccountNumber"`
-	DebtorName          string `json:"debtorName"`
-	DebtorIDNumber      string `json:"debtorIdNumber,omitempty"` 
-	DebtorAccountNumber string `json:"debtorAccountNumber"`
-	DebtorAccountType   string `json:"debtorAccountType,omitempty"` 
-	DebtorBankCode      string `json:"debtorBankCode"` 
-	DebtorEmail         string `json:"debtorEmail,omitempty"`
-	DebtorMobile        string `json:"debtorMobile,omitempty"`
-
-	ContractReference   string  `json:"contractReference"`   
-	MaximumAmount       float64 `json:"maximumAmount"` 
-	Currency            string  `json:"currency"`            
-	FrequencyType       string  `json:"frequencyType"`       
-	FirstCollectionDate string  `json:"firstCollectionDate"` 
-	LastCollectionDate  string  `json:"lastCollectionDate,omitempty"` 
-	CollectionDay       int     `json:"collectionDay,omitempty"` 
-
-	MandateDescription  string `json:"mandateDescription"`
-	CategoryCode        string `json:"categoryCode,omitempty"` 
-	IdempotencyKey      string `json:"idempotencyKey,omitempty"`
+	DebtorName            string `json:"debtorName"`
+	DebtorIDNumber        string `json:"debtorIdNumber,omitempty"`
+	DebtorAccountNumber   string `json:"debtorAccountNumber"`
+	DebtorAccountType     string `json:"debtorAccountType,omitempty"`
+	DebtorBankCode        string `json:"debtorBankCode"`
+	DebtorEmail           string `json:"debtorEmail,omitempty"`
+	DebtorMobile          string `json:"debtorMobile,omitempty"`
+
+	ContractReference   string         `json:"contractReference"`
+	MaximumAmount       money.Number   `json:"maximumAmount"`
+	Currency            money.Currency `json:"currency"`
+	FrequencyType       string         `json:"frequencyType"`
+	FirstCollectionDate string         `json:"firstCollectionDate"`
+	LastCollectionDate  string         `json:"lastCollectionDate,omitempty"`
+	CollectionDay       int            `json:"collectionDay,omitempty"`
+
+	MandateDescription string `json:"mandateDescription"`
+	CategoryCode       string `json:"categoryCode,omitempty"`
+	IdempotencyKey     string `json:"idempotencyKey,omitempty"`
 }
 type MandateResponse struct {
-	MandateID           string    `json:"mandateId"`
-	Status              string    `json:"status"` 
-	StatusDescription   string    `json:"statusDescription"`
-	ContractReference   string    `json:"contractReference"`
-	DebtorName          string    `json:"debtorName"`
-	MaximumAmount       float64   `json:"maximumAmount"`
-	Currency            string    `json:"currency"`
-	FrequencyType       string    `json:"frequencyType"`
-	FirstCollectionDate string    `json:"firstCollectionDate"`
-	CreatedDate         time.Time `json:"createdDate"`
-	ApprovalDate        string    `json:"approvalDate,omitempty"`
-	ExpiryDate          string    `json:"expiryDate,omitempty"`
-	Message             string    `json:"message,omitempty"`
+	MandateID           string         `json:"mandateId"`
+	Status              string         `json:"status"`
+	StatusDescription   string         `json:"statusDescription"`
+	ContractReference   string         `json:"contractReference"`
+	DebtorName          string         `json:"debtorName"`
+	MaximumAmount       money.Number   `json:"maximumAmount"`
+	Currency            money.Currency `json:"currency"`
+	FrequencyType       string         `json:"frequencyType"`
+	FirstCollectionDate string         `json:"firstCollectionDate"`
+	CreatedDate         time.Time      `json:"createdDate"`
+	ApprovalDate        string         `json:"approvalDate,omitempty"`
+	ExpiryDate          string         `json:"expiryDate,omitempty"`
+	Message             string         `json:"message,omitempty"`
 }
 type MandateStatusResponse struct {
-	MandateID           string    `json:"mandateId"`
-	ContractReference   string    `json:"contractReference"`
-	Status              string    `json:"status"`
-	StatusDescription   string    `json:"statusDescription"`
-	CreditorName        string    `json:"creditorName"`
-	DebtorName          string    `json:"debtorName"`
-	DebtorAccountNumber string    `json:"debtorAccountNumber"`
-	MaximumAmount       float64   `json:"maximumAmount"`
-	Currency            string    `json:"currency"`
-	FrequencyType       string    `json:"frequencyType"`
-	FirstCollectionDate string    `json:"firstCollectionDate"`
-	LastCollectionDate  string    `json:"lastCollectionDate,omitempty"`
-	NextCollectionDate  string    `json:"nextCollectionDate,omitempty"`
-	CreatedDate         time.Time `json:"createdDate"`
-	ApprovalDate        string    `json:"approvalDate,omitempty"`
-	LastModifiedDate    time.Time `json:"lastModifiedDate"`
-	RejectionReason     string    `json:"rejectionReason,omitempty"`
+	MandateID           string         `json:"mandateId"`
+	ContractReference   string         `json:"contractReference"`
+	Status              string         `json:"status"`
+	StatusDescription   string         `json:"statusDescription"`
+	CreditorName        string         `json:"creditorName"`
+	DebtorName          string         `json:"debtorName"`
+	DebtorAccountNumber string         `json:"debtorAccountNumber"`
+	MaximumAmount       money.Number   `json:"maximumAmount"`
+	Currency            money.Currency `json:"currency"`
+	FrequencyType       string         `json:"frequencyType"`
+	FirstCollectionDate string         `json:"firstCollectionDate"`
+	LastCollectionDate  string         `json:"lastCollectionDate,omitempty"`
+	NextCollectionDate  string         `json:"nextCollectionDate,omitempty"`
+	CreatedDate         time.Time      `json:"createdDate"`
+	ApprovalDate        string         `json:"approvalDate,omitempty"`
+	LastModifiedDate    time.Time      `json:"lastModifiedDate"`
+	RejectionReason     string         `json:"rejectionReason,omitempty"`
 }
 type MandateUpdateRequest struct {
-	MandateID           string  `json:"mandateId"`
-	MaximumAmount       float64 `json:"maximumAmount,omitempty"`
-	LastCollectionDate  string  `json:"lastCollectionDate,omitempty"`
-	CollectionDay       int     `json:"collectionDay,omitempty"`
-	UpdateReason        string  `json:"updateReason"`
+	MandateID          string       `json:"mandateId"`
+	MaximumAmount      money.Number `json:"maximumAmount,omitzero"`
+	LastCollectionDate string       `json:"lastCollectionDate,omitempty"`
+	CollectionDay      int          `json:"collectionDay,omitempty"`
+	UpdateReason       string       `json:"updateReason"`
 }
 type MandateCancellationRequest struct {
 	MandateID          string `json:"mandateId"`
@@ -84,12 +86,12 @@ type MandateSuspensionRequest struct {
 	SuspensionPeriod int    `json:"suspensionPeriod,omitempty"` 
 }
 type MandateCollectionRequest struct {
-	MandateID           string  `json:"mandateId"`
-	Amount              float64 `json:"amount"`
-	CollectionReference string  `json:"collectionReference"`
-	CollectionDate      string  `json:"collectionDate,omitempty"` 
-	Description         string  `json:"description"`
-	IdempotencyKey      string  `json:"idempotencyKey,omitempty"`
+	MandateID           string       `json:"mandateId"`
+	Amount              money.Number `json:"amount"`
+	CollectionReference string       `json:"collectionReference"`
+	CollectionDate      string       `json:"collectionDate,omitempty"`
+	Description         string       `json:"description"`
+	IdempotencyKey      string       `json:"idempotencyKey,omitempty"`
 }
 type MandateListRequest struct {
 	Status            string `json:"status,omitempty"`            
@@ -112,6 +114,9 @@ func (c *Client) CreateMandate(ctx context.Context, req MandateRequest) (*Mandat
 	if req.Currency == "" {
 		req.Currency = "ZAR"
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
 
 	var result MandateResponse
 	if err := c.DoRequest(ctx, "POST", "/api/v1/debicheck/mandates", req, &result); err != nil {
@@ -190,6 +195,9 @@ func (c *Client) ReinstateMandate(ctx context.Context, mandateID string, reason
 }
 
 func (c *Client) CollectAgainstMandate(ctx context.Context, req MandateCollectionRequest) (*EFTCollectionResponse, error) {
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
 	if req.CollectionDate == "" {
 		req.CollectionDate = time.Now().Format("2006-01-02")
 	}
@@ -218,10 +226,10 @@ func (c *Client) ListMandates(ctx context.Context, req MandateListRequest) (*Man
 	return &result, nil
 }
 
-func (c *Client) VerifyMandate(ctx context.Context, mandateID string, amount float64) (bool, error) {
+func (c *Client) VerifyMandate(ctx context.Context, mandateID string, amount money.Money) (bool, error) {
 	payload := map[string]interface{}{
 		"mandateId": mandateID,
-		"amount":    amount,
+		"amount":    amount.Number(),
 	}
 
 	var result struct {
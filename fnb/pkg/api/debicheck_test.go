@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 func TestCreateMandate(t *testing.T) {
@@ -19,8 +21,8 @@ func TestCreateMandate(t *testing.T) {
 			t.Fatalf("Failed to decode request: %v", err)
 		}
 
-		if req.MaximumAmount != 5000.00 {
-			t.Errorf("Expected maximum amount 5000.00, got %.2f", req.MaximumAmount)
+		if !req.MaximumAmount.Equal(number("5000.00")) {
+			t.Errorf("Expected maximum amount 5000.00, got %s", req.MaximumAmount)
 		}
 
 		resp := MandateResponse{
@@ -51,7 +53,7 @@ func TestCreateMandate(t *testing.T) {
 		DebtorAccountNumber:   "9876543210",
 		DebtorBankCode:        "250655",
 		ContractReference:     "CONTRACT001",
-		MaximumAmount:         5000.00,
+		MaximumAmount:         number("5000.00"),
 		Currency:              "ZAR",
 		FrequencyType:         "MONTHLY",
 		FirstCollectionDate:   "2025-11-01",
@@ -71,8 +73,8 @@ func TestCreateMandate(t *testing.T) {
 		t.Errorf("Expected status 'PENDING_APPROVAL', got %s", resp.Status)
 	}
 
-	if resp.MaximumAmount != 5000.00 {
-		t.Errorf("Expected maximum amount 5000.00, got %.2f", resp.MaximumAmount)
+	if !resp.MaximumAmount.Equal(number("5000.00")) {
+		t.Errorf("Expected maximum amount 5000.00, got %s", resp.MaximumAmount)
 	}
 }
 
@@ -90,7 +92,7 @@ func TestGetMandateStatus(t *testing.T) {
 			CreditorName:        "Test Company",
 			DebtorName:          "John Doe",
 			DebtorAccountNumber: "9876543210",
-			MaximumAmount:       5000.00,
+			MaximumAmount:       number("5000.00"),
 			Currency:            "ZAR",
 			FrequencyType:       "MONTHLY",
 			FirstCollectionDate: "2025-11-01",
@@ -131,8 +133,8 @@ func TestCollectAgainstMandate(t *testing.T) {
 			t.Fatalf("Failed to decode request: %v", err)
 		}
 
-		if req.Amount != 1000.00 {
-			t.Errorf("Expected amount 1000.00, got %.2f", req.Amount)
+		if !req.Amount.Equal(number("1000.00")) {
+			t.Errorf("Expected amount 1000.00, got %s", req.Amount)
 		}
 
 		resp := EFTCollectionResponse{
@@ -153,7 +155,7 @@ func TestCollectAgainstMandate(t *testing.T) {
 	ctx := context.Background()
 	req := MandateCollectionRequest{
 		MandateID:           "MAN123456",
-		Amount:              1000.00,
+		Amount:              number("1000.00"),
 		CollectionReference: "COLL001",
 		Description:         "Monthly subscription",
 	}
@@ -211,7 +213,7 @@ func TestVerifyMandate(t *testing.T) {
 	defer server.Close()
 
 	ctx := context.Background()
-	valid, err := client.VerifyMandate(ctx, "MAN123456", 1000.00)
+	valid, err := client.VerifyMandate(ctx, "MAN123456", number("1000.00").Money(money.ZAR))
 	if err != nil {
 		t.Fatalf("VerifyMandate failed: %v", err)
 	}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/jlaffaye/ftp"
+	"github.com/nutcas3/payment-rails/rails/banks"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type H2HClient struct {
@@ -39,23 +41,33 @@ type H2HFileHeader struct {
 }
 
 type H2HPaymentRecord struct {
-	RecordType          string
-	SequenceNumber      int
-	BeneficiaryAccount  string
-	BeneficiaryName     string
-	BankCode            string
-	BranchCode          string
-	Amount              float64
-	PaymentReference    string
+	RecordType           string
+	SequenceNumber       int
+	BeneficiaryAccount   string
+	BeneficiaryName      string
+	BankCode             string
+	BranchCode           string
+	Amount               money.Money
+	PaymentReference     string
 	BeneficiaryReference string
-	ActionDate          string
+	ActionDate           string
+}
+
+// Total returns the sum of the payment amounts, added as decimals so the
+// trailer matches the records to the cent.
+func (f *H2HPaymentFile) Total() (money.Money, error) {
+	amounts := make([]money.Money, len(f.Payments))
+	for i, payment := range f.Payments {
+		amounts[i] = payment.Amount
+	}
+	return h2hTotal(amounts)
 }
 
 type H2HFileTrailer struct {
-	RecordType   string
-	RecordCount  int
-	TotalAmount  float64
-	HashTotal    string
+	RecordType  string
+	RecordCount int
+	TotalAmount money.Money
+	HashTotal   string
 }
 
 type H2HCollectionFile struct {
@@ -71,13 +83,69 @@ type H2HCollectionRecord struct {
 	DebtorName          string
 	BankCode            string
 	BranchCode          string
-	Amount              float64
+	Amount              money.Money
 	CollectionReference string
 	ContractReference   string
 	ActionDate          string
 	MandateReference    string
 }
 
+// Total returns the sum of the collection amounts.
+func (f *H2HCollectionFile) Total() (money.Money, error) {
+	amounts := make([]money.Money, len(f.Collections))
+	for i, collection := range f.Collections {
+		amounts[i] = collection.Amount
+	}
+	return h2hTotal(amounts)
+}
+
+func h2hTotal(amounts []money.Money) (money.Money, error) {
+	if len(amounts) == 0 {
+		return money.Money{Currency: money.ZAR}, nil
+	}
+
+	// Each amount is written rounded to the cent, so the total is too.
+	total := money.Money{Currency: amounts[0].Currency}
+	for i, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount.Round()); err != nil {
+			return money.Money{}, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return total, nil
+}
+
+// h2hAccount validates account against the bank its branch or bank code
+// identifies, so bad accounts are rejected before the file is submitted.
+// Codes not in the bank registry, such as branch-specific ones, are left to
+// BankservAfrica.
+func h2hAccount(bankCode, branchCode, account string) error {
+	for _, code := range []string{branchCode, bankCode} {
+		if code == "" {
+			continue
+		}
+		if bank, err := banks.Lookup(banks.SouthAfrica, code); err == nil {
+			return bank.ValidateAccount(account)
+		}
+	}
+	return nil
+}
+
+// h2hTrailer fills in the record count and total of a trailer left empty.
+func h2hTrailer(trailer H2HFileTrailer, count int, total func() (money.Money, error)) (H2HFileTrailer, error) {
+	if trailer.RecordCount == 0 {
+		trailer.RecordCount = count
+	}
+	if trailer.TotalAmount.IsZero() {
+		amount, err := total()
+		if err != nil {
+			return trailer, fmt.Errorf("failed to total records: %w", err)
+		}
+		trailer.TotalAmount = amount
+	}
+	return trailer, nil
+}
+
 type H2HResponseFile struct {
 	Header   H2HFileHeader
 	Records  []H2HResponseRecord
@@ -85,17 +153,32 @@ type H2HResponseFile struct {
 }
 
 type H2HResponseRecord struct {
-	RecordType       string
-	SequenceNumber   int
-	TransactionID    string
-	Status           string
-	StatusCode       string
+	RecordType        string
+	SequenceNumber    int
+	TransactionID     string
+	Status            string
+	StatusCode        string
 	StatusDescription string
-	Reference        string
-	Amount           float64
+	Reference         string
+	Amount            money.Money
 }
 
+// GeneratePaymentFile renders file in the H2H format. A trailer with no
+// record count or total amount gets them from the payments. Beneficiary
+// accounts at banks with a universal branch code are checked with the bank's
+// check digit verification first.
 func (h *H2HClient) GeneratePaymentFile(file H2HPaymentFile) (string, error) {
+	for _, payment := range file.Payments {
+		if err := h2hAccount(payment.BankCode, payment.BranchCode, payment.BeneficiaryAccount); err != nil {
+			return "", fmt.Errorf("payment %d: %w", payment.SequenceNumber, err)
+		}
+	}
+
+	trailer, err := h2hTrailer(file.Trailer, len(file.Payments), file.Total)
+	if err != nil {
+		return "", err
+	}
+
 	var sb strings.Builder
 
 	headerLine := fmt.Sprintf("H|%s|%s|%s|%s|%s|%s\n",
@@ -109,13 +192,13 @@ func (h *H2HClient) GeneratePaymentFile(file H2HPaymentFile) (string, error) {
 	sb.WriteString(headerLine)
 
 	for _, payment := range file.Payments {
-		paymentLine := fmt.Sprintf("P|%d|%s|%s|%s|%s|%.2f|%s|%s|%s\n",
+		paymentLine := fmt.Sprintf("P|%d|%s|%s|%s|%s|%s|%s|%s|%s\n",
 			payment.SequenceNumber,
 			payment.BeneficiaryAccount,
 			payment.BeneficiaryName,
 			payment.BankCode,
 			payment.BranchCode,
-			payment.Amount,
+			payment.Amount.Fixed(),
 			payment.PaymentReference,
 			payment.BeneficiaryReference,
 			payment.ActionDate,
@@ -123,17 +206,31 @@ func (h *H2HClient) GeneratePaymentFile(file H2HPaymentFile) (string, error) {
 		sb.WriteString(paymentLine)
 	}
 
-	trailerLine := fmt.Sprintf("T|%d|%.2f|%s\n",
-		file.Trailer.RecordCount,
-		file.Trailer.TotalAmount,
-		file.Trailer.HashTotal,
+	trailerLine := fmt.Sprintf("T|%d|%s|%s\n",
+		trailer.RecordCount,
+		trailer.TotalAmount.Fixed(),
+		trailer.HashTotal,
 	)
 	sb.WriteString(trailerLine)
 
 	return sb.String(), nil
 }
 
+// GenerateCollectionFile renders file in the H2H format. A trailer with no
+// record count or total amount gets them from the collections. Debtor
+// accounts are checked as in GeneratePaymentFile.
 func (h *H2HClient) GenerateCollectionFile(file H2HCollectionFile) (string, error) {
+	for _, collection := range file.Collections {
+		if err := h2hAccount(collection.BankCode, collection.BranchCode, collection.DebtorAccount); err != nil {
+			return "", fmt.Errorf("collection %d: %w", collection.SequenceNumber, err)
+		}
+	}
+
+	trailer, err := h2hTrailer(file.Trailer, len(file.Collections), file.Total)
+	if err != nil {
+		return "", err
+	}
+
 	var sb strings.Builder
 
 	headerLine := fmt.Sprintf("H|%s|%s|%s|%s|%s|%s\n",
@@ -147,13 +244,13 @@ func (h *H2HClient) GenerateCollectionFile(file H2HCollectionFile) (string, erro
 	sb.WriteString(headerLine)
 
 	for _, collection := range file.Collections {
-		collectionLine := fmt.Sprintf("C|%d|%s|%s|%s|%s|%.2f|%s|%s|%s|%s\n",
+		collectionLine := fmt.Sprintf("C|%d|%s|%s|%s|%s|%s|%s|%s|%s|%s\n",
 			collection.SequenceNumber,
 			collection.DebtorAccount,
 			collection.DebtorName,
 			collection.BankCode,
 			collection.BranchCode,
-			collection.Amount,
+			collection.Amount.Fixed(),
 			collection.CollectionReference,
 			collection.ContractReference,
 			collection.ActionDate,
@@ -162,10 +259,10 @@ func (h *H2HClient) GenerateCollectionFile(file H2HCollectionFile) (string, erro
 		sb.WriteString(collectionLine)
 	}
 
-	trailerLine := fmt.Sprintf("T|%d|%.2f|%s\n",
-		file.Trailer.RecordCount,
-		file.Trailer.TotalAmount,
-		file.Trailer.HashTotal,
+	trailerLine := fmt.Sprintf("T|%d|%s|%s\n",
+		trailer.RecordCount,
+		trailer.TotalAmount.Fixed(),
+		trailer.HashTotal,
 	)
 	sb.WriteString(trailerLine)
 
@@ -257,8 +354,7 @@ func (h *H2HClient) ParseResponseFile(content string) (*H2HResponseFile, error)
 			}
 		case "R":
 			if len(fields) >= 8 {
-				var amount float64
-				fmt.Sscanf(fields[7], "%f", &amount)
+				amount, _ := money.Parse(fields[7], money.ZAR)
 
 				record := H2HResponseRecord{
 					RecordType:        recordType,
@@ -274,9 +370,8 @@ func (h *H2HClient) ParseResponseFile(content string) (*H2HResponseFile, error)
 		case "T":
 			if len(fields) >= 3 {
 				var recordCount int
-				var totalAmount float64
 				fmt.Sscanf(fields[1], "%d", &recordCount)
-				fmt.Sscanf(fields[2], "%f", &totalAmount)
+				totalAmount, _ := money.Parse(fields[2], money.ZAR)
 
 				file.Trailer = H2HFileTrailer{
 					RecordType:  recordType,
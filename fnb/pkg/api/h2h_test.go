@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nutcas3/payment-rails/rails/banks"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+func TestGeneratePaymentFileTrailer(t *testing.T) {
+	file := H2HPaymentFile{Header: H2HFileHeader{FileType: "PAY"}}
+	for i := 0; i < 3; i++ {
+		file.Payments = append(file.Payments, H2HPaymentRecord{
+			SequenceNumber: i + 1,
+			Amount:         money.New(decimal.RequireFromString("0.10"), money.ZAR),
+		})
+	}
+	file.Payments = append(file.Payments, H2HPaymentRecord{
+		SequenceNumber: 4,
+		Amount:         money.New(decimal.RequireFromString("100.005"), money.ZAR),
+	})
+
+	content, err := NewH2HClient(&H2HConfig{}).GeneratePaymentFile(file)
+	if err != nil {
+		t.Fatalf("GeneratePaymentFile failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if !strings.Contains(lines[4], "|100.01|") {
+		t.Errorf("Expected the last payment to be rounded to 100.01, got %s", lines[4])
+	}
+	if trailer := lines[len(lines)-1]; trailer != "T|4|100.31|" {
+		t.Errorf("Expected trailer 'T|4|100.31|', got '%s'", trailer)
+	}
+}
+
+func TestGeneratePaymentFileMixedCurrencies(t *testing.T) {
+	file := H2HPaymentFile{Payments: []H2HPaymentRecord{
+		{Amount: money.New(decimal.NewFromInt(1), money.ZAR)},
+		{Amount: money.New(decimal.NewFromInt(1), money.USD)},
+	}}
+
+	if _, err := NewH2HClient(&H2HConfig{}).GeneratePaymentFile(file); err == nil {
+		t.Error("Expected an error totalling payments in different currencies")
+	}
+}
+
+func TestGeneratePaymentFileAccounts(t *testing.T) {
+	payment := H2HPaymentRecord{
+		SequenceNumber:     1,
+		BeneficiaryAccount: "405612345012",
+		BranchCode:         "632005",
+		Amount:             money.New(decimal.NewFromInt(1), money.ZAR),
+	}
+	file := H2HPaymentFile{Payments: []H2HPaymentRecord{payment}}
+
+	_, err := NewH2HClient(&H2HConfig{}).GeneratePaymentFile(file)
+	if !errors.Is(err, banks.ErrInvalidAccount) {
+		t.Errorf("Expected ErrInvalidAccount for an account too long for the bank, got %v", err)
+	}
+
+	file.Payments[0].BeneficiaryAccount = "4056123450"
+	if _, err := NewH2HClient(&H2HConfig{}).GeneratePaymentFile(file); err != nil {
+		t.Errorf("Expected a valid account to be accepted, got %v", err)
+	}
+}
+
+func TestParseResponseFileAmounts(t *testing.T) {
+	content := "H|RSP|REF1|20250101120000|ORG|Originator|T\n" +
+		"R|1|TXN1|OK|00|Processed|PAY001|1500.10\n" +
+		"T|1|1500.10|HASH\n"
+
+	file, err := NewH2HClient(&H2HConfig{}).ParseResponseFile(content)
+	if err != nil {
+		t.Fatalf("ParseResponseFile failed: %v", err)
+	}
+
+	if got := file.Records[0].Amount.String(); got != "ZAR 1500.10" {
+		t.Errorf("Expected record amount ZAR 1500.10, got %s", got)
+	}
+	if got := file.Trailer.TotalAmount.Minor(); got != 150010 {
+		t.Errorf("Expected trailer total of 150010 cents, got %d", got)
+	}
+}
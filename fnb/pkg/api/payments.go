@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type EFTPaymentRequest struct {
@@ -16,28 +19,28 @@ type EFTPaymentRequest struct {
 	BeneficiaryBankCode      string `json:"beneficiaryBankCode"`
 	BeneficiaryReference     string `json:"beneficiaryReference,omitempty"`
 
-	Amount              float64 `json:"amount"`
-	Currency            string  `json:"currency"`
-	PaymentReference    string  `json:"paymentReference"`
-	PaymentDescription  string  `json:"paymentDescription"`
-	PaymentDate         string  `json:"paymentDate,omitempty"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	PaymentReference   string         `json:"paymentReference"`
+	PaymentDescription string         `json:"paymentDescription"`
+	PaymentDate        string         `json:"paymentDate,omitempty"`
 
-	NotificationEmail   string `json:"notificationEmail,omitempty"`
-	NotificationMobile  string `json:"notificationMobile,omitempty"`
-	IdempotencyKey      string `json:"idempotencyKey,omitempty"`
+	NotificationEmail  string `json:"notificationEmail,omitempty"`
+	NotificationMobile string `json:"notificationMobile,omitempty"`
+	IdempotencyKey     string `json:"idempotencyKey,omitempty"`
 }
 
 type EFTPaymentResponse struct {
-	TransactionID       string    `json:"transactionId"`
-	Status              string    `json:"status"`
-	StatusDescription   string    `json:"statusDescription"`
-	PaymentReference    string    `json:"paymentReference"`
-	Amount              float64   `json:"amount"`
-	Currency            string    `json:"currency"`
-	ProcessingDate      time.Time `json:"processingDate"`
-	SettlementDate      string    `json:"settlementDate,omitempty"`
-	BeneficiaryName     string    `json:"beneficiaryName"`
-	Message             string    `json:"message,omitempty"`
+	TransactionID     string         `json:"transactionId"`
+	Status            string         `json:"status"`
+	StatusDescription string         `json:"statusDescription"`
+	PaymentReference  string         `json:"paymentReference"`
+	Amount            money.Number   `json:"amount"`
+	Currency          money.Currency `json:"currency"`
+	ProcessingDate    time.Time      `json:"processingDate"`
+	SettlementDate    string         `json:"settlementDate,omitempty"`
+	BeneficiaryName   string         `json:"beneficiaryName"`
+	Message           string         `json:"message,omitempty"`
 }
 
 type UrgentPaymentRequest struct {
@@ -50,56 +53,59 @@ type PaymentStatusRequest struct {
 	PaymentReference string `json:"paymentReference,omitempty"`
 }
 type PaymentStatusResponse struct {
-	TransactionID       string    `json:"transactionId"`
-	PaymentReference    string    `json:"paymentReference"`
-	Status              string    `json:"status"`
-	StatusDescription   string    `json:"statusDescription"`
-	Amount              float64   `json:"amount"`
-	Currency            string    `json:"currency"`
-	SourceAccount       string    `json:"sourceAccount"`
-	BeneficiaryAccount  string    `json:"beneficiaryAccount"`
-	BeneficiaryName     string    `json:"beneficiaryName"`
-	ProcessingDate      time.Time `json:"processingDate"`
-	SettlementDate      string    `json:"settlementDate,omitempty"`
-	FailureReason       string    `json:"failureReason,omitempty"`
-	LastUpdated         time.Time `json:"lastUpdated"`
+	TransactionID      string         `json:"transactionId"`
+	PaymentReference   string         `json:"paymentReference"`
+	Status             string         `json:"status"`
+	StatusDescription  string         `json:"statusDescription"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	SourceAccount      string         `json:"sourceAccount"`
+	BeneficiaryAccount string         `json:"beneficiaryAccount"`
+	BeneficiaryName    string         `json:"beneficiaryName"`
+	ProcessingDate     time.Time      `json:"processingDate"`
+	SettlementDate     string         `json:"settlementDate,omitempty"`
+	FailureReason      string         `json:"failureReason,omitempty"`
+	LastUpdated        time.Time      `json:"lastUpdated"`
 }
 
 type BatchPaymentRequest struct {
 	BatchReference      string              `json:"batchReference"`
 	SourceAccountNumber string              `json:"sourceAccountNumber"`
-	TotalAmount         float64             `json:"totalAmount"`
+	TotalAmount         money.Number        `json:"totalAmount"`
 	TotalCount          int                 `json:"totalCount"`
 	ProcessingDate      string              `json:"processingDate,omitempty"`
 	Payments            []EFTPaymentRequest `json:"payments"`
 }
 
 type BatchPaymentResponse struct {
-	BatchID            string                   `json:"batchId"`
-	BatchReference     string                   `json:"batchReference"`
-	Status             string                   `json:"status"`
-	TotalAmount        float64                  `json:"totalAmount"`
-	TotalCount         int                      `json:"totalCount"`
-	SuccessCount       int                      `json:"successCount"`
-	FailureCount       int                      `json:"failureCount"`
-	ProcessingDate     time.Time                `json:"processingDate"`
-	PaymentResults     []PaymentResult          `json:"paymentResults,omitempty"`
+	BatchID        string          `json:"batchId"`
+	BatchReference string          `json:"batchReference"`
+	Status         string          `json:"status"`
+	TotalAmount    money.Number    `json:"totalAmount"`
+	TotalCount     int             `json:"totalCount"`
+	SuccessCount   int             `json:"successCount"`
+	FailureCount   int             `json:"failureCount"`
+	ProcessingDate time.Time       `json:"processingDate"`
+	PaymentResults []PaymentResult `json:"paymentResults,omitempty"`
 }
 
 type PaymentResult struct {
-	PaymentReference  string  `json:"paymentReference"`
-	TransactionID     string  `json:"transactionId,omitempty"`
-	Status            string  `json:"status"`
-	StatusDescription string  `json:"statusDescription"`
-	Amount            float64 `json:"amount"`
-	BeneficiaryName   string  `json:"beneficiaryName"`
-	FailureReason     string  `json:"failureReason,omitempty"`
+	PaymentReference  string       `json:"paymentReference"`
+	TransactionID     string       `json:"transactionId,omitempty"`
+	Status            string       `json:"status"`
+	StatusDescription string       `json:"statusDescription"`
+	Amount            money.Number `json:"amount"`
+	BeneficiaryName   string       `json:"beneficiaryName"`
+	FailureReason     string       `json:"failureReason,omitempty"`
 }
 
 func (c *Client) CreateEFTPayment(ctx context.Context, req EFTPaymentRequest) (*EFTPaymentResponse, error) {
 	if req.Currency == "" {
 		req.Currency = "ZAR"
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
 	if req.PaymentDate == "" {
 		req.PaymentDate = time.Now().Format("2006-01-02")
 	}
@@ -116,6 +122,9 @@ func (c *Client) CreateUrgentPayment(ctx context.Context, req UrgentPaymentReque
 	if req.Currency == "" {
 		req.Currency = "ZAR"
 	}
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
 
 	var result EFTPaymentResponse
 	if err := c.DoRequest(ctx, "POST", "/api/v1/payments/urgent", req, &result); err != nil {
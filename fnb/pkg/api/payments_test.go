@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
 )
 
 func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *Client) {
@@ -34,6 +37,10 @@ func setupTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server,
 	return server, client
 }
 
+func number(s string) money.Number {
+	return money.NewNumber(decimal.RequireFromString(s))
+}
+
 func TestCreateEFTPayment(t *testing.T) {
 	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/payments/eft" {
@@ -49,8 +56,8 @@ func TestCreateEFTPayment(t *testing.T) {
 			t.Fatalf("Failed to decode request: %v", err)
 		}
 
-		if req.Amount != 1000.00 {
-			t.Errorf("Expected amount 1000.00, got %.2f", req.Amount)
+		if !req.Amount.Equal(number("1000.00")) {
+			t.Errorf("Expected amount 1000.00, got %s", req.Amount)
 		}
 
 		resp := EFTPaymentResponse{
@@ -75,7 +82,7 @@ func TestCreateEFTPayment(t *testing.T) {
 		BeneficiaryAccountNumber: "0987654321",
 		BeneficiaryName:          "John Doe",
 		BeneficiaryBankCode:      "250655",
-		Amount:                   1000.00,
+		Amount:                   number("1000.00"),
 		Currency:                 "ZAR",
 		PaymentReference:         "PAY001",
 		PaymentDescription:       "Test payment",
@@ -94,8 +101,35 @@ func TestCreateEFTPayment(t *testing.T) {
 		t.Errorf("Expected status 'PENDING', got %s", resp.Status)
 	}
 
-	if resp.Amount != 1000.00 {
-		t.Errorf("Expected amount 1000.00, got %.2f", resp.Amount)
+	if !resp.Amount.Equal(number("1000.00")) {
+		t.Errorf("Expected amount 1000.00, got %s", resp.Amount)
+	}
+}
+
+func TestEFTPaymentAmountFormat(t *testing.T) {
+	server, client := setupTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if string(body["amount"]) != "1234.50" {
+			t.Errorf("Expected amount to be sent as 1234.50, got %s", body["amount"])
+		}
+
+		w.Write([]byte(`{"transactionId":"TXN1","amount":"1234.5","currency":"ZAR"}`))
+	})
+	defer server.Close()
+
+	resp, err := client.CreateEFTPayment(context.Background(), EFTPaymentRequest{
+		Amount:           money.New(decimal.RequireFromString("1234.5"), money.ZAR).Number(),
+		PaymentReference: "PAY001",
+	})
+	if err != nil {
+		t.Fatalf("CreateEFTPayment failed: %v", err)
+	}
+
+	if got := resp.Amount.Money(resp.Currency).String(); got != "ZAR 1234.50" {
+		t.Errorf("Expected ZAR 1234.50, got %s", got)
 	}
 }
 
@@ -114,7 +148,7 @@ func TestGetPaymentStatus(t *testing.T) {
 			PaymentReference:   "PAY001",
 			Status:             "COMPLETED",
 			StatusDescription:  "Payment completed successfully",
-			Amount:             1000.00,
+			Amount:             number("1000.00"),
 			Currency:           "ZAR",
 			SourceAccount:      "1234567890",
 			BeneficiaryAccount: "0987654321",
@@ -179,21 +213,21 @@ func TestCreateBatchPayment(t *testing.T) {
 	req := BatchPaymentRequest{
 		BatchReference:      "BATCH001",
 		SourceAccountNumber: "1234567890",
-		TotalAmount:         3000.00,
+		TotalAmount:         number("3000.00"),
 		TotalCount:          2,
 		Payments: []EFTPaymentRequest{
 			{
 				BeneficiaryAccountNumber: "1111111111",
 				BeneficiaryName:          "Beneficiary 1",
 				BeneficiaryBankCode:      "250655",
-				Amount:                   1500.00,
+				Amount:                   number("1500.00"),
 				PaymentReference:         "PAY001",
 			},
 			{
 				BeneficiaryAccountNumber: "2222222222",
 				BeneficiaryName:          "Beneficiary 2",
 				BeneficiaryBankCode:      "250655",
-				Amount:                   1500.00,
+				Amount:                   number("1500.00"),
 				PaymentReference:         "PAY002",
 			},
 		},
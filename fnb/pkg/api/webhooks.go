@@ -2,16 +2,19 @@ package api
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
+const WebhookSignatureHeader = "X-FNB-Signature"
+
 type WebhookEvent struct {
 	EventID       string                 `json:"eventId"`
 	EventType     string                 `json:"eventType"` 
@@ -23,20 +26,21 @@ type WebhookEvent struct {
 }
 
 type NotificationEvent struct {
-	NotificationID   string    `json:"notificationId"`
-	AccountNumber    string    `json:"accountNumber"`
-	NotificationType string    `json:"notificationType"` 
-	Timestamp        time.Time `json:"timestamp"`
-	TransactionID    string    `json:"transactionId,omitempty"`
-	Amount           float64   `json:"amount"`
-	Balance          float64   `json:"balance"`
-	Description      string    `json:"description"`
-	Reference        string    `json:"reference,omitempty"`
-	CounterParty     string    `json:"counterParty,omitempty"`
+	NotificationID   string       `json:"notificationId"`
+	AccountNumber    string       `json:"accountNumber"`
+	NotificationType string       `json:"notificationType"`
+	Timestamp        time.Time    `json:"timestamp"`
+	TransactionID    string       `json:"transactionId,omitempty"`
+	Amount           money.Number `json:"amount"`
+	Balance          money.Number `json:"balance"`
+	Description      string       `json:"description"`
+	Reference        string       `json:"reference,omitempty"`
+	CounterParty     string       `json:"counterParty,omitempty"`
 }
 
 type WebhookHandler struct {
 	webhookSecret string
+	verifier      *signature.Verifier
 	handlers      map[string]WebhookEventHandler
 }
 type WebhookEventHandler func(event WebhookEvent) error
@@ -44,10 +48,17 @@ type WebhookEventHandler func(event WebhookEvent) error
 func NewWebhookHandler(webhookSecret string) *WebhookHandler {
 	return &WebhookHandler{
 		webhookSecret: webhookSecret,
+		verifier:      signature.New(),
 		handlers:      make(map[string]WebhookEventHandler),
 	}
 }
 
+// SetVerifier replaces the policy webhooks are verified with, e.g. to share
+// a signature.Store between instances or change the timestamp tolerance.
+func (wh *WebhookHandler) SetVerifier(verifier *signature.Verifier) {
+	wh.verifier = verifier
+}
+
 func (wh *WebhookHandler) RegisterHandler(eventType string, handler WebhookEventHandler) {
 	wh.handlers[eventType] = handler
 }
@@ -66,8 +77,8 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 	defer r.Body.Close()
 
 	
-	signature := r.Header.Get("X-FNB-Signature")
-	if !wh.verifySignature(body, signature) {
+	sig := r.Header.Get(WebhookSignatureHeader)
+	if !wh.verifySignature(body, sig) {
 		http.Error(w, "Invalid signature", http.StatusUnauthorized)
 		return
 	}
@@ -79,6 +90,29 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Events without an ID are only checked for their timestamp
+	var id string
+	if event.EventID != "" {
+		id = "fnb:" + event.EventID
+	}
+	if err := wh.verifier.Check(r.Context(), id, event.Timestamp); err != nil {
+		if errors.Is(err, signature.ErrReplayed) {
+			// Already handled, acknowledge so it is not sent again
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{
+				"status": "duplicate",
+				"eventId": event.EventID,
+			})
+			return
+		}
+		if errors.Is(err, signature.ErrTimestamp) {
+			http.Error(w, "Invalid timestamp", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to check event", http.StatusInternalServerError)
+		return
+	}
+
 	
 	handler, exists := wh.handlers[event.EventType]
 	if !exists {
@@ -93,6 +127,8 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 
 	
 	if err := handler(event); err != nil {
+		// Let FNB send the event again
+		wh.verifier.Forget(r.Context(), id)
 		http.Error(w, fmt.Sprintf("Handler error: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -105,16 +141,16 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-func (wh *WebhookHandler) verifySignature(payload []byte, signature string) bool {
-	if wh.webhookSecret == "" {
-		return true
-	}
-
-	mac := hmac.New(sha256.New, []byte(wh.webhookSecret))
-	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
+// verifySignature refuses webhooks when no secret is set, unless the
+// verifier allows unsigned webhooks.
+func (wh *WebhookHandler) verifySignature(payload []byte, sig string) bool {
+	return wh.verifier.Verify(payload, sig, wh.webhookSecret) == nil
+}
 
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+// ValidateWebhookSignature reports whether sig is the hex HMAC-SHA256 of
+// payload keyed with secret. It compares in constant time.
+func ValidateWebhookSignature(payload []byte, sig, secret string) bool {
+	return signature.Valid(payload, sig, secret)
 }
 
 func ParseWebhookEvent(data []byte) (*WebhookEvent, error) {
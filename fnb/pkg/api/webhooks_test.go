@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -122,6 +123,96 @@ func TestWebhookHandlerNoHandler(t *testing.T) {
 	}
 }
 
+func TestWebhookHandlerReplay(t *testing.T) {
+	secret := "test-webhook-secret"
+	handler := NewWebhookHandler(secret)
+
+	calls := 0
+	handler.RegisterHandler("payment.completed", func(event WebhookEvent) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database down")
+		}
+		return nil
+	})
+
+	send := func(timestamp time.Time) int {
+		eventJSON, _ := json.Marshal(WebhookEvent{
+			EventID:   "EVT123456",
+			EventType: "payment.completed",
+			Timestamp: timestamp,
+		})
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(eventJSON)
+
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(eventJSON))
+		req.Header.Set("X-FNB-Signature", hex.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		return w.Code
+	}
+
+	// A failed event can be sent again, a handled one is dropped
+	now := time.Now()
+	for i, want := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		if code := send(now); code != want {
+			t.Errorf("Delivery %d: expected status %d, got %d", i+1, want, code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the handler to be called twice, got %d", calls)
+	}
+
+	if code := send(now.Add(-time.Hour)); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a stale event, got %d", code)
+	}
+}
+
+func TestWebhookHandlerNoEventID(t *testing.T) {
+	secret := "test-webhook-secret"
+	handler := NewWebhookHandler(secret)
+
+	var handled []string
+	handler.RegisterHandler("payment.completed", func(event WebhookEvent) error {
+		handled = append(handled, event.Data["paymentId"].(string))
+		return nil
+	})
+
+	// Events without an ID cannot be told apart, so none is a replay
+	for _, paymentID := range []string{"PAY1", "PAY2"} {
+		eventJSON, _ := json.Marshal(WebhookEvent{
+			EventType: "payment.completed",
+			Timestamp: time.Now(),
+			Data:      map[string]interface{}{"paymentId": paymentID},
+		})
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(eventJSON)
+
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(eventJSON))
+		req.Header.Set("X-FNB-Signature", hex.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		handler.HandleWebhook(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %s, got %d", paymentID, w.Code)
+		}
+	}
+	if len(handled) != 2 {
+		t.Errorf("Expected both events to be handled, got %v", handled)
+	}
+}
+
+func TestWebhookHandlerNoSecret(t *testing.T) {
+	handler := NewWebhookHandler("")
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader([]byte(`{"eventId":"EVT123456"}`)))
+	w := httptest.NewRecorder()
+	handler.HandleWebhook(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 without a webhook secret, got %d", w.Code)
+	}
+}
+
 func TestWebhookHandlerMethodNotAllowed(t *testing.T) {
 	handler := NewWebhookHandler("secret")
 
@@ -180,8 +271,8 @@ func TestNotificationHandler(t *testing.T) {
 		AccountNumber:    "1234567890",
 		NotificationType: "CREDIT",
 		Timestamp:        time.Now(),
-		Amount:           500.00,
-		Balance:          10000.00,
+		Amount:           number("500.00"),
+		Balance:          number("10000.00"),
 		Description:      "Payment received",
 	}
 
@@ -1,9 +1,16 @@
 package jenga
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 	"github.com/nutcas3/payment-rails/jenga/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/signature"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -23,6 +30,46 @@ func NewClient(apiKey, username, password, privateKey, environment string) (*Cli
 	}, nil
 }
 
+// SetHttpClient replaces the HTTP client. Call it before Use
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.apiClient.SetHttpClient(httpClient)
+}
+
+// SetBaseURL overrides the API base URL the environment selects
+func (c *Client) SetBaseURL(baseURL string) {
+	c.apiClient.SetBaseURL(baseURL)
+}
+
+// SetTokenStore sets the store used to share access tokens between clients
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.apiClient.SetTokenStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.apiClient.SetTokenSkew(skew)
+}
+
+// SetRetryPolicy sets how failed requests are retried
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.apiClient.SetRetryPolicy(policy)
+}
+
+// SetCircuitBreaker sets when requests to a failing endpoint are stopped
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.apiClient.SetCircuitBreaker(settings)
+}
+
+// Health reports the state of the circuit breaker
+func (c *Client) Health() breaker.Health {
+	return c.apiClient.Health()
+}
+
+// Use runs every request through middleware, see transport.Middleware
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.apiClient.Use(middleware...)
+}
+
 // SetWebhookSecret sets the webhook secret for validating webhook signatures
 func (c *Client) SetWebhookSecret(webhookSecret string) {
 	c.webhookHandler = api.NewWebhookHandler(webhookSecret)
@@ -37,67 +84,137 @@ func (c *Client) HandleWebhook(w http.ResponseWriter, r *http.Request, handlers
 	return nil
 }
 
+// SetWebhookVerifier sets the policy webhook signatures, timestamps and
+// replays are checked with
+func (c *Client) SetWebhookVerifier(verifier *signature.Verifier) error {
+	if c.webhookHandler == nil {
+		return fmt.Errorf("webhook handler not initialized, call SetWebhookSecret first")
+	}
+	c.webhookHandler.Verifier = verifier
+	return nil
+}
+
 func (c *Client) GetAccountBalance(req api.AccountBalanceRequest) (*api.AccountBalanceResponse, error) {
-	return c.apiClient.GetAccountBalance(req)
+	return c.GetAccountBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context, req api.AccountBalanceRequest) (*api.AccountBalanceResponse, error) {
+	return c.apiClient.GetAccountBalanceWithContext(ctx, req)
 }
 
 func (c *Client) GetMiniStatement(req api.MiniStatementRequest) (*api.MiniStatementResponse, error) {
-	return c.apiClient.GetMiniStatement(req)
+	return c.GetMiniStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetMiniStatementWithContext(ctx context.Context, req api.MiniStatementRequest) (*api.MiniStatementResponse, error) {
+	return c.apiClient.GetMiniStatementWithContext(ctx, req)
 }
 
 func (c *Client) GetFullStatement(req api.FullStatementRequest) (*api.FullStatementResponse, error) {
-	return c.apiClient.GetFullStatement(req)
+	return c.GetFullStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetFullStatementWithContext(ctx context.Context, req api.FullStatementRequest) (*api.FullStatementResponse, error) {
+	return c.apiClient.GetFullStatementWithContext(ctx, req)
 }
 
 func (c *Client) ValidateAccount(req api.AccountValidateRequest) (*api.AccountValidateResponse, error) {
-	return c.apiClient.ValidateAccount(req)
+	return c.ValidateAccountWithContext(context.Background(), req)
+}
+
+func (c *Client) ValidateAccountWithContext(ctx context.Context, req api.AccountValidateRequest) (*api.AccountValidateResponse, error) {
+	return c.apiClient.ValidateAccountWithContext(ctx, req)
 }
 
 
 func (c *Client) SendMoney(req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
-	return c.apiClient.SendMoney(req)
+	return c.SendMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) SendMoneyWithContext(ctx context.Context, req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
+	return c.apiClient.SendMoneyWithContext(ctx, req)
 }
 
 func (c *Client) SendToMobileWallet(req api.MobileWalletRequest) (*api.MobileWalletResponse, error) {
-	return c.apiClient.SendToMobileWallet(req)
+	return c.SendToMobileWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) SendToMobileWalletWithContext(ctx context.Context, req api.MobileWalletRequest) (*api.MobileWalletResponse, error) {
+	return c.apiClient.SendToMobileWalletWithContext(ctx, req)
 }
 
 func (c *Client) SendInternalBankTransfer(req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
-	return c.apiClient.SendInternalBankTransfer(req)
+	return c.SendInternalBankTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) SendInternalBankTransferWithContext(ctx context.Context, req api.SendMoneyRequest) (*api.SendMoneyResponse, error) {
+	return c.apiClient.SendInternalBankTransferWithContext(ctx, req)
 }
 
 
 func (c *Client) PayBill(req api.BillPaymentRequest) (*api.BillPaymentResponse, error) {
-	return c.apiClient.PayBill(req)
+	return c.PayBillWithContext(context.Background(), req)
+}
+
+func (c *Client) PayBillWithContext(ctx context.Context, req api.BillPaymentRequest) (*api.BillPaymentResponse, error) {
+	return c.apiClient.PayBillWithContext(ctx, req)
 }
 
 func (c *Client) ReceiveMoney(req api.ReceiveMoneyRequest) (*api.ReceiveMoneyResponse, error) {
-	return c.apiClient.ReceiveMoney(req)
+	return c.ReceiveMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) ReceiveMoneyWithContext(ctx context.Context, req api.ReceiveMoneyRequest) (*api.ReceiveMoneyResponse, error) {
+	return c.apiClient.ReceiveMoneyWithContext(ctx, req)
 }
 
 func (c *Client) QueryReceiveMoneyTransaction(req api.ReceiveMoneyQueryRequest) (*api.ReceiveMoneyQueryResponse, error) {
-	return c.apiClient.QueryReceiveMoneyTransaction(req)
+	return c.QueryReceiveMoneyTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) QueryReceiveMoneyTransactionWithContext(ctx context.Context, req api.ReceiveMoneyQueryRequest) (*api.ReceiveMoneyQueryResponse, error) {
+	return c.apiClient.QueryReceiveMoneyTransactionWithContext(ctx, req)
 }
 
 func (c *Client) PurchaseAirtime(req api.AirtimePurchaseRequest) (*api.AirtimePurchaseResponse, error) {
-	return c.apiClient.PurchaseAirtime(req)
+	return c.PurchaseAirtimeWithContext(context.Background(), req)
+}
+
+func (c *Client) PurchaseAirtimeWithContext(ctx context.Context, req api.AirtimePurchaseRequest) (*api.AirtimePurchaseResponse, error) {
+	return c.apiClient.PurchaseAirtimeWithContext(ctx, req)
 }
 
 
 func (c *Client) VerifyIdentity(req api.KYCRequest) (*api.KYCResponse, error) {
-	return c.apiClient.VerifyIdentity(req)
+	return c.VerifyIdentityWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyIdentityWithContext(ctx context.Context, req api.KYCRequest) (*api.KYCResponse, error) {
+	return c.apiClient.VerifyIdentityWithContext(ctx, req)
 }
 
 func (c *Client) PerformAMLScreening(req api.AMLScreeningRequest) (*api.AMLScreeningResponse, error) {
-	return c.apiClient.PerformAMLScreening(req)
+	return c.PerformAMLScreeningWithContext(context.Background(), req)
+}
+
+func (c *Client) PerformAMLScreeningWithContext(ctx context.Context, req api.AMLScreeningRequest) (*api.AMLScreeningResponse, error) {
+	return c.apiClient.PerformAMLScreeningWithContext(ctx, req)
 }
 
 func (c *Client) PerformCustomerDueDiligence(req api.CDDRequest) (*api.CDDResponse, error) {
-	return c.apiClient.PerformCustomerDueDiligence(req)
+	return c.PerformCustomerDueDiligenceWithContext(context.Background(), req)
+}
+
+func (c *Client) PerformCustomerDueDiligenceWithContext(ctx context.Context, req api.CDDRequest) (*api.CDDResponse, error) {
+	return c.apiClient.PerformCustomerDueDiligenceWithContext(ctx, req)
 }
 
 func (c *Client) GetForexRates(req api.ForexRatesRequest) (*api.ForexRatesResponse, error) {
-	return c.apiClient.GetForexRates(req)
+	return c.GetForexRatesWithContext(context.Background(), req)
+}
+
+func (c *Client) GetForexRatesWithContext(ctx context.Context, req api.ForexRatesRequest) (*api.ForexRatesResponse, error) {
+	return c.apiClient.GetForexRatesWithContext(ctx, req)
 }
 
 // GenerateReference generates a unique reference ID for transactions
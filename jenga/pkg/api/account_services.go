@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,10 @@ const (
 )
 
 func (c *Client) GetAccountBalance(req AccountBalanceRequest) (*AccountBalanceResponse, error) {
+	return c.GetAccountBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context, req AccountBalanceRequest) (*AccountBalanceResponse, error) {
 	if req.CountryCode == "" || req.AccountID == "" {
 		return nil, fmt.Errorf("countryCode and accountId are required")
 	}
@@ -35,7 +40,7 @@ func (c *Client) GetAccountBalance(req AccountBalanceRequest) (*AccountBalanceRe
 
 	signatureData := req.AccountID
 
-	respBody, err := c.SendRequest(http.MethodGet, endpoint, nil, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodGet, endpoint, nil, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +54,10 @@ func (c *Client) GetAccountBalance(req AccountBalanceRequest) (*AccountBalanceRe
 }
 
 func (c *Client) GetMiniStatement(req MiniStatementRequest) (*MiniStatementResponse, error) {
+	return c.GetMiniStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetMiniStatementWithContext(ctx context.Context, req MiniStatementRequest) (*MiniStatementResponse, error) {
 	if req.CountryCode == "" || req.AccountID == "" {
 		return nil, fmt.Errorf("countryCode and accountId are required")
 	}
@@ -71,7 +80,7 @@ func (c *Client) GetMiniStatement(req MiniStatementRequest) (*MiniStatementRespo
 	// Updated signature generation according to API documentation
 	signatureData := req.CountryCode + req.AccountID
 
-	respBody, err := c.SendRequest(http.MethodGet, endpoint, nil, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodGet, endpoint, nil, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -85,6 +94,10 @@ func (c *Client) GetMiniStatement(req MiniStatementRequest) (*MiniStatementRespo
 }
 
 func (c *Client) GetFullStatement(req FullStatementRequest) (*FullStatementResponse, error) {
+	return c.GetFullStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetFullStatementWithContext(ctx context.Context, req FullStatementRequest) (*FullStatementResponse, error) {
 	if req.CountryCode == "" || req.AccountID == "" || req.FromDate == "" || req.ToDate == "" {
 		return nil, fmt.Errorf("countryCode, accountId, fromDate, and toDate are required")
 	}
@@ -101,7 +114,7 @@ func (c *Client) GetFullStatement(req FullStatementRequest) (*FullStatementRespo
 	// Updated signature generation according to API documentation
 	signatureData := req.AccountID + req.CountryCode + req.ToDate
 
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +132,10 @@ func joinQueryParams(params []string) string {
 }
 
 func (c *Client) ValidateAccount(req AccountValidateRequest) (*AccountValidateResponse, error) {
+	return c.ValidateAccountWithContext(context.Background(), req)
+}
+
+func (c *Client) ValidateAccountWithContext(ctx context.Context, req AccountValidateRequest) (*AccountValidateResponse, error) {
 	if req.CountryCode == "" || req.AccountNumber == "" || req.AccountFullName == "" {
 		return nil, fmt.Errorf("countryCode, accountNumber, and accountFullName are required")
 	}
@@ -130,7 +147,7 @@ func (c *Client) ValidateAccount(req AccountValidateRequest) (*AccountValidateRe
 
 	signatureData := req.CountryCode + req.AccountNumber + req.AccountFullName
 
-	respBody, err := c.SendRequest(http.MethodPost, accountValidateEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, accountValidateEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,6 +12,10 @@ const (
 )
 
 func (c *Client) PurchaseAirtime(req AirtimePurchaseRequest) (*AirtimePurchaseResponse, error) {
+	return c.PurchaseAirtimeWithContext(context.Background(), req)
+}
+
+func (c *Client) PurchaseAirtimeWithContext(ctx context.Context, req AirtimePurchaseRequest) (*AirtimePurchaseResponse, error) {
 	if req.CustomerMobile == "" || req.TelcoCode == "" || req.Amount == "" || req.Reference == "" || req.CurrencyCode == "" {
 		return nil, fmt.Errorf("customerMobile, telcoCode, amount, reference, and currencyCode are required")
 	}
@@ -22,7 +27,7 @@ func (c *Client) PurchaseAirtime(req AirtimePurchaseRequest) (*AirtimePurchaseRe
 
 	signatureData := req.CustomerMobile + req.TelcoCode + req.Amount + req.CurrencyCode
 
-	respBody, err := c.SendRequest(http.MethodPost, airtimePurchaseEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, airtimePurchaseEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
@@ -16,7 +17,11 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 const (
@@ -27,8 +32,6 @@ const (
 	productionBaseURL = "https://api.finserve.africa/v3-apis"
 
 	authEndpoint = "/authentication/api/v3/authenticate/merchant"
-
-	tokenCacheKey = "jenga_auth_token"
 )
 
 type Client struct {
@@ -39,13 +42,15 @@ type Client struct {
 	Environment string
 	BaseURL     string
 	HTTPClient  *http.Client
-	TokenCache  *cache.Cache
+	tokens      *auth.TokenManager
+	retry       retry.Policy
+	breaker     *breaker.Breaker
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken string         `json:"access_token"`
+	TokenType   string         `json:"token_type"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
 }
 
 type ErrorResponse struct {
@@ -64,9 +69,7 @@ func NewClient(apiKey, username, password, privateKey, environment string) (*Cli
 		baseURL = productionBaseURL
 	}
 
-	tokenCache := cache.New(50*time.Minute, 10*time.Minute)
-
-	return &Client{
+	c := &Client{
 		APIKey:      apiKey,
 		Username:    username,
 		Password:    password,
@@ -74,63 +77,122 @@ func NewClient(apiKey, username, password, privateKey, environment string) (*Cli
 		Environment: environment,
 		BaseURL:     baseURL,
 		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
-		TokenCache:  tokenCache,
-	}, nil
+	}
+	c.tokens = auth.NewTokenManager(nil, auth.Key("jenga", environment, apiKey+":"+username), c.fetchAuthToken)
+	c.retry = retry.DefaultPolicy()
+	c.breaker = breaker.New(provider, breaker.DefaultSettings())
+
+	return c, nil
 }
 
 func (c *Client) SetHttpClient(httpClient *http.Client) {
 	c.HTTPClient = httpClient
 }
 
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server. Tokens fetched from
+// baseURL are stored apart from the environment's.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.BaseURL = baseURL
+	c.tokens.SetKey(auth.Key("jenga", baseURL, c.APIKey+":"+c.Username))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same API key reuse one token instead of each requesting their own.
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.tokens.SetStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.HTTPClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.HTTPClient = transport.Wrap(c.HTTPClient, provider, middleware...)
+}
+
 func (c *Client) GetAuthToken() (string, error) {
-	if token, found := c.TokenCache.Get(tokenCacheKey); found {
-		return token.(string), nil
+	return c.GetAuthTokenWithContext(context.Background())
+}
+
+func (c *Client) GetAuthTokenWithContext(ctx context.Context) (string, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
+func (c *Client) fetchAuthToken(ctx context.Context) (auth.Token, error) {
 	reqBody := map[string]string{
 		"username": c.Username,
 		"password": c.Password,
 	}
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("error marshaling auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error marshaling auth request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.BaseURL+authEndpoint, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+authEndpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return "", fmt.Errorf("error creating auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error creating auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Api-Key", c.APIKey)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return "", fmt.Errorf("error sending auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error sending auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("error reading auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("error reading auth response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err == nil {
-			return "", fmt.Errorf("auth error: %s (code: %d)", errResp.Message, errResp.Code)
-		}
-		return "", fmt.Errorf("auth error: %s", string(body))
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 
 	var authResp AuthResponse
 	if err := json.Unmarshal(body, &authResp); err != nil {
-		return "", fmt.Errorf("error parsing auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("error parsing auth response: %w", err)
 	}
 
-	c.TokenCache.Set(tokenCacheKey, authResp.AccessToken, time.Duration(authResp.ExpiresIn)*time.Second)
-
-	return authResp.AccessToken, nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
 func (c *Client) GenerateSignature(data string) (string, error) {
@@ -155,39 +217,45 @@ func (c *Client) GenerateSignature(data string) (string, error) {
 }
 
 func (c *Client) SendRequest(method, endpoint string, body interface{}, signatureData string) ([]byte, error) {
-	token, err := c.GetAuthToken()
-	if err != nil {
-		return nil, err
-	}
+	return c.SendRequestWithContext(context.Background(), method, endpoint, body, signatureData)
+}
 
+func (c *Client) SendRequestWithContext(ctx context.Context, method, endpoint string, body interface{}, signatureData string) ([]byte, error) {
 	var jsonBody []byte
 	if body != nil {
+		var err error
 		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling request: %w", err)
 		}
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL+endpoint, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+	resp, err := c.tokens.Do(ctx, func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, bytes.NewBuffer(jsonBody))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Api-Key", c.APIKey)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Api-Key", c.APIKey)
 
-	if signatureData != "" {
-		signature, err := c.GenerateSignature(signatureData)
-		if err != nil {
-			return nil, err
+		if signatureData != "" {
+			signature, err := c.GenerateSignature(signatureData)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Signature", signature)
 		}
-		req.Header.Set("Signature", signature)
-	}
 
-	resp, err := c.HTTPClient.Do(req)
+		resp, err := c.retry.Do(req, c.roundTrip)
+		if err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+		return resp, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -197,18 +265,14 @@ func (c *Client) SendRequest(method, endpoint string, body interface{}, signatur
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			return nil, fmt.Errorf("API error: %s (code: %d)", errResp.Message, errResp.Code)
-		}
-		return nil, fmt.Errorf("API error: %s", string(respBody))
+		return nil, newProviderError(resp, respBody)
 	}
 
 	return respBody, nil
 }
 
+// GenerateReference returns a 20-digit transaction reference that does not
+// collide across processes, see idempotency.NewNumericReference.
 func GenerateReference() string {
-	timestamp := time.Now().Format("20060102150405")
-	random := fmt.Sprintf("%06d", time.Now().Nanosecond()/1000)
-	return timestamp + random[:6]
+	return idempotency.NewNumericReference()
 }
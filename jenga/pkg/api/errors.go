@@ -0,0 +1,27 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "jenga"
+
+// newProviderError builds the error for a rejected request from its response
+// and an ErrorResponse body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	message := string(body)
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		message = errResp.Message
+	}
+
+	var code string
+	if errResp.Code != 0 {
+		code = strconv.Itoa(errResp.Code)
+	}
+	return railerrors.FromResponse(provider, resp, code, message)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,6 +15,10 @@ const (
 )
 
 func (c *Client) VerifyIdentity(req KYCRequest) (*KYCResponse, error) {
+	return c.VerifyIdentityWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyIdentityWithContext(ctx context.Context, req KYCRequest) (*KYCResponse, error) {
 	if req.DocumentType == "" || req.DocumentNumber == "" || req.CountryCode == "" {
 		return nil, fmt.Errorf("documentType, documentNumber, and countryCode are required")
 	}
@@ -26,7 +31,7 @@ func (c *Client) VerifyIdentity(req KYCRequest) (*KYCResponse, error) {
 
 	signatureData := req.DocumentType + req.DocumentNumber + req.CountryCode
 
-	respBody, err := c.SendRequest(http.MethodPost, kycEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, kycEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +46,11 @@ func (c *Client) VerifyIdentity(req KYCRequest) (*KYCResponse, error) {
 
 // PerformAMLScreening performs Anti-Money Laundering screening on an individual
 func (c *Client) PerformAMLScreening(req AMLScreeningRequest) (*AMLScreeningResponse, error) {
+	return c.PerformAMLScreeningWithContext(context.Background(), req)
+}
+
+// PerformAMLScreeningWithContext is like PerformAMLScreening but takes a context.
+func (c *Client) PerformAMLScreeningWithContext(ctx context.Context, req AMLScreeningRequest) (*AMLScreeningResponse, error) {
 	if req.FirstName == "" || req.LastName == "" || req.CountryCode == "" {
 		return nil, fmt.Errorf("firstName, lastName, and countryCode are required")
 	}
@@ -55,7 +65,7 @@ func (c *Client) PerformAMLScreening(req AMLScreeningRequest) (*AMLScreeningResp
 	signatureData := req.FirstName + req.LastName + req.CountryCode
 
 	// Send POST request
-	respBody, err := c.SendRequest(http.MethodPost, amlScreeningEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, amlScreeningEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +80,11 @@ func (c *Client) PerformAMLScreening(req AMLScreeningRequest) (*AMLScreeningResp
 
 // PerformCustomerDueDiligence performs Customer Due Diligence (CDD) checks
 func (c *Client) PerformCustomerDueDiligence(req CDDRequest) (*CDDResponse, error) {
+	return c.PerformCustomerDueDiligenceWithContext(context.Background(), req)
+}
+
+// PerformCustomerDueDiligenceWithContext is like PerformCustomerDueDiligence but takes a context.
+func (c *Client) PerformCustomerDueDiligenceWithContext(ctx context.Context, req CDDRequest) (*CDDResponse, error) {
 	if req.CustomerID == "" || req.CountryCode == "" {
 		return nil, fmt.Errorf("customerID and countryCode are required")
 	}
@@ -84,7 +99,7 @@ func (c *Client) PerformCustomerDueDiligence(req CDDRequest) (*CDDResponse, erro
 	signatureData := req.CustomerID + req.CountryCode
 
 	// Send POST request
-	respBody, err := c.SendRequest(http.MethodPost, cddEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, cddEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +113,10 @@ func (c *Client) PerformCustomerDueDiligence(req CDDRequest) (*CDDResponse, erro
 }
 
 func (c *Client) GetForexRates(req ForexRatesRequest) (*ForexRatesResponse, error) {
+	return c.GetForexRatesWithContext(context.Background(), req)
+}
+
+func (c *Client) GetForexRatesWithContext(ctx context.Context, req ForexRatesRequest) (*ForexRatesResponse, error) {
 	if req.CountryCode == "" || req.CurrencyCode == "" {
 		return nil, fmt.Errorf("countryCode and currencyCode are required")
 	}
@@ -110,7 +129,7 @@ func (c *Client) GetForexRates(req ForexRatesRequest) (*ForexRatesResponse, erro
 
 	signatureData := req.CountryCode + req.CurrencyCode
 
-	respBody, err := c.SendRequest(http.MethodGet, endpoint, nil, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodGet, endpoint, nil, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,6 +12,10 @@ const (
 )
 
 func (c *Client) PayBill(req BillPaymentRequest) (*BillPaymentResponse, error) {
+	return c.PayBillWithContext(context.Background(), req)
+}
+
+func (c *Client) PayBillWithContext(ctx context.Context, req BillPaymentRequest) (*BillPaymentResponse, error) {
 	if req.BillerCode == "" || req.AccountNumber == "" || req.Amount == "" || 
 	   req.Reference == "" || req.CurrencyCode == "" {
 		return nil, fmt.Errorf("missing required fields in BillPaymentRequest")
@@ -18,7 +23,7 @@ func (c *Client) PayBill(req BillPaymentRequest) (*BillPaymentResponse, error) {
 
 	signatureData := req.BillerCode + req.AccountNumber + req.Amount + req.Reference
 
-	respBody, err := c.SendRequest(http.MethodPost, billPaymentEndpoint, req, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, billPaymentEndpoint, req, signatureData)
 	if err != nil {
 		return nil, err
 	}
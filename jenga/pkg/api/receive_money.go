@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,6 +13,10 @@ const (
 )
 
 func (c *Client) ReceiveMoney(req ReceiveMoneyRequest) (*ReceiveMoneyResponse, error) {
+	return c.ReceiveMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) ReceiveMoneyWithContext(ctx context.Context, req ReceiveMoneyRequest) (*ReceiveMoneyResponse, error) {
 	if req.MerchantCode == "" || req.MerchantAccount == "" || req.CustomerName == "" || req.Amount == "" || req.CurrencyCode == "" || req.Reference == "" || req.Description == "" {
 		return nil, fmt.Errorf("merchantCode, merchantAccount, customerName, amount, currencyCode, reference, and description are required")
 	}
@@ -23,7 +28,7 @@ func (c *Client) ReceiveMoney(req ReceiveMoneyRequest) (*ReceiveMoneyResponse, e
 
 	signatureData := req.MerchantCode + req.MerchantAccount + req.Amount + req.CurrencyCode
 
-	respBody, err := c.SendRequest(http.MethodPost, receiveMoneyEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, receiveMoneyEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +42,10 @@ func (c *Client) ReceiveMoney(req ReceiveMoneyRequest) (*ReceiveMoneyResponse, e
 }
 
 func (c *Client) QueryReceiveMoneyTransaction(req ReceiveMoneyQueryRequest) (*ReceiveMoneyQueryResponse, error) {
+	return c.QueryReceiveMoneyTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) QueryReceiveMoneyTransactionWithContext(ctx context.Context, req ReceiveMoneyQueryRequest) (*ReceiveMoneyQueryResponse, error) {
 	if req.MerchantCode == "" || req.TransactionID == "" {
 		return nil, fmt.Errorf("merchantCode and transactionId are required")
 	}
@@ -48,7 +57,7 @@ func (c *Client) QueryReceiveMoneyTransaction(req ReceiveMoneyQueryRequest) (*Re
 
 	signatureData := req.MerchantCode + req.TransactionID
 
-	respBody, err := c.SendRequest(http.MethodPost, receiveMoneyQueryEndpoint, requestBody, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, receiveMoneyQueryEndpoint, requestBody, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,10 @@ const (
 )
 
 func (c *Client) SendMoney(req SendMoneyRequest) (*SendMoneyResponse, error) {
+	return c.SendMoneyWithContext(context.Background(), req)
+}
+
+func (c *Client) SendMoneyWithContext(ctx context.Context, req SendMoneyRequest) (*SendMoneyResponse, error) {
 	if req.Source.CountryCode == "" || req.Source.AccountNumber == "" || 
 	   req.Destination.CountryCode == "" || req.Destination.AccountNumber == "" ||
 	   req.Transfer.Amount == "" || req.Transfer.CurrencyCode == "" || req.Transfer.Reference == "" {
@@ -35,7 +40,7 @@ func (c *Client) SendMoney(req SendMoneyRequest) (*SendMoneyResponse, error) {
 
 	signatureData := req.Source.AccountNumber + req.Transfer.Amount + req.Transfer.CurrencyCode + req.Transfer.Reference
 
-	respBody, err := c.SendRequest(http.MethodPost, endpoint, req, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, endpoint, req, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +54,10 @@ func (c *Client) SendMoney(req SendMoneyRequest) (*SendMoneyResponse, error) {
 }
 
 func (c *Client) SendToMobileWallet(req MobileWalletRequest) (*MobileWalletResponse, error) {
+	return c.SendToMobileWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) SendToMobileWalletWithContext(ctx context.Context, req MobileWalletRequest) (*MobileWalletResponse, error) {
 	if req.Source.CountryCode == "" || req.Source.AccountNumber == "" || 
 	   req.Destination.CountryCode == "" || req.Destination.MobileNumber == "" || req.Destination.WalletName == "" ||
 	   req.Transfer.Amount == "" || req.Transfer.CurrencyCode == "" || req.Transfer.Reference == "" ||
@@ -58,7 +67,7 @@ func (c *Client) SendToMobileWallet(req MobileWalletRequest) (*MobileWalletRespo
 
 	signatureData := req.Source.AccountNumber + req.Transfer.Amount + req.Transfer.CurrencyCode + req.Transfer.Reference
 
-	respBody, err := c.SendRequest(http.MethodPost, mobileWalletEndpoint, req, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, mobileWalletEndpoint, req, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +83,11 @@ func (c *Client) SendToMobileWallet(req MobileWalletRequest) (*MobileWalletRespo
 // SendInternalBankTransfer sends money between Equity Bank accounts
 // This function is specifically for transfers within Equity Bank across Kenya, Uganda, Tanzania, Rwanda & South Sudan
 func (c *Client) SendInternalBankTransfer(req SendMoneyRequest) (*SendMoneyResponse, error) {
+	return c.SendInternalBankTransferWithContext(context.Background(), req)
+}
+
+// SendInternalBankTransferWithContext is like SendInternalBankTransfer but takes a context.
+func (c *Client) SendInternalBankTransferWithContext(ctx context.Context, req SendMoneyRequest) (*SendMoneyResponse, error) {
 	if req.Source.CountryCode == "" || req.Source.AccountNumber == "" || 
 	   req.Destination.CountryCode == "" || req.Destination.AccountNumber == "" ||
 	   req.Transfer.Amount == "" || req.Transfer.CurrencyCode == "" || req.Transfer.Reference == "" ||
@@ -86,7 +100,7 @@ func (c *Client) SendInternalBankTransfer(req SendMoneyRequest) (*SendMoneyRespo
 
 	signatureData := req.Source.AccountNumber + req.Transfer.Amount + req.Transfer.CurrencyCode + req.Transfer.Reference
 
-	respBody, err := c.SendRequest(http.MethodPost, internalBankTransferEndpoint, req, signatureData)
+	respBody, err := c.SendRequestWithContext(ctx, http.MethodPost, internalBankTransferEndpoint, req, signatureData)
 	if err != nil {
 		return nil, err
 	}
@@ -1,51 +1,60 @@
 package api
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
+const WebhookSignatureHeader = "X-Jenga-Signature"
+
 type WebhookHandler struct {
 	WebhookSecret string
+
+	// Verifier checks signatures, timestamps and replayed event IDs.
+	// NewWebhookHandler sets signature.New().
+	Verifier *signature.Verifier
 }
 
 func NewWebhookHandler(webhookSecret string) *WebhookHandler {
 	return &WebhookHandler{
 		WebhookSecret: webhookSecret,
+		Verifier:      signature.New(),
 	}
 }
 
-func (h *WebhookHandler) ValidateSignature(payload []byte, signature string) bool {
-	if h.WebhookSecret == "" || signature == "" {
-		return false
+func (h *WebhookHandler) verifier() *signature.Verifier {
+	if h.Verifier == nil {
+		return &signature.Verifier{}
 	}
+	return h.Verifier
+}
 
-	mac := hmac.New(sha256.New, []byte(h.WebhookSecret))
-	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-	return strings.EqualFold(signature, expectedSignature)
+// ValidateSignature reports whether signature is the HMAC-SHA256 of payload
+// keyed with WebhookSecret. It compares in constant time.
+func (h *WebhookHandler) ValidateSignature(payload []byte, signature string) bool {
+	return h.verifier().Verify(payload, signature, h.WebhookSecret) == nil
 }
 
+// ParseWebhookRequest verifies and decodes a webhook. A webhook whose ID was
+// already received fails with signature.ErrReplayed.
 func (h *WebhookHandler) ParseWebhookRequest(r *http.Request) (*WebhookEvent, error) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error reading request body: %w", err)
 	}
 
-	signature := r.Header.Get("X-Jenga-Signature")
-	if signature == "" {
-		return nil, fmt.Errorf("missing X-Jenga-Signature header")
+	sig := r.Header.Get(WebhookSignatureHeader)
+	if sig == "" && h.WebhookSecret != "" {
+		return nil, fmt.Errorf("missing %s header", WebhookSignatureHeader)
 	}
 
-	if !h.ValidateSignature(body, signature) {
-		return nil, fmt.Errorf("invalid webhook signature")
+	if err := h.verifier().Verify(body, sig, h.WebhookSecret); err != nil {
+		return nil, fmt.Errorf("invalid webhook signature: %w", err)
 	}
 
 	var event WebhookEvent
@@ -53,11 +62,26 @@ func (h *WebhookHandler) ParseWebhookRequest(r *http.Request) (*WebhookEvent, er
 		return nil, fmt.Errorf("error parsing webhook event: %w", err)
 	}
 
+	// Webhooks without an ID are only checked for their timestamp
+	var id string
+	if event.ID != "" {
+		id = "jenga:" + event.ID
+	}
+	if err := h.verifier().Check(r.Context(), id, event.CreatedAt); err != nil {
+		return nil, fmt.Errorf("rejected webhook %s: %w", event.ID, err)
+	}
+
 	return &event, nil
 }
 
 func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request, handlers WebhookHandlers) {
 	event, err := h.ParseWebhookRequest(r)
+	if errors.Is(err, signature.ErrReplayed) {
+		// Already handled, acknowledge so it is not sent again
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status": "duplicate"}`))
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleWebhookNoEventID(t *testing.T) {
+	secret := "test-secret"
+	h := NewWebhookHandler(secret)
+
+	var handled []string
+	handlers := WebhookHandlers{
+		TransactionSuccessHandler: func(event *WebhookEvent) {
+			handled = append(handled, event.TransactionID)
+		},
+	}
+
+	// Webhooks without an ID cannot be told apart, so none is a replay
+	for _, transactionID := range []string{"TX1", "TX2"} {
+		payload, _ := json.Marshal(WebhookEvent{
+			EventType:     WebhookEventTypeTransactionSuccess,
+			CreatedAt:     time.Now(),
+			TransactionID: transactionID,
+		})
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		h.HandleWebhook(w, req, handlers)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for %s, got %d", transactionID, w.Code)
+		}
+	}
+	if len(handled) != 2 {
+		t.Errorf("Expected both webhooks to be handled, got %v", handled)
+	}
+
+	// Webhooks with an ID are still checked for replays
+	payload, _ := json.Marshal(WebhookEvent{ID: "EVT1", EventType: WebhookEventTypeTransactionSuccess, CreatedAt: time.Now(), TransactionID: "TX3"})
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+		h.HandleWebhook(httptest.NewRecorder(), req, handlers)
+	}
+	if len(handled) != 3 {
+		t.Errorf("Expected a replayed webhook to be dropped, got %v", handled)
+	}
+}
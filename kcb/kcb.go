@@ -1,7 +1,14 @@
 package kcb
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/nutcas3/payment-rails/kcb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -24,63 +31,149 @@ func New(token string, sandbox bool) (*Client, error) {
 	}, nil
 }
 
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.service.SetHttpClient(httpClient)
+}
+
+func (c *Client) SetBaseURL(baseURL string) {
+	c.service.SetBaseURL(baseURL)
+}
+
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.service.SetRetryPolicy(policy)
+}
+
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.service.SetCircuitBreaker(settings)
+}
+
+func (c *Client) Health() breaker.Health {
+	return c.service.Health()
+}
+
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.service.Use(middleware...)
+}
 
 func (c *Client) GetAccountInfo() (*api.AccountInfoResponse, error) {
-	return c.service.GetAccountInfo()
+	return c.GetAccountInfoWithContext(context.Background())
+}
+
+func (c *Client) GetAccountInfoWithContext(ctx context.Context) (*api.AccountInfoResponse, error) {
+	return c.service.GetAccountInfoWithContext(ctx)
 }
 
 func (c *Client) GetAccountBalance(accountNumber string) (*api.AccountBalanceResponse, error) {
-	return c.service.GetAccountBalance(accountNumber)
+	return c.GetAccountBalanceWithContext(context.Background(), accountNumber)
+}
+
+func (c *Client) GetAccountBalanceWithContext(ctx context.Context, accountNumber string) (*api.AccountBalanceResponse, error) {
+	return c.service.GetAccountBalanceWithContext(ctx, accountNumber)
 }
 
 func (c *Client) GetAccountStatement(accountNumber, startDate, endDate string) (*api.StatementResponse, error) {
-	return c.service.GetAccountStatement(accountNumber, startDate, endDate)
+	return c.GetAccountStatementWithContext(context.Background(), accountNumber, startDate, endDate)
 }
 
-func (c *Client) TransferFunds(sourceAccount, destinationAccount string, amount float64, currency, reference, narration string) (*api.TransferResponse, error) {
-	return c.service.TransferFunds(sourceAccount, destinationAccount, amount, currency, reference, narration)
+func (c *Client) GetAccountStatementWithContext(ctx context.Context, accountNumber, startDate, endDate string) (*api.StatementResponse, error) {
+	return c.service.GetAccountStatementWithContext(ctx, accountNumber, startDate, endDate)
+}
+
+func (c *Client) TransferFunds(sourceAccount, destinationAccount string, amount money.Money, reference, narration string) (*api.TransferResponse, error) {
+	return c.TransferFundsWithContext(context.Background(), sourceAccount, destinationAccount, amount, reference, narration)
+}
+
+func (c *Client) TransferFundsWithContext(ctx context.Context, sourceAccount, destinationAccount string, amount money.Money, reference, narration string) (*api.TransferResponse, error) {
+	return c.service.TransferFundsWithContext(ctx, sourceAccount, destinationAccount, amount, reference, narration)
 }
 
 func (c *Client) GetForexRates(currency string) (*api.ForexRatesResponse, error) {
-	return c.service.GetForexRates(currency)
+	return c.GetForexRatesWithContext(context.Background(), currency)
+}
+
+func (c *Client) GetForexRatesWithContext(ctx context.Context, currency string) (*api.ForexRatesResponse, error) {
+	return c.service.GetForexRatesWithContext(ctx, currency)
+}
+
+func (c *Client) ExchangeCurrency(amount money.Money, to money.Currency) (*api.ForexExchangeResponse, error) {
+	return c.ExchangeCurrencyWithContext(context.Background(), amount, to)
 }
 
-func (c *Client) ExchangeCurrency(from, to string, amount float64) (*api.ForexExchangeResponse, error) {
-	return c.service.ExchangeCurrency(from, to, amount)
+func (c *Client) ExchangeCurrencyWithContext(ctx context.Context, amount money.Money, to money.Currency) (*api.ForexExchangeResponse, error) {
+	return c.service.ExchangeCurrencyWithContext(ctx, amount, to)
 }
 
-func (c *Client) VoomaPay(amount float64) (*api.VoomaPayResponse, error) {
-	return c.service.VoomaPay(amount)
+func (c *Client) VoomaPay(amount money.Money) (*api.VoomaPayResponse, error) {
+	return c.VoomaPayWithContext(context.Background(), amount)
+}
+
+func (c *Client) VoomaPayWithContext(ctx context.Context, amount money.Money) (*api.VoomaPayResponse, error) {
+	return c.service.VoomaPayWithContext(ctx, amount)
 }
 
 func (c *Client) CheckVoomaStatus(transactionID string) (*api.VoomaStatusResponse, error) {
-	return c.service.CheckVoomaStatus(transactionID)
+	return c.CheckVoomaStatusWithContext(context.Background(), transactionID)
 }
 
-func (c *Client) PesalinkTransfer(sourceAccount, destinationAccount, destinationBank string, amount float64, currency, reference, narration, phoneNumber string) (*api.PesalinkResponse, error) {
-	return c.service.PesalinkTransfer(sourceAccount, destinationAccount, destinationBank, amount, currency, reference, narration, phoneNumber)
+func (c *Client) CheckVoomaStatusWithContext(ctx context.Context, transactionID string) (*api.VoomaStatusResponse, error) {
+	return c.service.CheckVoomaStatusWithContext(ctx, transactionID)
+}
+
+// PesalinkTransfer sends amount to an account at another bank over PesaLink.
+// destinationBank is the bank's code, which banks.Find in rails/banks looks
+// up by name.
+func (c *Client) PesalinkTransfer(sourceAccount, destinationAccount, destinationBank string, amount money.Money, reference, narration, phoneNumber string) (*api.PesalinkResponse, error) {
+	return c.PesalinkTransferWithContext(context.Background(), sourceAccount, destinationAccount, destinationBank, amount, reference, narration, phoneNumber)
+}
+
+func (c *Client) PesalinkTransferWithContext(ctx context.Context, sourceAccount, destinationAccount, destinationBank string, amount money.Money, reference, narration, phoneNumber string) (*api.PesalinkResponse, error) {
+	return c.service.PesalinkTransferWithContext(ctx, sourceAccount, destinationAccount, destinationBank, amount, reference, narration, phoneNumber)
 }
 
 func (c *Client) CheckPesalinkStatus(transactionID string) (*api.PesalinkStatusResponse, error) {
-	return c.service.CheckPesalinkStatus(transactionID)
+	return c.CheckPesalinkStatusWithContext(context.Background(), transactionID)
+}
+
+func (c *Client) CheckPesalinkStatusWithContext(ctx context.Context, transactionID string) (*api.PesalinkStatusResponse, error) {
+	return c.service.CheckPesalinkStatusWithContext(ctx, transactionID)
 }
 
-func (c *Client) MobileMoneyTransfer(sourceAccount, phoneNumber string, amount float64, currency, reference, narration, provider string) (*api.MobileMoneyResponse, error) {
-	return c.service.MobileMoneyTransfer(sourceAccount, phoneNumber, amount, currency, reference, narration, provider)
+func (c *Client) MobileMoneyTransfer(sourceAccount, phoneNumber string, amount money.Money, reference, narration, provider string) (*api.MobileMoneyResponse, error) {
+	return c.MobileMoneyTransferWithContext(context.Background(), sourceAccount, phoneNumber, amount, reference, narration, provider)
+}
+
+func (c *Client) MobileMoneyTransferWithContext(ctx context.Context, sourceAccount, phoneNumber string, amount money.Money, reference, narration, provider string) (*api.MobileMoneyResponse, error) {
+	return c.service.MobileMoneyTransferWithContext(ctx, sourceAccount, phoneNumber, amount, reference, narration, provider)
 }
 
 func (c *Client) CheckMobileMoneyStatus(transactionID string) (*api.MobileMoneyStatusResponse, error) {
-	return c.service.CheckMobileMoneyStatus(transactionID)
+	return c.CheckMobileMoneyStatusWithContext(context.Background(), transactionID)
+}
+
+func (c *Client) CheckMobileMoneyStatusWithContext(ctx context.Context, transactionID string) (*api.MobileMoneyStatusResponse, error) {
+	return c.service.CheckMobileMoneyStatusWithContext(ctx, transactionID)
 }
 
 func (c *Client) GetUtilityProviders() (*api.UtilityProvidersResponse, error) {
-	return c.service.GetUtilityProviders()
+	return c.GetUtilityProvidersWithContext(context.Background())
+}
+
+func (c *Client) GetUtilityProvidersWithContext(ctx context.Context) (*api.UtilityProvidersResponse, error) {
+	return c.service.GetUtilityProvidersWithContext(ctx)
+}
+
+func (c *Client) PayUtility(sourceAccount, providerID, accountNumber string, amount money.Money, reference, phoneNumber string) (*api.UtilityPaymentResponse, error) {
+	return c.PayUtilityWithContext(context.Background(), sourceAccount, providerID, accountNumber, amount, reference, phoneNumber)
 }
 
-func (c *Client) PayUtility(sourceAccount, providerID, accountNumber string, amount float64, currency, reference, phoneNumber string) (*api.UtilityPaymentResponse, error) {
-	return c.service.PayUtility(sourceAccount, providerID, accountNumber, amount, currency, reference, phoneNumber)
+func (c *Client) PayUtilityWithContext(ctx context.Context, sourceAccount, providerID, accountNumber string, amount money.Money, reference, phoneNumber string) (*api.UtilityPaymentResponse, error) {
+	return c.service.PayUtilityWithContext(ctx, sourceAccount, providerID, accountNumber, amount, reference, phoneNumber)
 }
 
 func (c *Client) CheckUtilityPaymentStatus(transactionID string) (*api.UtilityStatusResponse, error) {
-	return c.service.CheckUtilityPaymentStatus(transactionID)
+	return c.CheckUtilityPaymentStatusWithContext(context.Background(), transactionID)
+}
+
+func (c *Client) CheckUtilityPaymentStatusWithContext(ctx context.Context, transactionID string) (*api.UtilityStatusResponse, error) {
+	return c.service.CheckUtilityPaymentStatusWithContext(ctx, transactionID)
 }
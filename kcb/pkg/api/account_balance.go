@@ -1,27 +1,34 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type AccountBalanceResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		AccountNumber string  `json:"accountNumber"`
-		AccountName   string  `json:"accountName"`
-		Balance       float64 `json:"balance"`
-		Currency      string  `json:"currency"`
-		AsOf          string  `json:"asOf"`
+		AccountNumber string         `json:"accountNumber"`
+		AccountName   string         `json:"accountName"`
+		Balance       money.Number   `json:"balance"`
+		Currency      money.Currency `json:"currency"`
+		AsOf          string         `json:"asOf"`
 	} `json:"data"`
 }
 
 func (s *Service) GetAccountBalance(accountNumber string) (*AccountBalanceResponse, error) {
+	return s.GetAccountBalanceWithContext(context.Background(), accountNumber)
+}
+
+func (s *Service) GetAccountBalanceWithContext(ctx context.Context, accountNumber string) (*AccountBalanceResponse, error) {
 	url := fmt.Sprintf("%s?accountNumber=%s", accountBalanceURL, accountNumber)
 	
-	respBody, err := s.makeRequest(http.MethodGet, url, nil)
+	respBody, err := s.makeRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account balance: %w", err)
 	}
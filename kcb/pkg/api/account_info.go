@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type AccountInfoResponse struct {
@@ -14,18 +17,22 @@ type AccountInfoResponse struct {
 		Code       string `json:"code"`
 	} `json:"status"`
 	Data struct {
-		AccountNumber string  `json:"account_number"`
-		AccountName   string  `json:"account_name"`
-		Balance       float64 `json:"balance"`
-		Currency      string  `json:"currency"`
-		AccountType   string  `json:"account_type"`
-		Branch        string  `json:"branch"`
-		Status        string  `json:"status"`
+		AccountNumber string         `json:"account_number"`
+		AccountName   string         `json:"account_name"`
+		Balance       money.Number   `json:"balance"`
+		Currency      money.Currency `json:"currency"`
+		AccountType   string         `json:"account_type"`
+		Branch        string         `json:"branch"`
+		Status        string         `json:"status"`
 	} `json:"data"`
 }
 
 func (s *Service) GetAccountInfo() (*AccountInfoResponse, error) {
-	respBody, err := s.makeRequest(http.MethodGet, accountInfoURL, nil)
+	return s.GetAccountInfoWithContext(context.Background())
+}
+
+func (s *Service) GetAccountInfoWithContext(ctx context.Context) (*AccountInfoResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodGet, accountInfoURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account information: %w", err)
 	}
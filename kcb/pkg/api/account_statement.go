@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type StatementRequest struct {
@@ -14,36 +18,40 @@ type StatementRequest struct {
 }
 
 type Transaction struct {
-	TransactionID   string    `json:"transactionId"`
-	TransactionDate time.Time `json:"transactionDate"`
-	Description     string    `json:"description"`
-	Amount          float64   `json:"amount"`
-	Type            string    `json:"type"` // "DEBIT" or "CREDIT"
-	Balance         float64   `json:"balance"`
-	Reference       string    `json:"reference"`
+	TransactionID   string       `json:"transactionId"`
+	TransactionDate time.Time    `json:"transactionDate"`
+	Description     string       `json:"description"`
+	Amount          money.Number `json:"amount"`
+	Type            string       `json:"type"` // "DEBIT" or "CREDIT"
+	Balance         money.Number `json:"balance"`
+	Reference       string       `json:"reference"`
 }
 
 type StatementResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		AccountNumber string        `json:"accountNumber"`
-		AccountName   string        `json:"accountName"`
-		StartDate     string        `json:"startDate"`
-		EndDate       string        `json:"endDate"`
-		Currency      string        `json:"currency"`
-		Transactions  []Transaction `json:"transactions"`
+		AccountNumber string         `json:"accountNumber"`
+		AccountName   string         `json:"accountName"`
+		StartDate     string         `json:"startDate"`
+		EndDate       string         `json:"endDate"`
+		Currency      money.Currency `json:"currency"`
+		Transactions  []Transaction  `json:"transactions"`
 	} `json:"data"`
 }
 
 func (s *Service) GetAccountStatement(accountNumber, startDate, endDate string) (*StatementResponse, error) {
+	return s.GetAccountStatementWithContext(context.Background(), accountNumber, startDate, endDate)
+}
+
+func (s *Service) GetAccountStatementWithContext(ctx context.Context, accountNumber, startDate, endDate string) (*StatementResponse, error) {
 	payload := StatementRequest{
 		AccountNumber: accountNumber,
 		StartDate:     startDate,
 		EndDate:       endDate,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, accountStatementURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, accountStatementURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account statement: %w", err)
 	}
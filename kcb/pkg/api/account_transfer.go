@@ -1,46 +1,53 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type TransferRequest struct {
-	SourceAccount      string  `json:"sourceAccount"`
-	DestinationAccount string  `json:"destinationAccount"`
-	Amount             float64 `json:"amount"`
-	Currency           string  `json:"currency"`
-	Reference          string  `json:"reference"`
-	Narration          string  `json:"narration"`
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	Narration          string         `json:"narration"`
 }
 
 type TransferResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		DestAccount     string  `json:"destinationAccount"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		DestAccount     string         `json:"destinationAccount"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
 	} `json:"data"`
 }
 
-func (s *Service) TransferFunds(sourceAccount, destinationAccount string, amount float64, currency, reference, narration string) (*TransferResponse, error) {
+func (s *Service) TransferFunds(sourceAccount, destinationAccount string, amount money.Money, reference, narration string) (*TransferResponse, error) {
+	return s.TransferFundsWithContext(context.Background(), sourceAccount, destinationAccount, amount, reference, narration)
+}
+
+func (s *Service) TransferFundsWithContext(ctx context.Context, sourceAccount, destinationAccount string, amount money.Money, reference, narration string) (*TransferResponse, error) {
 	payload := TransferRequest{
 		SourceAccount:      sourceAccount,
 		DestinationAccount: destinationAccount,
-		Amount:             amount,
-		Currency:           currency,
+		Amount:             amount.Number(),
+		Currency:           amount.Currency,
 		Reference:          reference,
 		Narration:          narration,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, accountTransferURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, accountTransferURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to transfer funds: %w", err)
 	}
@@ -2,12 +2,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -42,7 +45,8 @@ type Service struct {
 	environment  Environment
 	baseURL      string
 	httpClient   *http.Client
-	cache        *cache.Cache
+	retry        retry.Policy
+	breaker      *breaker.Breaker
 }
 
 func New(token string, environment Environment) (*Service, error) {
@@ -55,14 +59,13 @@ func New(token string, environment Environment) (*Service, error) {
 		baseURL = "https://buni.kcbgroup.com"
 	}
 
-	c := cache.New(1*time.Hour, 10*time.Minute)
-
 	return &Service{
 		token:       token,
 		environment: environment,
 		baseURL:     baseURL,
 		httpClient:  &http.Client{Timeout: 30 * time.Second},
-		cache:       c,
+		retry:       retry.DefaultPolicy(),
+		breaker:     breaker.New(provider, breaker.DefaultSettings()),
 	}, nil
 }
 
@@ -70,7 +73,43 @@ func (s *Service) SetHttpClient(httpClient *http.Client) {
 	s.httpClient = httpClient
 }
 
-func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte, error) {
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server.
+func (s *Service) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (s *Service) SetRetryPolicy(policy retry.Policy) {
+	s.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (s *Service) SetCircuitBreaker(settings breaker.Settings) {
+	s.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (s *Service) Health() breaker.Health {
+	return s.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (s *Service) roundTrip(req *http.Request) (*http.Response, error) {
+	return s.breaker.Do(req, s.httpClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (s *Service) Use(middleware ...transport.Middleware) {
+	s.httpClient = transport.Wrap(s.httpClient, provider, middleware...)
+}
+
+func (s *Service) makeRequest(ctx context.Context, method, url string, payload interface{}) ([]byte, error) {
 	var reqBody []byte
 	var err error
 
@@ -81,7 +120,7 @@ func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte,
 		}
 	}
 
-	req, err := http.NewRequest(method, s.baseURL+url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -89,7 +128,7 @@ func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte,
 	req.Header.Set("Authorization", "Bearer "+s.token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.retry.Do(req, s.roundTrip)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -101,11 +140,7 @@ func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte,
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		var errResp map[string]interface{}
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			return nil, fmt.Errorf("request failed with status: %s, error: %v", resp.Status, errResp)
-		}
-		return nil, fmt.Errorf("request failed with status: %s", resp.Status)
+		return nil, newProviderError(resp, respBody)
 	}
 
 	return respBody, nil
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "kcb"
+
+// ErrorResponse is the body KCB sends with a rejected request. Depending on
+// the endpoint, status is either an object with a code and message or a
+// string next to a top-level message.
+type ErrorResponse struct {
+	Status  json.RawMessage `json:"status"`
+	Message string          `json:"message"`
+	Code    string          `json:"code"`
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	json.Unmarshal(body, &errResp)
+
+	code, message := errResp.Code, errResp.Message
+	var status struct {
+		ResultCode string `json:"result_code"`
+		Message    string `json:"message"`
+		Code       string `json:"code"`
+	}
+	if json.Unmarshal(errResp.Status, &status) == nil {
+		if status.ResultCode != "" {
+			code = status.ResultCode
+		} else if status.Code != "" {
+			code = status.Code
+		}
+		if status.Message != "" {
+			message = status.Message
+		}
+	}
+
+	return railerrors.FromResponse(provider, resp, code, message)
+}
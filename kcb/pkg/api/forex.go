@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type ForexRatesResponse struct {
@@ -14,9 +17,9 @@ type ForexRatesResponse struct {
 		Code       string `json:"code"`
 	} `json:"status"`
 	Data struct {
-		BaseCurrency string `json:"base_currency"`
+		BaseCurrency money.Currency     `json:"base_currency"`
 		Rates        map[string]float64 `json:"rates"`
-		Timestamp    string `json:"timestamp"`
+		Timestamp    string             `json:"timestamp"`
 	} `json:"data"`
 }
 
@@ -28,24 +31,28 @@ type ForexExchangeResponse struct {
 		Code       string `json:"code"`
 	} `json:"status"`
 	Data struct {
-		FromCurrency string  `json:"from_currency"`
-		ToCurrency   string  `json:"to_currency"`
-		Amount       float64 `json:"amount"`
-		ConvertedAmount float64 `json:"converted_amount"`
-		ExchangeRate float64 `json:"exchange_rate"`
-		Timestamp    string  `json:"timestamp"`
+		FromCurrency    money.Currency `json:"from_currency"`
+		ToCurrency      money.Currency `json:"to_currency"`
+		Amount          money.Number   `json:"amount"`
+		ConvertedAmount money.Number   `json:"converted_amount"`
+		ExchangeRate    float64        `json:"exchange_rate"`
+		Timestamp       string         `json:"timestamp"`
 	} `json:"data"`
 }
 
 type ForexExchangeRequest struct {
-	FromCurrency string  `json:"from_currency"`
-	ToCurrency   string  `json:"to_currency"`
-	Amount       float64 `json:"amount"`
+	FromCurrency money.Currency `json:"from_currency"`
+	ToCurrency   money.Currency `json:"to_currency"`
+	Amount       money.Number   `json:"amount"`
 }
 
 func (s *Service) GetForexRates(currency string) (*ForexRatesResponse, error) {
+	return s.GetForexRatesWithContext(context.Background(), currency)
+}
+
+func (s *Service) GetForexRatesWithContext(ctx context.Context, currency string) (*ForexRatesResponse, error) {
 	url := fmt.Sprintf("%s/%s", forexRatesURL, currency)
-	respBody, err := s.makeRequest(http.MethodGet, url, nil)
+	respBody, err := s.makeRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get forex rates: %w", err)
 	}
@@ -58,14 +65,18 @@ func (s *Service) GetForexRates(currency string) (*ForexRatesResponse, error) {
 	return &response, nil
 }
 
-func (s *Service) ExchangeCurrency(from, to string, amount float64) (*ForexExchangeResponse, error) {
+func (s *Service) ExchangeCurrency(amount money.Money, to money.Currency) (*ForexExchangeResponse, error) {
+	return s.ExchangeCurrencyWithContext(context.Background(), amount, to)
+}
+
+func (s *Service) ExchangeCurrencyWithContext(ctx context.Context, amount money.Money, to money.Currency) (*ForexExchangeResponse, error) {
 	payload := ForexExchangeRequest{
-		FromCurrency: from,
+		FromCurrency: amount.Currency,
 		ToCurrency:   to,
-		Amount:       amount,
+		Amount:       amount.Number(),
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, forexExchangeURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, forexExchangeURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange currency: %w", err)
 	}
@@ -1,34 +1,38 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type MobileMoneyRequest struct {
-	SourceAccount string  `json:"sourceAccount"`
-	PhoneNumber   string  `json:"phoneNumber"`
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`
-	Reference     string  `json:"reference"`
-	Narration     string  `json:"narration"`
-	Provider      string  `json:"provider"` // e.g., "MPESA", "AIRTEL", etc.
+	SourceAccount string         `json:"sourceAccount"`
+	PhoneNumber   string         `json:"phoneNumber"`
+	Amount        money.Number   `json:"amount"`
+	Currency      money.Currency `json:"currency"`
+	Reference     string         `json:"reference"`
+	Narration     string         `json:"narration"`
+	Provider      string         `json:"provider"` // e.g., "MPESA", "AIRTEL", etc.
 }
 
 type MobileMoneyResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		PhoneNumber     string  `json:"phoneNumber"`
-		Provider        string  `json:"provider"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		PhoneNumber     string         `json:"phoneNumber"`
+		Provider        string         `json:"provider"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
 	} `json:"data"`
 }
 
@@ -40,31 +44,35 @@ type MobileMoneyStatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		PhoneNumber     string  `json:"phoneNumber"`
-		Provider        string  `json:"provider"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
-		StatusReason    string  `json:"statusReason,omitempty"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		PhoneNumber     string         `json:"phoneNumber"`
+		Provider        string         `json:"provider"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
+		StatusReason    string         `json:"statusReason,omitempty"`
 	} `json:"data"`
 }
 
-func (s *Service) MobileMoneyTransfer(sourceAccount, phoneNumber string, amount float64, currency, reference, narration, provider string) (*MobileMoneyResponse, error) {
+func (s *Service) MobileMoneyTransfer(sourceAccount, phoneNumber string, amount money.Money, reference, narration, provider string) (*MobileMoneyResponse, error) {
+	return s.MobileMoneyTransferWithContext(context.Background(), sourceAccount, phoneNumber, amount, reference, narration, provider)
+}
+
+func (s *Service) MobileMoneyTransferWithContext(ctx context.Context, sourceAccount, phoneNumber string, amount money.Money, reference, narration, provider string) (*MobileMoneyResponse, error) {
 	payload := MobileMoneyRequest{
 		SourceAccount: sourceAccount,
 		PhoneNumber:   phoneNumber,
-		Amount:        amount,
-		Currency:      currency,
+		Amount:        amount.Number(),
+		Currency:      amount.Currency,
 		Reference:     reference,
 		Narration:     narration,
 		Provider:      provider,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, mobileMoneyURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, mobileMoneyURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate mobile money transfer: %w", err)
 	}
@@ -78,11 +86,15 @@ func (s *Service) MobileMoneyTransfer(sourceAccount, phoneNumber string, amount
 }
 
 func (s *Service) CheckMobileMoneyStatus(transactionID string) (*MobileMoneyStatusResponse, error) {
+	return s.CheckMobileMoneyStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) CheckMobileMoneyStatusWithContext(ctx context.Context, transactionID string) (*MobileMoneyStatusResponse, error) {
 	payload := MobileMoneyStatusRequest{
 		TransactionID: transactionID,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, mobileMoneyStatusURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, mobileMoneyStatusURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check mobile money status: %w", err)
 	}
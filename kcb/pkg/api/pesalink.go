@@ -1,35 +1,39 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type PesalinkRequest struct {
-	SourceAccount      string  `json:"sourceAccount"`
-	DestinationAccount string  `json:"destinationAccount"`
-	DestinationBank    string  `json:"destinationBank"` // Bank code
-	Amount             float64 `json:"amount"`
-	Currency           string  `json:"currency"`
-	Reference          string  `json:"reference"`
-	Narration          string  `json:"narration"`
-	PhoneNumber        string  `json:"phoneNumber"` // Recipient's phone number
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	DestinationBank    string         `json:"destinationBank"` // Bank code
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	Narration          string         `json:"narration"`
+	PhoneNumber        string         `json:"phoneNumber"` // Recipient's phone number
 }
 
 type PesalinkResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		DestAccount     string  `json:"destinationAccount"`
-		DestBank        string  `json:"destinationBank"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		DestAccount     string         `json:"destinationAccount"`
+		DestBank        string         `json:"destinationBank"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
 	} `json:"data"`
 }
 
@@ -41,32 +45,36 @@ type PesalinkStatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		DestAccount     string  `json:"destinationAccount"`
-		DestBank        string  `json:"destinationBank"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
-		StatusReason    string  `json:"statusReason,omitempty"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		DestAccount     string         `json:"destinationAccount"`
+		DestBank        string         `json:"destinationBank"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
+		StatusReason    string         `json:"statusReason,omitempty"`
 	} `json:"data"`
 }
 
-func (s *Service) PesalinkTransfer(sourceAccount, destinationAccount, destinationBank string, amount float64, currency, reference, narration, phoneNumber string) (*PesalinkResponse, error) {
+func (s *Service) PesalinkTransfer(sourceAccount, destinationAccount, destinationBank string, amount money.Money, reference, narration, phoneNumber string) (*PesalinkResponse, error) {
+	return s.PesalinkTransferWithContext(context.Background(), sourceAccount, destinationAccount, destinationBank, amount, reference, narration, phoneNumber)
+}
+
+func (s *Service) PesalinkTransferWithContext(ctx context.Context, sourceAccount, destinationAccount, destinationBank string, amount money.Money, reference, narration, phoneNumber string) (*PesalinkResponse, error) {
 	payload := PesalinkRequest{
 		SourceAccount:      sourceAccount,
 		DestinationAccount: destinationAccount,
 		DestinationBank:    destinationBank,
-		Amount:             amount,
-		Currency:           currency,
+		Amount:             amount.Number(),
+		Currency:           amount.Currency,
 		Reference:          reference,
 		Narration:          narration,
 		PhoneNumber:        phoneNumber,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, pesalinkURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, pesalinkURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate PesaLink transfer: %w", err)
 	}
@@ -80,11 +88,15 @@ func (s *Service) PesalinkTransfer(sourceAccount, destinationAccount, destinatio
 }
 
 func (s *Service) CheckPesalinkStatus(transactionID string) (*PesalinkStatusResponse, error) {
+	return s.CheckPesalinkStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) CheckPesalinkStatusWithContext(ctx context.Context, transactionID string) (*PesalinkStatusResponse, error) {
 	payload := PesalinkStatusRequest{
 		TransactionID: transactionID,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, pesalinkStatusURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, pesalinkStatusURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check PesaLink status: %w", err)
 	}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type UtilityProvider struct {
@@ -22,30 +26,30 @@ type UtilityProvidersResponse struct {
 }
 
 type UtilityPaymentRequest struct {
-	SourceAccount string  `json:"sourceAccount"`
-	ProviderID    string  `json:"providerId"`
-	AccountNumber string  `json:"accountNumber"` // Customer account number with the utility provider
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`
-	Reference     string  `json:"reference"`
-	PhoneNumber   string  `json:"phoneNumber,omitempty"` // For notifications
+	SourceAccount string         `json:"sourceAccount"`
+	ProviderID    string         `json:"providerId"`
+	AccountNumber string         `json:"accountNumber"` // Customer account number with the utility provider
+	Amount        money.Number   `json:"amount"`
+	Currency      money.Currency `json:"currency"`
+	Reference     string         `json:"reference"`
+	PhoneNumber   string         `json:"phoneNumber,omitempty"` // For notifications
 }
 
 type UtilityPaymentResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		ProviderID      string  `json:"providerId"`
-		ProviderName    string  `json:"providerName"`
-		AccountNumber   string  `json:"accountNumber"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
-		ReceiptNumber   string  `json:"receiptNumber,omitempty"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		ProviderID      string         `json:"providerId"`
+		ProviderName    string         `json:"providerName"`
+		AccountNumber   string         `json:"accountNumber"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
+		ReceiptNumber   string         `json:"receiptNumber,omitempty"`
 	} `json:"data"`
 }
 
@@ -57,23 +61,27 @@ type UtilityStatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		SourceAccount   string  `json:"sourceAccount"`
-		ProviderID      string  `json:"providerId"`
-		ProviderName    string  `json:"providerName"`
-		AccountNumber   string  `json:"accountNumber"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
-		StatusReason    string  `json:"statusReason,omitempty"`
-		ReceiptNumber   string  `json:"receiptNumber,omitempty"`
+		TransactionID   string         `json:"transactionId"`
+		SourceAccount   string         `json:"sourceAccount"`
+		ProviderID      string         `json:"providerId"`
+		ProviderName    string         `json:"providerName"`
+		AccountNumber   string         `json:"accountNumber"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
+		StatusReason    string         `json:"statusReason,omitempty"`
+		ReceiptNumber   string         `json:"receiptNumber,omitempty"`
 	} `json:"data"`
 }
 
 func (s *Service) GetUtilityProviders() (*UtilityProvidersResponse, error) {
-	respBody, err := s.makeRequest(http.MethodGet, utilityProvidersURL, nil)
+	return s.GetUtilityProvidersWithContext(context.Background())
+}
+
+func (s *Service) GetUtilityProvidersWithContext(ctx context.Context) (*UtilityProvidersResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodGet, utilityProvidersURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get utility providers: %w", err)
 	}
@@ -86,18 +94,22 @@ func (s *Service) GetUtilityProviders() (*UtilityProvidersResponse, error) {
 	return &response, nil
 }
 
-func (s *Service) PayUtility(sourceAccount, providerID, accountNumber string, amount float64, currency, reference, phoneNumber string) (*UtilityPaymentResponse, error) {
+func (s *Service) PayUtility(sourceAccount, providerID, accountNumber string, amount money.Money, reference, phoneNumber string) (*UtilityPaymentResponse, error) {
+	return s.PayUtilityWithContext(context.Background(), sourceAccount, providerID, accountNumber, amount, reference, phoneNumber)
+}
+
+func (s *Service) PayUtilityWithContext(ctx context.Context, sourceAccount, providerID, accountNumber string, amount money.Money, reference, phoneNumber string) (*UtilityPaymentResponse, error) {
 	payload := UtilityPaymentRequest{
 		SourceAccount: sourceAccount,
 		ProviderID:    providerID,
 		AccountNumber: accountNumber,
-		Amount:        amount,
-		Currency:      currency,
+		Amount:        amount.Number(),
+		Currency:      amount.Currency,
 		Reference:     reference,
 		PhoneNumber:   phoneNumber,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, utilityPaymentURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, utilityPaymentURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make utility payment: %w", err)
 	}
@@ -111,11 +123,15 @@ func (s *Service) PayUtility(sourceAccount, providerID, accountNumber string, am
 }
 
 func (s *Service) CheckUtilityPaymentStatus(transactionID string) (*UtilityStatusResponse, error) {
+	return s.CheckUtilityPaymentStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) CheckUtilityPaymentStatusWithContext(ctx context.Context, transactionID string) (*UtilityStatusResponse, error) {
 	payload := UtilityStatusRequest{
 		TransactionID: transactionID,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, utilityStatusURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, utilityStatusURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check utility payment status: %w", err)
 	}
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type VoomaPayRequest struct {
-	Amount float64 `json:"amount"`
+	Amount money.Number `json:"amount"`
 }
 
 type VoomaPayResponse struct {
@@ -18,21 +21,25 @@ type VoomaPayResponse struct {
 		Code       string `json:"code"`
 	} `json:"status"`
 	Data struct {
-		TransactionID   string  `json:"transaction_id"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		Status          string  `json:"status"`
-		TransactionDate string  `json:"transaction_date"`
-		Reference       string  `json:"reference"`
+		TransactionID   string         `json:"transaction_id"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		Status          string         `json:"status"`
+		TransactionDate string         `json:"transaction_date"`
+		Reference       string         `json:"reference"`
 	} `json:"data"`
 }
 
-func (s *Service) VoomaPay(amount float64) (*VoomaPayResponse, error) {
+func (s *Service) VoomaPay(amount money.Money) (*VoomaPayResponse, error) {
+	return s.VoomaPayWithContext(context.Background(), amount)
+}
+
+func (s *Service) VoomaPayWithContext(ctx context.Context, amount money.Money) (*VoomaPayResponse, error) {
 	payload := VoomaPayRequest{
-		Amount: amount,
+		Amount: amount.Number(),
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, voomaPayURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, voomaPayURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate Vooma payment: %w", err)
 	}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type VoomaStatusRequest struct {
@@ -14,22 +18,26 @@ type VoomaStatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		TransactionID   string  `json:"transactionId"`
-		Amount          float64 `json:"amount"`
-		Currency        string  `json:"currency"`
-		TransactionDate string  `json:"transactionDate"`
-		Reference       string  `json:"reference"`
-		Status          string  `json:"status"`
-		StatusReason    string  `json:"statusReason,omitempty"`
+		TransactionID   string         `json:"transactionId"`
+		Amount          money.Number   `json:"amount"`
+		Currency        money.Currency `json:"currency"`
+		TransactionDate string         `json:"transactionDate"`
+		Reference       string         `json:"reference"`
+		Status          string         `json:"status"`
+		StatusReason    string         `json:"statusReason,omitempty"`
 	} `json:"data"`
 }
 
 func (s *Service) CheckVoomaStatus(transactionID string) (*VoomaStatusResponse, error) {
+	return s.CheckVoomaStatusWithContext(context.Background(), transactionID)
+}
+
+func (s *Service) CheckVoomaStatusWithContext(ctx context.Context, transactionID string) (*VoomaStatusResponse, error) {
 	payload := VoomaStatusRequest{
 		TransactionID: transactionID,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, voomaStatusURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, voomaStatusURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check Vooma payment status: %w", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nutcas3/payment-rails/momo/common/types"
+	"github.com/nutcas3/payment-rails/rails/auth"
 )
 
 const (
@@ -24,11 +25,11 @@ func (c Collection) getAccessToken(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":" + c.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":" + c.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 	}
 
 	var resp types.AccessTokenResp
@@ -46,7 +47,7 @@ func (c Collection) getAccessToken(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	c.cache.Set(authTokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	c.cache.Set(authTokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
@@ -57,11 +58,11 @@ func (c Collection) getOauth2Token(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":" + c.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(c.apiKey + ":" + c.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 		envHeader:     []string{c.environment},
 	}
 
@@ -80,7 +81,7 @@ func (c Collection) getOauth2Token(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	c.cache.Set(oauth2TokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	c.cache.Set(oauth2TokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
@@ -5,12 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -38,10 +42,29 @@ type BackendConfig struct {
 	// Environment is the API environment being used i.e. sandbox or production.
 	Environment string
 
+	// BaseURL, if set, is used instead of the environment's base URL, e.g.
+	// to send requests through a proxy or to a mock server.
+	BaseURL string
+
 	// HTTPClient is an HTTP client instance to use when making API requests.
 	//
 	// If left unset, it'll be set to a default HTTP client for the package.
 	HTTPClient *http.Client
+
+	// RetryPolicy controls how requests that fail with a gateway error or a
+	// transient network error are retried.
+	//
+	// If left unset, retry.DefaultPolicy() is used.
+	RetryPolicy *retry.Policy
+
+	// Breaker controls when requests to a failing endpoint are stopped.
+	//
+	// If left unset, breaker.DefaultSettings() is used.
+	Breaker *breaker.Settings
+
+	// Middleware runs every request, including token fetches and retries,
+	// through transport middleware such as transport.Log.
+	Middleware []transport.Middleware
 }
 
 // Params represents path and query paramters
@@ -54,6 +77,8 @@ type Params struct {
 type BackendImpl struct {
 	url        string
 	HTTPClient *http.Client
+	retry      retry.Policy
+	breaker    *breaker.Breaker
 }
 
 // Call is the method for invoking API calls.
@@ -81,15 +106,16 @@ func (b *BackendImpl) Call(
 		return err
 	}
 
-	resp, err := b.HTTPClient.Do(req)
+	resp, err := b.retry.Do(req, b.roundTrip)
 	if err != nil {
-		return fmt.Errorf("momosdk: request failed with error: %s", err)
+		return fmt.Errorf("momosdk: request failed with error: %w", err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("momosdk: request failed with status: %s", resp.Status)
+		body, _ := io.ReadAll(resp.Body)
+		return NewProviderError(resp, body)
 	}
 
 	// If the endpoint returns empty body skip decoding
@@ -100,6 +126,16 @@ func (b *BackendImpl) Call(
 	return json.NewDecoder(resp.Body).Decode(result)
 }
 
+// Health reports the state of the circuit breaker.
+func (b *BackendImpl) Health() breaker.Health {
+	return b.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (b *BackendImpl) roundTrip(req *http.Request) (*http.Response, error) {
+	return b.breaker.Do(req, b.HTTPClient.Do)
+}
+
 // NewRequest is used by Call to build a HTTP request. It handles encoding parameters and
 // attaching headers.
 func (b *BackendImpl) NewRequest(
@@ -168,9 +204,24 @@ func NewBackend(config *BackendConfig) (Backend, error) {
 	} else {
 		baseURL = sandboxURL
 	}
+	if cfg.BaseURL != "" {
+		baseURL = cfg.BaseURL
+	}
+
+	policy := retry.DefaultPolicy()
+	if cfg.RetryPolicy != nil {
+		policy = *cfg.RetryPolicy
+	}
+
+	settings := breaker.DefaultSettings()
+	if cfg.Breaker != nil {
+		settings = *cfg.Breaker
+	}
 
 	return &BackendImpl{
 		url:        baseURL,
-		HTTPClient: cfg.HTTPClient,
+		HTTPClient: transport.Wrap(cfg.HTTPClient, provider, cfg.Middleware...),
+		retry:      policy,
+		breaker:    breaker.New(provider, settings),
 	}, nil
 }
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "momo"
+
+// errorCodeCategories maps MoMo error reason codes to a category.
+var errorCodeCategories = map[string]railerrors.Category{
+	"PAYEE_NOT_FOUND":                railerrors.CategoryNotFound,
+	"PAYER_NOT_FOUND":                railerrors.CategoryNotFound,
+	"RESOURCE_NOT_FOUND":             railerrors.CategoryNotFound,
+	"NOT_ENOUGH_FUNDS":               railerrors.CategoryInsufficientFunds,
+	"PAYER_LIMIT_REACHED":            railerrors.CategoryValidation,
+	"PAYEE_NOT_ALLOWED_TO_RECEIVE":   railerrors.CategoryValidation,
+	"INVALID_CURRENCY":               railerrors.CategoryValidation,
+	"INVALID_CALLBACK_URL_HOST":      railerrors.CategoryValidation,
+	"NOT_ALLOWED":                    railerrors.CategoryAuth,
+	"NOT_ALLOWED_TARGET_ENVIRONMENT": railerrors.CategoryAuth,
+	"RESOURCE_ALREADY_EXIST":         railerrors.CategoryDuplicate,
+	"SERVICE_UNAVAILABLE":            railerrors.CategoryProviderDown,
+	"INTERNAL_PROCESSING_ERROR":      railerrors.CategoryProviderDown,
+}
+
+// NewProviderError builds the error for a request MoMo rejected from its
+// response and body.
+func NewProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var reason struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &reason)
+
+	perr := railerrors.FromResponse(provider, resp, reason.Code, reason.Message)
+	if category, ok := errorCodeCategories[reason.Code]; ok {
+		perr.SetCategory(category)
+	}
+	return perr
+}
@@ -2,194 +2,195 @@ package types
 
 import (
 	"errors"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 var ErrRefIDRequired = errors.New("refID is required")
 
-// Currency represents ISO4217 currency codes
-//
-// https://en.wikipedia.org/wiki/ISO_4217
-type Currency string
+// Currency represents ISO4217 currency codes. It is money.Currency, so
+// amounts in MoMo currencies can be used with the other providers.
+type Currency = money.Currency
 
 const (
-	AED Currency = "AED"
-	AFN Currency = "AFN"
-	ALL Currency = "ALL"
-	AMD Currency = "AMD"
-	AOA Currency = "AOA"
-	ARS Currency = "ARS"
-	AUD Currency = "AUD"
-	AWG Currency = "AWG"
-	AZN Currency = "AZN"
-	BAM Currency = "BAM"
-	BBD Currency = "BBD"
-	BDT Currency = "BDT"
-	BGN Currency = "BGN"
-	BHD Currency = "BHD"
-	BIF Currency = "BIF"
-	BMD Currency = "BMD"
-	BND Currency = "BND"
-	BOB Currency = "BOB"
-	BOV Currency = "BOV"
-	BRL Currency = "BRL"
-	BSD Currency = "BSD"
-	BTN Currency = "BTN"
-	BWP Currency = "BWP"
-	BYN Currency = "BYN"
-	BZD Currency = "BZD"
-	CAD Currency = "CAD"
-	CDF Currency = "CDF"
-	CHE Currency = "CHE"
-	CHF Currency = "CHF"
-	CHW Currency = "CHW"
-	CLF Currency = "CLF"
-	CLP Currency = "CLP"
-	CNY Currency = "CNY"
-	COP Currency = "COP"
-	COU Currency = "COU"
-	CRC Currency = "CRC"
-	CUP Currency = "CUP"
-	CVE Currency = "CVE"
-	CZK Currency = "CZK"
-	DJF Currency = "DJF"
-	DKK Currency = "DKK"
-	DOP Currency = "DOP"
-	DZD Currency = "DZD"
-	EGP Currency = "EGP"
-	ERN Currency = "ERN"
-	ETB Currency = "ETB"
-	EUR Currency = "EUR"
-	FJD Currency = "FJD"
-	FKP Currency = "FKP"
-	GBP Currency = "GBP"
-	GEL Currency = "GEL"
-	GHS Currency = "GHS"
-	GIP Currency = "GIP"
-	GMD Currency = "GMD"
-	GNF Currency = "GNF"
-	GTQ Currency = "GTQ"
-	GYD Currency = "GYD"
-	HKD Currency = "HKD"
-	HNL Currency = "HNL"
-	HTG Currency = "HTG"
-	HUF Currency = "HUF"
-	IDR Currency = "IDR"
-	ILS Currency = "ILS"
-	INR Currency = "INR"
-	IQD Currency = "IQD"
-	IRR Currency = "IRR"
-	ISK Currency = "ISK"
-	JMD Currency = "JMD"
-	JOD Currency = "JOD"
-	JPY Currency = "JPY"
-	KES Currency = "KES"
-	KGS Currency = "KGS"
-	KHR Currency = "KHR"
-	KMF Currency = "KMF"
-	KPW Currency = "KPW"
-	KRW Currency = "KRW"
-	KWD Currency = "KWD"
-	KYD Currency = "KYD"
-	KZT Currency = "KZT"
-	LAK Currency = "LAK"
-	LBP Currency = "LBP"
-	LKR Currency = "LKR"
-	LRD Currency = "LRD"
-	LSL Currency = "LSL"
-	LYD Currency = "LYD"
-	MAD Currency = "MAD"
-	MDL Currency = "MDL"
-	MGA Currency = "MGA"
-	MKD Currency = "MKD"
-	MMK Currency = "MMK"
-	MNT Currency = "MNT"
-	MOP Currency = "MOP"
-	MRU Currency = "MRU"
-	MUR Currency = "MUR"
-	MVR Currency = "MVR"
-	MWK Currency = "MWK"
-	MXN Currency = "MXN"
-	MXV Currency = "MXV"
-	MYR Currency = "MYR"
-	MZN Currency = "MZN"
-	NAD Currency = "NAD"
-	NGN Currency = "NGN"
-	NIO Currency = "NIO"
-	NOK Currency = "NOK"
-	NPR Currency = "NPR"
-	NZD Currency = "NZD"
-	OMR Currency = "OMR"
-	PAB Currency = "PAB"
-	PEN Currency = "PEN"
-	PGK Currency = "PGK"
-	PHP Currency = "PHP"
-	PKR Currency = "PKR"
-	PLN Currency = "PLN"
-	PYG Currency = "PYG"
-	QAR Currency = "QAR"
-	RON Currency = "RON"
-	RSD Currency = "RSD"
-	RUB Currency = "RUB"
-	RWF Currency = "RWF"
-	SAR Currency = "SAR"
-	SBD Currency = "SBD"
-	SCR Currency = "SCR"
-	SDG Currency = "SDG"
-	SEK Currency = "SEK"
-	SGD Currency = "SGD"
-	SHP Currency = "SHP"
-	SLE Currency = "SLE"
-	SOS Currency = "SOS"
-	SRD Currency = "SRD"
-	SSP Currency = "SSP"
-	STN Currency = "STN"
-	SVC Currency = "SVC"
-	SYP Currency = "SYP"
-	SZL Currency = "SZL"
-	THB Currency = "THB"
-	TJS Currency = "TJS"
-	TMT Currency = "TMT"
-	TND Currency = "TND"
-	TOP Currency = "TOP"
-	TRY Currency = "TRY"
-	TTD Currency = "TTD"
-	TWD Currency = "TWD"
-	TZS Currency = "TZS"
-	UAH Currency = "UAH"
-	UGX Currency = "UGX"
-	USD Currency = "USD"
-	USN Currency = "USN"
-	UYI Currency = "UYI"
-	UYU Currency = "UYU"
-	UYW Currency = "UYW"
-	UZS Currency = "UZS"
-	VED Currency = "VED"
-	VES Currency = "VES"
-	VND Currency = "VND"
-	VUV Currency = "VUV"
-	WST Currency = "WST"
-	XAD Currency = "XAD"
-	XAF Currency = "XAF"
-	XAG Currency = "XAG"
-	XAU Currency = "XAU"
-	XBA Currency = "XBA"
-	XBB Currency = "XBB"
-	XBC Currency = "XBC"
-	XBD Currency = "XBD"
-	XCD Currency = "XCD"
-	XCG Currency = "XCG"
-	XDR Currency = "XDR"
-	XOF Currency = "XOF"
-	XPD Currency = "XPD"
-	XPF Currency = "XPF"
-	XPT Currency = "XPT"
-	XSU Currency = "XSU"
-	XTS Currency = "XTS"
-	XUA Currency = "XUA"
-	YER Currency = "YER"
-	ZAR Currency = "ZAR"
-	ZMW Currency = "ZMW"
-	ZWG Currency = "ZWG"
+	AED = money.AED
+	AFN = money.AFN
+	ALL = money.ALL
+	AMD = money.AMD
+	AOA = money.AOA
+	ARS = money.ARS
+	AUD = money.AUD
+	AWG = money.AWG
+	AZN = money.AZN
+	BAM = money.BAM
+	BBD = money.BBD
+	BDT = money.BDT
+	BGN = money.BGN
+	BHD = money.BHD
+	BIF = money.BIF
+	BMD = money.BMD
+	BND = money.BND
+	BOB = money.BOB
+	BOV = money.BOV
+	BRL = money.BRL
+	BSD = money.BSD
+	BTN = money.BTN
+	BWP = money.BWP
+	BYN = money.BYN
+	BZD = money.BZD
+	CAD = money.CAD
+	CDF = money.CDF
+	CHE = money.CHE
+	CHF = money.CHF
+	CHW = money.CHW
+	CLF = money.CLF
+	CLP = money.CLP
+	CNY = money.CNY
+	COP = money.COP
+	COU = money.COU
+	CRC = money.CRC
+	CUP = money.CUP
+	CVE = money.CVE
+	CZK = money.CZK
+	DJF = money.DJF
+	DKK = money.DKK
+	DOP = money.DOP
+	DZD = money.DZD
+	EGP = money.EGP
+	ERN = money.ERN
+	ETB = money.ETB
+	EUR = money.EUR
+	FJD = money.FJD
+	FKP = money.FKP
+	GBP = money.GBP
+	GEL = money.GEL
+	GHS = money.GHS
+	GIP = money.GIP
+	GMD = money.GMD
+	GNF = money.GNF
+	GTQ = money.GTQ
+	GYD = money.GYD
+	HKD = money.HKD
+	HNL = money.HNL
+	HTG = money.HTG
+	HUF = money.HUF
+	IDR = money.IDR
+	ILS = money.ILS
+	INR = money.INR
+	IQD = money.IQD
+	IRR = money.IRR
+	ISK = money.ISK
+	JMD = money.JMD
+	JOD = money.JOD
+	JPY = money.JPY
+	KES = money.KES
+	KGS = money.KGS
+	KHR = money.KHR
+	KMF = money.KMF
+	KPW = money.KPW
+	KRW = money.KRW
+	KWD = money.KWD
+	KYD = money.KYD
+	KZT = money.KZT
+	LAK = money.LAK
+	LBP = money.LBP
+	LKR = money.LKR
+	LRD = money.LRD
+	LSL = money.LSL
+	LYD = money.LYD
+	MAD = money.MAD
+	MDL = money.MDL
+	MGA = money.MGA
+	MKD = money.MKD
+	MMK = money.MMK
+	MNT = money.MNT
+	MOP = money.MOP
+	MRU = money.MRU
+	MUR = money.MUR
+	MVR = money.MVR
+	MWK = money.MWK
+	MXN = money.MXN
+	MXV = money.MXV
+	MYR = money.MYR
+	MZN = money.MZN
+	NAD = money.NAD
+	NGN = money.NGN
+	NIO = money.NIO
+	NOK = money.NOK
+	NPR = money.NPR
+	NZD = money.NZD
+	OMR = money.OMR
+	PAB = money.PAB
+	PEN = money.PEN
+	PGK = money.PGK
+	PHP = money.PHP
+	PKR = money.PKR
+	PLN = money.PLN
+	PYG = money.PYG
+	QAR = money.QAR
+	RON = money.RON
+	RSD = money.RSD
+	RUB = money.RUB
+	RWF = money.RWF
+	SAR = money.SAR
+	SBD = money.SBD
+	SCR = money.SCR
+	SDG = money.SDG
+	SEK = money.SEK
+	SGD = money.SGD
+	SHP = money.SHP
+	SLE = money.SLE
+	SOS = money.SOS
+	SRD = money.SRD
+	SSP = money.SSP
+	STN = money.STN
+	SVC = money.SVC
+	SYP = money.SYP
+	SZL = money.SZL
+	THB = money.THB
+	TJS = money.TJS
+	TMT = money.TMT
+	TND = money.TND
+	TOP = money.TOP
+	TRY = money.TRY
+	TTD = money.TTD
+	TWD = money.TWD
+	TZS = money.TZS
+	UAH = money.UAH
+	UGX = money.UGX
+	USD = money.USD
+	USN = money.USN
+	UYI = money.UYI
+	UYU = money.UYU
+	UYW = money.UYW
+	UZS = money.UZS
+	VED = money.VED
+	VES = money.VES
+	VND = money.VND
+	VUV = money.VUV
+	WST = money.WST
+	XAD = money.XAD
+	XAF = money.XAF
+	XAG = money.XAG
+	XAU = money.XAU
+	XBA = money.XBA
+	XBB = money.XBB
+	XBC = money.XBC
+	XBD = money.XBD
+	XCD = money.XCD
+	XCG = money.XCG
+	XDR = money.XDR
+	XOF = money.XOF
+	XPD = money.XPD
+	XPF = money.XPF
+	XPT = money.XPT
+	XSU = money.XSU
+	XTS = money.XTS
+	XUA = money.XUA
+	YER = money.YER
+	ZAR = money.ZAR
+	ZMW = money.ZMW
+	ZWG = money.ZWG
 )
 
 type Balance struct {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nutcas3/payment-rails/momo/common/types"
+	"github.com/nutcas3/payment-rails/rails/auth"
 )
 
 const (
@@ -24,11 +25,11 @@ func (d Disbursement) getAccessToken(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(d.apiKey + ":" + d.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(d.apiKey + ":" + d.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 	}
 
 	var resp types.AccessTokenResp
@@ -46,7 +47,7 @@ func (d Disbursement) getAccessToken(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	d.cache.Set(authTokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	d.cache.Set(authTokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
@@ -57,11 +58,11 @@ func (d Disbursement) getOauth2Token(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(d.apiKey + ":" + d.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(d.apiKey + ":" + d.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 		envHeader:     []string{d.environment},
 	}
 
@@ -80,7 +81,7 @@ func (d Disbursement) getOauth2Token(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	d.cache.Set(oauth2TokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	d.cache.Set(oauth2TokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
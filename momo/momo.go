@@ -7,6 +7,9 @@ import (
 	"github.com/nutcas3/payment-rails/momo/common"
 	"github.com/nutcas3/payment-rails/momo/disbursement"
 	"github.com/nutcas3/payment-rails/momo/remittance"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -19,12 +22,16 @@ const (
 // ClientConfig holds the configuration for creating a new Momo client
 type ClientConfig struct {
 	Environment                 string
+	BaseURL                     string // Overrides the environment's base URL, see common.BackendConfig
 	APIKey                      string
 	APISecret                   string
 	CollectionSubscriptionKey   string
 	DisbursementSubscriptionKey string
 	RemittanceSubscriptionKey   string
 	HTTPClient                  *http.Client
+	RetryPolicy                 *retry.Policy
+	Breaker                     *breaker.Settings
+	Middleware                  []transport.Middleware // See common.BackendConfig
 }
 
 type Client struct {
@@ -39,7 +46,11 @@ type Client struct {
 func New(cfg ClientConfig) (*Client, error) {
 	backendCfg := &common.BackendConfig{
 		Environment: cfg.Environment,
+		BaseURL:     cfg.BaseURL,
 		HTTPClient:  cfg.HTTPClient,
+		RetryPolicy: cfg.RetryPolicy,
+		Breaker:     cfg.Breaker,
+		Middleware:  cfg.Middleware,
 	}
 
 	backend, err := common.NewBackend(backendCfg)
@@ -92,3 +103,11 @@ func New(cfg ClientConfig) (*Client, error) {
 
 	return client, nil
 }
+
+// Health reports the state of the circuit breaker shared by the services.
+func (c *Client) Health() breaker.Health {
+	if b, ok := c.backend.(interface{ Health() breaker.Health }); ok {
+		return b.Health()
+	}
+	return breaker.Health{Provider: "momo"}
+}
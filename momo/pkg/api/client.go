@@ -5,9 +5,15 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/nutcas3/payment-rails/momo/common"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 	"github.com/patrickmn/go-cache"
 )
 
@@ -34,12 +40,14 @@ type Client struct {
 	baseURL         string
 	httpClient      *http.Client
 	cache           *cache.Cache
+	retry           retry.Policy
+	breaker         *breaker.Breaker
 }
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken string         `json:"access_token"`
+	TokenType   string         `json:"token_type"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
 }
 
 type APIUserRequest struct {
@@ -70,6 +78,8 @@ func New(apiUser, apiKey, subscriptionKey string, environment Environment) (*Cli
 		baseURL:         baseURL,
 		httpClient:      &http.Client{Timeout: 30 * time.Second},
 		cache:           c,
+		retry:           retry.DefaultPolicy(),
+		breaker:         breaker.New("momo", breaker.DefaultSettings()),
 	}, nil
 }
 
@@ -77,6 +87,36 @@ func (c *Client) SetHttpClient(httpClient *http.Client) {
 	c.httpClient = httpClient
 }
 
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.breaker = breaker.New("momo", settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.httpClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.httpClient = transport.Wrap(c.httpClient, "momo", middleware...)
+}
+
 func (c *Client) GetCollectionToken() (string, error) {
 	return c.getToken(tokenURL, collectionTokenKey)
 }
@@ -103,14 +143,15 @@ func (c *Client) getToken(endpoint, cacheKey string) (string, error) {
 	req.Header.Set("Authorization", "Basic "+c.getBasicAuth())
 	req.Header.Set("Ocp-Apim-Subscription-Key", c.subscriptionKey)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to execute token request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("token request failed with status: %s", resp.Status)
+		body, _ := io.ReadAll(resp.Body)
+		return "", common.NewProviderError(resp, body).AuthFailure()
 	}
 
 	var tokenResp TokenResponse
@@ -118,11 +159,7 @@ func (c *Client) getToken(endpoint, cacheKey string) (string, error) {
 		return "", fmt.Errorf("failed to decode token response: %w", err)
 	}
 
-	expiresIn := tokenResp.ExpiresIn
-	if expiresIn == 0 {
-		expiresIn = 3600 
-	}
-	c.cache.Set(cacheKey, tokenResp.AccessToken, time.Duration(expiresIn)*time.Second)
+	c.cache.Set(cacheKey, tokenResp.AccessToken, auth.TTL(tokenResp.ExpiresIn.Duration()))
 
 	return tokenResp.AccessToken, nil
 }
@@ -173,7 +210,7 @@ func (c *Client) makeRequest(method, url, product string, payload interface{}, h
 		req.Header.Set(key, value)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.retry.Do(req, c.roundTrip)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
@@ -186,7 +223,7 @@ func (c *Client) makeRequest(method, url, product string, payload interface{}, h
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, respBody.String())
+		return nil, common.NewProviderError(resp, respBody.Bytes())
 	}
 
 	return respBody.Bytes(), nil
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/nutcas3/payment-rails/momo/common/types"
+	"github.com/nutcas3/payment-rails/rails/auth"
 )
 
 const (
@@ -24,11 +25,11 @@ func (r Remittance) getAccessToken(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(r.apiKey + ":" + r.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(r.apiKey + ":" + r.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 	}
 
 	var resp types.AccessTokenResp
@@ -46,7 +47,7 @@ func (r Remittance) getAccessToken(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	r.cache.Set(authTokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	r.cache.Set(authTokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
@@ -57,11 +58,11 @@ func (r Remittance) getOauth2Token(ctx context.Context) (string, error) {
 		return token.(string), nil
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(r.apiKey + ":" + r.apiSecret))
+	basic := base64.StdEncoding.EncodeToString([]byte(r.apiKey + ":" + r.apiSecret))
 
 	headers := http.Header{
 		contentHeader: []string{"application/json"},
-		authHeader:    []string{"Basic " + auth},
+		authHeader:    []string{"Basic " + basic},
 		envHeader:     []string{r.environment},
 	}
 
@@ -80,7 +81,7 @@ func (r Remittance) getOauth2Token(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	r.cache.Set(oauth2TokenKey, resp.AccessToken, time.Duration(resp.ExpiresIn)*time.Second)
+	r.cache.Set(oauth2TokenKey, resp.AccessToken, auth.TTL(time.Duration(resp.ExpiresIn)*time.Second))
 
 	return resp.AccessToken, nil
 }
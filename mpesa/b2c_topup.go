@@ -1,12 +1,15 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
 type B2CTopUpRequest struct {
 	Initiator          string // The M-Pesa API operator username
 	SecurityCredential string // The encrypted password of the M-Pesa API operator
+	InitiatorPassword  string // Optional: plain password, encrypted when SecurityCredential is empty
 	Amount             string // The transaction amount
 	PartyA             string // Your shortcode (from which money will be deducted)
 	PartyB             string // The B2C shortcode to which money will be loaded
@@ -25,9 +28,14 @@ type B2CTopUpResponse struct {
 }
 
 func (c *Client) B2CAccountTopUp(req B2CTopUpRequest) (*B2CTopUpResponse, error) {
+	return c.B2CAccountTopUpWithContext(context.Background(), req)
+}
+
+func (c *Client) B2CAccountTopUpWithContext(ctx context.Context, req B2CTopUpRequest) (*B2CTopUpResponse, error) {
 	internalReq := daraja.B2CTopUpRequest{
 		Initiator:              req.Initiator,
 		SecurityCredential:     req.SecurityCredential,
+		InitiatorPassword:      req.InitiatorPassword,
 		CommandID:              "BusinessPayToBulk", // This API only supports BusinessPayToBulk command
 		SenderIdentifierType:   "4",                 // Only type 4 is allowed for this API
 		RecieverIdentifierType: "4",                 // Only type 4 is allowed for this API
@@ -41,7 +49,7 @@ func (c *Client) B2CAccountTopUp(req B2CTopUpRequest) (*B2CTopUpResponse, error)
 		ResultURL:              req.ResultURL,
 	}
 
-	resp, err := c.Service.B2CAccountTopUp(internalReq)
+	resp, err := c.Service.B2CAccountTopUpWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
@@ -88,6 +90,10 @@ type BillManagerUpdateOptInRequest struct {
 }
 
 func (c *Client) OptInBillManager(req BillManagerOptInRequest) (*BillManagerOptInResponse, error) {
+	return c.OptInBillManagerWithContext(context.Background(), req)
+}
+
+func (c *Client) OptInBillManagerWithContext(ctx context.Context, req BillManagerOptInRequest) (*BillManagerOptInResponse, error) {
 	darajaReq := daraja.BillManagerOptInRequest{
 		Shortcode:       req.Shortcode,
 		Email:           req.Email,
@@ -97,7 +103,7 @@ func (c *Client) OptInBillManager(req BillManagerOptInRequest) (*BillManagerOptI
 		CallbackURL:     req.CallbackURL,
 	}
 
-	resp, err := c.Service.OptInBillManager(darajaReq)
+	resp, err := c.Service.OptInBillManagerWithContext(ctx, darajaReq)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +116,10 @@ func (c *Client) OptInBillManager(req BillManagerOptInRequest) (*BillManagerOptI
 }
 
 func (c *Client) CreateSingleInvoice(req BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
+	return c.CreateSingleInvoiceWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateSingleInvoiceWithContext(ctx context.Context, req BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
 	invoiceItems := make([]daraja.BillManagerInvoiceItem, len(req.InvoiceItems))
 	for i, item := range req.InvoiceItems {
 		invoiceItems[i] = daraja.BillManagerInvoiceItem{
@@ -130,7 +140,7 @@ func (c *Client) CreateSingleInvoice(req BillManagerSingleInvoiceRequest) (*Bill
 		InvoiceItems:      invoiceItems,
 	}
 
-	resp, err := c.Service.CreateSingleInvoice(darajaReq)
+	resp, err := c.Service.CreateSingleInvoiceWithContext(ctx, darajaReq)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +153,10 @@ func (c *Client) CreateSingleInvoice(req BillManagerSingleInvoiceRequest) (*Bill
 }
 
 func (c *Client) CreateBulkInvoices(requests []BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
+	return c.CreateBulkInvoicesWithContext(context.Background(), requests)
+}
+
+func (c *Client) CreateBulkInvoicesWithContext(ctx context.Context, requests []BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
 	darajaReqs := make([]daraja.BillManagerSingleInvoiceRequest, len(requests))
 
 	for i, req := range requests {
@@ -167,7 +181,7 @@ func (c *Client) CreateBulkInvoices(requests []BillManagerSingleInvoiceRequest)
 		}
 	}
 
-	resp, err := c.Service.CreateBulkInvoices(darajaReqs)
+	resp, err := c.Service.CreateBulkInvoicesWithContext(ctx, darajaReqs)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +194,10 @@ func (c *Client) CreateBulkInvoices(requests []BillManagerSingleInvoiceRequest)
 }
 
 func (c *Client) SendPaymentAcknowledgment(req BillManagerAcknowledgmentRequest) (*BillManagerPaymentResponse, error) {
+	return c.SendPaymentAcknowledgmentWithContext(context.Background(), req)
+}
+
+func (c *Client) SendPaymentAcknowledgmentWithContext(ctx context.Context, req BillManagerAcknowledgmentRequest) (*BillManagerPaymentResponse, error) {
 	darajaReq := daraja.BillManagerAcknowledgmentRequest{
 		PaymentDate:       req.PaymentDate,
 		PaidAmount:        req.PaidAmount,
@@ -191,7 +209,7 @@ func (c *Client) SendPaymentAcknowledgment(req BillManagerAcknowledgmentRequest)
 		ExternalReference: req.ExternalReference,
 	}
 
-	resp, err := c.Service.SendPaymentAcknowledgment(darajaReq)
+	resp, err := c.Service.SendPaymentAcknowledgmentWithContext(ctx, darajaReq)
 	if err != nil {
 		return nil, err
 	}
@@ -203,11 +221,15 @@ func (c *Client) SendPaymentAcknowledgment(req BillManagerAcknowledgmentRequest)
 }
 
 func (c *Client) CancelSingleInvoice(req BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
+	return c.CancelSingleInvoiceWithContext(context.Background(), req)
+}
+
+func (c *Client) CancelSingleInvoiceWithContext(ctx context.Context, req BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
 	darajaReq := daraja.BillManagerCancelInvoiceRequest{
 		ExternalReference: req.ExternalReference,
 	}
 
-	resp, err := c.Service.CancelSingleInvoice(darajaReq)
+	resp, err := c.Service.CancelSingleInvoiceWithContext(ctx, darajaReq)
 	if err != nil {
 		return nil, err
 	}
@@ -221,6 +243,10 @@ func (c *Client) CancelSingleInvoice(req BillManagerCancelInvoiceRequest) (*Bill
 }
 
 func (c *Client) CancelBulkInvoices(requests []BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
+	return c.CancelBulkInvoicesWithContext(context.Background(), requests)
+}
+
+func (c *Client) CancelBulkInvoicesWithContext(ctx context.Context, requests []BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
 	darajaReqs := make([]daraja.BillManagerCancelInvoiceRequest, len(requests))
 
 	for i, req := range requests {
@@ -229,7 +255,7 @@ func (c *Client) CancelBulkInvoices(requests []BillManagerCancelInvoiceRequest)
 		}
 	}
 
-	resp, err := c.Service.CancelBulkInvoices(darajaReqs)
+	resp, err := c.Service.CancelBulkInvoicesWithContext(ctx, darajaReqs)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +269,10 @@ func (c *Client) CancelBulkInvoices(requests []BillManagerCancelInvoiceRequest)
 }
 
 func (c *Client) UpdateOptInDetails(req BillManagerUpdateOptInRequest) (*BillManagerPaymentResponse, error) {
+	return c.UpdateOptInDetailsWithContext(context.Background(), req)
+}
+
+func (c *Client) UpdateOptInDetailsWithContext(ctx context.Context, req BillManagerUpdateOptInRequest) (*BillManagerPaymentResponse, error) {
 	darajaReq := daraja.BillManagerUpdateOptInRequest{
 		Shortcode:       req.Shortcode,
 		Email:           req.Email,
@@ -252,7 +282,7 @@ func (c *Client) UpdateOptInDetails(req BillManagerUpdateOptInRequest) (*BillMan
 		CallbackURL:     req.CallbackURL,
 	}
 
-	resp, err := c.Service.UpdateOptInDetails(darajaReq)
+	resp, err := c.Service.UpdateOptInDetailsWithContext(ctx, darajaReq)
 	if err != nil {
 		return nil, err
 	}
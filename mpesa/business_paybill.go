@@ -1,12 +1,15 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
 type BusinessPayBillRequest struct {
 	Initiator          string // The M-Pesa API operator username
 	SecurityCredential string // The encrypted password of the M-Pesa API operator
+	InitiatorPassword  string // Optional: plain password, encrypted when SecurityCredential is empty
 	Amount             string // The transaction amount
 	PartyA             string // Your shortcode (from which money will be deducted)
 	PartyB             string // The shortcode to which money will be moved
@@ -26,9 +29,14 @@ type BusinessPayBillResponse struct {
 }
 
 func (c *Client) BusinessPayBill(req BusinessPayBillRequest) (*BusinessPayBillResponse, error) {
+	return c.BusinessPayBillWithContext(context.Background(), req)
+}
+
+func (c *Client) BusinessPayBillWithContext(ctx context.Context, req BusinessPayBillRequest) (*BusinessPayBillResponse, error) {
 	internalReq := daraja.BusinessPayBillRequest{
 		Initiator:              req.Initiator,
 		SecurityCredential:     req.SecurityCredential,
+		InitiatorPassword:      req.InitiatorPassword,
 		CommandID:              "BusinessPayBill", // This API only supports BusinessPayBill command
 		SenderIdentifierType:   "4",               // Only type 4 is allowed for this API
 		RecieverIdentifierType: "4",               // Only type 4 is allowed for this API
@@ -43,7 +51,7 @@ func (c *Client) BusinessPayBill(req BusinessPayBillRequest) (*BusinessPayBillRe
 		Occasion:               req.Occasion,
 	}
 
-	resp, err := c.Service.BusinessPayBill(internalReq)
+	resp, err := c.Service.BusinessPayBillWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,14 @@
 package mpesa
 
 import (
+	"context"
 	"net/http"
+	"time"
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -31,6 +37,46 @@ func (c *Client) SetHttpClient(httpClient *http.Client) {
 	c.Service.SetHttpClient(httpClient)
 }
 
+func (c *Client) SetBaseURL(baseURL string) {
+	c.Service.SetBaseURL(baseURL)
+}
+
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.Service.SetTokenStore(store)
+}
+
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.Service.SetTokenSkew(skew)
+}
+
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.Service.SetRetryPolicy(policy)
+}
+
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.Service.SetCircuitBreaker(settings)
+}
+
+func (c *Client) Health() breaker.Health {
+	return c.Service.Health()
+}
+
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.Service.Use(middleware...)
+}
+
 func (c *Client) GetAuthToken() (string, error) {
-	return c.Service.GetAuthToken()
+	return c.GetAuthTokenWithContext(context.Background())
+}
+
+func (c *Client) GetAuthTokenWithContext(ctx context.Context) (string, error) {
+	return c.Service.GetAuthTokenWithContext(ctx)
+}
+
+func (c *Client) SetCertificate(pemData []byte) error {
+	return c.Service.SetCertificate(pemData)
+}
+
+func (c *Client) SecurityCredential(initiatorPassword string) (string, error) {
+	return c.Service.SecurityCredential(initiatorPassword)
 }
@@ -28,21 +28,24 @@ type C2BSimulateParams struct {
 }
 
 type B2CPaymentParams struct {
-	InitiatorName      string
-	SecurityCredential string
-	CommandID          string
-	Amount             int
-	PartyA             int
-	PartyB             int
-	Remarks            string
-	QueueTimeOutURL    string
-	ResultURL          string
-	Occasion           string
+	OriginatorConversationID string // Optional, see daraja.B2CRequestBody
+	InitiatorName            string
+	SecurityCredential       string
+	InitiatorPassword        string
+	CommandID                string
+	Amount                   int
+	PartyA                   int
+	PartyB                   int
+	Remarks                  string
+	QueueTimeOutURL          string
+	ResultURL                string
+	Occasion                 string
 }
 
 type B2BPaymentParams struct {
 	Initiator              string
 	SecurityCredential     string
+	InitiatorPassword      string
 	CommandID              string
 	SenderIdentifierType   string
 	ReceiverIdentifierType string
@@ -59,6 +62,7 @@ type B2BPaymentParams struct {
 type TransactionStatusParams struct {
 	Initiator          string
 	SecurityCredential string
+	InitiatorPassword  string
 	CommandID          string
 	TransactionID      string
 	PartyA             int
@@ -72,6 +76,7 @@ type TransactionStatusParams struct {
 type AccountBalanceParams struct {
 	Initiator          string
 	SecurityCredential string
+	InitiatorPassword  string
 	CommandID          string
 	PartyA             int
 	IdentifierType     int
@@ -83,6 +88,7 @@ type AccountBalanceParams struct {
 type ReversalParams struct {
 	Initiator              string
 	SecurityCredential     string
+	InitiatorPassword      string
 	CommandID              string
 	TransactionID          string
 	Amount                 int
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type AccountBalanceRequestBody struct {
 	Initiator          string `json:"Initiator"`
 	SecurityCredential string `json:"SecurityCredential"`
+	InitiatorPassword  string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID          string `json:"CommandID"`
 	PartyA             int    `json:"PartyA"`
 	IdentifierType     int    `json:"IdentifierType"`
@@ -25,7 +27,17 @@ type AccountBalanceResponse struct {
 }
 
 func (s *Service) AccountBalance(body AccountBalanceRequestBody) (*AccountBalanceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, accountBalanceURL, body)
+	return s.AccountBalanceWithContext(context.Background(), body)
+}
+
+func (s *Service) AccountBalanceWithContext(ctx context.Context, body AccountBalanceRequestBody) (*AccountBalanceResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	body.SecurityCredential = credential
+
+	respBody, err := s.makeRequest(ctx, http.MethodPost, accountBalanceURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make account balance request: %w", err)
 	}
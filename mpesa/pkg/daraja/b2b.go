@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type BusinessToBusinessRequestBody struct {
 	Initiator              string `json:"Initiator"`
 	SecurityCredential     string `json:"SecurityCredential"`
+	InitiatorPassword      string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID              string `json:"CommandID"`
 	SenderIdentifierType   string `json:"SenderIdentifierType"`
 	RecieverIdentifierType string `json:"RecieverIdentifierType"`
@@ -30,7 +32,17 @@ type BusinessToBusinessResponse struct {
 }
 
 func (s *Service) BusinessToBusinessPayment(body BusinessToBusinessRequestBody) (*BusinessToBusinessResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, b2bURL, body)
+	return s.BusinessToBusinessPaymentWithContext(context.Background(), body)
+}
+
+func (s *Service) BusinessToBusinessPaymentWithContext(ctx context.Context, body BusinessToBusinessRequestBody) (*BusinessToBusinessResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	body.SecurityCredential = credential
+
+	respBody, err := s.makeRequest(ctx, http.MethodPost, b2bURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make B2B payment request: %w", err)
 	}
@@ -1,14 +1,23 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
 )
 
 type B2CRequestBody struct {
+	// OriginatorConversationID identifies the request; M-Pesa rejects a
+	// repeated ID as a duplicate. Defaults to the idempotency key in the
+	// context, if any.
+	OriginatorConversationID string `json:"OriginatorConversationID,omitempty"`
+
 	InitiatorName      string `json:"InitiatorName"`
 	SecurityCredential string `json:"SecurityCredential"`
+	InitiatorPassword  string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID          string `json:"CommandID"`
 	Amount             int    `json:"Amount"`
 	PartyA             int    `json:"PartyA"`
@@ -27,7 +36,20 @@ type B2CResponse struct {
 }
 
 func (s *Service) B2CPayment(body B2CRequestBody) (*B2CResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, b2cURL, body)
+	return s.B2CPaymentWithContext(context.Background(), body)
+}
+
+func (s *Service) B2CPaymentWithContext(ctx context.Context, body B2CRequestBody) (*B2CResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, idempotency.NotSent(err)
+	}
+	body.SecurityCredential = credential
+	if body.OriginatorConversationID == "" {
+		body.OriginatorConversationID = idempotency.FromContext(ctx)
+	}
+
+	respBody, err := s.makeRequest(ctx, http.MethodPost, b2cURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make B2C payment request: %w", err)
 	}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type B2CTopUpRequest struct {
 	Initiator              string `json:"Initiator"`
 	SecurityCredential     string `json:"SecurityCredential"`
+	InitiatorPassword      string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID              string `json:"CommandID"`
 	SenderIdentifierType   string `json:"SenderIdentifierType"`
 	RecieverIdentifierType string `json:"RecieverIdentifierType"`
@@ -30,6 +32,16 @@ type B2CTopUpResponse struct {
 }
 
 func (s *Service) B2CAccountTopUp(req B2CTopUpRequest) (*B2CTopUpResponse, error) {
+	return s.B2CAccountTopUpWithContext(context.Background(), req)
+}
+
+func (s *Service) B2CAccountTopUpWithContext(ctx context.Context, req B2CTopUpRequest) (*B2CTopUpResponse, error) {
+	credential, err := s.resolveSecurityCredential(req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	req.SecurityCredential = credential
+
 	if req.CommandID != "BusinessPayToBulk" {
 		return nil, fmt.Errorf("invalid CommandID: only BusinessPayToBulk is allowed for this API")
 	}
@@ -42,7 +54,7 @@ func (s *Service) B2CAccountTopUp(req B2CTopUpRequest) (*B2CTopUpResponse, error
 		return nil, fmt.Errorf("invalid RecieverIdentifierType: only 4 is allowed for this API")
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, b2bURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, b2bURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make B2C Account Top Up request: %w", err)
 	}
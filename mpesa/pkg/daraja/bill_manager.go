@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -99,7 +100,11 @@ type BillManagerUpdateOptInRequest struct {
 }
 
 func (s *Service) OptInBillManager(req BillManagerOptInRequest) (*BillManagerOptInResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerOptInURL, req)
+	return s.OptInBillManagerWithContext(context.Background(), req)
+}
+
+func (s *Service) OptInBillManagerWithContext(ctx context.Context, req BillManagerOptInRequest) (*BillManagerOptInResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerOptInURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to opt-in to Bill Manager: %w", err)
 	}
@@ -113,7 +118,11 @@ func (s *Service) OptInBillManager(req BillManagerOptInRequest) (*BillManagerOpt
 }
 
 func (s *Service) CreateSingleInvoice(req BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerSingleInvoicingURL, req)
+	return s.CreateSingleInvoiceWithContext(context.Background(), req)
+}
+
+func (s *Service) CreateSingleInvoiceWithContext(ctx context.Context, req BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerSingleInvoicingURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create single invoice: %w", err)
 	}
@@ -127,7 +136,11 @@ func (s *Service) CreateSingleInvoice(req BillManagerSingleInvoiceRequest) (*Bil
 }
 
 func (s *Service) CreateBulkInvoices(requests []BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerBulkInvoicingURL, requests)
+	return s.CreateBulkInvoicesWithContext(context.Background(), requests)
+}
+
+func (s *Service) CreateBulkInvoicesWithContext(ctx context.Context, requests []BillManagerSingleInvoiceRequest) (*BillManagerInvoiceResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerBulkInvoicingURL, requests)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bulk invoices: %w", err)
 	}
@@ -141,7 +154,11 @@ func (s *Service) CreateBulkInvoices(requests []BillManagerSingleInvoiceRequest)
 }
 
 func (s *Service) SendPaymentAcknowledgment(req BillManagerAcknowledgmentRequest) (*BillManagerPaymentResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerReconciliationURL, req)
+	return s.SendPaymentAcknowledgmentWithContext(context.Background(), req)
+}
+
+func (s *Service) SendPaymentAcknowledgmentWithContext(ctx context.Context, req BillManagerAcknowledgmentRequest) (*BillManagerPaymentResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerReconciliationURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send payment acknowledgment: %w", err)
 	}
@@ -155,7 +172,11 @@ func (s *Service) SendPaymentAcknowledgment(req BillManagerAcknowledgmentRequest
 }
 
 func (s *Service) CancelSingleInvoice(req BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerCancelSingleURL, req)
+	return s.CancelSingleInvoiceWithContext(context.Background(), req)
+}
+
+func (s *Service) CancelSingleInvoiceWithContext(ctx context.Context, req BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerCancelSingleURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cancel single invoice: %w", err)
 	}
@@ -170,7 +191,12 @@ func (s *Service) CancelSingleInvoice(req BillManagerCancelInvoiceRequest) (*Bil
 
 // CancelBulkInvoices cancels multiple invoices
 func (s *Service) CancelBulkInvoices(requests []BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerCancelBulkURL, requests)
+	return s.CancelBulkInvoicesWithContext(context.Background(), requests)
+}
+
+// CancelBulkInvoicesWithContext is like CancelBulkInvoices but takes a context.
+func (s *Service) CancelBulkInvoicesWithContext(ctx context.Context, requests []BillManagerCancelInvoiceRequest) (*BillManagerCancelInvoiceResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerCancelBulkURL, requests)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cancel bulk invoices: %w", err)
 	}
@@ -185,7 +211,12 @@ func (s *Service) CancelBulkInvoices(requests []BillManagerCancelInvoiceRequest)
 
 // UpdateOptInDetails updates the Bill Manager opt-in details
 func (s *Service) UpdateOptInDetails(req BillManagerUpdateOptInRequest) (*BillManagerPaymentResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, billManagerUpdateOptInURL, req)
+	return s.UpdateOptInDetailsWithContext(context.Background(), req)
+}
+
+// UpdateOptInDetailsWithContext is like UpdateOptInDetails but takes a context.
+func (s *Service) UpdateOptInDetailsWithContext(ctx context.Context, req BillManagerUpdateOptInRequest) (*BillManagerPaymentResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, billManagerUpdateOptInURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update opt-in details: %w", err)
 	}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type BusinessPayBillRequest struct {
 	Initiator              string `json:"Initiator"`
 	SecurityCredential     string `json:"SecurityCredential"`
+	InitiatorPassword      string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID              string `json:"CommandID"`
 	SenderIdentifierType   string `json:"SenderIdentifierType"`
 	RecieverIdentifierType string `json:"RecieverIdentifierType"`
@@ -31,6 +33,16 @@ type BusinessPayBillResponse struct {
 }
 
 func (s *Service) BusinessPayBill(req BusinessPayBillRequest) (*BusinessPayBillResponse, error) {
+	return s.BusinessPayBillWithContext(context.Background(), req)
+}
+
+func (s *Service) BusinessPayBillWithContext(ctx context.Context, req BusinessPayBillRequest) (*BusinessPayBillResponse, error) {
+	credential, err := s.resolveSecurityCredential(req.SecurityCredential, req.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	req.SecurityCredential = credential
+
 	if req.CommandID != "BusinessPayBill" {
 		return nil, fmt.Errorf("invalid CommandID: only BusinessPayBill is allowed for this API")
 	}
@@ -43,7 +55,7 @@ func (s *Service) BusinessPayBill(req BusinessPayBillRequest) (*BusinessPayBillR
 		return nil, fmt.Errorf("invalid RecieverIdentifierType: only 4 is allowed for this API")
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, b2bURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, b2bURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make Business Pay Bill request: %w", err)
 	}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -34,7 +35,11 @@ type C2BSimulateResponse struct {
 }
 
 func (s *Service) C2BRegisterURL(body RegisterC2BURLBody) (*RegisterC2BURLResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, c2bRegisterURL, body)
+	return s.C2BRegisterURLWithContext(context.Background(), body)
+}
+
+func (s *Service) C2BRegisterURLWithContext(ctx context.Context, body RegisterC2BURLBody) (*RegisterC2BURLResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, c2bRegisterURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make C2B register URL request: %w", err)
 	}
@@ -48,7 +53,11 @@ func (s *Service) C2BRegisterURL(body RegisterC2BURLBody) (*RegisterC2BURLRespon
 }
 
 func (s *Service) C2BSimulate(body C2BSimulateRequestBody) (*C2BSimulateResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, c2bSimulateURL, body)
+	return s.C2BSimulateWithContext(context.Background(), body)
+}
+
+func (s *Service) C2BSimulateWithContext(ctx context.Context, body C2BSimulateRequestBody) (*C2BSimulateResponse, error) {
+	respBody, err := s.makeRequest(ctx, http.MethodPost, c2bSimulateURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make C2B simulate request: %w", err)
 	}
@@ -0,0 +1,236 @@
+package daraja
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	C2BResultAccepted = "0"
+
+	C2BRejectInvalidMSISDN        = "C2B00011"
+	C2BRejectInvalidAccountNumber = "C2B00012"
+	C2BRejectInvalidAmount        = "C2B00013"
+	C2BRejectInvalidKYCDetails    = "C2B00014"
+	C2BRejectInvalidShortcode     = "C2B00015"
+	C2BRejectOtherError           = "C2B00016"
+
+	DefaultC2BValidationTimeout = 5 * time.Second
+
+	c2bTransTimeLayout = "20060102150405"
+)
+
+var hashedMSISDNPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+type C2BValidationRequest struct {
+	TransactionType   string `json:"TransactionType"`
+	TransID           string `json:"TransID"`
+	TransTime         string `json:"TransTime"`
+	TransAmount       string `json:"TransAmount"`
+	BusinessShortCode string `json:"BusinessShortCode"`
+	BillRefNumber     string `json:"BillRefNumber"`
+	InvoiceNumber     string `json:"InvoiceNumber"`
+	OrgAccountBalance string `json:"OrgAccountBalance"`
+	ThirdPartyTransID string `json:"ThirdPartyTransID"`
+	MSISDN            string `json:"MSISDN"`
+	FirstName         string `json:"FirstName"`
+	MiddleName        string `json:"MiddleName"`
+	LastName          string `json:"LastName"`
+}
+
+// C2BConfirmation carries the same fields as the validation request, with
+// OrgAccountBalance populated once the payment has completed.
+type C2BConfirmation C2BValidationRequest
+
+func (r *C2BValidationRequest) Amount() (decimal.Decimal, error) {
+	return decimal.NewFromString(r.TransAmount)
+}
+
+func (r *C2BValidationRequest) Time() (time.Time, error) {
+	return time.ParseInLocation(c2bTransTimeLayout, r.TransTime, eatLocation)
+}
+
+// MSISDNHashed reports whether MSISDN is the SHA-256 hash newer C2B payloads send
+// in place of the customer's phone number.
+func (r *C2BValidationRequest) MSISDNHashed() bool {
+	return hashedMSISDNPattern.MatchString(r.MSISDN)
+}
+
+func (c *C2BConfirmation) Amount() (decimal.Decimal, error) {
+	return (*C2BValidationRequest)(c).Amount()
+}
+
+func (c *C2BConfirmation) Time() (time.Time, error) {
+	return (*C2BValidationRequest)(c).Time()
+}
+
+func (c *C2BConfirmation) MSISDNHashed() bool {
+	return (*C2BValidationRequest)(c).MSISDNHashed()
+}
+
+// OrgBalance returns OrgAccountBalance, which is empty for paybills that do not
+// share their balance.
+func (c *C2BConfirmation) OrgBalance() (decimal.Decimal, error) {
+	if c.OrgAccountBalance == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(c.OrgAccountBalance)
+}
+
+// C2BDecision is the validation outcome, serialized in the response shape
+// Safaricom requires.
+type C2BDecision struct {
+	ResultCode string `json:"ResultCode"`
+	ResultDesc string `json:"ResultDesc"`
+}
+
+func C2BAccept() C2BDecision {
+	return C2BDecision{ResultCode: C2BResultAccepted, ResultDesc: "Accepted"}
+}
+
+// C2BReject rejects the payment with one of the C2BReject result codes.
+func C2BReject(resultCode string) C2BDecision {
+	return C2BDecision{ResultCode: resultCode, ResultDesc: "Rejected"}
+}
+
+func (d C2BDecision) Accepted() bool {
+	return d.ResultCode == C2BResultAccepted
+}
+
+type C2BValidationFunc func(ctx context.Context, req *C2BValidationRequest) (C2BDecision, error)
+
+type C2BConfirmationFunc func(ctx context.Context, confirmation *C2BConfirmation) error
+
+// C2BHandler serves the ValidationURL and ConfirmationURL registered with
+// C2BRegisterURL. If Validate does not return within ValidationTimeout the
+// TimeoutDecision is sent instead, so Safaricom always gets a deterministic
+// answer before its own timeout.
+type C2BHandler struct {
+	Validate          C2BValidationFunc
+	Confirm           C2BConfirmationFunc
+	ValidationTimeout time.Duration
+	TimeoutDecision   C2BDecision
+}
+
+func NewC2BHandler(validate C2BValidationFunc, confirm C2BConfirmationFunc) *C2BHandler {
+	return &C2BHandler{
+		Validate:          validate,
+		Confirm:           confirm,
+		ValidationTimeout: DefaultC2BValidationTimeout,
+		TimeoutDecision:   C2BAccept(),
+	}
+}
+
+// ValidationHandler returns the http.Handler to mount at the ValidationURL.
+func (h *C2BHandler) ValidationHandler() http.Handler {
+	return http.HandlerFunc(h.serveValidation)
+}
+
+// ConfirmationHandler returns the http.Handler to mount at the ConfirmationURL.
+func (h *C2BHandler) ConfirmationHandler() http.Handler {
+	return http.HandlerFunc(h.serveConfirmation)
+}
+
+func (h *C2BHandler) serveValidation(w http.ResponseWriter, r *http.Request) {
+	var req C2BValidationRequest
+	if err := decodeC2BRequest(r, &req); err != nil {
+		writeC2BDecision(w, http.StatusBadRequest, C2BReject(C2BRejectOtherError))
+		return
+	}
+
+	if h.Validate == nil {
+		writeC2BDecision(w, http.StatusOK, C2BAccept())
+		return
+	}
+
+	writeC2BDecision(w, http.StatusOK, h.validate(r.Context(), &req))
+}
+
+func (h *C2BHandler) validate(ctx context.Context, req *C2BValidationRequest) C2BDecision {
+	timeout := h.ValidationTimeout
+	if timeout <= 0 {
+		timeout = DefaultC2BValidationTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		decision C2BDecision
+		err      error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		decision, err := h.Validate(ctx, req)
+		done <- outcome{decision: decision, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			return C2BReject(C2BRejectOtherError)
+		}
+		if o.decision.ResultCode == "" {
+			return C2BAccept()
+		}
+		return o.decision
+	case <-ctx.Done():
+		return h.timeoutDecision()
+	}
+}
+
+func (h *C2BHandler) timeoutDecision() C2BDecision {
+	if h.TimeoutDecision.ResultCode == "" {
+		return C2BAccept()
+	}
+	return h.TimeoutDecision
+}
+
+func (h *C2BHandler) serveConfirmation(w http.ResponseWriter, r *http.Request) {
+	var confirmation C2BConfirmation
+	if err := decodeC2BRequest(r, &confirmation); err != nil {
+		writeC2BDecision(w, http.StatusBadRequest, C2BDecision{ResultCode: "1", ResultDesc: "Invalid request"})
+		return
+	}
+
+	if h.Confirm != nil {
+		if err := h.Confirm(r.Context(), &confirmation); err != nil {
+			writeC2BDecision(w, http.StatusInternalServerError, C2BDecision{ResultCode: "1", ResultDesc: "Failed"})
+			return
+		}
+	}
+
+	writeC2BDecision(w, http.StatusOK, C2BDecision{ResultCode: C2BResultAccepted, ResultDesc: "Success"})
+}
+
+func decodeC2BRequest(r *http.Request, v interface{}) error {
+	if r.Method != http.MethodPost {
+		return fmt.Errorf("invalid method: %s", r.Method)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse C2B request: %w", err)
+	}
+
+	return nil
+}
+
+func writeC2BDecision(w http.ResponseWriter, status int, decision C2BDecision) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(decision)
+}
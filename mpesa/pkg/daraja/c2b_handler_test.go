@@ -0,0 +1,124 @@
+package daraja
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const c2bPayload = `{
+	"TransactionType": "Pay Bill",
+	"TransID": "RKTQDM7W6S",
+	"TransTime": "20191122063845",
+	"TransAmount": "10",
+	"BusinessShortCode": "600638",
+	"BillRefNumber": "invoice008",
+	"InvoiceNumber": "",
+	"OrgAccountBalance": "49197.00",
+	"ThirdPartyTransID": "",
+	"MSISDN": "a5f4cb1d7ec9a6f0e5c1e2b4d2f9b1b4c7e0d3a8f6b2c9e1d4a7f0b3c6e9d2a5",
+	"FirstName": "John",
+	"MiddleName": "",
+	"LastName": "Doe"
+}`
+
+func postC2B(t *testing.T, h http.Handler, payload string) (int, C2BDecision) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/c2b", strings.NewReader(payload))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var decision C2BDecision
+	if err := json.Unmarshal(w.Body.Bytes(), &decision); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return w.Code, decision
+}
+
+func TestC2BValidationAccept(t *testing.T) {
+	var received *C2BValidationRequest
+	handler := NewC2BHandler(func(ctx context.Context, req *C2BValidationRequest) (C2BDecision, error) {
+		received = req
+		return C2BAccept(), nil
+	}, nil)
+
+	code, decision := postC2B(t, handler.ValidationHandler(), c2bPayload)
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if decision.ResultCode != "0" || decision.ResultDesc != "Accepted" {
+		t.Errorf("Unexpected decision %+v", decision)
+	}
+
+	if received.BillRefNumber != "invoice008" || received.FirstName != "John" {
+		t.Errorf("Unexpected request %+v", received)
+	}
+	if !received.MSISDNHashed() {
+		t.Error("Expected MSISDN to be detected as hashed")
+	}
+	amount, err := received.Amount()
+	if err != nil || amount.String() != "10" {
+		t.Errorf("Expected amount 10, got %s (%v)", amount, err)
+	}
+}
+
+func TestC2BValidationReject(t *testing.T) {
+	handler := NewC2BHandler(func(ctx context.Context, req *C2BValidationRequest) (C2BDecision, error) {
+		return C2BReject(C2BRejectInvalidAccountNumber), nil
+	}, nil)
+
+	_, decision := postC2B(t, handler.ValidationHandler(), c2bPayload)
+	if decision.ResultCode != C2BRejectInvalidAccountNumber || decision.ResultDesc != "Rejected" {
+		t.Errorf("Unexpected decision %+v", decision)
+	}
+
+	handler.Validate = func(ctx context.Context, req *C2BValidationRequest) (C2BDecision, error) {
+		return C2BDecision{}, errors.New("database unavailable")
+	}
+	_, decision = postC2B(t, handler.ValidationHandler(), c2bPayload)
+	if decision.ResultCode != C2BRejectOtherError {
+		t.Errorf("Expected %s on validation error, got %+v", C2BRejectOtherError, decision)
+	}
+}
+
+func TestC2BValidationTimeout(t *testing.T) {
+	handler := NewC2BHandler(func(ctx context.Context, req *C2BValidationRequest) (C2BDecision, error) {
+		<-ctx.Done()
+		return C2BAccept(), nil
+	}, nil)
+	handler.ValidationTimeout = 10 * time.Millisecond
+	handler.TimeoutDecision = C2BReject(C2BRejectOtherError)
+
+	_, decision := postC2B(t, handler.ValidationHandler(), c2bPayload)
+	if decision.ResultCode != C2BRejectOtherError {
+		t.Errorf("Expected timeout decision, got %+v", decision)
+	}
+}
+
+func TestC2BConfirmation(t *testing.T) {
+	var received *C2BConfirmation
+	handler := NewC2BHandler(nil, func(ctx context.Context, c *C2BConfirmation) error {
+		received = c
+		return nil
+	})
+
+	code, decision := postC2B(t, handler.ConfirmationHandler(), c2bPayload)
+	if code != http.StatusOK || decision.ResultCode != "0" {
+		t.Errorf("Unexpected response %d %+v", code, decision)
+	}
+
+	balance, err := received.OrgBalance()
+	if err != nil || balance.String() != "49197" {
+		t.Errorf("Expected balance 49197, got %s (%v)", balance, err)
+	}
+	transTime, err := received.Time()
+	if err != nil || transTime.Year() != 2019 {
+		t.Errorf("Unexpected TransTime %s (%v)", transTime, err)
+	}
+}
@@ -2,13 +2,20 @@ package daraja
 
 import (
 	"bytes"
+	"context"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Environment string
@@ -27,8 +34,6 @@ const (
 	accountBalanceURL    = "/mpesa/accountbalance/v1/query"
 	transactionStatusURL = "/mpesa/transactionstatus/v1/query"
 	reversalURL          = "/mpesa/reversal/v1/request"
-
-	authTokenCacheKey = "auth_token"
 )
 
 type Service struct {
@@ -38,12 +43,15 @@ type Service struct {
 	environment    Environment
 	baseURL        string
 	httpClient     *http.Client
-	cache          *cache.Cache
+	tokens         *auth.TokenManager
+	retry          retry.Policy
+	breaker        *breaker.Breaker
+	certificate    atomic.Pointer[rsa.PublicKey] // Set by SetCertificate, possibly while requests run
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   string `json:"expires_in"`
+	AccessToken string         `json:"access_token"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
 }
 
 func New(apiKey, consumerSecret, passKey string, environment Environment) (*Service, error) {
@@ -56,95 +64,169 @@ func New(apiKey, consumerSecret, passKey string, environment Environment) (*Serv
 		baseURL = "https://api.safaricom.co.ke"
 	}
 
-	c := cache.New(1*time.Hour, 10*time.Minute)
-
-	return &Service{
+	s := &Service{
 		apiKey:         apiKey,
 		consumerSecret: consumerSecret,
 		passKey:        passKey,
 		environment:    environment,
 		baseURL:        baseURL,
 		httpClient:     &http.Client{Timeout: 30 * time.Second},
-		cache:          c,
-	}, nil
+	}
+	s.tokens = auth.NewTokenManager(nil, auth.Key("mpesa", string(environment), apiKey), s.fetchAuthToken)
+	s.tokens.SetRejected(tokenRejected)
+	s.retry = retry.DefaultPolicy()
+	s.breaker = breaker.New(provider, breaker.DefaultSettings())
+
+	return s, nil
 }
 
 func (s *Service) SetHttpClient(httpClient *http.Client) {
 	s.httpClient = httpClient
 }
 
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server. Tokens fetched from
+// baseURL are stored apart from the environment's.
+func (s *Service) SetBaseURL(baseURL string) {
+	s.baseURL = baseURL
+	s.tokens.SetKey(auth.Key("mpesa", baseURL, s.apiKey))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same consumer key reuse one token instead of each requesting their own.
+func (s *Service) SetTokenStore(store auth.TokenStore) {
+	s.tokens.SetStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (s *Service) SetTokenSkew(skew time.Duration) {
+	s.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (s *Service) SetRetryPolicy(policy retry.Policy) {
+	s.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (s *Service) SetCircuitBreaker(settings breaker.Settings) {
+	s.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (s *Service) Health() breaker.Health {
+	return s.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (s *Service) roundTrip(req *http.Request) (*http.Response, error) {
+	return s.breaker.Do(req, s.httpClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log. Call it after SetHttpClient, which replaces
+// the client the middleware is installed on.
+func (s *Service) Use(middleware ...transport.Middleware) {
+	s.httpClient = transport.Wrap(s.httpClient, provider, middleware...)
+}
+
 func (s *Service) GetAuthToken() (string, error) {
-	if token, found := s.cache.Get(authTokenCacheKey); found {
-		return token.(string), nil
+	return s.GetAuthTokenWithContext(context.Background())
+}
+
+func (s *Service) GetAuthTokenWithContext(ctx context.Context) (string, error) {
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
+func (s *Service) fetchAuthToken(ctx context.Context) (auth.Token, error) {
 	url := s.baseURL + authURL
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(s.apiKey + ":" + s.consumerSecret))
-	req.Header.Set("Authorization", "Basic "+auth)
+	basic := base64.StdEncoding.EncodeToString([]byte(s.apiKey + ":" + s.consumerSecret))
+	req.Header.Set("Authorization", "Basic "+basic)
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := s.httpClient.Do(req)
+	resp, err := s.roundTrip(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to execute auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("auth request failed with status: %s", resp.Status)
+		body, _ := readResponseBody(resp)
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 
 	var authResp AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return "", fmt.Errorf("failed to decode auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
-	expiresIn := 3600
-	s.cache.Set(authTokenCacheKey, authResp.AccessToken, time.Duration(expiresIn)*time.Second)
-
-	return authResp.AccessToken, nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
-func (s *Service) makeRequest(method, url string, payload interface{}) ([]byte, error) {
-	token, err := s.GetAuthToken()
-	if err != nil {
-		return nil, err
-	}
-
+func (s *Service) makeRequest(ctx context.Context, method, url string, payload interface{}) ([]byte, error) {
 	var reqBody []byte
 	if payload != nil {
+		var err error
 		reqBody, err = json.Marshal(payload)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request payload: %w", err)
+			return nil, idempotency.NotSent(fmt.Errorf("failed to marshal request payload: %w", err))
 		}
 	}
 
-	req, err := http.NewRequest(method, s.baseURL+url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	// Errors before the request goes out, such as a failed token fetch,
+	// are marked so idempotency.Do can release the key
+	sent := false
+	resp, err := s.tokens.Do(ctx, func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, s.baseURL+url, bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
 
-	resp, err := s.httpClient.Do(req)
+		sent = true
+		resp, err := s.retry.Do(req, s.roundTrip)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+		return resp, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		if !sent {
+			err = idempotency.NotSent(err)
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status: %s", resp.Status)
-	}
-
 	respBody, err := readResponseBody(resp)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, newProviderError(resp, respBody)
+	}
+
 	return respBody, nil
 }
 
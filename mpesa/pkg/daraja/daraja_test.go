@@ -1,10 +1,19 @@
 package daraja
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 func TestNew(t *testing.T) {
@@ -33,8 +42,8 @@ func TestNew(t *testing.T) {
 	if service.httpClient == nil {
 		t.Error("HTTP client should not be nil")
 	}
-	if service.cache == nil {
-		t.Error("Cache should not be nil")
+	if service.tokens == nil {
+		t.Error("Token manager should not be nil")
 	}
 
 	service, err = New("", "test-consumer-secret", "test-pass-key", SANDBOX)
@@ -84,6 +93,35 @@ func TestSetHttpClient(t *testing.T) {
 	}
 }
 
+func TestUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+	customClient := &http.Client{Timeout: 60 * time.Second}
+	service.SetHttpClient(customClient)
+
+	var events []transport.Event
+	service.Use(transport.Observe(transport.Hooks{Response: func(e transport.Event) { events = append(events, e) }}))
+
+	if _, err := service.GetAuthToken(); err != nil {
+		t.Fatalf("GetAuthToken failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].Provider != "mpesa" || events[0].Operation != "GET /oauth/v1/generate" || events[0].StatusCode() != http.StatusOK {
+		t.Errorf("Unexpected event %+v", events[0])
+	}
+	if customClient.Transport != nil {
+		t.Error("Expected Use not to modify the client passed to SetHttpClient")
+	}
+}
+
 func TestGetAuthToken(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/oauth/v1/generate" {
@@ -125,6 +163,35 @@ func TestGetAuthToken(t *testing.T) {
 	}
 }
 
+func TestSharedTokenStore(t *testing.T) {
+	var authCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&authCalls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"shared-token","expires_in":"3599"}`))
+	}))
+	defer server.Close()
+
+	store := auth.NewMemoryTokenStore()
+	for i := 0; i < 3; i++ {
+		service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+		service.baseURL = server.URL
+		service.SetTokenStore(store)
+
+		token, err := service.GetAuthToken()
+		if err != nil {
+			t.Fatalf("GetAuthToken failed: %v", err)
+		}
+		if token != "shared-token" {
+			t.Errorf("Expected token to be 'shared-token', got '%s'", token)
+		}
+	}
+
+	if authCalls != 1 {
+		t.Errorf("Expected 1 auth request across services, got %d", authCalls)
+	}
+}
+
 func TestMakeRequest(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/oauth/v1/generate" {
@@ -153,7 +220,7 @@ func TestMakeRequest(t *testing.T) {
 	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
 	service.baseURL = server.URL
 	
-	resp, err := service.makeRequest(http.MethodGet, "/test/endpoint", nil)
+	resp, err := service.makeRequest(context.Background(), http.MethodGet, "/test/endpoint", nil)
 	if err != nil {
 		t.Fatalf("makeRequest failed: %v", err)
 	}
@@ -163,3 +230,179 @@ func TestMakeRequest(t *testing.T) {
 		t.Errorf("Expected response '%s', got '%s'", expected, string(resp))
 	}
 }
+
+func TestRequestWithCanceledContext(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := service.QueryStkPushWithContext(ctx, "174379", "ws_CO_123"); err == nil {
+		t.Error("Expected error for canceled context, got nil")
+	}
+	if _, err := service.GetAuthTokenWithContext(ctx); err == nil {
+		t.Error("Expected error for canceled context, got nil")
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests to be sent, got %d", requests)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var queries, pushes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+		case stkPushQueryURL:
+			if atomic.AddInt32(&queries, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"ResponseCode":"0","ResultCode":"0"}`))
+		case stkPushURL:
+			atomic.AddInt32(&pushes, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var retries int
+	policy := retry.DefaultPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.OnRetry = func(retry.Attempt) { retries++ }
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+	service.SetRetryPolicy(policy)
+
+	if _, err := service.QueryStkPush("174379", "ws_CO_123"); err != nil {
+		t.Fatalf("QueryStkPush failed: %v", err)
+	}
+	if queries != 2 || retries != 1 {
+		t.Errorf("Expected the query to be retried once, got %d requests and %d retries", queries, retries)
+	}
+
+	if _, err := service.InitiateStkPush(STKPushBody{BusinessShortCode: "174379"}); err == nil {
+		t.Error("Expected error for 503, got nil")
+	}
+	if pushes != 1 {
+		t.Errorf("Expected STK push to be sent once, got %d", pushes)
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+		case stkPushURL:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"requestId":"req-1","errorCode":"400.002.02","errorMessage":"Bad Request - Invalid PhoneNumber"}`))
+		case stkPushQueryURL:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"requestId":"req-2","errorCode":"404.001.03","errorMessage":"Invalid Access Token"}`))
+		}
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+
+	_, err := service.InitiateStkPush(STKPushBody{BusinessShortCode: "174379"})
+	perr, ok := railerrors.As(err)
+	if !ok {
+		t.Fatalf("Expected ProviderError, got %v", err)
+	}
+	if perr.Provider != "mpesa" || perr.StatusCode != http.StatusBadRequest || perr.Code != "400.002.02" || perr.RequestID != "req-1" {
+		t.Errorf("Unexpected ProviderError %+v", perr)
+	}
+	if perr.Category != railerrors.CategoryValidation {
+		t.Errorf("Expected category validation, got %s", perr.Category)
+	}
+
+	_, err = service.QueryStkPush("174379", "ws_CO_123")
+	if category := railerrors.CategoryOf(err); category != railerrors.CategoryAuth {
+		t.Errorf("Expected category auth for 404.001.03, got %s", category)
+	}
+}
+
+func TestInvalidAccessTokenRetry(t *testing.T) {
+	var tokens, queries int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/v1/generate" {
+			if atomic.AddInt32(&tokens, 1) == 1 {
+				w.Write([]byte(`{"access_token":"revoked-token","expires_in":"3599"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+			return
+		}
+
+		atomic.AddInt32(&queries, 1)
+		if r.Header.Get("Authorization") != "Bearer test-access-token" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"requestId":"req-1","errorCode":"404.001.03","errorMessage":"Invalid Access Token"}`))
+			return
+		}
+		w.Write([]byte(`{"ResponseCode":"0","ResultCode":"0"}`))
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+
+	resp, err := service.QueryStkPush("174379", "ws_CO_123")
+	if err != nil {
+		t.Fatalf("Expected the query to be retried with a new token, got %v", err)
+	}
+	if resp.ResultCode != "0" {
+		t.Errorf("Expected ResultCode '0', got '%s'", resp.ResultCode)
+	}
+	if tokens != 2 || queries != 2 {
+		t.Errorf("Expected 2 token fetches and 2 queries, got %d and %d", tokens, queries)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var pushes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+		case stkPushURL:
+			atomic.AddInt32(&pushes, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+	service.SetCircuitBreaker(breaker.Settings{ConsecutiveFailures: 2})
+
+	for i := 0; i < 3; i++ {
+		service.InitiateStkPush(STKPushBody{BusinessShortCode: "174379"})
+	}
+	if pushes != 2 {
+		t.Errorf("Expected the circuit to open after 2 failures, got %d requests", pushes)
+	}
+
+	_, err := service.InitiateStkPush(STKPushBody{BusinessShortCode: "174379"})
+	if !errors.Is(err, breaker.ErrOpen) || railerrors.CategoryOf(err) != railerrors.CategoryProviderDown {
+		t.Errorf("Expected a provider_down ErrOpen, got %v", err)
+	}
+
+	health := service.Health()
+	if health.Provider != "mpesa" || health.Endpoints["POST "+stkPushURL].State != breaker.StateOpen {
+		t.Errorf("Unexpected health %+v", health)
+	}
+}
@@ -0,0 +1,55 @@
+package daraja
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "mpesa"
+
+// ErrorResponse is the body Daraja sends with a rejected request.
+type ErrorResponse struct {
+	RequestID    string `json:"requestId"`
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// errorCodeCategories maps Daraja error codes whose HTTP status does not
+// match their meaning, e.g. an invalid access token is sent as a 404.
+var errorCodeCategories = map[string]railerrors.Category{
+	"404.001.03": railerrors.CategoryAuth,         // Invalid Access Token
+	"404.001.04": railerrors.CategoryAuth,         // Invalid Authentication Header
+	"400.002.05": railerrors.CategoryValidation,   // Invalid Request Payload
+	"500.003.02": railerrors.CategoryProviderDown, // System is busy
+	"500.003.03": railerrors.CategoryRateLimited,  // Quota violation, spike arrest
+}
+
+// tokenRejected reports whether a response means the access token was
+// refused. Besides a 401, Daraja sends an invalid or expired token as a 404
+// with errorCode 404.001.03.
+func tokenRejected(status int, body []byte) bool {
+	if auth.TokenRejected(status, body) {
+		return true
+	}
+	var errResp ErrorResponse
+	return json.Unmarshal(body, &errResp) == nil && errResp.ErrorCode == "404.001.03"
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	json.Unmarshal(body, &errResp)
+
+	perr := railerrors.FromResponse(provider, resp, errResp.ErrorCode, errResp.ErrorMessage)
+	if errResp.RequestID != "" {
+		perr.RequestID = errResp.RequestID
+	}
+	if category, ok := errorCodeCategories[errResp.ErrorCode]; ok {
+		perr.SetCategory(category)
+	}
+	return perr
+}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -24,6 +25,10 @@ type QRCodeResponse struct {
 }
 
 func (s *Service) GenerateQRCode(req QRCodeRequest) (*QRCodeResponse, error) {
+	return s.GenerateQRCodeWithContext(context.Background(), req)
+}
+
+func (s *Service) GenerateQRCodeWithContext(ctx context.Context, req QRCodeRequest) (*QRCodeResponse, error) {
 	if req.MerchantName == "" {
 		return nil, fmt.Errorf("merchant name is required")
 	}
@@ -44,7 +49,7 @@ func (s *Service) GenerateQRCode(req QRCodeRequest) (*QRCodeResponse, error) {
 		req.Size = "300"
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, qrCodeURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, qrCodeURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate QR code: %w", err)
 	}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -76,6 +77,10 @@ const (
 )
 
 func (s *Service) CreateStandingOrder(req RatibaRequest) (*RatibaResponse, error) {
+	return s.CreateStandingOrderWithContext(context.Background(), req)
+}
+
+func (s *Service) CreateStandingOrderWithContext(ctx context.Context, req RatibaRequest) (*RatibaResponse, error) {
 	if req.StandingOrderName == "" {
 		return nil, fmt.Errorf("StandingOrderName is required")
 	}
@@ -108,7 +113,7 @@ func (s *Service) CreateStandingOrder(req RatibaRequest) (*RatibaResponse, error
 		req.ReceiverPartyIdentifierType = ReceiverTypePaybill
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, ratibaURL, req)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, ratibaURL, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create standing order: %w", err)
 	}
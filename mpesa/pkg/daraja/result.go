@@ -0,0 +1,469 @@
+package daraja
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	resultCompletedLayout = "20060102150405"
+	b2cCompletedLayout    = "02.01.2006 15:04:05"
+)
+
+// ResultCode is the Result.ResultCode value. Safaricom sends it as a number for
+// most results and as a string for some timeouts, e.g. "SFC_IC0003".
+type ResultCode string
+
+func (c *ResultCode) UnmarshalJSON(b []byte) error {
+	*c = ResultCode(rawItemValue(b))
+	return nil
+}
+
+type ResultParameter struct {
+	Key   string          `json:"Key"`
+	Value json.RawMessage `json:"Value,omitempty"`
+}
+
+// ResultParameterList accepts both the array form and the single object form
+// Safaricom uses when only one parameter is present.
+type ResultParameterList []ResultParameter
+
+func (l *ResultParameterList) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || string(b) == "null" {
+		*l = nil
+		return nil
+	}
+	if b[0] == '[' {
+		var items []ResultParameter
+		if err := json.Unmarshal(b, &items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	}
+	var item ResultParameter
+	if err := json.Unmarshal(b, &item); err != nil {
+		return err
+	}
+	*l = ResultParameterList{item}
+	return nil
+}
+
+type ResultParameters struct {
+	ResultParameter ResultParameterList `json:"ResultParameter"`
+}
+
+type ReferenceData struct {
+	ReferenceItem ResultParameterList `json:"ReferenceItem"`
+}
+
+type Result struct {
+	ResultType               int               `json:"ResultType"`
+	ResultCode               ResultCode        `json:"ResultCode"`
+	ResultDesc               string            `json:"ResultDesc"`
+	OriginatorConversationID string            `json:"OriginatorConversationID"`
+	ConversationID           string            `json:"ConversationID"`
+	TransactionID            string            `json:"TransactionID"`
+	ResultParameters         *ResultParameters `json:"ResultParameters,omitempty"`
+	ReferenceData            *ReferenceData    `json:"ReferenceData,omitempty"`
+}
+
+type ResultEnvelope struct {
+	Result Result `json:"Result"`
+}
+
+// ParseResult decodes the payload posted to a ResultURL or QueueTimeOutURL.
+func ParseResult(data []byte) (*Result, error) {
+	var envelope ResultEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+	if envelope.Result.OriginatorConversationID == "" && envelope.Result.ConversationID == "" {
+		return nil, fmt.Errorf("result is missing OriginatorConversationID and ConversationID")
+	}
+	return &envelope.Result, nil
+}
+
+func (r *Result) Succeeded() bool {
+	return r.ResultCode == "0"
+}
+
+// Parameter returns the ResultParameters value for key with any JSON quoting removed.
+func (r *Result) Parameter(key string) (string, bool) {
+	if r.ResultParameters == nil {
+		return "", false
+	}
+	for _, p := range r.ResultParameters.ResultParameter {
+		if p.Key == key {
+			return rawItemValue(p.Value), true
+		}
+	}
+	return "", false
+}
+
+// Reference returns the ReferenceData value for key.
+func (r *Result) Reference(key string) (string, bool) {
+	if r.ReferenceData == nil {
+		return "", false
+	}
+	for _, p := range r.ReferenceData.ReferenceItem {
+		if p.Key == key {
+			return rawItemValue(p.Value), true
+		}
+	}
+	return "", false
+}
+
+type B2CResult struct {
+	TransactionAmount                   decimal.Decimal
+	TransactionReceipt                  string
+	ReceiverPartyPublicName             string
+	TransactionCompletedDateTime        time.Time
+	B2CRecipientIsRegisteredCustomer    bool
+	B2CUtilityAccountAvailableFunds     decimal.Decimal
+	B2CWorkingAccountAvailableFunds     decimal.Decimal
+	B2CChargesPaidAccountAvailableFunds decimal.Decimal
+}
+
+type B2BResult struct {
+	Amount                           decimal.Decimal
+	Currency                         string
+	TransCompletedTime               time.Time
+	ReceiverPartyPublicName          string
+	DebitPartyCharges                string
+	DebitAccountBalance              string
+	DebitPartyAffectedAccountBalance string
+	InitiatorAccountCurrentBalance   string
+	BillReferenceNumber              string
+}
+
+type ReversalResult struct {
+	Amount                decimal.Decimal
+	OriginalTransactionID string
+	TransCompletedTime    time.Time
+	Charge                decimal.Decimal
+	CreditPartyPublicName string
+	DebitPartyPublicName  string
+	DebitAccountBalance   string
+}
+
+type TransactionStatusResult struct {
+	ReceiptNo         string
+	Amount            decimal.Decimal
+	TransactionStatus string
+	ReasonType        string
+	TransactionReason string
+	DebitPartyName    string
+	CreditPartyName   string
+	DebitPartyCharges string
+	DebitAccountType  string
+	InitiatedTime     time.Time
+	FinalisedTime     time.Time
+}
+
+type AccountBalanceEntry struct {
+	Account   string
+	Currency  string
+	Available decimal.Decimal
+	Current   decimal.Decimal
+	Reserved  decimal.Decimal
+	Uncleared decimal.Decimal
+}
+
+type AccountBalanceResult struct {
+	Accounts        []AccountBalanceEntry
+	BOCompletedTime time.Time
+}
+
+// resultParser accumulates the first conversion error so the typed result
+// builders can read parameters without checking every field.
+type resultParser struct {
+	result *Result
+	err    error
+}
+
+func (p *resultParser) string(key string) string {
+	v, _ := p.result.Parameter(key)
+	return v
+}
+
+func (p *resultParser) decimal(key string) decimal.Decimal {
+	v, ok := p.result.Parameter(key)
+	if !ok || v == "" || p.err != nil {
+		return decimal.Zero
+	}
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return d
+}
+
+func (p *resultParser) time(key, layout string) time.Time {
+	v, ok := p.result.Parameter(key)
+	if !ok || v == "" || p.err != nil {
+		return time.Time{}
+	}
+	t, err := time.ParseInLocation(layout, v, eatLocation)
+	if err != nil {
+		p.err = fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return t
+}
+
+func (r *Result) B2C() (*B2CResult, error) {
+	p := &resultParser{result: r}
+	res := &B2CResult{
+		TransactionAmount:                   p.decimal("TransactionAmount"),
+		TransactionReceipt:                  p.string("TransactionReceipt"),
+		ReceiverPartyPublicName:             p.string("ReceiverPartyPublicName"),
+		TransactionCompletedDateTime:        p.time("TransactionCompletedDateTime", b2cCompletedLayout),
+		B2CRecipientIsRegisteredCustomer:    p.string("B2CRecipientIsRegisteredCustomer") == "Y",
+		B2CUtilityAccountAvailableFunds:     p.decimal("B2CUtilityAccountAvailableFunds"),
+		B2CWorkingAccountAvailableFunds:     p.decimal("B2CWorkingAccountAvailableFunds"),
+		B2CChargesPaidAccountAvailableFunds: p.decimal("B2CChargesPaidAccountAvailableFunds"),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse B2C result: %w", p.err)
+	}
+	return res, nil
+}
+
+func (r *Result) B2B() (*B2BResult, error) {
+	p := &resultParser{result: r}
+	res := &B2BResult{
+		Amount:                           p.decimal("Amount"),
+		Currency:                         p.string("Currency"),
+		TransCompletedTime:               p.time("TransCompletedTime", resultCompletedLayout),
+		ReceiverPartyPublicName:          p.string("ReceiverPartyPublicName"),
+		DebitPartyCharges:                p.string("DebitPartyCharges"),
+		DebitAccountBalance:              p.string("DebitAccountBalance"),
+		DebitPartyAffectedAccountBalance: p.string("DebitPartyAffectedAccountBalance"),
+		InitiatorAccountCurrentBalance:   p.string("InitiatorAccountCurrentBalance"),
+	}
+	res.BillReferenceNumber, _ = r.Reference("BillReferenceNumber")
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse B2B result: %w", p.err)
+	}
+	return res, nil
+}
+
+func (r *Result) Reversal() (*ReversalResult, error) {
+	return r.ReversalWithContext(context.Background())
+}
+
+func (r *Result) ReversalWithContext(ctx context.Context) (*ReversalResult, error) {
+	p := &resultParser{result: r}
+	res := &ReversalResult{
+		Amount:                p.decimal("Amount"),
+		OriginalTransactionID: p.string("OriginalTransactionID"),
+		TransCompletedTime:    p.time("TransCompletedTime", resultCompletedLayout),
+		Charge:                p.decimal("Charge"),
+		CreditPartyPublicName: p.string("CreditPartyPublicName"),
+		DebitPartyPublicName:  p.string("DebitPartyPublicName"),
+		DebitAccountBalance:   p.string("DebitAccountBalance"),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse reversal result: %w", p.err)
+	}
+	return res, nil
+}
+
+func (r *Result) TransactionStatus() (*TransactionStatusResult, error) {
+	return r.TransactionStatusWithContext(context.Background())
+}
+
+func (r *Result) TransactionStatusWithContext(ctx context.Context) (*TransactionStatusResult, error) {
+	p := &resultParser{result: r}
+	res := &TransactionStatusResult{
+		ReceiptNo:         p.string("ReceiptNo"),
+		Amount:            p.decimal("Amount"),
+		TransactionStatus: p.string("TransactionStatus"),
+		ReasonType:        p.string("ReasonType"),
+		TransactionReason: p.string("TransactionReason"),
+		DebitPartyName:    p.string("DebitPartyName"),
+		CreditPartyName:   p.string("CreditPartyName"),
+		DebitPartyCharges: p.string("DebitPartyCharges"),
+		DebitAccountType:  p.string("DebitAccountType"),
+		InitiatedTime:     p.time("InitiatedTime", resultCompletedLayout),
+		FinalisedTime:     p.time("FinalisedTime", resultCompletedLayout),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse transaction status result: %w", p.err)
+	}
+	return res, nil
+}
+
+func (r *Result) AccountBalance() (*AccountBalanceResult, error) {
+	return r.AccountBalanceWithContext(context.Background())
+}
+
+func (r *Result) AccountBalanceWithContext(ctx context.Context) (*AccountBalanceResult, error) {
+	p := &resultParser{result: r}
+	res := &AccountBalanceResult{
+		BOCompletedTime: p.time("BOCompletedTime", resultCompletedLayout),
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("failed to parse account balance result: %w", p.err)
+	}
+
+	accounts, err := ParseAccountBalances(p.string("AccountBalance"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse account balance result: %w", err)
+	}
+	res.Accounts = accounts
+
+	return res, nil
+}
+
+// ParseAccountBalances splits the AccountBalance result parameter, e.g.
+// "Working Account|KES|46713.00|46713.00|0.00|0.00&Float Account|KES|0.00|0.00|0.00|0.00",
+// into one entry per account.
+func ParseAccountBalances(s string) ([]AccountBalanceEntry, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var entries []AccountBalanceEntry
+	for _, account := range strings.Split(s, "&") {
+		fields := strings.Split(account, "|")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid account balance entry %q", account)
+		}
+
+		entry := AccountBalanceEntry{
+			Account:  strings.TrimSpace(fields[0]),
+			Currency: strings.TrimSpace(fields[1]),
+		}
+		amounts := []*decimal.Decimal{&entry.Available, &entry.Current, &entry.Reserved, &entry.Uncleared}
+		for i, amount := range amounts {
+			d, err := decimal.NewFromString(strings.TrimSpace(fields[i+2]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount in account balance entry %q: %w", account, err)
+			}
+			*amount = d
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+type ResultFunc func(ctx context.Context, result *Result) error
+
+type resultRoute struct {
+	onResult  ResultFunc
+	onTimeout ResultFunc
+}
+
+// ResultRouter dispatches ResultURL and QueueTimeOutURL callbacks to the
+// functions registered for the request's OriginatorConversationID, falling back
+// to OnResult and OnTimeout for unregistered conversations. Routes are removed
+// once a result or timeout has been handled without error.
+type ResultRouter struct {
+	OnResult  ResultFunc
+	OnTimeout ResultFunc
+
+	mu     sync.Mutex
+	routes map[string]resultRoute
+}
+
+func NewResultRouter(onResult, onTimeout ResultFunc) *ResultRouter {
+	return &ResultRouter{
+		OnResult:  onResult,
+		OnTimeout: onTimeout,
+		routes:    make(map[string]resultRoute),
+	}
+}
+
+// Register routes the result or timeout for originatorConversationID, as
+// returned by B2CPayment, BusinessToBusinessPayment, Reversal, TransactionStatus
+// or AccountBalance, to onResult or onTimeout.
+func (rr *ResultRouter) Register(originatorConversationID string, onResult, onTimeout ResultFunc) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.routes[originatorConversationID] = resultRoute{onResult: onResult, onTimeout: onTimeout}
+}
+
+func (rr *ResultRouter) Unregister(originatorConversationID string) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	delete(rr.routes, originatorConversationID)
+}
+
+func (rr *ResultRouter) lookup(originatorConversationID string) (resultRoute, bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	route, ok := rr.routes[originatorConversationID]
+	return route, ok
+}
+
+// ResultHandler returns the http.Handler to mount at the ResultURL.
+func (rr *ResultRouter) ResultHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rr.serve(w, r, false)
+	})
+}
+
+// TimeoutHandler returns the http.Handler to mount at the QueueTimeOutURL.
+func (rr *ResultRouter) TimeoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rr.serve(w, r, true)
+	})
+}
+
+func (rr *ResultRouter) serve(w http.ResponseWriter, r *http.Request, timeout bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeCallbackAck(w, http.StatusBadRequest, 1, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := ParseResult(body)
+	if err != nil {
+		writeCallbackAck(w, http.StatusBadRequest, 1, err.Error())
+		return
+	}
+
+	fn := rr.OnResult
+	if timeout {
+		fn = rr.OnTimeout
+	}
+	route, routed := rr.lookup(result.OriginatorConversationID)
+	if routed {
+		fn = route.onResult
+		if timeout {
+			fn = route.onTimeout
+		}
+	}
+
+	if fn != nil {
+		if err := fn(r.Context(), result); err != nil {
+			writeCallbackAck(w, http.StatusInternalServerError, 1, "Rejected")
+			return
+		}
+	}
+
+	if routed {
+		rr.Unregister(result.OriginatorConversationID)
+	}
+
+	writeCallbackAck(w, http.StatusOK, 0, "Accepted")
+}
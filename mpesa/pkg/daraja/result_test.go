@@ -0,0 +1,193 @@
+package daraja
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const b2cResultPayload = `{
+	"Result": {
+		"ResultType": 0,
+		"ResultCode": 0,
+		"ResultDesc": "The service request is processed successfully.",
+		"OriginatorConversationID": "10571-7910404-1",
+		"ConversationID": "AG_20191219_00004e48cf7e3533f581",
+		"TransactionID": "NLJ41HAY6Q",
+		"ResultParameters": {
+			"ResultParameter": [
+				{"Key": "TransactionAmount", "Value": 10},
+				{"Key": "TransactionReceipt", "Value": "NLJ41HAY6Q"},
+				{"Key": "B2CRecipientIsRegisteredCustomer", "Value": "Y"},
+				{"Key": "B2CChargesPaidAccountAvailableFunds", "Value": -4510.00},
+				{"Key": "ReceiverPartyPublicName", "Value": "254708374149 - John Doe"},
+				{"Key": "TransactionCompletedDateTime", "Value": "19.12.2019 11:45:50"},
+				{"Key": "B2CUtilityAccountAvailableFunds", "Value": 10116.00},
+				{"Key": "B2CWorkingAccountAvailableFunds", "Value": 900000.00}
+			]
+		},
+		"ReferenceData": {
+			"ReferenceItem": {"Key": "QueueTimeoutURL", "Value": "https://internalsandbox.safaricom.co.ke/mpesa/b2cresults/v1/submit"}
+		}
+	}
+}`
+
+const accountBalanceResultPayload = `{
+	"Result": {
+		"ResultType": 0,
+		"ResultCode": 0,
+		"ResultDesc": "The service request is processed successfully.",
+		"OriginatorConversationID": "16917-22577599-3",
+		"ConversationID": "AG_20200206_00005e091a8ec6b9eac5",
+		"TransactionID": "OA90000000",
+		"ResultParameters": {
+			"ResultParameter": [
+				{"Key": "AccountBalance", "Value": "Working Account|KES|700000.00|700000.00|0.00|0.00&Float Account|KES|0.00|0.00|0.00|0.00&Utility Account|KES|228037.00|228037.00|0.00|0.00"},
+				{"Key": "BOCompletedTime", "Value": 20200109125710}
+			]
+		}
+	}
+}`
+
+const timeoutPayload = `{
+	"Result": {
+		"ResultType": 1,
+		"ResultCode": "SFC_IC0003",
+		"ResultDesc": "The request timed out.",
+		"OriginatorConversationID": "10571-7910404-1",
+		"ConversationID": "AG_20191219_00004e48cf7e3533f581"
+	}
+}`
+
+func TestParseB2CResult(t *testing.T) {
+	result, err := ParseResult([]byte(b2cResultPayload))
+	if err != nil {
+		t.Fatalf("ParseResult failed: %v", err)
+	}
+	if !result.Succeeded() {
+		t.Error("Expected result to be successful")
+	}
+
+	queueTimeoutURL, ok := result.Reference("QueueTimeoutURL")
+	if !ok || !strings.HasPrefix(queueTimeoutURL, "https://") {
+		t.Errorf("Expected single ReferenceItem to be decoded, got %q", queueTimeoutURL)
+	}
+
+	b2c, err := result.B2C()
+	if err != nil {
+		t.Fatalf("B2C failed: %v", err)
+	}
+	if b2c.TransactionReceipt != "NLJ41HAY6Q" {
+		t.Errorf("Expected receipt NLJ41HAY6Q, got %s", b2c.TransactionReceipt)
+	}
+	if b2c.ReceiverPartyPublicName != "254708374149 - John Doe" {
+		t.Errorf("Unexpected ReceiverPartyPublicName %s", b2c.ReceiverPartyPublicName)
+	}
+	if b2c.B2CUtilityAccountAvailableFunds.String() != "10116" {
+		t.Errorf("Expected utility funds 10116, got %s", b2c.B2CUtilityAccountAvailableFunds)
+	}
+	if b2c.B2CChargesPaidAccountAvailableFunds.String() != "-4510" {
+		t.Errorf("Expected charges paid funds -4510, got %s", b2c.B2CChargesPaidAccountAvailableFunds)
+	}
+	if !b2c.B2CRecipientIsRegisteredCustomer {
+		t.Error("Expected recipient to be a registered customer")
+	}
+	if b2c.TransactionCompletedDateTime.Year() != 2019 || b2c.TransactionCompletedDateTime.Hour() != 11 {
+		t.Errorf("Unexpected completion time %s", b2c.TransactionCompletedDateTime)
+	}
+}
+
+func TestParseAccountBalanceResult(t *testing.T) {
+	result, err := ParseResult([]byte(accountBalanceResultPayload))
+	if err != nil {
+		t.Fatalf("ParseResult failed: %v", err)
+	}
+
+	balance, err := result.AccountBalance()
+	if err != nil {
+		t.Fatalf("AccountBalance failed: %v", err)
+	}
+	if len(balance.Accounts) != 3 {
+		t.Fatalf("Expected 3 accounts, got %d", len(balance.Accounts))
+	}
+
+	utility := balance.Accounts[2]
+	if utility.Account != "Utility Account" || utility.Currency != "KES" {
+		t.Errorf("Unexpected account %+v", utility)
+	}
+	if utility.Available.String() != "228037" {
+		t.Errorf("Expected available 228037, got %s", utility.Available)
+	}
+	if balance.BOCompletedTime.IsZero() {
+		t.Error("Expected BOCompletedTime to be parsed")
+	}
+}
+
+func TestParseAccountBalancesInvalid(t *testing.T) {
+	if _, err := ParseAccountBalances("Working Account|KES|1.00"); err == nil {
+		t.Error("Expected error for truncated entry")
+	}
+	if _, err := ParseAccountBalances("Working Account|KES|abc|0|0|0"); err == nil {
+		t.Error("Expected error for invalid amount")
+	}
+}
+
+func TestParseTimeoutResult(t *testing.T) {
+	result, err := ParseResult([]byte(timeoutPayload))
+	if err != nil {
+		t.Fatalf("ParseResult failed: %v", err)
+	}
+	if result.ResultCode != "SFC_IC0003" {
+		t.Errorf("Expected string result code, got %s", result.ResultCode)
+	}
+	if result.Succeeded() {
+		t.Error("Expected timeout not to be successful")
+	}
+}
+
+func TestResultRouter(t *testing.T) {
+	var routed, fallback, timedOut int
+	router := NewResultRouter(
+		func(ctx context.Context, result *Result) error {
+			fallback++
+			return nil
+		},
+		nil,
+	)
+	router.Register("10571-7910404-1",
+		func(ctx context.Context, result *Result) error {
+			routed++
+			return nil
+		},
+		func(ctx context.Context, result *Result) error {
+			timedOut++
+			return nil
+		},
+	)
+
+	post := func(h http.Handler, payload string) int {
+		req := httptest.NewRequest(http.MethodPost, "/result", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(router.TimeoutHandler(), timeoutPayload); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if timedOut != 1 {
+		t.Errorf("Expected timeout callback to be called once, got %d", timedOut)
+	}
+
+	// The route is consumed by the timeout, so the late result uses the fallback.
+	post(router.ResultHandler(), b2cResultPayload)
+	if routed != 0 || fallback != 1 {
+		t.Errorf("Expected fallback result callback, got routed=%d fallback=%d", routed, fallback)
+	}
+
+	if code := post(router.ResultHandler(), `{"Result":{}}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+}
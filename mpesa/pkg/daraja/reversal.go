@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type ReversalRequestBody struct {
 	Initiator              string `json:"Initiator"`
 	SecurityCredential     string `json:"SecurityCredential"`
+	InitiatorPassword      string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID              string `json:"CommandID"`
 	TransactionID          string `json:"TransactionID"`
 	Amount                 int    `json:"Amount"`
@@ -28,7 +30,17 @@ type ReversalResponse struct {
 }
 
 func (s *Service) Reversal(body ReversalRequestBody) (*ReversalResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, reversalURL, body)
+	return s.ReversalWithContext(context.Background(), body)
+}
+
+func (s *Service) ReversalWithContext(ctx context.Context, body ReversalRequestBody) (*ReversalResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	body.SecurityCredential = credential
+
+	respBody, err := s.makeRequest(ctx, http.MethodPost, reversalURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make reversal request: %w", err)
 	}
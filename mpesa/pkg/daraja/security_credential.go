@@ -0,0 +1,127 @@
+package daraja
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"embed"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+const (
+	sandboxCertificatePath    = "certs/sandbox.cer"
+	productionCertificatePath = "certs/production.cer"
+)
+
+// The Safaricom public certificates are bundled from the certs directory. Both
+// are published on the Daraja portal under "Getting Started".
+//
+//go:embed certs
+var bundledCertificates embed.FS
+
+// SetCertificate overrides the bundled Safaricom certificate used by
+// SecurityCredential. pemData may be PEM or DER encoded. It is safe to call
+// while requests are in flight, e.g. when Safaricom rotates its certificate.
+func (s *Service) SetCertificate(pemData []byte) error {
+	key, err := parseRSAPublicKey(pemData)
+	if err != nil {
+		return err
+	}
+	s.certificate.Store(key)
+	return nil
+}
+
+// SecurityCredential encrypts the initiator password with the Safaricom public
+// certificate for the Service's environment using RSA PKCS#1 v1.5 and returns
+// it base64 encoded, ready for use as a request's SecurityCredential.
+func (s *Service) SecurityCredential(initiatorPassword string) (string, error) {
+	if initiatorPassword == "" {
+		return "", fmt.Errorf("initiator password is required")
+	}
+
+	key := s.certificate.Load()
+	if key == nil {
+		var err error
+		key, err = bundledCertificate(s.environment)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return encryptSecurityCredential(initiatorPassword, key)
+}
+
+// EncryptSecurityCredential encrypts initiatorPassword with the given
+// certificate, for callers that manage the certificate themselves.
+func EncryptSecurityCredential(initiatorPassword string, certificate []byte) (string, error) {
+	key, err := parseRSAPublicKey(certificate)
+	if err != nil {
+		return "", err
+	}
+	return encryptSecurityCredential(initiatorPassword, key)
+}
+
+// resolveSecurityCredential returns credential unchanged when it is set and
+// otherwise encrypts initiatorPassword at request time.
+func (s *Service) resolveSecurityCredential(credential, initiatorPassword string) (string, error) {
+	if credential != "" || initiatorPassword == "" {
+		return credential, nil
+	}
+
+	credential, err := s.SecurityCredential(initiatorPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate security credential: %w", err)
+	}
+	return credential, nil
+}
+
+func encryptSecurityCredential(initiatorPassword string, key *rsa.PublicKey) (string, error) {
+	encrypted, err := rsa.EncryptPKCS1v15(rand.Reader, key, []byte(initiatorPassword))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt initiator password: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func bundledCertificate(environment Environment) (*rsa.PublicKey, error) {
+	path := sandboxCertificatePath
+	if environment == PRODUCTION {
+		path = productionCertificatePath
+	}
+
+	data, err := bundledCertificates.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no %s certificate is bundled at %s, call SetCertificate with the certificate from the Daraja portal", environment, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s certificate: %w", environment, err)
+	}
+
+	return parseRSAPublicKey(data)
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	}
+
+	var key interface{}
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		key = cert.PublicKey
+	} else if pub, err := x509.ParsePKIXPublicKey(der); err == nil {
+		key = pub
+	} else {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate does not contain an RSA public key")
+	}
+	return rsaKey, nil
+}
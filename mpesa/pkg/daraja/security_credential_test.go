@@ -0,0 +1,251 @@
+package daraja
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+)
+
+func testCertificate(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "apicrypt.safaricom.co.ke"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func decryptCredential(t *testing.T, key *rsa.PrivateKey, credential string) string {
+	t.Helper()
+
+	encrypted, err := base64.StdEncoding.DecodeString(credential)
+	if err != nil {
+		t.Fatalf("Credential is not base64: %v", err)
+	}
+	plain, err := rsa.DecryptPKCS1v15(rand.Reader, key, encrypted)
+	if err != nil {
+		t.Fatalf("Failed to decrypt credential: %v", err)
+	}
+	return string(plain)
+}
+
+func TestSecurityCredential(t *testing.T) {
+	key, cert := testCertificate(t)
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	if err := service.SetCertificate(cert); err != nil {
+		t.Fatalf("SetCertificate failed: %v", err)
+	}
+
+	credential, err := service.SecurityCredential("Safaricom999!*!")
+	if err != nil {
+		t.Fatalf("SecurityCredential failed: %v", err)
+	}
+	if plain := decryptCredential(t, key, credential); plain != "Safaricom999!*!" {
+		t.Errorf("Expected decrypted password 'Safaricom999!*!', got '%s'", plain)
+	}
+
+	block, _ := pem.Decode(cert)
+	credential, err = EncryptSecurityCredential("secret", block.Bytes)
+	if err != nil {
+		t.Fatalf("EncryptSecurityCredential with DER failed: %v", err)
+	}
+	if plain := decryptCredential(t, key, credential); plain != "secret" {
+		t.Errorf("Expected decrypted password 'secret', got '%s'", plain)
+	}
+
+	if _, err := service.SecurityCredential(""); err == nil {
+		t.Error("Expected error for empty initiator password")
+	}
+	if err := service.SetCertificate([]byte("not a certificate")); err == nil {
+		t.Error("Expected error for invalid certificate")
+	}
+
+	production, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", PRODUCTION)
+	if _, err := production.SecurityCredential("secret"); err == nil && !hasBundledCertificate(PRODUCTION) {
+		t.Error("Expected error when no certificate is bundled")
+	}
+}
+
+func TestBundledCertificates(t *testing.T) {
+	for _, environment := range []Environment{SANDBOX, PRODUCTION} {
+		key, err := bundledCertificate(environment)
+		if err != nil {
+			// See certs/README.md: the certificates must come from the Daraja portal
+			t.Skipf("No %s certificate is bundled: %v", environment, err)
+		}
+
+		service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", environment)
+		credential, err := service.SecurityCredential("Safaricom999!*!")
+		if err != nil {
+			t.Fatalf("%s: SecurityCredential failed: %v", environment, err)
+		}
+		encrypted, err := base64.StdEncoding.DecodeString(credential)
+		if err != nil {
+			t.Fatalf("%s: credential is not base64: %v", environment, err)
+		}
+		if len(encrypted) != key.Size() {
+			t.Errorf("%s: expected a %d byte RSA block, got %d bytes", environment, key.Size(), len(encrypted))
+		}
+	}
+}
+
+func TestSetCertificateConcurrent(t *testing.T) {
+	_, cert := testCertificate(t)
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	if err := service.SetCertificate(cert); err != nil {
+		t.Fatalf("SetCertificate failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			service.SetCertificate(cert)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := service.SecurityCredential("Safaricom999!*!"); err != nil {
+				t.Errorf("SecurityCredential failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func hasBundledCertificate(environment Environment) bool {
+	_, err := bundledCertificate(environment)
+	return err == nil
+}
+
+func TestB2CPaymentWithInitiatorPassword(t *testing.T) {
+	key, cert := testCertificate(t)
+
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/v1/generate" {
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+			return
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"ConversationID":"AG_20191219_00005797af5d7d75f652","OriginatorConversationID":"16740-34861180-1","ResponseCode":"0","ResponseDescription":"Accept the service request successfully."}`))
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+	if err := service.SetCertificate(cert); err != nil {
+		t.Fatalf("SetCertificate failed: %v", err)
+	}
+
+	_, err := service.B2CPayment(B2CRequestBody{
+		InitiatorName:     "testapi",
+		InitiatorPassword: "Safaricom999!*!",
+		CommandID:         "BusinessPayment",
+		Amount:            10,
+		PartyA:            600000,
+		PartyB:            254708374149,
+		Remarks:           "Test",
+		QueueTimeOutURL:   "https://example.com/timeout",
+		ResultURL:         "https://example.com/result",
+	})
+	if err != nil {
+		t.Fatalf("B2CPayment failed: %v", err)
+	}
+
+	if _, ok := received["InitiatorPassword"]; ok {
+		t.Error("InitiatorPassword must not be sent to Daraja")
+	}
+	credential, _ := received["SecurityCredential"].(string)
+	if plain := decryptCredential(t, key, credential); plain != "Safaricom999!*!" {
+		t.Errorf("Expected decrypted password 'Safaricom999!*!', got '%s'", plain)
+	}
+}
+
+func TestB2CPaymentIdempotency(t *testing.T) {
+	_, cert := testCertificate(t)
+
+	var payments int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/v1/generate" {
+			w.Write([]byte(`{"access_token":"test-access-token","expires_in":"3599"}`))
+			return
+		}
+		if atomic.AddInt32(&payments, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ConversationID":"AG_20191219_00005797af5d7d75f652","ResponseCode":"0"}`))
+	}))
+	defer server.Close()
+
+	service, _ := New("test-api-key", "test-consumer-secret", "test-pass-key", SANDBOX)
+	service.baseURL = server.URL
+	if err := service.SetCertificate(cert); err != nil {
+		t.Fatalf("SetCertificate failed: %v", err)
+	}
+
+	store := idempotency.NewMemoryStore(0)
+	pay := func(key, password string) error {
+		body := B2CRequestBody{InitiatorName: "testapi", InitiatorPassword: password, Amount: 10}
+		_, err := idempotency.Do(context.Background(), store, key, body.Amount, func(ctx context.Context) (*B2CResponse, error) {
+			return service.B2CPaymentWithContext(ctx, body)
+		})
+		return err
+	}
+
+	// Too long for the key, so the payment fails before it is sent
+	if err := pay("payout-1", strings.Repeat("x", 300)); !errors.Is(err, idempotency.ErrNotSent) {
+		t.Fatalf("Expected an error marked not sent, got %v", err)
+	}
+	if err := pay("payout-1", "Safaricom999!*!"); err != nil {
+		t.Fatalf("Expected the released key to be sent again, got %v", err)
+	}
+	if payments != 1 {
+		t.Errorf("Expected 1 payment to reach Daraja, got %d", payments)
+	}
+
+	// A 503 may have been acted on, so the key stays pending
+	if err := pay("payout-2", "Safaricom999!*!"); errors.Is(err, idempotency.ErrNotSent) {
+		t.Fatalf("Expected a 503 not to be marked not sent, got %v", err)
+	}
+	if err := pay("payout-2", "Safaricom999!*!"); !errors.Is(err, idempotency.ErrInProgress) {
+		t.Errorf("Expected ErrInProgress for a repeat after a 503, got %v", err)
+	}
+	if payments != 2 {
+		t.Errorf("Expected 2 payments to reach Daraja, got %d", payments)
+	}
+}
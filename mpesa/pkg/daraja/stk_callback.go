@@ -0,0 +1,209 @@
+package daraja
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	STKResultSuccess             = 0
+	STKResultInsufficientBalance = 1
+	STKResultCancelledByUser     = 1032
+	STKResultUserUnreachable     = 1037
+	STKResultInvalidPIN          = 2001
+
+	stkTransactionDateLayout = "20060102150405"
+)
+
+// Safaricom reports callback timestamps in East Africa Time.
+var eatLocation = time.FixedZone("EAT", 3*60*60)
+
+type STKCallbackItem struct {
+	Name  string          `json:"Name"`
+	Value json.RawMessage `json:"Value,omitempty"`
+}
+
+type STKCallbackMetadata struct {
+	Item []STKCallbackItem `json:"Item"`
+}
+
+type STKCallback struct {
+	MerchantRequestID string               `json:"MerchantRequestID"`
+	CheckoutRequestID string               `json:"CheckoutRequestID"`
+	ResultCode        int                  `json:"ResultCode"`
+	ResultDesc        string               `json:"ResultDesc"`
+	CallbackMetadata  *STKCallbackMetadata `json:"CallbackMetadata,omitempty"`
+
+	// Typed values extracted from CallbackMetadata by ParseSTKCallback.
+	Amount             decimal.Decimal `json:"-"`
+	MpesaReceiptNumber string          `json:"-"`
+	TransactionDate    time.Time       `json:"-"`
+	PhoneNumber        string          `json:"-"` // E.164, e.g. +254708374149
+	Balance            string          `json:"-"`
+}
+
+type STKCallbackBody struct {
+	StkCallback STKCallback `json:"stkCallback"`
+}
+
+type STKCallbackEnvelope struct {
+	Body STKCallbackBody `json:"Body"`
+}
+
+// CallbackAck is the acknowledgement body Safaricom expects in reply to a callback.
+type CallbackAck struct {
+	ResultCode int    `json:"ResultCode"`
+	ResultDesc string `json:"ResultDesc"`
+}
+
+// Succeeded reports whether the customer completed the payment.
+func (cb *STKCallback) Succeeded() bool {
+	return cb.ResultCode == STKResultSuccess
+}
+
+// Item returns the raw metadata value for name with any JSON quoting removed.
+func (cb *STKCallback) Item(name string) (string, bool) {
+	if cb.CallbackMetadata == nil {
+		return "", false
+	}
+	for _, item := range cb.CallbackMetadata.Item {
+		if item.Name == name {
+			return rawItemValue(item.Value), true
+		}
+	}
+	return "", false
+}
+
+// ParseSTKCallback decodes the Body.stkCallback payload posted to the STK Push
+// CallBackURL and extracts the CallbackMetadata items into typed fields.
+func ParseSTKCallback(data []byte) (*STKCallback, error) {
+	var envelope STKCallbackEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse STK callback: %w", err)
+	}
+
+	cb := &envelope.Body.StkCallback
+	if cb.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("STK callback is missing CheckoutRequestID")
+	}
+
+	if v, ok := cb.Item("Amount"); ok && v != "" {
+		amount, err := decimal.NewFromString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STK callback Amount %q: %w", v, err)
+		}
+		cb.Amount = amount
+	}
+
+	if v, ok := cb.Item("MpesaReceiptNumber"); ok {
+		cb.MpesaReceiptNumber = v
+	}
+
+	if v, ok := cb.Item("TransactionDate"); ok && v != "" {
+		date, err := time.ParseInLocation(stkTransactionDateLayout, v, eatLocation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid STK callback TransactionDate %q: %w", v, err)
+		}
+		cb.TransactionDate = date
+	}
+
+	if v, ok := cb.Item("PhoneNumber"); ok && v != "" {
+		cb.PhoneNumber = toE164(v)
+	}
+
+	if v, ok := cb.Item("Balance"); ok {
+		cb.Balance = v
+	}
+
+	return cb, nil
+}
+
+func rawItemValue(raw json.RawMessage) string {
+	s := strings.TrimSpace(string(raw))
+	if s == "" || s == "null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// toE164 returns phone in E.164, reading numbers without a calling code as
+// Kenyan. Values that do not parse as a mobile number are returned as they
+// are.
+func toE164(phone string) string {
+	n, err := msisdn.Parse(phone, msisdn.Kenya)
+	if err != nil {
+		return strings.TrimSpace(phone)
+	}
+	return n.E164()
+}
+
+type STKCallbackFunc func(ctx context.Context, cb *STKCallback) error
+
+// STKCallbackHandler is an http.Handler for the STK Push CallBackURL. OnSuccess is
+// called when ResultCode is 0 and OnFailure for every other ResultCode.
+type STKCallbackHandler struct {
+	OnSuccess STKCallbackFunc
+	OnFailure STKCallbackFunc
+}
+
+func NewSTKCallbackHandler(onSuccess, onFailure STKCallbackFunc) *STKCallbackHandler {
+	return &STKCallbackHandler{
+		OnSuccess: onSuccess,
+		OnFailure: onFailure,
+	}
+}
+
+func (h *STKCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeCallbackAck(w, http.StatusBadRequest, 1, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	cb, err := ParseSTKCallback(body)
+	if err != nil {
+		writeCallbackAck(w, http.StatusBadRequest, 1, err.Error())
+		return
+	}
+
+	fn := h.OnFailure
+	if cb.Succeeded() {
+		fn = h.OnSuccess
+	}
+
+	if fn != nil {
+		if err := fn(r.Context(), cb); err != nil {
+			writeCallbackAck(w, http.StatusInternalServerError, 1, "Rejected")
+			return
+		}
+	}
+
+	writeCallbackAck(w, http.StatusOK, 0, "Accepted")
+}
+
+func writeCallbackAck(w http.ResponseWriter, status, resultCode int, resultDesc string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(CallbackAck{
+		ResultCode: resultCode,
+		ResultDesc: resultDesc,
+	})
+}
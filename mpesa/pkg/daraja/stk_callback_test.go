@@ -0,0 +1,164 @@
+package daraja
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const stkSuccessPayload = `{
+	"Body": {
+		"stkCallback": {
+			"MerchantRequestID": "29115-34620561-1",
+			"CheckoutRequestID": "ws_CO_191220191020363925",
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"CallbackMetadata": {
+				"Item": [
+					{"Name": "Amount", "Value": 1.00},
+					{"Name": "MpesaReceiptNumber", "Value": "NLJ7RT61SV"},
+					{"Name": "TransactionDate", "Value": 20191219102115},
+					{"Name": "PhoneNumber", "Value": 254708374149}
+				]
+			}
+		}
+	}
+}`
+
+const stkCancelledPayload = `{
+	"Body": {
+		"stkCallback": {
+			"MerchantRequestID": "29115-34620561-1",
+			"CheckoutRequestID": "ws_CO_191220191020363925",
+			"ResultCode": 1032,
+			"ResultDesc": "Request cancelled by user."
+		}
+	}
+}`
+
+func TestParseSTKCallback(t *testing.T) {
+	cb, err := ParseSTKCallback([]byte(stkSuccessPayload))
+	if err != nil {
+		t.Fatalf("ParseSTKCallback failed: %v", err)
+	}
+
+	if !cb.Succeeded() {
+		t.Error("Expected callback to be successful")
+	}
+	if cb.Amount.String() != "1" {
+		t.Errorf("Expected amount 1, got %s", cb.Amount.String())
+	}
+	if cb.MpesaReceiptNumber != "NLJ7RT61SV" {
+		t.Errorf("Expected receipt NLJ7RT61SV, got %s", cb.MpesaReceiptNumber)
+	}
+	if cb.PhoneNumber != "+254708374149" {
+		t.Errorf("Expected phone +254708374149, got %s", cb.PhoneNumber)
+	}
+
+	expected := time.Date(2019, 12, 19, 7, 21, 15, 0, time.UTC)
+	if !cb.TransactionDate.Equal(expected) {
+		t.Errorf("Expected transaction date %s, got %s", expected, cb.TransactionDate.UTC())
+	}
+}
+
+func TestToE164(t *testing.T) {
+	tests := map[string]string{
+		"254708374149":  "+254708374149",
+		"+254708374149": "+254708374149",
+		"0708374149":    "+254708374149",
+		"708374149":     "+254708374149",
+		"0110123456":    "+254110123456",
+		"not-a-number":  "not-a-number",
+	}
+	for phone, want := range tests {
+		if got := toE164(phone); got != want {
+			t.Errorf("toE164(%s): expected %s, got %s", phone, want, got)
+		}
+	}
+}
+
+func TestParseSTKCallbackFailure(t *testing.T) {
+	cb, err := ParseSTKCallback([]byte(stkCancelledPayload))
+	if err != nil {
+		t.Fatalf("ParseSTKCallback failed: %v", err)
+	}
+
+	if cb.Succeeded() {
+		t.Error("Expected callback to be unsuccessful")
+	}
+	if cb.ResultCode != STKResultCancelledByUser {
+		t.Errorf("Expected result code %d, got %d", STKResultCancelledByUser, cb.ResultCode)
+	}
+	if !cb.Amount.IsZero() || cb.MpesaReceiptNumber != "" {
+		t.Error("Expected no metadata on a failed callback")
+	}
+}
+
+func TestParseSTKCallbackInvalid(t *testing.T) {
+	if _, err := ParseSTKCallback([]byte(`{"Body":{}}`)); err == nil {
+		t.Error("Expected error for callback without CheckoutRequestID")
+	}
+	if _, err := ParseSTKCallback([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestSTKCallbackHandler(t *testing.T) {
+	var succeeded, failed int
+	handler := NewSTKCallbackHandler(
+		func(ctx context.Context, cb *STKCallback) error {
+			succeeded++
+			return nil
+		},
+		func(ctx context.Context, cb *STKCallback) error {
+			failed++
+			return nil
+		},
+	)
+
+	for _, payload := range []string{stkSuccessPayload, stkCancelledPayload} {
+		req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+
+		var ack CallbackAck
+		if err := json.Unmarshal(w.Body.Bytes(), &ack); err != nil {
+			t.Fatalf("Failed to decode ack: %v", err)
+		}
+		if ack.ResultCode != 0 || ack.ResultDesc != "Accepted" {
+			t.Errorf("Unexpected ack: %+v", ack)
+		}
+	}
+
+	if succeeded != 1 || failed != 1 {
+		t.Errorf("Expected one success and one failure, got %d and %d", succeeded, failed)
+	}
+}
+
+func TestSTKCallbackHandlerBadRequest(t *testing.T) {
+	handler := NewSTKCallbackHandler(nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader("{"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/callback", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+}
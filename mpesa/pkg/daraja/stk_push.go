@@ -1,11 +1,14 @@
 package daraja
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/retry"
 )
 
 type STKPushBody struct {
@@ -47,6 +50,10 @@ type STKPushQueryResponse struct {
 }
 
 func (s *Service) InitiateStkPush(body STKPushBody) (*STKPushResponse, error) {
+	return s.InitiateStkPushWithContext(context.Background(), body)
+}
+
+func (s *Service) InitiateStkPushWithContext(ctx context.Context, body STKPushBody) (*STKPushResponse, error) {
 	timestamp := time.Now().Format("20060102150405")
 	password := base64.StdEncoding.EncodeToString([]byte(body.BusinessShortCode + s.passKey + timestamp))
 
@@ -64,7 +71,7 @@ func (s *Service) InitiateStkPush(body STKPushBody) (*STKPushResponse, error) {
 		TransactionDesc:   body.TransactionDesc,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, stkPushURL, payload)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, stkPushURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make STK push request: %w", err)
 	}
@@ -77,6 +84,10 @@ func (s *Service) InitiateStkPush(body STKPushBody) (*STKPushResponse, error) {
 }
 
 func (s *Service) QueryStkPush(businessShortCode, checkoutRequestID string) (*STKPushQueryResponse, error) {
+	return s.QueryStkPushWithContext(context.Background(), businessShortCode, checkoutRequestID)
+}
+
+func (s *Service) QueryStkPushWithContext(ctx context.Context, businessShortCode, checkoutRequestID string) (*STKPushQueryResponse, error) {
 	timestamp := time.Now().Format("20060102150405")
 	password := base64.StdEncoding.EncodeToString([]byte(businessShortCode + s.passKey + timestamp))
 
@@ -87,7 +98,7 @@ func (s *Service) QueryStkPush(businessShortCode, checkoutRequestID string) (*ST
 		CheckoutRequestID: checkoutRequestID,
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, stkPushQueryURL, payload)
+	respBody, err := s.makeRequest(retry.WithIdempotent(ctx), http.MethodPost, stkPushQueryURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make STK push query request: %w", err)
 	}
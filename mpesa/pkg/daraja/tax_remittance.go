@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,7 @@ const (
 type TaxRemittanceRequestBody struct {
 	Initiator              string `json:"Initiator"`
 	SecurityCredential     string `json:"SecurityCredential"`
+	InitiatorPassword      string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID              string `json:"CommandID"`
 	SenderIdentifierType   string `json:"SenderIdentifierType"`
 	RecieverIdentifierType string `json:"RecieverIdentifierType"`
@@ -34,7 +36,18 @@ type TaxRemittanceResponse struct {
 	ResponseDescription      string `json:"ResponseDescription"`
 }
 
-func (s *Service) RemitTax(body TaxRemittanceRequestBody) (*TaxRemittanceResponse, error) { // Validate required fields
+func (s *Service) RemitTax(body TaxRemittanceRequestBody) (*TaxRemittanceResponse, error) {
+	return s.RemitTaxWithContext(context.Background(), body)
+}
+
+func (s *Service) RemitTaxWithContext(ctx context.Context, body TaxRemittanceRequestBody) (*TaxRemittanceResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	body.SecurityCredential = credential
+
+	// Validate required fields
 	if body.Initiator == "" {
 		return nil, fmt.Errorf("initiator is required")
 	}
@@ -66,7 +79,7 @@ func (s *Service) RemitTax(body TaxRemittanceRequestBody) (*TaxRemittanceRespons
 		return nil, fmt.Errorf("resultURL is required")
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, TaxRemittanceURL, body)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, TaxRemittanceURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make Tax Remittance request: %w", err)
 	}
@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 type TransactionStatusRequestBody struct {
 	Initiator          string `json:"Initiator"`
 	SecurityCredential string `json:"SecurityCredential"`
+	InitiatorPassword  string `json:"-"` // Encrypted into SecurityCredential when that is empty
 	CommandID          string `json:"CommandID"`
 	TransactionID      string `json:"TransactionID"`
 	PartyA             int    `json:"PartyA"`
@@ -27,7 +29,17 @@ type TransactionStatusResponse struct {
 }
 
 func (s *Service) TransactionStatus(body TransactionStatusRequestBody) (*TransactionStatusResponse, error) {
-	respBody, err := s.makeRequest(http.MethodPost, transactionStatusURL, body)
+	return s.TransactionStatusWithContext(context.Background(), body)
+}
+
+func (s *Service) TransactionStatusWithContext(ctx context.Context, body TransactionStatusRequestBody) (*TransactionStatusResponse, error) {
+	credential, err := s.resolveSecurityCredential(body.SecurityCredential, body.InitiatorPassword)
+	if err != nil {
+		return nil, err
+	}
+	body.SecurityCredential = credential
+
+	respBody, err := s.makeRequest(ctx, http.MethodPost, transactionStatusURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make transaction status request: %w", err)
 	}
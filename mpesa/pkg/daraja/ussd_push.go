@@ -1,6 +1,7 @@
 package daraja
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -38,6 +39,10 @@ type UssdPushCallbackResponse struct {
 }
 
 func (s *Service) UssdPush(body UssdPushRequestBody) (*UssdPushResponse, error) {
+	return s.UssdPushWithContext(context.Background(), body)
+}
+
+func (s *Service) UssdPushWithContext(ctx context.Context, body UssdPushRequestBody) (*UssdPushResponse, error) {
 	if body.PrimaryShortCode == "" {
 		return nil, fmt.Errorf("primaryShortCode is required")
 	}
@@ -60,7 +65,7 @@ func (s *Service) UssdPush(body UssdPushRequestBody) (*UssdPushResponse, error)
 		return nil, fmt.Errorf("RequestRefID is required")
 	}
 
-	respBody, err := s.makeRequest(http.MethodPost, UssdPushURL, body)
+	respBody, err := s.makeRequest(ctx, http.MethodPost, UssdPushURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make USSD Push request: %w", err)
 	}
@@ -1,6 +1,8 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
@@ -28,6 +30,10 @@ const (
 )
 
 func (c *Client) GenerateQRCode(req QRCodeRequest) (*QRCodeResponse, error) {
+	return c.GenerateQRCodeWithContext(context.Background(), req)
+}
+
+func (c *Client) GenerateQRCodeWithContext(ctx context.Context, req QRCodeRequest) (*QRCodeResponse, error) {
 	internalReq := daraja.QRCodeRequest{
 		MerchantName: req.MerchantName,
 		RefNo:        req.RefNo,
@@ -37,7 +43,7 @@ func (c *Client) GenerateQRCode(req QRCodeRequest) (*QRCodeResponse, error) {
 		Size:         req.Size,
 	}
 
-	resp, err := c.Service.GenerateQRCode(internalReq)
+	resp, err := c.Service.GenerateQRCodeWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,8 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
@@ -48,6 +50,10 @@ const (
 )
 
 func (c *Client) CreateStandingOrder(req RatibaRequest) (*RatibaResponse, error) {
+	return c.CreateStandingOrderWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateStandingOrderWithContext(ctx context.Context, req RatibaRequest) (*RatibaResponse, error) {
 	internalReq := daraja.RatibaRequest{
 		StandingOrderName:           req.StandingOrderName,
 		StartDate:                   req.StartDate,
@@ -63,7 +69,7 @@ func (c *Client) CreateStandingOrder(req RatibaRequest) (*RatibaResponse, error)
 		Frequency:                   req.Frequency,
 	}
 
-	resp, err := c.Service.CreateStandingOrder(internalReq)
+	resp, err := c.Service.CreateStandingOrderWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
@@ -1,5 +1,7 @@
 package mpesa
 
+import "context"
+
 type STKPushQueryRequest struct {
 	BusinessShortCode string // The organization's shortcode (Paybill or Buygoods)
 	CheckoutRequestID string // The global unique identifier of the processed checkout transaction request
@@ -15,7 +17,11 @@ type STKPushQueryResponse struct {
 }
 
 func (c *Client) QueryStkPushStatus(req STKPushQueryRequest) (*STKPushQueryResponse, error) {
-	resp, err := c.Service.QueryStkPush(req.BusinessShortCode, req.CheckoutRequestID)
+	return c.QueryStkPushStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) QueryStkPushStatusWithContext(ctx context.Context, req STKPushQueryRequest) (*STKPushQueryResponse, error) {
+	resp, err := c.Service.QueryStkPushWithContext(ctx, req.BusinessShortCode, req.CheckoutRequestID)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,15 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
 type TaxRemittanceRequest struct {
 	Initiator          string // The M-Pesa API operator username
 	SecurityCredential string // The encrypted password of the M-Pesa API operator
+	InitiatorPassword  string // Optional: plain password, encrypted when SecurityCredential is empty
 	Amount             string // The transaction amount
 	PartyA             string // Your shortcode (from which money will be deducted)
 	AccountReference   string // The payment registration number (PRN) issued by KRA
@@ -23,9 +26,14 @@ type TaxRemittanceResponse struct {
 }
 
 func (c *Client) RemitTax(req TaxRemittanceRequest) (*TaxRemittanceResponse, error) {
+	return c.RemitTaxWithContext(context.Background(), req)
+}
+
+func (c *Client) RemitTaxWithContext(ctx context.Context, req TaxRemittanceRequest) (*TaxRemittanceResponse, error) {
 	internalReq := daraja.TaxRemittanceRequestBody{
 		Initiator:              req.Initiator,
 		SecurityCredential:     req.SecurityCredential,
+		InitiatorPassword:      req.InitiatorPassword,
 		CommandID:              daraja.CommandIDPayTaxToKRA, // Only PayTaxToKRA is allowed for this API
 		SenderIdentifierType:   "4",                         // Only type 4 is allowed for this API
 		RecieverIdentifierType: "4",                         // Only type 4 is allowed for this API
@@ -38,7 +46,7 @@ func (c *Client) RemitTax(req TaxRemittanceRequest) (*TaxRemittanceResponse, err
 		ResultURL:              req.ResultURL,
 	}
 
-	resp, err := c.Service.RemitTax(internalReq)
+	resp, err := c.Service.RemitTaxWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
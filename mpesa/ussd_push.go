@@ -1,6 +1,8 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
@@ -32,6 +34,10 @@ type UssdPushCallbackResponse struct {
 }
 
 func (c *Client) UssdPush(req UssdPushRequest) (*UssdPushResponse, error) {
+	return c.UssdPushWithContext(context.Background(), req)
+}
+
+func (c *Client) UssdPushWithContext(ctx context.Context, req UssdPushRequest) (*UssdPushResponse, error) {
 	internalReq := daraja.UssdPushRequestBody{
 		PrimaryShortCode:  req.PrimaryShortCode,
 		ReceiverShortCode: req.ReceiverShortCode,
@@ -42,7 +48,7 @@ func (c *Client) UssdPush(req UssdPushRequest) (*UssdPushResponse, error) {
 		RequestRefID:      req.RequestRefID,
 	}
 
-	resp, err := c.Service.UssdPush(internalReq)
+	resp, err := c.Service.UssdPushWithContext(ctx, internalReq)
 	if err != nil {
 		return nil, err
 	}
@@ -1,11 +1,18 @@
 package mpesa
 
 import (
+	"context"
+
 	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
 )
 
 // InitiateStkPush initiates an STK push request using a parameter struct
 func (c *Client) InitiateStkPush(params StkPushParams) (*daraja.STKPushResponse, error) {
+	return c.InitiateStkPushWithContext(context.Background(), params)
+}
+
+// InitiateStkPushWithContext is like InitiateStkPush but takes a context.
+func (c *Client) InitiateStkPushWithContext(ctx context.Context, params StkPushParams) (*daraja.STKPushResponse, error) {
 	body := daraja.STKPushBody{
 		BusinessShortCode: params.BusinessShortCode,
 		TransactionType:   params.TransactionType,
@@ -18,12 +25,17 @@ func (c *Client) InitiateStkPush(params StkPushParams) (*daraja.STKPushResponse,
 		TransactionDesc:   params.TransactionDesc,
 	}
 
-	return c.Service.InitiateStkPush(body)
+	return c.Service.InitiateStkPushWithContext(ctx, body)
 }
 
 // LegacyInitiateStkPush is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyInitiateStkPush(businessShortCode, transactionType, amount, partyA, partyB, phoneNumber, callBackURL, accountReference, transactionDesc string) (*daraja.STKPushResponse, error) {
-	return c.InitiateStkPush(StkPushParams{
+	return c.LegacyInitiateStkPushWithContext(context.Background(), businessShortCode, transactionType, amount, partyA, partyB, phoneNumber, callBackURL, accountReference, transactionDesc)
+}
+
+// LegacyInitiateStkPushWithContext is like LegacyInitiateStkPush but takes a context.
+func (c *Client) LegacyInitiateStkPushWithContext(ctx context.Context, businessShortCode, transactionType, amount, partyA, partyB, phoneNumber, callBackURL, accountReference, transactionDesc string) (*daraja.STKPushResponse, error) {
+	return c.InitiateStkPushWithContext(ctx, StkPushParams{
 		BusinessShortCode: businessShortCode,
 		TransactionType:   transactionType,
 		Amount:            amount,
@@ -37,10 +49,18 @@ func (c *Client) LegacyInitiateStkPush(businessShortCode, transactionType, amoun
 }
 
 func (c *Client) QueryStkPush(businessShortCode, checkoutRequestID string) (*daraja.STKPushQueryResponse, error) {
-	return c.Service.QueryStkPush(businessShortCode, checkoutRequestID)
+	return c.QueryStkPushWithContext(context.Background(), businessShortCode, checkoutRequestID)
+}
+
+func (c *Client) QueryStkPushWithContext(ctx context.Context, businessShortCode, checkoutRequestID string) (*daraja.STKPushQueryResponse, error) {
+	return c.Service.QueryStkPushWithContext(ctx, businessShortCode, checkoutRequestID)
 }
 
 func (c *Client) C2BRegisterURL(shortCode, responseType, confirmationURL, validationURL string) (*daraja.RegisterC2BURLResponse, error) {
+	return c.C2BRegisterURLWithContext(context.Background(), shortCode, responseType, confirmationURL, validationURL)
+}
+
+func (c *Client) C2BRegisterURLWithContext(ctx context.Context, shortCode, responseType, confirmationURL, validationURL string) (*daraja.RegisterC2BURLResponse, error) {
 	body := daraja.RegisterC2BURLBody{
 		ShortCode:       shortCode,
 		ResponseType:    responseType,
@@ -48,10 +68,14 @@ func (c *Client) C2BRegisterURL(shortCode, responseType, confirmationURL, valida
 		ValidationURL:   validationURL,
 	}
 
-	return c.Service.C2BRegisterURL(body)
+	return c.Service.C2BRegisterURLWithContext(ctx, body)
 }
 
 func (c *Client) C2BSimulate(shortCode int, commandID string, amount int, msisdn int, billRefNumber string) (*daraja.C2BSimulateResponse, error) {
+	return c.C2BSimulateWithContext(context.Background(), shortCode, commandID, amount, msisdn, billRefNumber)
+}
+
+func (c *Client) C2BSimulateWithContext(ctx context.Context, shortCode int, commandID string, amount int, msisdn int, billRefNumber string) (*daraja.C2BSimulateResponse, error) {
 	body := daraja.C2BSimulateRequestBody{
 		ShortCode:     shortCode,
 		CommandID:     commandID,
@@ -60,30 +84,42 @@ func (c *Client) C2BSimulate(shortCode int, commandID string, amount int, msisdn
 		BillRefNumber: billRefNumber,
 	}
 
-	return c.Service.C2BSimulate(body)
+	return c.Service.C2BSimulateWithContext(ctx, body)
 }
 
 // B2CPayment performs a business to customer payment using a parameter struct
 func (c *Client) B2CPayment(params B2CPaymentParams) (*daraja.B2CResponse, error) {
+	return c.B2CPaymentWithContext(context.Background(), params)
+}
+
+// B2CPaymentWithContext is like B2CPayment but takes a context.
+func (c *Client) B2CPaymentWithContext(ctx context.Context, params B2CPaymentParams) (*daraja.B2CResponse, error) {
 	body := daraja.B2CRequestBody{
-		InitiatorName:      params.InitiatorName,
-		SecurityCredential: params.SecurityCredential,
-		CommandID:          params.CommandID,
-		Amount:             params.Amount,
-		PartyA:             params.PartyA,
-		PartyB:             params.PartyB,
-		Remarks:            params.Remarks,
-		QueueTimeOutURL:    params.QueueTimeOutURL,
-		ResultURL:          params.ResultURL,
-		Occassion:          params.Occasion,
+		OriginatorConversationID: params.OriginatorConversationID,
+		InitiatorName:            params.InitiatorName,
+		SecurityCredential:       params.SecurityCredential,
+		InitiatorPassword:        params.InitiatorPassword,
+		CommandID:                params.CommandID,
+		Amount:                   params.Amount,
+		PartyA:                   params.PartyA,
+		PartyB:                   params.PartyB,
+		Remarks:                  params.Remarks,
+		QueueTimeOutURL:          params.QueueTimeOutURL,
+		ResultURL:                params.ResultURL,
+		Occassion:                params.Occasion,
 	}
 
-	return c.Service.B2CPayment(body)
+	return c.Service.B2CPaymentWithContext(ctx, body)
 }
 
 // LegacyB2CPayment is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyB2CPayment(initiatorName, securityCredential, commandID string, amount, partyA, partyB int, remarks, queueTimeOutURL, resultURL, occasion string) (*daraja.B2CResponse, error) {
-	return c.B2CPayment(B2CPaymentParams{
+	return c.LegacyB2CPaymentWithContext(context.Background(), initiatorName, securityCredential, commandID, amount, partyA, partyB, remarks, queueTimeOutURL, resultURL, occasion)
+}
+
+// LegacyB2CPaymentWithContext is like LegacyB2CPayment but takes a context.
+func (c *Client) LegacyB2CPaymentWithContext(ctx context.Context, initiatorName, securityCredential, commandID string, amount, partyA, partyB int, remarks, queueTimeOutURL, resultURL, occasion string) (*daraja.B2CResponse, error) {
+	return c.B2CPaymentWithContext(ctx, B2CPaymentParams{
 		InitiatorName:      initiatorName,
 		SecurityCredential: securityCredential,
 		CommandID:          commandID,
@@ -99,9 +135,15 @@ func (c *Client) LegacyB2CPayment(initiatorName, securityCredential, commandID s
 
 // B2BPayment performs a business to business payment using a parameter struct
 func (c *Client) B2BPayment(params B2BPaymentParams) (*daraja.BusinessToBusinessResponse, error) {
+	return c.B2BPaymentWithContext(context.Background(), params)
+}
+
+// B2BPaymentWithContext is like B2BPayment but takes a context.
+func (c *Client) B2BPaymentWithContext(ctx context.Context, params B2BPaymentParams) (*daraja.BusinessToBusinessResponse, error) {
 	body := daraja.BusinessToBusinessRequestBody{
 		Initiator:             params.Initiator,
 		SecurityCredential:    params.SecurityCredential,
+		InitiatorPassword:     params.InitiatorPassword,
 		CommandID:             params.CommandID,
 		SenderIdentifierType:  params.SenderIdentifierType,
 		RecieverIdentifierType: params.ReceiverIdentifierType,
@@ -115,12 +157,17 @@ func (c *Client) B2BPayment(params B2BPaymentParams) (*daraja.BusinessToBusiness
 		ResultURL:             params.ResultURL,
 	}
 
-	return c.Service.BusinessToBusinessPayment(body)
+	return c.Service.BusinessToBusinessPaymentWithContext(ctx, body)
 }
 
 // LegacyB2BPayment is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyB2BPayment(initiator, securityCredential, commandID, senderIdentifierType, receiverIdentifierType, amount, partyA, partyB, accountReference, requester, remarks, queueTimeOutURL, resultURL string) (*daraja.BusinessToBusinessResponse, error) {
-	return c.B2BPayment(B2BPaymentParams{
+	return c.LegacyB2BPaymentWithContext(context.Background(), initiator, securityCredential, commandID, senderIdentifierType, receiverIdentifierType, amount, partyA, partyB, accountReference, requester, remarks, queueTimeOutURL, resultURL)
+}
+
+// LegacyB2BPaymentWithContext is like LegacyB2BPayment but takes a context.
+func (c *Client) LegacyB2BPaymentWithContext(ctx context.Context, initiator, securityCredential, commandID, senderIdentifierType, receiverIdentifierType, amount, partyA, partyB, accountReference, requester, remarks, queueTimeOutURL, resultURL string) (*daraja.BusinessToBusinessResponse, error) {
+	return c.B2BPaymentWithContext(ctx, B2BPaymentParams{
 		Initiator:              initiator,
 		SecurityCredential:     securityCredential,
 		CommandID:              commandID,
@@ -139,9 +186,15 @@ func (c *Client) LegacyB2BPayment(initiator, securityCredential, commandID, send
 
 // TransactionStatus checks the status of a transaction using a parameter struct
 func (c *Client) TransactionStatus(params TransactionStatusParams) (*daraja.TransactionStatusResponse, error) {
+	return c.TransactionStatusWithContext(context.Background(), params)
+}
+
+// TransactionStatusWithContext is like TransactionStatus but takes a context.
+func (c *Client) TransactionStatusWithContext(ctx context.Context, params TransactionStatusParams) (*daraja.TransactionStatusResponse, error) {
 	body := daraja.TransactionStatusRequestBody{
 		Initiator:          params.Initiator,
 		SecurityCredential: params.SecurityCredential,
+		InitiatorPassword:  params.InitiatorPassword,
 		CommandID:          params.CommandID,
 		TransactionID:      params.TransactionID,
 		PartyA:             params.PartyA,
@@ -152,12 +205,17 @@ func (c *Client) TransactionStatus(params TransactionStatusParams) (*daraja.Tran
 		Occassion:          params.Occasion,
 	}
 
-	return c.Service.TransactionStatus(body)
+	return c.Service.TransactionStatusWithContext(ctx, body)
 }
 
 // LegacyTransactionStatus is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyTransactionStatus(initiator, securityCredential, commandID, transactionID string, partyA, identifierType int, resultURL, queueTimeOutURL, remarks, occasion string) (*daraja.TransactionStatusResponse, error) {
-	return c.TransactionStatus(TransactionStatusParams{
+	return c.LegacyTransactionStatusWithContext(context.Background(), initiator, securityCredential, commandID, transactionID, partyA, identifierType, resultURL, queueTimeOutURL, remarks, occasion)
+}
+
+// LegacyTransactionStatusWithContext is like LegacyTransactionStatus but takes a context.
+func (c *Client) LegacyTransactionStatusWithContext(ctx context.Context, initiator, securityCredential, commandID, transactionID string, partyA, identifierType int, resultURL, queueTimeOutURL, remarks, occasion string) (*daraja.TransactionStatusResponse, error) {
+	return c.TransactionStatusWithContext(ctx, TransactionStatusParams{
 		Initiator:          initiator,
 		SecurityCredential: securityCredential,
 		CommandID:          commandID,
@@ -173,9 +231,15 @@ func (c *Client) LegacyTransactionStatus(initiator, securityCredential, commandI
 
 // AccountBalance checks the account balance using a parameter struct
 func (c *Client) AccountBalance(params AccountBalanceParams) (*daraja.AccountBalanceResponse, error) {
+	return c.AccountBalanceWithContext(context.Background(), params)
+}
+
+// AccountBalanceWithContext is like AccountBalance but takes a context.
+func (c *Client) AccountBalanceWithContext(ctx context.Context, params AccountBalanceParams) (*daraja.AccountBalanceResponse, error) {
 	body := daraja.AccountBalanceRequestBody{
 		Initiator:          params.Initiator,
 		SecurityCredential: params.SecurityCredential,
+		InitiatorPassword:  params.InitiatorPassword,
 		CommandID:          params.CommandID,
 		PartyA:             params.PartyA,
 		IdentifierType:     params.IdentifierType,
@@ -184,12 +248,17 @@ func (c *Client) AccountBalance(params AccountBalanceParams) (*daraja.AccountBal
 		ResultURL:          params.ResultURL,
 	}
 
-	return c.Service.AccountBalance(body)
+	return c.Service.AccountBalanceWithContext(ctx, body)
 }
 
 // LegacyAccountBalance is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyAccountBalance(initiator, securityCredential, commandID string, partyA, identifierType int, remarks, queueTimeOutURL, resultURL string) (*daraja.AccountBalanceResponse, error) {
-	return c.AccountBalance(AccountBalanceParams{
+	return c.LegacyAccountBalanceWithContext(context.Background(), initiator, securityCredential, commandID, partyA, identifierType, remarks, queueTimeOutURL, resultURL)
+}
+
+// LegacyAccountBalanceWithContext is like LegacyAccountBalance but takes a context.
+func (c *Client) LegacyAccountBalanceWithContext(ctx context.Context, initiator, securityCredential, commandID string, partyA, identifierType int, remarks, queueTimeOutURL, resultURL string) (*daraja.AccountBalanceResponse, error) {
+	return c.AccountBalanceWithContext(ctx, AccountBalanceParams{
 		Initiator:          initiator,
 		SecurityCredential: securityCredential,
 		CommandID:          commandID,
@@ -203,9 +272,15 @@ func (c *Client) LegacyAccountBalance(initiator, securityCredential, commandID s
 
 // Reversal reverses a transaction using a parameter struct
 func (c *Client) Reversal(params ReversalParams) (*daraja.ReversalResponse, error) {
+	return c.ReversalWithContext(context.Background(), params)
+}
+
+// ReversalWithContext is like Reversal but takes a context.
+func (c *Client) ReversalWithContext(ctx context.Context, params ReversalParams) (*daraja.ReversalResponse, error) {
 	body := daraja.ReversalRequestBody{
 		Initiator:              params.Initiator,
 		SecurityCredential:     params.SecurityCredential,
+		InitiatorPassword:      params.InitiatorPassword,
 		CommandID:              params.CommandID,
 		TransactionID:          params.TransactionID,
 		Amount:                 params.Amount,
@@ -217,12 +292,17 @@ func (c *Client) Reversal(params ReversalParams) (*daraja.ReversalResponse, erro
 		Occasion:               params.Occasion,
 	}
 
-	return c.Service.Reversal(body)
+	return c.Service.ReversalWithContext(ctx, body)
 }
 
 // LegacyReversal is the original method with multiple parameters (kept for backward compatibility)
 func (c *Client) LegacyReversal(initiator, securityCredential, commandID, transactionID string, amount int, receiverParty, receiverIdentifierType int, resultURL, queueTimeOutURL, remarks, occasion string) (*daraja.ReversalResponse, error) {
-	return c.Reversal(ReversalParams{
+	return c.LegacyReversalWithContext(context.Background(), initiator, securityCredential, commandID, transactionID, amount, receiverParty, receiverIdentifierType, resultURL, queueTimeOutURL, remarks, occasion)
+}
+
+// LegacyReversalWithContext is like LegacyReversal but takes a context.
+func (c *Client) LegacyReversalWithContext(ctx context.Context, initiator, securityCredential, commandID, transactionID string, amount int, receiverParty, receiverIdentifierType int, resultURL, queueTimeOutURL, remarks, occasion string) (*daraja.ReversalResponse, error) {
+	return c.ReversalWithContext(ctx, ReversalParams{
 		Initiator:              initiator,
 		SecurityCredential:     securityCredential,
 		CommandID:              commandID,
@@ -1,25 +1,28 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type AccountDetails struct {
-	AccountNumber string  `json:"accountNumber"`
-	AccountName   string  `json:"accountName"`
-	Balance       float64 `json:"balance"`
-	Currency      string  `json:"currency"`
-	Status        string  `json:"status"`
+	AccountNumber string         `json:"accountNumber"`
+	AccountName   string         `json:"accountName"`
+	Balance       money.Number   `json:"balance"`
+	Currency      money.Currency `json:"currency"`
+	Status        string         `json:"status"`
 }
 
 type MiniStatement struct {
-	Date        string  `json:"date"`
-	Description string  `json:"description"`
-	Amount      float64 `json:"amount"`
-	Type        string  `json:"type"`
-	Balance     float64 `json:"balance"`
+	Date        string       `json:"date"`
+	Description string       `json:"description"`
+	Amount      money.Number `json:"amount"`
+	Type        string       `json:"type"`
+	Balance     money.Number `json:"balance"`
 }
 
 type AccountStatement struct {
@@ -31,30 +34,28 @@ type AccountStatement struct {
 }
 
 type Transaction struct {
-	Date        string  `json:"date"`
-	Description string  `json:"description"`
-	Reference   string  `json:"reference"`
-	Amount      float64 `json:"amount"`
-	Type        string  `json:"type"`
-	Balance     float64 `json:"balance"`
+	Date        string       `json:"date"`
+	Description string       `json:"description"`
+	Reference   string       `json:"reference"`
+	Amount      money.Number `json:"amount"`
+	Type        string       `json:"type"`
+	Balance     money.Number `json:"balance"`
 }
 
 func (c *Client) GetAccountDetails(countryCode, accountNo string) (*AccountDetails, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.GetAccountDetailsWithContext(context.Background(), countryCode, accountNo)
+}
 
-	url := fmt.Sprintf("%s/accounts/%s/%s", BaseURL, countryCode, accountNo)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetAccountDetailsWithContext(ctx context.Context, countryCode, accountNo string) (*AccountDetails, error) {
+	url := fmt.Sprintf("%s/accounts/%s/%s", c.baseURL, countryCode, accountNo)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(req)
-	
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -67,21 +68,19 @@ func (c *Client) GetAccountDetails(countryCode, accountNo string) (*AccountDetai
 }
 
 func (c *Client) GetMiniStatement(countryCode, accountNo string) ([]MiniStatement, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.GetMiniStatementWithContext(context.Background(), countryCode, accountNo)
+}
 
-	url := fmt.Sprintf("%s/accounts/%s/%s/mini-statement", BaseURL, countryCode, accountNo)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetMiniStatementWithContext(ctx context.Context, countryCode, accountNo string) ([]MiniStatement, error) {
+	url := fmt.Sprintf("%s/accounts/%s/%s/mini-statement", c.baseURL, countryCode, accountNo)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(req)
-
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -94,21 +93,19 @@ func (c *Client) GetMiniStatement(countryCode, accountNo string) ([]MiniStatemen
 }
 
 func (c *Client) GetAccountStatement(countryCode, accountNo, fromDate, toDate string) (*AccountStatement, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.GetAccountStatementWithContext(context.Background(), countryCode, accountNo, fromDate, toDate)
+}
 
-	url := fmt.Sprintf("%s/accounts/%s/%s/statement?from=%s&to=%s", BaseURL, countryCode, accountNo, fromDate, toDate)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetAccountStatementWithContext(ctx context.Context, countryCode, accountNo, fromDate, toDate string) (*AccountStatement, error) {
+	url := fmt.Sprintf("%s/accounts/%s/%s/statement?from=%s&to=%s", c.baseURL, countryCode, accountNo, fromDate, toDate)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(req)
-
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
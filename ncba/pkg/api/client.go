@@ -2,10 +2,17 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 const (
@@ -16,30 +23,97 @@ type Client struct {
 	apiKey    string
 	username  string
 	password  string
+	baseURL   string
 	client    *http.Client
-	apiToken  string
-	tokenExp  time.Time
+	tokens    *auth.TokenManager
+	retry     retry.Policy
+	breaker   *breaker.Breaker
 }
 
 func NewClient(apiKey, username, password string) *Client {
-	return &Client{
+	c := &Client{
 		apiKey:    apiKey,
 		username:  username,
 		password:  password,
+		baseURL:   BaseURL,
 		client:    &http.Client{Timeout: 30 * time.Second},
 	}
+	c.tokens = auth.NewTokenManager(nil, auth.Key("ncba", c.baseURL, apiKey+":"+username), c.fetchToken)
+	c.retry = retry.DefaultPolicy()
+	c.breaker = breaker.New(provider, breaker.DefaultSettings())
+	return c
+}
+
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.client = httpClient
+}
+
+// SetBaseURL overrides BaseURL, e.g. to send requests through a proxy or to
+// a mock server. Tokens fetched from baseURL are stored apart from
+// BaseURL's.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+	c.tokens.SetKey(auth.Key("ncba", baseURL, c.apiKey+":"+c.username))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same API key reuse one token instead of each requesting their own.
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.tokens.SetStore(store)
+}
+
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.client.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.client = transport.Wrap(c.client, provider, middleware...)
 }
 
 type AuthResponse struct {
-	Token     string `json:"token"`
-	ExpiresIn int    `json:"expiresIn"`
+	Token     string         `json:"token"`
+	ExpiresIn auth.ExpiresIn `json:"expiresIn"`
 }
 
 func (c *Client) Authenticate() error {
-	if c.isTokenValid() {
-		return nil
-	}
+	return c.AuthenticateWithContext(context.Background())
+}
+
+// AuthenticateWithContext fetches an access token if there is no valid one.
+// Requests authenticate on their own, so calling it is optional.
+func (c *Client) AuthenticateWithContext(ctx context.Context) error {
+	_, err := c.tokens.Token(ctx)
+	return err
+}
 
+func (c *Client) fetchToken(ctx context.Context) (auth.Token, error) {
 	payload := map[string]string{
 		"apiKey":   c.apiKey,
 		"username": c.username,
@@ -48,36 +122,63 @@ func (c *Client) Authenticate() error {
 
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("error marshaling auth payload: %v", err)
+		return auth.Token{}, fmt.Errorf("error marshaling auth payload: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", BaseURL+"/auth", bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/auth", bytes.NewBuffer(data))
 	if err != nil {
-		return fmt.Errorf("error creating auth request: %v", err)
+		return auth.Token{}, fmt.Errorf("error creating auth request: %v", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return fmt.Errorf("error making auth request: %v", err)
+		return auth.Token{}, fmt.Errorf("error making auth request: %v", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
+	}
+
 	var authResp AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return fmt.Errorf("error decoding auth response: %v", err)
+		return auth.Token{}, fmt.Errorf("error decoding auth response: %v", err)
 	}
 
-	c.apiToken = authResp.Token
-	c.tokenExp = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
-	return nil
+	return auth.Token{
+		AccessToken: authResp.Token,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
-func (c *Client) isTokenValid() bool {
-	return c.apiToken != "" && time.Now().Before(c.tokenExp)
-}
+// do sends req with the access token, retrying once with a new token if the
+// current one is rejected and retrying transient failures per the retry
+// policy. Requests built from a bytes.Buffer can be replayed. A response
+// other than 2xx is returned as a *errors.ProviderError.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.tokens.Do(req.Context(), func(token string) (*http.Response, error) {
+		attempt := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+		attempt.Header.Set("Authorization", "Bearer "+token)
+		return c.retry.Do(attempt, c.roundTrip)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (c *Client) setAuthHeader(req *http.Request) {
-	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newProviderError(resp, body)
+	}
+	return resp, nil
 }
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "ncba"
+
+// ErrorResponse is the body NCBA sends with a rejected request. The code may
+// be a number or a string.
+type ErrorResponse struct {
+	Code    json.RawMessage `json:"code"`
+	Message string          `json:"message"`
+	Error   string          `json:"error"`
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return railerrors.FromResponse(provider, resp, "", string(body))
+	}
+
+	message := errResp.Message
+	if message == "" {
+		message = errResp.Error
+	}
+	code := strings.Trim(string(errResp.Code), `"`)
+	if code == "null" {
+		code = ""
+	}
+	return railerrors.FromResponse(provider, resp, code, message)
+}
@@ -1,41 +1,42 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type TransactionStatus struct {
-	TransactionID   string  `json:"transactionId"`
-	Status         string  `json:"status"`
-	Amount         float64 `json:"amount"`
-	Currency       string  `json:"currency"`
-	Reference      string  `json:"reference"`
-	Timestamp      string  `json:"timestamp"`
-	Type           string  `json:"type"`
-	SourceAccount  string  `json:"sourceAccount"`
-	Description    string  `json:"description"`
-	ResponseCode   string  `json:"responseCode"`
-	ResponseDesc   string  `json:"responseDesc"`
+	TransactionID string         `json:"transactionId"`
+	Status        string         `json:"status"`
+	Amount        money.Number   `json:"amount"`
+	Currency      money.Currency `json:"currency"`
+	Reference     string         `json:"reference"`
+	Timestamp     string         `json:"timestamp"`
+	Type          string         `json:"type"`
+	SourceAccount string         `json:"sourceAccount"`
+	Description   string         `json:"description"`
+	ResponseCode  string         `json:"responseCode"`
+	ResponseDesc  string         `json:"responseDesc"`
 }
 
 func (c *Client) CheckTransactionStatus(transactionID string) (*TransactionStatus, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.CheckTransactionStatusWithContext(context.Background(), transactionID)
+}
 
-	url := fmt.Sprintf("%s/transactions/%s/status", BaseURL, transactionID)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) CheckTransactionStatusWithContext(ctx context.Context, transactionID string) (*TransactionStatus, error) {
+	url := fmt.Sprintf("%s/transactions/%s/status", c.baseURL, transactionID)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(req)
-
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -2,18 +2,21 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type TransferRequest struct {
-	SourceAccount      string  `json:"sourceAccount"`
-	DestinationAccount string  `json:"destinationAccount"`
-	Amount            float64 `json:"amount"`
-	Currency          string  `json:"currency"`
-	Reference         string  `json:"reference"`
-	Narration         string  `json:"narration"`
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	Narration          string         `json:"narration"`
 }
 
 type InternalTransferRequest struct {
@@ -23,7 +26,7 @@ type InternalTransferRequest struct {
 
 type ExternalTransferRequest struct {
 	TransferRequest
-	BankCode         string `json:"bankCode"`
+	BankCode         string `json:"bankCode"` // Bank code, see banks.Find in rails/banks
 	BranchCode       string `json:"branchCode"`
 	DestinationName  string `json:"destinationName"`
 }
@@ -35,7 +38,7 @@ type RTGSTransferRequest struct {
 
 type PesaLinkTransferRequest struct {
 	TransferRequest
-	DestinationBank string `json:"destinationBank"`
+	DestinationBank string `json:"destinationBank"` // Bank code, see banks.Find in rails/banks
 	PhoneNumber     string `json:"phoneNumber,omitempty"`
 }
 
@@ -46,26 +49,25 @@ type TransferResponse struct {
 }
 
 func (c *Client) SendInternalTransfer(req InternalTransferRequest) (*TransferResponse, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.SendInternalTransferWithContext(context.Background(), req)
+}
 
+func (c *Client) SendInternalTransferWithContext(ctx context.Context, req InternalTransferRequest) (*TransferResponse, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling request: %v", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", BaseURL+"/transfers/internal", bytes.NewBuffer(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/transfers/internal", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -78,26 +80,25 @@ func (c *Client) SendInternalTransfer(req InternalTransferRequest) (*TransferRes
 }
 
 func (c *Client) SendExternalTransfer(req ExternalTransferRequest) (*TransferResponse, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.SendExternalTransferWithContext(context.Background(), req)
+}
 
+func (c *Client) SendExternalTransferWithContext(ctx context.Context, req ExternalTransferRequest) (*TransferResponse, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling request: %v", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", BaseURL+"/transfers/external", bytes.NewBuffer(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/transfers/external", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -110,26 +111,25 @@ func (c *Client) SendExternalTransfer(req ExternalTransferRequest) (*TransferRes
 }
 
 func (c *Client) SendRTGSTransfer(req RTGSTransferRequest) (*TransferResponse, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.SendRTGSTransferWithContext(context.Background(), req)
+}
 
+func (c *Client) SendRTGSTransferWithContext(ctx context.Context, req RTGSTransferRequest) (*TransferResponse, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling request: %v", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", BaseURL+"/transfers/rtgs", bytes.NewBuffer(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/transfers/rtgs", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -142,26 +142,25 @@ func (c *Client) SendRTGSTransfer(req RTGSTransferRequest) (*TransferResponse, e
 }
 
 func (c *Client) SendPesaLinkTransfer(req PesaLinkTransferRequest) (*TransferResponse, error) {
-	if err := c.Authenticate(); err != nil {
-		return nil, err
-	}
+	return c.SendPesaLinkTransferWithContext(context.Background(), req)
+}
 
+func (c *Client) SendPesaLinkTransferWithContext(ctx context.Context, req PesaLinkTransferRequest) (*TransferResponse, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling request: %v", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", BaseURL+"/transfers/pesalink", bytes.NewBuffer(data))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/transfers/pesalink", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	c.setAuthHeader(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(httpReq)
+	resp, err := c.do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
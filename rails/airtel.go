@@ -0,0 +1,176 @@
+package rails
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/airtel"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+// Airtel Money transaction status codes.
+var airtelStatuses = map[string]Status{
+	"TS":  StatusSucceeded, // Transaction Success
+	"TF":  StatusFailed,    // Transaction Failed
+	"TA":  StatusPending,   // Transaction Ambiguous, resolved by a later status query
+	"TIP": StatusPending,   // Transaction in Progress
+	"TE":  StatusExpired,   // Transaction Expired
+}
+
+type AirtelConfig struct {
+	PIN string // Encrypted disbursement PIN
+}
+
+// AirtelAdapter collects through USSD Push and disburses through the
+// disbursement API. The merchant reference is used as the Airtel transaction
+// ID, which is what status queries take.
+type AirtelAdapter struct {
+	client *airtel.Client
+	config AirtelConfig
+}
+
+func NewAirtelAdapter(client *airtel.Client, config AirtelConfig) *AirtelAdapter {
+	return &AirtelAdapter{client: client, config: config}
+}
+
+func (a *AirtelAdapter) Provider() Provider {
+	return ProviderAirtel
+}
+
+func (a *AirtelAdapter) currency() string {
+	return a.client.Currency()
+}
+
+func (a *AirtelAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "UssdPush")
+	resp, err := a.client.UssdPushWithContext(ctx, req.Reference, a.msisdn(req.Phone), req.Money(a.client.Currency()), req.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	code := resp.Data.Transaction.Status
+	return &PaymentResult{
+		Provider:       ProviderAirtel,
+		Kind:           KindCollection,
+		TransactionID:  firstNonEmpty(resp.Data.Transaction.ID, req.Reference),
+		Reference:      req.Reference,
+		Status:         airtelAcceptedStatus(resp.Status.Success, code),
+		ProviderStatus: firstNonEmpty(code, resp.Status.ResultCode),
+		Message:        resp.Status.Message,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *AirtelAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "Disburse")
+	resp, err := a.client.DisburseWithContext(ctx, req.Reference, a.msisdn(req.Phone), req.Money(a.client.Currency()), req.Reference, a.config.PIN)
+	if err != nil {
+		return nil, err
+	}
+
+	code := resp.Data.Transaction.Status
+	return &PaymentResult{
+		Provider:       ProviderAirtel,
+		Kind:           KindDisbursement,
+		TransactionID:  firstNonEmpty(resp.Data.Transaction.ID, req.Reference),
+		Reference:      req.Reference,
+		Status:         airtelAcceptedStatus(resp.Status.Success, code),
+		ProviderStatus: firstNonEmpty(code, resp.Status.ResultCode),
+		Message:        resp.Status.Message,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *AirtelAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	result := &PaymentResult{
+		Provider:      ProviderAirtel,
+		Kind:          req.Kind,
+		TransactionID: req.TransactionID,
+	}
+
+	switch req.Kind {
+	case KindDisbursement:
+		ctx = telemetry.Name(ctx, "GetDisbursementStatus")
+		resp, err := a.client.GetDisbursementStatusWithContext(ctx, req.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		result.ProviderStatus = resp.Data.Transaction.Status
+		result.Message = firstNonEmpty(resp.Data.Transaction.AirtelMoney.Message, resp.Status.Message)
+		result.Raw = resp
+	case KindCollection, "":
+		ctx = telemetry.Name(ctx, "GetTransactionStatus")
+		resp, err := a.client.GetTransactionStatusWithContext(ctx, req.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		result.Kind = KindCollection
+		result.ProviderStatus = resp.Data.Transaction.Status
+		result.Message = firstNonEmpty(resp.Data.Transaction.AirtelMoney.Message, resp.Status.Message)
+		result.Raw = resp
+	default:
+		return nil, ErrNotSupported
+	}
+
+	result.Status = AirtelStatus(result.ProviderStatus)
+	return result, nil
+}
+
+// Refund refunds a collection. TransactionID must be the Airtel Money ID
+// reported in the collection callback or status response.
+func (a *AirtelAdapter) Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "RefundTransaction")
+	resp, err := a.client.RefundTransactionWithContext(ctx, req.TransactionID, req.Money(a.client.Currency()))
+	if err != nil {
+		return nil, err
+	}
+
+	code := resp.Data.Transaction.Status
+	status := airtelAcceptedStatus(resp.Status.Success, code)
+	if status == StatusSucceeded {
+		status = StatusReversed
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderAirtel,
+		Kind:           KindRefund,
+		TransactionID:  firstNonEmpty(resp.Data.Transaction.ID, req.TransactionID),
+		Reference:      req.Reference,
+		Status:         status,
+		ProviderStatus: firstNonEmpty(code, resp.Status.ResultCode),
+		Message:        resp.Status.Message,
+		Raw:            resp,
+	}, nil
+}
+
+// AirtelStatus maps an Airtel Money transaction status code onto a Status.
+func AirtelStatus(code string) Status {
+	if status, ok := airtelStatuses[strings.ToUpper(strings.TrimSpace(code))]; ok {
+		return status
+	}
+	return statusFromText(code)
+}
+
+func airtelAcceptedStatus(success bool, code string) Status {
+	if !success {
+		return StatusFailed
+	}
+	if status := AirtelStatus(code); status != StatusUnknown {
+		return status
+	}
+	return StatusPending
+}
+
+// msisdn strips the country code, which Airtel expects in the X-Country
+// header rather than the MSISDN.
+func (a *AirtelAdapter) msisdn(phone string) string {
+	if n, err := msisdn.Parse(phone, msisdn.Country(a.client.Country())); err == nil {
+		return n.National()
+	}
+	phone = strings.TrimPrefix(phone, "+")
+	if len(phone) == 12 {
+		return phone[3:]
+	}
+	return phone
+}
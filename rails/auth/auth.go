@@ -0,0 +1,46 @@
+// Package auth caches provider access tokens in a TokenStore that can be
+// shared between clients and, with a store such as FileTokenStore or a
+// Redis-backed implementation, between processes. A TokenManager wraps a
+// store with single-flight refresh so concurrent callers trigger at most one
+// request to the provider's auth endpoint.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Token is an access token and the time it stops being usable. IssuedAt is
+// set by TokenManager so the skew can be scaled down for short-lived tokens.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	IssuedAt    time.Time `json:"issued_at,omitempty"`
+}
+
+// Valid reports whether the token is set and has not expired.
+func (t Token) Valid() bool {
+	return t.AccessToken != "" && time.Now().Before(t.ExpiresAt)
+}
+
+// TokenStore holds tokens by key. Get must not return expired tokens.
+type TokenStore interface {
+	Get(ctx context.Context, key string) (Token, bool, error)
+	Set(ctx context.Context, key string, token Token) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Locker is implemented by stores shared between processes. TokenManager
+// holds the lock while it refreshes so only one process calls the provider.
+type Locker interface {
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// Key builds a store key for a provider credential. The client ID is hashed
+// so keys can be logged without leaking it.
+func Key(provider, environment, clientID string) string {
+	sum := sha256.Sum256([]byte(clientID))
+	return provider + ":" + environment + ":" + hex.EncodeToString(sum[:8])
+}
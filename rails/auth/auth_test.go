@@ -0,0 +1,555 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryTokenStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+
+	if _, found, _ := store.Get(ctx, "k"); found {
+		t.Error("Expected empty store")
+	}
+
+	store.Set(ctx, "k", Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)})
+	token, found, err := store.Get(ctx, "k")
+	if err != nil || !found {
+		t.Fatalf("Expected token, got found=%v err=%v", found, err)
+	}
+	if token.AccessToken != "abc" {
+		t.Errorf("Expected token 'abc', got '%s'", token.AccessToken)
+	}
+
+	store.Set(ctx, "k", Token{AccessToken: "old", ExpiresAt: time.Now().Add(-time.Second)})
+	if _, found, _ := store.Get(ctx, "k"); found {
+		t.Error("Expected expired token to be dropped")
+	}
+
+	store.Set(ctx, "k", Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)})
+	store.Delete(ctx, "k")
+	if _, found, _ := store.Get(ctx, "k"); found {
+		t.Error("Expected deleted token to be gone")
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tokens", "store.json")
+
+	writer, err := NewFileTokenStore(path)
+	if err != nil {
+		t.Fatalf("NewFileTokenStore failed: %v", err)
+	}
+	if err := writer.Set(ctx, "k", Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := writer.Set(ctx, "stale", Token{AccessToken: "old", ExpiresAt: time.Now().Add(-time.Second)}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	// A second store on the same file sees the first one's writes.
+	reader, _ := NewFileTokenStore(path)
+	token, found, err := reader.Get(ctx, "k")
+	if err != nil || !found {
+		t.Fatalf("Expected token, got found=%v err=%v", found, err)
+	}
+	if token.AccessToken != "abc" {
+		t.Errorf("Expected token 'abc', got '%s'", token.AccessToken)
+	}
+	if _, found, _ := reader.Get(ctx, "stale"); found {
+		t.Error("Expected expired token to be dropped")
+	}
+
+	if err := reader.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, found, _ := writer.Get(ctx, "k"); found {
+		t.Error("Expected deleted token to be gone")
+	}
+}
+
+func TestFileTokenStoreLock(t *testing.T) {
+	store, _ := NewFileTokenStore(filepath.Join(t.TempDir(), "store.json"))
+
+	unlock, err := store.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected lock to be held, got %v", err)
+	}
+
+	unlock()
+	unlock, err = store.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("Lock after unlock failed: %v", err)
+	}
+	unlock()
+}
+
+func TestTokenManagerSingleFlight(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	manager := NewTokenManager(NewMemoryTokenStore(), "k", func(ctx context.Context) (Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := manager.Token(context.Background())
+			if err == nil && token.AccessToken != "abc" {
+				err = errors.New("unexpected token " + token.AccessToken)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Token failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetches)
+	}
+
+	if _, err := manager.Token(context.Background()); err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("Expected stored token to be reused, got %d fetches", fetches)
+	}
+
+	manager.Invalidate(context.Background(), "abc")
+	if _, err := manager.Token(context.Background()); err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected a fetch after Invalidate, got %d fetches", fetches)
+	}
+}
+
+func TestTokenManagerSharedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	// Two managers on separate stores stand in for two processes.
+	var fetches int32
+	fetch := func(ctx context.Context) (Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		time.Sleep(20 * time.Millisecond)
+		return Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	storeA, _ := NewFileTokenStore(path)
+	storeB, _ := NewFileTokenStore(path)
+	managers := []*TokenManager{NewTokenManager(storeA, "k", fetch), NewTokenManager(storeB, "k", fetch)}
+
+	var wg sync.WaitGroup
+	for _, m := range managers {
+		wg.Add(1)
+		go func(m *TokenManager) {
+			defer wg.Done()
+			if _, err := m.Token(context.Background()); err != nil {
+				t.Errorf("Token failed: %v", err)
+			}
+		}(m)
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch across processes, got %d", fetches)
+	}
+}
+
+func TestTokenManagerFetchError(t *testing.T) {
+	calls := 0
+	manager := NewTokenManager(nil, "k", func(ctx context.Context) (Token, error) {
+		calls++
+		if calls == 1 {
+			return Token{}, errors.New("auth down")
+		}
+		return Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	if _, err := manager.Token(context.Background()); err == nil {
+		t.Error("Expected fetch error")
+	}
+	token, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if token.AccessToken != "abc" {
+		t.Errorf("Expected token 'abc', got '%s'", token.AccessToken)
+	}
+}
+
+func TestRefreshHook(t *testing.T) {
+	manager := NewTokenManager(nil, "mpesa:sandbox:k", func(ctx context.Context) (Token, error) {
+		return Token{AccessToken: "abc", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	var refreshed []string
+	ctx := WithRefreshHook(context.Background(), func(ctx context.Context, key string, err error) {
+		refreshed = append(refreshed, fmt.Sprint(key, " ", err))
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := manager.Token(ctx); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+
+	if len(refreshed) != 1 || refreshed[0] != "mpesa:sandbox:k <nil>" {
+		t.Errorf("Expected one refresh, got %v", refreshed)
+	}
+}
+
+func TestTokenManagerSetKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+
+	fetches := 0
+	m := NewTokenManager(store, "ncba:https://api.example.com:abc", func(ctx context.Context) (Token, error) {
+		fetches++
+		return Token{AccessToken: fmt.Sprintf("token-%d", fetches), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+	if token, _ := m.Token(ctx); token.AccessToken != "token-1" {
+		t.Fatalf("Expected token-1, got %s", token.AccessToken)
+	}
+
+	// A token for another server is not reused
+	m.SetKey("ncba:http://localhost:8080:abc")
+	if token, _ := m.Token(ctx); token.AccessToken != "token-2" {
+		t.Errorf("Expected a new token for the new key, got %s", token.AccessToken)
+	}
+	if token, found, _ := store.Get(ctx, "ncba:https://api.example.com:abc"); !found || token.AccessToken != "token-1" {
+		t.Errorf("Expected the old key's token to be kept, got %+v", token)
+	}
+}
+
+func TestKey(t *testing.T) {
+	key := Key("mpesa", "sandbox", "consumer-key")
+	if !strings.HasPrefix(key, "mpesa:sandbox:") {
+		t.Errorf("Expected provider and environment prefix, got '%s'", key)
+	}
+	if strings.Contains(key, "consumer-key") {
+		t.Errorf("Expected client ID to be hashed, got '%s'", key)
+	}
+	if key == Key("mpesa", "sandbox", "other-key") {
+		t.Error("Expected different client IDs to have different keys")
+	}
+}
+
+func TestExpiresIn(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ExpiresIn
+	}{
+		{`{"expires_in":"3599"}`, 3599},
+		{`{"expires_in":3599}`, 3599},
+		{`{"expires_in":" 120 "}`, 120},
+		{`{"expires_in":""}`, 0},
+		{`{"expires_in":null}`, 0},
+		{`{}`, 0},
+	}
+
+	for _, tt := range tests {
+		var resp struct {
+			ExpiresIn ExpiresIn `json:"expires_in"`
+		}
+		if err := json.Unmarshal([]byte(tt.input), &resp); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", tt.input, err)
+			continue
+		}
+		if resp.ExpiresIn != tt.want {
+			t.Errorf("Unmarshal(%s): expected %d, got %d", tt.input, tt.want, resp.ExpiresIn)
+		}
+	}
+
+	var e ExpiresIn
+	if err := json.Unmarshal([]byte(`"soon"`), &e); err == nil {
+		t.Error("Expected error for non-numeric expires_in")
+	}
+	if !ExpiresIn(0).ExpiresAt().IsZero() {
+		t.Error("Expected zero ExpiresAt for missing lifetime")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	if got := TTL(time.Hour); got != time.Hour-DefaultSkew {
+		t.Errorf("Expected %v, got %v", time.Hour-DefaultSkew, got)
+	}
+	if got := TTL(0); got != DefaultLifetime-DefaultSkew {
+		t.Errorf("Expected %v, got %v", DefaultLifetime-DefaultSkew, got)
+	}
+	if got := TTL(time.Minute); got != 30*time.Second {
+		t.Errorf("Expected 30s, got %v", got)
+	}
+}
+
+func TestTokenManagerSkew(t *testing.T) {
+	ctx := context.Background()
+	fetch := func(ctx context.Context) (Token, error) {
+		return Token{AccessToken: "new", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+
+	// A stored token 30 seconds from expiry is inside the default skew.
+	store := NewMemoryTokenStore()
+	store.Set(ctx, "k", Token{AccessToken: "old", ExpiresAt: time.Now().Add(30 * time.Second)})
+	token, _ := NewTokenManager(store, "k", fetch).Token(ctx)
+	if token.AccessToken != "new" {
+		t.Errorf("Expected a new token inside the skew, got '%s'", token.AccessToken)
+	}
+
+	store.Set(ctx, "k", Token{AccessToken: "old", ExpiresAt: time.Now().Add(30 * time.Second)})
+	manager := NewTokenManager(store, "k", fetch)
+	manager.SetSkew(5 * time.Second)
+	token, _ = manager.Token(ctx)
+	if token.AccessToken != "old" {
+		t.Errorf("Expected the stored token with a smaller skew, got '%s'", token.AccessToken)
+	}
+}
+
+func TestTokenManagerShortLivedToken(t *testing.T) {
+	var fetches int32
+	manager := NewTokenManager(nil, "k", func(ctx context.Context) (Token, error) {
+		atomic.AddInt32(&fetches, 1)
+		return Token{AccessToken: "short", ExpiresAt: time.Now().Add(30 * time.Second)}, nil
+	})
+
+	// A 30 second token is shorter than the skew, which is scaled down so the
+	// token is still reused.
+	for i := 0; i < 3; i++ {
+		if _, err := manager.Token(context.Background()); err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetches)
+	}
+
+	token, _ := manager.Token(context.Background())
+	if until := time.Until(manager.RefreshAt(token)); until < 10*time.Second || until > 15*time.Second {
+		t.Errorf("Expected RefreshAt about 15s from now, got %v", until)
+	}
+}
+
+func TestTokenManagerDefaultLifetime(t *testing.T) {
+	manager := NewTokenManager(nil, "k", func(ctx context.Context) (Token, error) {
+		return Token{AccessToken: "abc"}, nil
+	})
+
+	token, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if until := time.Until(token.ExpiresAt); until < DefaultLifetime-time.Minute || until > DefaultLifetime {
+		t.Errorf("Expected ExpiresAt about %v from now, got %v", DefaultLifetime, until)
+	}
+}
+
+func TestTokenManagerBackgroundRefresh(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+	store.Set(ctx, "k", Token{AccessToken: "old", ExpiresAt: time.Now().Add(90 * time.Second)})
+
+	fetched := make(chan struct{})
+	manager := NewTokenManager(store, "k", func(ctx context.Context) (Token, error) {
+		defer close(fetched)
+		return Token{AccessToken: "new", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	// 90 seconds is past the skew but inside twice the skew, so the old token
+	// is returned while a new one is fetched.
+	token, err := manager.Token(ctx)
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token.AccessToken != "old" {
+		t.Errorf("Expected 'old' while refreshing, got '%s'", token.AccessToken)
+	}
+
+	select {
+	case <-fetched:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a background refresh")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		token, _ = manager.Token(ctx)
+		if token.AccessToken == "new" || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if token.AccessToken != "new" {
+		t.Errorf("Expected refreshed token 'new', got '%s'", token.AccessToken)
+	}
+}
+
+func TestTokenManagerInvalidateReplaced(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+	store.Set(ctx, "k", Token{AccessToken: "current", ExpiresAt: time.Now().Add(time.Hour)})
+	manager := NewTokenManager(store, "k", nil)
+
+	manager.Invalidate(ctx, "rejected")
+	if _, found, _ := store.Get(ctx, "k"); !found {
+		t.Error("Expected a replaced token not to be invalidated")
+	}
+
+	manager.Invalidate(ctx, "current")
+	if _, found, _ := store.Get(ctx, "k"); found {
+		t.Error("Expected the current token to be invalidated")
+	}
+}
+
+func TestTokenRejected(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusUnauthorized, ``, true},
+		{http.StatusUnauthorized, `{"errorCode":"404.001.03","errorMessage":"Invalid Access Token"}`, true},
+		{http.StatusForbidden, `{"error":"token_expired"}`, true},
+		{http.StatusForbidden, `{"message":"Access has expired"}`, true},
+		{http.StatusForbidden, `{"message":"Insufficient scope for this resource"}`, false},
+		{http.StatusBadRequest, `{"message":"Invalid token"}`, false},
+		{http.StatusOK, ``, false},
+	}
+
+	for _, tt := range tests {
+		if got := TokenRejected(tt.status, []byte(tt.body)); got != tt.want {
+			t.Errorf("TokenRejected(%d, %s): expected %v, got %v", tt.status, tt.body, tt.want, got)
+		}
+	}
+}
+
+func TestTokenManagerDo(t *testing.T) {
+	var fetches int32
+	manager := NewTokenManager(nil, "k", func(ctx context.Context) (Token, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return Token{AccessToken: fmt.Sprintf("token-%d", n), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Token expired"}`))
+			return
+		}
+		if r.URL.Path == "/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Insufficient scope"}`))
+			return
+		}
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	do := func(path string) (*http.Response, error) {
+		return manager.Do(context.Background(), func(token string) (*http.Response, error) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			return http.DefaultClient.Do(req)
+		})
+	}
+
+	resp, err := do("/")
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("Expected retried request to succeed, got %d %s", resp.StatusCode, body)
+	}
+	if len(seen) != 2 || seen[1] != "Bearer token-2" {
+		t.Errorf("Expected a retry with a new token, got %v", seen)
+	}
+
+	// A 403 that is not about the token is returned untouched.
+	resp, err = do("/forbidden")
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "Insufficient scope") {
+		t.Errorf("Expected the 403 body to be readable, got %d %s", resp.StatusCode, body)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected no refresh for a permission error, got %d fetches", fetches)
+	}
+}
+
+func TestTokenManagerSetRejected(t *testing.T) {
+	var fetches int32
+	manager := NewTokenManager(nil, "k", func(ctx context.Context) (Token, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return Token{AccessToken: fmt.Sprintf("token-%d", n), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	})
+	manager.SetRejected(func(status int, body []byte) bool {
+		return status == http.StatusNotFound && strings.Contains(string(body), "Invalid Access Token")
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessage":"Invalid Access Token"}`))
+			return
+		}
+		w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	resp, err := manager.Do(context.Background(), func(token string) (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return http.DefaultClient.Do(req)
+	})
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || fetches != 2 {
+		t.Errorf("Expected a retry with a new token, got %d after %d fetches", resp.StatusCode, fetches)
+	}
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	lockPollInterval = 50 * time.Millisecond
+	lockStaleAfter   = 30 * time.Second
+)
+
+// FileTokenStore keeps tokens in a JSON file so processes on the same host,
+// or pods sharing a volume, reuse one token per credential. Writes replace
+// the file atomically and refreshes are serialized across processes with a
+// lock file next to it.
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileTokenStore(path string) (*FileTokenStore, error) {
+	if path == "" {
+		return nil, errors.New("token store path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create token store directory: %w", err)
+	}
+	return &FileTokenStore{path: path}, nil
+}
+
+func (s *FileTokenStore) Get(ctx context.Context, key string) (Token, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return Token{}, false, err
+	}
+	token, found := tokens[key]
+	if !found || !token.Valid() {
+		return Token{}, false, nil
+	}
+	return token, true, nil
+}
+
+func (s *FileTokenStore) Set(ctx context.Context, key string, token Token) error {
+	return s.update(func(tokens map[string]Token) {
+		tokens[key] = token
+	})
+}
+
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	return s.update(func(tokens map[string]Token) {
+		delete(tokens, key)
+	})
+}
+
+// Lock takes the store's lock file, waiting until it is free or ctx is done.
+// A lock file older than 30 seconds is assumed to belong to a crashed process
+// and is removed.
+func (s *FileTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	lockPath := s.path + ".lock"
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create token store lock: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(lockPath)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (s *FileTokenStore) update(fn func(map[string]Token)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	fn(tokens)
+
+	// Drop expired tokens so the file does not grow with rotated credentials.
+	for key, token := range tokens {
+		if !token.Valid() {
+			delete(tokens, key)
+		}
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to encode token store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write token store: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) read() (map[string]Token, error) {
+	tokens := make(map[string]Token)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token store: %w", err)
+	}
+	if len(data) == 0 {
+		return tokens, nil
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token store: %w", err)
+	}
+	return tokens, nil
+}
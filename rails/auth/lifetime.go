@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSkew is how long before expiry a token stops being handed out,
+	// so requests in flight do not reach the provider with an expired token.
+	DefaultSkew = time.Minute
+
+	// DefaultLifetime is assumed when a provider sends no or zero expires_in.
+	DefaultLifetime = 30 * time.Minute
+)
+
+// ExpiresIn is a token lifetime in seconds. It decodes from a JSON number or
+// a numeric string, since Daraja sends "3599" where others send 3599.
+type ExpiresIn int64
+
+func (e *ExpiresIn) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*e = 0
+		return nil
+	}
+
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		s = string(data)
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*e = 0
+		return nil
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expires_in %s: %w", data, err)
+	}
+	*e = ExpiresIn(seconds)
+	return nil
+}
+
+func (e ExpiresIn) Duration() time.Duration {
+	return time.Duration(e) * time.Second
+}
+
+// ExpiresAt returns when a token issued now expires, or the zero time if the
+// provider sent no lifetime, in which case TokenManager uses DefaultLifetime.
+func (e ExpiresIn) ExpiresAt() time.Time {
+	if e <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(e.Duration())
+}
+
+// TTL returns how long to cache a token that lives for expiresIn when it is
+// kept outside a TokenManager, applying DefaultLifetime and DefaultSkew.
+// Lifetimes shorter than twice the skew are halved instead.
+func TTL(expiresIn time.Duration) time.Duration {
+	if expiresIn <= 0 {
+		expiresIn = DefaultLifetime
+	}
+	if expiresIn <= 2*DefaultSkew {
+		return expiresIn / 2
+	}
+	return expiresIn - DefaultSkew
+}
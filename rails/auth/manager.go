@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// refreshTimeout bounds a refresh, which runs detached from the caller so a
+// cancelled request does not fail everyone waiting on the same token.
+const refreshTimeout = 30 * time.Second
+
+// FetchFunc requests a new token from the provider. A token with a zero
+// ExpiresAt is assumed to live for DefaultLifetime.
+type FetchFunc func(ctx context.Context) (Token, error)
+
+// TokenManager returns the stored token for a key, fetching and storing a new
+// one when it is missing or within the skew of its expiry. Concurrent callers
+// in a process share a single fetch, and stores that implement Locker
+// serialize fetches across processes.
+//
+// Once a token is within twice the skew of its expiry, callers still get it
+// while a new one is fetched in the background, so busy clients rarely wait
+// on the auth endpoint. For tokens that live less than twice the skew, the
+// skew is reduced to half their lifetime.
+type TokenManager struct {
+	key   string
+	fetch FetchFunc
+
+	mu       sync.Mutex
+	store    TokenStore
+	skew     time.Duration
+	rejected func(status int, body []byte) bool
+	inflight *call
+}
+
+// RefreshHook is called after each token fetch with the context of the
+// request that caused it, the manager's key and the error the fetch
+// returned, if any.
+type RefreshHook func(ctx context.Context, key string, err error)
+
+type refreshHookKey struct{}
+
+// WithRefreshHook returns a context whose requests call hook when they cause
+// a token fetch, e.g. to count refreshes. Callers sharing a fetch with one
+// already in flight do not call it.
+func WithRefreshHook(ctx context.Context, hook RefreshHook) context.Context {
+	return context.WithValue(ctx, refreshHookKey{}, hook)
+}
+
+type call struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+func NewTokenManager(store TokenStore, key string, fetch FetchFunc) *TokenManager {
+	m := &TokenManager{key: key, fetch: fetch, skew: DefaultSkew}
+	m.SetStore(store)
+	return m
+}
+
+// SetStore replaces the store, defaulting to a MemoryTokenStore when nil.
+func (m *TokenManager) SetStore(store TokenStore) {
+	if store == nil {
+		store = NewMemoryTokenStore()
+	}
+	m.mu.Lock()
+	m.store = store
+	m.mu.Unlock()
+}
+
+// SetKey changes the key the token is stored under, e.g. because the client
+// now talks to a different server whose tokens must not be shared with the
+// old one's.
+func (m *TokenManager) SetKey(key string) {
+	m.mu.Lock()
+	m.key = key
+	m.mu.Unlock()
+}
+
+// SetSkew sets how long before expiry a token is refreshed.
+func (m *TokenManager) SetSkew(skew time.Duration) {
+	m.mu.Lock()
+	m.skew = skew
+	m.mu.Unlock()
+}
+
+// SetRejected sets how Do recognizes a response refusing the token, for
+// providers that do not answer with a 401 or 403. rejected is given the
+// status and the start of the body of each response with a status of 400 or
+// more. TokenRejected is used until it is set.
+func (m *TokenManager) SetRejected(rejected func(status int, body []byte) bool) {
+	m.mu.Lock()
+	m.rejected = rejected
+	m.mu.Unlock()
+}
+
+// RefreshAt returns when Token stops handing out token, for callers that keep
+// their own copy of it.
+func (m *TokenManager) RefreshAt(token Token) time.Time {
+	_, skew, _ := m.config()
+	hard, _ := windows(token, skew)
+	return token.ExpiresAt.Add(-hard)
+}
+
+// Token returns a valid token. A store that fails to read is treated as
+// empty so a store outage degrades to fetching tokens directly.
+func (m *TokenManager) Token(ctx context.Context) (Token, error) {
+	store, skew, key := m.config()
+
+	if token, found, err := store.Get(ctx, key); err == nil && found {
+		hard, soft := windows(token, skew)
+		if usable(token, hard) {
+			if !usable(token, soft) {
+				m.start(ctx)
+			}
+			return token, nil
+		}
+	}
+
+	c := m.start(ctx)
+	select {
+	case <-c.done:
+		return c.token, c.err
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	}
+}
+
+// Invalidate removes accessToken from the store so the next call to Token
+// fetches a new one. A token that has already been replaced, e.g. by another
+// process, is left alone.
+func (m *TokenManager) Invalidate(ctx context.Context, accessToken string) error {
+	store, _, key := m.config()
+
+	token, found, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if found && token.AccessToken != accessToken {
+		return nil
+	}
+	return store.Delete(ctx, key)
+}
+
+func (m *TokenManager) config() (TokenStore, time.Duration, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.store, m.skew, m.key
+}
+
+// start returns the refresh in flight, starting one if there is none.
+func (m *TokenManager) start(ctx context.Context) *call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.inflight != nil {
+		return m.inflight
+	}
+	c := &call{done: make(chan struct{})}
+	m.inflight = c
+
+	store, skew, key := m.store, m.skew, m.key
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+	go func() {
+		defer cancel()
+		c.token, c.err = m.refresh(ctx, store, skew, key)
+
+		m.mu.Lock()
+		m.inflight = nil
+		m.mu.Unlock()
+		close(c.done)
+	}()
+	return c
+}
+
+func (m *TokenManager) refresh(ctx context.Context, store TokenStore, skew time.Duration, key string) (Token, error) {
+	if locker, ok := store.(Locker); ok {
+		unlock, err := locker.Lock(ctx, key)
+		if err != nil {
+			return Token{}, err
+		}
+		defer unlock()
+
+		// Another process may have refreshed while we waited for the lock.
+		if token, found, err := store.Get(ctx, key); err == nil && found {
+			if _, soft := windows(token, skew); usable(token, soft) {
+				return token, nil
+			}
+		}
+	}
+
+	token, err := m.fetch(ctx)
+	if hook, ok := ctx.Value(refreshHookKey{}).(RefreshHook); ok {
+		hook(ctx, key, err)
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	token.IssuedAt = time.Now()
+	if token.ExpiresAt.IsZero() {
+		token.ExpiresAt = token.IssuedAt.Add(DefaultLifetime)
+	}
+
+	// The token is usable even if it could not be shared.
+	_ = store.Set(ctx, key, token)
+
+	return token, nil
+}
+
+// windows returns how long before expiry token stops being handed out, and
+// how long before expiry a background refresh starts.
+func windows(token Token, skew time.Duration) (hard, soft time.Duration) {
+	hard, soft = skew, 2*skew
+	if token.IssuedAt.IsZero() {
+		return hard, soft
+	}
+	lifetime := token.ExpiresAt.Sub(token.IssuedAt)
+	if hard > lifetime/2 {
+		hard = lifetime / 2
+	}
+	if soft > hard+lifetime/4 {
+		soft = hard + lifetime/4
+	}
+	return hard, soft
+}
+
+// usable reports whether token is still valid skew from now.
+func usable(token Token, skew time.Duration) bool {
+	return token.AccessToken != "" && time.Now().Add(skew).Before(token.ExpiresAt)
+}
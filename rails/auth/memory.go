@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// MemoryTokenStore keeps tokens in process memory. It is the default store
+// of every client.
+type MemoryTokenStore struct {
+	cache *cache.Cache
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{cache: cache.New(cache.NoExpiration, 10*time.Minute)}
+}
+
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (Token, bool, error) {
+	v, found := s.cache.Get(key)
+	if !found {
+		return Token{}, false, nil
+	}
+	token := v.(Token)
+	if !token.Valid() {
+		return Token{}, false, nil
+	}
+	return token, true, nil
+}
+
+func (s *MemoryTokenStore) Set(ctx context.Context, key string, token Token) error {
+	if !token.Valid() {
+		s.cache.Delete(key)
+		return nil
+	}
+	s.cache.Set(key, token, time.Until(token.ExpiresAt))
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// maxRejectedBody bounds how much of an error body is read to tell a token
+// error from a permission error.
+const maxRejectedBody = 64 << 10
+
+// TokenRejected reports whether a response means the access token itself was
+// refused, i.e. a 401 or 403 that is empty or mentions the token or its
+// expiry. Other 403s, such as missing permissions, are not retried.
+func TokenRejected(status int, body []byte) bool {
+	if status != http.StatusUnauthorized && status != http.StatusForbidden {
+		return false
+	}
+	body = bytes.ToLower(bytes.TrimSpace(body))
+	if len(body) == 0 {
+		return true
+	}
+	return bytes.Contains(body, []byte("token")) || bytes.Contains(body, []byte("expired"))
+}
+
+// Rejected is TokenRejected for a response whose body has not been read. The
+// body is left readable for the caller when the token was not rejected.
+func Rejected(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	return rejected(resp, TokenRejected)
+}
+
+// rejected calls tokenRejected with the status and the start of the body of
+// an error response, leaving the body readable.
+func rejected(resp *http.Response, tokenRejected func(status int, body []byte) bool) bool {
+	if resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxRejectedBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	return tokenRejected(resp.StatusCode, body)
+}
+
+// Do calls send with a valid token. If the provider rejects the token, as
+// told by TokenRejected or the function given to SetRejected, Do invalidates
+// it and calls send once more with a new one, so send must build a fresh
+// request on every call.
+func (m *TokenManager) Do(ctx context.Context, send func(token string) (*http.Response, error)) (*http.Response, error) {
+	token, err := m.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	tokenRejected := m.rejected
+	m.mu.Unlock()
+	if tokenRejected == nil {
+		tokenRejected = TokenRejected
+	}
+
+	resp, err := send(token.AccessToken)
+	if err != nil || !rejected(resp, tokenRejected) {
+		return resp, err
+	}
+	if err := m.Invalidate(ctx, token.AccessToken); err != nil {
+		// Retrying would only reuse the rejected token.
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	token, err = m.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return send(token.AccessToken)
+}
@@ -0,0 +1,236 @@
+// Package banks is a registry of Kenyan bank codes, which PesaLink uses to
+// identify its participants, and South African universal branch codes:
+//
+//	bank, err := banks.Find(banks.Kenya, "Equity")
+//	bank.Code // "68", for a PesaLink destinationBank
+//
+//	err = banks.ValidateAccount(banks.SouthAfrica, "632005", "4056123450")
+//
+// South African account numbers are checked against their bank's check
+// digit verification (CDV) rule, where the registry has one, so bad
+// accounts are rejected before a payment file reaches BankservAfrica. CDV
+// rules must come from BankservAfrica's CDV specification; the registry
+// carries none yet, so accounts are only checked for digits and length.
+//
+// The registry is embedded from the JSON files in the registry directory,
+// each carrying the version of the data it holds.
+package banks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnknownBank is returned for codes and names not in the registry.
+	ErrUnknownBank = errors.New("banks: unknown bank")
+
+	// ErrAmbiguous is returned by Find for names that match more than one
+	// bank.
+	ErrAmbiguous = errors.New("banks: ambiguous bank name")
+
+	// ErrInvalidAccount is returned for account numbers that are not
+	// digits, or too long for their bank.
+	ErrInvalidAccount = errors.New("banks: invalid account number")
+
+	// ErrCheckDigit is returned for account numbers that fail their bank's
+	// check digit verification.
+	ErrCheckDigit = errors.New("banks: account number fails check digit verification")
+)
+
+// Country is an ISO 3166-1 alpha-2 country code.
+type Country string
+
+const (
+	Kenya       Country = "KE"
+	SouthAfrica Country = "ZA"
+)
+
+// Bank is a bank in the registry.
+type Bank struct {
+	Country Country
+
+	// Code is the Central Bank of Kenya bank code, which is also the
+	// bank's PesaLink code, or the South African universal branch code.
+	Code string
+
+	Name    string
+	Aliases []string // Other names the bank is known by, e.g. "KCB"
+
+	// PesaLink reports whether the bank is a PesaLink participant.
+	PesaLink bool
+
+	maxLength int
+	cdv       *cdv
+}
+
+// cdv is a check digit verification rule: an account number, padded with
+// zeros to the number of weights, is valid if the sum of its digits times
+// their weights, plus Fudge, is a multiple of Modulus. The first of the
+// Exceptions to match an account number replaces the rule for it, as
+// exception codes do in BankservAfrica's specification.
+type cdv struct {
+	Weights    []int          `json:"weights"`
+	Modulus    int            `json:"modulus"`
+	Fudge      int            `json:"fudge,omitempty"`
+	Exceptions []cdvException `json:"exceptions,omitempty"`
+}
+
+// cdvException matches account numbers, unpadded, that start with Prefix
+// and have Length digits, where those are set. They are checked against Rule
+// instead, or not checked when Rule is nil.
+type cdvException struct {
+	Prefix string `json:"prefix,omitempty"`
+	Length int    `json:"length,omitempty"`
+	Rule   *cdv   `json:"rule,omitempty"`
+}
+
+func (c *cdv) valid(account string) bool {
+	for _, e := range c.Exceptions {
+		if strings.HasPrefix(account, e.Prefix) && (e.Length == 0 || len(account) == e.Length) {
+			return e.Rule == nil || e.Rule.valid(account)
+		}
+	}
+
+	account = strings.Repeat("0", len(c.Weights)-len(account)) + account
+	sum := c.Fudge
+	for i, d := range account {
+		sum += int(d-'0') * c.Weights[i]
+	}
+	return sum%c.Modulus == 0
+}
+
+// check reports whether the rule and its exceptions can verify account
+// numbers of up to maxLength digits. An exception matching every account
+// number is refused, since it would leave the rule unused.
+func (c *cdv) check(maxLength int) bool {
+	if c.Modulus <= 0 || len(c.Weights) < maxLength {
+		return false
+	}
+	for _, e := range c.Exceptions {
+		if e.Prefix == "" && e.Length == 0 || e.Rule != nil && !e.Rule.check(maxLength) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasCDV reports whether ValidateAccount runs check digit verification on
+// the bank's account numbers.
+func (b Bank) HasCDV() bool {
+	return b.cdv != nil
+}
+
+// ValidateAccount checks that account, ignoring spaces and dashes, is a
+// number the bank could have issued: digits no longer than the bank's
+// account numbers, which pass its check digit verification if it has one.
+func (b Bank) ValidateAccount(account string) error {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(account)
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return fmt.Errorf("%w: %q", ErrInvalidAccount, account)
+	}
+	if len(digits) > b.maxLength {
+		return fmt.Errorf("%w: %q is longer than %d digits for %s", ErrInvalidAccount, account, b.maxLength, b.Name)
+	}
+	if b.cdv != nil && !b.cdv.valid(digits) {
+		return fmt.Errorf("%w: %q at %s", ErrCheckDigit, account, b.Name)
+	}
+	return nil
+}
+
+func (b Bank) String() string {
+	return b.Name
+}
+
+// Version returns the version of country's registry data, or "" for
+// countries not in the registry.
+func Version(country Country) string {
+	return registries[country].version
+}
+
+// Banks returns the banks in country, by name.
+func Banks(country Country) []Bank {
+	return append([]Bank(nil), registries[country].banks...)
+}
+
+// PesaLinkParticipants returns the Kenyan banks taking part in PesaLink, by
+// name.
+func PesaLinkParticipants() []Bank {
+	var participants []Bank
+	for _, b := range registries[Kenya].banks {
+		if b.PesaLink {
+			participants = append(participants, b)
+		}
+	}
+	return participants
+}
+
+// Lookup returns the bank with code in country. Kenyan codes may be written
+// with extra leading zeros, as in "0068", and South African ones without
+// theirs, as in "51001".
+func Lookup(country Country, code string) (Bank, error) {
+	r, found := registries[country]
+	if !found {
+		return Bank{}, fmt.Errorf("%w: no registry for %q", ErrUnknownBank, country)
+	}
+	b, found := r.byCode[r.normalizeCode(code)]
+	if !found {
+		return Bank{}, fmt.Errorf("%w: %s code %q", ErrUnknownBank, country, code)
+	}
+	return b, nil
+}
+
+// Find returns the bank in country called name, ignoring case and
+// punctuation. A name that is not a bank's name or alias matches the bank
+// whose name contains it, and fails with ErrAmbiguous if several do.
+func Find(country Country, name string) (Bank, error) {
+	r := registries[country]
+	key := normalizeName(name)
+	if key == "" {
+		return Bank{}, fmt.Errorf("%w: empty name", ErrUnknownBank)
+	}
+	if b, found := r.byName[key]; found {
+		return b, nil
+	}
+
+	var matches []Bank
+	for _, b := range r.banks {
+		if strings.Contains(normalizeName(b.Name), key) {
+			matches = append(matches, b)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Bank{}, fmt.Errorf("%w: %s bank %q", ErrUnknownBank, country, name)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, b := range matches {
+		names[i] = b.Name
+	}
+	return Bank{}, fmt.Errorf("%w: %q matches %s", ErrAmbiguous, name, strings.Join(names, ", "))
+}
+
+// ValidateAccount checks account against the bank with code in country, as
+// Bank.ValidateAccount does.
+func ValidateAccount(country Country, code, account string) error {
+	b, err := Lookup(country, code)
+	if err != nil {
+		return err
+	}
+	return b.ValidateAccount(account)
+}
+
+// normalizeName lowercases name, spells out "&" and drops everything but
+// letters and digits, so "Co-op Bank" and "coop bank" match.
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.ReplaceAll(name, "&", " and ")) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package banks
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		country Country
+		code    string
+		want    string
+	}{
+		{Kenya, "68", "Equity Bank Kenya"},
+		{Kenya, "0068", "Equity Bank Kenya"},
+		{Kenya, "1", "Kenya Commercial Bank"},
+		{SouthAfrica, "250655", "First National Bank"},
+		{SouthAfrica, "51001", "Standard Bank"},
+	}
+	for _, tt := range tests {
+		b, err := Lookup(tt.country, tt.code)
+		if err != nil {
+			t.Errorf("Lookup(%s, %q): %v", tt.country, tt.code, err)
+			continue
+		}
+		if b.Name != tt.want {
+			t.Errorf("Lookup(%s, %q): Expected %s, got %s", tt.country, tt.code, tt.want, b.Name)
+		}
+	}
+
+	if _, err := Lookup(Kenya, "99"); !errors.Is(err, ErrUnknownBank) {
+		t.Errorf("Expected ErrUnknownBank, got %v", err)
+	}
+	if _, err := Lookup("NG", "044"); !errors.Is(err, ErrUnknownBank) {
+		t.Errorf("Expected ErrUnknownBank for a country with no registry, got %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		country Country
+		name    string
+		want    string
+	}{
+		{Kenya, "KCB", "01"},
+		{Kenya, "coop bank", "11"},
+		{Kenya, "I & M Bank", "57"},
+		{Kenya, "Diamond", "63"},
+		{SouthAfrica, "fnb", "250655"},
+		{SouthAfrica, "Capitec", "470010"},
+	}
+	for _, tt := range tests {
+		b, err := Find(tt.country, tt.name)
+		if err != nil {
+			t.Errorf("Find(%s, %q): %v", tt.country, tt.name, err)
+			continue
+		}
+		if b.Code != tt.want {
+			t.Errorf("Find(%s, %q): Expected %s, got %s", tt.country, tt.name, tt.want, b.Code)
+		}
+	}
+
+	if _, err := Find(Kenya, "Commercial"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("Expected ErrAmbiguous, got %v", err)
+	}
+	if _, err := Find(SouthAfrica, "Equity"); !errors.Is(err, ErrUnknownBank) {
+		t.Errorf("Expected ErrUnknownBank, got %v", err)
+	}
+}
+
+func TestValidateAccount(t *testing.T) {
+	tests := []struct {
+		country Country
+		code    string
+		account string
+		want    error
+	}{
+		{SouthAfrica, "632005", "4056-123-450", nil},
+		{SouthAfrica, "632005", "405612345012", ErrInvalidAccount},
+		{SouthAfrica, "250655", "62123456789", nil},
+		{SouthAfrica, "250655", "621234567890", ErrInvalidAccount},
+		{SouthAfrica, "470010", "12345678901", ErrInvalidAccount}, // Capitec accounts are 10 digits
+		{Kenya, "68", "0123456789012", nil},
+		{Kenya, "68", "01234A", ErrInvalidAccount},
+		{Kenya, "98", "0123456789", ErrUnknownBank},
+	}
+	for _, tt := range tests {
+		err := ValidateAccount(tt.country, tt.code, tt.account)
+		if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("ValidateAccount(%s, %s, %q): Expected %v, got %v", tt.country, tt.code, tt.account, tt.want, err)
+		}
+	}
+}
+
+func TestCDV(t *testing.T) {
+	// A made-up rule, not a bank's: 0132 weighs 0*4 + 1*3 + 3*2 + 2*1 = 11
+	b := Bank{Name: "Test Bank", maxLength: 4, cdv: &cdv{Weights: []int{4, 3, 2, 1}, Modulus: 11}}
+	for account, want := range map[string]error{
+		"0132": nil,
+		"132":  nil, // Padded with zeros to the number of weights
+		"0133": ErrCheckDigit,
+		"1234": ErrCheckDigit,
+	} {
+		if err := b.ValidateAccount(account); !errors.Is(err, want) || (want == nil && err != nil) {
+			t.Errorf("ValidateAccount(%q): Expected %v, got %v", account, want, err)
+		}
+	}
+}
+
+func TestCDVExceptions(t *testing.T) {
+	// Made-up rules too: accounts starting with 9 are not checked, and
+	// three digit ones use other weights and a fudge of 1
+	rule := &cdv{
+		Weights: []int{4, 3, 2, 1},
+		Modulus: 11,
+		Exceptions: []cdvException{
+			{Prefix: "9"},
+			{Length: 3, Rule: &cdv{Weights: []int{1, 1, 1, 1}, Modulus: 10, Fudge: 1}},
+		},
+	}
+	b := Bank{Name: "Test Bank", maxLength: 4, cdv: rule}
+	for account, want := range map[string]error{
+		"0132": nil,
+		"0133": ErrCheckDigit,
+		"9999": nil, // Not checked
+		"135":  nil, // 1 + 1 + 3 + 5 = 10
+		"132":  ErrCheckDigit,
+	} {
+		if err := b.ValidateAccount(account); !errors.Is(err, want) || (want == nil && err != nil) {
+			t.Errorf("ValidateAccount(%q): Expected %v, got %v", account, want, err)
+		}
+	}
+
+	if !rule.check(4) {
+		t.Error("Expected the rule to be usable for 4 digit accounts")
+	}
+	if rule.check(5) {
+		t.Error("Expected a rule with too few weights to be refused")
+	}
+	invalid := &cdv{Weights: []int{1, 1, 1, 1}, Modulus: 10, Exceptions: []cdvException{{Length: 2, Rule: &cdv{Weights: []int{1}, Modulus: 10}}}}
+	if invalid.check(4) {
+		t.Error("Expected an exception with too few weights to be refused")
+	}
+	catchAll := &cdv{Weights: []int{1, 1, 1, 1}, Modulus: 10, Exceptions: []cdvException{{}}}
+	if catchAll.check(4) {
+		t.Error("Expected an exception matching every account to be refused")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, country := range []Country{Kenya, SouthAfrica} {
+		if Version(country) == "" {
+			t.Errorf("Expected a version for %s", country)
+		}
+		if len(Banks(country)) == 0 {
+			t.Errorf("Expected banks for %s", country)
+		}
+	}
+	for _, b := range PesaLinkParticipants() {
+		if b.Country != Kenya {
+			t.Errorf("Expected only Kenyan PesaLink participants, got %s in %s", b.Name, b.Country)
+		}
+	}
+}
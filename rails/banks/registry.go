@@ -0,0 +1,139 @@
+package banks
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The registry data is bundled from the registry directory, one file per
+// country.
+//
+//go:embed registry/*.json
+var registryFiles embed.FS
+
+// registryFile is the format of a country's registry file.
+type registryFile struct {
+	Country Country `json:"country"`
+	Version string  `json:"version"`
+	Banks   []struct {
+		Code      string   `json:"code"`
+		Name      string   `json:"name"`
+		Aliases   []string `json:"aliases"`
+		PesaLink  bool     `json:"pesalink"`
+		MaxLength int      `json:"maxLength"`
+		CDV       *cdv     `json:"cdv"`
+	} `json:"banks"`
+}
+
+type registry struct {
+	version  string
+	codeSize int // Digits in a code, which shorter ones are padded to
+	banks    []Bank
+	byCode   map[string]Bank
+	byName   map[string]Bank
+}
+
+// Codes are two digits in Kenya and six in South Africa, where account
+// numbers are at most 11 digits long.
+var (
+	codeSizes  = map[Country]int{Kenya: 2, SouthAfrica: 6}
+	maxLengths = map[Country]int{Kenya: 16, SouthAfrica: 11}
+)
+
+var registries = mustLoadRegistries()
+
+func mustLoadRegistries() map[Country]*registry {
+	registries, err := loadRegistries()
+	if err != nil {
+		panic(err)
+	}
+	return registries
+}
+
+func loadRegistries() (map[Country]*registry, error) {
+	entries, err := registryFiles.ReadDir("registry")
+	if err != nil {
+		return nil, err
+	}
+	registries := make(map[Country]*registry)
+	for _, entry := range entries {
+		data, err := registryFiles.ReadFile("registry/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		r, country, err := parseRegistry(data)
+		if err != nil {
+			return nil, fmt.Errorf("banks: registry/%s: %w", entry.Name(), err)
+		}
+		registries[country] = r
+	}
+	return registries, nil
+}
+
+func parseRegistry(data []byte) (*registry, Country, error) {
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, "", err
+	}
+	codeSize, found := codeSizes[file.Country]
+	if !found {
+		return nil, "", fmt.Errorf("unsupported country %q", file.Country)
+	}
+
+	r := &registry{
+		version:  file.Version,
+		codeSize: codeSize,
+		byCode:   make(map[string]Bank),
+		byName:   make(map[string]Bank),
+	}
+	for _, entry := range file.Banks {
+		b := Bank{
+			Country:   file.Country,
+			Code:      entry.Code,
+			Name:      entry.Name,
+			Aliases:   entry.Aliases,
+			PesaLink:  entry.PesaLink,
+			maxLength: entry.MaxLength,
+			cdv:       entry.CDV,
+		}
+		if b.maxLength == 0 {
+			b.maxLength = maxLengths[file.Country]
+		}
+		if len(b.Code) != codeSize || strings.Trim(b.Code, "0123456789") != "" {
+			return nil, "", fmt.Errorf("%s has invalid code %q", b.Name, b.Code)
+		}
+		if _, found := r.byCode[b.Code]; found {
+			return nil, "", fmt.Errorf("code %s is listed twice", b.Code)
+		}
+		if b.cdv != nil && !b.cdv.check(b.maxLength) {
+			return nil, "", fmt.Errorf("%s has an invalid CDV rule", b.Name)
+		}
+		r.byCode[b.Code] = b
+		for _, name := range append([]string{b.Name}, b.Aliases...) {
+			key := normalizeName(name)
+			if other, found := r.byName[key]; found {
+				return nil, "", fmt.Errorf("%q names both %s and %s", name, other.Name, b.Name)
+			}
+			r.byName[key] = b
+		}
+		r.banks = append(r.banks, b)
+	}
+	sort.Slice(r.banks, func(i, j int) bool { return r.banks[i].Name < r.banks[j].Name })
+	return r, file.Country, nil
+}
+
+// normalizeCode pads code with zeros to the registry's code size, after
+// dropping any extra leading zeros.
+func (r *registry) normalizeCode(code string) string {
+	code = strings.TrimSpace(code)
+	if len(code) > r.codeSize {
+		code = strings.TrimLeft(code, "0")
+	}
+	if len(code) < r.codeSize {
+		code = strings.Repeat("0", r.codeSize-len(code)) + code
+	}
+	return code
+}
@@ -0,0 +1,231 @@
+// Package breaker stops sending requests to a provider, or to one of its
+// endpoints, while it is failing, so callers get an error at once instead of
+// waiting for every request to time out.
+//
+// Each circuit starts closed. It opens when enough recent requests fail, and
+// rejects requests with ErrOpen until OpenTimeout has passed. It is then
+// half-open: a few probe requests are let through, and the circuit closes
+// if they succeed or opens again if one fails.
+//
+// Every provider client has a Breaker with DefaultSettings; its Health
+// method reports the state of each circuit.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/transport"
+)
+
+// ErrOpen is returned, wrapped in a ProviderError in the provider_down
+// category, for requests rejected by an open circuit.
+var ErrOpen = errors.New("breaker: circuit open")
+
+// State is the state of a circuit.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Requests are sent
+	StateOpen     State = "open"      // Requests are rejected
+	StateHalfOpen State = "half-open" // Probe requests are sent
+)
+
+// Settings control when circuits open and close. Zero fields take their
+// value from DefaultSettings.
+type Settings struct {
+	// Window is how far back requests count towards the error rate.
+	Window time.Duration
+
+	// MinRequests is how many requests the window must hold before the
+	// error rate can open the circuit.
+	MinRequests int
+
+	// FailureRate is the share of failed requests in the window, from 0 to
+	// 1, that opens the circuit.
+	FailureRate float64
+
+	// ConsecutiveFailures opens the circuit after that many failures in a
+	// row, however few requests there were.
+	ConsecutiveFailures int
+
+	// OpenTimeout is how long the circuit stays open before it lets probe
+	// requests through.
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is how many probes must succeed to close the circuit.
+	HalfOpenRequests int
+}
+
+// DefaultSettings opens a circuit when half of at least 10 requests in the
+// last minute failed, or after 5 failures in a row, and probes it again
+// after 30 seconds.
+func DefaultSettings() Settings {
+	return Settings{
+		Window:              time.Minute,
+		MinRequests:         10,
+		FailureRate:         0.5,
+		ConsecutiveFailures: 5,
+		OpenTimeout:         30 * time.Second,
+		HalfOpenRequests:    1,
+	}
+}
+
+func (s Settings) withDefaults() Settings {
+	d := DefaultSettings()
+	if s.Window <= 0 {
+		s.Window = d.Window
+	}
+	if s.MinRequests <= 0 {
+		s.MinRequests = d.MinRequests
+	}
+	if s.FailureRate <= 0 {
+		s.FailureRate = d.FailureRate
+	}
+	if s.ConsecutiveFailures <= 0 {
+		s.ConsecutiveFailures = d.ConsecutiveFailures
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = d.OpenTimeout
+	}
+	if s.HalfOpenRequests <= 0 {
+		s.HalfOpenRequests = d.HalfOpenRequests
+	}
+	return s
+}
+
+// Breaker guards a provider with one circuit for all its requests and one
+// per endpoint. A request is rejected when either is open. It is safe for
+// concurrent use.
+type Breaker struct {
+	provider string
+	settings Settings
+
+	mu        sync.Mutex
+	circuit   *circuit
+	endpoints map[string]*circuit
+}
+
+// New returns a Breaker for provider.
+func New(provider string, settings Settings) *Breaker {
+	settings = settings.withDefaults()
+	return &Breaker{
+		provider:  provider,
+		settings:  settings,
+		circuit:   newCircuit(settings),
+		endpoints: make(map[string]*circuit),
+	}
+}
+
+// Do sends req with send unless its circuit is open, and records the
+// outcome. Failures are network errors, other than the caller cancelling,
+// and 5xx responses.
+func (b *Breaker) Do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	endpoint := b.endpoint(Endpoint(req))
+	now := time.Now()
+	if !b.circuit.allow(now) {
+		return nil, b.rejected()
+	}
+	if !endpoint.allow(now) {
+		b.circuit.release()
+		return nil, b.rejected()
+	}
+
+	resp, err := send(req)
+
+	now = time.Now()
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		b.circuit.release()
+		endpoint.release()
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		b.circuit.record(now, false)
+		endpoint.record(now, false)
+	default:
+		b.circuit.record(now, true)
+		endpoint.record(now, true)
+	}
+	return resp, err
+}
+
+// Middleware returns the Breaker as transport middleware, for clients built
+// outside this module.
+func (b *Breaker) Middleware() transport.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return b.Do(req, next.RoundTrip)
+		})
+	}
+}
+
+// Health returns the state of the provider's circuits.
+func (b *Breaker) Health() Health {
+	now := time.Now()
+
+	b.mu.Lock()
+	endpoints := make(map[string]*circuit, len(b.endpoints))
+	for name, c := range b.endpoints {
+		endpoints[name] = c
+	}
+	b.mu.Unlock()
+
+	h := Health{Provider: b.provider, Stats: b.circuit.stats(now), Endpoints: make(map[string]Stats, len(endpoints))}
+	for name, c := range endpoints {
+		h.Endpoints[name] = c.stats(now)
+	}
+	return h
+}
+
+func (b *Breaker) endpoint(name string) *circuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.endpoints[name]
+	if !ok {
+		c = newCircuit(b.settings)
+		b.endpoints[name] = c
+	}
+	return c
+}
+
+func (b *Breaker) rejected() error {
+	return &railerrors.ProviderError{
+		Provider:  b.provider,
+		Message:   "circuit open, provider is failing",
+		Retryable: true,
+		Category:  railerrors.CategoryProviderDown,
+		Err:       ErrOpen,
+	}
+}
+
+// Endpoint names the endpoint req is sent to: its method and path, with
+// segments that look like identifiers, such as a UUID, an MSISDN or a
+// transaction ID, replaced by "{id}", e.g.
+// "GET /collection/v1_0/requesttopay/{id}".
+func Endpoint(req *http.Request) string {
+	segments := strings.Split(req.URL.Path, "/")
+	for i, s := range segments {
+		if identifier(s) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " " + strings.Join(segments, "/")
+}
+
+// identifier reports whether a path segment is a value rather than part of
+// the route: all digits, or at least three digits among other characters,
+// so names such as "v1_0" or "oauth2" are kept.
+func identifier(segment string) bool {
+	digits := 0
+	for _, r := range segment {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits > 0 && (digits == len(segment) || digits >= 3)
+}
@@ -0,0 +1,130 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+func respond(status int) func(*http.Request) (*http.Response, error) {
+	return func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(`{}`)), Request: r}, nil
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	b := New("airtel", Settings{ConsecutiveFailures: 3, OpenTimeout: 50 * time.Millisecond})
+	req, _ := http.NewRequest(http.MethodPost, "https://openapiuat.airtel.africa/merchant/v1/payments/", nil)
+
+	sent := 0
+	failing := func(r *http.Request) (*http.Response, error) {
+		sent++
+		return respond(http.StatusBadGateway)(r)
+	}
+	for i := 0; i < 5; i++ {
+		b.Do(req, failing)
+	}
+	if sent != 3 {
+		t.Errorf("Expected the circuit to open after 3 failures, sent %d", sent)
+	}
+
+	_, err := b.Do(req, failing)
+	if !errors.Is(err, ErrOpen) || railerrors.CategoryOf(err) != railerrors.CategoryProviderDown {
+		t.Errorf("Expected a provider_down ErrOpen, got %v", err)
+	}
+	if h := b.Health(); h.State != StateOpen || h.Failures != 3 || h.ErrorRate != 1 {
+		t.Errorf("Unexpected health %+v", h)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if h := b.Health(); h.State != StateHalfOpen {
+		t.Errorf("Expected half-open after the timeout, got %s", h.State)
+	}
+	if _, err := b.Do(req, respond(http.StatusOK)); err != nil {
+		t.Fatalf("Expected the probe to be sent, got %v", err)
+	}
+
+	h := b.Health()
+	if h.State != StateClosed || h.LastSuccess.IsZero() {
+		t.Errorf("Expected the circuit to close after a successful probe, got %+v", h)
+	}
+	if endpoint := h.Endpoints["POST /merchant/v1/payments/"]; endpoint.State != StateClosed || endpoint.LastFailure.IsZero() {
+		t.Errorf("Unexpected endpoint health %+v", h.Endpoints)
+	}
+}
+
+func TestBreakerProbeFails(t *testing.T) {
+	b := New("mpesa", Settings{ConsecutiveFailures: 1, OpenTimeout: 20 * time.Millisecond})
+	req, _ := http.NewRequest(http.MethodGet, "https://sandbox.safaricom.co.ke/oauth/v1/generate", nil)
+
+	b.Do(req, respond(http.StatusServiceUnavailable))
+	time.Sleep(30 * time.Millisecond)
+	b.Do(req, respond(http.StatusServiceUnavailable))
+
+	if h := b.Health(); h.State != StateOpen {
+		t.Errorf("Expected a failed probe to open the circuit again, got %s", h.State)
+	}
+}
+
+func TestBreakerErrorRate(t *testing.T) {
+	b := New("momo", Settings{MinRequests: 4, FailureRate: 0.5, ConsecutiveFailures: 100})
+	req, _ := http.NewRequest(http.MethodPost, "https://sandbox.momodeveloper.mtn.com/collection/v1_0/requesttopay", nil)
+
+	// Client errors and cancelled requests do not count as failures
+	b.Do(req, respond(http.StatusBadRequest))
+	b.Do(req, func(*http.Request) (*http.Response, error) { return nil, context.Canceled })
+	b.Do(req, respond(http.StatusInternalServerError))
+	if h := b.Health(); h.State != StateClosed || h.Requests != 2 {
+		t.Errorf("Unexpected health %+v", h)
+	}
+
+	b.Do(req, respond(http.StatusOK))
+	b.Do(req, func(*http.Request) (*http.Response, error) { return nil, errors.New("connection reset") })
+	if h := b.Health(); h.State != StateOpen || h.ErrorRate != 0.5 {
+		t.Errorf("Expected 2 failures in 4 requests to open the circuit, got %+v", h)
+	}
+}
+
+func TestBreakerEndpoint(t *testing.T) {
+	b := New("momo", Settings{ConsecutiveFailures: 2})
+	status, _ := http.NewRequest(http.MethodGet, "https://sandbox.momodeveloper.mtn.com/collection/v1_0/requesttopay/6f1f4b4e-2e1d-4a4f-9d0e-52b4fb3c1a7e", nil)
+	balance, _ := http.NewRequest(http.MethodGet, "https://sandbox.momodeveloper.mtn.com/collection/v1_0/account/balance", nil)
+
+	b.Do(status, respond(http.StatusOK))
+	b.Do(status, respond(http.StatusOK))
+	b.Do(balance, respond(http.StatusInternalServerError))
+	b.Do(status, respond(http.StatusOK))
+	b.Do(balance, respond(http.StatusInternalServerError))
+
+	h := b.Health()
+	if h.Endpoints["GET /collection/v1_0/account/balance"].State != StateOpen {
+		t.Errorf("Expected the balance circuit to be open, got %+v", h.Endpoints)
+	}
+	if h.State != StateClosed || h.Endpoints["GET /collection/v1_0/requesttopay/{id}"].State != StateClosed {
+		t.Errorf("Expected other circuits to stay closed, got %+v", h)
+	}
+	if _, err := b.Do(status, respond(http.StatusOK)); err != nil {
+		t.Errorf("Expected other endpoints to be reachable, got %v", err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://openapiuat.airtel.africa/standard/v1/users/752123456":   "GET /standard/v1/users/{id}",
+		"https://sandbox.safaricom.co.ke/oauth/v1/generate":              "GET /oauth/v1/generate",
+		"https://api.example.com/oauth2/token":                           "GET /oauth2/token",
+		"https://sandbox.momodeveloper.mtn.com/disbursement/v2_0/refund": "GET /disbursement/v2_0/refund",
+	}
+	for url, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if got := Endpoint(req); got != want {
+			t.Errorf("Endpoint(%s) = '%s', expected '%s'", url, got, want)
+		}
+	}
+}
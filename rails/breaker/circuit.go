@@ -0,0 +1,177 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// buckets is how many slices the window is counted in. Requests leave the
+// error rate one slice at a time.
+const buckets = 10
+
+// Health is a snapshot of a provider's circuits: the one for all its
+// requests, and one per endpoint, by Endpoint name.
+type Health struct {
+	Provider string
+	Stats
+	Endpoints map[string]Stats
+}
+
+// Stats is a snapshot of one circuit.
+type Stats struct {
+	State     State
+	Requests  int     // Requests in the window
+	Failures  int     // Failed requests in the window
+	ErrorRate float64 // Failures as a share of Requests, 0 with no requests
+
+	LastSuccess time.Time
+	LastFailure time.Time
+	OpenedAt    time.Time // When the circuit last opened
+}
+
+type bucket struct {
+	start    time.Time
+	requests int
+	failures int
+}
+
+type circuit struct {
+	settings Settings
+
+	mu          sync.Mutex
+	state       State
+	window      [buckets]bucket
+	consecutive int
+	probes      int // Probes in flight while half-open
+	successes   int // Probes that succeeded while half-open
+	openedAt    time.Time
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+func newCircuit(settings Settings) *circuit {
+	return &circuit{settings: settings, state: StateClosed}
+}
+
+// allow reports whether a request may be sent, taking a probe slot if the
+// circuit is half-open. Each allowed request must be followed by record or
+// release.
+func (c *circuit) allow(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == StateOpen && now.Sub(c.openedAt) >= c.settings.OpenTimeout {
+		c.state, c.probes, c.successes = StateHalfOpen, 0, 0
+	}
+	switch c.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if c.probes+c.successes >= c.settings.HalfOpenRequests {
+			return false
+		}
+		c.probes++
+	}
+	return true
+}
+
+// release gives back an allowed request that ended without an outcome,
+// e.g. because the caller cancelled it.
+func (c *circuit) release() {
+	c.mu.Lock()
+	if c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+	c.mu.Unlock()
+}
+
+func (c *circuit) record(now time.Time, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.bucket(now)
+	b.requests++
+	if success {
+		c.lastSuccess = now
+		c.consecutive = 0
+	} else {
+		b.failures++
+		c.lastFailure = now
+		c.consecutive++
+	}
+
+	switch c.state {
+	case StateHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if !success {
+			c.open(now)
+			return
+		}
+		c.successes++
+		if c.successes >= c.settings.HalfOpenRequests {
+			c.state = StateClosed
+			c.window = [buckets]bucket{}
+		}
+	case StateClosed:
+		if success {
+			return
+		}
+		requests, failures := c.counts(now)
+		if c.consecutive >= c.settings.ConsecutiveFailures ||
+			(requests >= c.settings.MinRequests && float64(failures) >= c.settings.FailureRate*float64(requests)) {
+			c.open(now)
+		}
+	}
+}
+
+func (c *circuit) open(now time.Time) {
+	c.state, c.openedAt = StateOpen, now
+	c.probes, c.successes = 0, 0
+}
+
+func (c *circuit) stats(now time.Time) Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.state
+	if state == StateOpen && now.Sub(c.openedAt) >= c.settings.OpenTimeout {
+		state = StateHalfOpen
+	}
+	s := Stats{
+		State:       state,
+		LastSuccess: c.lastSuccess,
+		LastFailure: c.lastFailure,
+		OpenedAt:    c.openedAt,
+	}
+	s.Requests, s.Failures = c.counts(now)
+	if s.Requests > 0 {
+		s.ErrorRate = float64(s.Failures) / float64(s.Requests)
+	}
+	return s
+}
+
+// bucket returns the slice of the window now falls in, clearing it if it
+// last held an earlier slice.
+func (c *circuit) bucket(now time.Time) *bucket {
+	width := c.settings.Window / buckets
+	start := now.Truncate(width)
+	b := &c.window[(start.UnixNano()/int64(width))%buckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+// counts returns the requests and failures in the window.
+func (c *circuit) counts(now time.Time) (requests, failures int) {
+	cutoff := now.Add(-c.settings.Window)
+	for _, b := range c.window {
+		if b.start.After(cutoff) {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
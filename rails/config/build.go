@@ -0,0 +1,173 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nutcas3/payment-rails/absa"
+	"github.com/nutcas3/payment-rails/airtel"
+	"github.com/nutcas3/payment-rails/coop"
+	"github.com/nutcas3/payment-rails/fnb"
+	"github.com/nutcas3/payment-rails/jenga"
+	"github.com/nutcas3/payment-rails/kcb"
+	"github.com/nutcas3/payment-rails/momo"
+	"github.com/nutcas3/payment-rails/mpesa"
+	"github.com/nutcas3/payment-rails/ncba"
+	"github.com/nutcas3/payment-rails/rails/ratelimit"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/webhooks"
+	"github.com/nutcas3/payment-rails/sasapay"
+)
+
+// defaultTimeout is the timeout of HTTP clients built for TLS settings
+// without a timeout, the one most provider packages use.
+const defaultTimeout = 30 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Clients are a tenant's provider clients. Providers the tenant does not
+// configure are nil.
+type Clients struct {
+	Tenant string
+
+	Mpesa   *mpesa.Client
+	Airtel  *airtel.Client
+	Momo    *momo.Client
+	SasaPay *sasapay.Client
+	KCB     *kcb.Client
+	Jenga   *jenga.Client
+	Coop    *coop.Client
+	NCBA    *ncba.Client
+	FNB     *fnb.Client
+	Absa    *absa.Client
+
+	// Webhooks are the webhook sources of the tenant's providers, with
+	// their webhook secrets, ready for webhooks.Gateway.Mount(Tenant, ...).
+	// Providers that sign their callbacks are left out when they have no
+	// webhook_secret, since every callback would fail verification.
+	Webhooks []webhooks.Source
+}
+
+// Build validates c and builds the clients of every tenant, by tenant name.
+func (c *Config) Build() (map[string]*Clients, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	tenants := make(map[string]*Clients, len(c.Tenants))
+	for _, tenant := range sortedKeys(c.Tenants) {
+		clients, err := c.build(tenant)
+		if err != nil {
+			return nil, err
+		}
+		tenants[tenant] = clients
+	}
+	return tenants, nil
+}
+
+// BuildTenant validates c and builds the clients of tenant.
+func (c *Config) BuildTenant(tenant string) (*Clients, error) {
+	if _, found := c.Tenants[tenant]; !found {
+		return nil, fmt.Errorf("config: no tenant %q", tenant)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c.build(tenant)
+}
+
+func (c *Config) build(tenant string) (*Clients, error) {
+	clients := &Clients{Tenant: tenant}
+	for _, name := range sortedKeys(c.Tenants[tenant]) {
+		key := "tenants." + tenant + "." + name
+		p, _ := c.Provider(tenant, name)
+		o, err := newOptions(tenant, name, p)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: %w", key, err)
+		}
+		if err := specs[name].build(clients, o); err != nil {
+			return nil, fmt.Errorf("config: %s: %w", key, err)
+		}
+	}
+	return clients, nil
+}
+
+// newOptions turns p's settings into the HTTP client, retry policy and
+// middleware of tenant's client for provider.
+func newOptions(tenant, provider string, p Provider) (options, error) {
+	o := options{Provider: p}
+
+	if p.Timeout > 0 || p.TLS != nil {
+		httpClient, err := newHTTPClient(time.Duration(p.Timeout), p.TLS)
+		if err != nil {
+			return options{}, err
+		}
+		o.httpClient = httpClient
+	}
+
+	if r := p.Retry; r != nil {
+		policy := retry.DefaultPolicy()
+		if r.MaxAttempts > 0 {
+			policy.MaxAttempts = r.MaxAttempts
+		}
+		if r.BaseDelay > 0 {
+			policy.BaseDelay = time.Duration(r.BaseDelay)
+		}
+		if r.MaxDelay > 0 {
+			policy.MaxDelay = time.Duration(r.MaxDelay)
+		}
+		if len(r.Statuses) > 0 {
+			policy.Statuses = r.Statuses
+		}
+		o.retry = &policy
+	}
+
+	if l := p.RateLimit; l != nil {
+		limits := []ratelimit.Limit{{Provider: provider, Rate: l.Rate, Burst: l.Burst}}
+		for _, operation := range sortedKeys(l.Operations) {
+			limit := l.Operations[operation]
+			limits = append(limits, ratelimit.Limit{Provider: provider, Operation: operation, Rate: limit.Rate, Burst: limit.Burst})
+		}
+		o.middleware = append(o.middleware, ratelimit.New(limits...).Middleware(tenant))
+	}
+	return o, nil
+}
+
+// newHTTPClient returns an HTTP client with timeout and the TLS settings t,
+// which may be nil.
+func newHTTPClient(timeout time.Duration, t *TLS) (*http.Client, error) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if t != nil {
+		tlsConfig := &tls.Config{MinVersion: tlsVersions[t.MinVersion]}
+		if t.CAFile != "" {
+			pem, err := os.ReadFile(t.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("tls.ca_file: %w", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("tls.ca_file: no PEM certificates in %s", t.CAFile)
+			}
+			tlsConfig.RootCAs = roots
+		}
+		if t.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("tls.cert_file: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Timeout: timeout, Transport: base}, nil
+}
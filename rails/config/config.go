@@ -0,0 +1,350 @@
+// Package config builds fully configured provider clients from a YAML or
+// JSON file, with environment variable overrides:
+//
+//	defaults:
+//	  environment: sandbox
+//	  timeout: 30s
+//	  retry: {max_attempts: 3}
+//	tenants:
+//	  acme:
+//	    mpesa:
+//	      credentials: {consumer_key: ..., consumer_secret: ..., passkey: ...}
+//	      rate_limit: {rate: 10}
+//	    jenga:
+//	      environment: production
+//	      webhook_secret: ...
+//	      credentials: {api_key: ..., username: ..., password: ..., private_key: ...}
+//
+//	cfg, err := config.Load("rails.yaml")
+//	clients, err := cfg.Build()
+//	clients["acme"].Mpesa.STKPushWithContext(ctx, ...)
+//
+// Each provider's settings override the defaults. Any key can be overridden
+// by an environment variable named after its path, in upper case, with "__"
+// between keys and EnvPrefix in front, e.g.
+// PAYMENT_RAILS__TENANTS__ACME__MPESA__CREDENTIALS__PASSKEY, so secrets need
+// not be written to the file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalid is returned, wrapped with the key at fault, for configurations
+// that fail to parse or validate.
+var ErrInvalid = errors.New("config: invalid configuration")
+
+// EnvPrefix is the prefix of the environment variables Load reads
+// overrides from.
+const EnvPrefix = "PAYMENT_RAILS"
+
+// Environments a provider can be configured for.
+const (
+	Sandbox    = "sandbox"
+	Production = "production"
+)
+
+// Config is the configuration of every tenant's providers.
+type Config struct {
+	// Defaults apply to every provider, unless the provider sets its own.
+	Defaults Settings `yaml:"defaults" json:"defaults"`
+
+	// Tenants maps tenant names, e.g. merchants or credential sets, to
+	// their providers.
+	Tenants map[string]Tenant `yaml:"tenants" json:"tenants"`
+}
+
+// Tenant maps provider names, e.g. "mpesa", to their configuration.
+type Tenant map[string]Provider
+
+// Provider is the configuration of one provider for a tenant.
+type Provider struct {
+	Settings `yaml:",inline"`
+
+	// Credentials are the provider's credentials and account details, see
+	// the README for the keys each provider takes.
+	Credentials map[string]string `yaml:"credentials" json:"credentials"`
+}
+
+// Settings are the client settings shared by every provider.
+type Settings struct {
+	Environment string `yaml:"environment" json:"environment"` // Sandbox or Production, Sandbox by default
+	BaseURL     string `yaml:"base_url" json:"base_url"`       // Overrides the environment's base URL
+
+	// Timeout limits each HTTP request, including reading the response.
+	// The provider package's default is kept if it is not set.
+	Timeout Duration `yaml:"timeout" json:"timeout"`
+
+	TLS           *TLS       `yaml:"tls" json:"tls"`
+	Retry         *Retry     `yaml:"retry" json:"retry"`
+	RateLimit     *RateLimit `yaml:"rate_limit" json:"rate_limit"`
+	WebhookSecret string     `yaml:"webhook_secret" json:"webhook_secret"` // Token for M-Pesa and MoMo, which do not sign callbacks
+}
+
+// TLS configures the connections to a provider.
+type TLS struct {
+	CAFile   string `yaml:"ca_file" json:"ca_file"`     // PEM certificates trusted instead of the system roots
+	CertFile string `yaml:"cert_file" json:"cert_file"` // PEM client certificate, for mutual TLS
+	KeyFile  string `yaml:"key_file" json:"key_file"`   // PEM key of the client certificate
+
+	// MinVersion is the lowest TLS version accepted, "1.2" by default.
+	MinVersion string `yaml:"min_version" json:"min_version"`
+}
+
+// Retry configures the retry.Policy. Unset fields keep the values of
+// retry.DefaultPolicy().
+type Retry struct {
+	MaxAttempts int      `yaml:"max_attempts" json:"max_attempts"` // 1 sends each request once
+	BaseDelay   Duration `yaml:"base_delay" json:"base_delay"`
+	MaxDelay    Duration `yaml:"max_delay" json:"max_delay"`
+	Statuses    []int    `yaml:"statuses" json:"statuses"`
+}
+
+// RateLimit limits the requests a tenant sends to a provider.
+type RateLimit struct {
+	Limit `yaml:",inline"`
+
+	// Operations limits some operations further, by operation name or URL
+	// path prefix as in ratelimit.Limit.Operation, e.g. "/mpesa/b2c/".
+	Operations map[string]Limit `yaml:"operations" json:"operations"`
+}
+
+// Limit is a request rate.
+type Limit struct {
+	Rate  float64 `yaml:"rate" json:"rate"`   // Requests per second, no limit when not positive
+	Burst int     `yaml:"burst" json:"burst"` // The rate rounded up by default
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "1m30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected e.g. \"30s\"", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalYAML refuses bare numbers, whose unit would be a guess.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+		return fmt.Errorf("line %d: invalid duration %q, expected e.g. \"30s\"", value.Line, value.Value)
+	}
+	return d.UnmarshalText([]byte(value.Value))
+}
+
+// UnmarshalJSON refuses bare numbers, whose unit would be a guess.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s, expected e.g. \"30s\"", data)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Load reads the configuration in the file at path, applies overrides from
+// environment variables starting with EnvPrefix, and validates it. Files
+// ending in ".json" are read as JSON, others as YAML.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	var cfg *Config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		cfg, err = ParseJSON(data)
+	} else {
+		cfg, err = ParseYAML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Override(EnvPrefix, os.Environ()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseYAML parses a YAML configuration. Unknown keys are errors, so typos
+// are not silently ignored.
+func ParseYAML(data []byte) (*Config, error) {
+	var cfg Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return &cfg, nil
+}
+
+// ParseJSON parses a JSON configuration. Unknown keys are errors, so typos
+// are not silently ignored.
+func ParseJSON(data []byte) (*Config, error) {
+	var cfg Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return &cfg, nil
+}
+
+// Provider returns the settings of tenant's provider, with the defaults
+// filled in, and whether it is configured.
+func (c *Config) Provider(tenant, provider string) (Provider, bool) {
+	p, found := c.Tenants[tenant][provider]
+	if !found {
+		return Provider{}, false
+	}
+	p.Settings = p.Settings.over(c.Defaults)
+	return p, true
+}
+
+// over returns s with its unset fields taken from defaults.
+func (s Settings) over(defaults Settings) Settings {
+	if s.Environment == "" {
+		s.Environment = defaults.Environment
+	}
+	if s.Environment == "" {
+		s.Environment = Sandbox
+	}
+	if s.BaseURL == "" {
+		s.BaseURL = defaults.BaseURL
+	}
+	if s.Timeout == 0 {
+		s.Timeout = defaults.Timeout
+	}
+	if s.TLS == nil {
+		s.TLS = defaults.TLS
+	}
+	if s.Retry == nil {
+		s.Retry = defaults.Retry
+	}
+	if s.RateLimit == nil {
+		s.RateLimit = defaults.RateLimit
+	}
+	if s.WebhookSecret == "" {
+		s.WebhookSecret = defaults.WebhookSecret
+	}
+	return s
+}
+
+// Validate checks every key, returning an error wrapping ErrInvalid for each
+// bad one.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s %s", ErrInvalid, key, fmt.Sprintf(format, args...)))
+	}
+
+	c.Defaults.validate("defaults", invalid)
+	if len(c.Tenants) == 0 {
+		invalid("tenants", "must list at least one tenant")
+	}
+	for _, tenant := range sortedKeys(c.Tenants) {
+		providers := c.Tenants[tenant]
+		if len(providers) == 0 {
+			invalid("tenants."+tenant, "must configure at least one provider")
+		}
+		for _, name := range sortedKeys(providers) {
+			key := "tenants." + tenant + "." + name
+			s, found := specs[name]
+			if !found {
+				invalid(key, "is not a provider, expected one of %s", strings.Join(sortedKeys(specs), ", "))
+				continue
+			}
+			p := providers[name]
+			if s.oneServer && p.Environment != "" {
+				invalid(key+".environment", "is not supported, %s has one server; set base_url to use another", name)
+			}
+			p.Settings.validate(key, invalid)
+			s.validate(key+".credentials", name, p.Credentials, invalid)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s Settings) validate(key string, invalid func(key, format string, args ...any)) {
+	switch s.Environment {
+	case "", Sandbox, Production:
+	default:
+		invalid(key+".environment", "must be %s or %s, got %q", Sandbox, Production, s.Environment)
+	}
+	if s.BaseURL != "" {
+		if u, err := url.Parse(s.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid(key+".base_url", "must be an http or https URL, got %q", s.BaseURL)
+		}
+	}
+	if s.Timeout < 0 {
+		invalid(key+".timeout", "must not be negative")
+	}
+	if t := s.TLS; t != nil {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			invalid(key+".tls", "needs both cert_file and key_file for a client certificate")
+		}
+		if _, found := tlsVersions[t.MinVersion]; !found {
+			invalid(key+".tls.min_version", "must be one of 1.2 or 1.3, got %q", t.MinVersion)
+		}
+	}
+	if r := s.Retry; r != nil {
+		if r.MaxAttempts < 0 {
+			invalid(key+".retry.max_attempts", "must not be negative")
+		}
+		if r.BaseDelay < 0 || r.MaxDelay < 0 {
+			invalid(key+".retry", "delays must not be negative")
+		}
+		for _, status := range r.Statuses {
+			if status < 100 || status > 599 {
+				invalid(key+".retry.statuses", "has invalid HTTP status %d", status)
+			}
+		}
+	}
+	if l := s.RateLimit; l != nil {
+		l.Limit.validate(key+".rate_limit", invalid)
+		for _, operation := range sortedKeys(l.Operations) {
+			if operation == "" {
+				invalid(key+".rate_limit.operations", "has an empty operation")
+			}
+			l.Operations[operation].validate(key+".rate_limit.operations."+operation, invalid)
+		}
+	}
+}
+
+func (l Limit) validate(key string, invalid func(key, format string, args ...any)) {
+	if l.Rate < 0 {
+		invalid(key+".rate", "must not be negative")
+	}
+	if l.Burst < 0 {
+		invalid(key+".burst", "must not be negative")
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
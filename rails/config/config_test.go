@@ -0,0 +1,265 @@
+package config
+
+import (
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/webhooks"
+)
+
+const testYAML = `
+defaults:
+  timeout: 20s
+  retry:
+    max_attempts: 2
+tenants:
+  acme:
+    mpesa:
+      webhook_secret: callback-token
+      rate_limit:
+        rate: 10
+        operations:
+          /mpesa/b2c/: {rate: 2}
+      credentials:
+        consumer_key: key
+        consumer_secret: secret
+        passkey: passkey
+    jenga:
+      environment: production
+      webhook_secret: jenga-secret
+      credentials: {api_key: key, username: merchant, password: secret, private_key: pem}
+  globex:
+    momo:
+      credentials:
+        api_key: user
+        api_secret: secret
+        collection_subscription_key: sub
+    ncba:
+      credentials: {api_key: key, username: user, password: pass}
+    fnb:
+      credentials: {client_id: id, client_secret: secret, api_key: key}
+`
+
+func TestBuild(t *testing.T) {
+	cfg, err := ParseYAML([]byte(testYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+	tenants, err := cfg.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	acme := tenants["acme"]
+	if acme == nil || acme.Mpesa == nil || acme.Jenga == nil {
+		t.Fatalf("Expected M-Pesa and Jenga clients for acme, got %+v", acme)
+	}
+	if acme.Momo != nil || acme.NCBA != nil {
+		t.Error("Expected no clients for providers acme does not configure")
+	}
+	if len(acme.Webhooks) != 2 {
+		t.Errorf("Expected 2 webhook sources for acme, got %d", len(acme.Webhooks))
+	}
+	globex := tenants["globex"]
+	if globex.Momo == nil || globex.Momo.Collection == nil || globex.NCBA == nil || globex.FNB == nil {
+		t.Errorf("Expected MoMo collection, NCBA and FNB clients for globex, got %+v", globex)
+	}
+	// FNB signs its callbacks, and has no webhook secret to check them with
+	momo := false
+	if len(globex.Webhooks) == 1 {
+		_, momo = globex.Webhooks[0].(webhooks.Momo)
+	}
+	if !momo {
+		t.Errorf("Expected only the MoMo webhook source for globex, got %+v", globex.Webhooks)
+	}
+
+	p, _ := cfg.Provider("acme", "jenga")
+	if p.Environment != Production || time.Duration(p.Timeout) != 20*time.Second || p.Retry.MaxAttempts != 2 {
+		t.Errorf("Expected jenga to override the environment and keep the defaults, got %+v", p.Settings)
+	}
+	if p, _ := cfg.Provider("acme", "mpesa"); p.Environment != Sandbox {
+		t.Errorf("Expected the sandbox by default, got %q", p.Environment)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := ParseJSON([]byte(`{
+		"tenants": {"acme": {"kcb": {"timeout": "5s", "credentials": {"token": "t"}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if p, _ := cfg.Provider("acme", "kcb"); time.Duration(p.Timeout) != 5*time.Second {
+		t.Errorf("Expected a 5s timeout, got %v", time.Duration(p.Timeout))
+	}
+	if _, err := cfg.Build(); err != nil {
+		t.Errorf("Build failed: %v", err)
+	}
+
+	if _, err := ParseJSON([]byte(`{"tenants": {"acme": {"kcb": {"timeout": 5}}}}`)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a duration without a unit, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := ParseYAML([]byte(`
+defaults:
+  environment: prod
+tenants:
+  acme:
+    mpsea:
+      credentials: {consumer_key: key}
+    mpesa:
+      base_url: localhost:8080
+      tls: {cert_file: client.pem}
+      retry: {statuses: [700]}
+      credentials: {consumer_key: key, consumer_secret: secret, shortcode: "174379"}
+    momo:
+      credentials: {api_key: user, api_secret: secret}
+    ncba:
+      environment: production
+      credentials: {api_key: key, username: user, password: pass}
+`))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	err = cfg.Validate()
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Expected ErrInvalid, got %v", err)
+	}
+	for _, want := range []string{
+		"defaults.environment must be sandbox or production",
+		"tenants.acme.mpsea is not a provider",
+		"tenants.acme.mpesa.base_url must be an http or https URL",
+		"tenants.acme.mpesa.tls needs both cert_file and key_file",
+		"tenants.acme.mpesa.retry.statuses has invalid HTTP status 700",
+		"tenants.acme.mpesa.credentials.passkey is required",
+		"tenants.acme.mpesa.credentials.shortcode is not a mpesa credential",
+		"tenants.acme.momo.credentials needs at least one of collection_subscription_key",
+		"tenants.acme.ncba.environment is not supported, ncba has one server",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to contain %q, got:\n%v", want, err)
+		}
+	}
+
+	if _, err := cfg.Build(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected Build to validate, got %v", err)
+	}
+
+	_, err = ParseYAML([]byte("tenants:\n  acme:\n    mpesa:\n      timeout_seconds: 5\n"))
+	if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "timeout_seconds") {
+		t.Errorf("Expected an error naming the unknown key, got %v", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	cfg, err := ParseYAML([]byte(`
+tenants:
+  Acme:
+    mpesa:
+      credentials: {consumer_key: key, consumer_secret: secret}
+`))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	err = cfg.Override("PAYMENT_RAILS", []string{
+		"PAYMENT_RAILS__TENANTS__ACME__MPESA__CREDENTIALS__PASSKEY=passkey",
+		"PAYMENT_RAILS__TENANTS__ACME__MPESA__RETRY__STATUSES=502, 503",
+		"PAYMENT_RAILS__DEFAULTS__TIMEOUT=45s",
+		"PAYMENT_RAILS__DEFAULTS__RATE_LIMIT__RATE=2.5",
+		"PAYMENT_RAILS__TENANTS__BETA__KCB__CREDENTIALS__TOKEN=token",
+		"OTHER__TENANTS__ACME__MPESA__ENVIRONMENT=production",
+	})
+	if err != nil {
+		t.Fatalf("Override failed: %v", err)
+	}
+
+	p, _ := cfg.Provider("Acme", "mpesa")
+	if p.Credentials["passkey"] != "passkey" {
+		t.Errorf("Expected the passkey from the environment, got %q", p.Credentials["passkey"])
+	}
+	if got := p.Retry.Statuses; len(got) != 2 || got[0] != 502 || got[1] != 503 {
+		t.Errorf("Expected statuses [502 503], got %v", got)
+	}
+	if time.Duration(p.Timeout) != 45*time.Second || p.RateLimit.Rate != 2.5 {
+		t.Errorf("Expected the defaults from the environment, got %+v", p.Settings)
+	}
+	if p.Environment != Sandbox {
+		t.Errorf("Expected variables without the prefix to be ignored, got %q", p.Environment)
+	}
+	if p, found := cfg.Provider("beta", "kcb"); !found || p.Credentials["token"] != "token" {
+		t.Errorf("Expected a tenant added from the environment, got %+v", p)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+
+	for _, name := range []string{
+		"PAYMENT_RAILS__TENANTS__ACME__MPESA__TIMEOUT_SECONDS",
+		"PAYMENT_RAILS__DEFAULTS__RETRY",
+		"PAYMENT_RAILS__DEFAULTS__RETRY__MAX_ATTEMPTS",
+	} {
+		err := cfg.Override("PAYMENT_RAILS", []string{name + "=x"})
+		if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected an error naming %s, got %v", name, err)
+		}
+	}
+}
+
+func TestBuildBaseURLAndTLS(t *testing.T) {
+	var requests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !strings.HasPrefix(r.URL.Path, "/oauth/v1/generate") {
+			t.Errorf("Expected a token request, got %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"access_token": "token", "expires_in": "3599"}`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Tenants: map[string]Tenant{"acme": {"mpesa": {
+		Settings: Settings{BaseURL: server.URL, TLS: &TLS{CAFile: caFile}},
+		Credentials: map[string]string{
+			"consumer_key": "key", "consumer_secret": "secret", "passkey": "passkey",
+		},
+	}}}}
+	clients, err := cfg.BuildTenant("acme")
+	if err != nil {
+		t.Fatalf("BuildTenant failed: %v", err)
+	}
+
+	token, err := clients.Mpesa.GetAuthToken()
+	if err != nil {
+		t.Fatalf("GetAuthToken failed: %v", err)
+	}
+	if token != "token" || requests != 1 {
+		t.Errorf("Expected one token request to the test server, got %d and token %q", requests, token)
+	}
+
+	cfg.Tenants["acme"]["mpesa"] = Provider{
+		Settings:    Settings{TLS: &TLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		Credentials: cfg.Tenants["acme"]["mpesa"].Credentials,
+	}
+	if _, err := cfg.BuildTenant("acme"); err == nil || !strings.Contains(err.Error(), "tenants.acme.mpesa: tls.ca_file") {
+		t.Errorf("Expected an error naming the CA file key, got %v", err)
+	}
+	if _, err := cfg.BuildTenant("globex"); err == nil {
+		t.Error("Expected an error building an unknown tenant")
+	}
+}
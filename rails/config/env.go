@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Override sets keys from the environment variables in environ, given as
+// "KEY=value" as by os.Environ, named prefix, "__" and the key's path in
+// upper case with "__" between keys:
+//
+//	PAYMENT_RAILS__DEFAULTS__TIMEOUT=45s
+//	PAYMENT_RAILS__TENANTS__ACME__MPESA__CREDENTIALS__PASSKEY=...
+//	PAYMENT_RAILS__TENANTS__ACME__MPESA__RETRY__STATUSES=502,503
+//
+// Tenants, providers and credentials are matched ignoring case, and added
+// in lower case if the file does not have them. Lists are separated by
+// commas.
+func (c *Config) Override(prefix string, environ []string) error {
+	prefix += "__"
+	for _, kv := range environ {
+		name, value, found := strings.Cut(kv, "=")
+		if !found || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(name, prefix)), "__")
+		if err := set(reflect.ValueOf(c).Elem(), path, value, ""); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalid, name, err)
+		}
+	}
+	return nil
+}
+
+var textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// set sets the key at path under v, which is settable, to value. key is the
+// path to v, for errors.
+func set(v reflect.Value, path []string, value, key string) error {
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map && v.Kind() != reflect.Pointer && len(path) > 0 {
+		return fmt.Errorf("%s has no key %s", key, path[0])
+	}
+	if len(path) > 0 && path[0] == "" {
+		return fmt.Errorf("empty key after %s", key)
+	}
+
+	switch {
+	case len(path) == 0 && reflect.PointerTo(v.Type()).Implements(textUnmarshaler):
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return set(v.Elem(), path, value, key)
+	case v.Kind() == reflect.Struct:
+		if len(path) == 0 {
+			return fmt.Errorf("%s is a section, not a value", key)
+		}
+		field, found := fieldByKey(v, path[0])
+		if !found {
+			return fmt.Errorf("%s is not a key", join(key, path[0]))
+		}
+		return set(field, path[1:], value, join(key, path[0]))
+	case v.Kind() == reflect.Map:
+		if len(path) == 0 {
+			return fmt.Errorf("%s is a section, not a value", key)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		name := path[0]
+		for _, k := range v.MapKeys() {
+			if strings.EqualFold(k.String(), name) {
+				name = k.String()
+			}
+		}
+		// Map elements are not addressable, so set a copy and put it back
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(reflect.ValueOf(name)); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := set(elem, path[1:], value, join(key, name)); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+		return nil
+	}
+	return setValue(v, value, key)
+}
+
+// setValue parses value into v, a string, number, bool or list.
+func setValue(v reflect.Value, value, key string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", key, value)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", key, value)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		items := strings.Split(value, ",")
+		list := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(list.Index(i), strings.TrimSpace(item), key); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+	default:
+		return fmt.Errorf("%s cannot be set from the environment", key)
+	}
+	return nil
+}
+
+// fieldByKey returns the field of struct v with the YAML key name, looking
+// into inline fields.
+func fieldByKey(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		tag := v.Type().Field(i).Tag.Get("yaml")
+		key, flags, _ := strings.Cut(tag, ",")
+		if flags == "inline" {
+			if field, found := fieldByKey(v.Field(i), name); found {
+				return field, true
+			}
+			continue
+		}
+		if key == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func join(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/absa"
+	"github.com/nutcas3/payment-rails/airtel"
+	"github.com/nutcas3/payment-rails/coop"
+	coopapi "github.com/nutcas3/payment-rails/coop/pkg/api"
+	"github.com/nutcas3/payment-rails/fnb"
+	fnbapi "github.com/nutcas3/payment-rails/fnb/pkg/api"
+	"github.com/nutcas3/payment-rails/jenga"
+	"github.com/nutcas3/payment-rails/kcb"
+	"github.com/nutcas3/payment-rails/momo"
+	"github.com/nutcas3/payment-rails/mpesa"
+	"github.com/nutcas3/payment-rails/ncba"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
+	"github.com/nutcas3/payment-rails/rails/webhooks"
+	"github.com/nutcas3/payment-rails/sasapay"
+)
+
+// spec is what a provider takes: its credential keys, and how its client is
+// built.
+type spec struct {
+	required []string
+	optional []string
+	oneOf    []string // At least one of these is required
+	build    func(c *Clients, o options) error
+
+	// oneServer is set for providers with a single server, which take no
+	// environment; base_url selects another server.
+	oneServer bool
+}
+
+// specs are the providers a Config can configure, by name.
+var specs = map[string]spec{
+	string(rails.ProviderMpesa): {
+		required: []string{"consumer_key", "consumer_secret", "passkey"},
+		build: func(c *Clients, o options) error {
+			client, err := mpesa.NewClient(o.credential("consumer_key"), o.credential("consumer_secret"), o.credential("passkey"), mpesa.Environment(o.Environment))
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			c.Mpesa = client
+			c.Webhooks = append(c.Webhooks, webhooks.Mpesa{Token: o.WebhookSecret})
+			return nil
+		},
+	},
+	string(rails.ProviderAirtel): {
+		required: []string{"client_id", "client_secret", "country", "currency"},
+		optional: []string{"public_key"},
+		build: func(c *Clients, o options) error {
+			client, err := airtel.New(o.credential("client_id"), o.credential("client_secret"), o.credential("public_key"), o.Environment != Production, o.credential("country"), o.credential("currency"))
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			c.Airtel = client
+			if o.WebhookSecret != "" {
+				c.Webhooks = append(c.Webhooks, webhooks.Airtel{Secret: o.WebhookSecret})
+			}
+			return nil
+		},
+	},
+	string(rails.ProviderMomo): {
+		required: []string{"api_key", "api_secret"},
+		oneOf:    []string{"collection_subscription_key", "disbursement_subscription_key", "remittance_subscription_key"},
+		build: func(c *Clients, o options) error {
+			client, err := momo.New(momo.ClientConfig{
+				Environment:                 o.Environment,
+				BaseURL:                     o.BaseURL,
+				APIKey:                      o.credential("api_key"),
+				APISecret:                   o.credential("api_secret"),
+				CollectionSubscriptionKey:   o.credential("collection_subscription_key"),
+				DisbursementSubscriptionKey: o.credential("disbursement_subscription_key"),
+				RemittanceSubscriptionKey:   o.credential("remittance_subscription_key"),
+				HTTPClient:                  o.httpClient,
+				RetryPolicy:                 o.retry,
+				Middleware:                  o.middleware,
+			})
+			if err != nil {
+				return err
+			}
+			c.Momo = client
+			c.Webhooks = append(c.Webhooks, webhooks.Momo{Token: o.WebhookSecret})
+			return nil
+		},
+	},
+	string(rails.ProviderSasaPay): {
+		required: []string{"client_id", "client_secret"},
+		build: func(c *Clients, o options) error {
+			client, err := sasapay.NewClient(o.credential("client_id"), o.credential("client_secret"), o.Environment)
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			if o.WebhookSecret != "" {
+				client.SetWebhookSecret(o.WebhookSecret)
+				c.Webhooks = append(c.Webhooks, webhooks.SasaPay{Secret: o.WebhookSecret})
+			}
+			c.SasaPay = client
+			return nil
+		},
+	},
+	string(rails.ProviderKCB): {
+		required: []string{"token"},
+		build: func(c *Clients, o options) error {
+			client, err := kcb.New(o.credential("token"), o.Environment != Production)
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			c.KCB = client
+			return nil
+		},
+	},
+	string(rails.ProviderJenga): {
+		required: []string{"api_key", "username", "password", "private_key"},
+		build: func(c *Clients, o options) error {
+			client, err := jenga.NewClient(o.credential("api_key"), o.credential("username"), o.credential("password"), o.credential("private_key"), o.Environment)
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			if o.WebhookSecret != "" {
+				client.SetWebhookSecret(o.WebhookSecret)
+				c.Webhooks = append(c.Webhooks, webhooks.Jenga{Secret: o.WebhookSecret})
+			}
+			c.Jenga = client
+			return nil
+		},
+	},
+	string(rails.ProviderCoop): {
+		required: []string{"client_id", "client_secret"},
+		build: func(c *Clients, o options) error {
+			client, err := coop.NewClient(o.credential("client_id"), o.credential("client_secret"), coopapi.Environment(o.Environment))
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			c.Coop = client
+			return nil
+		},
+	},
+	string(rails.ProviderNCBA): {
+		required:  []string{"api_key", "username", "password"},
+		oneServer: true,
+		build: func(c *Clients, o options) error {
+			client := ncba.NewClient(o.credential("api_key"), o.credential("username"), o.credential("password"))
+			o.configure(client)
+			c.NCBA = client
+			return nil
+		},
+	},
+	string(rails.ProviderFNB): {
+		required: []string{"client_id", "client_secret", "api_key"},
+		build: func(c *Clients, o options) error {
+			client := &fnb.Client{Client: fnbapi.NewClient(&fnbapi.ClientConfig{
+				ClientID:     o.credential("client_id"),
+				ClientSecret: o.credential("client_secret"),
+				APIKey:       o.credential("api_key"),
+				Environment:  o.Environment,
+				BaseURL:      o.BaseURL,
+				RetryPolicy:  o.retry,
+			})}
+			if o.httpClient != nil {
+				client.SetHttpClient(o.httpClient)
+			}
+			client.Use(o.middleware...)
+			c.FNB = client
+			if o.WebhookSecret != "" {
+				c.Webhooks = append(c.Webhooks, webhooks.FNB{Secret: o.WebhookSecret})
+			}
+			return nil
+		},
+	},
+	string(rails.ProviderAbsa): {
+		required: []string{"client_id", "client_secret", "api_key"},
+		build: func(c *Clients, o options) error {
+			client, err := absa.NewClient(o.credential("client_id"), o.credential("client_secret"), o.credential("api_key"), o.Environment)
+			if err != nil {
+				return err
+			}
+			o.configure(client)
+			if o.WebhookSecret != "" {
+				client.SetWebhookSecret(o.WebhookSecret)
+				c.Webhooks = append(c.Webhooks, webhooks.Absa{Secret: o.WebhookSecret})
+			}
+			c.Absa = client
+			return nil
+		},
+	},
+}
+
+// validate checks credentials has the keys s takes, and only those.
+func (s spec) validate(key, provider string, credentials map[string]string, invalid func(key, format string, args ...any)) {
+	for _, name := range s.required {
+		if credentials[name] == "" {
+			invalid(key+"."+name, "is required")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		found := false
+		for _, name := range s.oneOf {
+			found = found || credentials[name] != ""
+		}
+		if !found {
+			invalid(key, "needs at least one of %s", strings.Join(s.oneOf, ", "))
+		}
+	}
+	for _, name := range sortedKeys(credentials) {
+		if !s.takes(name) {
+			invalid(key+"."+name, "is not a %s credential", provider)
+		}
+	}
+}
+
+func (s spec) takes(name string) bool {
+	return slices.Contains(s.required, name) || slices.Contains(s.optional, name) || slices.Contains(s.oneOf, name)
+}
+
+// options are a provider's merged settings, ready to apply to its client.
+type options struct {
+	Provider
+
+	httpClient *http.Client  // Nil to keep the package's client
+	retry      *retry.Policy // Nil to keep the package's policy
+	middleware []transport.Middleware
+}
+
+func (o options) credential(name string) string {
+	return o.Credentials[name]
+}
+
+// configurable is a client with the setters most provider clients share.
+type configurable interface {
+	SetHttpClient(httpClient *http.Client)
+	SetBaseURL(baseURL string)
+	SetRetryPolicy(policy retry.Policy)
+	Use(middleware ...transport.Middleware)
+}
+
+// configure applies o to client. The HTTP client goes first, since Use
+// installs the middleware on it.
+func (o options) configure(client configurable) {
+	if o.httpClient != nil {
+		client.SetHttpClient(o.httpClient)
+	}
+	if o.BaseURL != "" {
+		client.SetBaseURL(o.BaseURL)
+	}
+	if o.retry != nil {
+		client.SetRetryPolicy(*o.retry)
+	}
+	client.Use(o.middleware...)
+}
@@ -0,0 +1,217 @@
+// Package errors defines ProviderError, which every provider client returns
+// (possibly wrapped) when a provider rejects a request, so callers can branch
+// on what went wrong without parsing provider-specific messages:
+//
+//	var perr *errors.ProviderError
+//	if stderrors.As(err, &perr) && perr.Category == errors.CategoryInsufficientFunds {
+//		// ask the customer to top up
+//	}
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Category is the normalized reason a provider rejected a request.
+type Category string
+
+const (
+	CategoryUnknown           Category = "unknown"            // Not recognized
+	CategoryAuth              Category = "auth"               // Credentials or access token refused
+	CategoryValidation        Category = "validation"         // The request is malformed or has invalid values, e.g. an MSISDN
+	CategoryInsufficientFunds Category = "insufficient_funds" // The payer cannot cover the amount
+	CategoryDuplicate         Category = "duplicate"          // The request repeats one already accepted or in progress
+	CategoryNotFound          Category = "not_found"          // The account, transaction or resource does not exist
+	CategoryRateLimited       Category = "rate_limited"       // Too many requests, try again later
+	CategoryProviderDown      Category = "provider_down"      // The provider failed or is unavailable
+)
+
+// Retryable reports whether a request rejected for this reason may succeed
+// if sent again later without changes.
+func (c Category) Retryable() bool {
+	return c == CategoryRateLimited || c == CategoryProviderDown
+}
+
+// RequestIDHeaders are the response headers providers use to identify a
+// request in their logs, in the order they are checked.
+var RequestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid", "Request-Id"}
+
+// ProviderError is a request rejected by a provider.
+type ProviderError struct {
+	Provider   string
+	StatusCode int
+	Code       string // The provider's own error code, if any
+	Message    string
+	RequestID  string
+	Retryable  bool
+	Category   Category
+
+	// Err is the provider-specific error, such as an SDK ErrorResponse, if
+	// there is one. errors.As can still reach it through the ProviderError.
+	Err error
+}
+
+// New returns a ProviderError categorized by status and message.
+func New(provider string, status int, code, message string) *ProviderError {
+	category := Classify(status, message)
+	return &ProviderError{
+		Provider:   provider,
+		StatusCode: status,
+		Code:       code,
+		Message:    message,
+		Retryable:  category.Retryable(),
+		Category:   category,
+	}
+}
+
+// FromResponse returns a ProviderError for resp, taking the request ID from
+// RequestIDHeaders. The response body is not read.
+func FromResponse(provider string, resp *http.Response, code, message string) *ProviderError {
+	e := New(provider, resp.StatusCode, code, message)
+	for _, h := range RequestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	return e
+}
+
+// SetCategory overrides the category, e.g. from a provider error code, and
+// updates Retryable to match.
+func (e *ProviderError) SetCategory(c Category) {
+	e.Category = c
+	e.Retryable = c.Retryable()
+}
+
+// AuthFailure marks a failed token request as CategoryAuth and returns e.
+// Client errors from an auth endpoint mean the credentials were refused,
+// whatever the status or message, while rate limits and outages keep their
+// category.
+func (e *ProviderError) AuthFailure() *ProviderError {
+	if !e.Retryable {
+		e.SetCategory(CategoryAuth)
+	}
+	return e
+}
+
+func (e *ProviderError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Provider)
+	if e.Category != CategoryUnknown && e.Category != "" {
+		b.WriteString(" " + strings.ReplaceAll(string(e.Category), "_", " "))
+	}
+	b.WriteString(" error: ")
+
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	b.WriteString(message)
+
+	details := []string{fmt.Sprintf("status %d", e.StatusCode)}
+	if e.Code != "" {
+		details = append(details, "code "+e.Code)
+	}
+	if e.RequestID != "" {
+		details = append(details, "request "+e.RequestID)
+	}
+	b.WriteString(" (" + strings.Join(details, ", ") + ")")
+
+	return b.String()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// As returns the ProviderError in err's chain, if any.
+func As(err error) (*ProviderError, bool) {
+	var perr *ProviderError
+	ok := stderrors.As(err, &perr)
+	return perr, ok
+}
+
+// CategoryOf returns the category of the ProviderError in err's chain, or
+// CategoryUnknown.
+func CategoryOf(err error) Category {
+	if perr, ok := As(err); ok {
+		return perr.Category
+	}
+	return CategoryUnknown
+}
+
+// IsRetryable reports whether err is a ProviderError marked retryable.
+func IsRetryable(err error) bool {
+	perr, ok := As(err)
+	return ok && perr.Retryable
+}
+
+// messageCategories maps phrases in provider messages to a category, for
+// client errors whose status alone does not say what went wrong. They are
+// checked in order.
+var messageCategories = []struct {
+	phrase   string
+	category Category
+}{
+	{"insufficient", CategoryInsufficientFunds},
+	{"not enough funds", CategoryInsufficientFunds},
+	{"duplicate", CategoryDuplicate},
+	{"already exists", CategoryDuplicate},
+	{"already in process", CategoryDuplicate},
+	{"already processed", CategoryDuplicate},
+	{"not found", CategoryNotFound},
+	{"does not exist", CategoryNotFound},
+	{"invalid access token", CategoryAuth},
+	{"invalid token", CategoryAuth},
+	{"expired token", CategoryAuth},
+	{"token expired", CategoryAuth},
+	{"unauthorized", CategoryAuth},
+	{"too many requests", CategoryRateLimited},
+	{"rate limit", CategoryRateLimited},
+	{"quota", CategoryRateLimited},
+	{"spike arrest", CategoryRateLimited},
+}
+
+// Classify returns the category for a response status and message.
+func Classify(status int, message string) Category {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return CategoryRateLimited
+	case status >= 500:
+		if c := classifyMessage(message); c == CategoryRateLimited || c == CategoryDuplicate {
+			return c
+		}
+		return CategoryProviderDown
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return CategoryAuth
+	case status == http.StatusPaymentRequired:
+		return CategoryInsufficientFunds
+	case status == http.StatusConflict:
+		return CategoryDuplicate
+	}
+
+	if c := classifyMessage(message); c != CategoryUnknown {
+		return c
+	}
+	switch status {
+	case http.StatusNotFound:
+		return CategoryNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CategoryValidation
+	}
+	return CategoryUnknown
+}
+
+func classifyMessage(message string) Category {
+	message = strings.ToLower(message)
+	for _, m := range messageCategories {
+		if strings.Contains(message, m.phrase) {
+			return m.category
+		}
+	}
+	return CategoryUnknown
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		status   int
+		message  string
+		expected Category
+	}{
+		{http.StatusUnauthorized, "", CategoryAuth},
+		{http.StatusForbidden, "Forbidden", CategoryAuth},
+		{http.StatusBadRequest, "Invalid MSISDN", CategoryValidation},
+		{http.StatusBadRequest, "Insufficient balance in account", CategoryInsufficientFunds},
+		{http.StatusPaymentRequired, "", CategoryInsufficientFunds},
+		{http.StatusConflict, "", CategoryDuplicate},
+		{http.StatusBadRequest, "Duplicate originator conversation ID", CategoryDuplicate},
+		{http.StatusNotFound, "", CategoryNotFound},
+		{http.StatusBadRequest, "Account does not exist", CategoryNotFound},
+		{http.StatusTooManyRequests, "", CategoryRateLimited},
+		{http.StatusServiceUnavailable, "", CategoryProviderDown},
+		{http.StatusInternalServerError, "Unable to lock subscriber, a transaction is already in process", CategoryDuplicate},
+		{http.StatusInternalServerError, "Spike arrest violation", CategoryRateLimited},
+		{http.StatusTeapot, "", CategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.status, tt.message); got != tt.expected {
+			t.Errorf("Classify(%d, %q): expected %s, got %s", tt.status, tt.message, tt.expected, got)
+		}
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	resp.Header.Set("X-Correlation-Id", "corr-1")
+
+	perr := FromResponse("airtel", resp, "ESB000001", "Service unavailable")
+	if perr.RequestID != "corr-1" {
+		t.Errorf("Expected request ID 'corr-1', got '%s'", perr.RequestID)
+	}
+	if perr.Category != CategoryProviderDown || !perr.Retryable {
+		t.Errorf("Expected retryable provider_down, got %s retryable=%v", perr.Category, perr.Retryable)
+	}
+
+	expected := "airtel provider down error: Service unavailable (status 503, code ESB000001, request corr-1)"
+	if perr.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, perr.Error())
+	}
+
+	perr.SetCategory(CategoryValidation)
+	if perr.Retryable {
+		t.Error("Expected validation error not to be retryable")
+	}
+}
+
+func TestProviderErrorWrapped(t *testing.T) {
+	cause := stderrors.New("sdk error")
+	perr := New("fnb", http.StatusBadRequest, "", "insufficient funds")
+	perr.Err = cause
+	err := fmt.Errorf("failed to create payment: %w", perr)
+
+	got, ok := As(err)
+	if !ok || got != perr {
+		t.Fatal("Expected As to find the ProviderError")
+	}
+	if !stderrors.Is(err, cause) {
+		t.Error("Expected the cause to be reachable through the ProviderError")
+	}
+	if CategoryOf(err) != CategoryInsufficientFunds {
+		t.Errorf("Expected insufficient_funds, got %s", CategoryOf(err))
+	}
+	if IsRetryable(err) {
+		t.Error("Expected insufficient funds not to be retryable")
+	}
+	if CategoryOf(cause) != CategoryUnknown {
+		t.Errorf("Expected unknown for a plain error, got %s", CategoryOf(cause))
+	}
+}
+
+func TestAuthFailure(t *testing.T) {
+	if c := New("jenga", http.StatusBadRequest, "", "Invalid MSISDN").AuthFailure().Category; c != CategoryAuth {
+		t.Errorf("Expected auth for a rejected token request, got %s", c)
+	}
+	if c := New("jenga", http.StatusBadGateway, "", "").AuthFailure().Category; c != CategoryProviderDown {
+		t.Errorf("Expected provider_down for a failed token endpoint, got %s", c)
+	}
+}
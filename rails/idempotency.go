@@ -0,0 +1,71 @@
+package rails
+
+import (
+	"context"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+)
+
+// IdempotentAdapter wraps an adapter so a request with an IdempotencyKey
+// reaches the provider at most once. Repeats get the first PaymentResult, or
+// the provider's rejection, from the store; see idempotency.Do for requests
+// whose outcome is unknown. A replayed PaymentResult's Raw is the provider
+// response decoded from JSON into a map.
+type IdempotentAdapter struct {
+	adapter interface{}
+	store   idempotency.Store
+}
+
+// NewIdempotentAdapter wraps adapter, which may implement any of Collector,
+// Disburser, Refunder and StatusChecker. Operations it does not implement
+// return ErrNotSupported.
+func NewIdempotentAdapter(adapter interface{}, store idempotency.Store) *IdempotentAdapter {
+	return &IdempotentAdapter{adapter: adapter, store: store}
+}
+
+func (a *IdempotentAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	c, ok := a.adapter.(Collector)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return idempotency.Do(ctx, a.store, req.IdempotencyKey, req, func(ctx context.Context) (*PaymentResult, error) {
+		return c.Collect(ctx, req)
+	})
+}
+
+func (a *IdempotentAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	d, ok := a.adapter.(Disburser)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return idempotency.Do(ctx, a.store, req.IdempotencyKey, req, func(ctx context.Context) (*PaymentResult, error) {
+		return d.Disburse(ctx, req)
+	})
+}
+
+func (a *IdempotentAdapter) Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error) {
+	r, ok := a.adapter.(Refunder)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return idempotency.Do(ctx, a.store, req.IdempotencyKey, req, func(ctx context.Context) (*PaymentResult, error) {
+		return r.Refund(ctx, req)
+	})
+}
+
+// Status is not recorded, since querying a transaction changes nothing.
+func (a *IdempotentAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	s, ok := a.adapter.(StatusChecker)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return s.Status(ctx, req)
+}
+
+// Provider returns the wrapped adapter's provider, if it has one.
+func (a *IdempotentAdapter) Provider() Provider {
+	if p, ok := a.adapter.(interface{ Provider() Provider }); ok {
+		return p.Provider()
+	}
+	return ""
+}
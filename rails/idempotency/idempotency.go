@@ -0,0 +1,218 @@
+// Package idempotency makes payment requests safe to submit more than once.
+// Do records each request under a caller-chosen key in a Store before it is
+// sent, along with a hash of the request and, once known, its outcome. A
+// repeated request with the same key gets the stored response instead of
+// reaching the provider again, so a payout retried after a timeout is not
+// paid twice:
+//
+//	resp, err := idempotency.Do(ctx, store, "payout-1001", body, func(ctx context.Context) (*daraja.B2CResponse, error) {
+//		return client.B2CPaymentWithContext(ctx, body)
+//	})
+//
+// When a request fails without a definite answer from the provider, such as
+// a network timeout or a 5xx, its key stays pending and repeats fail with
+// ErrInProgress until the caller has checked the transaction status and
+// called Store.Delete, or the record expires. Errors that clients mark with
+// NotSent, because the request failed before it was sent, release the key.
+//
+// The key is also passed to the provider where it has a native idempotency
+// field: clients read it with FromContext.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+var (
+	// ErrInProgress is returned for a key whose request has not finished, or
+	// whose outcome is unknown.
+	ErrInProgress = errors.New("idempotency: request with this key is in progress or has an unknown outcome")
+
+	// ErrConflict is returned when a key is reused for a different request.
+	ErrConflict = errors.New("idempotency: key was used for a different request")
+
+	// ErrNotSent matches errors marked with NotSent.
+	ErrNotSent = errors.New("idempotency: request was not sent")
+)
+
+// NotSent marks err as coming from a request that failed before it was sent
+// to the provider, such as a validation, encoding or authentication error, so
+// Do releases its key instead of leaving it pending. Clients call it on
+// errors from before the HTTP request is made. The returned error has err's
+// message and unwraps to it, and errors.Is reports it as ErrNotSent. NotSent
+// returns nil for a nil err.
+func NotSent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return notSentError{err}
+}
+
+type notSentError struct{ err error }
+
+func (e notSentError) Error() string        { return e.err.Error() }
+func (e notSentError) Unwrap() error        { return e.err }
+func (e notSentError) Is(target error) bool { return target == ErrNotSent }
+
+// State is how far a recorded request got.
+type State string
+
+const (
+	StatePending   State = "pending"   // Sent, or being sent, with no answer yet
+	StateSucceeded State = "succeeded" // The provider accepted it; Response holds its answer
+	StateFailed    State = "failed"    // The provider rejected it; Error holds why
+)
+
+// Record is what a Store keeps for a key.
+type Record struct {
+	Key       string                    `json:"key"`
+	Hash      string                    `json:"hash"` // Hash of the request, see Hash
+	State     State                     `json:"state"`
+	Response  json.RawMessage           `json:"response,omitempty"`
+	Error     *railerrors.ProviderError `json:"error,omitempty"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
+
+// Store keeps records by key. Implementations shared between processes, e.g.
+// backed by Redis or a database table with a unique key, must make Reserve
+// atomic so only one caller can send a request.
+type Store interface {
+	// Reserve saves rec if there is no record for rec.Key and returns true.
+	// Otherwise it returns the existing record and false.
+	Reserve(ctx context.Context, rec Record) (Record, bool, error)
+
+	// Save replaces the record for rec.Key.
+	Save(ctx context.Context, rec Record) error
+
+	// Delete removes the record for key, so the request can be sent again.
+	Delete(ctx context.Context, key string) error
+}
+
+type keyContextKey struct{}
+
+// WithKey returns a context carrying key, for clients to send as a provider's
+// idempotency field or header. Do calls it for you.
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+// FromContext returns the key set by WithKey, or "".
+func FromContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyContextKey{}).(string)
+	return key
+}
+
+// Hash returns a digest of the JSON encoding of request, so a key reused for
+// a different request can be detected.
+func Hash(request any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("idempotency: failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Do calls send once per key. A repeat of a request that succeeded returns
+// the stored response decoded into T; a repeat of one the provider rejected
+// returns the stored *errors.ProviderError. Reusing key for a request whose
+// Hash differs returns ErrConflict. An empty key sends the request without
+// recording it.
+func Do[T any](ctx context.Context, store Store, key string, request any, send func(context.Context) (T, error)) (T, error) {
+	var zero T
+	if key == "" {
+		return send(ctx)
+	}
+
+	hash, err := Hash(request)
+	if err != nil {
+		return zero, err
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	now := time.Now()
+	rec, reserved, err := store.Reserve(ctx, Record{Key: key, Hash: hash, State: StatePending, CreatedAt: now, UpdatedAt: now})
+	if err != nil {
+		return zero, fmt.Errorf("idempotency: failed to reserve key %q: %w", key, err)
+	}
+	if !reserved {
+		return replay[T](rec, hash)
+	}
+
+	result, err := send(WithKey(ctx, key))
+	if err != nil {
+		return zero, settle(ctx, store, rec, err)
+	}
+
+	rec.Response, err = json.Marshal(result)
+	if err != nil {
+		return result, fmt.Errorf("idempotency: failed to encode response for key %q: %w", key, err)
+	}
+	rec.State = StateSucceeded
+	rec.UpdatedAt = time.Now()
+	if err := store.Save(ctx, rec); err != nil {
+		return result, fmt.Errorf("idempotency: failed to save response for key %q: %w", key, err)
+	}
+	return result, nil
+}
+
+func replay[T any](rec Record, hash string) (T, error) {
+	var result T
+	if rec.Hash != hash {
+		return result, fmt.Errorf("%w: %q", ErrConflict, rec.Key)
+	}
+
+	switch rec.State {
+	case StateSucceeded:
+		if err := json.Unmarshal(rec.Response, &result); err != nil {
+			return result, fmt.Errorf("idempotency: failed to decode stored response for key %q: %w", rec.Key, err)
+		}
+		return result, nil
+	case StateFailed:
+		if rec.Error != nil {
+			return result, rec.Error
+		}
+	}
+	return result, fmt.Errorf("%w: %q", ErrInProgress, rec.Key)
+}
+
+// settle records the outcome of a failed request and returns err. Errors
+// marked NotSent release the key. A rejection by the provider is final and
+// stored for replay, except for rate limits and refused credentials, which
+// mean the request was not acted on and the key is released too. Anything
+// else may have reached the provider, so the key is left pending.
+func settle(ctx context.Context, store Store, rec Record, err error) error {
+	perr, ok := railerrors.As(err)
+	switch {
+	case errors.Is(err, ErrNotSent),
+		ok && (perr.Category == railerrors.CategoryRateLimited || perr.Category == railerrors.CategoryAuth):
+		if delErr := store.Delete(ctx, rec.Key); delErr != nil {
+			return errors.Join(err, fmt.Errorf("idempotency: failed to release key %q: %w", rec.Key, delErr))
+		}
+		return err
+	case !ok || perr.StatusCode >= http.StatusInternalServerError:
+		return err
+	}
+
+	stored := *perr
+	stored.Err = nil
+	rec.State = StateFailed
+	rec.Error = &stored
+	rec.UpdatedAt = time.Now()
+	if saveErr := store.Save(ctx, rec); saveErr != nil {
+		return errors.Join(err, fmt.Errorf("idempotency: failed to save error for key %q: %w", rec.Key, saveErr))
+	}
+	return err
+}
@@ -0,0 +1,230 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+type payout struct {
+	Phone  string `json:"phone"`
+	Amount string `json:"amount"`
+}
+
+type payoutResponse struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+func TestDoReplaysResponse(t *testing.T) {
+	store := NewMemoryStore(0)
+	calls := 0
+	send := func(ctx context.Context) (*payoutResponse, error) {
+		calls++
+		if key := FromContext(ctx); key != "payout-1" {
+			t.Errorf("Expected key 'payout-1' in context, got '%s'", key)
+		}
+		return &payoutResponse{ConversationID: "AG_1"}, nil
+	}
+
+	req := payout{Phone: "254708374149", Amount: "100.00"}
+	for i := 0; i < 3; i++ {
+		resp, err := Do(context.Background(), store, "payout-1", req, send)
+		if err != nil {
+			t.Fatalf("Do failed: %v", err)
+		}
+		if resp.ConversationID != "AG_1" {
+			t.Errorf("Expected conversation ID 'AG_1', got '%s'", resp.ConversationID)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected the request to be sent once, got %d", calls)
+	}
+}
+
+func TestDoConflict(t *testing.T) {
+	store := NewMemoryStore(0)
+	send := func(ctx context.Context) (string, error) { return "ok", nil }
+
+	Do(context.Background(), store, "payout-1", payout{Amount: "100.00"}, send)
+	_, err := Do(context.Background(), store, "payout-1", payout{Amount: "200.00"}, send)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestDoFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		resent bool  // Whether a repeat reaches the provider again
+		replay error // What a repeat returns otherwise
+	}{
+		{"rejected", railerrors.New("kcb", http.StatusBadRequest, "", "Insufficient funds"), false, nil},
+		{"timeout", context.DeadlineExceeded, false, ErrInProgress},
+		{"provider down", railerrors.New("kcb", http.StatusBadGateway, "", ""), false, ErrInProgress},
+		{"rate limited", railerrors.New("kcb", http.StatusTooManyRequests, "", ""), true, nil},
+		{"connection reset", errors.New("read: connection reset by peer"), false, ErrInProgress},
+		{"invalid phone", NotSent(errors.New("invalid phone number")), true, nil},
+		{"cancelled before dispatch", NotSent(context.Canceled), true, nil},
+	}
+
+	for _, tt := range tests {
+		store := NewMemoryStore(0)
+		calls := 0
+		send := func(ctx context.Context) (string, error) {
+			calls++
+			return "", tt.err
+		}
+
+		Do(context.Background(), store, "payout-1", payout{}, send)
+		_, err := Do(context.Background(), store, "payout-1", payout{}, send)
+
+		if tt.resent {
+			if calls != 2 {
+				t.Errorf("%s: expected the request to be sent again, got %d calls", tt.name, calls)
+			}
+			continue
+		}
+		if calls != 1 {
+			t.Errorf("%s: expected the request not to be sent again, got %d calls", tt.name, calls)
+		}
+		if tt.replay != nil && !errors.Is(err, tt.replay) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.replay, err)
+		}
+		if tt.replay == nil && railerrors.CategoryOf(err) != railerrors.CategoryInsufficientFunds {
+			t.Errorf("%s: expected the stored rejection, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestNotSent(t *testing.T) {
+	cause := errors.New("failed to generate security credential")
+	err := NotSent(cause)
+	if !errors.Is(err, ErrNotSent) || !errors.Is(err, cause) {
+		t.Errorf("Expected the error to match ErrNotSent and its cause, got %v", err)
+	}
+	if err.Error() != cause.Error() {
+		t.Errorf("Expected the cause's message, got '%s'", err.Error())
+	}
+	if NotSent(nil) != nil {
+		t.Error("Expected nil for a nil error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	store := NewMemoryStore(0)
+	_, err = Do(ctx, store, "payout-1", payout{}, func(ctx context.Context) (string, error) {
+		t.Error("Expected a cancelled request not to be sent")
+		return "", nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if _, reserved, _ := store.Reserve(context.Background(), Record{Key: "payout-1"}); !reserved {
+		t.Error("Expected a cancelled request not to reserve its key")
+	}
+}
+
+func TestDoConcurrent(t *testing.T) {
+	store := NewMemoryStore(0)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	send := func(ctx context.Context) (string, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return "ok", nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Do(context.Background(), store, "payout-1", payout{}, send)
+			errs <- err
+		}()
+	}
+	for {
+		mu.Lock()
+		started := calls
+		mu.Unlock()
+		if started > 0 {
+			break
+		}
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	inProgress := 0
+	for err := range errs {
+		if errors.Is(err, ErrInProgress) {
+			inProgress++
+		}
+	}
+	if calls != 1 || inProgress != 9 {
+		t.Errorf("Expected one request to be sent, got %d calls and %d in progress", calls, inProgress)
+	}
+}
+
+func TestNewReference(t *testing.T) {
+	pattern := regexp.MustCompile(`^COOP-[0-9A-Z]{26}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		ref := NewReference("COOP-")
+		if !pattern.MatchString(ref) {
+			t.Fatalf("Unexpected reference format '%s'", ref)
+		}
+		if seen[ref] {
+			t.Fatalf("Duplicate reference '%s'", ref)
+		}
+		seen[ref] = true
+	}
+}
+
+func TestNewNumericReference(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9]{20}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		ref := NewNumericReference()
+		if !pattern.MatchString(ref) {
+			t.Fatalf("Unexpected reference format '%s'", ref)
+		}
+		if seen[ref] {
+			t.Fatalf("Duplicate reference '%s'", ref)
+		}
+		seen[ref] = true
+	}
+}
+
+func TestNumericReferenceInstances(t *testing.T) {
+	// Replicas making references in the same second
+	now := time.Unix(1760000000, 0)
+	clock := func() time.Time { return now }
+	replicas := []*numericGenerator{{now: clock}, {now: clock}, {now: clock}}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		for _, g := range replicas {
+			ref := g.next()
+			if !strings.HasPrefix(ref, "1760000000") || len(ref) != 20 {
+				t.Fatalf("Unexpected reference format '%s'", ref)
+			}
+			if seen[ref] {
+				t.Fatalf("Duplicate reference '%s' across replicas", ref)
+			}
+			seen[ref] = true
+		}
+	}
+}
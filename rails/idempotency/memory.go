@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// DefaultTTL is how long MemoryStore keeps a record when no TTL is given.
+const DefaultTTL = 24 * time.Hour
+
+// MemoryStore keeps records in process memory for a fixed time. It only
+// protects against duplicates within one process.
+type MemoryStore struct {
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewMemoryStore returns a store that forgets records ttl after they were
+// reserved, or after DefaultTTL if ttl is zero.
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &MemoryStore{cache: cache.New(ttl, 10*time.Minute), ttl: ttl}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, rec Record) (Record, bool, error) {
+	if err := s.cache.Add(rec.Key, rec, s.ttl); err != nil {
+		if existing, found := s.cache.Get(rec.Key); found {
+			return existing.(Record), false, nil
+		}
+		// The record expired in between
+		return s.Reserve(ctx, rec)
+	}
+	return rec, true, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, rec Record) error {
+	ttl := time.Until(rec.CreatedAt.Add(s.ttl))
+	if ttl <= 0 {
+		s.cache.Delete(rec.Key)
+		return nil
+	}
+	s.cache.Set(rec.Key, rec, ttl)
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+var crockford = base32.NewEncoding("0123456789ABCDEFGHJKMNPQRSTVWXYZ").WithPadding(base32.NoPadding)
+
+// NewReference returns prefix followed by 26 upper-case letters and digits
+// encoding the time in milliseconds and 80 random bits, as in a ULID.
+// References from the same clock sort in the order they were made, and two
+// references made in the same millisecond collide with negligible
+// probability, even across processes.
+func NewReference(prefix string) string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(id[6:])
+	return prefix + crockford.EncodeToString(id[:])
+}
+
+// numericDigits is how many random digits follow the time in a numeric
+// reference.
+const numericDigits = 10
+
+var numericSpace = big.NewInt(10_000_000_000)
+
+// numericGenerator makes numeric references. issued holds the random parts
+// handed out in the current second, so the process never repeats one.
+type numericGenerator struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	second int64
+	issued map[int64]bool
+}
+
+var numeric = &numericGenerator{now: time.Now}
+
+// NewNumericReference returns a 20-digit reference for providers that only
+// accept digits: the Unix time in seconds followed by 10 random digits. A
+// process never makes the same reference twice, and two references made by
+// different processes in the same second collide with probability 1e-10.
+func NewNumericReference() string {
+	return numeric.next()
+}
+
+func (g *numericGenerator) next() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	second := g.now().Unix()
+	if second != g.second {
+		g.second, g.issued = second, make(map[int64]bool)
+	}
+	for {
+		n, _ := rand.Int(rand.Reader, numericSpace)
+		if !g.issued[n.Int64()] {
+			g.issued[n.Int64()] = true
+			return fmt.Sprintf("%010d%0*d", second, numericDigits, n.Int64())
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore keeps each message in a JSON file in a directory, so messages
+// survive restarts without a database. Writes replace files atomically.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, errors.New("inbox directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create inbox directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Save(ctx context.Context, msg Message) error {
+	path, err := s.path(msg.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, msg.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write webhook: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write webhook: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write webhook: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write webhook: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (Message, bool, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return Message{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return read(path)
+}
+
+func (s *FileStore) List(ctx context.Context, state State, limit int) ([]Message, error) {
+	msgs, err := s.all(func(msg Message) bool { return msg.State == state })
+	if err != nil {
+		return nil, err
+	}
+	return oldest(msgs, limit), nil
+}
+
+func (s *FileStore) Due(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	msgs, err := s.all(func(msg Message) bool { return due(msg, now) })
+	if err != nil {
+		return nil, err
+	}
+	return earliest(msgs, limit), nil
+}
+
+// all reads the stored messages that match.
+func (s *FileStore) all(match func(Message) bool) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	var msgs []Message
+	for _, path := range paths {
+		msg, found, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+		if found && match(msg) {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// path returns the file for id, refusing IDs that would point outside the
+// directory.
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid webhook ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func read(path string) (Message, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Message{}, false, nil
+	}
+	if err != nil {
+		return Message{}, false, fmt.Errorf("failed to read webhook: %w", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Message{}, false, fmt.Errorf("failed to decode webhook %s: %w", filepath.Base(path), err)
+	}
+	return msg, true, nil
+}
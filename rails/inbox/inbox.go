@@ -0,0 +1,380 @@
+// Package inbox stores webhooks before they are handled, so a failing
+// handler does not depend on the provider retrying the callback. Each
+// request is saved with its body, headers and verification result and
+// answered straight away. A worker then passes stored messages to a Handler,
+// retrying failures with a growing delay and moving messages that keep
+// failing to a dead-letter store, from where they can be replayed:
+//
+//	box := inbox.New(store, func(ctx context.Context, msg *inbox.Message) error {
+//		event, err := api.ParseWebhookEvent(msg.Body)
+//		if err != nil {
+//			return inbox.Permanent(err)
+//		}
+//		return payments.Update(ctx, event)
+//	})
+//	http.Handle("/fnb/callback", box.Receiver("fnb", verifyFNB))
+//	go box.Run(ctx)
+//
+// A webhooks.Gateway stores its callbacks in an Inbox with SetInbox.
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBodySize is the largest webhook body Receiver reads. Larger requests
+// are answered with 400.
+const MaxBodySize = 1 << 20
+
+// State is where a message is in its handling.
+type State string
+
+const (
+	StatePending  State = "pending"  // Waiting to be handled, or to be retried
+	StateDone     State = "done"     // Handled
+	StateDead     State = "dead"     // Failed MaxAttempts times, or permanently
+	StateRejected State = "rejected" // Failed verification; never handled
+)
+
+// Message is a stored webhook.
+type Message struct {
+	ID       string      `json:"id"`
+	Provider string      `json:"provider"`
+	Tenant   string      `json:"tenant,omitempty"`
+	Route    string      `json:"route,omitempty"` // The path the webhook was received on
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body"`
+
+	// Verified is whether the webhook passed verification. VerifyError
+	// holds why it did not.
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verify_error,omitempty"`
+
+	State       State     `json:"state"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextAttempt time.Time `json:"next_attempt"`
+	ReceivedAt  time.Time `json:"received_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Store keeps messages by ID. Run a single worker per Store, since messages
+// are not locked while they are handled.
+type Store interface {
+	// Save adds msg, or replaces the message with its ID.
+	Save(ctx context.Context, msg Message) error
+
+	// Get returns the message with id, and false if there is none.
+	Get(ctx context.Context, id string) (Message, bool, error)
+
+	// List returns up to limit messages in state, oldest first. A limit of
+	// zero or less returns them all.
+	List(ctx context.Context, state State, limit int) ([]Message, error)
+
+	// Due returns up to limit pending messages whose NextAttempt is not
+	// after now, the earliest due first. A limit of zero or less returns
+	// them all.
+	Due(ctx context.Context, now time.Time, limit int) ([]Message, error)
+
+	// Delete removes the message with id.
+	Delete(ctx context.Context, id string) error
+}
+
+// Handler handles a stored message. An error retries it later, unless it is
+// wrapped with Permanent.
+type Handler func(ctx context.Context, msg *Message) error
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one retrying will not fix, such as a body that
+// cannot be decoded, so the message is moved to the dead-letter store
+// straight away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Settings control how messages are retried. Zero fields take their value
+// from DefaultSettings.
+type Settings struct {
+	// MaxAttempts is how many times a message is handled before it is
+	// moved to the dead-letter store.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles for each
+	// further retry, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// PollInterval is how often Run looks for messages due for handling.
+	PollInterval time.Duration
+
+	// BatchSize is how many messages are read from the store at a time.
+	BatchSize int
+}
+
+// DefaultSettings handles a message up to 8 times over about two hours.
+func DefaultSettings() Settings {
+	return Settings{
+		MaxAttempts:  8,
+		BaseDelay:    time.Minute,
+		MaxDelay:     time.Hour,
+		PollInterval: 5 * time.Second,
+		BatchSize:    100,
+	}
+}
+
+func (s Settings) withDefaults() Settings {
+	d := DefaultSettings()
+	if s.MaxAttempts <= 0 {
+		s.MaxAttempts = d.MaxAttempts
+	}
+	if s.BaseDelay <= 0 {
+		s.BaseDelay = d.BaseDelay
+	}
+	if s.MaxDelay <= 0 {
+		s.MaxDelay = d.MaxDelay
+	}
+	if s.PollInterval <= 0 {
+		s.PollInterval = d.PollInterval
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = d.BatchSize
+	}
+	return s
+}
+
+// delay returns BaseDelay doubled for each attempt after the first, capped
+// at MaxDelay.
+func (s Settings) delay(attempt int) time.Duration {
+	d := s.BaseDelay
+	for i := 1; i < attempt && d < s.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, s.MaxDelay)
+}
+
+// Inbox stores webhooks and hands them to a Handler.
+type Inbox struct {
+	store    Store
+	dead     Store
+	handler  Handler
+	settings Settings
+	wake     chan struct{}
+}
+
+// New returns an Inbox keeping messages in store, dead letters included,
+// and passing them to handler.
+func New(store Store, handler Handler) *Inbox {
+	return &Inbox{
+		store:    store,
+		dead:     store,
+		handler:  handler,
+		settings: DefaultSettings(),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// SetSettings sets how messages are retried.
+func (b *Inbox) SetSettings(settings Settings) {
+	b.settings = settings.withDefaults()
+}
+
+// SetDeadLetterStore keeps dead messages in store instead of the inbox's
+// own store.
+func (b *Inbox) SetDeadLetterStore(store Store) {
+	b.dead = store
+}
+
+// Receive stores msg and returns it with its ID and state set. Verified
+// messages are handled by the worker; others are kept as StateRejected.
+func (b *Inbox) Receive(ctx context.Context, msg Message) (Message, error) {
+	now := time.Now()
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	if msg.ReceivedAt.IsZero() {
+		msg.ReceivedAt = now
+	}
+	msg.State, msg.Attempts, msg.NextAttempt, msg.UpdatedAt = StatePending, 0, now, now
+	if !msg.Verified {
+		msg.State = StateRejected
+	}
+
+	if err := b.store.Save(ctx, msg); err != nil {
+		return Message{}, fmt.Errorf("failed to store webhook: %w", err)
+	}
+	if msg.State == StatePending {
+		select {
+		case b.wake <- struct{}{}:
+		default:
+		}
+	}
+	return msg, nil
+}
+
+// Receiver returns an http.Handler storing the webhooks of provider. Each
+// request is checked with verify, which may be nil, and stored. Verified
+// webhooks are answered with 200 once stored, and others with 401.
+func (b *Inbox) Receiver(provider string, verify func(r *http.Request, body []byte) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		msg := Message{
+			Provider: provider,
+			Route:    r.URL.Path,
+			Header:   r.Header.Clone(),
+			Body:     body,
+			Verified: true,
+		}
+		if verify != nil {
+			if err := verify(r, bytes.Clone(body)); err != nil {
+				msg.Verified, msg.VerifyError = false, err.Error()
+			}
+		}
+
+		if _, err := b.Receive(r.Context(), msg); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !msg.Verified {
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Process handles the pending messages that are due and returns how many
+// were handled successfully. Messages waiting for a retry do not hold up
+// newer ones, however many there are.
+func (b *Inbox) Process(ctx context.Context) (int, error) {
+	msgs, err := b.store.Due(ctx, time.Now(), b.settings.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	handled := 0
+	for i := range msgs {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+		ok, err := b.handle(ctx, &msgs[i])
+		if err != nil {
+			return handled, err
+		}
+		if ok {
+			handled++
+		}
+	}
+	return handled, nil
+}
+
+// handle passes msg to the Handler and saves the outcome. It only returns
+// an error when the outcome cannot be saved.
+func (b *Inbox) handle(ctx context.Context, msg *Message) (bool, error) {
+	herr := b.handler(ctx, msg)
+
+	msg.Attempts++
+	msg.UpdatedAt = time.Now()
+	if herr == nil {
+		msg.State, msg.LastError = StateDone, ""
+		return true, b.store.Save(ctx, *msg)
+	}
+
+	msg.LastError = herr.Error()
+	var permanent permanentError
+	if errors.As(herr, &permanent) || msg.Attempts >= b.settings.MaxAttempts {
+		return false, b.bury(ctx, *msg)
+	}
+	msg.NextAttempt = msg.UpdatedAt.Add(b.settings.delay(msg.Attempts))
+	return false, b.store.Save(ctx, *msg)
+}
+
+// bury moves msg to the dead-letter store.
+func (b *Inbox) bury(ctx context.Context, msg Message) error {
+	msg.State = StateDead
+	if err := b.dead.Save(ctx, msg); err != nil {
+		return fmt.Errorf("failed to store dead webhook: %w", err)
+	}
+	if b.dead != b.store {
+		return b.store.Delete(ctx, msg.ID)
+	}
+	return nil
+}
+
+// Run calls Process every PollInterval, and as soon as a webhook is
+// received, until ctx is done. Store errors are retried on the next poll.
+func (b *Inbox) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.settings.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := b.Process(ctx); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-b.wake:
+		}
+	}
+}
+
+// DeadLetters returns up to limit dead messages, oldest first.
+func (b *Inbox) DeadLetters(ctx context.Context, limit int) ([]Message, error) {
+	return b.dead.List(ctx, StateDead, limit)
+}
+
+// Replay queues the message with id to be handled again, with its attempts
+// reset, whether it is dead, done or was rejected. Replaying a rejected
+// message handles a webhook that failed verification.
+func (b *Inbox) Replay(ctx context.Context, id string) error {
+	msg, found, err := b.store.Get(ctx, id)
+	if err == nil && !found && b.dead != b.store {
+		msg, found, err = b.dead.Get(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("webhook %s not found", id)
+	}
+
+	now := time.Now()
+	msg.State, msg.Attempts, msg.LastError = StatePending, 0, ""
+	msg.NextAttempt, msg.UpdatedAt = now, now
+	if err := b.store.Save(ctx, msg); err != nil {
+		return err
+	}
+	if b.dead != b.store {
+		if err := b.dead.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
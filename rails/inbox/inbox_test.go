@@ -0,0 +1,218 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	calls := 0
+	box := New(store, func(ctx context.Context, msg *Message) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database down")
+		}
+		return nil
+	})
+	box.SetSettings(Settings{BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+
+	msg, err := box.Receive(ctx, Message{Provider: "fnb", Body: []byte(`{}`), Verified: true})
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+
+	if handled, err := box.Process(ctx); err != nil || handled != 0 {
+		t.Fatalf("Expected the first attempt to fail, got %d handled, err=%v", handled, err)
+	}
+	stored, _, _ := store.Get(ctx, msg.ID)
+	if stored.State != StatePending || stored.Attempts != 1 || stored.LastError != "database down" {
+		t.Errorf("Expected a pending retry, got %+v", stored)
+	}
+
+	time.Sleep(time.Millisecond)
+	if handled, err := box.Process(ctx); err != nil || handled != 1 {
+		t.Fatalf("Expected the retry to succeed, got %d handled, err=%v", handled, err)
+	}
+	stored, _, _ = store.Get(ctx, msg.ID)
+	if stored.State != StateDone || stored.Attempts != 2 || stored.LastError != "" {
+		t.Errorf("Expected a handled message, got %+v", stored)
+	}
+}
+
+func TestProcessBackedOff(t *testing.T) {
+	ctx := context.Background()
+	files, _ := NewFileStore(t.TempDir())
+
+	for _, store := range []Store{NewMemoryStore(), files} {
+		var handled []string
+		box := New(store, func(ctx context.Context, msg *Message) error {
+			handled = append(handled, msg.ID)
+			return nil
+		})
+		box.SetSettings(Settings{BatchSize: 3})
+
+		// More older messages waiting for a retry than fit in a batch
+		now := time.Now()
+		for i := range 5 {
+			store.Save(ctx, Message{
+				ID:          "old-" + string(rune('a'+i)),
+				State:       StatePending,
+				Attempts:    3,
+				NextAttempt: now.Add(time.Hour),
+				ReceivedAt:  now.Add(-time.Hour),
+			})
+		}
+		msg, err := box.Receive(ctx, Message{Provider: "fnb", Body: []byte(`{}`), Verified: true})
+		if err != nil {
+			t.Fatalf("Receive failed: %v", err)
+		}
+
+		if n, err := box.Process(ctx); err != nil || n != 1 {
+			t.Fatalf("%T: expected the new message to be handled, got %d handled, err=%v", store, n, err)
+		}
+		if len(handled) != 1 || handled[0] != msg.ID {
+			t.Errorf("%T: expected only %s to be handled, got %v", store, msg.ID, handled)
+		}
+
+		due, _ := store.Due(ctx, now.Add(2*time.Hour), 2)
+		if len(due) != 2 || !strings.HasPrefix(due[0].ID, "old-") {
+			t.Errorf("%T: expected 2 backed off messages due later, got %+v", store, due)
+		}
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	store, dead := NewMemoryStore(), NewMemoryStore()
+
+	fail := true
+	box := New(store, func(ctx context.Context, msg *Message) error {
+		if string(msg.Body) == "garbage" {
+			return Permanent(errors.New("invalid JSON"))
+		}
+		if fail {
+			return errors.New("database down")
+		}
+		return nil
+	})
+	box.SetSettings(Settings{MaxAttempts: 2, BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+	box.SetDeadLetterStore(dead)
+
+	poison, _ := box.Receive(ctx, Message{Provider: "absa", Body: []byte("garbage"), Verified: true})
+	failing, _ := box.Receive(ctx, Message{Provider: "absa", Body: []byte(`{}`), Verified: true})
+
+	for i := 0; i < 2; i++ {
+		box.Process(ctx)
+		time.Sleep(time.Millisecond)
+	}
+
+	letters, err := box.DeadLetters(ctx, 0)
+	if err != nil {
+		t.Fatalf("DeadLetters failed: %v", err)
+	}
+	if len(letters) != 2 || letters[0].ID != poison.ID || letters[1].ID != failing.ID {
+		t.Fatalf("Expected both messages to be dead, got %+v", letters)
+	}
+	if letters[0].Attempts != 1 || letters[1].Attempts != 2 {
+		t.Errorf("Expected a permanent error not to be retried, got %d and %d attempts", letters[0].Attempts, letters[1].Attempts)
+	}
+	if pending, _ := store.List(ctx, StatePending, 0); len(pending) != 0 {
+		t.Errorf("Expected dead messages to leave the inbox, got %d", len(pending))
+	}
+
+	fail = false
+	if err := box.Replay(ctx, failing.ID); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if handled, _ := box.Process(ctx); handled != 1 {
+		t.Errorf("Expected the replayed message to be handled, got %d", handled)
+	}
+	if _, found, _ := dead.Get(ctx, failing.ID); found {
+		t.Error("Expected the replayed message to leave the dead-letter store")
+	}
+	if err := box.Replay(ctx, "missing"); err == nil {
+		t.Error("Expected an error replaying an unknown message")
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	store := NewMemoryStore()
+	box := New(store, func(ctx context.Context, msg *Message) error { return nil })
+	receiver := box.Receiver("fnb", func(r *http.Request, body []byte) error {
+		if r.Header.Get("X-FNB-Signature") != "valid" {
+			return errors.New("invalid signature")
+		}
+		return nil
+	})
+
+	for signature, want := range map[string]int{"valid": http.StatusOK, "forged": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodPost, "/fnb/callback", strings.NewReader(`{"eventId":"EVT1"}`))
+		req.Header.Set("X-FNB-Signature", signature)
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Expected %d for a %s signature, got %d", want, signature, rec.Code)
+		}
+	}
+
+	ctx := context.Background()
+	pending, _ := store.List(ctx, StatePending, 0)
+	rejected, _ := store.List(ctx, StateRejected, 0)
+	if len(pending) != 1 || len(rejected) != 1 {
+		t.Fatalf("Expected 1 pending and 1 rejected message, got %d and %d", len(pending), len(rejected))
+	}
+	msg := pending[0]
+	if msg.Provider != "fnb" || msg.Route != "/fnb/callback" || string(msg.Body) != `{"eventId":"EVT1"}` || msg.Header.Get("X-FNB-Signature") != "valid" {
+		t.Errorf("Expected the request to be stored, got %+v", msg)
+	}
+	if rejected[0].Verified || rejected[0].VerifyError != "invalid signature" {
+		t.Errorf("Expected the verification result to be stored, got %+v", rejected[0])
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "inbox")
+
+	writer, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+	box := New(writer, func(ctx context.Context, msg *Message) error { return nil })
+	msg, err := box.Receive(ctx, Message{Provider: "jenga", Header: http.Header{"X-Jenga-Signature": {"abc"}}, Body: []byte(`{"id":"evt-1"}`), Verified: true})
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+
+	// A new store on the same directory, e.g. after a restart
+	reader, _ := NewFileStore(dir)
+	stored, found, err := reader.Get(ctx, msg.ID)
+	if err != nil || !found {
+		t.Fatalf("Expected the message, got found=%v err=%v", found, err)
+	}
+	if string(stored.Body) != `{"id":"evt-1"}` || stored.Header.Get("X-Jenga-Signature") != "abc" || stored.State != StatePending {
+		t.Errorf("Unexpected message %+v", stored)
+	}
+	if pending, _ := reader.List(ctx, StatePending, 0); len(pending) != 1 {
+		t.Errorf("Expected 1 pending message, got %d", len(pending))
+	}
+
+	if err := reader.Delete(ctx, msg.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, found, _ := writer.Get(ctx, msg.ID); found {
+		t.Error("Expected the message to be deleted")
+	}
+	if _, _, err := reader.Get(ctx, "../secrets"); err == nil {
+		t.Error("Expected an ID outside the directory to be refused")
+	}
+}
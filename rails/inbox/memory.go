@@ -0,0 +1,107 @@
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps messages in process memory, so they are lost on
+// restart. It is meant for tests and single-process development.
+type MemoryStore struct {
+	mu   sync.Mutex
+	msgs map[string]Message
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{msgs: make(map[string]Message)}
+}
+
+func (s *MemoryStore) Save(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs[msg.ID] = clone(msg)
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, found := s.msgs[id]
+	return clone(msg), found, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, state State, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msgs []Message
+	for _, msg := range s.msgs {
+		if msg.State == state {
+			msgs = append(msgs, clone(msg))
+		}
+	}
+	return oldest(msgs, limit), nil
+}
+
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var msgs []Message
+	for _, msg := range s.msgs {
+		if due(msg, now) {
+			msgs = append(msgs, clone(msg))
+		}
+	}
+	return earliest(msgs, limit), nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.msgs, id)
+	return nil
+}
+
+// clone copies the body and headers, so callers cannot change a stored
+// message.
+func clone(msg Message) Message {
+	msg.Body = bytes.Clone(msg.Body)
+	msg.Header = msg.Header.Clone()
+	return msg
+}
+
+// due reports whether msg is pending and its next attempt is not after now.
+func due(msg Message, now time.Time) bool {
+	return msg.State == StatePending && !msg.NextAttempt.After(now)
+}
+
+// oldest sorts msgs by when they were received and keeps the first limit.
+func oldest(msgs []Message, limit int) []Message {
+	slices.SortFunc(msgs, func(a, b Message) int {
+		return a.ReceivedAt.Compare(b.ReceivedAt)
+	})
+	return first(msgs, limit)
+}
+
+// earliest sorts msgs by when they are due, then by when they were
+// received, and keeps the first limit.
+func earliest(msgs []Message, limit int) []Message {
+	slices.SortFunc(msgs, func(a, b Message) int {
+		if c := a.NextAttempt.Compare(b.NextAttempt); c != 0 {
+			return c
+		}
+		return a.ReceivedAt.Compare(b.ReceivedAt)
+	})
+	return first(msgs, limit)
+}
+
+func first(msgs []Message, limit int) []Message {
+	if limit > 0 && len(msgs) > limit {
+		msgs = msgs[:limit]
+	}
+	return msgs
+}
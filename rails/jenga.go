@@ -0,0 +1,203 @@
+package rails
+
+import (
+	"context"
+	"time"
+
+	"github.com/nutcas3/payment-rails/jenga"
+	"github.com/nutcas3/payment-rails/jenga/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+type JengaConfig struct {
+	MerchantCode    string // Receive Money merchant
+	MerchantAccount string
+	CallbackURL     string
+
+	CountryCode   string // Defaults to KE
+	Currency      string // Defaults to KES
+	SourceName    string // Account holder name of SourceAccount
+	SourceAccount string // Account debited for disbursements
+	WalletName    string // Mobile wallet for disbursements to a phone, defaults to Mpesa
+	TransferType  string // Bank transfer type for disbursements to an account, defaults to EFT
+}
+
+// JengaAdapter collects through Receive Money and disburses to a mobile
+// wallet when the request has a Phone, or to a bank account otherwise. Jenga
+// only exposes status queries for Receive Money and has no refund API.
+type JengaAdapter struct {
+	client *jenga.Client
+	config JengaConfig
+}
+
+func NewJengaAdapter(client *jenga.Client, config JengaConfig) *JengaAdapter {
+	if config.CountryCode == "" {
+		config.CountryCode = "KE"
+	}
+	if config.Currency == "" {
+		config.Currency = "KES"
+	}
+	if config.WalletName == "" {
+		config.WalletName = "Mpesa"
+	}
+	if config.TransferType == "" {
+		config.TransferType = api.TransferTypeEFT
+	}
+	return &JengaAdapter{client: client, config: config}
+}
+
+func (a *JengaAdapter) Provider() Provider {
+	return ProviderJenga
+}
+
+func (a *JengaAdapter) currency() string {
+	return a.config.Currency
+}
+
+func (a *JengaAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "ReceiveMoney")
+	resp, err := a.client.ReceiveMoneyWithContext(ctx, api.ReceiveMoneyRequest{
+		MerchantCode:    a.config.MerchantCode,
+		MerchantAccount: a.config.MerchantAccount,
+		CustomerAccount: req.Account,
+		CustomerName:    req.Name,
+		CustomerPhone:   adapterPhone(ProviderJenga, req.Phone, msisdn.Country(a.config.CountryCode)),
+		Amount:          req.Amount.StringFixed(2),
+		CurrencyCode:    firstNonEmpty(req.Currency, a.config.Currency),
+		Reference:       req.Reference,
+		Description:     req.Description,
+		CallbackUrl:     firstNonEmpty(req.CallbackURL, a.config.CallbackURL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderJenga,
+		Kind:           KindCollection,
+		TransactionID:  resp.Data.TransactionID,
+		Reference:      firstNonEmpty(resp.Reference, req.Reference),
+		Status:         jengaAcceptedStatus(resp.Status, resp.Data.Status),
+		ProviderStatus: resp.Data.Status,
+		Message:        resp.Message,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *JengaAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	source := api.Source{
+		CountryCode:   a.config.CountryCode,
+		Name:          a.config.SourceName,
+		AccountNumber: a.config.SourceAccount,
+	}
+	date := time.Now().Format("2006-01-02")
+	currency := firstNonEmpty(req.Currency, a.config.Currency)
+
+	if req.Phone != "" {
+		wallet := api.MobileWalletRequest{Source: source}
+		wallet.Destination.Type = "mobile"
+		wallet.Destination.CountryCode = a.config.CountryCode
+		wallet.Destination.Name = req.Name
+		wallet.Destination.MobileNumber = adapterPhone(ProviderJenga, req.Phone, msisdn.Country(a.config.CountryCode))
+		wallet.Destination.WalletName = a.config.WalletName
+		wallet.Transfer.Type = "MobileWallet"
+		wallet.Transfer.Amount = req.Amount.StringFixed(2)
+		wallet.Transfer.CurrencyCode = currency
+		wallet.Transfer.Reference = req.Reference
+		wallet.Transfer.Date = date
+		wallet.Transfer.Description = req.Description
+		wallet.Transfer.CallbackUrl = req.CallbackURL
+
+		ctx = telemetry.Name(ctx, "SendToMobileWallet")
+		resp, err := a.client.SendToMobileWalletWithContext(ctx, wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		return &PaymentResult{
+			Provider:       ProviderJenga,
+			Kind:           KindDisbursement,
+			TransactionID:  resp.Data.TransactionID,
+			Reference:      firstNonEmpty(resp.Reference, req.Reference),
+			Status:         jengaAcceptedStatus(resp.Status, resp.Data.Status),
+			ProviderStatus: resp.Data.Status,
+			Message:        resp.Message,
+			Raw:            resp,
+		}, nil
+	}
+
+	ctx = telemetry.Name(ctx, "SendMoney")
+	resp, err := a.client.SendMoneyWithContext(ctx, api.SendMoneyRequest{
+		Source: source,
+		Destination: api.Destination{
+			Type:          "bank",
+			CountryCode:   a.config.CountryCode,
+			Name:          req.Name,
+			AccountNumber: req.Account,
+		},
+		Transfer: api.Transfer{
+			Type:         a.config.TransferType,
+			Amount:       req.Amount.StringFixed(2),
+			CurrencyCode: currency,
+			Reference:    req.Reference,
+			Date:         date,
+			Description:  req.Description,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderJenga,
+		Kind:           KindDisbursement,
+		TransactionID:  resp.Data.TransactionID,
+		Reference:      firstNonEmpty(resp.Reference, req.Reference),
+		Status:         jengaAcceptedStatus(resp.Status, resp.Data.Status),
+		ProviderStatus: resp.Data.Status,
+		Message:        resp.Message,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *JengaAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	if req.Kind != "" && req.Kind != KindCollection {
+		return nil, ErrNotSupported
+	}
+
+	ctx = telemetry.Name(ctx, "QueryReceiveMoneyTransaction")
+	resp, err := a.client.QueryReceiveMoneyTransactionWithContext(ctx, api.ReceiveMoneyQueryRequest{
+		MerchantCode:  a.config.MerchantCode,
+		TransactionID: req.TransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderJenga,
+		Kind:           KindCollection,
+		TransactionID:  firstNonEmpty(resp.Data.TransactionID, req.TransactionID),
+		Reference:      resp.Reference,
+		Status:         JengaStatus(resp.Data.Status),
+		ProviderStatus: resp.Data.Status,
+		Message:        firstNonEmpty(resp.Data.Description, resp.Message),
+		Raw:            resp,
+	}, nil
+}
+
+// JengaStatus maps a Jenga transaction status onto a Status.
+func JengaStatus(status string) Status {
+	return statusFromText(status)
+}
+
+func jengaAcceptedStatus(ok bool, transactionStatus string) Status {
+	if !ok {
+		return StatusFailed
+	}
+	if status := JengaStatus(transactionStatus); status != StatusUnknown {
+		return status
+	}
+	return StatusPending
+}
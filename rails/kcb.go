@@ -0,0 +1,156 @@
+package rails
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/kcb"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+type KCBConfig struct {
+	SourceAccount string // Account debited for mobile money disbursements
+	Currency      string // Defaults to KES
+	Provider      string // Mobile money network for disbursements, detected from the phone if empty
+}
+
+// KCBAdapter collects through Vooma and disburses to mobile money wallets.
+// KCB has no refund API, so it does not implement Refunder.
+type KCBAdapter struct {
+	client *kcb.Client
+	config KCBConfig
+}
+
+func NewKCBAdapter(client *kcb.Client, config KCBConfig) *KCBAdapter {
+	if config.Currency == "" {
+		config.Currency = "KES"
+	}
+	return &KCBAdapter{client: client, config: config}
+}
+
+func (a *KCBAdapter) Provider() Provider {
+	return ProviderKCB
+}
+
+func (a *KCBAdapter) currency() string {
+	return a.config.Currency
+}
+
+func (a *KCBAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "VoomaPay")
+	resp, err := a.client.VoomaPayWithContext(ctx, req.Money(a.config.Currency))
+	if err != nil {
+		return nil, err
+	}
+
+	status := KCBStatus(resp.Data.Status)
+	if !resp.Status.Success {
+		status = StatusFailed
+	} else if status == StatusUnknown {
+		status = StatusPending
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderKCB,
+		Kind:           KindCollection,
+		TransactionID:  resp.Data.TransactionID,
+		Reference:      firstNonEmpty(resp.Data.Reference, req.Reference),
+		Status:         status,
+		ProviderStatus: firstNonEmpty(resp.Data.Status, resp.Status.ResultCode),
+		Message:        resp.Status.Message,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *KCBAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "MobileMoneyTransfer")
+	resp, err := a.client.MobileMoneyTransferWithContext(ctx,
+		a.config.SourceAccount,
+		adapterPhone(ProviderKCB, req.Phone, msisdn.Kenya),
+		req.Money(a.config.Currency),
+		req.Reference,
+		req.Description,
+		a.network(req.Phone),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderKCB,
+		Kind:           KindDisbursement,
+		TransactionID:  resp.Data.TransactionID,
+		Reference:      firstNonEmpty(resp.Data.Reference, req.Reference),
+		Status:         kcbAcceptedStatus(resp.Status, resp.Data.Status),
+		ProviderStatus: firstNonEmpty(resp.Data.Status, resp.Status),
+		Message:        resp.Message,
+		Raw:            resp,
+	}, nil
+}
+
+// network returns the mobile money network to disburse to phone on: the
+// configured one, or AIRTEL for Airtel numbers and MPESA otherwise.
+func (a *KCBAdapter) network(phone string) string {
+	if a.config.Provider != "" {
+		return a.config.Provider
+	}
+	if provider, err := PhoneProvider(phone, msisdn.Kenya); err == nil && provider == ProviderAirtel {
+		return "AIRTEL"
+	}
+	return "MPESA"
+}
+
+func (a *KCBAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	result := &PaymentResult{
+		Provider:      ProviderKCB,
+		Kind:          req.Kind,
+		TransactionID: req.TransactionID,
+	}
+
+	switch req.Kind {
+	case KindCollection, "":
+		ctx = telemetry.Name(ctx, "CheckVoomaStatus")
+		resp, err := a.client.CheckVoomaStatusWithContext(ctx, req.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		result.Kind = KindCollection
+		result.Reference = resp.Data.Reference
+		result.ProviderStatus = resp.Data.Status
+		result.Message = firstNonEmpty(resp.Data.StatusReason, resp.Message)
+		result.Raw = resp
+	case KindDisbursement:
+		ctx = telemetry.Name(ctx, "CheckMobileMoneyStatus")
+		resp, err := a.client.CheckMobileMoneyStatusWithContext(ctx, req.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		result.Reference = resp.Data.Reference
+		result.ProviderStatus = resp.Data.Status
+		result.Message = firstNonEmpty(resp.Data.StatusReason, resp.Message)
+		result.Raw = resp
+	default:
+		return nil, ErrNotSupported
+	}
+
+	result.Status = KCBStatus(result.ProviderStatus)
+	return result, nil
+}
+
+// KCBStatus maps a KCB Buni transaction status onto a Status.
+func KCBStatus(status string) Status {
+	return statusFromText(status)
+}
+
+// kcbAcceptedStatus derives the status of a newly submitted transaction from
+// the response's outcome and, when present, the transaction status.
+func kcbAcceptedStatus(outcome, transactionStatus string) Status {
+	if status := KCBStatus(transactionStatus); status != StatusUnknown {
+		return status
+	}
+	if KCBStatus(outcome) == StatusFailed || strings.EqualFold(outcome, "false") {
+		return StatusFailed
+	}
+	return StatusPending
+}
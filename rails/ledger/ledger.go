@@ -0,0 +1,595 @@
+// Package ledger is a double-entry ledger for wallet balances and
+// settlement tracking. Money only moves between accounts through entries
+// whose debits and credits balance in each currency, so every balance is
+// explained by the entries that made it:
+//
+//	l := ledger.New(ledger.NewMemoryStore())
+//	l.CreateAccount(ctx, ledger.Account{ID: "wallet:cust-42", Type: ledger.Liability, Currency: "KES"})
+//	l.Post(ctx, ledger.Entry{
+//		ID: "topup-1001",
+//		Postings: []ledger.Posting{
+//			{Account: "clearing:mpesa:KES", Side: ledger.Debit, Amount: amount},
+//			{Account: "wallet:cust-42", Side: ledger.Credit, Amount: amount},
+//		},
+//	})
+//
+// A Hold reserves funds, which then count against the available balance
+// until the hold is captured by an entry or released. See Payments for
+// posting tracked payments as they succeed or are reversed.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrAccountNotFound is returned for an account that is not in the
+	// store.
+	ErrAccountNotFound = errors.New("ledger: account not found")
+
+	// ErrAccountExists is returned by CreateAccount for an ID already in use.
+	ErrAccountExists = errors.New("ledger: account already exists")
+
+	// ErrInvalidEntry is returned for an entry with missing or malformed
+	// postings.
+	ErrInvalidEntry = errors.New("ledger: invalid entry")
+
+	// ErrUnbalanced is returned for an entry whose debits and credits differ
+	// in some currency.
+	ErrUnbalanced = errors.New("ledger: debits and credits do not balance")
+
+	// ErrCurrencyMismatch is returned for a posting or hold in a currency
+	// other than its account's.
+	ErrCurrencyMismatch = errors.New("ledger: currency does not match the account")
+
+	// ErrEntryExists is returned when an entry ID is posted again with
+	// different postings.
+	ErrEntryExists = errors.New("ledger: entry ID already used for a different entry")
+
+	// ErrInsufficientFunds is returned when an entry or hold would take an
+	// account's available balance below zero.
+	ErrInsufficientFunds = errors.New("ledger: insufficient funds")
+
+	// ErrHoldNotFound is returned for a hold that is not in the store.
+	ErrHoldNotFound = errors.New("ledger: hold not found")
+
+	// ErrHoldExists is returned when a hold ID is used again for a
+	// different account or amount.
+	ErrHoldExists = errors.New("ledger: hold ID already used for a different hold")
+
+	// ErrHoldNotActive is returned for capturing a hold that was released,
+	// captured by another entry, or has expired.
+	ErrHoldNotActive = errors.New("ledger: hold is not active")
+)
+
+// Type is the kind of an account, which decides the side that increases
+// its balance.
+type Type string
+
+const (
+	Asset     Type = "asset"     // Money held, e.g. the float at a provider
+	Liability Type = "liability" // Money owed, e.g. customer wallets
+	Equity    Type = "equity"
+	Income    Type = "income"  // e.g. fees earned
+	Expense   Type = "expense" // e.g. provider charges
+)
+
+// normal returns the side that increases an account of type t.
+func (t Type) normal() Side {
+	if t == Asset || t == Expense {
+		return Debit
+	}
+	return Credit
+}
+
+func (t Type) valid() bool {
+	switch t {
+	case Asset, Liability, Equity, Income, Expense:
+		return true
+	}
+	return false
+}
+
+// Side is the side of an entry a posting is on.
+type Side string
+
+const (
+	Debit  Side = "debit"
+	Credit Side = "credit"
+)
+
+// Account holds a balance in a single currency.
+type Account struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name,omitempty"`
+	Type     Type           `json:"type"`
+	Currency money.Currency `json:"currency"`
+
+	// AllowNegative lets the balance go below zero, e.g. for clearing
+	// accounts that mirror money held elsewhere.
+	AllowNegative bool `json:"allow_negative,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Posting is one side of an entry on one account.
+type Posting struct {
+	Account string      `json:"account"`
+	Side    Side        `json:"side"`
+	Amount  money.Money `json:"amount"` // Always positive, see Side
+}
+
+// Entry is a set of postings whose debits and credits balance in each
+// currency.
+type Entry struct {
+	// ID identifies the entry. Posting an entry with the ID of one already
+	// posted returns that entry, so a retried post is safe. A random ID is
+	// used if it is empty.
+	ID          string            `json:"id"`
+	Description string            `json:"description,omitempty"`
+	Postings    []Posting         `json:"postings"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// PostedAt is when the entry takes effect, for balances at a point in
+	// time. It is the time of posting if zero.
+	PostedAt  time.Time `json:"posted_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HoldState is where a hold is in its life.
+type HoldState string
+
+const (
+	HoldActive   HoldState = "active"   // Counts against the available balance
+	HoldCaptured HoldState = "captured" // Replaced by an entry
+	HoldReleased HoldState = "released" // Given back
+)
+
+// Hold reserves an amount of an account's balance, e.g. for a payout that
+// has been sent but not yet confirmed.
+type Hold struct {
+	ID      string      `json:"id"` // A random ID is used if it is empty
+	Account string      `json:"account"`
+	Amount  money.Money `json:"amount"`
+	Reason  string      `json:"reason,omitempty"`
+	State   HoldState   `json:"state"`
+	EntryID string      `json:"entry_id,omitempty"` // The entry that captured the hold
+
+	// ExpiresAt is when the hold stops counting against the balance. Zero
+	// means never.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// active reports whether h counts against the balance at now.
+func (h Hold) active(now time.Time) bool {
+	return h.State == HoldActive && (h.ExpiresAt.IsZero() || now.Before(h.ExpiresAt))
+}
+
+// Balance is an account's balance, positive on its normal side: debits
+// less credits for assets and expenses, credits less debits for the rest.
+type Balance struct {
+	Account   string      `json:"account"`
+	Posted    money.Money `json:"posted"`    // All entries posted
+	Held      money.Money `json:"held"`      // Active holds
+	Available money.Money `json:"available"` // Posted less Held
+}
+
+// Tx reads and writes a store within a transaction.
+type Tx interface {
+	Account(id string) (Account, bool, error)
+	CreateAccount(a Account) error
+
+	Entry(id string) (Entry, bool, error)
+	AddEntry(e Entry) error
+
+	// Totals returns the sums of the debits and credits posted to account
+	// at or before at, or of all of them if at is zero.
+	Totals(account string, at time.Time) (debits, credits decimal.Decimal, err error)
+
+	Hold(id string) (Hold, bool, error)
+	SaveHold(h Hold) error
+
+	// ActiveHolds returns the holds on account in HoldActive, expired or
+	// not.
+	ActiveHolds(account string) ([]Hold, error)
+}
+
+// Store keeps accounts, entries and holds. Balances are checked and changed
+// together, so the store works through transactions rather than single
+// calls: Transact runs fn, keeps its writes only if it returns nil, and
+// keeps concurrent transactions from interleaving.
+type Store interface {
+	Transact(ctx context.Context, fn func(tx Tx) error) error
+}
+
+// Ledger posts entries and keeps holds in a Store.
+type Ledger struct {
+	store Store
+}
+
+func New(store Store) *Ledger {
+	return &Ledger{store: store}
+}
+
+// CreateAccount creates a, or fails with ErrAccountExists.
+func (l *Ledger) CreateAccount(ctx context.Context, a Account) (Account, error) {
+	if a.ID == "" {
+		return Account{}, errors.New("ledger: account ID is required")
+	}
+	if !a.Type.valid() {
+		return Account{}, fmt.Errorf("ledger: invalid account type %q", a.Type)
+	}
+	if a.Currency == "" {
+		return Account{}, errors.New("ledger: account currency is required")
+	}
+	a.CreatedAt = time.Now()
+
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		_, found, err := tx.Account(a.ID)
+		if err != nil {
+			return err
+		}
+		if found {
+			return fmt.Errorf("%w: %s", ErrAccountExists, a.ID)
+		}
+		return tx.CreateAccount(a)
+	})
+	if err != nil {
+		return Account{}, err
+	}
+	return a, nil
+}
+
+// Account returns the account with id, or ErrAccountNotFound.
+func (l *Ledger) Account(ctx context.Context, id string) (Account, error) {
+	var a Account
+	err := l.store.Transact(ctx, func(tx Tx) (err error) {
+		a, err = account(tx, id)
+		return err
+	})
+	return a, err
+}
+
+// Post posts e. It fails with ErrInsufficientFunds if it would take the
+// available balance of an account that does not allow negative balances
+// below zero.
+func (l *Ledger) Post(ctx context.Context, e Entry) (Entry, error) {
+	return l.post(ctx, e, true)
+}
+
+func (l *Ledger) post(ctx context.Context, e Entry, checkFunds bool) (Entry, error) {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		return post(tx, &e, "", checkFunds, time.Now())
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Balance returns the balance of the account with id now.
+func (l *Ledger) Balance(ctx context.Context, id string) (Balance, error) {
+	var b Balance
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		a, err := account(tx, id)
+		if err != nil {
+			return err
+		}
+		b, err = balance(tx, a, time.Now(), "")
+		return err
+	})
+	return b, err
+}
+
+// BalanceAt returns the posted balance of the account with id as it was at
+// at, counting the entries posted at or before it.
+func (l *Ledger) BalanceAt(ctx context.Context, id string, at time.Time) (money.Money, error) {
+	var m money.Money
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		a, err := account(tx, id)
+		if err != nil {
+			return err
+		}
+		m, err = posted(tx, a, at)
+		return err
+	})
+	return m, err
+}
+
+// Hold reserves h.Amount of h.Account. It fails with ErrInsufficientFunds
+// if the account does not have it available. Placing a hold with the ID of
+// one already placed for the same account and amount returns that hold.
+func (l *Ledger) Hold(ctx context.Context, h Hold) (Hold, error) {
+	if h.Account == "" {
+		return Hold{}, errors.New("ledger: hold account is required")
+	}
+	if !h.Amount.Amount.IsPositive() {
+		return Hold{}, fmt.Errorf("ledger: hold amount must be positive, got %s", h.Amount)
+	}
+	if h.ID == "" {
+		h.ID = uuid.NewString()
+	}
+
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		existing, found, err := tx.Hold(h.ID)
+		if err != nil {
+			return err
+		}
+		if found {
+			if existing.Account != h.Account || !existing.Amount.Equal(h.Amount) {
+				return fmt.Errorf("%w: %s", ErrHoldExists, h.ID)
+			}
+			h = existing
+			return nil
+		}
+
+		a, err := account(tx, h.Account)
+		if err != nil {
+			return err
+		}
+		if h.Amount.Currency != a.Currency {
+			return fmt.Errorf("%w: %s hold on %s account %s", ErrCurrencyMismatch, h.Amount.Currency, a.Currency, a.ID)
+		}
+		now := time.Now()
+		if !a.AllowNegative {
+			b, err := balance(tx, a, now, "")
+			if err != nil {
+				return err
+			}
+			if b.Available.Amount.LessThan(h.Amount.Amount) {
+				return fmt.Errorf("%w: %s has %s available", ErrInsufficientFunds, a.ID, b.Available)
+			}
+		}
+		h.State, h.EntryID = HoldActive, ""
+		h.CreatedAt, h.UpdatedAt = now, now
+		return tx.SaveHold(h)
+	})
+	if err != nil {
+		return Hold{}, err
+	}
+	return h, nil
+}
+
+// Release gives back the funds held by the hold with id. Releasing a
+// released hold is a no-op, and a captured one fails with ErrHoldNotActive.
+func (l *Ledger) Release(ctx context.Context, id string) (Hold, error) {
+	var h Hold
+	err := l.store.Transact(ctx, func(tx Tx) (err error) {
+		h, err = hold(tx, id)
+		if err != nil {
+			return err
+		}
+		switch h.State {
+		case HoldReleased:
+			return nil
+		case HoldCaptured:
+			return fmt.Errorf("%w: %s was captured by entry %s", ErrHoldNotActive, id, h.EntryID)
+		}
+		h.State, h.UpdatedAt = HoldReleased, time.Now()
+		return tx.SaveHold(h)
+	})
+	return h, err
+}
+
+// Capture posts e in place of the hold with id: the hold stops counting
+// against the balance as e is posted. Capturing a hold again with the same
+// entry ID returns the entry already posted.
+func (l *Ledger) Capture(ctx context.Context, id string, e Entry) (Entry, error) {
+	return l.capture(ctx, id, e, true)
+}
+
+func (l *Ledger) capture(ctx context.Context, id string, e Entry, checkFunds bool) (Entry, error) {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	err := l.store.Transact(ctx, func(tx Tx) error {
+		h, err := hold(tx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if h.State == HoldCaptured && h.EntryID == e.ID {
+			return post(tx, &e, h.ID, false, now)
+		}
+		if !h.active(now) {
+			return fmt.Errorf("%w: %s is %s", ErrHoldNotActive, id, h.State)
+		}
+		if err := post(tx, &e, h.ID, checkFunds, now); err != nil {
+			return err
+		}
+		h.State, h.EntryID, h.UpdatedAt = HoldCaptured, e.ID, now
+		return tx.SaveHold(h)
+	})
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// post validates e and adds it, or sets it to the entry already posted
+// with its ID. The hold being captured, if any, does not count against the
+// balance.
+func post(tx Tx, e *Entry, capturing string, checkFunds bool, now time.Time) error {
+	existing, found, err := tx.Entry(e.ID)
+	if err != nil {
+		return err
+	}
+	if found {
+		if !samePostings(existing.Postings, e.Postings) {
+			return fmt.Errorf("%w: %s", ErrEntryExists, e.ID)
+		}
+		*e = existing
+		return nil
+	}
+
+	if err := validate(*e); err != nil {
+		return err
+	}
+
+	// The change to each account's balance on its normal side
+	accounts := make(map[string]Account)
+	var order []string
+	changes := make(map[string]decimal.Decimal)
+	for _, p := range e.Postings {
+		a, seen := accounts[p.Account]
+		if !seen {
+			if a, err = account(tx, p.Account); err != nil {
+				return err
+			}
+			accounts[a.ID] = a
+			order = append(order, a.ID)
+		}
+		if p.Amount.Currency != a.Currency {
+			return fmt.Errorf("%w: %s posting to %s account %s", ErrCurrencyMismatch, p.Amount.Currency, a.Currency, a.ID)
+		}
+		if p.Side == a.Type.normal() {
+			changes[a.ID] = changes[a.ID].Add(p.Amount.Amount)
+		} else {
+			changes[a.ID] = changes[a.ID].Sub(p.Amount.Amount)
+		}
+	}
+
+	if checkFunds {
+		for _, id := range order {
+			a, change := accounts[id], changes[id]
+			if a.AllowNegative || !change.IsNegative() {
+				continue
+			}
+			b, err := balance(tx, a, now, capturing)
+			if err != nil {
+				return err
+			}
+			if b.Available.Amount.Add(change).IsNegative() {
+				return fmt.Errorf("%w: %s has %s available", ErrInsufficientFunds, a.ID, b.Available)
+			}
+		}
+	}
+
+	e.CreatedAt = now
+	if e.PostedAt.IsZero() {
+		e.PostedAt = now
+	}
+	return tx.AddEntry(*e)
+}
+
+// validate checks that e has at least two well-formed postings and that
+// they balance in each currency.
+func validate(e Entry) error {
+	if len(e.Postings) < 2 {
+		return fmt.Errorf("%w: %s needs at least two postings", ErrInvalidEntry, e.ID)
+	}
+	sums := make(map[money.Currency]decimal.Decimal)
+	var currencies []money.Currency
+	for i, p := range e.Postings {
+		if p.Account == "" {
+			return fmt.Errorf("%w: posting %d has no account", ErrInvalidEntry, i+1)
+		}
+		if !p.Amount.Amount.IsPositive() {
+			return fmt.Errorf("%w: posting %d amount must be positive, got %s", ErrInvalidEntry, i+1, p.Amount)
+		}
+		c := p.Amount.Currency
+		if _, seen := sums[c]; !seen {
+			currencies = append(currencies, c)
+		}
+		switch p.Side {
+		case Debit:
+			sums[c] = sums[c].Add(p.Amount.Amount)
+		case Credit:
+			sums[c] = sums[c].Sub(p.Amount.Amount)
+		default:
+			return fmt.Errorf("%w: posting %d has side %q", ErrInvalidEntry, i+1, p.Side)
+		}
+	}
+	for _, c := range currencies {
+		if !sums[c].IsZero() {
+			return fmt.Errorf("%w: debits exceed credits by %s", ErrUnbalanced, money.New(sums[c], c))
+		}
+	}
+	return nil
+}
+
+func samePostings(a, b []Posting) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Account != b[i].Account || a[i].Side != b[i].Side || !a[i].Amount.Equal(b[i].Amount) {
+			return false
+		}
+	}
+	return true
+}
+
+func account(tx Tx, id string) (Account, error) {
+	a, found, err := tx.Account(id)
+	if err != nil {
+		return Account{}, err
+	}
+	if !found {
+		return Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, id)
+	}
+	return a, nil
+}
+
+func hold(tx Tx, id string) (Hold, error) {
+	h, found, err := tx.Hold(id)
+	if err != nil {
+		return Hold{}, err
+	}
+	if !found {
+		return Hold{}, fmt.Errorf("%w: %s", ErrHoldNotFound, id)
+	}
+	return h, nil
+}
+
+// posted returns a's balance from the entries posted at or before at, or
+// from all of them if at is zero.
+func posted(tx Tx, a Account, at time.Time) (money.Money, error) {
+	debits, credits, err := tx.Totals(a.ID, at)
+	if err != nil {
+		return money.Money{}, err
+	}
+	amount := debits.Sub(credits)
+	if a.Type.normal() == Credit {
+		amount = amount.Neg()
+	}
+	return money.New(amount, a.Currency), nil
+}
+
+// balance returns a's balance at now, leaving out the hold with ID
+// excluded.
+func balance(tx Tx, a Account, now time.Time, excluded string) (Balance, error) {
+	p, err := posted(tx, a, time.Time{})
+	if err != nil {
+		return Balance{}, err
+	}
+	holds, err := tx.ActiveHolds(a.ID)
+	if err != nil {
+		return Balance{}, err
+	}
+	held := decimal.Zero
+	for _, h := range holds {
+		if h.ID != excluded && h.active(now) {
+			held = held.Add(h.Amount.Amount)
+		}
+	}
+	return Balance{
+		Account:   a.ID,
+		Posted:    p,
+		Held:      money.New(held, a.Currency),
+		Available: money.New(p.Amount.Sub(held), a.Currency),
+	}, nil
+}
@@ -0,0 +1,257 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/lifecycle"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// stores runs test against each Store implementation.
+func stores(t *testing.T, test func(t *testing.T, l *Ledger)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, New(NewMemoryStore()))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "ledger.db")+"?_txlock=immediate&_busy_timeout=5000")
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		store, err := NewSQLiteStore(db)
+		if err != nil {
+			t.Fatalf("NewSQLiteStore failed: %v", err)
+		}
+		test(t, New(store))
+	})
+}
+
+func kes(s string) money.Money {
+	m, _ := money.Parse(s, "KES")
+	return m
+}
+
+// setup creates a clearing account and a wallet.
+func setup(t *testing.T, l *Ledger) {
+	ctx := context.Background()
+	for _, a := range []Account{
+		{ID: "clearing", Type: Asset, Currency: "KES", AllowNegative: true},
+		{ID: "wallet", Type: Liability, Currency: "KES"},
+	} {
+		if _, err := l.CreateAccount(ctx, a); err != nil {
+			t.Fatalf("CreateAccount failed: %v", err)
+		}
+	}
+}
+
+func transfer(id, from, to string, amount money.Money) Entry {
+	return Entry{ID: id, Postings: []Posting{
+		{Account: from, Side: Debit, Amount: amount},
+		{Account: to, Side: Credit, Amount: amount},
+	}}
+}
+
+func expectBalance(t *testing.T, l *Ledger, account, posted, available string) {
+	t.Helper()
+	b, err := l.Balance(context.Background(), account)
+	if err != nil {
+		t.Fatalf("Balance failed: %v", err)
+	}
+	if !b.Posted.Equal(kes(posted)) || !b.Available.Equal(kes(available)) {
+		t.Errorf("Expected %s posted and %s available on %s, got %s and %s", posted, available, account, b.Posted, b.Available)
+	}
+}
+
+func TestPost(t *testing.T) {
+	stores(t, func(t *testing.T, l *Ledger) {
+		ctx := context.Background()
+		setup(t, l)
+
+		if _, err := l.CreateAccount(ctx, Account{ID: "wallet", Type: Liability, Currency: "KES"}); !errors.Is(err, ErrAccountExists) {
+			t.Errorf("Expected ErrAccountExists, got %v", err)
+		}
+
+		topup := transfer("topup-1", "clearing", "wallet", kes("1000"))
+		topup.Metadata = map[string]string{"source": "mpesa"}
+		if _, err := l.Post(ctx, topup); err != nil {
+			t.Fatalf("Post failed: %v", err)
+		}
+		expectBalance(t, l, "wallet", "1000", "1000")
+		expectBalance(t, l, "clearing", "1000", "1000")
+
+		// A retried post changes nothing
+		again, err := l.Post(ctx, topup)
+		if err != nil || again.Metadata["source"] != "mpesa" {
+			t.Errorf("Expected the posted entry back, got %+v, err=%v", again, err)
+		}
+		expectBalance(t, l, "wallet", "1000", "1000")
+		if _, err := l.Post(ctx, transfer("topup-1", "clearing", "wallet", kes("5"))); !errors.Is(err, ErrEntryExists) {
+			t.Errorf("Expected ErrEntryExists, got %v", err)
+		}
+
+		unbalanced := transfer("bad-1", "clearing", "wallet", kes("10"))
+		unbalanced.Postings[1].Amount = kes("9")
+		if _, err := l.Post(ctx, unbalanced); !errors.Is(err, ErrUnbalanced) {
+			t.Errorf("Expected ErrUnbalanced, got %v", err)
+		}
+		usd, _ := money.Parse("10", "USD")
+		if _, err := l.Post(ctx, transfer("bad-2", "clearing", "wallet", usd)); !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+		}
+		if _, err := l.Post(ctx, transfer("bad-3", "clearing", "missing", kes("10"))); !errors.Is(err, ErrAccountNotFound) {
+			t.Errorf("Expected ErrAccountNotFound, got %v", err)
+		}
+		if _, err := l.Post(ctx, transfer("bad-4", "clearing", "wallet", kes("0"))); !errors.Is(err, ErrInvalidEntry) {
+			t.Errorf("Expected ErrInvalidEntry, got %v", err)
+		}
+
+		if _, err := l.Post(ctx, transfer("payout-1", "wallet", "clearing", kes("1000.01"))); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+		}
+		if _, err := l.Post(ctx, transfer("payout-2", "wallet", "clearing", kes("1000"))); err != nil {
+			t.Errorf("Expected the whole balance to be spendable, got %v", err)
+		}
+		expectBalance(t, l, "wallet", "0", "0")
+	})
+}
+
+func TestBalanceAt(t *testing.T) {
+	stores(t, func(t *testing.T, l *Ledger) {
+		ctx := context.Background()
+		setup(t, l)
+
+		day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+		for i, amount := range []string{"100", "250"} {
+			e := transfer("topup-"+amount, "clearing", "wallet", kes(amount))
+			e.PostedAt = day(1 + 2*i)
+			if _, err := l.Post(ctx, e); err != nil {
+				t.Fatalf("Post failed: %v", err)
+			}
+		}
+
+		for at, want := range map[time.Time]string{day(1).Add(-time.Second): "0", day(1): "100", day(2): "100", day(4): "350"} {
+			got, err := l.BalanceAt(ctx, "wallet", at)
+			if err != nil || !got.Equal(kes(want)) {
+				t.Errorf("Expected %s at %s, got %s, err=%v", want, at, got, err)
+			}
+		}
+	})
+}
+
+func TestHolds(t *testing.T) {
+	stores(t, func(t *testing.T, l *Ledger) {
+		ctx := context.Background()
+		setup(t, l)
+		l.Post(ctx, transfer("topup-1", "clearing", "wallet", kes("500")))
+
+		h, err := l.Hold(ctx, Hold{ID: "payout-1", Account: "wallet", Amount: kes("300")})
+		if err != nil {
+			t.Fatalf("Hold failed: %v", err)
+		}
+		if h.State != HoldActive {
+			t.Errorf("Expected an active hold, got %s", h.State)
+		}
+		expectBalance(t, l, "wallet", "500", "200")
+
+		if _, err := l.Hold(ctx, Hold{ID: "payout-2", Account: "wallet", Amount: kes("300")}); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("Expected ErrInsufficientFunds, got %v", err)
+		}
+		if _, err := l.Post(ctx, transfer("spend-1", "wallet", "clearing", kes("201"))); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("Expected held funds not to be spendable, got %v", err)
+		}
+		if _, err := l.Hold(ctx, Hold{ID: "payout-1", Account: "wallet", Amount: kes("1")}); !errors.Is(err, ErrHoldExists) {
+			t.Errorf("Expected ErrHoldExists, got %v", err)
+		}
+
+		// The capture may use the held funds
+		payout := transfer("payout-1", "wallet", "clearing", kes("300"))
+		if _, err := l.Capture(ctx, "payout-1", payout); err != nil {
+			t.Fatalf("Capture failed: %v", err)
+		}
+		expectBalance(t, l, "wallet", "200", "200")
+		if _, err := l.Capture(ctx, "payout-1", payout); err != nil {
+			t.Errorf("Expected a repeated capture to succeed, got %v", err)
+		}
+		expectBalance(t, l, "wallet", "200", "200")
+		if _, err := l.Release(ctx, "payout-1"); !errors.Is(err, ErrHoldNotActive) {
+			t.Errorf("Expected ErrHoldNotActive, got %v", err)
+		}
+
+		l.Hold(ctx, Hold{ID: "payout-3", Account: "wallet", Amount: kes("150")})
+		h, err = l.Release(ctx, "payout-3")
+		if err != nil || h.State != HoldReleased {
+			t.Errorf("Expected a released hold, got %s, err=%v", h.State, err)
+		}
+		expectBalance(t, l, "wallet", "200", "200")
+
+		l.Hold(ctx, Hold{ID: "payout-4", Account: "wallet", Amount: kes("200"), ExpiresAt: time.Now().Add(-time.Second)})
+		expectBalance(t, l, "wallet", "200", "200")
+		if _, err := l.Capture(ctx, "payout-4", transfer("payout-4", "wallet", "clearing", kes("200"))); !errors.Is(err, ErrHoldNotActive) {
+			t.Errorf("Expected an expired hold not to be captured, got %v", err)
+		}
+		if _, err := l.Release(ctx, "missing"); !errors.Is(err, ErrHoldNotFound) {
+			t.Errorf("Expected ErrHoldNotFound, got %v", err)
+		}
+	})
+}
+
+func TestPayments(t *testing.T) {
+	stores(t, func(t *testing.T, l *Ledger) {
+		ctx := context.Background()
+		l.CreateAccount(ctx, Account{ID: "wallet:cust-42", Type: Liability, Currency: "KES"})
+
+		payments := NewPayments(l, func(p lifecycle.Payment) (string, error) {
+			return "wallet:cust-42", nil
+		})
+		tracker := lifecycle.New(lifecycle.NewMemoryStore())
+		tracker.SetTransitionHook(payments.Hook)
+
+		tracker.Create(ctx, lifecycle.Payment{ID: "topup-1", Provider: rails.ProviderMpesa, Kind: rails.KindCollection, Amount: kes("1000")})
+		if _, err := tracker.Submitted(ctx, "topup-1", &rails.PaymentResult{TransactionID: "ws_CO_1", Status: rails.StatusSucceeded}); err != nil {
+			t.Fatalf("Submitted failed: %v", err)
+		}
+		expectBalance(t, l, "wallet:cust-42", "1000", "1000")
+		expectBalance(t, l, ClearingAccount(rails.ProviderMpesa, "KES"), "1000", "1000")
+
+		// A payout reserved before it is sent
+		if _, err := l.Hold(ctx, Hold{ID: HoldID("payout-1"), Account: "wallet:cust-42", Amount: kes("400")}); err != nil {
+			t.Fatalf("Hold failed: %v", err)
+		}
+		tracker.Create(ctx, lifecycle.Payment{ID: "payout-1", Provider: rails.ProviderAirtel, Kind: rails.KindDisbursement, Amount: kes("400")})
+		tracker.Transition(ctx, "payout-1", lifecycle.StatePending, lifecycle.Transition{Source: lifecycle.SourceSubmit})
+		expectBalance(t, l, "wallet:cust-42", "1000", "600")
+		if _, err := tracker.Apply(ctx, "payout-1", lifecycle.SourceCallback, rails.StatusSucceeded, "TS"); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		expectBalance(t, l, "wallet:cust-42", "600", "600")
+
+		// A failed payout gives the funds back
+		l.Hold(ctx, Hold{ID: HoldID("payout-2"), Account: "wallet:cust-42", Amount: kes("100")})
+		tracker.Create(ctx, lifecycle.Payment{ID: "payout-2", Provider: rails.ProviderAirtel, Kind: rails.KindDisbursement, Amount: kes("100")})
+		tracker.Apply(ctx, "payout-2", lifecycle.SourceCallback, rails.StatusFailed, "TF")
+		expectBalance(t, l, "wallet:cust-42", "600", "600")
+
+		// The top-up is reversed after the money was spent
+		p, err := tracker.Transition(ctx, "topup-1", lifecycle.StateReversed, lifecycle.Transition{Source: lifecycle.SourceCallback})
+		if err != nil {
+			t.Fatalf("Transition failed: %v", err)
+		}
+		expectBalance(t, l, "wallet:cust-42", "-400", "-400")
+
+		entries, err := payments.Post(ctx, p)
+		if err != nil || len(entries) != 2 {
+			t.Fatalf("Expected the success and reversal entries, got %d, err=%v", len(entries), err)
+		}
+		if reversal := entries[1].Postings[0]; reversal.Account != ClearingAccount(rails.ProviderMpesa, "KES") || reversal.Side != Credit || entries[0].Metadata["transaction_id"] != "ws_CO_1" {
+			t.Errorf("Unexpected entries %+v", entries)
+		}
+		expectBalance(t, l, "wallet:cust-42", "-400", "-400")
+	})
+}
@@ -0,0 +1,168 @@
+package ledger
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MemoryStore keeps the ledger in process memory. It is meant for tests and
+// single-process development.
+type MemoryStore struct {
+	mu       sync.Mutex
+	accounts map[string]Account
+	entries  map[string]Entry
+	postings map[string][]posting // By account ID
+	holds    map[string]Hold
+}
+
+// posting is a posting as kept for balances.
+type posting struct {
+	side     Side
+	amount   decimal.Decimal
+	postedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts: make(map[string]Account),
+		entries:  make(map[string]Entry),
+		postings: make(map[string][]posting),
+		holds:    make(map[string]Hold),
+	}
+}
+
+// Transact runs fn with the store locked, and applies its writes if it
+// returns nil.
+func (s *MemoryStore) Transact(ctx context.Context, fn func(tx Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryTx{
+		store:    s,
+		accounts: make(map[string]Account),
+		entries:  make(map[string]Entry),
+		holds:    make(map[string]Hold),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	tx.commit()
+	return nil
+}
+
+// memoryTx keeps a transaction's writes until it commits. Reads see them
+// first.
+type memoryTx struct {
+	store    *MemoryStore
+	accounts map[string]Account
+	entries  map[string]Entry
+	order    []string // Entry IDs in the order they were added
+	holds    map[string]Hold
+}
+
+func (tx *memoryTx) Account(id string) (Account, bool, error) {
+	if a, found := tx.accounts[id]; found {
+		return a, true, nil
+	}
+	a, found := tx.store.accounts[id]
+	return a, found, nil
+}
+
+func (tx *memoryTx) CreateAccount(a Account) error {
+	tx.accounts[a.ID] = a
+	return nil
+}
+
+func (tx *memoryTx) Entry(id string) (Entry, bool, error) {
+	e, found := tx.entries[id]
+	if !found {
+		e, found = tx.store.entries[id]
+	}
+	return cloneEntry(e), found, nil
+}
+
+func (tx *memoryTx) AddEntry(e Entry) error {
+	tx.entries[e.ID] = cloneEntry(e)
+	tx.order = append(tx.order, e.ID)
+	return nil
+}
+
+func (tx *memoryTx) Totals(account string, at time.Time) (debits, credits decimal.Decimal, err error) {
+	add := func(p posting) {
+		if !at.IsZero() && p.postedAt.After(at) {
+			return
+		}
+		if p.side == Debit {
+			debits = debits.Add(p.amount)
+		} else {
+			credits = credits.Add(p.amount)
+		}
+	}
+	for _, p := range tx.store.postings[account] {
+		add(p)
+	}
+	for _, id := range tx.order {
+		e := tx.entries[id]
+		for _, p := range e.Postings {
+			if p.Account == account {
+				add(posting{side: p.Side, amount: p.Amount.Amount, postedAt: e.PostedAt})
+			}
+		}
+	}
+	return debits, credits, nil
+}
+
+func (tx *memoryTx) Hold(id string) (Hold, bool, error) {
+	if h, found := tx.holds[id]; found {
+		return h, true, nil
+	}
+	h, found := tx.store.holds[id]
+	return h, found, nil
+}
+
+func (tx *memoryTx) SaveHold(h Hold) error {
+	tx.holds[h.ID] = h
+	return nil
+}
+
+func (tx *memoryTx) ActiveHolds(account string) ([]Hold, error) {
+	var holds []Hold
+	for id, h := range tx.store.holds {
+		if pending, found := tx.holds[id]; found {
+			h = pending
+		}
+		if h.Account == account && h.State == HoldActive {
+			holds = append(holds, h)
+		}
+	}
+	for id, h := range tx.holds {
+		if _, found := tx.store.holds[id]; !found && h.Account == account && h.State == HoldActive {
+			holds = append(holds, h)
+		}
+	}
+	return holds, nil
+}
+
+func (tx *memoryTx) commit() {
+	s := tx.store
+	maps.Copy(s.accounts, tx.accounts)
+	maps.Copy(s.holds, tx.holds)
+	for _, id := range tx.order {
+		e := tx.entries[id]
+		s.entries[id] = e
+		for _, p := range e.Postings {
+			s.postings[p.Account] = append(s.postings[p.Account], posting{side: p.Side, amount: p.Amount.Amount, postedAt: e.PostedAt})
+		}
+	}
+}
+
+func cloneEntry(e Entry) Entry {
+	e.Postings = slices.Clone(e.Postings)
+	e.Metadata = maps.Clone(e.Metadata)
+	return e
+}
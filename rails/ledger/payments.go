@@ -0,0 +1,178 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/lifecycle"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// Payments posts tracked payments to a ledger as they succeed or are
+// reversed, on any rail. A collection moves money from the provider's
+// clearing account into the payment's account, e.g. a customer wallet, and
+// a disbursement or refund moves it back out:
+//
+//	payments := ledger.NewPayments(l, func(p lifecycle.Payment) (string, error) {
+//		return "wallet:" + customerOf(p.ID), nil
+//	})
+//	tracker.SetTransitionHook(payments.Hook)
+//
+// The money has moved by the time a provider reports it, so payment entries
+// are posted even if they overdraw the account. To keep a wallet from being
+// overdrawn by a payout, hold the amount under HoldID(payment ID) before
+// sending it: the entry captures the hold, and a failed payout releases it.
+type Payments struct {
+	ledger  *Ledger
+	account func(lifecycle.Payment) (string, error)
+}
+
+// NewPayments returns Payments posting to l. account returns the ID of the
+// account a payment is for.
+func NewPayments(l *Ledger, account func(lifecycle.Payment) (string, error)) *Payments {
+	return &Payments{ledger: l, account: account}
+}
+
+// ClearingAccount returns the ID of the asset account for money at
+// provider in currency. Payments creates it on first use, allowing negative
+// balances.
+func ClearingAccount(provider rails.Provider, currency money.Currency) string {
+	return "clearing:" + string(provider) + ":" + string(currency)
+}
+
+// HoldID returns the ID of the hold Payments captures for the payment with
+// paymentID.
+func HoldID(paymentID string) string {
+	return "payment:" + paymentID
+}
+
+// Hook posts the payment when tr moves it to succeeded or reversed, and
+// releases its hold when it fails. It is a lifecycle.TransitionHook.
+func (p *Payments) Hook(ctx context.Context, payment lifecycle.Payment, tr lifecycle.Transition) error {
+	switch tr.To {
+	case lifecycle.StateSucceeded, lifecycle.StateReversed:
+		_, err := p.post(ctx, payment, tr.To)
+		return err
+	case lifecycle.StateFailed:
+		_, err := p.ledger.Release(ctx, HoldID(payment.ID))
+		if errors.Is(err, ErrHoldNotFound) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Post posts the entries for payment's current state: its success if it
+// succeeded, and its success and reversal if it was reversed. Entries
+// already posted are returned as they are, so Post can catch up on
+// payments a failed hook missed.
+func (p *Payments) Post(ctx context.Context, payment lifecycle.Payment) ([]Entry, error) {
+	switch payment.State {
+	case lifecycle.StateSucceeded, lifecycle.StateReversed:
+		return p.post(ctx, payment, payment.State)
+	}
+	return nil, nil
+}
+
+// post posts payment's success, and its reversal if state is reversed.
+func (p *Payments) post(ctx context.Context, payment lifecycle.Payment, state lifecycle.State) ([]Entry, error) {
+	success, err := p.entry(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	posted, err := p.ledger.capture(ctx, HoldID(payment.ID), success, false)
+	if errors.Is(err, ErrHoldNotFound) || errors.Is(err, ErrHoldNotActive) {
+		posted, err = p.ledger.post(ctx, success, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	success = posted
+	entries := []Entry{success}
+	if state != lifecycle.StateReversed {
+		return entries, nil
+	}
+
+	reversal := Entry{
+		ID:          "payment:" + payment.ID + ":reversed",
+		Description: "Reversal of " + success.Description,
+		Metadata:    success.Metadata,
+		PostedAt:    reachedAt(payment, lifecycle.StateReversed),
+	}
+	for _, posting := range success.Postings {
+		posting.Side = opposite(posting.Side)
+		reversal.Postings = append(reversal.Postings, posting)
+	}
+	reversal, err = p.ledger.post(ctx, reversal, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, reversal), nil
+}
+
+// entry returns the entry for payment's success, creating the clearing
+// account if needed.
+func (p *Payments) entry(ctx context.Context, payment lifecycle.Payment) (Entry, error) {
+	account, err := p.account(payment)
+	if err != nil {
+		return Entry{}, fmt.Errorf("ledger: no account for payment %s: %w", payment.ID, err)
+	}
+	amount := payment.Amount
+	clearing := ClearingAccount(payment.Provider, amount.Currency)
+	_, err = p.ledger.CreateAccount(ctx, Account{
+		ID:            clearing,
+		Name:          fmt.Sprintf("%s %s clearing", payment.Provider, amount.Currency),
+		Type:          Asset,
+		Currency:      amount.Currency,
+		AllowNegative: true,
+	})
+	if err != nil && !errors.Is(err, ErrAccountExists) {
+		return Entry{}, err
+	}
+
+	debit, credit := clearing, account
+	switch payment.Kind {
+	case rails.KindCollection:
+	case rails.KindDisbursement, rails.KindRefund:
+		debit, credit = account, clearing
+	default:
+		return Entry{}, fmt.Errorf("ledger: payment %s has unknown kind %q", payment.ID, payment.Kind)
+	}
+
+	return Entry{
+		ID:          "payment:" + payment.ID + ":succeeded",
+		Description: fmt.Sprintf("%s %s %s", payment.Provider, payment.Kind, payment.ID),
+		Postings: []Posting{
+			{Account: debit, Side: Debit, Amount: amount},
+			{Account: credit, Side: Credit, Amount: amount},
+		},
+		Metadata: map[string]string{
+			"payment_id":     payment.ID,
+			"provider":       string(payment.Provider),
+			"transaction_id": payment.TransactionID,
+		},
+		PostedAt: reachedAt(payment, lifecycle.StateSucceeded),
+	}, nil
+}
+
+// reachedAt returns when payment last moved to state, or the zero time if
+// its history does not say.
+func reachedAt(payment lifecycle.Payment, state lifecycle.State) (at time.Time) {
+	for _, tr := range payment.History {
+		if tr.To == state {
+			at = tr.At
+		}
+	}
+	return at
+}
+
+func opposite(s Side) Side {
+	if s == Debit {
+		return Credit
+	}
+	return Debit
+}
@@ -0,0 +1,308 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+// sqliteSchema creates the ledger's tables. Amounts are kept as decimal
+// text, so they are summed in Go rather than by SQLite, which would round
+// them through floats. Times are Unix nanoseconds, 0 for none.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+	id             TEXT PRIMARY KEY,
+	name           TEXT NOT NULL,
+	type           TEXT NOT NULL,
+	currency       TEXT NOT NULL,
+	allow_negative INTEGER NOT NULL,
+	created_at     INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ledger_entries (
+	id          TEXT PRIMARY KEY,
+	description TEXT NOT NULL,
+	metadata    TEXT NOT NULL,
+	posted_at   INTEGER NOT NULL,
+	created_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ledger_postings (
+	entry_id   TEXT NOT NULL REFERENCES ledger_entries (id),
+	position   INTEGER NOT NULL,
+	account_id TEXT NOT NULL REFERENCES ledger_accounts (id),
+	side       TEXT NOT NULL,
+	amount     TEXT NOT NULL,
+	currency   TEXT NOT NULL,
+	posted_at  INTEGER NOT NULL,
+	PRIMARY KEY (entry_id, position)
+);
+CREATE INDEX IF NOT EXISTS ledger_postings_account ON ledger_postings (account_id, posted_at);
+CREATE TABLE IF NOT EXISTS ledger_holds (
+	id         TEXT PRIMARY KEY,
+	account_id TEXT NOT NULL REFERENCES ledger_accounts (id),
+	amount     TEXT NOT NULL,
+	currency   TEXT NOT NULL,
+	reason     TEXT NOT NULL,
+	state      TEXT NOT NULL,
+	entry_id   TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ledger_holds_account ON ledger_holds (account_id, state);
+`
+
+// SQLiteStore keeps the ledger in SQLite tables named ledger_*, which
+// NewSQLiteStore creates if they do not exist. db may come from any
+// database/sql SQLite driver. With github.com/mattn/go-sqlite3, open it
+// with "_txlock=immediate" so concurrent transactions wait for each other
+// rather than fail:
+//
+//	db, err := sql.Open("sqlite3", "file:ledger.db?_txlock=immediate&_busy_timeout=5000")
+//	store, err := ledger.NewSQLiteStore(db)
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("failed to create ledger tables: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Transact runs fn in a database transaction, committed if fn returns nil
+// and rolled back otherwise.
+func (s *SQLiteStore) Transact(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin ledger transaction: %w", err)
+	}
+	if err := fn(&sqliteTx{ctx: ctx, tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ledger transaction: %w", err)
+	}
+	return nil
+}
+
+type sqliteTx struct {
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+func (t *sqliteTx) Account(id string) (Account, bool, error) {
+	var (
+		a         Account
+		createdAt int64
+	)
+	err := t.tx.QueryRowContext(t.ctx,
+		`SELECT id, name, type, currency, allow_negative, created_at FROM ledger_accounts WHERE id = ?`, id,
+	).Scan(&a.ID, &a.Name, &a.Type, &a.Currency, &a.AllowNegative, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{}, false, nil
+	}
+	if err != nil {
+		return Account{}, false, fmt.Errorf("failed to read account: %w", err)
+	}
+	a.CreatedAt = fromNanos(createdAt)
+	return a, true, nil
+}
+
+func (t *sqliteTx) CreateAccount(a Account) error {
+	_, err := t.tx.ExecContext(t.ctx,
+		`INSERT INTO ledger_accounts (id, name, type, currency, allow_negative, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		a.ID, a.Name, string(a.Type), string(a.Currency), a.AllowNegative, nanos(a.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+	return nil
+}
+
+func (t *sqliteTx) Entry(id string) (Entry, bool, error) {
+	var (
+		e                   Entry
+		metadata            string
+		postedAt, createdAt int64
+	)
+	err := t.tx.QueryRowContext(t.ctx,
+		`SELECT id, description, metadata, posted_at, created_at FROM ledger_entries WHERE id = ?`, id,
+	).Scan(&e.ID, &e.Description, &metadata, &postedAt, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to read entry: %w", err)
+	}
+	if err := json.Unmarshal([]byte(metadata), &e.Metadata); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to decode entry metadata: %w", err)
+	}
+	e.PostedAt, e.CreatedAt = fromNanos(postedAt), fromNanos(createdAt)
+
+	rows, err := t.tx.QueryContext(t.ctx,
+		`SELECT account_id, side, amount, currency FROM ledger_postings WHERE entry_id = ? ORDER BY position`, id)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("failed to read postings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p              Posting
+			amount, symbol string
+		)
+		if err := rows.Scan(&p.Account, &p.Side, &amount, &symbol); err != nil {
+			return Entry{}, false, fmt.Errorf("failed to read posting: %w", err)
+		}
+		if p.Amount, err = money.Parse(amount, money.Currency(symbol)); err != nil {
+			return Entry{}, false, err
+		}
+		e.Postings = append(e.Postings, p)
+	}
+	if err := rows.Err(); err != nil {
+		return Entry{}, false, fmt.Errorf("failed to read postings: %w", err)
+	}
+	return e, true, nil
+}
+
+func (t *sqliteTx) AddEntry(e Entry) error {
+	metadata, err := json.Marshal(e.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode entry metadata: %w", err)
+	}
+	_, err = t.tx.ExecContext(t.ctx,
+		`INSERT INTO ledger_entries (id, description, metadata, posted_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+		e.ID, e.Description, string(metadata), nanos(e.PostedAt), nanos(e.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add entry: %w", err)
+	}
+	for i, p := range e.Postings {
+		_, err := t.tx.ExecContext(t.ctx,
+			`INSERT INTO ledger_postings (entry_id, position, account_id, side, amount, currency, posted_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			e.ID, i, p.Account, string(p.Side), p.Amount.Amount.String(), string(p.Amount.Currency), nanos(e.PostedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add posting: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *sqliteTx) Totals(account string, at time.Time) (debits, credits decimal.Decimal, err error) {
+	rows, err := t.tx.QueryContext(t.ctx,
+		`SELECT side, amount FROM ledger_postings WHERE account_id = ? AND (? = 0 OR posted_at <= ?)`,
+		account, nanos(at), nanos(at))
+	if err != nil {
+		return debits, credits, fmt.Errorf("failed to read postings: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var side, text string
+		if err := rows.Scan(&side, &text); err != nil {
+			return debits, credits, fmt.Errorf("failed to read posting: %w", err)
+		}
+		amount, err := decimal.NewFromString(text)
+		if err != nil {
+			return debits, credits, fmt.Errorf("invalid posting amount %q: %w", text, err)
+		}
+		if Side(side) == Debit {
+			debits = debits.Add(amount)
+		} else {
+			credits = credits.Add(amount)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return debits, credits, fmt.Errorf("failed to read postings: %w", err)
+	}
+	return debits, credits, nil
+}
+
+const holdColumns = `id, account_id, amount, currency, reason, state, entry_id, expires_at, created_at, updated_at`
+
+func (t *sqliteTx) Hold(id string) (Hold, bool, error) {
+	h, err := scanHold(t.tx.QueryRowContext(t.ctx, `SELECT `+holdColumns+` FROM ledger_holds WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Hold{}, false, nil
+	}
+	if err != nil {
+		return Hold{}, false, err
+	}
+	return h, true, nil
+}
+
+func (t *sqliteTx) SaveHold(h Hold) error {
+	_, err := t.tx.ExecContext(t.ctx,
+		`INSERT INTO ledger_holds (`+holdColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET state = excluded.state, entry_id = excluded.entry_id, updated_at = excluded.updated_at`,
+		h.ID, h.Account, h.Amount.Amount.String(), string(h.Amount.Currency), h.Reason, string(h.State), h.EntryID,
+		nanos(h.ExpiresAt), nanos(h.CreatedAt), nanos(h.UpdatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save hold: %w", err)
+	}
+	return nil
+}
+
+func (t *sqliteTx) ActiveHolds(account string) ([]Hold, error) {
+	rows, err := t.tx.QueryContext(t.ctx,
+		`SELECT `+holdColumns+` FROM ledger_holds WHERE account_id = ? AND state = ?`, account, string(HoldActive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []Hold
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read holds: %w", err)
+	}
+	return holds, nil
+}
+
+func scanHold(row interface{ Scan(...any) error }) (Hold, error) {
+	var (
+		h                               Hold
+		amount, currency                string
+		expiresAt, createdAt, updatedAt int64
+	)
+	err := row.Scan(&h.ID, &h.Account, &amount, &currency, &h.Reason, &h.State, &h.EntryID, &expiresAt, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Hold{}, err
+	}
+	if err != nil {
+		return Hold{}, fmt.Errorf("failed to read hold: %w", err)
+	}
+	if h.Amount, err = money.Parse(amount, money.Currency(currency)); err != nil {
+		return Hold{}, err
+	}
+	h.ExpiresAt, h.CreatedAt, h.UpdatedAt = fromNanos(expiresAt), fromNanos(createdAt), fromNanos(updatedAt)
+	return h, nil
+}
+
+func nanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
@@ -0,0 +1,372 @@
+// Package lifecycle tracks a payment from its creation to its final state.
+// Every change of State goes through a guarded transition and is recorded in
+// the payment's history, so a late callback or a stale poll result cannot
+// move a succeeded payment back to pending:
+//
+//	tracker := lifecycle.New(store)
+//	tracker.Create(ctx, lifecycle.Payment{ID: "order-1001", Provider: rails.ProviderMpesa, Amount: amount})
+//	result, err := adapter.Collect(ctx, req)
+//	tracker.Submitted(ctx, "order-1001", result)
+//	// later, from a callback or a status poll
+//	tracker.Apply(ctx, "order-1001", lifecycle.SourceCallback, meta.Status, rawStatus)
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+var (
+	// ErrInvalidTransition is returned, wrapped in a TransitionError, for a
+	// change of state the machine does not allow.
+	ErrInvalidTransition = errors.New("lifecycle: invalid transition")
+
+	// ErrNotFound is returned for a payment that is not in the store.
+	ErrNotFound = errors.New("lifecycle: payment not found")
+
+	// ErrExists is returned by Store.Create for an ID already in use.
+	ErrExists = errors.New("lifecycle: payment already exists")
+
+	// ErrConflict is returned by Store.Update when the payment was changed
+	// since it was read.
+	ErrConflict = errors.New("lifecycle: payment was changed concurrently")
+
+	// ErrUnknownStatus is returned for a provider status that does not map
+	// onto a State.
+	ErrUnknownStatus = errors.New("lifecycle: unknown provider status")
+)
+
+// State is where a payment is in its lifecycle.
+type State string
+
+const (
+	StateCreated   State = "created"   // Recorded, not yet sent to the provider
+	StateSubmitted State = "submitted" // Sent to the provider, no answer yet
+	StatePending   State = "pending"   // Accepted by the provider, outcome not yet known
+	StateSucceeded State = "succeeded" // Funds moved
+	StateFailed    State = "failed"    // Rejected, declined or cancelled; no funds moved
+	StateReversed  State = "reversed"  // Succeeded and later reversed or refunded
+	StateExpired   State = "expired"   // No outcome in time
+)
+
+// transitions lists the states each state may move to. Expired payments may
+// still succeed or fail, since a provider can report the outcome after the
+// payment was given up on.
+var transitions = map[State][]State{
+	StateCreated:   {StateSubmitted, StatePending, StateSucceeded, StateFailed, StateExpired},
+	StateSubmitted: {StatePending, StateSucceeded, StateFailed, StateExpired},
+	StatePending:   {StateSucceeded, StateFailed, StateExpired},
+	StateSucceeded: {StateReversed},
+	StateExpired:   {StateSucceeded, StateFailed},
+}
+
+// CanTransition reports whether a payment in state from may move to to.
+func CanTransition(from, to State) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// Final reports whether the payment has an outcome. Only a reversal, or a
+// late outcome for an expired payment, changes it.
+func (s State) Final() bool {
+	switch s {
+	case StateSucceeded, StateFailed, StateReversed, StateExpired:
+		return true
+	}
+	return false
+}
+
+// TransitionError describes a refused transition.
+type TransitionError struct {
+	PaymentID string
+	From, To  State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("lifecycle: payment %s cannot move from %s to %s", e.PaymentID, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Source is what reported a transition.
+type Source string
+
+const (
+	SourceClient   Source = "client"   // The merchant's own code
+	SourceSubmit   Source = "submit"   // The provider's answer to the request
+	SourceCallback Source = "callback" // A webhook or callback
+	SourcePoll     Source = "poll"     // A status query
+)
+
+// Transition is a recorded change of state.
+type Transition struct {
+	From           State     `json:"from"`
+	To             State     `json:"to"`
+	Source         Source    `json:"source"`
+	ProviderStatus string    `json:"provider_status,omitempty"` // The raw status or result code
+	Reason         string    `json:"reason,omitempty"`
+	At             time.Time `json:"at"`
+}
+
+// Payment is a tracked payment.
+type Payment struct {
+	ID             string         `json:"id"` // The merchant's ID, e.g. an order or payout ID
+	Provider       rails.Provider `json:"provider"`
+	Kind           rails.Kind     `json:"kind,omitempty"`
+	TransactionID  string         `json:"transaction_id,omitempty"` // Set once the provider accepts the payment
+	Reference      string         `json:"reference,omitempty"`
+	Amount         money.Money    `json:"amount"`
+	State          State          `json:"state"`
+	ProviderStatus string         `json:"provider_status,omitempty"`
+	History        []Transition   `json:"history,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	// Version is incremented by each update, for Store.Update to detect
+	// concurrent changes.
+	Version int `json:"version"`
+}
+
+// Store keeps payments by ID. Implementations backed by a database must
+// make Update a compare-and-swap on Version.
+type Store interface {
+	// Create saves p, or fails with ErrExists if its ID is in use.
+	Create(ctx context.Context, p Payment) error
+
+	// Get returns the payment with id, and false if there is none.
+	Get(ctx context.Context, id string) (Payment, bool, error)
+
+	// FindByTransaction returns the payment a provider knows by
+	// transactionID, and false if there is none.
+	FindByTransaction(ctx context.Context, provider rails.Provider, transactionID string) (Payment, bool, error)
+
+	// Update replaces the payment with p.ID if its Version is still
+	// p.Version-1, or fails with ErrConflict.
+	Update(ctx context.Context, p Payment) error
+}
+
+// FromStatus maps a normalized Status onto a State. Cancelled payments are
+// failed; the second result is false for StatusUnknown.
+func FromStatus(status rails.Status) (State, bool) {
+	switch status {
+	case rails.StatusPending:
+		return StatePending, true
+	case rails.StatusSucceeded:
+		return StateSucceeded, true
+	case rails.StatusFailed, rails.StatusCancelled:
+		return StateFailed, true
+	case rails.StatusExpired:
+		return StateExpired, true
+	case rails.StatusReversed:
+		return StateReversed, true
+	}
+	return "", false
+}
+
+// ProviderStatus maps a provider's raw status or result code onto a Status:
+// an STK Push ResultCode for M-Pesa, an Airtel status code such as "TS",
+// a MoMo status such as "SUCCESSFUL", or the status text of KCB, Jenga and
+// SasaPay.
+func ProviderStatus(provider rails.Provider, code string) rails.Status {
+	switch provider {
+	case rails.ProviderMpesa:
+		return rails.MpesaSTKStatus(code)
+	case rails.ProviderAirtel:
+		return rails.AirtelStatus(code)
+	case rails.ProviderMomo:
+		return rails.MomoStatus(code, "")
+	case rails.ProviderKCB:
+		return rails.KCBStatus(code)
+	case rails.ProviderJenga:
+		return rails.JengaStatus(code)
+	case rails.ProviderSasaPay:
+		return rails.SasaPayStatus(code)
+	}
+	return rails.ParseStatus(code)
+}
+
+// maxConflicts is how many times a transition is retried after a
+// concurrent update.
+const maxConflicts = 5
+
+// TransitionHook is called after a transition is saved, with the payment as
+// saved. Its error is returned from the call that made the transition, which
+// stays saved.
+type TransitionHook func(ctx context.Context, p Payment, tr Transition) error
+
+// Tracker moves payments through their lifecycle and persists every
+// transition in a Store.
+type Tracker struct {
+	store Store
+	hook  TransitionHook
+}
+
+func New(store Store) *Tracker {
+	return &Tracker{store: store}
+}
+
+// SetTransitionHook sets a hook called for each transition, e.g. to post
+// succeeded payments to a ledger. It is called once per transition, in
+// order, and not for repeated statuses.
+func (t *Tracker) SetTransitionHook(hook TransitionHook) {
+	t.hook = hook
+}
+
+// Create records a new payment in StateCreated.
+func (t *Tracker) Create(ctx context.Context, p Payment) (Payment, error) {
+	if p.ID == "" {
+		return Payment{}, errors.New("lifecycle: payment ID is required")
+	}
+	now := time.Now()
+	p.State, p.History, p.Version = StateCreated, nil, 1
+	p.CreatedAt, p.UpdatedAt = now, now
+	if err := t.store.Create(ctx, p); err != nil {
+		return Payment{}, err
+	}
+	return p, nil
+}
+
+// Get returns the payment with id, or ErrNotFound.
+func (t *Tracker) Get(ctx context.Context, id string) (Payment, error) {
+	p, found, err := t.store.Get(ctx, id)
+	if err != nil {
+		return Payment{}, err
+	}
+	if !found {
+		return Payment{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return p, nil
+}
+
+// Transition moves the payment with id to state to, recording tr as the
+// reason. Moving to the payment's current state is a no-op. A transition
+// the machine does not allow fails with a *TransitionError and leaves the
+// payment unchanged.
+func (t *Tracker) Transition(ctx context.Context, id string, to State, tr Transition) (Payment, error) {
+	return t.update(ctx, id, func(p *Payment) error {
+		if p.State == to {
+			return errUnchanged
+		}
+		if !CanTransition(p.State, to) {
+			return &TransitionError{PaymentID: p.ID, From: p.State, To: to}
+		}
+		tr.From, tr.To, tr.At = p.State, to, time.Now()
+		p.State, p.UpdatedAt = to, tr.At
+		if tr.ProviderStatus != "" {
+			p.ProviderStatus = tr.ProviderStatus
+		}
+		p.History = append(p.History, tr)
+		return nil
+	})
+}
+
+// Submitted records the provider's answer to a payment request: its
+// transaction ID, and a move to StateSubmitted and then to the result's
+// status if it maps onto a State. A callback may have moved the payment on
+// already, in which case the answer does not change its state.
+func (t *Tracker) Submitted(ctx context.Context, id string, result *rails.PaymentResult) (Payment, error) {
+	to, mapped := FromStatus(result.Status)
+	return t.update(ctx, id, func(p *Payment) error {
+		changed := false
+		if result.TransactionID != "" && p.TransactionID != result.TransactionID {
+			p.TransactionID, changed = result.TransactionID, true
+		}
+		if result.ProviderStatus != "" {
+			p.ProviderStatus = result.ProviderStatus
+		}
+
+		now := time.Now()
+		if p.State == StateCreated {
+			p.History = append(p.History, Transition{From: p.State, To: StateSubmitted, Source: SourceSubmit, ProviderStatus: result.ProviderStatus, At: now})
+			p.State, changed = StateSubmitted, true
+		}
+		if mapped && p.State == StateSubmitted && CanTransition(p.State, to) {
+			p.History = append(p.History, Transition{From: p.State, To: to, Source: SourceSubmit, ProviderStatus: result.ProviderStatus, Reason: result.Message, At: now})
+			p.State, changed = to, true
+		}
+		if !changed {
+			return errUnchanged
+		}
+		p.UpdatedAt = now
+		return nil
+	})
+}
+
+// Apply moves the payment with id to the State status maps onto, as
+// reported by source. providerStatus is the raw status, kept for audit. A
+// status that does not map onto a State fails with ErrUnknownStatus.
+func (t *Tracker) Apply(ctx context.Context, id string, source Source, status rails.Status, providerStatus string) (Payment, error) {
+	to, ok := FromStatus(status)
+	if !ok {
+		return Payment{}, fmt.Errorf("%w: %q", ErrUnknownStatus, providerStatus)
+	}
+	return t.Transition(ctx, id, to, Transition{Source: source, ProviderStatus: providerStatus})
+}
+
+// ApplyTransaction is Apply for a payment found by the provider's
+// transaction ID, e.g. from a callback.
+func (t *Tracker) ApplyTransaction(ctx context.Context, provider rails.Provider, transactionID string, source Source, status rails.Status, providerStatus string) (Payment, error) {
+	p, found, err := t.store.FindByTransaction(ctx, provider, transactionID)
+	if err != nil {
+		return Payment{}, err
+	}
+	if !found {
+		return Payment{}, fmt.Errorf("%w: %s transaction %s", ErrNotFound, provider, transactionID)
+	}
+	return t.Apply(ctx, p.ID, source, status, providerStatus)
+}
+
+// errUnchanged stops update without saving.
+var errUnchanged = errors.New("unchanged")
+
+// update applies change to the payment with id and saves it, starting over
+// when the payment was changed concurrently.
+func (t *Tracker) update(ctx context.Context, id string, change func(*Payment) error) (Payment, error) {
+	for attempt := 1; ; attempt++ {
+		p, err := t.Get(ctx, id)
+		if err != nil {
+			return Payment{}, err
+		}
+		recorded := len(p.History)
+		if err := change(&p); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return p, nil
+			}
+			return p, err
+		}
+		p.Version++
+		err = t.store.Update(ctx, p)
+		if err == nil {
+			return p, t.notify(ctx, p, p.History[recorded:])
+		}
+		if !errors.Is(err, ErrConflict) || attempt == maxConflicts {
+			return Payment{}, err
+		}
+	}
+}
+
+// notify calls the hook for each of transitions.
+func (t *Tracker) notify(ctx context.Context, p Payment, transitions []Transition) error {
+	if t.hook == nil {
+		return nil
+	}
+	for _, tr := range transitions {
+		if err := t.hook(ctx, p, tr); err != nil {
+			return fmt.Errorf("lifecycle: transition hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// transactionKey is how stores index payments by transaction ID.
+func transactionKey(provider rails.Provider, transactionID string) string {
+	return string(provider) + ":" + strings.TrimSpace(transactionID)
+}
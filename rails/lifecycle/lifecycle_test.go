@@ -0,0 +1,237 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to State
+		want     bool
+	}{
+		{StateCreated, StateSubmitted, true},
+		{StateSubmitted, StatePending, true},
+		{StatePending, StateSucceeded, true},
+		{StateSucceeded, StateReversed, true},
+		{StateExpired, StateSucceeded, true},
+		{StateSucceeded, StatePending, false},
+		{StateSucceeded, StateFailed, false},
+		{StateFailed, StateSucceeded, false},
+		{StateReversed, StateSucceeded, false},
+		{StatePending, StateSubmitted, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, expected %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(NewMemoryStore())
+
+	if _, err := tracker.Create(ctx, Payment{ID: "order-1001", Provider: rails.ProviderMpesa}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := tracker.Create(ctx, Payment{ID: "order-1001"}); !errors.Is(err, ErrExists) {
+		t.Errorf("Expected ErrExists, got %v", err)
+	}
+
+	p, err := tracker.Submitted(ctx, "order-1001", &rails.PaymentResult{
+		TransactionID:  "ws_CO_191220191020363925",
+		Status:         rails.StatusPending,
+		ProviderStatus: "0",
+	})
+	if err != nil {
+		t.Fatalf("Submitted failed: %v", err)
+	}
+	if p.State != StatePending || p.TransactionID != "ws_CO_191220191020363925" || len(p.History) != 2 {
+		t.Errorf("Expected a pending payment, got %+v", p)
+	}
+
+	p, err = tracker.ApplyTransaction(ctx, rails.ProviderMpesa, "ws_CO_191220191020363925", SourceCallback, ProviderStatus(rails.ProviderMpesa, "0"), "0")
+	if err != nil {
+		t.Fatalf("ApplyTransaction failed: %v", err)
+	}
+	if p.State != StateSucceeded {
+		t.Errorf("Expected the callback to succeed the payment, got %s", p.State)
+	}
+
+	// A late poll result must not move the payment back
+	_, err = tracker.Apply(ctx, "order-1001", SourcePoll, rails.StatusPending, "PENDING")
+	var terr *TransitionError
+	if !errors.As(err, &terr) || terr.From != StateSucceeded || terr.To != StatePending {
+		t.Errorf("Expected a TransitionError, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	// A repeated callback is a no-op
+	p, err = tracker.Apply(ctx, "order-1001", SourceCallback, rails.StatusSucceeded, "0")
+	if err != nil || len(p.History) != 3 {
+		t.Errorf("Expected a repeat to change nothing, got %d transitions, err=%v", len(p.History), err)
+	}
+
+	if _, err := tracker.Apply(ctx, "order-1001", SourcePoll, rails.StatusUnknown, "ON_HOLD"); !errors.Is(err, ErrUnknownStatus) {
+		t.Errorf("Expected ErrUnknownStatus, got %v", err)
+	}
+	if _, err := tracker.Apply(ctx, "order-404", SourcePoll, rails.StatusSucceeded, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	p, _ = tracker.Get(ctx, "order-1001")
+	want := []State{StateSubmitted, StatePending, StateSucceeded}
+	for i, tr := range p.History {
+		if tr.To != want[i] {
+			t.Errorf("Transition %d: expected %s, got %s", i, want[i], tr.To)
+		}
+	}
+	if last := p.History[len(p.History)-1]; last.Source != SourceCallback || last.From != StatePending {
+		t.Errorf("Unexpected transition %+v", last)
+	}
+}
+
+func TestSubmittedAfterCallback(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(NewMemoryStore())
+	tracker.Create(ctx, Payment{ID: "payout-1", Provider: rails.ProviderMomo})
+
+	// The callback arrived before the answer to the request was recorded
+	tracker.Apply(ctx, "payout-1", SourceCallback, rails.StatusSucceeded, "SUCCESSFUL")
+	p, err := tracker.Submitted(ctx, "payout-1", &rails.PaymentResult{TransactionID: "ref-1", Status: rails.StatusPending})
+	if err != nil {
+		t.Fatalf("Submitted failed: %v", err)
+	}
+	if p.State != StateSucceeded || p.TransactionID != "ref-1" {
+		t.Errorf("Expected the payment to stay succeeded with its transaction ID, got %+v", p)
+	}
+}
+
+func TestConcurrentTransitions(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(NewMemoryStore())
+	tracker.Create(ctx, Payment{ID: "order-1", Provider: rails.ProviderAirtel})
+	tracker.Transition(ctx, "order-1", StatePending, Transition{Source: SourceSubmit})
+
+	var wg sync.WaitGroup
+	results := make([]error, 2)
+	for i, status := range []rails.Status{rails.StatusSucceeded, rails.StatusFailed} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, results[i] = tracker.Apply(ctx, "order-1", SourceCallback, status, "")
+		}()
+	}
+	wg.Wait()
+
+	failures := 0
+	for _, err := range results {
+		if errors.Is(err, ErrInvalidTransition) {
+			failures++
+		}
+	}
+	p, _ := tracker.Get(ctx, "order-1")
+	if failures != 1 || (p.State != StateSucceeded && p.State != StateFailed) {
+		t.Errorf("Expected exactly one outcome to win, got %s with %v", p.State, results)
+	}
+}
+
+func TestProviderStatus(t *testing.T) {
+	tests := []struct {
+		provider rails.Provider
+		code     string
+		want     State
+	}{
+		{rails.ProviderMpesa, "0", StateSucceeded},
+		{rails.ProviderMpesa, "1032", StateFailed},
+		{rails.ProviderMpesa, "1037", StateExpired},
+		{rails.ProviderMpesa, "4999", StatePending},
+		{rails.ProviderMomo, "SUCCESSFUL", StateSucceeded},
+		{rails.ProviderMomo, "FAILED", StateFailed},
+		{rails.ProviderKCB, "PENDING", StatePending},
+		{rails.ProviderAirtel, "TS", StateSucceeded},
+		{rails.ProviderAirtel, "TIP", StatePending},
+		{rails.ProviderJenga, "reversed", StateReversed},
+	}
+	for _, tt := range tests {
+		got, ok := FromStatus(ProviderStatus(tt.provider, tt.code))
+		if !ok || got != tt.want {
+			t.Errorf("%s %s: expected %s, got %s", tt.provider, tt.code, tt.want, got)
+		}
+	}
+	if _, ok := FromStatus(ProviderStatus(rails.ProviderKCB, "ON_HOLD")); ok {
+		t.Error("Expected an unmapped status not to map onto a state")
+	}
+	if _, ok := FromStatus(ProviderStatus(rails.ProviderMpesa, "4321")); ok {
+		t.Error("Expected an unlisted M-Pesa code not to map onto a state")
+	}
+}
+
+func TestMpesaPollStillProcessing(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(NewMemoryStore())
+	tracker.Create(ctx, Payment{ID: "order-1", Provider: rails.ProviderMpesa})
+	tracker.Submitted(ctx, "order-1", &rails.PaymentResult{TransactionID: "ws_CO_1", Status: rails.StatusPending, ProviderStatus: "0"})
+
+	// The query API answers 4999 until the customer enters their PIN
+	p, err := tracker.ApplyTransaction(ctx, rails.ProviderMpesa, "ws_CO_1", SourcePoll, ProviderStatus(rails.ProviderMpesa, "4999"), "4999")
+	if err != nil || p.State != StatePending {
+		t.Fatalf("Expected the poll to leave the payment pending, got %s, err=%v", p.State, err)
+	}
+
+	p, err = tracker.ApplyTransaction(ctx, rails.ProviderMpesa, "ws_CO_1", SourceCallback, ProviderStatus(rails.ProviderMpesa, "0"), "0")
+	if err != nil {
+		t.Fatalf("ApplyTransaction failed: %v", err)
+	}
+	if p.State != StateSucceeded {
+		t.Errorf("Expected the callback to succeed the payment, got %s", p.State)
+	}
+
+	if _, err := tracker.Apply(ctx, "order-1", SourcePoll, ProviderStatus(rails.ProviderMpesa, "4321"), "4321"); !errors.Is(err, ErrUnknownStatus) {
+		t.Errorf("Expected ErrUnknownStatus for an unlisted code, got %v", err)
+	}
+}
+
+func TestTransitionHook(t *testing.T) {
+	ctx := context.Background()
+	tracker := New(NewMemoryStore())
+
+	var seen []State
+	tracker.SetTransitionHook(func(ctx context.Context, p Payment, tr Transition) error {
+		seen = append(seen, tr.To)
+		if tr.To == StateSucceeded {
+			return errors.New("ledger down")
+		}
+		return nil
+	})
+	tracker.Create(ctx, Payment{ID: "order-1", Provider: rails.ProviderKCB})
+	tracker.Submitted(ctx, "order-1", &rails.PaymentResult{TransactionID: "tx-1", Status: rails.StatusPending})
+
+	p, err := tracker.Apply(ctx, "order-1", SourceCallback, rails.StatusSucceeded, "SUCCESS")
+	if err == nil || p.State != StateSucceeded {
+		t.Errorf("Expected the hook error with the transition saved, got %s, err=%v", p.State, err)
+	}
+	if stored, _ := tracker.Get(ctx, "order-1"); stored.State != StateSucceeded {
+		t.Errorf("Expected the transition to stay saved, got %s", stored.State)
+	}
+
+	// A repeated status is not a transition
+	tracker.Apply(ctx, "order-1", SourceCallback, rails.StatusSucceeded, "SUCCESS")
+
+	want := []State{StateSubmitted, StatePending, StateSucceeded}
+	if len(seen) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("Transition %d: expected %s, got %s", i, want[i], seen[i])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package lifecycle
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// MemoryStore keeps payments in process memory. It is meant for tests and
+// single-process development.
+type MemoryStore struct {
+	mu           sync.Mutex
+	payments     map[string]Payment
+	transactions map[string]string // transactionKey to payment ID
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		payments:     make(map[string]Payment),
+		transactions: make(map[string]string),
+	}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, p Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.payments[p.ID]; found {
+		return ErrExists
+	}
+	s.save(p)
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Payment, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, found := s.payments[id]
+	p.History = slices.Clone(p.History)
+	return p, found, nil
+}
+
+func (s *MemoryStore) FindByTransaction(ctx context.Context, provider rails.Provider, transactionID string) (Payment, bool, error) {
+	s.mu.Lock()
+	id, found := s.transactions[transactionKey(provider, transactionID)]
+	s.mu.Unlock()
+
+	if !found {
+		return Payment{}, false, nil
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *MemoryStore) Update(ctx context.Context, p Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, found := s.payments[p.ID]
+	if !found {
+		return ErrNotFound
+	}
+	if current.Version != p.Version-1 {
+		return ErrConflict
+	}
+	s.save(p)
+	return nil
+}
+
+func (s *MemoryStore) save(p Payment) {
+	p.History = slices.Clone(p.History)
+	s.payments[p.ID] = p
+	if p.TransactionID != "" {
+		s.transactions[transactionKey(p.Provider, p.TransactionID)] = p.ID
+	}
+}
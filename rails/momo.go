@@ -0,0 +1,239 @@
+package rails
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nutcas3/payment-rails/momo"
+	"github.com/nutcas3/payment-rails/momo/collection"
+	"github.com/nutcas3/payment-rails/momo/common/types"
+	"github.com/nutcas3/payment-rails/momo/disbursement"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+type MomoConfig struct {
+	Currency    string         // Used when a request has no currency, EUR in the sandbox
+	Country     msisdn.Country // Of phone numbers in local format; defaults to the country of the currency
+	CallbackURL string
+}
+
+// momoCountries is the country whose local phone numbers are assumed for
+// each currency MTN MoMo transacts in, when MomoConfig has no Country.
+var momoCountries = map[string]msisdn.Country{
+	"GHS": msisdn.Ghana,
+	"NGN": msisdn.Nigeria,
+	"RWF": msisdn.Rwanda,
+	"UGX": msisdn.Uganda,
+	"ZAR": msisdn.SouthAfrica,
+	"ZMW": msisdn.Zambia,
+}
+
+// MomoAdapter collects through Request to Pay and disburses and refunds
+// through the Disbursement product. Every request is sent with a fresh
+// X-Reference-Id, which is the TransactionID to query, unless it has an
+// IdempotencyKey; see momoReferenceID.
+type MomoAdapter struct {
+	collection   collection.Service
+	disbursement disbursement.Service
+	config       MomoConfig
+}
+
+func NewMomoAdapter(client *momo.Client, config MomoConfig) *MomoAdapter {
+	return &MomoAdapter{
+		collection:   client.Collection,
+		disbursement: client.Disbursement,
+		config:       config,
+	}
+}
+
+func (a *MomoAdapter) Provider() Provider {
+	return ProviderMomo
+}
+
+func (a *MomoAdapter) currency() string {
+	return a.config.Currency
+}
+
+// phone formats phone for MoMo, reading a local number as one from the
+// configured country or else the country of currency.
+func (a *MomoAdapter) phone(phone, currency string) string {
+	country := a.config.Country
+	if country == "" {
+		country = momoCountries[strings.ToUpper(currency)]
+	}
+	return adapterPhone(ProviderMomo, phone, country)
+}
+
+func (a *MomoAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	if a.collection == nil {
+		return nil, idempotency.NotSent(fmt.Errorf("momo collection subscription key is not configured"))
+	}
+
+	currency := firstNonEmpty(req.Currency, a.config.Currency)
+	refID := momoReferenceID(ctx)
+	ctx = telemetry.Name(ctx, "collection.RequestToPay")
+	_, err := a.collection.RequestToPay(ctx, refID, firstNonEmpty(req.CallbackURL, a.config.CallbackURL), false, types.RequestToPayInput{
+		Amount:       req.Amount.String(),
+		ExternalID:   req.Reference,
+		PayerMessage: req.Description,
+		PayeeNote:    req.Description,
+		Currency:     types.Currency(currency),
+		Payer:        types.Party{PartyIDType: types.MSISDN, PartyID: a.phone(req.Phone, currency)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.accepted(KindCollection, refID, req.Reference), nil
+}
+
+func (a *MomoAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	if a.disbursement == nil {
+		return nil, idempotency.NotSent(fmt.Errorf("momo disbursement subscription key is not configured"))
+	}
+
+	currency := firstNonEmpty(req.Currency, a.config.Currency)
+	refID := momoReferenceID(ctx)
+	ctx = telemetry.Name(ctx, "disbursement.Transfer")
+	err := a.disbursement.Transfer(ctx, refID, firstNonEmpty(req.CallbackURL, a.config.CallbackURL), types.TransferInput{
+		Amount:       req.Amount.String(),
+		Currency:     types.Currency(currency),
+		ExternalID:   req.Reference,
+		Payee:        types.Party{PartyIDType: types.MSISDN, PartyID: a.phone(req.Phone, currency)},
+		PayerMessage: req.Description,
+		PayeeNote:    req.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.accepted(KindDisbursement, refID, req.Reference), nil
+}
+
+func (a *MomoAdapter) Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error) {
+	if a.disbursement == nil {
+		return nil, idempotency.NotSent(fmt.Errorf("momo disbursement subscription key is not configured"))
+	}
+
+	refID := momoReferenceID(ctx)
+	ctx = telemetry.Name(ctx, "disbursement.RefundV2")
+	err := a.disbursement.RefundV2(ctx, refID, firstNonEmpty(req.CallbackURL, a.config.CallbackURL), types.RefundInput{
+		Amount:              req.Amount.String(),
+		Currency:            types.Currency(firstNonEmpty(req.Currency, a.config.Currency)),
+		ExternalID:          req.Reference,
+		PayerMessage:        req.Reason,
+		PayeeNote:           req.Reason,
+		ReferenceIDToRefund: req.TransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.accepted(KindRefund, refID, req.Reference), nil
+}
+
+func (a *MomoAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	refID, err := uuid.Parse(req.TransactionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid momo reference ID %q: %w", req.TransactionID, err)
+	}
+
+	var status, externalID string
+	var reason types.ErrorReason
+	var raw interface{}
+
+	switch req.Kind {
+	case KindCollection, "":
+		if a.collection == nil {
+			return nil, fmt.Errorf("momo collection subscription key is not configured")
+		}
+		ctx = telemetry.Name(ctx, "collection.RequestToPayTransactionStatus")
+		resp, err := a.collection.RequestToPayTransactionStatus(ctx, refID)
+		if err != nil {
+			return nil, err
+		}
+		status, externalID, reason, raw = resp.Status, resp.ExternalID, resp.Reason, resp
+	case KindDisbursement, KindRefund:
+		if a.disbursement == nil {
+			return nil, fmt.Errorf("momo disbursement subscription key is not configured")
+		}
+		get, name := a.disbursement.GetTransferStatus, "disbursement.GetTransferStatus"
+		if req.Kind == KindRefund {
+			get, name = a.disbursement.GetRefundStatus, "disbursement.GetRefundStatus"
+		}
+		resp, err := get(telemetry.Name(ctx, name), refID)
+		if err != nil {
+			return nil, err
+		}
+		status, externalID, reason, raw = resp.Status, resp.ExternalID, resp.Reason, resp
+	default:
+		return nil, ErrNotSupported
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = KindCollection
+	}
+	normalized := MomoStatus(status, reason.Code)
+	if kind == KindRefund && normalized == StatusSucceeded {
+		normalized = StatusReversed
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderMomo,
+		Kind:           kind,
+		TransactionID:  req.TransactionID,
+		Reference:      externalID,
+		Status:         normalized,
+		ProviderStatus: firstNonEmpty(reason.Code, status),
+		Message:        reason.Message,
+		Raw:            raw,
+	}, nil
+}
+
+// MomoStatus maps a MoMo transaction status and, for failures, its reason
+// code onto a Status.
+func MomoStatus(status, reasonCode string) Status {
+	switch strings.ToUpper(status) {
+	case "SUCCESSFUL":
+		return StatusSucceeded
+	case "PENDING", "CREATED", "ONGOING":
+		return StatusPending
+	case "FAILED", "REJECTED", "TIMEOUT":
+		switch strings.ToUpper(reasonCode) {
+		case "APPROVAL_REJECTED", "TRANSACTION_CANCELED":
+			return StatusCancelled
+		case "EXPIRED":
+			return StatusExpired
+		}
+		if strings.EqualFold(status, "TIMEOUT") {
+			return StatusExpired
+		}
+		return StatusFailed
+	}
+	return statusFromText(status)
+}
+
+// momoReferenceID returns the X-Reference-Id for a new request. With an
+// idempotency key in ctx it is derived from the key, so MoMo rejects a repeat
+// as a duplicate instead of paying it again.
+func momoReferenceID(ctx context.Context) uuid.UUID {
+	if key := idempotency.FromContext(ctx); key != "" {
+		return uuid.NewSHA1(uuid.NameSpaceOID, []byte(key))
+	}
+	return uuid.New()
+}
+
+func (a *MomoAdapter) accepted(kind Kind, refID uuid.UUID, reference string) *PaymentResult {
+	return &PaymentResult{
+		Provider:      ProviderMomo,
+		Kind:          kind,
+		TransactionID: refID.String(),
+		Reference:     reference,
+		Status:        StatusPending,
+	}
+}
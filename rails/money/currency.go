@@ -0,0 +1,227 @@
+package money
+
+// Currency is an ISO 4217 currency code.
+//
+// https://en.wikipedia.org/wiki/ISO_4217
+type Currency string
+
+const (
+	AED Currency = "AED"
+	AFN Currency = "AFN"
+	ALL Currency = "ALL"
+	AMD Currency = "AMD"
+	AOA Currency = "AOA"
+	ARS Currency = "ARS"
+	AUD Currency = "AUD"
+	AWG Currency = "AWG"
+	AZN Currency = "AZN"
+	BAM Currency = "BAM"
+	BBD Currency = "BBD"
+	BDT Currency = "BDT"
+	BGN Currency = "BGN"
+	BHD Currency = "BHD"
+	BIF Currency = "BIF"
+	BMD Currency = "BMD"
+	BND Currency = "BND"
+	BOB Currency = "BOB"
+	BOV Currency = "BOV"
+	BRL Currency = "BRL"
+	BSD Currency = "BSD"
+	BTN Currency = "BTN"
+	BWP Currency = "BWP"
+	BYN Currency = "BYN"
+	BZD Currency = "BZD"
+	CAD Currency = "CAD"
+	CDF Currency = "CDF"
+	CHE Currency = "CHE"
+	CHF Currency = "CHF"
+	CHW Currency = "CHW"
+	CLF Currency = "CLF"
+	CLP Currency = "CLP"
+	CNY Currency = "CNY"
+	COP Currency = "COP"
+	COU Currency = "COU"
+	CRC Currency = "CRC"
+	CUP Currency = "CUP"
+	CVE Currency = "CVE"
+	CZK Currency = "CZK"
+	DJF Currency = "DJF"
+	DKK Currency = "DKK"
+	DOP Currency = "DOP"
+	DZD Currency = "DZD"
+	EGP Currency = "EGP"
+	ERN Currency = "ERN"
+	ETB Currency = "ETB"
+	EUR Currency = "EUR"
+	FJD Currency = "FJD"
+	FKP Currency = "FKP"
+	GBP Currency = "GBP"
+	GEL Currency = "GEL"
+	GHS Currency = "GHS"
+	GIP Currency = "GIP"
+	GMD Currency = "GMD"
+	GNF Currency = "GNF"
+	GTQ Currency = "GTQ"
+	GYD Currency = "GYD"
+	HKD Currency = "HKD"
+	HNL Currency = "HNL"
+	HTG Currency = "HTG"
+	HUF Currency = "HUF"
+	IDR Currency = "IDR"
+	ILS Currency = "ILS"
+	INR Currency = "INR"
+	IQD Currency = "IQD"
+	IRR Currency = "IRR"
+	ISK Currency = "ISK"
+	JMD Currency = "JMD"
+	JOD Currency = "JOD"
+	JPY Currency = "JPY"
+	KES Currency = "KES"
+	KGS Currency = "KGS"
+	KHR Currency = "KHR"
+	KMF Currency = "KMF"
+	KPW Currency = "KPW"
+	KRW Currency = "KRW"
+	KWD Currency = "KWD"
+	KYD Currency = "KYD"
+	KZT Currency = "KZT"
+	LAK Currency = "LAK"
+	LBP Currency = "LBP"
+	LKR Currency = "LKR"
+	LRD Currency = "LRD"
+	LSL Currency = "LSL"
+	LYD Currency = "LYD"
+	MAD Currency = "MAD"
+	MDL Currency = "MDL"
+	MGA Currency = "MGA"
+	MKD Currency = "MKD"
+	MMK Currency = "MMK"
+	MNT Currency = "MNT"
+	MOP Currency = "MOP"
+	MRU Currency = "MRU"
+	MUR Currency = "MUR"
+	MVR Currency = "MVR"
+	MWK Currency = "MWK"
+	MXN Currency = "MXN"
+	MXV Currency = "MXV"
+	MYR Currency = "MYR"
+	MZN Currency = "MZN"
+	NAD Currency = "NAD"
+	NGN Currency = "NGN"
+	NIO Currency = "NIO"
+	NOK Currency = "NOK"
+	NPR Currency = "NPR"
+	NZD Currency = "NZD"
+	OMR Currency = "OMR"
+	PAB Currency = "PAB"
+	PEN Currency = "PEN"
+	PGK Currency = "PGK"
+	PHP Currency = "PHP"
+	PKR Currency = "PKR"
+	PLN Currency = "PLN"
+	PYG Currency = "PYG"
+	QAR Currency = "QAR"
+	RON Currency = "RON"
+	RSD Currency = "RSD"
+	RUB Currency = "RUB"
+	RWF Currency = "RWF"
+	SAR Currency = "SAR"
+	SBD Currency = "SBD"
+	SCR Currency = "SCR"
+	SDG Currency = "SDG"
+	SEK Currency = "SEK"
+	SGD Currency = "SGD"
+	SHP Currency = "SHP"
+	SLE Currency = "SLE"
+	SOS Currency = "SOS"
+	SRD Currency = "SRD"
+	SSP Currency = "SSP"
+	STN Currency = "STN"
+	SVC Currency = "SVC"
+	SYP Currency = "SYP"
+	SZL Currency = "SZL"
+	THB Currency = "THB"
+	TJS Currency = "TJS"
+	TMT Currency = "TMT"
+	TND Currency = "TND"
+	TOP Currency = "TOP"
+	TRY Currency = "TRY"
+	TTD Currency = "TTD"
+	TWD Currency = "TWD"
+	TZS Currency = "TZS"
+	UAH Currency = "UAH"
+	UGX Currency = "UGX"
+	USD Currency = "USD"
+	USN Currency = "USN"
+	UYI Currency = "UYI"
+	UYU Currency = "UYU"
+	UYW Currency = "UYW"
+	UZS Currency = "UZS"
+	VED Currency = "VED"
+	VES Currency = "VES"
+	VND Currency = "VND"
+	VUV Currency = "VUV"
+	WST Currency = "WST"
+	XAD Currency = "XAD"
+	XAF Currency = "XAF"
+	XAG Currency = "XAG"
+	XAU Currency = "XAU"
+	XBA Currency = "XBA"
+	XBB Currency = "XBB"
+	XBC Currency = "XBC"
+	XBD Currency = "XBD"
+	XCD Currency = "XCD"
+	XCG Currency = "XCG"
+	XDR Currency = "XDR"
+	XOF Currency = "XOF"
+	XPD Currency = "XPD"
+	XPF Currency = "XPF"
+	XPT Currency = "XPT"
+	XSU Currency = "XSU"
+	XTS Currency = "XTS"
+	XUA Currency = "XUA"
+	YER Currency = "YER"
+	ZAR Currency = "ZAR"
+	ZMW Currency = "ZMW"
+	ZWG Currency = "ZWG"
+)
+
+// minorUnits lists the currencies whose minor unit is not a hundredth, such
+// as UGX and RWF, which have none, and KWD, which has thousandths.
+var minorUnits = map[Currency]int32{
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"ISK": 0,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"PYG": 0,
+	"RWF": 0,
+	"UGX": 0,
+	"UYI": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+	"BHD": 3,
+	"IQD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"CLF": 4,
+	"UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places in the currency's minor
+// unit, which is 2 for currencies not known to differ.
+func (c Currency) MinorUnits() int32 {
+	if n, ok := minorUnits[c]; ok {
+		return n
+	}
+	return 2
+}
@@ -0,0 +1,112 @@
+// Package money represents amounts as a decimal and a currency, so amounts
+// are never rounded through float64 on the way to or from a provider.
+//
+// Providers disagree on how an amount is sent: Absa and SasaPay expect a
+// string, M-Pesa and H2H files whole or minor units, and the banks a JSON
+// number with two decimal places. Money converts to each of these, and
+// Number is the field type for the last.
+package money
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts in different
+// currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an amount in a currency.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency Currency        `json:"currency"`
+}
+
+// New returns amount in currency.
+func New(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse returns the amount in s, such as "1500.50", in currency.
+func Parse(s string, currency Currency) (Money, error) {
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return New(amount, currency), nil
+}
+
+// FromMinor returns the amount of units of currency's minor unit, e.g. 150050
+// cents as KES 1500.50.
+func FromMinor(units int64, currency Currency) Money {
+	return New(decimal.New(units, -currency.MinorUnits()), currency)
+}
+
+// Round returns m rounded half away from zero to the currency's minor unit.
+func (m Money) Round() Money {
+	return New(m.Amount.Round(m.Currency.MinorUnits()), m.Currency)
+}
+
+// Minor returns m in the currency's minor unit, e.g. cents, rounded half away
+// from zero.
+func (m Money) Minor() int64 {
+	return m.Amount.Shift(m.Currency.MinorUnits()).Round(0).IntPart()
+}
+
+// Whole returns m in whole units, for providers such as M-Pesa that only
+// accept those. It fails if m has a fractional part.
+func (m Money) Whole() (int64, error) {
+	if !m.Amount.IsInteger() {
+		return 0, fmt.Errorf("amount %s is not a whole number of %s", m.Amount, m.Currency)
+	}
+	return m.Amount.IntPart(), nil
+}
+
+// Fixed returns the amount with exactly the currency's minor unit digits,
+// e.g. "1500.50" for KES or "1500" for UGX, for providers that take a string.
+func (m Money) Fixed() string {
+	return m.Amount.StringFixed(m.Currency.MinorUnits())
+}
+
+// Number returns the amount rounded to the currency's minor unit, for fields
+// sent as a JSON number.
+func (m Money) Number() Number {
+	return Number(m.Round().Amount)
+}
+
+// String returns m as e.g. "KES 1500.50".
+func (m Money) String() string {
+	return string(m.Currency) + " " + m.Fixed()
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount.IsNegative()
+}
+
+// Add returns m + o. Both must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return New(m.Amount.Add(o.Amount), m.Currency), nil
+}
+
+// Sub returns m - o. Both must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return New(m.Amount.Sub(o.Amount), m.Currency), nil
+}
+
+// Equal reports whether m and o are the same amount in the same currency,
+// regardless of trailing zeros.
+func (m Money) Equal(o Money) bool {
+	return m.Currency == o.Currency && m.Amount.Equal(o.Amount)
+}
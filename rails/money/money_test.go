@@ -0,0 +1,128 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMinorUnits(t *testing.T) {
+	for currency, expected := range map[Currency]int32{KES: 2, ZAR: 2, UGX: 0, RWF: 0, KWD: 3, "XYZ": 2} {
+		if got := currency.MinorUnits(); got != expected {
+			t.Errorf("Expected %d minor units for %s, got %d", expected, currency, got)
+		}
+	}
+}
+
+func TestMinor(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency Currency
+		minor    int64
+		fixed    string
+	}{
+		{"1500.50", KES, 150050, "1500.50"},
+		{"0.1", ZAR, 10, "0.10"},
+		{"10.005", ZAR, 1001, "10.01"},
+		{"1500", UGX, 1500, "1500"},
+		{"1.2345", KWD, 1235, "1.235"},
+	}
+
+	for _, tt := range tests {
+		m, err := Parse(tt.amount, tt.currency)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.amount, err)
+		}
+		if got := m.Minor(); got != tt.minor {
+			t.Errorf("Expected %s %s to be %d minor units, got %d", tt.currency, tt.amount, tt.minor, got)
+		}
+		if got := m.Fixed(); got != tt.fixed {
+			t.Errorf("Expected %s %s to format as '%s', got '%s'", tt.currency, tt.amount, tt.fixed, got)
+		}
+		if back := FromMinor(tt.minor, tt.currency); !back.Equal(m.Round()) {
+			t.Errorf("Expected FromMinor(%d) to equal %s, got %s", tt.minor, m.Round(), back)
+		}
+	}
+}
+
+// TestNoDrift sums amounts that do not add up exactly as float64.
+func TestNoDrift(t *testing.T) {
+	total := New(decimal.Zero, ZAR)
+	m, _ := Parse("0.10", ZAR)
+	for i := 0; i < 1000; i++ {
+		total, _ = total.Add(m)
+	}
+
+	if total.String() != "ZAR 100.00" {
+		t.Errorf("Expected ZAR 100.00, got %s", total)
+	}
+}
+
+func TestAddCurrencyMismatch(t *testing.T) {
+	_, err := New(decimal.NewFromInt(1), KES).Add(New(decimal.NewFromInt(1), UGX))
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestWhole(t *testing.T) {
+	if n, err := New(decimal.NewFromInt(100), KES).Whole(); err != nil || n != 100 {
+		t.Errorf("Expected 100, got %d (%v)", n, err)
+	}
+	if _, err := New(decimal.RequireFromString("100.5"), KES).Whole(); err == nil {
+		t.Error("Expected an error for a fractional amount")
+	}
+}
+
+func TestNumberJSON(t *testing.T) {
+	var body struct {
+		Amount Number `json:"amount"`
+	}
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{`{"amount":1500.5}`, `{"amount":1500.50}`},
+		{`{"amount":"1500.5"}`, `{"amount":1500.50}`},
+		{`{"amount":100}`, `{"amount":100.00}`},
+		{`{"amount":0.125}`, `{"amount":0.125}`},
+		{`{"amount":null}`, `{"amount":0.00}`},
+	}
+
+	for _, tt := range tests {
+		body.Amount = Number{}
+		if err := json.Unmarshal([]byte(tt.in), &body); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.in, err)
+		}
+		out, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(out) != tt.out {
+			t.Errorf("Expected %s to round trip as %s, got %s", tt.in, tt.out, out)
+		}
+	}
+}
+
+func TestNumberOmitZero(t *testing.T) {
+	var body struct {
+		Amount Number `json:"amount,omitzero"`
+	}
+	if out, _ := json.Marshal(body); string(out) != `{}` {
+		t.Errorf("Expected a zero amount to be omitted, got %s", out)
+	}
+}
+
+func TestNumberFromMoney(t *testing.T) {
+	m, _ := Parse("99.999", ZAR)
+	out, _ := json.Marshal(m.Number())
+	if string(out) != "100.00" {
+		t.Errorf("Expected 100.00, got %s", out)
+	}
+	if !m.Number().Money(ZAR).Equal(m.Round()) {
+		t.Errorf("Expected Number().Money() to round trip, got %s", m.Number().Money(ZAR))
+	}
+}
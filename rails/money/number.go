@@ -0,0 +1,68 @@
+package money
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Number is an amount sent as a JSON number with at least two decimal
+// places, e.g. 1500.50, as the bank APIs expect. It decodes from a JSON
+// number or a numeric string without passing through float64.
+type Number decimal.Decimal
+
+// NewNumber returns the Number for amount, e.g. NewNumber(decimal.NewFromInt(100)).
+func NewNumber(amount decimal.Decimal) Number {
+	return Number(amount)
+}
+
+func (n Number) Decimal() decimal.Decimal {
+	return decimal.Decimal(n)
+}
+
+// Money returns n in currency.
+func (n Number) Money(currency Currency) Money {
+	return New(n.Decimal(), currency)
+}
+
+// Equal reports whether n and o are the same amount, regardless of trailing
+// zeros.
+func (n Number) Equal(o Number) bool {
+	return n.Decimal().Equal(o.Decimal())
+}
+
+// IsZero reports whether n is zero, so fields tagged omitzero are left out
+// when no amount is set.
+func (n Number) IsZero() bool {
+	return n.Decimal().IsZero()
+}
+
+func (n Number) String() string {
+	return n.format()
+}
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	return []byte(n.format()), nil
+}
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	var d decimal.Decimal
+	if string(data) == "null" {
+		*n = Number{}
+		return nil
+	}
+	if err := d.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*n = Number(d)
+	return nil
+}
+
+// format keeps any digits past the second decimal place, so an amount in a
+// currency with thousandths is not rounded.
+func (n Number) format() string {
+	d := n.Decimal()
+	places := int32(2)
+	if exp := -d.Exponent(); exp > places {
+		places = exp
+	}
+	return d.StringFixed(places)
+}
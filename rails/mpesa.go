@@ -0,0 +1,280 @@
+package rails
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nutcas3/payment-rails/mpesa"
+	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+	"github.com/shopspring/decimal"
+)
+
+// STK Push ResultCodes without a daraja constant.
+const (
+	stkResultSystemError         = 17   // M-Pesa system internal error
+	stkResultSystemBusy          = 26   // System busy
+	stkResultSubscriberLocked    = 1001 // Another transaction is in progress for the subscriber
+	stkResultTransactionExpired  = 1019
+	stkResultPushFailed          = 1025 // Error sending the push request
+	stkResultStillProcessing     = 4999 // Reported by the query API while the customer has not answered
+	stkResultPushFailedUndefined = 9999 // Error sending the push request
+)
+
+type MpesaConfig struct {
+	ShortCode       string // Paybill or till used for STK Push and as B2C PartyA
+	PartyB          string // Defaults to ShortCode; set to the till's store number for Buy Goods
+	TransactionType string // Defaults to CustomerPayBillOnline
+	CallbackURL     string // STK Push callback URL
+
+	InitiatorName      string
+	InitiatorPassword  string // Encrypted into the SecurityCredential per request
+	SecurityCredential string // Used as-is when set
+	B2CCommandID       string // Defaults to BusinessPayment
+	ResultURL          string
+	QueueTimeOutURL    string
+}
+
+// MpesaAdapter collects through STK Push, disburses through B2C and refunds
+// through Reversal. B2C and Reversal complete asynchronously, so their results
+// are always StatusPending; the outcome arrives on the ResultURL.
+type MpesaAdapter struct {
+	client *mpesa.Client
+	config MpesaConfig
+}
+
+func NewMpesaAdapter(client *mpesa.Client, config MpesaConfig) *MpesaAdapter {
+	if config.PartyB == "" {
+		config.PartyB = config.ShortCode
+	}
+	if config.TransactionType == "" {
+		config.TransactionType = "CustomerPayBillOnline"
+	}
+	if config.B2CCommandID == "" {
+		config.B2CCommandID = "BusinessPayment"
+	}
+	return &MpesaAdapter{client: client, config: config}
+}
+
+func (a *MpesaAdapter) Provider() Provider {
+	return ProviderMpesa
+}
+
+func (a *MpesaAdapter) currency() string {
+	return "KES"
+}
+
+func (a *MpesaAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	amount, err := mpesaAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	phone := adapterPhone(ProviderMpesa, req.Phone, msisdn.Kenya)
+
+	ctx = telemetry.Name(ctx, "InitiateStkPush")
+	resp, err := a.client.InitiateStkPushWithContext(ctx, mpesa.StkPushParams{
+		BusinessShortCode: a.config.ShortCode,
+		TransactionType:   a.config.TransactionType,
+		Amount:            strconv.FormatInt(amount, 10),
+		PartyA:            phone,
+		PartyB:            a.config.PartyB,
+		PhoneNumber:       phone,
+		CallBackURL:       firstNonEmpty(req.CallbackURL, a.config.CallbackURL),
+		AccountReference:  req.Reference,
+		TransactionDesc:   firstNonEmpty(req.Description, req.Reference),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderMpesa,
+		Kind:           KindCollection,
+		TransactionID:  resp.CheckoutRequestID,
+		Reference:      req.Reference,
+		Status:         mpesaAcceptedStatus(resp.ResponseCode),
+		ProviderStatus: resp.ResponseCode,
+		Message:        resp.CustomerMessage,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *MpesaAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	amount, err := mpesaAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	partyA, err := strconv.Atoi(a.config.ShortCode)
+	if err != nil {
+		return nil, idempotency.NotSent(fmt.Errorf("invalid shortcode %q: %w", a.config.ShortCode, err))
+	}
+	partyB, err := strconv.Atoi(adapterPhone(ProviderMpesa, req.Phone, msisdn.Kenya))
+	if err != nil {
+		return nil, idempotency.NotSent(fmt.Errorf("invalid phone number %q: %w", req.Phone, err))
+	}
+
+	ctx = telemetry.Name(ctx, "B2CPayment")
+	resp, err := a.client.B2CPaymentWithContext(ctx, mpesa.B2CPaymentParams{
+		InitiatorName:      a.config.InitiatorName,
+		SecurityCredential: a.config.SecurityCredential,
+		InitiatorPassword:  a.config.InitiatorPassword,
+		CommandID:          a.config.B2CCommandID,
+		Amount:             int(amount),
+		PartyA:             partyA,
+		PartyB:             partyB,
+		Remarks:            firstNonEmpty(req.Description, req.Reference),
+		QueueTimeOutURL:    a.config.QueueTimeOutURL,
+		ResultURL:          firstNonEmpty(req.CallbackURL, a.config.ResultURL),
+		Occasion:           req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderMpesa,
+		Kind:           KindDisbursement,
+		TransactionID:  resp.OriginatorConversationID,
+		Reference:      req.Reference,
+		Status:         mpesaAcceptedStatus(resp.ResponseCode),
+		ProviderStatus: resp.ResponseCode,
+		Message:        resp.ResponseDescription,
+		Raw:            resp,
+	}, nil
+}
+
+// Status queries an STK Push by its CheckoutRequestID. Daraja has no
+// synchronous status API for B2C or reversals.
+func (a *MpesaAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	if req.Kind != "" && req.Kind != KindCollection {
+		return nil, ErrNotSupported
+	}
+
+	ctx = telemetry.Name(ctx, "QueryStkPush")
+	resp, err := a.client.QueryStkPushWithContext(ctx, a.config.ShortCode, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderMpesa,
+		Kind:           KindCollection,
+		TransactionID:  resp.CheckoutRequestID,
+		Status:         MpesaSTKStatus(resp.ResultCode),
+		ProviderStatus: resp.ResultCode,
+		Message:        resp.ResultDesc,
+		Raw:            resp,
+	}, nil
+}
+
+func (a *MpesaAdapter) Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error) {
+	amount, err := mpesaAmount(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := strconv.Atoi(a.config.ShortCode)
+	if err != nil {
+		return nil, idempotency.NotSent(fmt.Errorf("invalid shortcode %q: %w", a.config.ShortCode, err))
+	}
+
+	ctx = telemetry.Name(ctx, "Reversal")
+	resp, err := a.client.ReversalWithContext(ctx, mpesa.ReversalParams{
+		Initiator:              a.config.InitiatorName,
+		SecurityCredential:     a.config.SecurityCredential,
+		InitiatorPassword:      a.config.InitiatorPassword,
+		CommandID:              "TransactionReversal",
+		TransactionID:          req.TransactionID,
+		Amount:                 int(amount),
+		ReceiverParty:          receiver,
+		ReceiverIdentifierType: 11,
+		ResultURL:              firstNonEmpty(req.CallbackURL, a.config.ResultURL),
+		QueueTimeOutURL:        a.config.QueueTimeOutURL,
+		Remarks:                firstNonEmpty(req.Reason, "Refund"),
+		Occasion:               req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentResult{
+		Provider:       ProviderMpesa,
+		Kind:           KindRefund,
+		TransactionID:  resp.OriginatorConversationID,
+		Reference:      req.Reference,
+		Status:         mpesaAcceptedStatus(resp.ResponseCode),
+		ProviderStatus: resp.ResponseCode,
+		Message:        resp.ResponseDescription,
+		Raw:            resp,
+	}, nil
+}
+
+// MpesaSTKStatus maps an STK Push ResultCode, from the query API or the
+// callback, onto a Status. Codes it does not know map to StatusUnknown rather
+// than StatusFailed, since a code such as 4999 from the query API means the
+// payment is still in progress.
+func MpesaSTKStatus(resultCode string) Status {
+	code, err := strconv.Atoi(resultCode)
+	if err != nil {
+		return StatusUnknown
+	}
+
+	switch code {
+	case daraja.STKResultSuccess:
+		return StatusSucceeded
+	case stkResultStillProcessing:
+		return StatusPending
+	case daraja.STKResultCancelledByUser:
+		return StatusCancelled
+	case daraja.STKResultUserUnreachable, stkResultTransactionExpired:
+		return StatusExpired
+	case daraja.STKResultInsufficientBalance, daraja.STKResultInvalidPIN,
+		stkResultSystemError, stkResultSystemBusy, stkResultSubscriberLocked,
+		stkResultPushFailed, stkResultPushFailedUndefined:
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
+}
+
+// MpesaResultStatus maps the ResultCode of an asynchronous Result callback
+// (B2C, B2B, Reversal) onto a Status.
+func MpesaResultStatus(result *daraja.Result) Status {
+	if result.Succeeded() {
+		return StatusSucceeded
+	}
+	if result.ResultCode == "SFC_IC0003" {
+		return StatusExpired
+	}
+	return StatusFailed
+}
+
+func mpesaAcceptedStatus(responseCode string) Status {
+	if responseCode == "0" {
+		return StatusPending
+	}
+	return StatusFailed
+}
+
+// mpesaAmount returns amount in whole shillings, since Daraja rejects
+// fractional amounts.
+func mpesaAmount(amount decimal.Decimal) (int64, error) {
+	if !amount.IsPositive() {
+		return 0, idempotency.NotSent(fmt.Errorf("amount must be greater than zero"))
+	}
+	if !amount.Equal(amount.Truncate(0)) {
+		return 0, idempotency.NotSent(fmt.Errorf("M-Pesa amounts must be whole numbers, got %s", amount))
+	}
+	return amount.IntPart(), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
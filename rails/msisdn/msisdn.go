@@ -0,0 +1,231 @@
+// Package msisdn parses mobile phone numbers from Kenya, Uganda, Tanzania,
+// Rwanda, Ghana, Zambia, Nigeria and South Africa, in local or
+// international format, and formats them the way each rail expects:
+//
+//	n, err := msisdn.Parse("0712 345 678", msisdn.Kenya)
+//	n.International() // "254712345678", for M-Pesa
+//	n.National()      // "712345678", for Airtel
+//	n.Operator        // msisdn.Safaricom
+//
+// The operator is the one the number's prefix was allocated to. A number
+// moved to another operator keeps its prefix, so the operator is a good
+// default for routing rather than a certainty.
+package msisdn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalid is returned for input that is not a phone number of the
+	// right length.
+	ErrInvalid = errors.New("msisdn: invalid phone number")
+
+	// ErrUnsupportedCountry is returned for numbers from countries not in
+	// this package, or local numbers without a country.
+	ErrUnsupportedCountry = errors.New("msisdn: unsupported country")
+
+	// ErrNotMobile is returned for numbers outside the country's mobile
+	// ranges.
+	ErrNotMobile = errors.New("msisdn: not a mobile number")
+)
+
+// Country is an ISO 3166-1 alpha-2 country code.
+type Country string
+
+const (
+	Kenya       Country = "KE"
+	Uganda      Country = "UG"
+	Tanzania    Country = "TZ"
+	Rwanda      Country = "RW"
+	Ghana       Country = "GH"
+	Zambia      Country = "ZM"
+	Nigeria     Country = "NG"
+	SouthAfrica Country = "ZA"
+)
+
+// Operator is a mobile network operator.
+type Operator string
+
+const (
+	Safaricom     Operator = "safaricom"
+	Airtel        Operator = "airtel"
+	MTN           Operator = "mtn"
+	Telkom        Operator = "telkom"
+	Equitel       Operator = "equitel"
+	Faiba         Operator = "faiba"
+	UgandaTelecom Operator = "utl"
+	Vodacom       Operator = "vodacom"
+	Tigo          Operator = "tigo"
+	Halotel       Operator = "halotel"
+	TTCL          Operator = "ttcl"
+	Telecel       Operator = "telecel"
+	AirtelTigo    Operator = "airteltigo"
+	Zamtel        Operator = "zamtel"
+	Glo           Operator = "glo"
+	NineMobile    Operator = "9mobile"
+	CellC         Operator = "cellc"
+)
+
+// Format is a way of writing a number.
+type Format string
+
+const (
+	E164          Format = "e164"          // "+254712345678"
+	International Format = "international" // "254712345678", E.164 without the plus
+	National      Format = "national"      // "712345678", without the trunk 0
+	Local         Format = "local"         // "0712345678", as dialled in the country
+)
+
+// Number is a parsed mobile number.
+type Number struct {
+	Country Country
+
+	// NSN is the national significant number: the digits after the calling
+	// code, without the trunk 0.
+	NSN string
+
+	// Operator is the operator the number's prefix was allocated to, or
+	// empty if it is not known.
+	Operator Operator
+}
+
+// CallingCode returns the country's calling code, e.g. "254".
+func (n Number) CallingCode() string {
+	return plans[n.Country].callingCode
+}
+
+func (n Number) E164() string {
+	return "+" + n.International()
+}
+
+func (n Number) International() string {
+	return n.CallingCode() + n.NSN
+}
+
+func (n Number) National() string {
+	return n.NSN
+}
+
+func (n Number) Local() string {
+	return "0" + n.NSN
+}
+
+// Format returns n written in f, or in E.164 for an unknown f.
+func (n Number) Format(f Format) string {
+	switch f {
+	case International:
+		return n.International()
+	case National:
+		return n.National()
+	case Local:
+		return n.Local()
+	}
+	return n.E164()
+}
+
+func (n Number) String() string {
+	return n.E164()
+}
+
+// Parse parses a mobile number in international format, with or without
+// "+" or "00", or in local format, with or without the trunk 0. Spaces,
+// dashes, dots and brackets are ignored. country is the country of numbers
+// in local format, and may be empty if all numbers are international.
+func Parse(s string, country Country) (Number, error) {
+	digits, international := clean(s)
+	if digits == "" {
+		return Number{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	p, supported := plans[country]
+	local := !international && supported && len(strings.TrimPrefix(digits, "0")) <= p.length
+	if !local {
+		// Without a "+", digits too long to be local are taken as
+		// international if the rest has the right length
+		c, nsn, found := splitCallingCode(digits)
+		if found && (international || len(nsn) == plans[c].length) {
+			return parseNSN(s, c, nsn)
+		}
+		if international {
+			return Number{}, fmt.Errorf("%w: %q", ErrUnsupportedCountry, s)
+		}
+	}
+	if country == "" {
+		return Number{}, fmt.Errorf("%w: %q has no known calling code and no country was given", ErrUnsupportedCountry, s)
+	}
+	if !supported {
+		return Number{}, fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+	return parseNSN(s, country, strings.TrimPrefix(digits, "0"))
+}
+
+// Valid reports whether s parses as a mobile number.
+func Valid(s string, country Country) bool {
+	_, err := Parse(s, country)
+	return err == nil
+}
+
+// clean returns the digits of s, and whether s was marked international by
+// a leading "+" or "00".
+func clean(s string) (digits string, international bool) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "+") {
+		s, international = s[1:], true
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	digits = b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+	return digits, international
+}
+
+// splitCallingCode returns the country whose calling code digits start
+// with, and the rest of digits. A trunk 0 written after the calling code, as
+// in "+254 0712 345 678", is dropped.
+func splitCallingCode(digits string) (Country, string, bool) {
+	for c, p := range plans {
+		if nsn, found := strings.CutPrefix(digits, p.callingCode); found {
+			if len(nsn) == p.length+1 && nsn[0] == '0' {
+				nsn = nsn[1:]
+			}
+			return c, nsn, true
+		}
+	}
+	return "", "", false
+}
+
+func parseNSN(s string, country Country, nsn string) (Number, error) {
+	p := plans[country]
+	if len(nsn) != p.length {
+		return Number{}, fmt.Errorf("%w: %q has %d digits, %s numbers have %d", ErrInvalid, s, len(nsn), country, p.length)
+	}
+	op, mobile := operator(p, nsn)
+	if !mobile {
+		return Number{}, fmt.Errorf("%w: %q", ErrNotMobile, s)
+	}
+	return Number{Country: country, NSN: nsn, Operator: op}, nil
+}
+
+// operator returns the operator of the longest prefix of nsn in p, and
+// whether there is one.
+func operator(p plan, nsn string) (Operator, bool) {
+	for n := min(len(nsn), 4); n > 0; n-- {
+		if op, found := p.operators[nsn[:n]]; found {
+			return op, true
+		}
+	}
+	return "", false
+}
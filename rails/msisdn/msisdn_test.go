@@ -0,0 +1,83 @@
+package msisdn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		country  Country
+		want     string // E.164
+		operator Operator
+	}{
+		{"0712345678", Kenya, "+254712345678", Safaricom},
+		{"712 345 678", Kenya, "+254712345678", Safaricom},
+		{"+254 0712-345-678", "", "+254712345678", Safaricom},
+		{"254733123456", Kenya, "+254733123456", Airtel},
+		{"00254110123456", "", "+254110123456", Safaricom},
+		{"0771234567", Kenya, "+254771234567", Telkom},
+		{"0763123456", Kenya, "+254763123456", Equitel},
+		{"0772123456", Uganda, "+256772123456", MTN},
+		{"+256 701 234 567", Kenya, "+256701234567", Airtel},
+		{"0754123456", Tanzania, "+255754123456", Vodacom},
+		{"0788123456", Rwanda, "+250788123456", MTN},
+		{"(024) 412 3456", Ghana, "+233244123456", MTN},
+		{"0201234567", Ghana, "+233201234567", Telecel},
+		{"0971234567", Zambia, "+260971234567", Airtel},
+		{"08031234567", Nigeria, "+2348031234567", MTN},
+		{"+234 805 123 4567", "", "+2348051234567", Glo},
+		{"0821234567", SouthAfrica, "+27821234567", Vodacom},
+		{"0811234567", SouthAfrica, "+27811234567", Telkom},
+		{"0601234567", SouthAfrica, "+27601234567", ""},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.input, tt.country)
+		if err != nil {
+			t.Errorf("Parse(%q, %q) failed: %v", tt.input, tt.country, err)
+			continue
+		}
+		if n.E164() != tt.want || n.Operator != tt.operator {
+			t.Errorf("Parse(%q, %q) = %s %q, expected %s %q", tt.input, tt.country, n, n.Operator, tt.want, tt.operator)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		country Country
+		want    error
+	}{
+		{"", Kenya, ErrInvalid},
+		{"07123456789", Kenya, ErrInvalid},
+		{"071234567", Kenya, ErrInvalid},
+		{"0712-CALL-ME", Kenya, ErrInvalid},
+		{"+2547123", "", ErrInvalid},
+		{"0202123456", Kenya, ErrNotMobile},
+		{"0114123456", SouthAfrica, ErrNotMobile},
+		{"+46733123453", "", ErrUnsupportedCountry},
+		{"0712345678", "", ErrUnsupportedCountry},
+		{"0712345678", "US", ErrUnsupportedCountry},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.input, tt.country); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q, %q): expected %v, got %v", tt.input, tt.country, tt.want, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	n, _ := Parse("0712345678", Kenya)
+	for f, want := range map[Format]string{
+		E164:          "+254712345678",
+		International: "254712345678",
+		National:      "712345678",
+		Local:         "0712345678",
+	} {
+		if got := n.Format(f); got != want {
+			t.Errorf("Format(%s) = %q, expected %q", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package msisdn
+
+import "maps"
+
+// plan is a country's mobile numbering plan.
+type plan struct {
+	callingCode string
+	length      int // Digits in the national significant number
+
+	// operators maps the leading digits of mobile numbers, without the
+	// trunk 0, to the operator they were allocated to. Longer prefixes win.
+	operators map[string]Operator
+}
+
+// prefixes returns a map giving each of the prefixes to op.
+func prefixes(op Operator, list ...string) map[string]Operator {
+	m := make(map[string]Operator, len(list))
+	for _, p := range list {
+		m[p] = op
+	}
+	return m
+}
+
+func merge(parts ...map[string]Operator) map[string]Operator {
+	m := make(map[string]Operator)
+	for _, part := range parts {
+		maps.Copy(m, part)
+	}
+	return m
+}
+
+// plans are the numbering plans of the supported countries, as allocated
+// by each country's regulator.
+var plans = map[Country]plan{
+	Kenya: {
+		callingCode: "254",
+		length:      9,
+		operators: merge(
+			prefixes(Safaricom, "70", "71", "72", "740", "741", "742", "743", "745", "746", "748", "757", "758", "759", "768", "769", "79", "110", "111", "112", "113", "114", "115"),
+			prefixes(Airtel, "73", "750", "751", "752", "753", "754", "755", "756", "762", "78", "100", "101", "102"),
+			prefixes(Telkom, "77"),
+			prefixes(Equitel, "763", "764", "765", "766"),
+			prefixes(Faiba, "747"),
+		),
+	},
+	Uganda: {
+		callingCode: "256",
+		length:      9,
+		operators: merge(
+			prefixes(MTN, "76", "77", "78"),
+			prefixes(Airtel, "70", "74", "75"),
+			prefixes(UgandaTelecom, "71"),
+		),
+	},
+	Tanzania: {
+		callingCode: "255",
+		length:      9,
+		operators: merge(
+			prefixes(Vodacom, "74", "75", "76"),
+			prefixes(Airtel, "68", "69", "78"),
+			prefixes(Tigo, "65", "67", "71", "77"),
+			prefixes(Halotel, "61", "62"),
+			prefixes(TTCL, "73"),
+		),
+	},
+	Rwanda: {
+		callingCode: "250",
+		length:      9,
+		operators: merge(
+			prefixes(MTN, "78", "79"),
+			prefixes(Airtel, "72", "73"),
+		),
+	},
+	Ghana: {
+		callingCode: "233",
+		length:      9,
+		operators: merge(
+			prefixes(MTN, "24", "25", "53", "54", "55", "59"),
+			prefixes(Telecel, "20", "50"),
+			prefixes(AirtelTigo, "26", "27", "56", "57"),
+		),
+	},
+	Zambia: {
+		callingCode: "260",
+		length:      9,
+		operators: merge(
+			prefixes(Airtel, "97", "77"),
+			prefixes(MTN, "96", "76"),
+			prefixes(Zamtel, "95", "75"),
+		),
+	},
+	Nigeria: {
+		callingCode: "234",
+		length:      10,
+		operators: merge(
+			prefixes(MTN, "703", "704", "706", "803", "806", "810", "813", "814", "816", "903", "906", "913", "916"),
+			prefixes(Airtel, "701", "708", "802", "808", "812", "901", "902", "904", "907", "911", "912"),
+			prefixes(Glo, "705", "805", "807", "811", "815", "905", "915"),
+			prefixes(NineMobile, "809", "817", "818", "908", "909"),
+		),
+	},
+	SouthAfrica: {
+		callingCode: "27",
+		length:      9,
+		operators: merge(
+			// Mobile ranges whose operator is not known from the prefix
+			prefixes("", "60", "62", "64", "65", "67", "68", "69", "70", "75", "77", "81"),
+			prefixes(Vodacom, "66", "71", "72", "76", "79", "82"),
+			prefixes(MTN, "63", "73", "78", "83"),
+			prefixes(CellC, "61", "74", "84"),
+			prefixes(Telkom, "811", "812", "813", "814", "815"),
+		),
+	},
+}
@@ -0,0 +1,61 @@
+package rails
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+)
+
+// phoneFormats is how each provider expects a customer's phone number.
+var phoneFormats = map[Provider]msisdn.Format{
+	ProviderMpesa:   msisdn.International,
+	ProviderAirtel:  msisdn.National, // The country goes in the X-Country header
+	ProviderMomo:    msisdn.International,
+	ProviderSasaPay: msisdn.International,
+	ProviderKCB:     msisdn.International,
+	ProviderJenga:   msisdn.International,
+}
+
+// operatorProviders is the provider whose wallets each operator's
+// customers pay from.
+var operatorProviders = map[msisdn.Operator]Provider{
+	msisdn.Safaricom: ProviderMpesa,
+	msisdn.Airtel:    ProviderAirtel,
+	msisdn.MTN:       ProviderMomo,
+}
+
+// FormatPhone returns phone written the way provider expects it. country
+// is the country of numbers in local format, and may be empty if all
+// numbers are international.
+func FormatPhone(provider Provider, phone string, country msisdn.Country) (string, error) {
+	n, err := msisdn.Parse(phone, country)
+	if err != nil {
+		return "", err
+	}
+	return n.Format(phoneFormats[provider]), nil
+}
+
+// PhoneProvider returns the provider whose wallet phone belongs to, going by
+// its operator: M-Pesa for Safaricom, Airtel for Airtel and MoMo for MTN. It
+// fails with ErrNotSupported for other operators.
+func PhoneProvider(phone string, country msisdn.Country) (Provider, error) {
+	n, err := msisdn.Parse(phone, country)
+	if err != nil {
+		return "", err
+	}
+	provider, found := operatorProviders[n.Operator]
+	if !found {
+		return "", fmt.Errorf("%w: no provider for %s numbers on %s", ErrNotSupported, n.Country, firstNonEmpty(string(n.Operator), "an unknown operator"))
+	}
+	return provider, nil
+}
+
+// adapterPhone is FormatPhone for adapters. Numbers msisdn cannot parse, such
+// as sandbox test numbers, are passed on without the "+" as before.
+func adapterPhone(provider Provider, phone string, country msisdn.Country) string {
+	if formatted, err := FormatPhone(provider, phone, country); err == nil {
+		return formatted
+	}
+	return strings.TrimPrefix(strings.TrimSpace(phone), "+")
+}
@@ -0,0 +1,76 @@
+package rails
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+)
+
+func TestFormatPhone(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		phone    string
+		country  msisdn.Country
+		want     string
+	}{
+		{ProviderMpesa, "0712 345 678", msisdn.Kenya, "254712345678"},
+		{ProviderMpesa, "+254712345678", "", "254712345678"},
+		{ProviderAirtel, "+254 733 123 456", msisdn.Kenya, "733123456"},
+		{ProviderAirtel, "0752123456", msisdn.Uganda, "752123456"},
+		{ProviderMomo, "0772123456", msisdn.Uganda, "256772123456"},
+		{ProviderJenga, "0712345678", msisdn.Kenya, "254712345678"},
+	}
+	for _, tt := range tests {
+		got, err := FormatPhone(tt.provider, tt.phone, tt.country)
+		if err != nil {
+			t.Errorf("FormatPhone(%s, %q): %v", tt.provider, tt.phone, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FormatPhone(%s, %q): Expected %s, got %s", tt.provider, tt.phone, tt.want, got)
+		}
+	}
+
+	if _, err := FormatPhone(ProviderMpesa, "0712", msisdn.Kenya); !errors.Is(err, msisdn.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a short number, got %v", err)
+	}
+}
+
+func TestPhoneProvider(t *testing.T) {
+	tests := []struct {
+		phone   string
+		country msisdn.Country
+		want    Provider
+	}{
+		{"0712345678", msisdn.Kenya, ProviderMpesa},
+		{"0110345678", msisdn.Kenya, ProviderMpesa},
+		{"0733123456", msisdn.Kenya, ProviderAirtel},
+		{"+256772123456", "", ProviderMomo},
+		{"0788123456", msisdn.Rwanda, ProviderMomo},
+	}
+	for _, tt := range tests {
+		got, err := PhoneProvider(tt.phone, tt.country)
+		if err != nil {
+			t.Errorf("PhoneProvider(%q): %v", tt.phone, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PhoneProvider(%q): Expected %s, got %s", tt.phone, tt.want, got)
+		}
+	}
+
+	if _, err := PhoneProvider("0772123456", msisdn.Kenya); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported for a Telkom number, got %v", err)
+	}
+}
+
+func TestAdapterPhone(t *testing.T) {
+	// MoMo's sandbox numbers are not real numbers, and are passed on as they are
+	if got := adapterPhone(ProviderMomo, "+46733123453", ""); got != "46733123453" {
+		t.Errorf("Expected 46733123453, got %s", got)
+	}
+	if got := adapterPhone(ProviderMpesa, "0712345678", msisdn.Kenya); got != "254712345678" {
+		t.Errorf("Expected 254712345678, got %s", got)
+	}
+}
@@ -0,0 +1,120 @@
+// Package rails provides a provider-agnostic facade over the payment rails in
+// this module. Checkout code depends on the Collector, Disburser,
+// StatusChecker and Refunder interfaces and a common PaymentRequest and
+// PaymentResult model; the adapters in this package translate them to each
+// provider's SDK and normalize provider status codes into a single Status.
+package rails
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+// ErrNotSupported is returned when a provider has no API for an operation,
+// e.g. querying the status of a Jenga disbursement.
+var ErrNotSupported = errors.New("operation not supported by provider")
+
+type Provider string
+
+const (
+	ProviderMpesa   Provider = "mpesa"
+	ProviderAirtel  Provider = "airtel"
+	ProviderMomo    Provider = "momo"
+	ProviderSasaPay Provider = "sasapay"
+	ProviderKCB     Provider = "kcb"
+	ProviderJenga   Provider = "jenga"
+
+	// Providers with webhooks or statements but no adapter yet
+	ProviderAbsa         Provider = "absa"
+	ProviderFNB          Provider = "fnb"
+	ProviderStandardBank Provider = "standardbank"
+	ProviderCoop         Provider = "coop"
+	ProviderNCBA         Provider = "ncba"
+)
+
+// Kind identifies which API a transaction went through, since several
+// providers expose a separate status endpoint per kind.
+type Kind string
+
+const (
+	KindCollection   Kind = "collection"
+	KindDisbursement Kind = "disbursement"
+	KindRefund       Kind = "refund"
+)
+
+type PaymentRequest struct {
+	Reference   string // Merchant reference, echoed back by the provider where supported
+	Amount      decimal.Decimal
+	Currency    string // ISO 4217 code, defaults to the provider's currency when empty
+	Phone       string // Customer MSISDN for mobile money, in international format
+	Account     string // Bank account number for bank transfers
+	Name        string // Customer or beneficiary name
+	Description string
+	CallbackURL string // Overrides the adapter's configured callback URL
+
+	// IdempotencyKey, if set, is sent to providers with a native
+	// idempotency field, and lets IdempotentAdapter send the request once.
+	IdempotencyKey string
+}
+
+// Money returns the amount in the request's currency, or in currency when
+// the request has none.
+func (r *PaymentRequest) Money(currency string) money.Money {
+	return money.New(r.Amount, money.Currency(firstNonEmpty(r.Currency, currency)))
+}
+
+type RefundRequest struct {
+	TransactionID string // Provider transaction ID of the payment being refunded
+	Reference     string
+	Amount        decimal.Decimal
+	Currency      string
+	Reason        string
+	CallbackURL   string
+
+	// IdempotencyKey is as for PaymentRequest.
+	IdempotencyKey string
+}
+
+// Money returns the amount in the request's currency, or in currency when
+// the request has none.
+func (r *RefundRequest) Money(currency string) money.Money {
+	return money.New(r.Amount, money.Currency(firstNonEmpty(r.Currency, currency)))
+}
+
+type StatusRequest struct {
+	TransactionID string
+	Kind          Kind
+}
+
+type PaymentResult struct {
+	Provider Provider
+	Kind     Kind
+	// TransactionID is the identifier to pass back in a StatusRequest or
+	// RefundRequest; for asynchronous APIs it identifies the request rather
+	// than the settled transaction.
+	TransactionID  string
+	Reference      string
+	Status         Status
+	ProviderStatus string // Raw provider status or result code
+	Message        string
+	Raw            interface{} // The provider SDK response
+}
+
+type Collector interface {
+	Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error)
+}
+
+type Disburser interface {
+	Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error)
+}
+
+type StatusChecker interface {
+	Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error)
+}
+
+type Refunder interface {
+	Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error)
+}
@@ -0,0 +1,344 @@
+package rails
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nutcas3/payment-rails/momo"
+	"github.com/nutcas3/payment-rails/mpesa"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+	"github.com/shopspring/decimal"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	_ Collector     = (*MpesaAdapter)(nil)
+	_ Disburser     = (*MpesaAdapter)(nil)
+	_ StatusChecker = (*MpesaAdapter)(nil)
+	_ Refunder      = (*MpesaAdapter)(nil)
+	_ Collector     = (*AirtelAdapter)(nil)
+	_ Disburser     = (*AirtelAdapter)(nil)
+	_ StatusChecker = (*AirtelAdapter)(nil)
+	_ Refunder      = (*AirtelAdapter)(nil)
+	_ Collector     = (*MomoAdapter)(nil)
+	_ Disburser     = (*MomoAdapter)(nil)
+	_ StatusChecker = (*MomoAdapter)(nil)
+	_ Refunder      = (*MomoAdapter)(nil)
+	_ Collector     = (*SasaPayAdapter)(nil)
+	_ Disburser     = (*SasaPayAdapter)(nil)
+	_ StatusChecker = (*SasaPayAdapter)(nil)
+	_ Collector     = (*KCBAdapter)(nil)
+	_ Disburser     = (*KCBAdapter)(nil)
+	_ StatusChecker = (*KCBAdapter)(nil)
+	_ Collector     = (*JengaAdapter)(nil)
+	_ Disburser     = (*JengaAdapter)(nil)
+	_ StatusChecker = (*JengaAdapter)(nil)
+	_ Collector     = (*IdempotentAdapter)(nil)
+	_ Disburser     = (*IdempotentAdapter)(nil)
+	_ StatusChecker = (*IdempotentAdapter)(nil)
+	_ Refunder      = (*IdempotentAdapter)(nil)
+	_ Collector     = (*InstrumentedAdapter)(nil)
+	_ Disburser     = (*InstrumentedAdapter)(nil)
+	_ StatusChecker = (*InstrumentedAdapter)(nil)
+	_ Refunder      = (*InstrumentedAdapter)(nil)
+)
+
+// roundTripFunc serves requests to any host, so adapters can be tested
+// through the providers' own clients.
+type roundTripFunc func(*http.Request) (int, string)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	status, body := f(r)
+	return &http.Response{
+		StatusCode:    status,
+		Status:        http.StatusText(status),
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
+
+func TestMpesaAdapter(t *testing.T) {
+	client, _ := mpesa.NewClient("key", "secret", "passkey", mpesa.SANDBOX)
+	client.SetHttpClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (int, string) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			return http.StatusOK, `{"access_token":"token","expires_in":"3599"}`
+		case "/mpesa/stkpush/v1/processrequest":
+			return http.StatusOK, `{"MerchantRequestID":"29115-34620561-1","CheckoutRequestID":"ws_CO_191220191020363925","ResponseCode":"0","ResponseDescription":"Success. Request accepted for processing","CustomerMessage":"Success. Request accepted for processing"}`
+		case "/mpesa/stkpushquery/v1/query":
+			return http.StatusOK, `{"ResponseCode":"0","ResponseDescription":"The service request has been accepted successsfully","MerchantRequestID":"29115-34620561-1","CheckoutRequestID":"ws_CO_191220191020363925","ResultCode":"1032","ResultDesc":"Request cancelled by user"}`
+		}
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+		return http.StatusNotFound, `{}`
+	})})
+
+	adapter := NewMpesaAdapter(client, MpesaConfig{ShortCode: "174379", CallbackURL: "https://example.com/stk"})
+
+	result, err := adapter.Collect(context.Background(), &PaymentRequest{
+		Reference: "INV-1",
+		Amount:    decimal.NewFromInt(10),
+		Phone:     "+254708374149",
+	})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if result.Status != StatusPending || result.TransactionID != "ws_CO_191220191020363925" {
+		t.Errorf("Unexpected collect result %+v", result)
+	}
+
+	result, err = adapter.Status(context.Background(), &StatusRequest{TransactionID: result.TransactionID, Kind: result.Kind})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if result.Status != StatusCancelled || result.ProviderStatus != "1032" {
+		t.Errorf("Unexpected status result %+v", result)
+	}
+
+	if _, err := adapter.Collect(context.Background(), &PaymentRequest{Amount: decimal.RequireFromString("10.50"), Phone: "254708374149"}); err == nil {
+		t.Error("Expected error for fractional amount")
+	}
+	if _, err := adapter.Status(context.Background(), &StatusRequest{TransactionID: "AG_1", Kind: KindDisbursement}); err != ErrNotSupported {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestIdempotentAdapter(t *testing.T) {
+	payouts := 0
+	client, _ := mpesa.NewClient("key", "secret", "passkey", mpesa.SANDBOX)
+	client.SetHttpClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (int, string) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			return http.StatusOK, `{"access_token":"token","expires_in":"3599"}`
+		case "/mpesa/b2c/v1/paymentrequest":
+			payouts++
+			var body struct{ OriginatorConversationID string }
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.OriginatorConversationID != "payout-1" {
+				t.Errorf("Expected OriginatorConversationID 'payout-1', got '%s'", body.OriginatorConversationID)
+			}
+			return http.StatusOK, `{"OriginatorConversationID":"payout-1","ConversationID":"AG_1","ResponseCode":"0","ResponseDescription":"Accept the service request successfully."}`
+		}
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+		return http.StatusNotFound, `{}`
+	})})
+
+	adapter := NewIdempotentAdapter(NewMpesaAdapter(client, MpesaConfig{
+		ShortCode:          "600000",
+		InitiatorName:      "testapi",
+		SecurityCredential: "credential",
+		ResultURL:          "https://example.com/b2c",
+	}), idempotency.NewMemoryStore(0))
+
+	req := &PaymentRequest{
+		Reference:      "PAYOUT-1",
+		Amount:         decimal.NewFromInt(100),
+		Phone:          "254708374149",
+		IdempotencyKey: "payout-1",
+	}
+	for i := 0; i < 2; i++ {
+		result, err := adapter.Disburse(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Disburse failed: %v", err)
+		}
+		if result.TransactionID != "payout-1" || result.Status != StatusPending {
+			t.Errorf("Unexpected disburse result %+v", result)
+		}
+	}
+	if payouts != 1 {
+		t.Errorf("Expected one B2C request, got %d", payouts)
+	}
+
+	req.Amount = decimal.NewFromInt(200)
+	if _, err := adapter.Disburse(context.Background(), req); !errors.Is(err, idempotency.ErrConflict) {
+		t.Errorf("Expected ErrConflict for a changed request, got %v", err)
+	}
+	if adapter.Provider() != ProviderMpesa {
+		t.Errorf("Expected provider mpesa, got %s", adapter.Provider())
+	}
+}
+
+func TestInstrumentedAdapter(t *testing.T) {
+	client, _ := mpesa.NewClient("key", "secret", "passkey", mpesa.SANDBOX)
+	client.SetHttpClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (int, string) {
+		switch r.URL.Path {
+		case "/oauth/v1/generate":
+			return http.StatusOK, `{"access_token":"token","expires_in":"3599"}`
+		case "/mpesa/stkpush/v1/processrequest":
+			return http.StatusOK, `{"MerchantRequestID":"29115","CheckoutRequestID":"ws_CO_1","ResponseCode":"0","ResponseDescription":"Success","CustomerMessage":"Success"}`
+		case "/mpesa/b2c/v1/paymentrequest":
+			return http.StatusBadRequest, `{"requestId":"11728-2929992-1","errorCode":"400.002.02","errorMessage":"Bad Request - Invalid Amount"}`
+		}
+		t.Errorf("Unexpected request to %s", r.URL.Path)
+		return http.StatusNotFound, `{}`
+	})})
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	tel, err := telemetry.New(
+		sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	if err != nil {
+		t.Fatalf("telemetry.New failed: %v", err)
+	}
+
+	adapter := NewInstrumentedAdapter(NewMpesaAdapter(client, MpesaConfig{
+		ShortCode:   "174379",
+		CallbackURL: "https://example.com/stk",
+		ResultURL:   "https://example.com/b2c",
+	}), tel, "sandbox")
+
+	req := &PaymentRequest{Reference: "INV-1", Amount: decimal.NewFromInt(100), Phone: "254708374149"}
+	if _, err := adapter.Collect(context.Background(), req); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if _, err := adapter.Disburse(context.Background(), req); err == nil {
+		t.Fatal("Expected Disburse to fail")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(ended))
+	}
+	expected := []map[string]string{
+		{"name": "mpesa.InitiateStkPush", "payment.environment": "sandbox", "payment.currency": "KES", "payment.status": "pending"},
+		{"name": "mpesa.B2CPayment", "payment.provider": "mpesa", "payment.error.category": "validation"},
+	}
+	for i, span := range ended {
+		got := map[string]string{"name": span.Name()}
+		for _, kv := range span.Attributes() {
+			got[string(kv.Key)] = kv.Value.Emit()
+		}
+		for k, v := range expected[i] {
+			if got[k] != v {
+				t.Errorf("Expected span %d %s '%s', got '%s'", i, k, v, got[k])
+			}
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect metrics failed: %v", err)
+	}
+	totals := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+			for _, dp := range sum.DataPoints {
+				totals[m.Name] += dp.Value
+			}
+		}
+	}
+	for name, want := range map[string]int64{telemetry.CallsMetric: 2, telemetry.FailuresMetric: 1, telemetry.TokenRefreshesMetric: 1} {
+		if totals[name] != want {
+			t.Errorf("Expected %s %d, got %d", name, want, totals[name])
+		}
+	}
+}
+
+func TestMomoAdapter(t *testing.T) {
+	var referenceID string
+	client, err := momo.New(momo.ClientConfig{
+		Environment:               "sandbox",
+		APIKey:                    "key",
+		APISecret:                 "secret",
+		CollectionSubscriptionKey: "sub",
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (int, string) {
+			switch {
+			case r.URL.Path == "/collection/token/":
+				return http.StatusOK, `{"access_token":"token","token_type":"access_token","expires_in":3600}`
+			case r.URL.Path == "/collection/v1_0/requesttopay" && r.Method == http.MethodPost:
+				referenceID = r.Header.Get("X-Reference-Id")
+				return http.StatusAccepted, ``
+			case strings.HasPrefix(r.URL.Path, "/collection/v1_0/requesttopay/"):
+				return http.StatusOK, `{"amount":"100","currency":"EUR","externalId":"INV-2","payer":{"partyIdType":"MSISDN","partyId":"46733123453"},"status":"FAILED","reason":{"code":"APPROVAL_REJECTED","message":"Rejected by payer"}}`
+			}
+			t.Errorf("Unexpected request to %s %s", r.Method, r.URL.Path)
+			return http.StatusNotFound, `{}`
+		})},
+	})
+	if err != nil {
+		t.Fatalf("momo.New failed: %v", err)
+	}
+
+	adapter := NewMomoAdapter(client, MomoConfig{Currency: "EUR"})
+
+	result, err := adapter.Collect(context.Background(), &PaymentRequest{
+		Reference: "INV-2",
+		Amount:    decimal.NewFromInt(100),
+		Phone:     "46733123453",
+	})
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if result.TransactionID != referenceID || result.Status != StatusPending {
+		t.Errorf("Unexpected collect result %+v", result)
+	}
+
+	result, err = adapter.Status(context.Background(), &StatusRequest{TransactionID: result.TransactionID})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if result.Status != StatusCancelled || result.Reference != "INV-2" {
+		t.Errorf("Unexpected status result %+v", result)
+	}
+
+	if _, err := adapter.Disburse(context.Background(), &PaymentRequest{Amount: decimal.NewFromInt(1)}); err == nil {
+		t.Error("Expected error without a disbursement subscription key")
+	}
+}
+
+func TestMomoAdapterLocalNumber(t *testing.T) {
+	var payers []string
+	client, err := momo.New(momo.ClientConfig{
+		Environment:               "sandbox",
+		APIKey:                    "key",
+		APISecret:                 "secret",
+		CollectionSubscriptionKey: "sub",
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (int, string) {
+			if r.URL.Path == "/collection/token/" {
+				return http.StatusOK, `{"access_token":"token","token_type":"access_token","expires_in":3600}`
+			}
+			var body struct {
+				Payer struct {
+					PartyID string `json:"partyId"`
+				} `json:"payer"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			payers = append(payers, body.Payer.PartyID)
+			return http.StatusAccepted, ``
+		})},
+	})
+	if err != nil {
+		t.Fatalf("momo.New failed: %v", err)
+	}
+
+	for _, config := range []MomoConfig{
+		{Currency: "UGX"},
+		{Currency: "EUR", Country: msisdn.Uganda},
+	} {
+		adapter := NewMomoAdapter(client, config)
+		if _, err := adapter.Collect(context.Background(), &PaymentRequest{Reference: "INV-3", Amount: decimal.NewFromInt(100), Phone: "0772123456"}); err != nil {
+			t.Fatalf("Collect failed: %v", err)
+		}
+	}
+	for _, payer := range payers {
+		if payer != "256772123456" {
+			t.Errorf("Expected the local number as '256772123456', got '%s'", payer)
+		}
+	}
+	if len(payers) != 2 {
+		t.Errorf("Expected 2 requests to pay, got %d", len(payers))
+	}
+}
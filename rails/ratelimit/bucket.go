@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket: it holds up to burst tokens, refilled at rate
+// per second, and each request takes one. It is safe for concurrent use.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64   // Tokens at last, negative when requests are waiting
+	last   time.Time // When tokens was computed, or the end of a pause
+}
+
+// NewBucket returns a full Bucket refilled at rate tokens per second, which
+// must be positive. A burst below 1 means the rate rounded up.
+func NewBucket(rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Allow takes a token if one is available now.
+func (b *Bucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.advance(now)
+	if b.tokens < 1 || b.last.After(now) {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait blocks until a token is available and takes it. If ctx has a
+// deadline before then, Wait returns ErrDeadline at once without taking a
+// token.
+func (b *Bucket) Wait(ctx context.Context) error {
+	return wait(ctx, b)
+}
+
+// Pause hands out no tokens before until, e.g. the time given by a
+// Retry-After header, and empties the bucket so requests resume at the
+// bucket's rate rather than in a burst.
+func (b *Bucket) Pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+	if !until.After(b.last) {
+		return
+	}
+	if b.tokens > 0 {
+		b.tokens = 0
+	}
+	b.last = until
+}
+
+// reserve takes a token and returns when it may be used.
+func (b *Bucket) reserve(now time.Time) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+	b.tokens--
+	return b.ready()
+}
+
+// cancel returns a token taken by reserve that was not used.
+func (b *Bucket) cancel() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// ready returns when the last token taken may be used.
+func (b *Bucket) ready() time.Time {
+	if b.tokens >= 0 {
+		return b.last
+	}
+	return b.last.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+}
+
+// advance adds the tokens refilled since last. Nothing is refilled during a
+// pause.
+func (b *Bucket) advance(now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
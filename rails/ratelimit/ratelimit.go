@@ -0,0 +1,208 @@
+// Package ratelimit keeps requests to a provider under its TPS limits with
+// token buckets shared by every goroutine, so bulk jobs are slowed down
+// before the provider starts answering 429:
+//
+//	limiter := ratelimit.New(
+//		ratelimit.Limit{Provider: "mpesa", Rate: 10},
+//		ratelimit.Limit{Provider: "mpesa", Operation: "/mpesa/b2c/", Rate: 2},
+//	)
+//	client.Use(limiter.Middleware("payouts-app"))
+//
+// A request waits for a token from every limit that matches it. When a
+// provider does answer 429 or 503 with a Retry-After header, the matching
+// buckets are paused until then.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/transport"
+)
+
+// ErrDeadline is returned instead of waiting for a token that would only be
+// available after the context's deadline.
+var ErrDeadline = errors.New("ratelimit: wait would exceed context deadline")
+
+// Limit is a request rate for a provider. Each credential set, e.g. each
+// Daraja app, gets its own bucket for a Limit.
+type Limit struct {
+	Provider string
+
+	// Operation, if set, limits only matching requests, sharing one bucket
+	// between them. It matches the name given to transport.WithOperation,
+	// e.g. "B2CPayment", or, when it starts with '/', any request whose URL
+	// path starts with it, e.g. "/mpesa/b2c/".
+	Operation string
+
+	// Credential, if set, limits only requests made with that credential set.
+	Credential string
+
+	Rate  float64 // Requests per second, no limit when not positive
+	Burst int     // Requests that may be sent at once, the rate rounded up by default
+}
+
+func (l Limit) matches(provider, operation, path, credential string) bool {
+	if l.Rate <= 0 || l.Provider != provider {
+		return false
+	}
+	if l.Credential != "" && l.Credential != credential {
+		return false
+	}
+	if l.Operation == "" {
+		return true
+	}
+	if strings.HasPrefix(l.Operation, "/") {
+		return strings.HasPrefix(path, l.Operation)
+	}
+	return l.Operation == operation
+}
+
+// Limiter applies a set of Limits. It is safe for concurrent use, and should
+// be shared by every client using the same credentials.
+type Limiter struct {
+	limits []Limit
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*Bucket
+}
+
+type bucketKey struct {
+	limit      int
+	credential string
+}
+
+// New returns a Limiter for limits.
+func New(limits ...Limit) *Limiter {
+	return &Limiter{limits: limits, buckets: make(map[bucketKey]*Bucket)}
+}
+
+// Wait blocks until every limit matching a request allows it. If ctx has a
+// deadline before then, Wait returns ErrDeadline at once.
+func (l *Limiter) Wait(ctx context.Context, req *http.Request, credential string) error {
+	return wait(ctx, l.match(req, credential)...)
+}
+
+// Pause stops the limits matching a request from allowing any more requests
+// before until.
+func (l *Limiter) Pause(req *http.Request, credential string, until time.Time) {
+	for _, b := range l.match(req, credential) {
+		b.Pause(until)
+	}
+}
+
+// Middleware returns middleware that waits for the limiter before sending
+// each request made with credential, a name for the client's credential set.
+// A request that cannot be sent before its context's deadline fails with a
+// ProviderError in the rate_limited category wrapping ErrDeadline. A 429 or
+// 503 response with a Retry-After header pauses the matching limits.
+func (l *Limiter) Middleware(credential string) transport.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return transport.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := l.Wait(req.Context(), req, credential); err != nil {
+				if !errors.Is(err, ErrDeadline) {
+					return nil, err
+				}
+				provider, _ := transport.Describe(req)
+				return nil, &railerrors.ProviderError{
+					Provider:  provider,
+					Message:   "client-side rate limit reached",
+					Retryable: true,
+					Category:  railerrors.CategoryRateLimited,
+					Err:       err,
+				}
+			}
+
+			resp, err := next.RoundTrip(req)
+			if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+				if delay, ok := RetryAfter(resp); ok {
+					l.Pause(req, credential, time.Now().Add(delay))
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// match returns the buckets of the limits matching a request, creating them
+// on first use.
+func (l *Limiter) match(req *http.Request, credential string) []*Bucket {
+	provider, operation := transport.Describe(req)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buckets []*Bucket
+	for i, limit := range l.limits {
+		if !limit.matches(provider, operation, req.URL.Path, credential) {
+			continue
+		}
+		key := bucketKey{limit: i, credential: credential}
+		b, ok := l.buckets[key]
+		if !ok {
+			b = NewBucket(limit.Rate, limit.Burst)
+			l.buckets[key] = b
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// RetryAfter returns how long resp asks the client to wait, from a
+// Retry-After header given in seconds or as an HTTP date.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// wait takes a token from every bucket, then sleeps until the last of them
+// may be used. The tokens are returned if ctx ends first or would end
+// before then.
+func wait(ctx context.Context, buckets ...*Bucket) error {
+	now := time.Now()
+	at := now
+	for _, b := range buckets {
+		if ready := b.reserve(now); ready.After(at) {
+			at = ready
+		}
+	}
+	cancel := func() {
+		for _, b := range buckets {
+			b.cancel()
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && at.After(deadline) {
+		cancel()
+		return ErrDeadline
+	}
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
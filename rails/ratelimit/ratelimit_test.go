@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/transport"
+)
+
+func TestBucket(t *testing.T) {
+	b := NewBucket(20, 2)
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("Expected the burst to be allowed")
+	}
+	if b.Allow() {
+		t.Error("Expected an empty bucket to refuse")
+	}
+
+	start := time.Now()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected to wait about 50ms for a token, waited %v", elapsed)
+	}
+}
+
+func TestBucketDeadline(t *testing.T) {
+	b := NewBucket(1, 1)
+	b.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.Wait(ctx); !errors.Is(err, ErrDeadline) {
+		t.Errorf("Expected ErrDeadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected to fail fast, waited %v", elapsed)
+	}
+
+	// The refused wait must not have used up the next token
+	if ready := b.reserve(time.Now()); time.Until(ready) > time.Second {
+		t.Errorf("Expected the next token within a second, got %v", time.Until(ready))
+	}
+}
+
+func TestBucketConcurrent(t *testing.T) {
+	b := NewBucket(100, 5)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 15; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Wait(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	// 5 at once, then 10 more at 100 per second
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected 15 requests to take about 100ms, took %v", elapsed)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	limiter := New(
+		Limit{Provider: "mpesa", Rate: 1, Burst: 3},
+		Limit{Provider: "mpesa", Operation: "/mpesa/b2c/", Rate: 1},
+		Limit{Provider: "momo", Operation: "disbursement.Transfer", Credential: "bulk", Rate: 1},
+	)
+	request := func(provider, operation, path string) *http.Request {
+		ctx := transport.WithOperation(context.Background(), operation)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://example.com"+path, nil)
+		// Describe reads the provider set by a Transport
+		var described *http.Request
+		transport.New(provider, transport.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			described = r
+			return nil, errors.New("not sent")
+		})).RoundTrip(req)
+		return described
+	}
+
+	b2c := request("mpesa", "", "/mpesa/b2c/v1/paymentrequest")
+	if got := len(limiter.match(b2c, "app")); got != 2 {
+		t.Errorf("Expected B2C to match 2 limits, got %d", got)
+	}
+	if got := len(limiter.match(request("mpesa", "", "/mpesa/stkpush/v1/processrequest"), "app")); got != 1 {
+		t.Errorf("Expected STK Push to match 1 limit, got %d", got)
+	}
+	transfer := request("momo", "disbursement.Transfer", "/disbursement/v1_0/transfer")
+	if len(limiter.match(transfer, "bulk")) != 1 || len(limiter.match(transfer, "checkout")) != 0 {
+		t.Error("Expected the MoMo limit to apply to the bulk credential only")
+	}
+
+	if limiter.match(b2c, "app")[0] == limiter.match(b2c, "other")[0] {
+		t.Error("Expected each credential to get its own bucket")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := New(Limit{Provider: "airtel", Rate: 1000})
+	sent := 0
+	client := transport.Wrap(&http.Client{Transport: transport.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent++
+		header := http.Header{}
+		if sent == 1 {
+			header.Set("Retry-After", "1")
+		}
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: header, Body: io.NopCloser(strings.NewReader(`{}`)), Request: r}, nil
+	})}, "airtel", limiter.Middleware("app"))
+
+	req, _ := http.NewRequest(http.MethodPost, "https://openapiuat.airtel.africa/merchant/v1/payments/", nil)
+	if _, err := client.Do(req); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodPost, "https://openapiuat.airtel.africa/merchant/v1/payments/", nil)
+	_, err := client.Do(req)
+	if !errors.Is(err, ErrDeadline) || railerrors.CategoryOf(err) != railerrors.CategoryRateLimited {
+		t.Errorf("Expected a rate_limited ErrDeadline while paused by Retry-After, got %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("Expected 1 request to reach the provider, got %d", sent)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+		ok    bool
+	}{
+		{"120", 120 * time.Second, 120 * time.Second, true},
+		{time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat), 28 * time.Second, 30 * time.Second, true},
+		{"soon", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{tt.value}}}
+		got, ok := RetryAfter(resp)
+		if ok != tt.ok || got < tt.min || got > tt.max {
+			t.Errorf("RetryAfter(%q) = %v, %v", tt.value, got, ok)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package reconcile
+
+import (
+	"github.com/nutcas3/payment-rails/coop/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// FromCoop returns the lines of a Co-operative Bank account transactions
+// response. Co-op has no reference field, so the serial number stands in.
+func FromCoop(statement *api.AccountTransactionsResponse) []StatementEntry {
+	entries := make([]StatementEntry, 0, len(statement.Transactions))
+	for _, tx := range statement.Transactions {
+		kind := tx.DebitCreditIndicator
+		if kind == "" {
+			kind = tx.TransactionType
+		}
+		l := line{
+			transactionID: tx.TransactionID,
+			reference:     tx.SerialNumber,
+			narration:     tx.Narration,
+			amount:        tx.Amount.Decimal(),
+			kind:          kind,
+			date:          tx.TransactionDate,
+			balance:       tx.RunningBookBalance.Decimal(),
+		}
+		account, currency := tx.AccountNumber, tx.Currency
+		if account == "" {
+			account = statement.AccountNumber
+		}
+		if currency == "" {
+			currency = statement.Currency
+		}
+		entries = append(entries, l.entry(rails.ProviderCoop, account, currency))
+	}
+	return entries
+}
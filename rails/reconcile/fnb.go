@@ -0,0 +1,26 @@
+package reconcile
+
+import (
+	"github.com/nutcas3/payment-rails/fnb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// FromFNB returns the lines of an FNB transaction history page. The
+// history does not name the account's currency, so the caller passes it.
+func FromFNB(history *api.TransactionHistoryResponse, currency money.Currency) []StatementEntry {
+	entries := make([]StatementEntry, 0, len(history.Transactions))
+	for _, tx := range history.Transactions {
+		l := line{
+			transactionID: tx.TransactionID,
+			reference:     tx.Reference,
+			narration:     tx.Description,
+			amount:        tx.Amount.Decimal(),
+			kind:          tx.Type,
+			date:          tx.Date,
+			balance:       tx.Balance.Decimal(),
+		}
+		entries = append(entries, l.entry(rails.ProviderFNB, history.AccountNumber, currency))
+	}
+	return entries
+}
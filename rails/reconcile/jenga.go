@@ -0,0 +1,41 @@
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/jenga/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+// FromJenga returns the lines of a Jenga full statement. Jenga sends
+// amounts as text, so a line whose amount cannot be read is an error.
+func FromJenga(statement *api.FullStatementResponse) ([]StatementEntry, error) {
+	currency := money.Currency(statement.Data.Currency)
+	entries := make([]StatementEntry, 0, len(statement.Data.Transactions))
+	for i, tx := range statement.Data.Transactions {
+		amount, err := decimal.NewFromString(strings.ReplaceAll(tx.Amount, ",", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount %q on line %d: %w", tx.Amount, i+1, err)
+		}
+		balance, _ := decimal.NewFromString(tx.RunningBalance.Amount.String())
+
+		date := parseDate(tx.PostedDateTime)
+		if date.IsZero() {
+			date = parseDate(tx.Date)
+		}
+		l := line{
+			transactionID: tx.TransactionId,
+			reference:     tx.Reference,
+			narration:     tx.Description,
+			amount:        amount,
+			kind:          tx.Type,
+			date:          date,
+			balance:       balance,
+		}
+		entries = append(entries, l.entry(rails.ProviderJenga, statement.Data.AccountNumber, currency))
+	}
+	return entries, nil
+}
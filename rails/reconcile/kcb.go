@@ -0,0 +1,24 @@
+package reconcile
+
+import (
+	"github.com/nutcas3/payment-rails/kcb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// FromKCB returns the lines of a KCB account statement.
+func FromKCB(statement *api.StatementResponse) []StatementEntry {
+	entries := make([]StatementEntry, 0, len(statement.Data.Transactions))
+	for _, tx := range statement.Data.Transactions {
+		l := line{
+			transactionID: tx.TransactionID,
+			reference:     tx.Reference,
+			narration:     tx.Description,
+			amount:        tx.Amount.Decimal(),
+			kind:          tx.Type,
+			date:          tx.TransactionDate,
+			balance:       tx.Balance.Decimal(),
+		}
+		entries = append(entries, l.entry(rails.ProviderKCB, statement.Data.AccountNumber, statement.Data.Currency))
+	}
+	return entries
+}
@@ -0,0 +1,25 @@
+package reconcile
+
+import (
+	"github.com/nutcas3/payment-rails/ncba/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// FromNCBA returns the lines of an NCBA account statement. The statement
+// names neither the account nor its currency, so the caller passes both.
+func FromNCBA(statement *api.AccountStatement, account string, currency money.Currency) []StatementEntry {
+	entries := make([]StatementEntry, 0, len(statement.Transactions))
+	for _, tx := range statement.Transactions {
+		l := line{
+			reference: tx.Reference,
+			narration: tx.Description,
+			amount:    tx.Amount.Decimal(),
+			kind:      tx.Type,
+			date:      parseDate(tx.Date),
+			balance:   tx.Balance.Decimal(),
+		}
+		entries = append(entries, l.entry(rails.ProviderNCBA, account, currency))
+	}
+	return entries
+}
@@ -0,0 +1,275 @@
+// Package reconcile matches bank and wallet statements against the payments
+// a merchant expects to see on them. Statements from each provider are first
+// normalized into StatementEntry values, see FromJenga and the other From
+// functions, and then matched:
+//
+//	entries, err := reconcile.FromJenga(statement)
+//	result := reconcile.Reconcile(expected, entries, reconcile.Settings{})
+//	for _, m := range result.AmountMismatches {
+//		log.Printf("%s: expected %s, got %s", m.Expected.ID, m.Expected.Amount, m.Entry.Amount)
+//	}
+//
+// An entry matches an expected payment when its reference or transaction ID
+// is the payment's reference, or when its narration holds the reference,
+// possibly cut short by the bank, and the amount, direction and date agree.
+package reconcile
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/lifecycle"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// Direction is which way money moved on the account.
+type Direction string
+
+const (
+	Credit Direction = "credit" // Money in
+	Debit  Direction = "debit"  // Money out
+)
+
+// StatementEntry is a statement line in the same shape for every provider.
+type StatementEntry struct {
+	Provider      rails.Provider
+	Account       string
+	TransactionID string // The provider's ID for the line, if any
+	Reference     string
+	Narration     string
+	Amount        money.Money // Always positive, see Direction
+	Direction     Direction
+	Date          time.Time   // Zero if the provider's date could not be read
+	Balance       money.Money // The running balance, zero if not given
+}
+
+// Expected is a payment the merchant expects to find on a statement.
+type Expected struct {
+	ID        string // The merchant's ID, e.g. an order or payout ID
+	Reference string // The reference sent to the provider
+	Amount    money.Money
+	Direction Direction // Empty matches either direction
+	Date      time.Time // When the payment was made
+}
+
+// ExpectPayment returns the Expected for a tracked payment. Collections
+// are credits, and disbursements and refunds debits.
+func ExpectPayment(p lifecycle.Payment) Expected {
+	e := Expected{
+		ID:        p.ID,
+		Reference: p.Reference,
+		Amount:    p.Amount,
+		Date:      p.CreatedAt,
+	}
+	switch p.Kind {
+	case rails.KindCollection:
+		e.Direction = Credit
+	case rails.KindDisbursement, rails.KindRefund:
+		e.Direction = Debit
+	}
+	return e
+}
+
+// Rule is how an entry was matched.
+type Rule string
+
+const (
+	RuleReference Rule = "reference" // The reference or transaction ID is the expected reference
+	RuleNarration Rule = "narration" // The narration holds the reference, possibly truncated
+)
+
+// Match pairs an expected payment with its statement entry.
+type Match struct {
+	Expected Expected
+	Entry    StatementEntry
+	Rule     Rule
+}
+
+// Mismatch is an entry with an expected payment's reference but a
+// different amount.
+type Mismatch struct {
+	Expected   Expected
+	Entry      StatementEntry
+	Difference money.Money // Entry amount less the expected amount
+}
+
+// Result sorts expected payments and statement entries into buckets. Each
+// payment and each entry is in exactly one bucket.
+type Result struct {
+	Matched            []Match
+	AmountMismatches   []Mismatch
+	UnmatchedExpected  []Expected
+	UnmatchedStatement []StatementEntry
+}
+
+// Settings control how loosely entries are matched. Zero fields take their
+// value from DefaultSettings.
+type Settings struct {
+	// DateWindow is how far an entry's date may be from the expected date
+	// for a narration match. Banks post transfers a day or more late.
+	DateWindow time.Duration
+
+	// MinReferenceLength is the fewest characters of a reference a
+	// truncated narration must keep to match.
+	MinReferenceLength int
+}
+
+// DefaultSettings matches narrations within 3 days that keep at least 6
+// characters of the reference.
+func DefaultSettings() Settings {
+	return Settings{
+		DateWindow:         72 * time.Hour,
+		MinReferenceLength: 6,
+	}
+}
+
+func (s Settings) withDefaults() Settings {
+	d := DefaultSettings()
+	if s.DateWindow <= 0 {
+		s.DateWindow = d.DateWindow
+	}
+	if s.MinReferenceLength <= 0 {
+		s.MinReferenceLength = d.MinReferenceLength
+	}
+	return s
+}
+
+// Reconcile matches entries against expected payments. Exact reference
+// matches are made first, then narration matches for what is left. When
+// several entries fit a payment, one with its amount is taken over one
+// without, then the one closest to its date.
+func Reconcile(expected []Expected, entries []StatementEntry, settings Settings) Result {
+	settings = settings.withDefaults()
+
+	var result Result
+	used := make([]bool, len(entries))
+	done := make([]bool, len(expected))
+
+	// Exact references: first with the same amount, so an entry with the
+	// payment's amount is preferred to a closer one without, then whatever
+	// the amount
+	for _, sameAmount := range []bool{true, false} {
+		for i, exp := range expected {
+			ref := normalize(exp.Reference)
+			if done[i] || ref == "" {
+				continue
+			}
+			j := closest(exp, entries, used, func(e StatementEntry) bool {
+				return directionAgrees(exp, e) && (normalize(e.Reference) == ref || normalize(e.TransactionID) == ref) &&
+					(!sameAmount || e.Amount.Equal(exp.Amount))
+			})
+			if j < 0 {
+				continue
+			}
+			used[j], done[i] = true, true
+			entry := entries[j]
+			if entry.Amount.Equal(exp.Amount) {
+				result.Matched = append(result.Matched, Match{Expected: exp, Entry: entry, Rule: RuleReference})
+				continue
+			}
+			diff, err := entry.Amount.Sub(exp.Amount)
+			if err != nil {
+				// Different currencies: report the entry amount as it is
+				diff = entry.Amount
+			}
+			result.AmountMismatches = append(result.AmountMismatches, Mismatch{Expected: exp, Entry: entry, Difference: diff})
+		}
+	}
+
+	// Narrations, only with the same amount and a date in the window
+	for i, exp := range expected {
+		if done[i] {
+			continue
+		}
+		ref := normalize(exp.Reference)
+		if len(ref) < settings.MinReferenceLength {
+			continue
+		}
+		j := closest(exp, entries, used, func(e StatementEntry) bool {
+			return directionAgrees(exp, e) && e.Amount.Equal(exp.Amount) &&
+				withinWindow(exp.Date, e.Date, settings.DateWindow) &&
+				narrationHolds(normalize(e.Narration), ref, settings.MinReferenceLength)
+		})
+		if j < 0 {
+			continue
+		}
+		used[j], done[i] = true, true
+		result.Matched = append(result.Matched, Match{Expected: exp, Entry: entries[j], Rule: RuleNarration})
+	}
+
+	for i, exp := range expected {
+		if !done[i] {
+			result.UnmatchedExpected = append(result.UnmatchedExpected, exp)
+		}
+	}
+	for j, entry := range entries {
+		if !used[j] {
+			result.UnmatchedStatement = append(result.UnmatchedStatement, entry)
+		}
+	}
+	return result
+}
+
+// closest returns the index of the unused entry fitting match whose date is
+// closest to exp's, or -1.
+func closest(exp Expected, entries []StatementEntry, used []bool, match func(StatementEntry) bool) int {
+	best := -1
+	var bestDistance time.Duration
+	for j, entry := range entries {
+		if used[j] || !match(entry) {
+			continue
+		}
+		distance := entry.Date.Sub(exp.Date).Abs()
+		if best < 0 || distance < bestDistance {
+			best, bestDistance = j, distance
+		}
+	}
+	return best
+}
+
+func directionAgrees(exp Expected, entry StatementEntry) bool {
+	return exp.Direction == "" || entry.Direction == "" || exp.Direction == entry.Direction
+}
+
+// withinWindow reports whether two dates are at most window apart. Entries
+// without a date never are, unless the expected payment has none either.
+func withinWindow(expected, actual time.Time, window time.Duration) bool {
+	if expected.IsZero() {
+		return true
+	}
+	if actual.IsZero() {
+		return false
+	}
+	return actual.Sub(expected).Abs() <= window
+}
+
+// narrationHolds reports whether narration contains ref, or ends with at
+// least minLength of its leading characters, as when a bank cuts the
+// narration short.
+func narrationHolds(narration, ref string, minLength int) bool {
+	if narration == "" {
+		return false
+	}
+	if strings.Contains(narration, ref) {
+		return true
+	}
+	for n := len(ref) - 1; n >= minLength; n-- {
+		if strings.HasSuffix(narration, ref[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize upper-cases s and drops everything but letters and digits, since
+// banks add or strip spaces, dashes and slashes in references.
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,187 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jenga "github.com/nutcas3/payment-rails/jenga/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/lifecycle"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+func kes(s string) money.Money {
+	m, _ := money.Parse(s, "KES")
+	return m
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 10, 0, 0, 0, time.UTC)
+}
+
+func TestReconcile(t *testing.T) {
+	expected := []Expected{
+		{ID: "order-1", Reference: "INV-2026-0001", Amount: kes("1500"), Direction: Credit, Date: day(2)},
+		{ID: "order-2", Reference: "INV-2026-0002", Amount: kes("2500"), Direction: Credit, Date: day(2)},
+		{ID: "order-3", Reference: "INV-2026-0003", Amount: kes("990.50"), Direction: Credit, Date: day(2)},
+		{ID: "order-4", Reference: "INV-2026-0004", Amount: kes("100"), Direction: Credit, Date: day(2)},
+		{ID: "payout-1", Reference: "PAY-778899", Amount: kes("300"), Direction: Debit, Date: day(3)},
+	}
+	entries := []StatementEntry{
+		{Reference: "inv 2026 0001", Amount: kes("1500.00"), Direction: Credit, Date: day(3)},
+		{Reference: "INV-2026-0002", Amount: kes("2400"), Direction: Credit, Date: day(2)},
+		// The bank cut the narration short
+		{Narration: "MPESA C2B 254712345678 INV20260", Amount: kes("990.5"), Direction: Credit, Date: day(4)},
+		// Holds the reference, but posted too late
+		{Narration: "TRANSFER INV-2026-0004", Amount: kes("100"), Direction: Credit, Date: day(9)},
+		// Same reference, but money in rather than out
+		{Reference: "PAY-778899", Amount: kes("300"), Direction: Credit, Date: day(3)},
+		{Reference: "PAY-778899", Amount: kes("300"), Direction: Debit, Date: day(3)},
+	}
+
+	result := Reconcile(expected, entries, Settings{})
+
+	if len(result.Matched) != 3 {
+		t.Fatalf("Expected 3 matches, got %+v", result.Matched)
+	}
+	want := map[string]Rule{"order-1": RuleReference, "order-3": RuleNarration, "payout-1": RuleReference}
+	for _, m := range result.Matched {
+		if rule, found := want[m.Expected.ID]; !found || rule != m.Rule {
+			t.Errorf("Unexpected match %s by %s", m.Expected.ID, m.Rule)
+		}
+		if m.Expected.ID == "payout-1" && m.Entry.Direction != Debit {
+			t.Errorf("Expected the payout to match the debit, got %s", m.Entry.Direction)
+		}
+	}
+
+	if len(result.AmountMismatches) != 1 {
+		t.Fatalf("Expected 1 amount mismatch, got %+v", result.AmountMismatches)
+	}
+	if m := result.AmountMismatches[0]; m.Expected.ID != "order-2" || !m.Difference.Equal(kes("-100")) {
+		t.Errorf("Expected order-2 to be KES 100 short, got %s difference %s", m.Expected.ID, m.Difference)
+	}
+
+	if len(result.UnmatchedExpected) != 1 || result.UnmatchedExpected[0].ID != "order-4" {
+		t.Errorf("Expected order-4 to be unmatched, got %+v", result.UnmatchedExpected)
+	}
+	if len(result.UnmatchedStatement) != 2 {
+		t.Errorf("Expected 2 unmatched entries, got %+v", result.UnmatchedStatement)
+	}
+
+	// A wider window takes in the late transfer
+	result = Reconcile(expected, entries, Settings{DateWindow: 7 * 24 * time.Hour})
+	if len(result.UnmatchedExpected) != 0 {
+		t.Errorf("Expected every payment to be found, got %+v", result.UnmatchedExpected)
+	}
+}
+
+func TestReconcileClosestDate(t *testing.T) {
+	expected := []Expected{{ID: "order-1", Reference: "ABC123456", Amount: kes("50"), Date: day(10)}}
+	entries := []StatementEntry{
+		{Narration: "POS ABC123456", Amount: kes("50"), Date: day(8)},
+		{Narration: "POS ABC123456", Amount: kes("50"), Date: day(11)},
+	}
+	result := Reconcile(expected, entries, Settings{})
+	if len(result.Matched) != 1 || !result.Matched[0].Entry.Date.Equal(day(11)) {
+		t.Errorf("Expected the entry closest in date to match, got %+v", result.Matched)
+	}
+
+	// Too little of the reference is left to be sure
+	entries = []StatementEntry{{Narration: "POS ABC12", Amount: kes("50"), Date: day(10)}}
+	if result := Reconcile(expected, entries, Settings{}); len(result.Matched) != 0 {
+		t.Errorf("Expected a 5-character prefix not to match, got %+v", result.Matched)
+	}
+}
+
+func TestReconcileSharedReference(t *testing.T) {
+	// A reversal and a retry share the payment's reference
+	expected := []Expected{{ID: "order-1", Reference: "INV-7", Amount: kes("500"), Date: day(10)}}
+	entries := []StatementEntry{
+		{Reference: "INV-7", Amount: kes("50"), Date: day(10)},
+		{Reference: "INV-7", Amount: kes("500"), Date: day(12)},
+	}
+	result := Reconcile(expected, entries, Settings{})
+	if len(result.Matched) != 1 || !result.Matched[0].Entry.Amount.Equal(kes("500")) || len(result.AmountMismatches) != 0 {
+		t.Errorf("Expected the entry with the same amount to match, got %+v", result)
+	}
+
+	// Two payments with one reference each get the entry with their amount
+	expected = append(expected, Expected{ID: "order-2", Reference: "INV-7", Amount: kes("50"), Date: day(12)})
+	result = Reconcile(expected, entries, Settings{})
+	if len(result.Matched) != 2 || len(result.AmountMismatches) != 0 {
+		t.Errorf("Expected both payments to match their amounts, got %+v", result)
+	}
+
+	// Without an entry of the same amount, the closest is a mismatch
+	result = Reconcile(expected[:1], entries[:1], Settings{})
+	if len(result.AmountMismatches) != 1 {
+		t.Errorf("Expected an amount mismatch, got %+v", result)
+	}
+}
+
+func TestExpectPayment(t *testing.T) {
+	e := ExpectPayment(lifecycle.Payment{ID: "payout-9", Kind: rails.KindDisbursement, Reference: "R1", Amount: kes("10"), CreatedAt: day(1)})
+	if e.Direction != Debit || e.Reference != "R1" || !e.Date.Equal(day(1)) {
+		t.Errorf("Unexpected %+v", e)
+	}
+}
+
+func TestFromJenga(t *testing.T) {
+	var statement jenga.FullStatementResponse
+	err := json.Unmarshal([]byte(`{
+		"status": true,
+		"data": {
+			"currency": "KES",
+			"accountNumber": "0011547896523",
+			"transactions": [
+				{"reference": "S2344566", "date": "2026-03-02", "amount": "1,000.00", "description": "EAZZYPAY INV-2026-0001", "type": "Credit", "transactionId": "TX1", "runningBalance": {"amount": 5000, "currency": "KES"}},
+				{"reference": "S2344567", "date": "2026-03-02T11:30:00", "amount": "-250", "description": "CHARGE", "type": "", "transactionId": "TX2"}
+			]
+		}
+	}`), &statement)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	entries, err := FromJenga(&statement)
+	if err != nil {
+		t.Fatalf("FromJenga failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Provider != rails.ProviderJenga || first.Account != "0011547896523" || !first.Amount.Equal(kes("1000")) ||
+		first.Direction != Credit || !first.Balance.Equal(kes("5000")) || first.Date.IsZero() {
+		t.Errorf("Unexpected entry %+v", first)
+	}
+	if second := entries[1]; second.Direction != Debit || !second.Amount.Equal(kes("250")) || second.Date.Hour() != 11 {
+		t.Errorf("Expected a signed amount to make a debit, got %+v", second)
+	}
+
+	statement.Data.Transactions[0].Amount = "n/a"
+	if _, err := FromJenga(&statement); err == nil {
+		t.Error("Expected an error for an unreadable amount")
+	}
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		kind   string
+		amount int64
+		want   Direction
+	}{
+		{"CREDIT", 10, Credit},
+		{"Dr", 10, Debit},
+		{" c ", 10, Credit},
+		{"DEPOSIT", 10, ""},
+		{"", -10, Debit},
+	}
+	for _, tt := range tests {
+		if got := direction(tt.kind, decimal.NewFromInt(tt.amount)); got != tt.want {
+			t.Errorf("direction(%q, %d) = %q, expected %q", tt.kind, tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package reconcile
+
+import (
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/sasapay/pkg/api"
+)
+
+// FromSasaPay returns the lines of a SasaPay wallet statement. The
+// statement does not name the wallet's currency, so the caller passes it.
+func FromSasaPay(statement *api.WalletStatementResponse, currency money.Currency) []StatementEntry {
+	entries := make([]StatementEntry, 0, len(statement.Transactions))
+	for _, tx := range statement.Transactions {
+		l := line{
+			transactionID: tx.TransactionID,
+			reference:     tx.Reference,
+			narration:     tx.Description,
+			amount:        tx.Amount,
+			kind:          tx.Type,
+			date:          tx.Timestamp,
+			balance:       tx.Balance,
+		}
+		entries = append(entries, l.entry(rails.ProviderSasaPay, statement.WalletID, currency))
+	}
+	return entries
+}
@@ -0,0 +1,77 @@
+package reconcile
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/shopspring/decimal"
+)
+
+// line is a statement line as a provider reports it, before the amount is
+// signed and the direction worked out.
+type line struct {
+	transactionID string
+	reference     string
+	narration     string
+	amount        decimal.Decimal
+	kind          string // The provider's debit or credit marker, if any
+	date          time.Time
+	balance       decimal.Decimal
+}
+
+func (l line) entry(provider rails.Provider, account string, currency money.Currency) StatementEntry {
+	return StatementEntry{
+		Provider:      provider,
+		Account:       account,
+		TransactionID: strings.TrimSpace(l.transactionID),
+		Reference:     strings.TrimSpace(l.reference),
+		Narration:     strings.TrimSpace(l.narration),
+		Amount:        money.New(l.amount.Abs(), currency),
+		Direction:     direction(l.kind, l.amount),
+		Date:          l.date,
+		Balance:       money.New(l.balance, currency),
+	}
+}
+
+// direction reads a debit or credit marker such as "CREDIT", "Dr" or "C".
+// Without one, a negative amount is a debit and any other is left open.
+func direction(kind string, amount decimal.Decimal) Direction {
+	switch strings.ToUpper(strings.TrimSpace(kind)) {
+	case "C", "CR", "CREDIT":
+		return Credit
+	case "D", "DR", "DEBIT":
+		return Debit
+	}
+	if amount.IsNegative() {
+		return Debit
+	}
+	return ""
+}
+
+// dateLayouts are the date formats seen on provider statements.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02/01/2006 15:04:05",
+	"02/01/2006",
+	"02-01-2006",
+	"02 Jan 2006",
+}
+
+// parseDate returns the date in s in any of dateLayouts, or the zero time.
+// An unreadable date only keeps a line out of narration matching, so it is
+// not an error.
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
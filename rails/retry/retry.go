@@ -0,0 +1,189 @@
+// Package retry resends provider requests that failed for transient reasons,
+// such as a 502 from a gateway or a connection reset, waiting an
+// exponentially growing, jittered delay between attempts.
+//
+// Only requests that are safe to send twice are retried: those with an
+// idempotent method, those carrying an idempotency key header, and those
+// whose context was marked with WithIdempotent.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// IdempotencyHeaders are request headers whose presence makes a request safe
+// to retry. MoMo's X-Reference-Id identifies the resource being created, so a
+// repeated request is rejected rather than applied twice.
+var IdempotencyHeaders = []string{"Idempotency-Key", "X-Idempotency-Key", "X-Reference-Id"}
+
+// Policy controls how many times a request is sent and how long to wait in
+// between. The zero Policy sends each request once.
+type Policy struct {
+	// MaxAttempts is the total number of times a request is sent, including
+	// the first.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles for each
+	// further retry, up to MaxDelay, and the actual wait is a random duration
+	// up to that value.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Statuses are the response status codes that are retried.
+	Statuses []int
+
+	// OnRetry, if set, is called before each retry.
+	OnRetry func(Attempt)
+}
+
+// Attempt describes a failed attempt that is about to be retried.
+type Attempt struct {
+	Request *http.Request
+
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt int
+
+	// StatusCode is the response status, or 0 if Err is set.
+	StatusCode int
+	Err        error
+
+	// Delay is how long is waited before the next attempt.
+	Delay time.Duration
+}
+
+// DefaultPolicy sends a request up to 3 times on gateway errors and
+// transient network failures.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Statuses: []int{
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type idempotentKey struct{}
+
+// WithIdempotent marks requests made with ctx as safe to retry, for
+// operations such as status queries that use POST but change nothing.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// Idempotent reports whether req can be sent more than once without risk of
+// the provider acting on it twice.
+func Idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	for _, h := range IdempotencyHeaders {
+		if req.Header.Get(h) != "" {
+			return true
+		}
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// Do sends req with send, retrying per the policy. Requests that are not
+// Idempotent, or whose body cannot be replayed, are sent once.
+func (p Policy) Do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if p.MaxAttempts <= 1 || !Idempotent(req) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return send(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := send(req)
+		if attempt >= p.MaxAttempts || !p.retryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		delay := p.delay(attempt)
+		if p.OnRetry != nil {
+			a := Attempt{Request: req, Attempt: attempt, Err: err, Delay: delay}
+			if resp != nil {
+				a.StatusCode = resp.StatusCode
+			}
+			p.OnRetry(a)
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (p Policy) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return Transient(err)
+	}
+	return slices.Contains(p.Statuses, resp.StatusCode)
+}
+
+// delay returns a random duration up to BaseDelay doubled for each attempt
+// after the first, capped at MaxDelay.
+func (p Policy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// Transient reports whether err is a network failure worth retrying: a
+// timeout, a reset or refused connection, or a connection closed before the
+// response arrived.
+func Transient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
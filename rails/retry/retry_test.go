@@ -0,0 +1,210 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func testPolicy(attempts *[]Attempt) Policy {
+	p := DefaultPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 2 * time.Millisecond
+	p.OnRetry = func(a Attempt) { *attempts = append(*attempts, a) }
+	return p
+}
+
+func failingServer(failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(body)
+	}))
+	return server, &calls
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	server, calls := failingServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var attempts []Attempt
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := testPolicy(&attempts).Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("Expected 2 retries reported, got %d", len(attempts))
+	}
+	if attempts[0].Attempt != 1 || attempts[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected attempt 1 with status 503, got %+v", attempts[0])
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	server, calls := failingServer(10, http.StatusBadGateway)
+	defer server.Close()
+
+	var attempts []Attempt
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := testPolicy(&attempts).Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected last status 502, got %d", resp.StatusCode)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", *calls)
+	}
+}
+
+func TestRetrySkipsNonIdempotentPost(t *testing.T) {
+	server, calls := failingServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var attempts []Attempt
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+	resp, err := testPolicy(&attempts).Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, got %d", *calls)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("Expected no retries, got %d", len(attempts))
+	}
+}
+
+func TestRetryPostWithIdempotencyKey(t *testing.T) {
+	server, calls := failingServer(1, http.StatusGatewayTimeout)
+	defer server.Close()
+
+	var attempts []Attempt
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"amount":10}`))
+	req.Header.Set("Idempotency-Key", "pay-1")
+	resp, err := testPolicy(&attempts).Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"amount":10}` {
+		t.Errorf("Expected body to be replayed, got '%s'", body)
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", *calls)
+	}
+}
+
+func TestRetryWithIdempotentContext(t *testing.T) {
+	server, calls := failingServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var attempts []Attempt
+	ctx := WithIdempotent(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader("{}"))
+	resp, err := testPolicy(&attempts).Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if *calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", *calls)
+	}
+}
+
+func TestRetryTransientError(t *testing.T) {
+	var calls int
+	send := func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, syscall.ECONNRESET
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+
+	var attempts []Attempt
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	resp, err := testPolicy(&attempts).Do(req, send)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(attempts) != 1 || !errors.Is(attempts[0].Err, syscall.ECONNRESET) {
+		t.Errorf("Expected one retry after ECONNRESET, got %+v", attempts)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	send := func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	}
+
+	var attempts []Attempt
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.invalid", nil)
+	resp, _ := testPolicy(&attempts).Do(req, send)
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the 503 to be returned, got %v", resp)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("Expected no retries after cancel, got %d", len(attempts))
+	}
+}
+
+func TestZeroPolicySendsOnce(t *testing.T) {
+	server, calls := failingServer(1, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := Policy{}.Do(req, http.DefaultClient.Do)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if *calls != 1 {
+		t.Errorf("Expected 1 call, got %d", *calls)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := p.delay(attempt); d < 0 || d > max {
+				t.Errorf("Expected delay for attempt %d within [0, %v], got %v", attempt, max, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rails
+
+import (
+	"context"
+
+	"github.com/nutcas3/payment-rails/rails/msisdn"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+	"github.com/nutcas3/payment-rails/sasapay"
+	"github.com/nutcas3/payment-rails/sasapay/pkg/api"
+)
+
+type SasaPayConfig struct {
+	MerchantCode string
+	CallbackURL  string
+}
+
+// SasaPayAdapter collects through C2B and disburses through B2C. SasaPay
+// has no refund API, so it does not implement Refunder.
+type SasaPayAdapter struct {
+	client *sasapay.Client
+	config SasaPayConfig
+}
+
+func NewSasaPayAdapter(client *sasapay.Client, config SasaPayConfig) *SasaPayAdapter {
+	return &SasaPayAdapter{client: client, config: config}
+}
+
+func (a *SasaPayAdapter) Provider() Provider {
+	return ProviderSasaPay
+}
+
+func (a *SasaPayAdapter) currency() string {
+	return "KES"
+}
+
+func (a *SasaPayAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "CustomerToBusiness")
+	resp, err := a.client.CustomerToBusinessWithContext(ctx, api.C2BRequest{
+		MerchantCode: a.config.MerchantCode,
+		PhoneNumber:  adapterPhone(ProviderSasaPay, req.Phone, msisdn.Kenya),
+		Amount:       req.Amount,
+		Reference:    req.Reference,
+		Description:  req.Description,
+		CallbackURL:  firstNonEmpty(req.CallbackURL, a.config.CallbackURL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sasaPayResult(KindCollection, resp.TransactionID, req.Reference, resp.Status, resp.Message, resp), nil
+}
+
+func (a *SasaPayAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "BusinessToCustomer")
+	resp, err := a.client.BusinessToCustomerWithContext(ctx, api.B2CRequest{
+		MerchantCode: a.config.MerchantCode,
+		PhoneNumber:  adapterPhone(ProviderSasaPay, req.Phone, msisdn.Kenya),
+		Amount:       req.Amount,
+		Reference:    req.Reference,
+		Description:  req.Description,
+		CallbackURL:  firstNonEmpty(req.CallbackURL, a.config.CallbackURL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sasaPayResult(KindDisbursement, resp.TransactionID, req.Reference, resp.Status, resp.Message, resp), nil
+}
+
+func (a *SasaPayAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	ctx = telemetry.Name(ctx, "CheckTransactionStatus")
+	resp, err := a.client.CheckTransactionStatusWithContext(ctx, api.TransactionStatusRequest{
+		TransactionID: req.TransactionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sasaPayResult(req.Kind, resp.TransactionID, "", resp.Status, resp.Message, resp), nil
+}
+
+// SasaPayStatus maps a SasaPay transaction status onto a Status.
+func SasaPayStatus(status string) Status {
+	return statusFromText(status)
+}
+
+func sasaPayResult(kind Kind, transactionID, reference, status, message string, raw interface{}) *PaymentResult {
+	return &PaymentResult{
+		Provider:       ProviderSasaPay,
+		Kind:           kind,
+		TransactionID:  transactionID,
+		Reference:      reference,
+		Status:         SasaPayStatus(status),
+		ProviderStatus: status,
+		Message:        message,
+		Raw:            raw,
+	}
+}
@@ -0,0 +1,183 @@
+// Package signature verifies webhooks signed with an HMAC-SHA256 of their
+// body, the scheme used by Jenga, Absa, FNB, Standard Bank and SasaPay.
+//
+// A Verifier compares signatures in constant time and refuses webhooks
+// when no secret is configured. It also refuses webhooks sent outside a
+// time window around now, and drops event IDs it has already seen, so a
+// captured webhook cannot be replayed:
+//
+//	if err := v.Verify(body, r.Header.Get("X-Jenga-Signature"), secret); err != nil {
+//		// 401
+//	}
+//	// decode the event
+//	if err := v.Check(ctx, "jenga:"+event.ID, event.CreatedAt); errors.Is(err, signature.ErrReplayed) {
+//		// already handled, answer 200
+//	}
+package signature
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+var (
+	// ErrNoSecret is returned when no secret is configured to verify a
+	// webhook with, unless the Verifier allows unsigned webhooks.
+	ErrNoSecret = errors.New("signature: no webhook secret configured")
+
+	// ErrInvalid is returned for a missing or wrong signature.
+	ErrInvalid = errors.New("signature: invalid signature")
+
+	// ErrTimestamp is returned for a webhook without a timestamp, or sent
+	// further from now than the Verifier's tolerance.
+	ErrTimestamp = errors.New("signature: timestamp outside the allowed window")
+
+	// ErrReplayed is returned for an event ID that was already received.
+	ErrReplayed = errors.New("signature: event already received")
+)
+
+const (
+	// DefaultTolerance is how far from now a webhook's timestamp may be.
+	DefaultTolerance = 5 * time.Minute
+
+	// DefaultTTL is how long event IDs are remembered.
+	DefaultTTL = 24 * time.Hour
+)
+
+// Sign returns the hex HMAC-SHA256 of payload keyed with secret.
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Valid reports whether signature is the hex HMAC-SHA256 of payload keyed
+// with secret, in either case. The comparison takes constant time.
+func Valid(payload []byte, signature, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// Store records the IDs of received events. Implementations shared between
+// processes, e.g. backed by Redis, must make Add atomic.
+type Store interface {
+	// Add records id for ttl and returns true, or returns false if id is
+	// already recorded.
+	Add(ctx context.Context, id string, ttl time.Duration) (bool, error)
+
+	// Delete forgets id, so the event is accepted again.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps event IDs in process memory.
+type MemoryStore struct {
+	cache *cache.Cache
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{cache: cache.New(DefaultTTL, 10*time.Minute)}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	return s.cache.Add(id, struct{}{}, ttl) == nil, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id string) error {
+	s.cache.Delete(id)
+	return nil
+}
+
+// Verifier is the policy webhooks are verified with. The zero value checks
+// signatures only; use New for timestamp and replay checks as well.
+type Verifier struct {
+	// AllowUnsigned accepts webhooks without checking their signature when
+	// no secret is configured. It is meant for local testing only.
+	AllowUnsigned bool
+
+	// Tolerance is how far from now a webhook's timestamp may be. Zero
+	// skips the check.
+	Tolerance time.Duration
+
+	// Store, if set, keeps the IDs of received events for TTL, or for
+	// DefaultTTL if TTL is zero, so repeats are refused with ErrReplayed.
+	Store Store
+	TTL   time.Duration
+}
+
+// New returns a Verifier with DefaultTolerance and a MemoryStore.
+func New() *Verifier {
+	return &Verifier{Tolerance: DefaultTolerance, Store: NewMemoryStore()}
+}
+
+// Verify checks that signature is the HMAC of payload keyed with secret. An
+// empty secret fails with ErrNoSecret unless AllowUnsigned is set.
+func (v *Verifier) Verify(payload []byte, signature, secret string) error {
+	if secret == "" {
+		if v.AllowUnsigned {
+			return nil
+		}
+		return ErrNoSecret
+	}
+	if !Valid(payload, signature, secret) {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Check refuses a verified event sent at timestamp, if it is outside
+// Tolerance, or whose id was already received. id should be namespaced by
+// provider, e.g. "fnb:EVT123". An empty id is not checked for replays.
+//
+// Once Check accepts an id, repeats are refused until the caller calls
+// Forget, e.g. because handling the event failed and the provider will
+// send it again.
+func (v *Verifier) Check(ctx context.Context, id string, timestamp time.Time) error {
+	if v.Tolerance > 0 {
+		if timestamp.IsZero() {
+			return ErrTimestamp
+		}
+		if skew := time.Since(timestamp); skew > v.Tolerance || skew < -v.Tolerance {
+			return ErrTimestamp
+		}
+	}
+
+	if v.Store == nil || id == "" {
+		return nil
+	}
+	ttl := v.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	added, err := v.Store.Add(ctx, id, ttl)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// Forget lets an event accepted by Check be received again.
+func (v *Verifier) Forget(ctx context.Context, id string) error {
+	if v.Store == nil || id == "" {
+		return nil
+	}
+	return v.Store.Delete(ctx, id)
+}
@@ -0,0 +1,87 @@
+package signature
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValid(t *testing.T) {
+	payload := []byte(`{"eventId":"EVT123"}`)
+	sig := Sign(payload, "secret")
+
+	tests := []struct {
+		name      string
+		signature string
+		secret    string
+		want      bool
+	}{
+		{"Valid signature", sig, "secret", true},
+		{"Upper case signature", strings.ToUpper(sig), "secret", true},
+		{"Wrong secret", sig, "other", false},
+		{"Empty secret", sig, "", false},
+		{"Empty signature", "", "secret", false},
+		{"Not hex", "not-a-signature", "secret", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(payload, tt.signature, tt.secret); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{}`)
+
+	v := New()
+	if err := v.Verify(payload, Sign(payload, "secret"), "secret"); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := v.Verify(payload, "deadbeef", "secret"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if err := v.Verify(payload, "", ""); !errors.Is(err, ErrNoSecret) {
+		t.Errorf("Expected ErrNoSecret without a secret, got %v", err)
+	}
+
+	v.AllowUnsigned = true
+	if err := v.Verify(payload, "", ""); err != nil {
+		t.Errorf("Expected unsigned webhooks to be allowed, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	v := New()
+
+	if err := v.Check(ctx, "fnb:EVT1", time.Now()); err != nil {
+		t.Fatalf("Expected the first event to be accepted, got %v", err)
+	}
+	if err := v.Check(ctx, "fnb:EVT1", time.Now()); !errors.Is(err, ErrReplayed) {
+		t.Errorf("Expected ErrReplayed, got %v", err)
+	}
+
+	v.Forget(ctx, "fnb:EVT1")
+	if err := v.Check(ctx, "fnb:EVT1", time.Now()); err != nil {
+		t.Errorf("Expected a forgotten event to be accepted, got %v", err)
+	}
+
+	for name, timestamp := range map[string]time.Time{
+		"missing": {},
+		"old":     time.Now().Add(-10 * time.Minute),
+		"future":  time.Now().Add(10 * time.Minute),
+	} {
+		if err := v.Check(ctx, "fnb:"+name, timestamp); !errors.Is(err, ErrTimestamp) {
+			t.Errorf("Expected ErrTimestamp for a %s timestamp, got %v", name, err)
+		}
+	}
+
+	var zero Verifier
+	if err := zero.Check(ctx, "fnb:EVT1", time.Time{}); err != nil {
+		t.Errorf("Expected the zero Verifier to skip checks, got %v", err)
+	}
+}
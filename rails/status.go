@@ -0,0 +1,69 @@
+package rails
+
+import "strings"
+
+// Status is the normalized state of a payment across all providers.
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // Accepted by the provider, outcome not yet known
+	StatusSucceeded Status = "succeeded" // Funds moved
+	StatusFailed    Status = "failed"    // Rejected or declined, no funds moved
+	StatusCancelled Status = "cancelled" // Cancelled by the customer
+	StatusExpired   Status = "expired"   // The customer did not respond in time
+	StatusReversed  Status = "reversed"  // Completed and later reversed or refunded
+	StatusUnknown   Status = "unknown"   // The provider status could not be mapped
+)
+
+// Final reports whether the status will not change without further action.
+func (s Status) Final() bool {
+	switch s {
+	case StatusSucceeded, StatusFailed, StatusCancelled, StatusExpired, StatusReversed:
+		return true
+	}
+	return false
+}
+
+// textStatuses maps the free-text statuses used by providers that do not
+// document a fixed set of codes.
+var textStatuses = map[string]Status{
+	"pending":    StatusPending,
+	"processing": StatusPending,
+	"queued":     StatusPending,
+	"initiated":  StatusPending,
+	"accepted":   StatusPending,
+	"received":   StatusPending,
+	"ongoing":    StatusPending,
+	"success":    StatusSucceeded,
+	"successful": StatusSucceeded,
+	"succeeded":  StatusSucceeded,
+	"completed":  StatusSucceeded,
+	"complete":   StatusSucceeded,
+	"paid":       StatusSucceeded,
+	"failed":     StatusFailed,
+	"failure":    StatusFailed,
+	"declined":   StatusFailed,
+	"rejected":   StatusFailed,
+	"error":      StatusFailed,
+	"cancelled":  StatusCancelled,
+	"canceled":   StatusCancelled,
+	"expired":    StatusExpired,
+	"timeout":    StatusExpired,
+	"timedout":   StatusExpired,
+	"reversed":   StatusReversed,
+	"refunded":   StatusReversed,
+}
+
+// ParseStatus maps a free-text status, e.g. "Completed" or "DECLINED", onto
+// a Status.
+func ParseStatus(s string) Status {
+	return statusFromText(s)
+}
+
+func statusFromText(s string) Status {
+	key := strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(s)))
+	if status, ok := textStatuses[key]; ok {
+		return status
+	}
+	return StatusUnknown
+}
@@ -0,0 +1,48 @@
+package rails
+
+import "testing"
+
+func TestStatusFinal(t *testing.T) {
+	if StatusPending.Final() || StatusUnknown.Final() {
+		t.Error("Expected pending and unknown not to be final")
+	}
+	if !StatusSucceeded.Final() || !StatusReversed.Final() {
+		t.Error("Expected succeeded and reversed to be final")
+	}
+}
+
+func TestProviderStatusMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Status
+		want Status
+	}{
+		{"mpesa success", MpesaSTKStatus("0"), StatusSucceeded},
+		{"mpesa cancelled", MpesaSTKStatus("1032"), StatusCancelled},
+		{"mpesa unreachable", MpesaSTKStatus("1037"), StatusExpired},
+		{"mpesa insufficient balance", MpesaSTKStatus("1"), StatusFailed},
+		{"mpesa invalid code", MpesaSTKStatus(""), StatusUnknown},
+		{"mpesa still processing", MpesaSTKStatus("4999"), StatusPending},
+		{"mpesa unlisted code", MpesaSTKStatus("4321"), StatusUnknown},
+		{"airtel success", AirtelStatus("TS"), StatusSucceeded},
+		{"airtel in progress", AirtelStatus("TIP"), StatusPending},
+		{"airtel ambiguous", AirtelStatus("TA"), StatusPending},
+		{"airtel expired", AirtelStatus("TE"), StatusExpired},
+		{"airtel failed", AirtelStatus("tf"), StatusFailed},
+		{"momo successful", MomoStatus("SUCCESSFUL", ""), StatusSucceeded},
+		{"momo pending", MomoStatus("PENDING", ""), StatusPending},
+		{"momo rejected", MomoStatus("FAILED", "APPROVAL_REJECTED"), StatusCancelled},
+		{"momo expired", MomoStatus("FAILED", "EXPIRED"), StatusExpired},
+		{"momo not enough funds", MomoStatus("FAILED", "NOT_ENOUGH_FUNDS"), StatusFailed},
+		{"sasapay completed", SasaPayStatus("Completed"), StatusSucceeded},
+		{"kcb timed out", KCBStatus("TIMED_OUT"), StatusExpired},
+		{"jenga reversed", JengaStatus("reversed"), StatusReversed},
+		{"jenga unmapped", JengaStatus("on hold"), StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, tt.got)
+		}
+	}
+}
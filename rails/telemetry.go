@@ -0,0 +1,100 @@
+package rails
+
+import (
+	"context"
+
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+// InstrumentedAdapter wraps an adapter so each operation gets an
+// OpenTelemetry span named after the provider API it calls, e.g.
+// "mpesa.B2CPayment" or "momo.collection.RequestToPay", and is counted and
+// timed by tel. Spans and metrics carry the provider, environment, currency,
+// normalized Status and, on failure, the error category.
+//
+// Wrapped inside an IdempotentAdapter, only requests that reach the provider
+// are recorded; wrapped around one, replays are recorded too.
+type InstrumentedAdapter struct {
+	adapter     interface{}
+	telemetry   *telemetry.Telemetry
+	environment string
+}
+
+// NewInstrumentedAdapter wraps adapter, which may implement any of
+// Collector, Disburser, Refunder and StatusChecker. environment, e.g.
+// "sandbox", is recorded with every operation. Operations adapter does not
+// implement return ErrNotSupported.
+func NewInstrumentedAdapter(adapter interface{}, tel *telemetry.Telemetry, environment string) *InstrumentedAdapter {
+	return &InstrumentedAdapter{adapter: adapter, telemetry: tel, environment: environment}
+}
+
+func (a *InstrumentedAdapter) Collect(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	c, ok := a.adapter.(Collector)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return a.do(ctx, "Collect", req.Currency, func(ctx context.Context) (*PaymentResult, error) {
+		return c.Collect(ctx, req)
+	})
+}
+
+func (a *InstrumentedAdapter) Disburse(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	d, ok := a.adapter.(Disburser)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return a.do(ctx, "Disburse", req.Currency, func(ctx context.Context) (*PaymentResult, error) {
+		return d.Disburse(ctx, req)
+	})
+}
+
+func (a *InstrumentedAdapter) Refund(ctx context.Context, req *RefundRequest) (*PaymentResult, error) {
+	r, ok := a.adapter.(Refunder)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return a.do(ctx, "Refund", req.Currency, func(ctx context.Context) (*PaymentResult, error) {
+		return r.Refund(ctx, req)
+	})
+}
+
+func (a *InstrumentedAdapter) Status(ctx context.Context, req *StatusRequest) (*PaymentResult, error) {
+	s, ok := a.adapter.(StatusChecker)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return a.do(ctx, "Status", "", func(ctx context.Context) (*PaymentResult, error) {
+		return s.Status(ctx, req)
+	})
+}
+
+// Provider returns the wrapped adapter's provider, if it has one.
+func (a *InstrumentedAdapter) Provider() Provider {
+	if p, ok := a.adapter.(interface{ Provider() Provider }); ok {
+		return p.Provider()
+	}
+	return ""
+}
+
+// do records fn as an operation named after method until the adapter names
+// the provider API it calls.
+func (a *InstrumentedAdapter) do(ctx context.Context, method, currency string, fn func(ctx context.Context) (*PaymentResult, error)) (*PaymentResult, error) {
+	if currency == "" && method != "Status" {
+		if c, ok := a.adapter.(interface{ currency() string }); ok {
+			currency = c.currency()
+		}
+	}
+
+	ctx, call := a.telemetry.Start(ctx, telemetry.Operation{
+		Provider:    string(a.Provider()),
+		Name:        method,
+		Environment: a.environment,
+		Currency:    currency,
+	})
+	result, err := fn(ctx)
+	if result != nil {
+		call.SetStatus(string(result.Status))
+	}
+	call.End(err)
+	return result, err
+}
@@ -0,0 +1,238 @@
+// Package telemetry instruments payment calls with OpenTelemetry. Each
+// logical operation, such as an M-Pesa B2C payment, gets a client span named
+// after the provider and its API, e.g. "mpesa.B2CPayment", and is counted
+// and timed:
+//
+//	tel, err := telemetry.New(nil, nil) // the global providers
+//	ctx, call := tel.Start(ctx, telemetry.Operation{Provider: "mpesa", Name: "B2CPayment"})
+//	resp, err := client.B2CPaymentWithContext(ctx, params)
+//	call.End(err)
+//
+// The HTTP requests, token fetches and retries made with ctx happen inside
+// that span, and token fetches are counted too. rails.InstrumentedAdapter
+// does this for every adapter operation.
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/transport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope of the tracer and meter.
+const ScopeName = "github.com/nutcas3/payment-rails/rails/telemetry"
+
+// Attribute keys set on spans and metrics.
+const (
+	ProviderKey      = attribute.Key("payment.provider")
+	EnvironmentKey   = attribute.Key("payment.environment")
+	OperationKey     = attribute.Key("payment.operation")
+	CurrencyKey      = attribute.Key("payment.currency")
+	StatusKey        = attribute.Key("payment.status")         // The normalized status, e.g. "pending"
+	ErrorCategoryKey = attribute.Key("payment.error.category") // A railerrors.Category
+	EventKey         = attribute.Key("payment.webhook.event")
+	OutcomeKey       = attribute.Key("payment.outcome") // "success" or "failure", for token fetches and webhooks
+)
+
+// Metric names.
+const (
+	CallsMetric          = "payment.calls"
+	FailuresMetric       = "payment.failures"
+	DurationMetric       = "payment.duration"
+	TokenRefreshesMetric = "payment.token.refreshes"
+	WebhooksMetric       = "payment.webhooks"
+)
+
+// Telemetry creates spans and records metrics for payment calls. It is safe
+// for concurrent use.
+type Telemetry struct {
+	tracer         trace.Tracer
+	calls          metric.Int64Counter
+	failures       metric.Int64Counter
+	duration       metric.Float64Histogram
+	tokenRefreshes metric.Int64Counter
+	webhooks       metric.Int64Counter
+}
+
+// New returns a Telemetry that creates spans with tp and instruments with
+// mp. A nil provider means the global one from the otel package.
+func New(tp trace.TracerProvider, mp metric.MeterProvider) (*Telemetry, error) {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	meter := mp.Meter(ScopeName)
+	t := &Telemetry{tracer: tp.Tracer(ScopeName)}
+
+	var err error
+	if t.calls, err = meter.Int64Counter(CallsMetric,
+		metric.WithDescription("Payment operations sent to providers"),
+		metric.WithUnit("{call}")); err != nil {
+		return nil, err
+	}
+	if t.failures, err = meter.Int64Counter(FailuresMetric,
+		metric.WithDescription("Payment operations that returned an error"),
+		metric.WithUnit("{call}")); err != nil {
+		return nil, err
+	}
+	if t.duration, err = meter.Float64Histogram(DurationMetric,
+		metric.WithDescription("Duration of payment operations, including retries and token fetches"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if t.tokenRefreshes, err = meter.Int64Counter(TokenRefreshesMetric,
+		metric.WithDescription("Access token fetches from provider auth endpoints"),
+		metric.WithUnit("{refresh}")); err != nil {
+		return nil, err
+	}
+	if t.webhooks, err = meter.Int64Counter(WebhooksMetric,
+		metric.WithDescription("Webhook callbacks received from providers"),
+		metric.WithUnit("{webhook}")); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Operation describes a payment call. Environment and Currency are left out
+// of the span and metrics when empty.
+type Operation struct {
+	Provider    string
+	Name        string // The provider API, e.g. "B2CPayment" or "collection.RequestToPay"
+	Environment string
+	Currency    string
+}
+
+func (op Operation) spanName() string {
+	if op.Name == "" {
+		return op.Provider
+	}
+	return op.Provider + "." + op.Name
+}
+
+func (op Operation) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{ProviderKey.String(op.Provider), OperationKey.String(op.Name)}
+	if op.Environment != "" {
+		attrs = append(attrs, EnvironmentKey.String(op.Environment))
+	}
+	if op.Currency != "" {
+		attrs = append(attrs, CurrencyKey.String(op.Currency))
+	}
+	return attrs
+}
+
+// Call is an operation in progress, ended by End.
+type Call struct {
+	t     *Telemetry
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+
+	mu     sync.Mutex
+	op     Operation
+	status string
+}
+
+type callKey struct{}
+
+// Start starts a span for op and returns a context carrying it, which the
+// call to the provider must use.
+func (t *Telemetry) Start(ctx context.Context, op Operation) (context.Context, *Call) {
+	c := &Call{t: t, op: op, start: time.Now()}
+	ctx, c.span = t.tracer.Start(ctx, op.spanName(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(op.attributes()...))
+
+	ctx = context.WithValue(ctx, callKey{}, c)
+	ctx = auth.WithRefreshHook(ctx, t.tokenRefreshed)
+	if op.Name != "" {
+		ctx = transport.WithOperation(ctx, op.Name)
+	}
+	c.ctx = ctx
+	return ctx, c
+}
+
+// Name sets the operation name of the call started with ctx, renaming its
+// span, for code that only picks the provider API once the call has
+// started. The returned context names the operation for transport
+// middleware too. Without a call in ctx, only the latter happens.
+func Name(ctx context.Context, name string) context.Context {
+	if c, ok := ctx.Value(callKey{}).(*Call); ok {
+		c.mu.Lock()
+		c.op.Name = name
+		c.span.SetName(c.op.spanName())
+		c.mu.Unlock()
+		c.span.SetAttributes(OperationKey.String(name))
+	}
+	return transport.WithOperation(ctx, name)
+}
+
+// SetStatus records the normalized status the provider returned, e.g.
+// "pending".
+func (c *Call) SetStatus(status string) {
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+}
+
+// End ends the span and records the call, as failed if err is not nil. The
+// failure is categorized by the ProviderError in err's chain, if any.
+func (c *Call) End(err error) {
+	c.mu.Lock()
+	attrs := c.op.attributes()
+	if c.status != "" {
+		attrs = append(attrs, StatusKey.String(c.status))
+	}
+	c.mu.Unlock()
+
+	if err != nil {
+		attrs = append(attrs, ErrorCategoryKey.String(string(railerrors.CategoryOf(err))))
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	}
+	c.span.SetAttributes(attrs...)
+
+	set := metric.WithAttributes(attrs...)
+	c.t.calls.Add(c.ctx, 1, set)
+	if err != nil {
+		c.t.failures.Add(c.ctx, 1, set)
+	}
+	c.t.duration.Record(c.ctx, time.Since(c.start).Seconds(), set)
+	c.span.End()
+}
+
+// Do calls fn inside a call for op, for instrumenting SDK calls made
+// directly rather than through an adapter.
+func Do[T any](ctx context.Context, t *Telemetry, op Operation, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, call := t.Start(ctx, op)
+	v, err := fn(ctx)
+	call.End(err)
+	return v, err
+}
+
+// tokenRefreshed counts a token fetch made during a call, and adds it to the
+// call's span as an event.
+func (t *Telemetry) tokenRefreshed(ctx context.Context, key string, err error) {
+	provider, _, _ := strings.Cut(key, ":")
+	attrs := []attribute.KeyValue{ProviderKey.String(provider), outcome(err)}
+	t.tokenRefreshes.Add(ctx, 1, metric.WithAttributes(attrs...))
+	trace.SpanFromContext(ctx).AddEvent("token refresh", trace.WithAttributes(attrs...))
+}
+
+func outcome(err error) attribute.KeyValue {
+	if err != nil {
+		return OutcomeKey.String("failure")
+	}
+	return OutcomeKey.String("success")
+}
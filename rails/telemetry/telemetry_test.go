@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+	"github.com/nutcas3/payment-rails/rails/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTest(t *testing.T) (*Telemetry, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	tel, err := New(
+		sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return tel, exporter, reader
+}
+
+// sums returns the total of each counter, by metric name and the value of
+// key.
+func sums(t *testing.T, reader *sdkmetric.ManualReader, key attribute.Key) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	totals := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				v, _ := dp.Attributes.Value(key)
+				totals[m.Name+" "+v.Emit()] += dp.Value
+			}
+		}
+	}
+	return totals
+}
+
+func TestDo(t *testing.T) {
+	tel, exporter, reader := newTest(t)
+
+	op := Operation{Provider: "momo", Name: "collection.RequestToPay", Environment: "sandbox", Currency: "EUR"}
+	var operation string
+	_, err := Do(context.Background(), tel, op, func(ctx context.Context) (string, error) {
+		req := httptest.NewRequest(http.MethodPost, "/collection/v1_0/requesttopay", nil).WithContext(ctx)
+		_, operation = transport.Describe(req)
+		return "", &railerrors.ProviderError{Provider: "momo", StatusCode: 409, Category: railerrors.CategoryDuplicate}
+	})
+	if err == nil {
+		t.Fatal("Expected the error from fn")
+	}
+	if operation != "collection.RequestToPay" {
+		t.Errorf("Expected transport operation 'collection.RequestToPay', got '%s'", operation)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "momo.collection.RequestToPay" || span.Status.Code != codes.Error {
+		t.Errorf("Unexpected span %s with status %v", span.Name, span.Status)
+	}
+	attrs := attribute.NewSet(span.Attributes...)
+	for key, want := range map[attribute.Key]string{ProviderKey: "momo", EnvironmentKey: "sandbox", CurrencyKey: "EUR", ErrorCategoryKey: "duplicate"} {
+		if v, _ := attrs.Value(key); v.Emit() != want {
+			t.Errorf("Expected %s '%s', got '%s'", key, want, v.Emit())
+		}
+	}
+
+	totals := sums(t, reader, ErrorCategoryKey)
+	if totals[CallsMetric+" duplicate"] != 1 || totals[FailuresMetric+" duplicate"] != 1 {
+		t.Errorf("Unexpected metrics %v", totals)
+	}
+}
+
+func TestName(t *testing.T) {
+	tel, exporter, reader := newTest(t)
+
+	ctx, call := tel.Start(context.Background(), Operation{Provider: "jenga", Name: "Disburse"})
+	Name(ctx, "SendToMobileWallet")
+	call.SetStatus("pending")
+	call.End(nil)
+
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Name != "jenga.SendToMobileWallet" {
+		t.Errorf("Expected span 'jenga.SendToMobileWallet', got %v", spans)
+	}
+	if totals := sums(t, reader, OperationKey); totals[CallsMetric+" SendToMobileWallet"] != 1 {
+		t.Errorf("Expected the call counted under its new name, got %v", totals)
+	}
+
+	// Without a call, Name only names the operation for middleware
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(Name(context.Background(), "VoomaPay"))
+	if _, operation := transport.Describe(req); operation != "VoomaPay" {
+		t.Errorf("Expected operation 'VoomaPay', got '%s'", operation)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	tel, exporter, reader := newTest(t)
+
+	handler := tel.Webhook("mpesa", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("bad") != "" {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/callback", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/callback?bad=1", nil))
+	tel.WebhookReceived(context.Background(), "mpesa", "stk_callback", errors.New("malformed body"))
+
+	if spans := exporter.GetSpans(); len(spans) != 2 || spans[0].Name != "mpesa.webhook" || spans[1].Status.Code != codes.Error {
+		t.Errorf("Unexpected webhook spans %v", spans)
+	}
+	totals := sums(t, reader, OutcomeKey)
+	if totals[WebhooksMetric+" success"] != 1 || totals[WebhooksMetric+" failure"] != 2 {
+		t.Errorf("Unexpected webhook metrics %v", totals)
+	}
+}
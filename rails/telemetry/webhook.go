@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebhookReceived counts a callback received from provider. event is the
+// kind of callback, e.g. "stk_callback", and is left out when empty. A
+// non-nil err, such as a bad signature or body, counts it as failed.
+func (t *Telemetry) WebhookReceived(ctx context.Context, provider, event string, err error) {
+	attrs := []attribute.KeyValue{ProviderKey.String(provider), outcome(err)}
+	if event != "" {
+		attrs = append(attrs, EventKey.String(event))
+	}
+	t.webhooks.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// Webhook returns handler wrapped to record each request as a server span,
+// named e.g. "mpesa.webhook", and with WebhookReceived. A request the
+// handler answers with a 4xx or 5xx status counts as failed.
+func (t *Telemetry) Webhook(provider string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := t.tracer.Start(r.Context(), provider+".webhook",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(ProviderKey.String(provider)))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(rec, r.WithContext(ctx))
+
+		var err error
+		if rec.status >= http.StatusBadRequest {
+			err = fmt.Errorf("webhook handler responded %d", rec.status)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		t.WebhookReceived(ctx, provider, "", err)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
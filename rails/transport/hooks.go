@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"net/http"
+	"time"
+)
+
+// Event describes one request to a provider. Each retry is a separate
+// Event.
+type Event struct {
+	Provider  string
+	Operation string
+	Request   *http.Request
+
+	// Response, Err and Latency are set after the request completes.
+	// Response is nil if Err is set.
+	Response *http.Response
+	Err      error
+	Latency  time.Duration
+}
+
+// StatusCode returns the response status, or 0 if there is no response.
+func (e Event) StatusCode() int {
+	if e.Response == nil {
+		return 0
+	}
+	return e.Response.StatusCode
+}
+
+// Hooks are called around each request. Either may be nil.
+type Hooks struct {
+	// Request is called before the request is sent.
+	Request func(Event)
+
+	// Response is called once the response headers arrive or the request
+	// fails. The response body must not be read.
+	Response func(Event)
+}
+
+// Observe returns middleware that calls hooks for every request.
+func Observe(hooks Hooks) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			provider, operation := Describe(req)
+			event := Event{Provider: provider, Operation: operation, Request: req}
+			if hooks.Request != nil {
+				hooks.Request(event)
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			if hooks.Response != nil {
+				event.Response, event.Err, event.Latency = resp, err, time.Since(start)
+				hooks.Response(event)
+			}
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxLoggedBody is how much of a request or response body Log includes.
+// Bodies are redacted whole before they are cut, so Log reads up to
+// maxRedactedBody of each, and leaves out bodies larger than that: a JSON
+// body cut short no longer parses, and its secret fields would be logged as
+// plain text.
+const (
+	maxLoggedBody   = 4 << 10
+	maxRedactedBody = 64 << 10
+)
+
+// truncatedBody is logged in place of a body larger than maxRedactedBody.
+const truncatedBody = "[truncated: body over 64 KiB]"
+
+// Logger receives log entries with a level of DEBUG, INFO, WARN or ERROR.
+// It has the same method as standardbank's api.Logger, so either can be used
+// where the other is expected.
+type Logger interface {
+	Log(level, message string, fields map[string]interface{})
+}
+
+// LoggerFunc is a function used as a Logger.
+type LoggerFunc func(level, message string, fields map[string]interface{})
+
+func (f LoggerFunc) Log(level, message string, fields map[string]interface{}) {
+	f(level, message, fields)
+}
+
+// Redacting returns a Logger that redacts the message and fields, as
+// RedactString and RedactFields do, before passing them to logger.
+func Redacting(logger Logger) Logger {
+	return LoggerFunc(func(level, message string, fields map[string]interface{}) {
+		logger.Log(level, RedactString(message), RedactFields(fields))
+	})
+}
+
+// Log returns middleware that logs each request with its provider,
+// operation, status and latency: at DEBUG when it succeeds, WARN for an error
+// status and ERROR when no response arrives. With bodies set, the request and
+// response bodies are logged too: redacted by RedactBody, then cut to 4 KiB.
+// Bodies over 64 KiB are left out. All fields go through Redacting.
+func Log(logger Logger, bodies bool) Middleware {
+	logger = Redacting(logger)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			provider, operation := Describe(req)
+			fields := map[string]interface{}{
+				"provider":  provider,
+				"operation": operation,
+				"method":    req.Method,
+				"url":       req.URL.Redacted(),
+			}
+			if bodies && req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					fields["request_body"] = loggedBody(body)
+					body.Close()
+				}
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			fields["latency"] = time.Since(start).String()
+
+			if err != nil {
+				fields["error"] = err.Error()
+				logger.Log("ERROR", "Provider request failed", fields)
+				return resp, err
+			}
+
+			fields["status"] = resp.StatusCode
+			if bodies && resp.Body != nil {
+				// Only read what is logged, and put it back in front of
+				// the rest of the stream
+				data, readErr := io.ReadAll(io.LimitReader(resp.Body, maxRedactedBody+1))
+				rest := resp.Body
+				if readErr != nil {
+					// Let the client see the same failure
+					rest = readCloser{errReader{readErr}, resp.Body}
+				}
+				resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), rest), resp.Body}
+				fields["response_body"] = loggedBody(bytes.NewReader(data))
+			}
+
+			level := "DEBUG"
+			if resp.StatusCode >= http.StatusBadRequest {
+				level = "WARN"
+			}
+			logger.Log(level, "Provider request completed", fields)
+			return resp, nil
+		})
+	}
+}
+
+func loggedBody(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, maxRedactedBody+1))
+	if len(data) > maxRedactedBody {
+		return truncatedBody
+	}
+	logged := RedactBody(data)
+	if len(logged) > maxLoggedBody {
+		return logged[:maxLoggedBody] + "..."
+	}
+	return logged
+}
+
+// readCloser reads from Reader and closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secrets in logged values.
+const Redacted = "[REDACTED]"
+
+// secretKeys are field names, lower case without '_' or '-', whose values are
+// replaced with Redacted wherever they appear in a name. shortSecretKeys only
+// match at the end of a name, so "pin" catches "userPin" but not "shipping".
+var (
+	secretKeys = []string{
+		"password", "credential", "secret", "token",
+		"authorization", "apikey", "passkey", "subscriptionkey", "privatekey",
+		"signature", "cookie",
+	}
+	shortSecretKeys = []string{"pin", "otp", "cvv"}
+)
+
+// personalKeys are field names whose values are masked down to their last
+// four digits, e.g. "********4149", when they contain at least six digits.
+var personalKeys = []string{"msisdn", "phone", "mobile", "accountnumber", "accountno", "partyid", "iban", "cardnumber"}
+
+var (
+	credentialPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	msisdnPattern     = regexp.MustCompile(`\+?\b(?:2\d{10,11}|0\d{9})\b`)
+)
+
+// RedactFields returns a copy of fields for logging. Values of secret fields,
+// such as Password, SecurityCredential, PINs and tokens, are replaced with
+// Redacted, and MSISDNs and account numbers are masked to their last four
+// digits. Nested maps and slices are redacted too.
+func RedactFields(fields map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		redacted[k] = redactValue(k, v)
+	}
+	return redacted
+}
+
+// RedactString masks bearer and basic credentials and MSISDNs in free text,
+// such as a log message or a body that is not JSON.
+func RedactString(s string) string {
+	s = credentialPattern.ReplaceAllString(s, "$1 "+Redacted)
+	return msisdnPattern.ReplaceAllStringFunc(s, maskDigits)
+}
+
+// RedactBody redacts a JSON or form-encoded request or response body as
+// RedactFields does, and any other body as RedactString does.
+func RedactBody(body []byte) string {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		data, _ := json.Marshal(redactValue("", v))
+		return string(data)
+	}
+
+	s := string(body)
+	if values, err := url.ParseQuery(s); err == nil && strings.Contains(s, "=") && !strings.ContainsAny(s, " \n{") {
+		for k, vs := range values {
+			for i := range vs {
+				vs[i] = fmt.Sprint(redactValue(k, vs[i]))
+			}
+		}
+		return values.Encode()
+	}
+	return RedactString(s)
+}
+
+func redactValue(key string, v interface{}) interface{} {
+	switch kind(key) {
+	case keySecret:
+		if v == nil || v == "" {
+			return v
+		}
+		return Redacted
+	case keyPersonal:
+		if s := fmt.Sprint(v); digits(s) >= 6 {
+			return maskDigits(s)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return RedactFields(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, e := range v {
+			redacted[i] = redactValue(key, e)
+		}
+		return redacted
+	case string:
+		return RedactString(v)
+	case json.Number, float64, int64, int:
+		s := fmt.Sprint(v)
+		if msisdnPattern.MatchString(s) {
+			return RedactString(s)
+		}
+	}
+	return v
+}
+
+type keyKind int
+
+const (
+	keyPlain keyKind = iota
+	keySecret
+	keyPersonal
+)
+
+func kind(key string) keyKind {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	if key == "" {
+		return keyPlain
+	}
+	for _, k := range secretKeys {
+		if strings.Contains(key, k) {
+			return keySecret
+		}
+	}
+	for _, k := range shortSecretKeys {
+		if strings.HasSuffix(key, k) {
+			return keySecret
+		}
+	}
+	for _, k := range personalKeys {
+		if strings.Contains(key, k) {
+			return keyPersonal
+		}
+	}
+	if strings.HasSuffix(key, "account") {
+		return keyPersonal
+	}
+	return keyPlain
+}
+
+func digits(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// maskDigits replaces every digit but the last four with '*'.
+func maskDigits(s string) string {
+	keep := 4
+	b := []byte(s)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '0' || b[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		b[i] = '*'
+	}
+	return string(b)
+}
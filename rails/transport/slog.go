@@ -0,0 +1,36 @@
+package transport
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Slog returns a Logger that writes to logger, with DEBUG, INFO, WARN and
+// ERROR mapped to the slog levels of the same name and fields as attributes
+// in name order.
+func Slog(logger *slog.Logger) Logger {
+	return LoggerFunc(func(level, message string, fields map[string]interface{}) {
+		attrs := make([]slog.Attr, 0, len(fields))
+		for k, v := range fields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+		slices.SortFunc(attrs, func(a, b slog.Attr) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+		logger.LogAttrs(context.Background(), slogLevel(level), message, attrs...)
+	})
+}
+
+func slogLevel(level string) slog.Level {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return slog.LevelDebug
+	case "WARN", "WARNING":
+		return slog.LevelWarn
+	case "ERROR":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
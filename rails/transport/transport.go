@@ -0,0 +1,102 @@
+// Package transport runs every HTTP request a provider client makes,
+// including token fetches and retries, through a chain of middleware, so
+// logging, tracing and metrics can be added in one place:
+//
+//	client.Use(
+//		transport.Log(transport.Slog(slog.Default()), true),
+//		transport.Observe(transport.Hooks{Response: recordLatency}),
+//	)
+//
+// Middleware sees the provider's name and the operation for each request,
+// see Describe. Log redacts secrets and personal data before they reach the
+// logger, see RedactFields.
+package transport
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// Middleware wraps a RoundTripper with behaviour of its own, such as
+// logging, and calls next to send the request.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc is a function used as an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Transport sends requests through middleware to a base RoundTripper,
+// tagging each with the provider's name.
+type Transport struct {
+	provider   string
+	base       http.RoundTripper
+	middleware []Middleware
+	chain      http.RoundTripper
+}
+
+// New returns a Transport for provider. The first middleware is the
+// outermost, seeing each request first and each response last. A nil base
+// means http.DefaultTransport.
+func New(provider string, base http.RoundTripper, middleware ...Middleware) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	chain := base
+	for i := len(middleware) - 1; i >= 0; i-- {
+		chain = middleware[i](chain)
+	}
+	return &Transport{provider: provider, base: base, middleware: middleware, chain: chain}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.chain.RoundTrip(req.WithContext(context.WithValue(req.Context(), providerKey{}, t.provider)))
+}
+
+// Wrap returns a copy of client whose transport runs middleware, after any
+// that client already runs for provider. client itself is not modified, so
+// an *http.Client shared with other code keeps its behaviour. A nil client
+// means a zero http.Client.
+func Wrap(client *http.Client, provider string, middleware ...Middleware) *http.Client {
+	if len(middleware) == 0 && client != nil {
+		return client
+	}
+
+	var wrapped http.Client
+	if client != nil {
+		wrapped = *client
+	}
+
+	base := wrapped.Transport
+	if t, ok := base.(*Transport); ok && t.provider == provider {
+		middleware = append(slices.Clip(t.middleware), middleware...)
+		base = t.base
+	}
+	wrapped.Transport = New(provider, base, middleware...)
+	return &wrapped
+}
+
+type providerKey struct{}
+
+type operationKey struct{}
+
+// WithOperation names the operation for requests made with ctx, e.g.
+// "B2CPayment", for Describe.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// Describe returns the provider that sent req and the operation it is part
+// of: the name given to WithOperation or, without one, the method and path,
+// e.g. "POST /mpesa/b2c/v1/paymentrequest".
+func Describe(req *http.Request) (provider, operation string) {
+	provider, _ = req.Context().Value(providerKey{}).(string)
+	operation, _ = req.Context().Value(operationKey{}).(string)
+	if operation == "" {
+		operation = req.Method + " " + req.URL.Path
+	}
+	return provider, operation
+}
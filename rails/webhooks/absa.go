@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/absa/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// Absa serves Absa webhooks at "callback".
+type Absa struct {
+	// Secret is the webhook secret the X-Absa-Signature header is keyed
+	// with. Webhooks are refused without it.
+	Secret string
+}
+
+// AbsaEvent is an Absa webhook. The field matching its type is set, and
+// none for types without one.
+type AbsaEvent struct {
+	Meta
+	PaymentSuccess    *api.PaymentSuccessWebhook
+	PaymentFailure    *api.PaymentFailureWebhook
+	TransactionStatus *api.TransactionStatusWebhook
+	AccountUpdate     *api.AccountUpdateWebhook
+}
+
+func (Absa) provider() rails.Provider {
+	return rails.ProviderAbsa
+}
+
+func (a Absa) verify(r *http.Request, body []byte) error {
+	return verifySignature(r, body, api.WebhookSignatureHeader, a.Secret)
+}
+
+func (Absa) routes() []route {
+	return []route{{path: "callback", signed: true, decode: decodeAbsa}}
+}
+
+func decodeAbsa(body []byte) (Event, error) {
+	var webhook api.WebhookEvent
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("error parsing webhook event: %w", err)
+	}
+
+	event := &AbsaEvent{Meta: Meta{Type: webhook.EventType, ID: webhook.ID(), SentAt: webhook.Time()}}
+	var err error
+	switch webhook.EventType {
+	case "payment.success":
+		event.PaymentSuccess = &api.PaymentSuccessWebhook{}
+		if err = json.Unmarshal(body, event.PaymentSuccess); err == nil {
+			p := event.PaymentSuccess
+			event.TransactionID, event.Reference = p.TransactionID, p.Reference
+			event.Status = rails.StatusSucceeded
+			event.Amount = parseAmount(p.Amount, p.Currency)
+		}
+	case "payment.failure":
+		event.PaymentFailure = &api.PaymentFailureWebhook{}
+		if err = json.Unmarshal(body, event.PaymentFailure); err == nil {
+			p := event.PaymentFailure
+			event.TransactionID, event.Reference = p.TransactionID, p.Reference
+			event.Status = rails.StatusFailed
+			event.Amount = parseAmount(p.Amount, p.Currency)
+		}
+	case "transaction.status":
+		event.TransactionStatus = &api.TransactionStatusWebhook{}
+		if err = json.Unmarshal(body, event.TransactionStatus); err == nil {
+			t := event.TransactionStatus
+			event.TransactionID, event.Reference = t.TransactionID, t.Reference
+			event.Status = rails.ParseStatus(t.Status)
+		}
+	case "account.update":
+		event.AccountUpdate = &api.AccountUpdateWebhook{}
+		err = json.Unmarshal(body, event.AccountUpdate)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing webhook data: %w", err)
+	}
+	return event, nil
+}
@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/airtel/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// Airtel serves the Airtel Money callback URL configured for the
+// application, at "callback".
+type Airtel struct {
+	// Secret is the callback authentication secret. If set, every callback
+	// must carry a valid hash, see api.Callback.Verify. Airtel only sends
+	// one when callback authentication is enabled for the application.
+	Secret string
+}
+
+// AirtelEvent is a completed collection or disbursement. Airtel callbacks
+// carry no amount.
+type AirtelEvent struct {
+	Meta
+	Callback *api.Callback
+}
+
+func (Airtel) provider() rails.Provider {
+	return rails.ProviderAirtel
+}
+
+func (a Airtel) verify(r *http.Request, body []byte) error {
+	if a.Secret == "" {
+		return nil
+	}
+	cb, err := api.ParseCallback(body)
+	if err != nil || !cb.Verify(a.Secret) {
+		return ErrSignature
+	}
+	return nil
+}
+
+func (Airtel) routes() []route {
+	return []route{{path: "callback", decode: decodeAirtel}}
+}
+
+func decodeAirtel(body []byte) (Event, error) {
+	cb, err := api.ParseCallback(body)
+	if err != nil {
+		return nil, err
+	}
+	return &AirtelEvent{
+		Meta: Meta{
+			Type:          "callback",
+			TransactionID: cb.Transaction.AirtelMoneyID,
+			Reference:     cb.Transaction.ID,
+			Status:        rails.AirtelStatus(cb.Transaction.StatusCode),
+		},
+		Callback: cb,
+	}, nil
+}
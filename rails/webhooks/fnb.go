@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/fnb/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// FNB serves FNB webhooks at "callback".
+type FNB struct {
+	// Secret is the webhook secret the X-FNB-Signature header is keyed
+	// with. Webhooks are refused without it.
+	Secret string
+}
+
+// FNBEvent is an FNB webhook.
+type FNBEvent struct {
+	Meta
+	Event *api.WebhookEvent
+}
+
+func (FNB) provider() rails.Provider {
+	return rails.ProviderFNB
+}
+
+func (f FNB) verify(r *http.Request, body []byte) error {
+	return verifySignature(r, body, api.WebhookSignatureHeader, f.Secret)
+}
+
+func (FNB) routes() []route {
+	return []route{{path: "callback", signed: true, decode: decodeFNB}}
+}
+
+func decodeFNB(body []byte) (Event, error) {
+	webhook, err := api.ParseWebhookEvent(body)
+	if err != nil {
+		return nil, err
+	}
+	reference, _ := webhook.Data["reference"].(string)
+	return &FNBEvent{
+		Meta: Meta{
+			Type:          webhook.EventType,
+			TransactionID: webhook.ResourceID,
+			Reference:     reference,
+			Status:        eventStatus(webhook.EventType),
+			ID:            webhook.EventID,
+			SentAt:        webhook.Timestamp,
+		},
+		Event: webhook,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/jenga/pkg/api"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// Jenga serves Jenga webhooks at "callback".
+type Jenga struct {
+	// Secret is the webhook secret the X-Jenga-Signature header is keyed
+	// with. Webhooks are refused without it.
+	Secret string
+}
+
+// JengaEvent is a Jenga webhook. Transaction is set for transaction events.
+type JengaEvent struct {
+	Meta
+	Event       *api.WebhookEvent
+	Transaction *api.TransactionWebhookData
+}
+
+func (Jenga) provider() rails.Provider {
+	return rails.ProviderJenga
+}
+
+func (j Jenga) verify(r *http.Request, body []byte) error {
+	return verifySignature(r, body, api.WebhookSignatureHeader, j.Secret)
+}
+
+func (Jenga) routes() []route {
+	return []route{{path: "callback", signed: true, decode: decodeJenga}}
+}
+
+func decodeJenga(body []byte) (Event, error) {
+	var webhook api.WebhookEvent
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("error parsing webhook event: %w", err)
+	}
+
+	event := &JengaEvent{
+		Meta: Meta{
+			Type:          string(webhook.EventType),
+			TransactionID: webhook.TransactionID,
+			Reference:     webhook.Reference,
+			ID:            webhook.ID,
+			SentAt:        webhook.CreatedAt,
+		},
+		Event: &webhook,
+	}
+	switch webhook.EventType {
+	case api.WebhookEventTypeTransactionSuccess, api.WebhookEventTypeTransactionFailed:
+		var data api.TransactionWebhookData
+		if err := json.Unmarshal(webhook.Data, &data); err != nil {
+			return nil, fmt.Errorf("error parsing transaction data: %w", err)
+		}
+		event.Transaction = &data
+		event.TransactionID = firstNonEmpty(event.TransactionID, data.TransactionID)
+		event.Reference = firstNonEmpty(event.Reference, data.Reference)
+		event.Amount = parseAmount(data.Amount, data.Currency)
+		event.Status = rails.StatusSucceeded
+		if webhook.EventType == api.WebhookEventTypeTransactionFailed {
+			event.Status = rails.StatusFailed
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/momo/common/types"
+	"github.com/nutcas3/payment-rails/rails"
+)
+
+// Momo serves the MTN MoMo callback URLs passed with requests:
+//
+//	collection    Request to Pay
+//	disbursement  Transfer
+//
+// MoMo sends callbacks with PUT or POST.
+type Momo struct {
+	// Token, if set, must be the token query parameter of every callback
+	// URL, since MoMo does not sign callbacks.
+	Token string
+}
+
+// MomoCollectionEvent is the final status of a Request to Pay.
+type MomoCollectionEvent struct {
+	Meta
+	Callback *types.RequestToPayStatus
+}
+
+// MomoDisbursementEvent is the final status of a transfer.
+type MomoDisbursementEvent struct {
+	Meta
+	Callback *types.TransferStatus
+}
+
+func (Momo) provider() rails.Provider {
+	return rails.ProviderMomo
+}
+
+func (m Momo) verify(r *http.Request, body []byte) error {
+	return verifyToken(r, m.Token)
+}
+
+func (Momo) routes() []route {
+	methods := []string{http.MethodPut, http.MethodPost}
+	return []route{
+		{path: "collection", methods: methods, decode: decodeMomoCollection},
+		{path: "disbursement", methods: methods, decode: decodeMomoDisbursement},
+	}
+}
+
+func decodeMomoCollection(body []byte) (Event, error) {
+	var status types.RequestToPayStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse MoMo callback: %w", err)
+	}
+	return &MomoCollectionEvent{
+		Meta:     momoMeta("collection", status.FinancialTransactionID, status.ExternalID, status.Status, status.Reason, status.Amount, string(status.Currency)),
+		Callback: &status,
+	}, nil
+}
+
+func decodeMomoDisbursement(body []byte) (Event, error) {
+	var status types.TransferStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse MoMo callback: %w", err)
+	}
+	return &MomoDisbursementEvent{
+		Meta:     momoMeta("disbursement", status.FinancialTransactionID, status.ExternalID, status.Status, status.Reason, status.Amount, string(status.Currency)),
+		Callback: &status,
+	}, nil
+}
+
+// momoMeta fills Meta from a callback. MoMo callbacks do not include the
+// X-Reference-Id status requests take, so TransactionID is the financial
+// transaction ID.
+func momoMeta(eventType, transactionID, externalID, status string, reason types.ErrorReason, amount, currency string) Meta {
+	return Meta{
+		Type:          eventType,
+		TransactionID: transactionID,
+		Reference:     externalID,
+		Status:        rails.MomoStatus(status, reason.Code),
+		Amount:        parseAmount(amount, currency),
+	}
+}
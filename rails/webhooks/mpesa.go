@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+)
+
+// Mpesa serves the Daraja callbacks:
+//
+//	stk               STK Push CallBackURL
+//	result            ResultURL of B2C, B2B, reversal, transaction status and balance requests
+//	timeout           QueueTimeOutURL of the same requests
+//	c2b/confirmation  C2B ConfirmationURL
+//	c2b/validation    C2B ValidationURL, only when Validate is set
+type Mpesa struct {
+	// Token, if set, must be the token query parameter of every callback
+	// URL, e.g. "https://example.com/mpesa/acme/stk?token=...", since
+	// Safaricom does not sign callbacks.
+	Token string
+
+	// Validate answers C2B validation requests, see daraja.C2BHandler. They
+	// are not passed to the Handler.
+	Validate daraja.C2BValidationFunc
+}
+
+// MpesaSTKEvent is an STK Push result.
+type MpesaSTKEvent struct {
+	Meta
+	Callback *daraja.STKCallback
+}
+
+// MpesaResultEvent is the result of an asynchronous request, or its timeout
+// in the queue.
+type MpesaResultEvent struct {
+	Meta
+	Result  *daraja.Result
+	Timeout bool
+}
+
+// MpesaC2BEvent is a completed C2B payment.
+type MpesaC2BEvent struct {
+	Meta
+	Confirmation *daraja.C2BConfirmation
+}
+
+func (Mpesa) provider() rails.Provider {
+	return rails.ProviderMpesa
+}
+
+func (m Mpesa) verify(r *http.Request, body []byte) error {
+	return verifyToken(r, m.Token)
+}
+
+func (m Mpesa) routes() []route {
+	routes := []route{
+		{path: "stk", decode: decodeMpesaSTK, ack: mpesaAck},
+		{path: "result", decode: decodeMpesaResult(false), ack: mpesaAck},
+		{path: "timeout", decode: decodeMpesaResult(true), ack: mpesaAck},
+		{path: "c2b/confirmation", decode: decodeMpesaC2B, ack: mpesaAck},
+	}
+	if m.Validate != nil {
+		routes = append(routes, route{
+			path:    "c2b/validation",
+			handler: daraja.NewC2BHandler(m.Validate, nil).ValidationHandler(),
+			ack:     mpesaAck,
+		})
+	}
+	return routes
+}
+
+func decodeMpesaSTK(body []byte) (Event, error) {
+	cb, err := daraja.ParseSTKCallback(body)
+	if err != nil {
+		return nil, err
+	}
+	status := rails.MpesaSTKStatus(strconv.Itoa(cb.ResultCode))
+	if status == rails.StatusUnknown || status == rails.StatusPending {
+		// The callback is the final result, so any code but success is a failure
+		status = rails.StatusFailed
+	}
+	return &MpesaSTKEvent{
+		Meta: Meta{
+			Type:          "stk_callback",
+			TransactionID: cb.CheckoutRequestID,
+			Status:        status,
+			Amount:        money.New(cb.Amount, money.KES),
+		},
+		Callback: cb,
+	}, nil
+}
+
+func decodeMpesaResult(timeout bool) func([]byte) (Event, error) {
+	return func(body []byte) (Event, error) {
+		result, err := daraja.ParseResult(body)
+		if err != nil {
+			return nil, err
+		}
+
+		event := &MpesaResultEvent{
+			Meta: Meta{
+				Type:          "result",
+				TransactionID: result.OriginatorConversationID,
+				Status:        rails.MpesaResultStatus(result),
+			},
+			Result:  result,
+			Timeout: timeout,
+		}
+		if timeout {
+			event.Type = "timeout"
+		}
+		for _, key := range []string{"TransactionAmount", "Amount"} {
+			if amount, ok := result.Parameter(key); ok {
+				event.Amount = parseAmount(amount, string(money.KES))
+				break
+			}
+		}
+		return event, nil
+	}
+}
+
+func decodeMpesaC2B(body []byte) (Event, error) {
+	var confirmation daraja.C2BConfirmation
+	if err := json.Unmarshal(body, &confirmation); err != nil {
+		return nil, fmt.Errorf("failed to parse C2B confirmation: %w", err)
+	}
+	if confirmation.TransID == "" {
+		return nil, fmt.Errorf("C2B confirmation is missing TransID")
+	}
+	return &MpesaC2BEvent{
+		Meta: Meta{
+			Type:          "c2b_confirmation",
+			TransactionID: confirmation.TransID,
+			Reference:     confirmation.BillRefNumber,
+			Status:        rails.StatusSucceeded,
+			Amount:        parseAmount(confirmation.TransAmount, string(money.KES)),
+		},
+		Confirmation: &confirmation,
+	}, nil
+}
+
+// mpesaAck replies in the shape Safaricom expects, with ResultCode 0 once
+// the callback is handled.
+func mpesaAck(w http.ResponseWriter, status int, err error) {
+	ack := daraja.CallbackAck{ResultCode: 0, ResultDesc: "Accepted"}
+	if err != nil {
+		ack = daraja.CallbackAck{ResultCode: 1, ResultDesc: "Rejected"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ack)
+}
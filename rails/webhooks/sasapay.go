@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/sasapay/pkg/api"
+)
+
+// SasaPay serves SasaPay webhooks at "callback".
+type SasaPay struct {
+	// Secret is the webhook secret the X-SasaPay-Signature header is keyed
+	// with. Webhooks are refused without it.
+	Secret string
+}
+
+// SasaPayEvent is a SasaPay webhook.
+type SasaPayEvent struct {
+	Meta
+	Event *api.WebhookEvent
+}
+
+func (SasaPay) provider() rails.Provider {
+	return rails.ProviderSasaPay
+}
+
+func (s SasaPay) verify(r *http.Request, body []byte) error {
+	return verifySignature(r, body, api.WebhookSignatureHeader, s.Secret)
+}
+
+func (SasaPay) routes() []route {
+	return []route{{path: "callback", signed: true, decode: decodeSasaPay}}
+}
+
+func decodeSasaPay(body []byte) (Event, error) {
+	var webhook api.WebhookEvent
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("error unmarshalling webhook payload: %w", err)
+	}
+
+	event := &SasaPayEvent{
+		Meta: Meta{
+			Type:          webhook.EventType,
+			TransactionID: webhook.TransactionID,
+			Reference:     webhook.Reference,
+			ID:            webhook.ID(),
+			SentAt:        webhook.Timestamp,
+		},
+		Event: &webhook,
+	}
+	if strings.HasPrefix(webhook.EventType, "payment.") {
+		event.Status = rails.SasaPayStatus(webhook.Status)
+		if event.Status == rails.StatusUnknown {
+			event.Status = eventStatus(webhook.EventType)
+		}
+	}
+	if webhook.Currency != "" {
+		event.Amount = money.New(webhook.Amount, money.Currency(webhook.Currency))
+	}
+	return event, nil
+}
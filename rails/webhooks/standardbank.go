@@ -0,0 +1,52 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/standardbank/pkg/api"
+)
+
+// StandardBank serves Standard Bank webhooks at "callback".
+type StandardBank struct {
+	// Secret is the webhook secret the X-StandardBank-Signature header is
+	// keyed with. Webhooks are refused without it.
+	Secret string
+}
+
+// StandardBankEvent is a Standard Bank webhook.
+type StandardBankEvent struct {
+	Meta
+	Event *api.WebhookEvent
+}
+
+func (StandardBank) provider() rails.Provider {
+	return rails.ProviderStandardBank
+}
+
+func (s StandardBank) verify(r *http.Request, body []byte) error {
+	return verifySignature(r, body, api.WebhookSignatureHeader, s.Secret)
+}
+
+func (StandardBank) routes() []route {
+	return []route{{path: "callback", signed: true, decode: decodeStandardBank}}
+}
+
+func decodeStandardBank(body []byte) (Event, error) {
+	webhook, err := api.ParseWebhookEvent(body)
+	if err != nil {
+		return nil, err
+	}
+	reference, _ := webhook.Data["reference"].(string)
+	return &StandardBankEvent{
+		Meta: Meta{
+			Type:          webhook.EventType,
+			TransactionID: webhook.ResourceID,
+			Reference:     reference,
+			Status:        eventStatus(webhook.EventType),
+			ID:            webhook.EventID,
+			SentAt:        webhook.Timestamp.Time,
+		},
+		Event: webhook,
+	}, nil
+}
@@ -0,0 +1,389 @@
+// Package webhooks serves the callbacks of every provider from one
+// http.Handler. Each request is verified with the provider's scheme,
+// decoded into a typed Event and passed to a single Handler:
+//
+//	gateway := webhooks.New(func(ctx context.Context, event webhooks.Event) error {
+//		meta := event.Metadata()
+//		return payments.Update(ctx, meta.Provider, meta.TransactionID, meta.Status)
+//	})
+//	gateway.Mount("acme", webhooks.Mpesa{Token: mpesaToken}, webhooks.Jenga{Secret: jengaSecret})
+//	http.Handle("/webhooks/", http.StripPrefix("/webhooks", gateway))
+//
+// Routes are namespaced by provider and tenant, e.g. "/mpesa/acme/stk" or
+// "/jenga/acme/callback", so one server can take callbacks for several
+// merchants or credential sets.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/inbox"
+	"github.com/nutcas3/payment-rails/rails/money"
+	"github.com/nutcas3/payment-rails/rails/signature"
+	"github.com/nutcas3/payment-rails/rails/telemetry"
+)
+
+// ErrSignature is returned for callbacks that fail verification. They are
+// answered with 401 and never reach the Handler.
+var ErrSignature = errors.New("webhooks: invalid signature")
+
+// MaxBodySize is the largest callback body read. Larger requests are
+// answered with 400.
+const MaxBodySize = 1 << 20
+
+// Event is a verified and decoded callback. Switch on its concrete type,
+// e.g. *MpesaSTKEvent, for the provider's own fields.
+type Event interface {
+	Metadata() *Meta
+}
+
+// Meta holds the fields every Event has.
+type Meta struct {
+	Provider      rails.Provider
+	Tenant        string
+	Type          string       // e.g. "stk_callback", or the provider's own event type
+	TransactionID string       // The provider's ID for the transaction
+	Reference     string       // The merchant's reference, if the callback echoes it
+	Status        rails.Status // Empty for events that are not about a payment
+	Amount        money.Money  // Zero if the callback has no amount
+	ID            string       // Identifies the callback for replay checks, empty if it cannot be
+	SentAt        time.Time    // When the provider sent the callback, zero if it does not say
+	ReceivedAt    time.Time
+	Body          []byte // The request body as received
+}
+
+// Metadata returns m, so types embedding Meta implement Event.
+func (m *Meta) Metadata() *Meta {
+	return m
+}
+
+// Handler is called once for each callback. An error answers the callback
+// with 500, so providers that retry callbacks send it again.
+type Handler func(ctx context.Context, event Event) error
+
+// Source is a provider's callbacks, with the secrets to verify them, as
+// passed to Gateway.Mount.
+type Source interface {
+	provider() rails.Provider
+	verify(r *http.Request, body []byte) error
+	routes() []route
+}
+
+type route struct {
+	path    string
+	methods []string // POST when empty
+	decode  func(body []byte) (Event, error)
+
+	// handler, if set, answers verified requests itself instead of decode,
+	// for callbacks that need a reply, such as M-Pesa C2B validation.
+	handler http.Handler
+
+	// ack writes the reply, with http.Error by default.
+	ack func(w http.ResponseWriter, status int, err error)
+
+	// signed marks callbacks whose signature covers Meta.ID and
+	// Meta.SentAt, so they are checked against the Gateway's verifier.
+	signed bool
+}
+
+// Gateway is an http.Handler serving the callbacks of mounted Sources.
+type Gateway struct {
+	handler   Handler
+	verifier  *signature.Verifier
+	inbox     *inbox.Inbox
+	telemetry *telemetry.Telemetry
+	mux       *http.ServeMux
+	mounted   map[string]mounted
+	paths     []string
+}
+
+type mounted struct {
+	source Source
+	tenant string
+	rt     route
+}
+
+// New returns a Gateway passing every event to handler. Signed callbacks
+// sent more than signature.DefaultTolerance from now, or already received,
+// are not passed on; see SetVerifier.
+func New(handler Handler) *Gateway {
+	return &Gateway{
+		handler:  handler,
+		verifier: signature.New(),
+		mux:      http.NewServeMux(),
+		mounted:  make(map[string]mounted),
+	}
+}
+
+// SetVerifier sets how the timestamps and IDs of signed callbacks are
+// checked, e.g. to share a signature.Store between replicas. Callbacks
+// outside the verifier's tolerance are answered with 400, and repeats with
+// 200 without reaching the Handler.
+func (g *Gateway) SetVerifier(v *signature.Verifier) {
+	g.verifier = v
+}
+
+// SetInbox stores callbacks in box instead of passing them to the Handler,
+// and answers them once stored. Callbacks failing verification are stored
+// as rejected. Create box with Dispatch as its handler, so stored callbacks
+// reach the Handler when the inbox's worker runs:
+//
+//	box := inbox.New(store, gateway.Dispatch)
+//	gateway.SetInbox(box)
+//	go box.Run(ctx)
+//
+// Callbacks answered by the gateway itself, such as M-Pesa C2B validation,
+// are not stored.
+func (g *Gateway) SetInbox(box *inbox.Inbox) {
+	g.inbox = box
+}
+
+// Dispatch decodes a callback stored by the inbox and passes it to the
+// Handler. Callbacks that cannot be decoded fail with inbox.Permanent.
+func (g *Gateway) Dispatch(ctx context.Context, msg *inbox.Message) error {
+	m, ok := g.mounted[msg.Route]
+	if !ok || m.rt.decode == nil {
+		return inbox.Permanent(fmt.Errorf("no callback mounted at %s", msg.Route))
+	}
+	event, err := m.decode(msg.Body, msg.ReceivedAt)
+	if err != nil {
+		return inbox.Permanent(err)
+	}
+	return g.handler(ctx, event)
+}
+
+// SetTelemetry counts each callback with telemetry.WebhookReceived.
+func (g *Gateway) SetTelemetry(t *telemetry.Telemetry) {
+	g.telemetry = t
+}
+
+// Mount serves the callbacks of sources under "/<provider>/<tenant>/". An
+// empty tenant mounts them under "/<provider>/". Mounting a provider twice
+// for a tenant panics.
+func (g *Gateway) Mount(tenant string, sources ...Source) {
+	for _, source := range sources {
+		for _, rt := range source.routes() {
+			p := path.Join("/", string(source.provider()), tenant, rt.path)
+			m := mounted{source: source, tenant: tenant, rt: rt}
+			g.mux.Handle(p, g.serve(p, m))
+			g.mounted[p] = m
+			g.paths = append(g.paths, p)
+		}
+	}
+}
+
+// Paths returns the paths mounted so far, to register as callback URLs.
+func (g *Gateway) Paths() []string {
+	return slices.Sorted(slices.Values(g.paths))
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *Gateway) serve(p string, m mounted) http.Handler {
+	source, tenant, rt := m.source, m.tenant, m.rt
+	methods := rt.methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost}
+	}
+	ack := rt.ack
+	if ack == nil {
+		ack = reply
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var (
+			event Event
+			err   error
+		)
+		defer func() {
+			if g.telemetry != nil {
+				eventType := path.Base(rt.path)
+				if event != nil {
+					eventType = event.Metadata().Type
+				}
+				g.telemetry.WebhookReceived(r.Context(), string(source.provider()), eventType, err)
+			}
+		}()
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		if err != nil {
+			ack(w, http.StatusBadRequest, err)
+			return
+		}
+		if err = source.verify(r, body); err != nil {
+			if g.inbox != nil {
+				g.inbox.Receive(r.Context(), message(r, p, m, body, err))
+			}
+			ack(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if rt.handler != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			rt.handler.ServeHTTP(w, r)
+			return
+		}
+
+		if event, err = m.decode(body, time.Now()); err != nil {
+			ack(w, http.StatusBadRequest, err)
+			return
+		}
+		meta := event.Metadata()
+
+		var id string
+		if rt.signed && meta.ID != "" {
+			id = path.Join(string(meta.Provider), tenant, meta.ID)
+		}
+		if rt.signed {
+			if err = g.verifier.Check(r.Context(), id, meta.SentAt); err != nil {
+				switch {
+				case errors.Is(err, signature.ErrReplayed):
+					// Already handled, acknowledge so it is not sent again
+					err = nil
+					ack(w, http.StatusOK, nil)
+				case errors.Is(err, signature.ErrTimestamp):
+					ack(w, http.StatusBadRequest, err)
+				default:
+					ack(w, http.StatusInternalServerError, err)
+				}
+				return
+			}
+		}
+
+		if g.inbox != nil {
+			if _, err = g.inbox.Receive(r.Context(), message(r, p, m, body, nil)); err != nil {
+				g.verifier.Forget(r.Context(), id)
+				ack(w, http.StatusInternalServerError, err)
+				return
+			}
+			ack(w, http.StatusOK, nil)
+			return
+		}
+
+		if err = g.handler(r.Context(), event); err != nil {
+			// Let the provider send the callback again
+			g.verifier.Forget(r.Context(), id)
+			ack(w, http.StatusInternalServerError, err)
+			return
+		}
+		ack(w, http.StatusOK, nil)
+	})
+}
+
+// decode decodes body into an Event and fills in its Meta.
+func (m mounted) decode(body []byte, receivedAt time.Time) (Event, error) {
+	event, err := m.rt.decode(body)
+	if err != nil {
+		return nil, err
+	}
+	meta := event.Metadata()
+	meta.Provider, meta.Tenant = m.source.provider(), m.tenant
+	meta.ReceivedAt, meta.Body = receivedAt, body
+	return event, nil
+}
+
+// message is the inbox message for a callback received on p, which failed
+// verification if verifyErr is set.
+func message(r *http.Request, p string, m mounted, body []byte, verifyErr error) inbox.Message {
+	msg := inbox.Message{
+		Provider: string(m.source.provider()),
+		Tenant:   m.tenant,
+		Route:    p,
+		Header:   r.Header.Clone(),
+		Body:     body,
+		Verified: verifyErr == nil,
+	}
+	if verifyErr != nil {
+		msg.VerifyError = verifyErr.Error()
+	}
+	return msg
+}
+
+func reply(w http.ResponseWriter, status int, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(status)
+	case status == http.StatusInternalServerError:
+		// Handler errors may hold internal details
+		http.Error(w, "Internal server error", status)
+	default:
+		http.Error(w, err.Error(), status)
+	}
+}
+
+// verifyToken checks the token query parameter of the callback URL, for
+// providers that do not sign callbacks. An empty token is not checked.
+func verifyToken(r *http.Request, token string) error {
+	if token == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		return ErrSignature
+	}
+	return nil
+}
+
+// verifySignature checks that header holds the hex HMAC-SHA256 of body.
+// Callbacks are refused when secret is empty, since the provider always
+// signs them.
+func verifySignature(r *http.Request, body []byte, header, secret string) error {
+	if !signature.Valid(body, r.Header.Get(header), secret) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// parseAmount returns the amount in currency, or zero if either is missing
+// or malformed.
+func parseAmount(amount, currency string) money.Money {
+	if amount == "" || currency == "" {
+		return money.Money{}
+	}
+	m, err := money.Parse(amount, money.Currency(currency))
+	if err != nil {
+		return money.Money{}
+	}
+	return m
+}
+
+// paymentEvents are the prefixes of event types about a payment.
+var paymentEvents = []string{"payment.", "collection.", "transfer.", "provider.payment."}
+
+// eventStatus maps a payment event type that ends in a status, e.g.
+// "payment.completed" or "collection.rejected", onto a Status. It is empty
+// for other events.
+func eventStatus(eventType string) rails.Status {
+	for _, prefix := range paymentEvents {
+		if strings.HasPrefix(eventType, prefix) {
+			return rails.ParseStatus(eventType[strings.LastIndex(eventType, ".")+1:])
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
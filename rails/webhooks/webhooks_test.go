@@ -0,0 +1,319 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nutcas3/payment-rails/mpesa/pkg/daraja"
+	"github.com/nutcas3/payment-rails/rails"
+	"github.com/nutcas3/payment-rails/rails/inbox"
+	"github.com/nutcas3/payment-rails/rails/signature"
+)
+
+const stkPayload = `{
+	"Body": {
+		"stkCallback": {
+			"MerchantRequestID": "29115-34620561-1",
+			"CheckoutRequestID": "ws_CO_191220191020363925",
+			"ResultCode": 0,
+			"ResultDesc": "The service request is processed successfully.",
+			"CallbackMetadata": {
+				"Item": [
+					{"Name": "Amount", "Value": 1.00},
+					{"Name": "MpesaReceiptNumber", "Value": "NLJ7RT61SV"},
+					{"Name": "TransactionDate", "Value": 20191219102115},
+					{"Name": "PhoneNumber", "Value": 254708374149}
+				]
+			}
+		}
+	}
+}`
+
+func jengaPayload(id string, createdAt time.Time) string {
+	return `{
+		"id": "` + id + `",
+		"event_type": "transaction.failed",
+		"merchant_code": "0011547896523",
+		"data": {"transaction_id": "TX-1", "reference": "INV-1001", "amount": "250.00", "currency": "KES", "status": "FAILED"},
+		"created_at": "` + createdAt.Format(time.RFC3339) + `"
+	}`
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func post(gateway http.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMpesa(t *testing.T) {
+	var events []Event
+	gateway := New(func(ctx context.Context, event Event) error {
+		events = append(events, event)
+		return nil
+	})
+	gateway.Mount("acme", Mpesa{Token: "s3cret"})
+
+	if rec := post(gateway, http.MethodPost, "/mpesa/acme/stk?token=wrong", stkPayload, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", rec.Code)
+	}
+	if rec := post(gateway, http.MethodGet, "/mpesa/acme/stk?token=s3cret", "", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+
+	rec := post(gateway, http.MethodPost, "/mpesa/acme/stk?token=s3cret", stkPayload, nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ResultCode":0`) {
+		t.Fatalf("Expected an accepted callback, got %d %s", rec.Code, rec.Body)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	event, ok := events[0].(*MpesaSTKEvent)
+	if !ok {
+		t.Fatalf("Expected *MpesaSTKEvent, got %T", events[0])
+	}
+	if event.Provider != rails.ProviderMpesa || event.Tenant != "acme" || event.Type != "stk_callback" {
+		t.Errorf("Unexpected metadata %+v", event.Meta)
+	}
+	if event.TransactionID != "ws_CO_191220191020363925" || event.Status != rails.StatusSucceeded || event.Amount.String() != "KES 1.00" {
+		t.Errorf("Unexpected metadata %+v", event.Meta)
+	}
+	if event.Callback.MpesaReceiptNumber != "NLJ7RT61SV" {
+		t.Errorf("Expected the decoded callback, got %+v", event.Callback)
+	}
+}
+
+func TestMpesaC2BValidation(t *testing.T) {
+	called := false
+	gateway := New(func(ctx context.Context, event Event) error {
+		called = true
+		return nil
+	})
+	gateway.Mount("", Mpesa{Validate: func(ctx context.Context, req *daraja.C2BValidationRequest) (daraja.C2BDecision, error) {
+		return daraja.C2BReject(daraja.C2BRejectInvalidAccountNumber), nil
+	}})
+
+	rec := post(gateway, http.MethodPost, "/mpesa/c2b/validation", `{"TransID":"RKTQDM7W6S","TransAmount":"10","BillRefNumber":"x"}`, nil)
+	if !strings.Contains(rec.Body.String(), daraja.C2BRejectInvalidAccountNumber) {
+		t.Errorf("Expected the validation decision, got %s", rec.Body)
+	}
+	if called {
+		t.Error("Expected validation requests not to reach the handler")
+	}
+}
+
+func TestJenga(t *testing.T) {
+	fail := false
+	var got *JengaEvent
+	gateway := New(func(ctx context.Context, event Event) error {
+		if fail {
+			return errors.New("database down")
+		}
+		got = event.(*JengaEvent)
+		return nil
+	})
+	gateway.Mount("acme", Jenga{Secret: "jenga-secret"})
+	gateway.Mount("globex", Jenga{})
+
+	body := jengaPayload("evt-1", time.Now())
+	signed := http.Header{"X-Jenga-Signature": {sign("jenga-secret", body)}}
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a signature, got %d", rec.Code)
+	}
+	if rec := post(gateway, http.MethodPost, "/jenga/globex/callback", body, signed); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a tenant without a secret, got %d", rec.Code)
+	}
+
+	fail = true
+	rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, signed)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "database") {
+		t.Errorf("Expected a bare 500 for a handler error, got %d %s", rec.Code, rec.Body)
+	}
+
+	fail = false
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, signed); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the retry to be accepted, got %d %s", rec.Code, rec.Body)
+	}
+	if got.Status != rails.StatusFailed || got.TransactionID != "TX-1" || got.Reference != "INV-1001" || got.Amount.String() != "KES 250.00" {
+		t.Errorf("Unexpected metadata %+v", got.Meta)
+	}
+	if got.ID != "evt-1" || got.SentAt.IsZero() {
+		t.Errorf("Expected the event ID and time, got %+v", got.Meta)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	calls := 0
+	gateway := New(func(ctx context.Context, event Event) error {
+		calls++
+		return nil
+	})
+	gateway.Mount("acme", Jenga{Secret: "jenga-secret"})
+	gateway.Mount("globex", Jenga{Secret: "jenga-secret"})
+
+	body := jengaPayload("evt-1", time.Now())
+	signed := http.Header{"X-Jenga-Signature": {sign("jenga-secret", body)}}
+	for i := 0; i < 2; i++ {
+		if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, signed); rec.Code != http.StatusOK {
+			t.Errorf("Expected 200, got %d %s", rec.Code, rec.Body)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected a replayed webhook to be dropped, got %d calls", calls)
+	}
+	if rec := post(gateway, http.MethodPost, "/jenga/globex/callback", body, signed); rec.Code != http.StatusOK || calls != 2 {
+		t.Errorf("Expected event IDs to be kept per tenant, got %d after %d calls", rec.Code, calls)
+	}
+
+	stale := jengaPayload("evt-2", time.Now().Add(-time.Hour))
+	signed = http.Header{"X-Jenga-Signature": {sign("jenga-secret", stale)}}
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", stale, signed); rec.Code != http.StatusBadRequest || calls != 2 {
+		t.Errorf("Expected 400 for a stale webhook, got %d", rec.Code)
+	}
+
+	gateway.SetVerifier(&signature.Verifier{Tolerance: 2 * time.Hour})
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", stale, signed); rec.Code != http.StatusOK || calls != 3 {
+		t.Errorf("Expected the tolerance to be configurable, got %d", rec.Code)
+	}
+}
+
+func TestInbox(t *testing.T) {
+	ctx := context.Background()
+	fail := true
+	var got *JengaEvent
+	gateway := New(func(ctx context.Context, event Event) error {
+		if fail {
+			return errors.New("database down")
+		}
+		got = event.(*JengaEvent)
+		return nil
+	})
+	gateway.Mount("acme", Jenga{Secret: "jenga-secret"})
+
+	store := inbox.NewMemoryStore()
+	box := inbox.New(store, gateway.Dispatch)
+	box.SetSettings(inbox.Settings{BaseDelay: time.Nanosecond, MaxDelay: time.Nanosecond})
+	gateway.SetInbox(box)
+
+	body := jengaPayload("evt-1", time.Now())
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, http.Header{"X-Jenga-Signature": {sign("jenga-secret", body)}}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the callback to be answered once stored, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := post(gateway, http.MethodPost, "/jenga/acme/callback", body, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a signature, got %d", rec.Code)
+	}
+	if rejected, _ := store.List(ctx, inbox.StateRejected, 0); len(rejected) != 1 {
+		t.Errorf("Expected the unsigned callback to be stored as rejected, got %d", len(rejected))
+	}
+
+	if handled, _ := box.Process(ctx); handled != 0 {
+		t.Fatalf("Expected the handler to fail, got %d handled", handled)
+	}
+	fail = false
+	time.Sleep(time.Millisecond)
+	if handled, _ := box.Process(ctx); handled != 1 {
+		t.Fatalf("Expected the retry to be handled, got %d", handled)
+	}
+	if got == nil || got.Tenant != "acme" || got.ID != "evt-1" || got.TransactionID != "TX-1" {
+		t.Errorf("Expected the stored callback to be decoded, got %+v", got)
+	}
+
+	err := gateway.Dispatch(ctx, &inbox.Message{Route: "/jenga/globex/callback"})
+	if err == nil {
+		t.Error("Expected an error for a callback that is not mounted")
+	}
+}
+
+func TestAirtel(t *testing.T) {
+	transaction := `{"id":"INV-1001","message":"Paid KES 10","status_code":"TS","airtel_money_id":"MP210603.1234.L06941"}`
+	mac := hmac.New(sha256.New, []byte("airtel-secret"))
+	mac.Write([]byte(transaction))
+	body := `{"transaction":` + transaction + `,"hash":"` + base64.StdEncoding.EncodeToString(mac.Sum(nil)) + `"}`
+
+	var got *AirtelEvent
+	gateway := New(func(ctx context.Context, event Event) error {
+		got = event.(*AirtelEvent)
+		return nil
+	})
+	gateway.Mount("acme", Airtel{Secret: "airtel-secret"})
+
+	if rec := post(gateway, http.MethodPost, "/airtel/acme/callback", strings.Replace(body, "TS", "TF", 1), nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a modified callback, got %d", rec.Code)
+	}
+	if rec := post(gateway, http.MethodPost, "/airtel/acme/callback", body, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if got.Status != rails.StatusSucceeded || got.Reference != "INV-1001" || got.TransactionID != "MP210603.1234.L06941" {
+		t.Errorf("Unexpected metadata %+v", got.Meta)
+	}
+}
+
+func TestMomo(t *testing.T) {
+	var got *MomoCollectionEvent
+	gateway := New(func(ctx context.Context, event Event) error {
+		got = event.(*MomoCollectionEvent)
+		return nil
+	})
+	gateway.Mount("acme", Momo{})
+
+	body := `{"financialTransactionId":"2130571924","externalId":"INV-1001","amount":"100","currency":"EUR","payer":{"partyIdType":"MSISDN","partyId":"46733123450"},"status":"FAILED","reason":{"code":"APPROVAL_REJECTED"}}`
+	if rec := post(gateway, http.MethodPut, "/momo/acme/collection", body, nil); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", rec.Code, rec.Body)
+	}
+	if got.Status != rails.StatusCancelled || got.Reference != "INV-1001" || got.Amount.String() != "EUR 100.00" {
+		t.Errorf("Unexpected metadata %+v", got.Meta)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	gateway := New(func(context.Context, Event) error { return nil })
+	gateway.Mount("acme", Momo{}, SasaPay{}, FNB{})
+
+	want := []string{"/fnb/acme/callback", "/momo/acme/collection", "/momo/acme/disbursement", "/sasapay/acme/callback"}
+	if got := gateway.Paths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestEventStatus(t *testing.T) {
+	tests := map[string]rails.Status{
+		"payment.completed":   rails.StatusSucceeded,
+		"collection.rejected": rails.StatusFailed,
+		"payment.cancelled":   rails.StatusCancelled,
+		"mandate.approved":    "",
+	}
+	for eventType, want := range tests {
+		if got := eventStatus(eventType); got != want {
+			t.Errorf("eventStatus(%s) = '%s', expected '%s'", eventType, got, want)
+		}
+	}
+}
+
+func TestDecodeMpesaSTKUnlistedCode(t *testing.T) {
+	event, err := decodeMpesaSTK([]byte(strings.Replace(stkPayload, `"ResultCode": 0`, `"ResultCode": 4321`, 1)))
+	if err != nil {
+		t.Fatalf("decodeMpesaSTK failed: %v", err)
+	}
+	if status := event.(*MpesaSTKEvent).Status; status != rails.StatusFailed {
+		t.Errorf("Expected a callback with an unlisted code to fail the payment, got %s", status)
+	}
+}
@@ -2,23 +2,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
-	"github.com/patrickmn/go-cache"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/signature"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 const (
 	SandboxBaseURL = "https://sandbox.sasapay.app/api/v1"
 	
 	ProductionBaseURL = "https://api.sasapay.app/api/v1"
-	
-	tokenCacheKey = "sasapay_auth_token"
-	
-	tokenExpiryBuffer = 60
 )
 
 type Client struct {
@@ -26,8 +27,11 @@ type Client struct {
 	ClientSecret string
 	BaseURL      string
 	HTTPClient   *http.Client
-	TokenCache   *cache.Cache
+	tokens       *auth.TokenManager
+	retry        retry.Policy
+	breaker      *breaker.Breaker
 	WebhookSecret string
+	verifier     *signature.Verifier
 }
 
 func NewClient(clientID, clientSecret, environment string) (*Client, error) {
@@ -36,17 +40,71 @@ func NewClient(clientID, clientSecret, environment string) (*Client, error) {
 		baseURL = ProductionBaseURL
 	}
 	
-	return &Client{
+	c := &Client{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		BaseURL:      baseURL,
 		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
-		TokenCache:   NewCache(),
-	}, nil
+	}
+	c.tokens = auth.NewTokenManager(nil, auth.Key("sasapay", baseURL, clientID), c.fetchAuthToken)
+	c.retry = retry.DefaultPolicy()
+	c.breaker = breaker.New(provider, breaker.DefaultSettings())
+	c.verifier = signature.New()
+
+	return c, nil
+}
+
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.HTTPClient = httpClient
+}
+
+// SetBaseURL overrides the API base URL the environment selects, e.g. to
+// send requests through a proxy or to a mock server. Tokens fetched from
+// baseURL are stored apart from the environment's.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.BaseURL = baseURL
+	c.tokens.SetKey(auth.Key("sasapay", baseURL, c.ClientID))
+}
+
+// SetTokenStore shares access tokens through store, so replicas using the
+// same client ID reuse one token instead of each requesting their own.
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.tokens.SetStore(store)
 }
 
-func NewCache() *cache.Cache {
-	return cache.New(24*time.Hour, 1*time.Hour)
+// SetTokenSkew sets how long before expiry the access token is refreshed,
+// auth.DefaultSkew by default.
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.tokens.SetSkew(skew)
+}
+
+// SetRetryPolicy sets how requests that fail with a gateway error or a
+// transient network error are retried, retry.DefaultPolicy() by default.
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.retry = policy
+}
+
+// SetCircuitBreaker replaces the circuit breaker, which stops sending
+// requests to a failing provider or endpoint, with one using settings.
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.breaker = breaker.New(provider, settings)
+}
+
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.HTTPClient.Do)
+}
+
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.HTTPClient = transport.Wrap(c.HTTPClient, provider, middleware...)
 }
 
 func (c *Client) SetWebhookSecret(secret string) {
@@ -54,10 +112,18 @@ func (c *Client) SetWebhookSecret(secret string) {
 }
 
 func (c *Client) GetAuthToken() (string, error) {
-	if token, found := c.TokenCache.Get(tokenCacheKey); found {
-		return token.(string), nil
+	return c.GetAuthTokenWithContext(context.Background())
+}
+
+func (c *Client) GetAuthTokenWithContext(ctx context.Context) (string, error) {
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return "", err
 	}
-	
+	return token.AccessToken, nil
+}
+
+func (c *Client) fetchAuthToken(ctx context.Context) (auth.Token, error) {
 	url := fmt.Sprintf("%s/auth/token", c.BaseURL)
 	
 	authReq := AuthTokenRequest{
@@ -67,70 +133,76 @@ func (c *Client) GetAuthToken() (string, error) {
 	
 	reqBody, err := json.Marshal(authReq)
 	if err != nil {
-		return "", fmt.Errorf("error marshalling auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error marshalling auth request: %w", err)
 	}
 	
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("error creating auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error creating auth request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
 	
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
-		return "", fmt.Errorf("error sending auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("error sending auth request: %w", err)
 	}
 	defer resp.Body.Close()
 	
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("error reading auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("error reading auth response: %w", err)
 	}
 	
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("auth request failed with status %d: %s", resp.StatusCode, string(body))
+		return auth.Token{}, newProviderError(resp, body).AuthFailure()
 	}
 	
 	var authResp AuthTokenResponse
 	err = json.Unmarshal(body, &authResp)
 	if err != nil {
-		return "", fmt.Errorf("error unmarshalling auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("error unmarshalling auth response: %w", err)
 	}
 	
-	expiryDuration := time.Duration(authResp.ExpiresIn-tokenExpiryBuffer) * time.Second
-	c.TokenCache.Set(tokenCacheKey, authResp.AccessToken, expiryDuration)
-	
-	return authResp.AccessToken, nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
 func (c *Client) SendRequest(method, endpoint string, body interface{}) ([]byte, error) {
-	token, err := c.GetAuthToken()
-	if err != nil {
-		return nil, fmt.Errorf("error getting auth token: %w", err)
-	}
-	
+	return c.SendRequestWithContext(context.Background(), method, endpoint, body)
+}
+
+func (c *Client) SendRequestWithContext(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
 	url := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
 	
 	var reqBody []byte
 	if body != nil {
+		var err error
 		reqBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("error marshalling request body: %w", err)
 		}
 	}
 	
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.tokens.Do(ctx, func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBody))
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		
+		resp, err := c.retry.Do(req, c.roundTrip)
+		if err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
+		}
+		return resp, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 	
@@ -140,11 +212,7 @@ func (c *Client) SendRequest(method, endpoint string, body interface{}) ([]byte,
 	}
 	
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var apiErr APIError
-		if err := json.Unmarshal(respBody, &apiErr); err == nil {
-			return nil, &apiErr
-		}
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newProviderError(resp, respBody)
 	}
 	
 	return respBody, nil
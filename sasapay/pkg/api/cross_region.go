@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -65,6 +66,10 @@ type CrossRegionQuoteResponse struct {
 }
 
 func (c *Client) CrossRegionTransfer(req CrossRegionTransferRequest) (*CrossRegionTransferResponse, error) {
+	return c.CrossRegionTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) CrossRegionTransferWithContext(ctx context.Context, req CrossRegionTransferRequest) (*CrossRegionTransferResponse, error) {
 	if req.SourceRegion == "" {
 		return nil, fmt.Errorf("source region is required")
 	}
@@ -90,7 +95,7 @@ func (c *Client) CrossRegionTransfer(req CrossRegionTransferRequest) (*CrossRegi
 		return nil, fmt.Errorf("reference is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/cross-region/transfer", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/cross-region/transfer", req)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +113,10 @@ func (c *Client) CrossRegionTransfer(req CrossRegionTransferRequest) (*CrossRegi
 }
 
 func (c *Client) GetCrossRegionQuote(req CrossRegionQuoteRequest) (*CrossRegionQuoteResponse, error) {
+	return c.GetCrossRegionQuoteWithContext(context.Background(), req)
+}
+
+func (c *Client) GetCrossRegionQuoteWithContext(ctx context.Context, req CrossRegionQuoteRequest) (*CrossRegionQuoteResponse, error) {
 	if req.SourceRegion == "" {
 		return nil, fmt.Errorf("source region is required")
 	}
@@ -127,7 +136,7 @@ func (c *Client) GetCrossRegionQuote(req CrossRegionQuoteRequest) (*CrossRegionQ
 		return nil, fmt.Errorf("amount must be greater than zero")
 	}
 
-	respBody, err := c.SendRequest("POST", "/cross-region/quote", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/cross-region/quote", req)
 	if err != nil {
 		return nil, err
 	}
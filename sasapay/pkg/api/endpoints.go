@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -10,6 +11,10 @@ import (
 
 
 func (c *Client) CustomerToBusiness(req C2BRequest) (*C2BResponse, error) {
+	return c.CustomerToBusinessWithContext(context.Background(), req)
+}
+
+func (c *Client) CustomerToBusinessWithContext(ctx context.Context, req C2BRequest) (*C2BResponse, error) {
 	if req.MerchantCode == "" {
 		return nil, fmt.Errorf("merchant code is required")
 	}
@@ -23,7 +28,7 @@ func (c *Client) CustomerToBusiness(req C2BRequest) (*C2BResponse, error) {
 		return nil, fmt.Errorf("reference is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/c2b/payment", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/c2b/payment", req)
 	if err != nil {
 		return nil, err
 	}
@@ -42,6 +47,10 @@ func (c *Client) CustomerToBusiness(req C2BRequest) (*C2BResponse, error) {
 
 
 func (c *Client) BusinessToCustomer(req B2CRequest) (*B2CResponse, error) {
+	return c.BusinessToCustomerWithContext(context.Background(), req)
+}
+
+func (c *Client) BusinessToCustomerWithContext(ctx context.Context, req B2CRequest) (*B2CResponse, error) {
 	if req.MerchantCode == "" {
 		return nil, fmt.Errorf("merchant code is required")
 	}
@@ -55,7 +64,7 @@ func (c *Client) BusinessToCustomer(req B2CRequest) (*B2CResponse, error) {
 		return nil, fmt.Errorf("reference is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/b2c/payment", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/b2c/payment", req)
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +83,10 @@ func (c *Client) BusinessToCustomer(req B2CRequest) (*B2CResponse, error) {
 
 
 func (c *Client) BusinessToBusiness(req B2BRequest) (*B2BResponse, error) {
+	return c.BusinessToBusinessWithContext(context.Background(), req)
+}
+
+func (c *Client) BusinessToBusinessWithContext(ctx context.Context, req B2BRequest) (*B2BResponse, error) {
 	if req.SourceMerchantCode == "" {
 		return nil, fmt.Errorf("source merchant code is required")
 	}
@@ -87,7 +100,7 @@ func (c *Client) BusinessToBusiness(req B2BRequest) (*B2BResponse, error) {
 		return nil, fmt.Errorf("reference is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/b2b/payment", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/b2b/payment", req)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +119,10 @@ func (c *Client) BusinessToBusiness(req B2BRequest) (*B2BResponse, error) {
 
 
 func (c *Client) CreateWallet(req CreateWalletRequest) (*CreateWalletResponse, error) {
+	return c.CreateWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateWalletWithContext(ctx context.Context, req CreateWalletRequest) (*CreateWalletResponse, error) {
 	if req.PhoneNumber == "" {
 		return nil, fmt.Errorf("phone number is required")
 	}
@@ -119,7 +136,7 @@ func (c *Client) CreateWallet(req CreateWalletRequest) (*CreateWalletResponse, e
 		return nil, fmt.Errorf("ID number is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/waas/wallet", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/waas/wallet", req)
 	if err != nil {
 		return nil, err
 	}
@@ -137,11 +154,15 @@ func (c *Client) CreateWallet(req CreateWalletRequest) (*CreateWalletResponse, e
 }
 
 func (c *Client) GetWalletBalance(req WalletBalanceRequest) (*WalletBalanceResponse, error) {
+	return c.GetWalletBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) GetWalletBalanceWithContext(ctx context.Context, req WalletBalanceRequest) (*WalletBalanceResponse, error) {
 	if req.WalletID == "" {
 		return nil, fmt.Errorf("wallet ID is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/waas/balance", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/waas/balance", req)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +180,10 @@ func (c *Client) GetWalletBalance(req WalletBalanceRequest) (*WalletBalanceRespo
 }
 
 func (c *Client) TransferToWallet(req WalletTransferRequest) (*WalletTransferResponse, error) {
+	return c.TransferToWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) TransferToWalletWithContext(ctx context.Context, req WalletTransferRequest) (*WalletTransferResponse, error) {
 	if req.SourceWalletID == "" {
 		return nil, fmt.Errorf("source wallet ID is required")
 	}
@@ -172,7 +197,7 @@ func (c *Client) TransferToWallet(req WalletTransferRequest) (*WalletTransferRes
 		return nil, fmt.Errorf("reference is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/waas/transfer", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/waas/transfer", req)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +215,10 @@ func (c *Client) TransferToWallet(req WalletTransferRequest) (*WalletTransferRes
 }
 
 func (c *Client) GetWalletStatement(req WalletStatementRequest) (*WalletStatementResponse, error) {
+	return c.GetWalletStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetWalletStatementWithContext(ctx context.Context, req WalletStatementRequest) (*WalletStatementResponse, error) {
 	if req.WalletID == "" {
 		return nil, fmt.Errorf("wallet ID is required")
 	}
@@ -203,7 +232,7 @@ func (c *Client) GetWalletStatement(req WalletStatementRequest) (*WalletStatemen
 		return nil, fmt.Errorf("end date must be after start date")
 	}
 
-	respBody, err := c.SendRequest("POST", "/waas/statement", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/waas/statement", req)
 	if err != nil {
 		return nil, err
 	}
@@ -222,11 +251,15 @@ func (c *Client) GetWalletStatement(req WalletStatementRequest) (*WalletStatemen
 
 
 func (c *Client) CheckTransactionStatus(req TransactionStatusRequest) (*TransactionStatusResponse, error) {
+	return c.CheckTransactionStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) CheckTransactionStatusWithContext(ctx context.Context, req TransactionStatusRequest) (*TransactionStatusResponse, error) {
 	if req.TransactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/transaction/status", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/transaction/status", req)
 	if err != nil {
 		return nil, err
 	}
@@ -244,11 +277,15 @@ func (c *Client) CheckTransactionStatus(req TransactionStatusRequest) (*Transact
 }
 
 func (c *Client) VerifyTransaction(req VerifyTransactionRequest) (*VerifyTransactionResponse, error) {
+	return c.VerifyTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyTransactionWithContext(ctx context.Context, req VerifyTransactionRequest) (*VerifyTransactionResponse, error) {
 	if req.TransactionID == "" {
 		return nil, fmt.Errorf("transaction ID is required")
 	}
 
-	respBody, err := c.SendRequest("POST", "/transaction/verify", req)
+	respBody, err := c.SendRequestWithContext(ctx, "POST", "/transaction/verify", req)
 	if err != nil {
 		return nil, err
 	}
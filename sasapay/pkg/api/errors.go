@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "sasapay"
+
+// errorCodeCategories maps SasaPay error codes to a category.
+var errorCodeCategories = map[string]railerrors.Category{
+	ErrInvalidRequest:      railerrors.CategoryValidation,
+	ErrInsufficientFunds:   railerrors.CategoryInsufficientFunds,
+	ErrAuthenticationError: railerrors.CategoryAuth,
+	ErrInvalidAccount:      railerrors.CategoryValidation,
+	ErrSystemError:         railerrors.CategoryProviderDown,
+}
+
+// newProviderError builds the error for a rejected request from its response
+// and body. The *APIError, if the body is one, is kept as its Err.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var apiErr APIError
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return railerrors.FromResponse(provider, resp, "", string(body))
+	}
+
+	perr := railerrors.FromResponse(provider, resp, apiErr.Code, apiErr.Message)
+	perr.Err = &apiErr
+	if category, ok := errorCodeCategories[apiErr.Code]; ok {
+		perr.SetCategory(category)
+	}
+	return perr
+}
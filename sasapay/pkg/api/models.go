@@ -3,6 +3,7 @@ package api
 import (
 	"time"
 
+	"github.com/nutcas3/payment-rails/rails/auth"
 	"github.com/shopspring/decimal"
 )
 
@@ -37,9 +38,9 @@ type AuthTokenRequest struct {
 }
 
 type AuthTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken string         `json:"access_token"`
+	TokenType   string         `json:"token_type"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
 }
 
 
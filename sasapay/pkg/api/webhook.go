@@ -1,12 +1,14 @@
 package api
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"time"
+
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
 const (
@@ -15,13 +17,42 @@ const (
 	EventPaymentFailed     = "payment.failed"
 	EventWalletCreated     = "wallet.created"
 	EventWalletTransferred = "wallet.transferred"
+
+	WebhookSignatureHeader = "X-SasaPay-Signature"
 )
 
-func (c *Client) HandleWebhook(payload []byte, signature string, handlers WebhookHandlers) error {
-	if c.WebhookSecret != "" {
-		if err := verifyWebhookSignature(payload, signature, c.WebhookSecret); err != nil {
-			return fmt.Errorf("webhook signature verification failed: %w", err)
-		}
+// ID identifies the webhook for replay checks. SasaPay webhooks have no
+// event ID, so it is made of the event type, transaction and timestamp, and
+// is empty if the transaction or timestamp is missing.
+func (e WebhookEvent) ID() string {
+	if e.TransactionID == "" || e.Timestamp.IsZero() {
+		return ""
+	}
+	return e.EventType + ":" + e.TransactionID + ":" + e.Timestamp.UTC().Format(time.RFC3339Nano)
+}
+
+// SetWebhookVerifier replaces the policy webhooks are verified with,
+// signature.New() by default, e.g. to share a signature.Store between
+// instances or change the timestamp tolerance.
+func (c *Client) SetWebhookVerifier(verifier *signature.Verifier) {
+	c.verifier = verifier
+}
+
+func (c *Client) HandleWebhook(payload []byte, sig string, handlers WebhookHandlers) error {
+	return c.HandleWebhookWithContext(context.Background(), payload, sig, handlers)
+}
+
+// HandleWebhookWithContext verifies a webhook and passes it to handlers.
+// Webhooks are refused when no webhook secret is set, unless the verifier
+// allows unsigned webhooks. A webhook already received returns nil without
+// calling handlers.
+func (c *Client) HandleWebhookWithContext(ctx context.Context, payload []byte, sig string, handlers WebhookHandlers) (err error) {
+	verifier := c.verifier
+	if verifier == nil {
+		verifier = &signature.Verifier{}
+	}
+	if err := verifier.Verify(payload, sig, c.WebhookSecret); err != nil {
+		return fmt.Errorf("webhook signature verification failed: %w", err)
 	}
 
 	var event WebhookEvent
@@ -29,6 +60,24 @@ func (c *Client) HandleWebhook(payload []byte, signature string, handlers Webhoo
 		return fmt.Errorf("error unmarshalling webhook payload: %w", err)
 	}
 
+	// Webhooks without an ID are only checked for their timestamp
+	var id string
+	if event.ID() != "" {
+		id = "sasapay:" + event.ID()
+	}
+	if err := verifier.Check(ctx, id, event.Timestamp); err != nil {
+		if errors.Is(err, signature.ErrReplayed) {
+			return nil
+		}
+		return fmt.Errorf("webhook rejected: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			// Let the webhook be sent again
+			verifier.Forget(ctx, id)
+		}
+	}()
+
 	switch event.EventType {
 	case EventPaymentReceived:
 		if handlers.PaymentReceived != nil {
@@ -57,24 +106,26 @@ func (c *Client) HandleWebhook(payload []byte, signature string, handlers Webhoo
 	return nil
 }
 
-func verifyWebhookSignature(payload []byte, signature, secret string) error {
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
-		return fmt.Errorf("invalid webhook signature")
+// VerifyWebhookSignature checks that sig is the hex HMAC-SHA256 of payload
+// keyed with secret. It compares in constant time.
+func VerifyWebhookSignature(payload []byte, sig, secret string) error {
+	if !signature.Valid(payload, sig, secret) {
+		return signature.ErrInvalid
 	}
 
 	return nil
 }
 
 func (c *Client) RegisterWebhookURL(url string) error {
+	return c.RegisterWebhookURLWithContext(context.Background(), url)
+}
+
+func (c *Client) RegisterWebhookURLWithContext(ctx context.Context, url string) error {
 	reqBody := map[string]string{
 		"webhook_url": url,
 	}
 
-	_, err := c.SendRequest("POST", "/webhook/register", reqBody)
+	_, err := c.SendRequestWithContext(ctx, "POST", "/webhook/register", reqBody)
 	if err != nil {
 		return err
 	}
@@ -82,11 +133,11 @@ func (c *Client) RegisterWebhookURL(url string) error {
 	return nil
 }
 
-func (c *Client) ProcessWebhookRequest(body io.ReadCloser, signature string, handlers WebhookHandlers) error {
+func (c *Client) ProcessWebhookRequest(body io.ReadCloser, sig string, handlers WebhookHandlers) error {
 	payload, err := io.ReadAll(body)
 	if err != nil {
 		return fmt.Errorf("error reading webhook request body: %w", err)
 	}
 
-	return c.HandleWebhook(payload, signature, handlers)
+	return c.HandleWebhook(payload, sig, handlers)
 }
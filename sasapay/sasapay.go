@@ -1,11 +1,20 @@
 package sasapay
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/nutcas3/payment-rails/sasapay/pkg/api"
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/signature"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 type Client struct {
@@ -43,65 +52,157 @@ func NewClient(clientID, clientSecret, environment string) (*Client, error) {
 	}, nil
 }
 
+func (c *Client) SetHttpClient(httpClient *http.Client) {
+	c.apiClient.SetHttpClient(httpClient)
+}
+
+func (c *Client) SetBaseURL(baseURL string) {
+	c.apiClient.SetBaseURL(baseURL)
+}
+
+func (c *Client) SetTokenStore(store auth.TokenStore) {
+	c.apiClient.SetTokenStore(store)
+}
+
+func (c *Client) SetTokenSkew(skew time.Duration) {
+	c.apiClient.SetTokenSkew(skew)
+}
+
+func (c *Client) SetRetryPolicy(policy retry.Policy) {
+	c.apiClient.SetRetryPolicy(policy)
+}
+
+func (c *Client) SetCircuitBreaker(settings breaker.Settings) {
+	c.apiClient.SetCircuitBreaker(settings)
+}
+
+func (c *Client) Health() breaker.Health {
+	return c.apiClient.Health()
+}
+
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.apiClient.Use(middleware...)
+}
+
 func (c *Client) SetWebhookSecret(secret string) {
 	c.apiClient.SetWebhookSecret(secret)
 }
 
+// SetWebhookVerifier sets the policy webhook signatures, timestamps and
+// replays are checked with
+func (c *Client) SetWebhookVerifier(verifier *signature.Verifier) {
+	c.apiClient.SetWebhookVerifier(verifier)
+}
+
+// GenerateReference returns a unique transaction reference, see
+// idempotency.NewReference.
 func GenerateReference() string {
-	return fmt.Sprintf("SASAPAY-%d", time.Now().UnixNano())
+	return idempotency.NewReference("SASAPAY-")
 }
 
 
 func (c *Client) CustomerToBusiness(req api.C2BRequest) (*api.C2BResponse, error) {
-	return c.apiClient.CustomerToBusiness(req)
+	return c.CustomerToBusinessWithContext(context.Background(), req)
+}
+
+func (c *Client) CustomerToBusinessWithContext(ctx context.Context, req api.C2BRequest) (*api.C2BResponse, error) {
+	return c.apiClient.CustomerToBusinessWithContext(ctx, req)
 }
 
 
 func (c *Client) BusinessToCustomer(req api.B2CRequest) (*api.B2CResponse, error) {
-	return c.apiClient.BusinessToCustomer(req)
+	return c.BusinessToCustomerWithContext(context.Background(), req)
+}
+
+func (c *Client) BusinessToCustomerWithContext(ctx context.Context, req api.B2CRequest) (*api.B2CResponse, error) {
+	return c.apiClient.BusinessToCustomerWithContext(ctx, req)
 }
 
 
 func (c *Client) BusinessToBusiness(req api.B2BRequest) (*api.B2BResponse, error) {
-	return c.apiClient.BusinessToBusiness(req)
+	return c.BusinessToBusinessWithContext(context.Background(), req)
+}
+
+func (c *Client) BusinessToBusinessWithContext(ctx context.Context, req api.B2BRequest) (*api.B2BResponse, error) {
+	return c.apiClient.BusinessToBusinessWithContext(ctx, req)
 }
 
 
 func (c *Client) CreateWallet(req api.CreateWalletRequest) (*api.CreateWalletResponse, error) {
-	return c.apiClient.CreateWallet(req)
+	return c.CreateWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) CreateWalletWithContext(ctx context.Context, req api.CreateWalletRequest) (*api.CreateWalletResponse, error) {
+	return c.apiClient.CreateWalletWithContext(ctx, req)
 }
 
 func (c *Client) GetWalletBalance(req api.WalletBalanceRequest) (*api.WalletBalanceResponse, error) {
-	return c.apiClient.GetWalletBalance(req)
+	return c.GetWalletBalanceWithContext(context.Background(), req)
+}
+
+func (c *Client) GetWalletBalanceWithContext(ctx context.Context, req api.WalletBalanceRequest) (*api.WalletBalanceResponse, error) {
+	return c.apiClient.GetWalletBalanceWithContext(ctx, req)
 }
 
 func (c *Client) TransferToWallet(req api.WalletTransferRequest) (*api.WalletTransferResponse, error) {
-	return c.apiClient.TransferToWallet(req)
+	return c.TransferToWalletWithContext(context.Background(), req)
+}
+
+func (c *Client) TransferToWalletWithContext(ctx context.Context, req api.WalletTransferRequest) (*api.WalletTransferResponse, error) {
+	return c.apiClient.TransferToWalletWithContext(ctx, req)
 }
 
 func (c *Client) GetWalletStatement(req api.WalletStatementRequest) (*api.WalletStatementResponse, error) {
-	return c.apiClient.GetWalletStatement(req)
+	return c.GetWalletStatementWithContext(context.Background(), req)
+}
+
+func (c *Client) GetWalletStatementWithContext(ctx context.Context, req api.WalletStatementRequest) (*api.WalletStatementResponse, error) {
+	return c.apiClient.GetWalletStatementWithContext(ctx, req)
 }
 
 
 func (c *Client) CheckTransactionStatus(req api.TransactionStatusRequest) (*api.TransactionStatusResponse, error) {
-	return c.apiClient.CheckTransactionStatus(req)
+	return c.CheckTransactionStatusWithContext(context.Background(), req)
+}
+
+func (c *Client) CheckTransactionStatusWithContext(ctx context.Context, req api.TransactionStatusRequest) (*api.TransactionStatusResponse, error) {
+	return c.apiClient.CheckTransactionStatusWithContext(ctx, req)
 }
 
 func (c *Client) VerifyTransaction(req api.VerifyTransactionRequest) (*api.VerifyTransactionResponse, error) {
-	return c.apiClient.VerifyTransaction(req)
+	return c.VerifyTransactionWithContext(context.Background(), req)
+}
+
+func (c *Client) VerifyTransactionWithContext(ctx context.Context, req api.VerifyTransactionRequest) (*api.VerifyTransactionResponse, error) {
+	return c.apiClient.VerifyTransactionWithContext(ctx, req)
 }
 
 
 func (c *Client) CrossRegionTransfer(req api.CrossRegionTransferRequest) (*api.CrossRegionTransferResponse, error) {
-	return c.apiClient.CrossRegionTransfer(req)
+	return c.CrossRegionTransferWithContext(context.Background(), req)
+}
+
+func (c *Client) CrossRegionTransferWithContext(ctx context.Context, req api.CrossRegionTransferRequest) (*api.CrossRegionTransferResponse, error) {
+	return c.apiClient.CrossRegionTransferWithContext(ctx, req)
 }
 
 func (c *Client) GetCrossRegionQuote(req api.CrossRegionQuoteRequest) (*api.CrossRegionQuoteResponse, error) {
-	return c.apiClient.GetCrossRegionQuote(req)
+	return c.GetCrossRegionQuoteWithContext(context.Background(), req)
+}
+
+func (c *Client) GetCrossRegionQuoteWithContext(ctx context.Context, req api.CrossRegionQuoteRequest) (*api.CrossRegionQuoteResponse, error) {
+	return c.apiClient.GetCrossRegionQuoteWithContext(ctx, req)
 }
 
 
 func (c *Client) HandleWebhook(payload []byte, signature string, handlers api.WebhookHandlers) error {
-	return fmt.Errorf("webhook handling not yet implemented")
+	return c.apiClient.HandleWebhook(payload, signature, handlers)
+}
+
+func (c *Client) HandleWebhookWithContext(ctx context.Context, payload []byte, signature string, handlers api.WebhookHandlers) error {
+	return c.apiClient.HandleWebhookWithContext(ctx, payload, signature, handlers)
+}
+
+func (c *Client) ProcessWebhookRequest(body io.ReadCloser, signature string, handlers api.WebhookHandlers) error {
+	return c.apiClient.ProcessWebhookRequest(body, signature, handlers)
 }
@@ -13,6 +13,12 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/auth"
+	"github.com/nutcas3/payment-rails/rails/breaker"
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/retry"
+	"github.com/nutcas3/payment-rails/rails/transport"
 )
 
 const (
@@ -32,6 +38,9 @@ type Client struct {
 	accessToken  string
 	tokenExpiry  time.Time
 	tokenMu      sync.RWMutex
+	tokens       *auth.TokenManager
+	retry        retry.Policy
+	breaker      *breaker.Breaker
 	logger       Logger
 }
 
@@ -43,8 +52,26 @@ type ClientConfig struct {
 	BaseURL      string
 	Timeout      time.Duration
 	Logger       Logger
+
+	// TokenStore shares access tokens between clients and processes.
+	// Defaults to an in-memory store.
+	TokenStore auth.TokenStore
+	// TokenSkew is how long before expiry the access token is refreshed.
+	// Defaults to TokenRefreshBuffer.
+	TokenSkew time.Duration
+	// RetryPolicy controls how requests that fail with a gateway error or a
+	// transient network error are retried. Defaults to retry.DefaultPolicy().
+	RetryPolicy *retry.Policy
+	// Breaker controls when requests to a failing endpoint are stopped.
+	// Defaults to breaker.DefaultSettings().
+	Breaker *breaker.Settings
+	// Middleware runs every request through transport middleware, such as
+	// transport.Log. More can be added with Client.Use.
+	Middleware []transport.Middleware
 }
 
+// Logger is the same as transport.Logger, so transport.Slog and
+// transport.Redacting loggers can be used here and this one with transport.Log.
 type Logger interface {
 	Log(level, message string, fields map[string]interface{})
 }
@@ -52,7 +79,7 @@ type Logger interface {
 type DefaultLogger struct{}
 
 func (l *DefaultLogger) Log(level, message string, fields map[string]interface{}) {
-	sanitized := sanitizeFields(fields)
+	sanitized := transport.RedactFields(fields)
 	log.Printf("[%s] %s: %+v", level, message, sanitized)
 }
 
@@ -80,7 +107,7 @@ func NewClient(config *ClientConfig) *Client {
 		MinVersion: tls.VersionTLS12,
 	}
 
-	return &Client{
+	client := &Client{
 		clientID:     config.ClientID,
 		clientSecret: config.ClientSecret,
 		apiKey:       config.APIKey,
@@ -96,13 +123,55 @@ func NewClient(config *ClientConfig) *Client {
 		},
 		logger: logger,
 	}
+	tokenSkew := config.TokenSkew
+	if tokenSkew == 0 {
+		tokenSkew = TokenRefreshBuffer
+	}
+
+	client.tokens = auth.NewTokenManager(config.TokenStore, auth.Key("standardbank", baseURL, config.ClientID), client.fetchToken)
+	client.tokens.SetSkew(tokenSkew)
+
+	client.retry = retry.DefaultPolicy()
+	if config.RetryPolicy != nil {
+		client.retry = *config.RetryPolicy
+	}
+
+	settings := breaker.DefaultSettings()
+	if config.Breaker != nil {
+		settings = *config.Breaker
+	}
+	client.breaker = breaker.New(provider, settings)
+
+	onRetry := client.retry.OnRetry
+	client.retry.OnRetry = func(attempt retry.Attempt) {
+		fields := map[string]interface{}{
+			"method":  attempt.Request.Method,
+			"url":     attempt.Request.URL.String(),
+			"attempt": attempt.Attempt,
+			"delay":   attempt.Delay.String(),
+		}
+		if attempt.Err != nil {
+			fields["error"] = attempt.Err.Error()
+		} else {
+			fields["status"] = attempt.StatusCode
+		}
+		client.logger.Log("WARN", "API request failed, retrying", fields)
+
+		if onRetry != nil {
+			onRetry(attempt)
+		}
+	}
+
+	client.Use(config.Middleware...)
+
+	return client
 }
 
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
-	Scope       string `json:"scope,omitempty"`
+	AccessToken string         `json:"access_token"`
+	TokenType   string         `json:"token_type"`
+	ExpiresIn   auth.ExpiresIn `json:"expires_in"`
+	Scope       string         `json:"scope,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -143,6 +212,18 @@ func (c *Client) Authenticate(ctx context.Context) error {
 		return nil
 	}
 
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.accessToken = token.AccessToken
+	c.tokenExpiry = c.tokens.RefreshAt(token)
+
+	return nil
+}
+
+func (c *Client) fetchToken(ctx context.Context) (auth.Token, error) {
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 	data.Set("client_id", c.clientID)
@@ -150,7 +231,7 @@ func (c *Client) Authenticate(ctx context.Context) error {
 
 	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/oauth/token", strings.NewReader(data.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to create auth request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -164,56 +245,42 @@ func (c *Client) Authenticate(ctx context.Context) error {
 		"url": req.URL.String(),
 	})
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.roundTrip(req)
 	if err != nil {
 		c.logger.Log("ERROR", "Authentication request failed", map[string]interface{}{
 			"error": err.Error(),
 		})
-		return fmt.Errorf("failed to execute auth request: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to execute auth request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read auth response: %w", err)
+		return auth.Token{}, fmt.Errorf("failed to read auth response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			errResp.Status = resp.StatusCode
-			c.logger.Log("ERROR", "Authentication failed", map[string]interface{}{
-				"status": resp.StatusCode,
-				"error":  errResp.Error(),
-			})
-			return &errResp
-		}
+		perr := newProviderError(resp, respBody).AuthFailure()
 		c.logger.Log("ERROR", "Authentication failed", map[string]interface{}{
 			"status": resp.StatusCode,
-			"body":   string(respBody),
+			"error":  perr.Error(),
 		})
-		return fmt.Errorf("authentication failed with status %d: %s", resp.StatusCode, string(respBody))
+		return auth.Token{}, perr
 	}
 
 	var authResp AuthResponse
 	if err := json.Unmarshal(respBody, &authResp); err != nil {
-		return fmt.Errorf("failed to decode auth response: %w", err)
-	}
-
-	c.accessToken = authResp.AccessToken
-	expirySeconds := time.Duration(authResp.ExpiresIn) * time.Second
-
-	if expirySeconds <= TokenRefreshBuffer {
-		c.tokenExpiry = time.Now().Add(expirySeconds / 2)
-	} else {
-		c.tokenExpiry = time.Now().Add(expirySeconds - TokenRefreshBuffer)
+		return auth.Token{}, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
 	c.logger.Log("INFO", "Successfully authenticated", map[string]interface{}{
 		"expires_in": authResp.ExpiresIn,
 	})
 
-	return nil
+	return auth.Token{
+		AccessToken: authResp.AccessToken,
+		ExpiresAt:   authResp.ExpiresIn.ExpiresAt(),
+	}, nil
 }
 
 func (c *Client) isTokenValid() bool {
@@ -221,23 +288,71 @@ func (c *Client) isTokenValid() bool {
 }
 
 func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}, result interface{}) error {
-	if err := c.Authenticate(ctx); err != nil {
-		return err
-	}
-
-	var reqBody io.Reader
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(data)
+		ctx = withIdempotencyKey(ctx, data)
 	}
 
 	url := c.baseURL + path
+	resp, respBody, err := c.send(ctx, method, url, data)
+	if err != nil {
+		return err
+	}
+	if auth.TokenRejected(resp.StatusCode, respBody) {
+		c.logger.Log("WARN", "Access token rejected, retrying with a new token", map[string]interface{}{
+			"method": method,
+			"url":    url,
+			"status": resp.StatusCode,
+		})
+		c.invalidateToken(ctx, resp.Request)
+		resp, respBody, err = c.send(ctx, method, url, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		perr := newProviderError(resp, respBody)
+		c.logger.Log("ERROR", "API request failed", map[string]interface{}{
+			"status": resp.StatusCode,
+			"error":  perr.Error(),
+		})
+		return perr
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	c.logger.Log("DEBUG", "API request successful", map[string]interface{}{
+		"method": method,
+		"url":    url,
+		"status": resp.StatusCode,
+	})
+
+	return nil
+}
+
+func (c *Client) send(ctx context.Context, method, url string, data []byte) (*http.Response, []byte, error) {
+	if err := c.Authenticate(ctx); err != nil {
+		return nil, nil, idempotency.NotSent(err)
+	}
+
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewBuffer(data)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, idempotency.NotSent(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	c.setHeaders(req)
@@ -247,52 +362,49 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 		"url":    url,
 	})
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.retry.Do(req, c.roundTrip)
 	if err != nil {
 		c.logger.Log("ERROR", "API request failed", map[string]interface{}{
 			"method": method,
 			"url":    url,
 			"error":  err.Error(),
 		})
-		return fmt.Errorf("failed to execute request: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode >= 400 {
-		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil {
-			errResp.Status = resp.StatusCode
-			c.logger.Log("ERROR", "API request failed", map[string]interface{}{
-				"status": resp.StatusCode,
-				"error":  errResp.Error(),
-			})
-			return &errResp
-		}
-		c.logger.Log("ERROR", "API request failed", map[string]interface{}{
-			"status": resp.StatusCode,
-			"body":   string(respBody),
-		})
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
-	}
+	return resp, respBody, nil
+}
 
-	if result != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
+// withIdempotencyKey marks ctx for retry when the request body carries an
+// idempotencyKey, which Standard Bank uses to discard duplicate instructions.
+func withIdempotencyKey(ctx context.Context, data []byte) context.Context {
+	var body struct {
+		IdempotencyKey string `json:"idempotencyKey"`
 	}
+	if json.Unmarshal(data, &body) == nil && body.IdempotencyKey != "" {
+		return retry.WithIdempotent(ctx)
+	}
+	return ctx
+}
 
-	c.logger.Log("DEBUG", "API request successful", map[string]interface{}{
-		"method": method,
-		"url":    url,
-		"status": resp.StatusCode,
-	})
+// invalidateToken drops the token req was sent with so the next request
+// authenticates again.
+func (c *Client) invalidateToken(ctx context.Context, req *http.Request) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
 
-	return nil
+	c.tokenMu.Lock()
+	if c.accessToken == token {
+		c.accessToken = ""
+	}
+	c.tokenMu.Unlock()
+
+	c.tokens.Invalidate(ctx, token)
 }
 
 func (c *Client) setHeaders(req *http.Request) {
@@ -311,28 +423,23 @@ func (c *Client) GetHTTPClient() *http.Client {
 	return c.httpClient
 }
 
-func (c *Client) GetBaseURL() string {
-	return c.baseURL
+// Use runs every request, including token fetches and retries, through
+// middleware such as transport.Log.
+func (c *Client) Use(middleware ...transport.Middleware) {
+	c.httpClient = transport.Wrap(c.httpClient, provider, middleware...)
 }
 
-func sanitizeFields(fields map[string]interface{}) map[string]interface{} {
-	sanitized := make(map[string]interface{})
-	sensitiveKeys := []string{"client_secret", "access_token", "authorization", "x-api-key"}
-
-	for k, v := range fields {
-		isSensitive := false
-		lowerKey := strings.ToLower(k)
-		for _, sensitive := range sensitiveKeys {
-			if strings.Contains(lowerKey, sensitive) {
-				isSensitive = true
-				break
-			}
-		}
-		if isSensitive {
-			sanitized[k] = "[REDACTED]"
-		} else {
-			sanitized[k] = v
-		}
-	}
-	return sanitized
+// Health reports the state of the circuit breaker, so callers can route
+// traffic away from a failing provider.
+func (c *Client) Health() breaker.Health {
+	return c.breaker.Health()
+}
+
+// roundTrip sends req through the circuit breaker.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	return c.breaker.Do(req, c.httpClient.Do)
+}
+
+func (c *Client) GetBaseURL() string {
+	return c.baseURL
 }
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	railerrors "github.com/nutcas3/payment-rails/rails/errors"
+)
+
+const provider = "standardbank"
+
+// newProviderError builds the error for a rejected request from its response
+// and body. The *ErrorResponse, if the body is one, is kept as its Err.
+func newProviderError(resp *http.Response, body []byte) *railerrors.ProviderError {
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return railerrors.FromResponse(provider, resp, "", string(body))
+	}
+	errResp.Status = resp.StatusCode
+
+	code, message := errResp.Code, errResp.Message
+	if code == "" {
+		code = errResp.Err
+	}
+	if message == "" {
+		message = errResp.ErrorDescription
+	}
+
+	perr := railerrors.FromResponse(provider, resp, code, message)
+	perr.Err = &errResp
+	return perr
+}
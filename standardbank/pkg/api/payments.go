@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net/url"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type PaymentRequest struct {
-	Amount              float64                `json:"amount"`
-	Currency            string                 `json:"currency"`
+	Amount              money.Number           `json:"amount"`
+	Currency            money.Currency         `json:"currency"`
 	Reference           string                 `json:"reference"`
 	Description         string                 `json:"description,omitempty"`
 	SourceAccount       string                 `json:"sourceAccount,omitempty"`
@@ -26,8 +29,8 @@ type PaymentResponse struct {
 	TransactionID      string                 `json:"transactionId"`
 	Status             string                 `json:"status"`
 	StatusDescription  string                 `json:"statusDescription,omitempty"`
-	Amount             float64                `json:"amount"`
-	Currency           string                 `json:"currency"`
+	Amount             money.Number           `json:"amount"`
+	Currency           money.Currency         `json:"currency"`
 	Reference          string                 `json:"reference"`
 	Description        string                 `json:"description,omitempty"`
 	SourceAccount      string                 `json:"sourceAccount,omitempty"`
@@ -44,8 +47,8 @@ type PaymentStatusResponse struct {
 	TransactionID      string                 `json:"transactionId"`
 	Status             string                 `json:"status"`
 	StatusDescription  string                 `json:"statusDescription,omitempty"`
-	Amount             float64                `json:"amount"`
-	Currency           string                 `json:"currency"`
+	Amount             money.Number           `json:"amount"`
+	Currency           money.Currency         `json:"currency"`
 	Reference          string                 `json:"reference"`
 	SourceAccount      string                 `json:"sourceAccount,omitempty"`
 	DestinationAccount string                 `json:"destinationAccount,omitempty"`
@@ -61,6 +64,10 @@ func (c *Client) CreatePayment(ctx context.Context, req PaymentRequest) (*Paymen
 		req.Currency = "ZAR"
 	}
 
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
+
 	if req.PaymentDate == "" {
 		req.PaymentDate = time.Now().Format("2006-01-02")
 	}
@@ -3,6 +3,9 @@ package api
 import (
 	"context"
 	"fmt"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type Provider struct {
@@ -11,7 +14,7 @@ type Provider struct {
 	Type        string            `json:"type"`
 	Description string            `json:"description,omitempty"`
 	Status      string            `json:"status"`
-	Currency    string            `json:"currency,omitempty"`
+	Currency    money.Currency    `json:"currency,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
@@ -24,8 +27,8 @@ type ProviderListResponse struct {
 
 type ProviderPaymentRequest struct {
 	ProviderID     string                 `json:"providerId"`
-	Amount         float64                `json:"amount"`
-	Currency       string                 `json:"currency"`
+	Amount         money.Number           `json:"amount"`
+	Currency       money.Currency         `json:"currency"`
 	Reference      string                 `json:"reference"`
 	Description    string                 `json:"description,omitempty"`
 	SourceAccount  string                 `json:"sourceAccount,omitempty"`
@@ -40,8 +43,8 @@ type ProviderPaymentResponse struct {
 	ProviderID        string                 `json:"providerId"`
 	Status            string                 `json:"status"`
 	StatusDescription string                 `json:"statusDescription,omitempty"`
-	Amount            float64                `json:"amount"`
-	Currency          string                 `json:"currency"`
+	Amount            money.Number           `json:"amount"`
+	Currency          money.Currency         `json:"currency"`
 	Reference         string                 `json:"reference"`
 	ProviderReference string                 `json:"providerReference,omitempty"`
 	Metadata          map[string]interface{} `json:"metadata,omitempty"`
@@ -72,6 +75,10 @@ func (c *Client) ExecuteProviderPayment(ctx context.Context, req ProviderPayment
 		req.Currency = "ZAR"
 	}
 
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
+
 	path := fmt.Sprintf("/api/providers/%s/pay", req.ProviderID)
 
 	var result ProviderPaymentResponse
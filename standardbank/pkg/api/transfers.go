@@ -4,50 +4,53 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/idempotency"
+	"github.com/nutcas3/payment-rails/rails/money"
 )
 
 type InternalTransferRequest struct {
-	SourceAccount      string  `json:"sourceAccount"`
-	DestinationAccount string  `json:"destinationAccount"`
-	Amount             float64 `json:"amount"`
-	Currency           string  `json:"currency"`
-	Reference          string  `json:"reference"`
-	Description        string  `json:"description,omitempty"`
-	DestinationName    string  `json:"destinationName,omitempty"`
-	TransferDate       string  `json:"transferDate,omitempty"`
-	IdempotencyKey     string  `json:"idempotencyKey,omitempty"`
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	Description        string         `json:"description,omitempty"`
+	DestinationName    string         `json:"destinationName,omitempty"`
+	TransferDate       string         `json:"transferDate,omitempty"`
+	IdempotencyKey     string         `json:"idempotencyKey,omitempty"`
 }
 
 type InternalTransferResponse struct {
-	TransferID         string     `json:"transferId"`
-	TransactionID      string     `json:"transactionId"`
-	Status             string     `json:"status"`
-	StatusDescription  string     `json:"statusDescription,omitempty"`
-	SourceAccount      string     `json:"sourceAccount"`
-	DestinationAccount string     `json:"destinationAccount"`
-	Amount             float64    `json:"amount"`
-	Currency           string     `json:"currency"`
-	Reference          string     `json:"reference"`
-	Description        string     `json:"description,omitempty"`
-	ProcessingDate     time.Time  `json:"processingDate"`
-	SettlementDate     *time.Time `json:"settlementDate,omitempty"`
-	CreatedAt          time.Time  `json:"createdAt"`
+	TransferID         string         `json:"transferId"`
+	TransactionID      string         `json:"transactionId"`
+	Status             string         `json:"status"`
+	StatusDescription  string         `json:"statusDescription,omitempty"`
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	Description        string         `json:"description,omitempty"`
+	ProcessingDate     time.Time      `json:"processingDate"`
+	SettlementDate     *time.Time     `json:"settlementDate,omitempty"`
+	CreatedAt          time.Time      `json:"createdAt"`
 }
 
 type TransferStatusResponse struct {
-	TransferID         string     `json:"transferId"`
-	TransactionID      string     `json:"transactionId"`
-	Status             string     `json:"status"`
-	StatusDescription  string     `json:"statusDescription,omitempty"`
-	SourceAccount      string     `json:"sourceAccount"`
-	DestinationAccount string     `json:"destinationAccount"`
-	Amount             float64    `json:"amount"`
-	Currency           string     `json:"currency"`
-	Reference          string     `json:"reference"`
-	ProcessingDate     time.Time  `json:"processingDate"`
-	SettlementDate     *time.Time `json:"settlementDate,omitempty"`
-	FailureReason      string     `json:"failureReason,omitempty"`
-	LastUpdated        time.Time  `json:"lastUpdated"`
+	TransferID         string         `json:"transferId"`
+	TransactionID      string         `json:"transactionId"`
+	Status             string         `json:"status"`
+	StatusDescription  string         `json:"statusDescription,omitempty"`
+	SourceAccount      string         `json:"sourceAccount"`
+	DestinationAccount string         `json:"destinationAccount"`
+	Amount             money.Number   `json:"amount"`
+	Currency           money.Currency `json:"currency"`
+	Reference          string         `json:"reference"`
+	ProcessingDate     time.Time      `json:"processingDate"`
+	SettlementDate     *time.Time     `json:"settlementDate,omitempty"`
+	FailureReason      string         `json:"failureReason,omitempty"`
+	LastUpdated        time.Time      `json:"lastUpdated"`
 }
 
 func (c *Client) CreateInternalTransfer(ctx context.Context, req InternalTransferRequest) (*InternalTransferResponse, error) {
@@ -55,6 +58,10 @@ func (c *Client) CreateInternalTransfer(ctx context.Context, req InternalTransfe
 		req.Currency = "ZAR"
 	}
 
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = idempotency.FromContext(ctx)
+	}
+
 	if req.TransferDate == "" {
 		req.TransferDate = time.Now().Format("2006-01-02")
 	}
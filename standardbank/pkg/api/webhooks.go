@@ -1,15 +1,17 @@
 package api
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
+const WebhookSignatureHeader = "X-StandardBank-Signature"
+
 type WebhookEvent struct {
 	EventID      string                 `json:"eventId"`
 	EventType    string                 `json:"eventType"`
@@ -22,6 +24,7 @@ type WebhookEvent struct {
 
 type WebhookHandler struct {
 	webhookSecret string
+	verifier      *signature.Verifier
 	handlers      map[string]WebhookEventHandler
 }
 
@@ -44,10 +47,17 @@ func NewWebhookHandler(webhookSecret string) *WebhookHandler {
 	}
 	return &WebhookHandler{
 		webhookSecret: webhookSecret,
+		verifier:      signature.New(),
 		handlers:      make(map[string]WebhookEventHandler),
 	}
 }
 
+// SetVerifier replaces the policy webhooks are verified with, e.g. to share
+// a signature.Store between instances or change the timestamp tolerance.
+func (wh *WebhookHandler) SetVerifier(verifier *signature.Verifier) {
+	wh.verifier = verifier
+}
+
 func (wh *WebhookHandler) RegisterHandler(eventType string, handler WebhookEventHandler) {
 	wh.handlers[eventType] = handler
 }
@@ -70,8 +80,8 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 		return fmt.Errorf("failed to read request body: %w", err)
 	}
 
-	signature := r.Header.Get("X-StandardBank-Signature")
-	if !wh.verifySignature(body, signature) {
+	sig := r.Header.Get(WebhookSignatureHeader)
+	if !wh.verifySignature(body, sig) {
 		http.Error(w, "Invalid signature", http.StatusUnauthorized)
 		return fmt.Errorf("invalid webhook signature")
 	}
@@ -83,6 +93,29 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 		return fmt.Errorf("failed to parse webhook event: %w", err)
 	}
 
+	// Events without an ID are only checked for their timestamp
+	var id string
+	if event.EventID != "" {
+		id = "standardbank:" + event.EventID
+	}
+	if err := wh.verifier.Check(r.Context(), id, event.Timestamp.Time); err != nil {
+		if errors.Is(err, signature.ErrReplayed) {
+			// Already handled, acknowledge so it is not sent again
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{
+				"status":  "duplicate",
+				"eventId": event.EventID,
+			})
+			return nil
+		}
+		if errors.Is(err, signature.ErrTimestamp) {
+			http.Error(w, "Invalid timestamp", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to check event", http.StatusInternalServerError)
+		}
+		return fmt.Errorf("rejected webhook event %s: %w", event.EventID, err)
+	}
+
 	handler, exists := wh.handlers[event.EventType]
 	if !exists {
 		w.WriteHeader(http.StatusOK)
@@ -94,6 +127,8 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := handler(event); err != nil {
+		// Let Standard Bank send the event again
+		wh.verifier.Forget(r.Context(), id)
 		http.Error(w, fmt.Sprintf("Handler error: %v", err), http.StatusInternalServerError)
 		return fmt.Errorf("handler error: %w", err)
 	}
@@ -107,20 +142,8 @@ func (wh *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
-func (wh *WebhookHandler) verifySignature(payload []byte, signature string) bool {
-	if wh.webhookSecret == "" {
-		return false
-	}
-
-	if signature == "" {
-		return false
-	}
-
-	mac := hmac.New(sha256.New, []byte(wh.webhookSecret))
-	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+func (wh *WebhookHandler) verifySignature(payload []byte, sig string) bool {
+	return wh.verifier.Verify(payload, sig, wh.webhookSecret) == nil
 }
 
 func ParseWebhookEvent(data []byte) (*WebhookEvent, error) {
@@ -131,14 +154,8 @@ func ParseWebhookEvent(data []byte) (*WebhookEvent, error) {
 	return &event, nil
 }
 
-func ValidateWebhookSignature(payload []byte, signature, secret string) bool {
-	if secret == "" || signature == "" {
-		return false
-	}
-
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+// ValidateWebhookSignature reports whether sig is the hex HMAC-SHA256 of
+// payload keyed with secret. It compares in constant time.
+func ValidateWebhookSignature(payload []byte, sig, secret string) bool {
+	return signature.Valid(payload, sig, secret)
 }
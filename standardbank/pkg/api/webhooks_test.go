@@ -1,10 +1,15 @@
 package api
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestVerifySignature(t *testing.T) {
@@ -74,3 +79,35 @@ func TestVerifySignature(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWebhookNoEventID(t *testing.T) {
+	secret := "test-secret"
+	wh := NewWebhookHandler(secret)
+
+	var handled []string
+	wh.RegisterHandler(EventPaymentCompleted, func(event WebhookEvent) error {
+		handled = append(handled, event.ResourceID)
+		return nil
+	})
+
+	// Events without an ID cannot be told apart, so none is a replay
+	for _, paymentID := range []string{"PAY1", "PAY2"} {
+		payload, _ := json.Marshal(WebhookEvent{
+			EventType:  EventPaymentCompleted,
+			Timestamp:  JSONTime{time.Now()},
+			ResourceID: paymentID,
+		})
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+		req.Header.Set(WebhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+		w := httptest.NewRecorder()
+		if err := wh.HandleWebhook(w, req); err != nil {
+			t.Errorf("HandleWebhook(%s) failed: %v", paymentID, err)
+		}
+	}
+	if len(handled) != 2 {
+		t.Errorf("Expected both events to be handled, got %v", handled)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"payment-rails/standardbank/pkg/api"
 	"time"
+
+	"github.com/nutcas3/payment-rails/rails/signature"
 )
 
 type Client struct {
@@ -161,6 +163,16 @@ func (p *Providers) Pay(ctx context.Context, req api.ProviderPaymentRequest) (*a
 	return p.client.apiClient.ExecuteProviderPayment(ctx, req)
 }
 
+// SetWebhookVerifier replaces the policy webhooks are verified with, e.g. to
+// share a signature.Store between instances.
+func (c *Client) SetWebhookVerifier(verifier *signature.Verifier) error {
+	if c.webhookHandler == nil {
+		return fmt.Errorf("webhook handler not initialized, call SetWebhookSecret first")
+	}
+	c.webhookHandler.SetVerifier(verifier)
+	return nil
+}
+
 func (c *Client) RegisterWebhookHandler(eventType string, handler api.WebhookEventHandler) error {
 	if c.webhookHandler == nil {
 		return fmt.Errorf("webhook handler not initialized, call SetWebhookSecret first")
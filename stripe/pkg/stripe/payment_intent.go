@@ -19,6 +19,10 @@ type PaymentIntentParams struct {
 	SetupFutureUsage   string            `json:"setup_future_usage,omitempty"`
 
 	ReturnURL string `json:"return_url,omitempty"`
+
+	// IdempotencyKey is sent as Stripe's Idempotency-Key header, so a retried
+	// request returns the payment intent created by the first.
+	IdempotencyKey string `json:"-"`
 }
 
 func (c *Client) CreatePaymentIntent(params PaymentIntentParams) (*stripe.PaymentIntent, error) {
@@ -55,6 +59,10 @@ func (c *Client) CreatePaymentIntent(params PaymentIntentParams) (*stripe.Paymen
 		piParams.ReturnURL = stripe.String(params.ReturnURL)
 	}
 
+	if params.IdempotencyKey != "" {
+		piParams.SetIdempotencyKey(params.IdempotencyKey)
+	}
+
 	return paymentintent.New(piParams)
 }
 
@@ -13,6 +13,9 @@ type RefundParams struct {
 	Amount      int64             `json:"amount,omitempty"`
 	Reason      string            `json:"reason,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+
+	// IdempotencyKey is sent as Stripe's Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
 }
 
 func (c *Client) CreateRefund(params RefundParams) (*stripe.Refund, error) {
@@ -33,6 +36,10 @@ func (c *Client) CreateRefund(params RefundParams) (*stripe.Refund, error) {
 		return nil, fmt.Errorf("either charge_id or payment_intent_id must be provided")
 	}
 
+	if params.IdempotencyKey != "" {
+		refundParams.SetIdempotencyKey(params.IdempotencyKey)
+	}
+
 	return refund.New(refundParams)
 }
 